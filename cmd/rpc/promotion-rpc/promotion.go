@@ -11,10 +11,12 @@ import (
 	"fmt"
 
 	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/zrpc"
 	"google.golang.org/grpc"
 
 	"github.com/aether-defense-system/service/promotion/rpc"
+	promotionConsumer "github.com/aether-defense-system/service/promotion/rpc/consumer"
 	promotionServer "github.com/aether-defense-system/service/promotion/rpc/server"
 	promotionSvc "github.com/aether-defense-system/service/promotion/rpc/svc"
 )
@@ -31,6 +33,19 @@ func main() {
 	// Create ServiceContext using the public helper function
 	ctx := promotionSvc.NewServiceContextFromPublic(&publicCfg)
 
+	// Consume trade's order events when configured
+	orderEvents, err := promotionConsumer.Start(ctx)
+	if err != nil {
+		panic(fmt.Sprintf("failed to start order events consumer: %v", err))
+	}
+	if orderEvents != nil {
+		defer func() {
+			if err := orderEvents.Shutdown(); err != nil {
+				logx.Errorf("failed to shut down order events consumer: %v", err)
+			}
+		}()
+	}
+
 	s := zrpc.MustNewServer(publicCfg.RpcServerConf, func(grpcServer *grpc.Server) {
 		rpc.RegisterPromotionServiceServer(grpcServer, promotionServer.NewPromotionServiceServer(ctx))
 	})
@@ -0,0 +1,246 @@
+// Package main is dlq-admin, a command to inspect and replay the messages consumer groups gave up
+// on. It offers the same operations as the trade AdminListDeadLetters and AdminReplayDeadLetters
+// RPCs, for operators with direct access to RocketMQ and the database.
+//
+// Usage:
+//
+//	dlq-admin [-f config] list -group GROUP [-type TYPE] [-order ORDER_ID] [-limit N]
+//	dlq-admin [-f config] replay -group GROUP -operator NAME [-reason TEXT] [-sandbox] MSG_ID...
+//
+// list prints one JSON object per dead letter; replay prints one per message and records every
+// attempt in the dead_letter_replay audit table.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/conf"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/deadletter"
+	"github.com/aether-defense-system/common/mq"
+)
+
+// Config is the configuration of dlq-admin.
+type Config struct {
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	DeadLetters deadletter.Config `json:"deadLetters,optional"`
+	// NameServer addresses of RocketMQ (comma-separated or semicolon-separated).
+	NameServer string          `json:"nameServer"`
+	Database   database.Config `json:"database"`
+}
+
+// deadLetterView is the JSON output of list.
+type deadLetterView struct {
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	FailedAt       *time.Time      `json:"failedAt,omitempty"`
+	MsgID          string          `json:"msgId"`
+	OriginTopic    string          `json:"originTopic"`
+	Tag            string          `json:"tag,omitempty"`
+	EventID        string          `json:"eventId,omitempty"`
+	EventType      string          `json:"eventType"`
+	AggregateID    string          `json:"aggregateId,omitempty"`
+	DecodeError    string          `json:"decodeError,omitempty"`
+	FailureReason  string          `json:"failureReason,omitempty"`
+	Keys           []string        `json:"keys,omitempty"`
+	EventVersion   int32           `json:"eventVersion,omitempty"`
+	ReconsumeTimes int32           `json:"reconsumeTimes,omitempty"`
+}
+
+// replayView is the JSON output of replay.
+type replayView struct {
+	MsgID       string `json:"msgId"`
+	TargetTopic string `json:"targetTopic,omitempty"`
+	NewMsgID    string `json:"newMsgId,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+var configFile = flag.String("f", "cmd/tool/dlq-admin/etc/dlq-admin.yaml", "the config file")
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var c Config
+	conf.MustLoad(*configFile, &c, conf.UseEnv())
+
+	if err := run(context.Background(), &c, flag.Arg(0), flag.Args()[1:]); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "dlq-admin: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	_, _ = fmt.Fprintf(os.Stderr, `usage:
+  dlq-admin [-f config] list -group GROUP [-type TYPE] [-order ORDER_ID] [-limit N]
+  dlq-admin [-f config] replay -group GROUP -operator NAME [-reason TEXT] [-sandbox] MSG_ID...
+`)
+	flag.PrintDefaults()
+}
+
+// run runs the subcommand cmd with its arguments.
+func run(ctx context.Context, c *Config, cmd string, args []string) error {
+	switch cmd {
+	case "list":
+		return list(ctx, c, args)
+	case "replay":
+		return replay(ctx, c, args)
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+// newInspector connects to RocketMQ and the database. The returned function releases them.
+func newInspector(c *Config) (*deadletter.Inspector, func(), error) {
+	if c.Database.DSN == "" {
+		return nil, nil, errors.New("database DSN is required for failure reasons and the audit log")
+	}
+	db, err := database.NewClient(&c.Database)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	readerCfg := c.DeadLetters.ReaderConfig(mq.Config{NameServer: c.NameServer})
+	reader, err := mq.NewDeadLetterReader(readerCfg)
+	if err != nil {
+		_ = db.Close()
+		return nil, nil, fmt.Errorf("failed to create dead letter reader: %w", err)
+	}
+	producer, err := mq.NewProducer(readerCfg)
+	if err != nil {
+		_ = db.Close()
+		return nil, nil, fmt.Errorf("failed to create producer: %w", err)
+	}
+	closeAll := func() {
+		_ = producer.Shutdown()
+		_ = db.Close()
+	}
+
+	inspector, err := deadletter.NewInspector(c.DeadLetters, reader, producer,
+		database.NewDeadLetterReplayStore(db.DB()),
+		deadletter.WithFailureLog(mq.NewSQLFailureLog(database.NewConsumeFailureStore(db.DB()))))
+	if err != nil {
+		closeAll()
+		return nil, nil, err
+	}
+	return inspector, closeAll, nil
+}
+
+// list prints the dead letters selected by args.
+func list(ctx context.Context, c *Config, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	group := fs.String("group", "", "consumer group whose dead letters are listed (required)")
+	eventType := fs.String("type", "", "only events of this type, e.g. ORDER_PLACED")
+	orderID := fs.Int64("order", 0, "only events about this order")
+	limit := fs.Int("limit", 50, "maximum number of dead letters")
+	_ = fs.Parse(args)
+	if *group == "" {
+		return errors.New("-group is required")
+	}
+
+	inspector, closeAll, err := newInspector(c)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+
+	dead, err := inspector.List(ctx, deadletter.Filter{
+		Group: *group, EventType: *eventType, OrderID: *orderID, Limit: *limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	for _, d := range dead {
+		if err := out.Encode(toDeadLetterView(d)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replay replays the messages named by args and prints the results.
+func replay(ctx context.Context, c *Config, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	group := fs.String("group", "", "consumer group that dead-lettered the messages (required)")
+	operator := fs.String("operator", "", "who replays the messages, for the audit log (required)")
+	reason := fs.String("reason", "", "why the messages are replayed, for the audit log")
+	sandbox := fs.Bool("sandbox", false, "replay to the sandbox topic for a dry run")
+	_ = fs.Parse(args)
+	if *group == "" || *operator == "" {
+		return errors.New("-group and -operator are required")
+	}
+	if fs.NArg() == 0 {
+		return errors.New("no message IDs given")
+	}
+
+	target := deadletter.TargetOrigin
+	if *sandbox {
+		target = deadletter.TargetSandbox
+	}
+
+	inspector, closeAll, err := newInspector(c)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+
+	results, err := inspector.Replay(ctx, &deadletter.ReplayRequest{
+		Group:    *group,
+		Operator: "cli:" + *operator,
+		Reason:   *reason,
+		MsgIDs:   fs.Args(),
+		Target:   target,
+	})
+
+	out := json.NewEncoder(os.Stdout)
+	for _, r := range results {
+		view := replayView{MsgID: r.MsgID, TargetTopic: r.TargetTopic, NewMsgID: r.NewMsgID}
+		if r.Err != nil {
+			view.Error = r.Err.Error()
+		}
+		if encodeErr := out.Encode(view); encodeErr != nil {
+			return encodeErr
+		}
+	}
+	return err
+}
+
+// toDeadLetterView converts a dead letter to its JSON output.
+func toDeadLetterView(d *deadletter.DeadLetter) *deadLetterView {
+	view := &deadLetterView{
+		MsgID:       d.Message.MsgID,
+		OriginTopic: d.OriginTopic,
+		Tag:         d.Message.Tag,
+		Keys:        d.Message.Keys,
+		EventID:     d.EventID(),
+		EventType:   d.EventType(),
+	}
+	if d.Event != nil {
+		view.EventVersion = d.Event.Envelope.Version
+		view.AggregateID = d.Event.Envelope.AggregateId
+		if payload := d.PayloadJSON(); payload != "" {
+			view.Payload = json.RawMessage(payload)
+		}
+	}
+	if d.DecodeErr != nil {
+		view.DecodeError = d.DecodeErr.Error()
+	}
+	if d.Failure != nil {
+		view.FailureReason = d.Failure.Reason
+		view.FailedAt = &d.Failure.FailedAt
+		view.ReconsumeTimes = d.Failure.ReconsumeTimes
+	}
+	return view
+}
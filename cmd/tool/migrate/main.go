@@ -0,0 +1,233 @@
+// Package main is migrate, a command to apply, revert and inspect the schema migrations of a
+// service. It reads the database and the sharding databases from the configuration of the service,
+// and migrates every sharding database of the sets with sharded tables.
+//
+// Usage:
+//
+//	migrate -f config -set SET[,SET...] up
+//	migrate -f config -set SET[,SET...] down [N]
+//	migrate -f config -set SET[,SET...] status
+//	migrate -f config -set SET force VERSION
+//	migrate -f config -set SET forget VERSION
+//
+// The sets are common, trade, promotion, user and reporting. down reverts the last N migrations of
+// each set (default: 1), in reverse order of the sets. force records a migration as applied without
+// running it, and forget removes it from the history without reverting it: both resolve a dirty
+// migration after an operator completed or undid it by hand.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/conf"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/database/migrations"
+	"github.com/aether-defense-system/common/migrate"
+)
+
+// Config is the part of a service configuration read by migrate.
+type Config struct {
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Sharding database.ShardingConfig `json:"sharding,optional"`
+	Database database.Config         `json:"database"`
+}
+
+var (
+	configFile = flag.String("f", "service/trade/rpc/etc/trade.yaml", "the config file of the service")
+	setNames   = flag.String("set", "", "comma-separated migration sets, e.g. common,trade (required)")
+)
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 || *setNames == "" {
+		usage()
+		os.Exit(2)
+	}
+
+	var c Config
+	conf.MustLoad(*configFile, &c, conf.UseEnv())
+
+	if err := run(context.Background(), &c, strings.Split(*setNames, ","), flag.Arg(0), flag.Args()[1:]); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	_, _ = fmt.Fprintf(os.Stderr, `usage:
+  migrate -f config -set SET[,SET...] up
+  migrate -f config -set SET[,SET...] down [N]
+  migrate -f config -set SET[,SET...] status
+  migrate -f config -set SET force VERSION
+  migrate -f config -set SET forget VERSION
+`)
+	flag.PrintDefaults()
+}
+
+// target is a migration set and the migrators of the databases holding it.
+type target struct {
+	set       *migrate.Set
+	migrators []*migrate.Migrator
+}
+
+// run runs the subcommand cmd with its arguments on the sets names.
+func run(ctx context.Context, c *Config, names []string, cmd string, args []string) error {
+	if c.Database.DSN == "" {
+		return errors.New("database DSN is required")
+	}
+	client, err := database.NewClient(&c.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	targets := make([]*target, 0, len(names))
+	for _, name := range names {
+		t, closeRouter, err := newTarget(c, client, strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		defer closeRouter()
+		targets = append(targets, t)
+	}
+
+	switch cmd {
+	case "up":
+		return up(ctx, targets)
+	case "down":
+		return down(ctx, targets, args)
+	case "status":
+		return status(ctx, targets)
+	case "force", "forget":
+		return resolve(ctx, targets, cmd, args)
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+// newTarget loads the set name. Sets with sharded tables are migrated in every sharding database
+// when sharding is configured, and in the database otherwise. The returned function closes the
+// sharding databases.
+func newTarget(c *Config, client *database.Client, name string) (*target, func(), error) {
+	set, err := migrations.Load(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	tables := migrations.ShardedTables[name]
+	if len(tables) == 0 || !c.Sharding.Enabled() {
+		return &target{set: set, migrators: []*migrate.Migrator{migrate.New(client.DB())}}, func() {}, nil
+	}
+	router, err := database.NewShardRouter(&c.Sharding, tables...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to sharding databases: %w", err)
+	}
+	return &target{set: set, migrators: migrate.ForRouter(router)}, func() { _ = router.Close() }, nil
+}
+
+// up applies the pending migrations of every set.
+func up(ctx context.Context, targets []*target) error {
+	for _, t := range targets {
+		for _, m := range t.migrators {
+			applied, err := m.Up(ctx, t.set)
+			for _, mig := range applied {
+				fmt.Printf("%s: applied %s/%d_%s\n", m.Name(), t.set.Name, mig.Version, mig.Name)
+			}
+			if err != nil {
+				return err
+			}
+			if len(applied) == 0 {
+				fmt.Printf("%s: %s is up to date\n", m.Name(), t.set.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// down reverts the last migrations of every set, the number given by args.
+func down(ctx context.Context, targets []*target, args []string) error {
+	steps := 1
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid number of migrations to revert: %q", args[0])
+		}
+		steps = n
+	}
+	for i := len(targets) - 1; i >= 0; i-- {
+		t := targets[i]
+		for _, m := range t.migrators {
+			reverted, err := m.Down(ctx, t.set, steps)
+			for _, mig := range reverted {
+				fmt.Printf("%s: reverted %s/%d_%s\n", m.Name(), t.set.Name, mig.Version, mig.Name)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// status prints the state of the migrations of every set.
+func status(ctx context.Context, targets []*target) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "DATABASE\tSET\tVERSION\tNAME\tSTATE\tAPPLIED")
+	for _, t := range targets {
+		for _, m := range t.migrators {
+			statuses, err := m.Status(ctx, t.set)
+			if err != nil {
+				_ = w.Flush()
+				return err
+			}
+			for _, st := range statuses {
+				applied := "-"
+				if st.AppliedAt != nil {
+					applied = st.AppliedAt.Format(time.DateTime)
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\n",
+					m.Name(), t.set.Name, st.Version, st.Name, st.State, applied)
+			}
+		}
+	}
+	return w.Flush()
+}
+
+// resolve forces or forgets the migration version given by args, in every database of the set.
+func resolve(ctx context.Context, targets []*target, cmd string, args []string) error {
+	if len(targets) != 1 {
+		return fmt.Errorf("%s takes a single migration set", cmd)
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("%s takes the migration version", cmd)
+	}
+	version, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid migration version: %q", args[0])
+	}
+
+	t := targets[0]
+	for _, m := range t.migrators {
+		done := "forced"
+		if cmd == "force" {
+			err = m.Force(ctx, t.set, version)
+		} else {
+			err = m.Forget(ctx, t.set, version)
+			done = "forgot"
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s %s/%d\n", m.Name(), done, t.set.Name, version)
+	}
+	return nil
+}
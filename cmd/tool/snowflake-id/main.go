@@ -0,0 +1,136 @@
+// Package main is snowflake-id, a command to take snowflake IDs apart and to convert them to and
+// from the public IDs exposed over HTTP.
+//
+// Usage:
+//
+//	snowflake-id [-key KEY] decode ID...
+//	snowflake-id [-key KEY] encode ID...
+//
+// decode prints one JSON object per ID with its time, worker and sequence. IDs are decimal or,
+// if a key is set, public IDs. encode prints the public ID of each decimal ID. The key of public
+// IDs is read from the SNOWFLAKE_ID_KEY environment variable, or given with -key.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aether-defense-system/common/snowflake"
+)
+
+// keyEnv is the environment variable holding the key of public IDs.
+const keyEnv = "SNOWFLAKE_ID_KEY"
+
+// idView is the JSON output of decode.
+type idView struct {
+	Time     time.Time `json:"time"`
+	PublicID string    `json:"publicId,omitempty"`
+	ID       int64     `json:"id"`
+	Worker   int64     `json:"worker"`
+	Sequence int64     `json:"sequence"`
+}
+
+var key = flag.String("key", os.Getenv(keyEnv), "key of public IDs (default: $"+keyEnv+")")
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), flag.Args()[1:]); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "snowflake-id: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	_, _ = fmt.Fprintf(os.Stderr, `usage:
+  snowflake-id [-key KEY] decode ID...
+  snowflake-id [-key KEY] encode ID...
+`)
+	flag.PrintDefaults()
+}
+
+// run runs the subcommand cmd on the IDs in args.
+func run(cmd string, args []string) error {
+	var codec *snowflake.IDCodec
+	if *key != "" {
+		var err error
+		if codec, err = snowflake.NewIDCodec([]byte(*key)); err != nil {
+			return err
+		}
+	}
+
+	switch cmd {
+	case "decode":
+		return decode(codec, args)
+	case "encode":
+		if codec == nil {
+			return errors.New("encode needs a key, set -key or $" + keyEnv)
+		}
+		return encode(codec, args)
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+// decode prints the components of the IDs in args.
+func decode(codec *snowflake.IDCodec, args []string) error {
+	out := json.NewEncoder(os.Stdout)
+	for _, arg := range args {
+		view, err := decodeID(codec, arg)
+		if err != nil {
+			return err
+		}
+		if err := out.Encode(view); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeID takes apart the ID s: a decimal ID or, with a codec, a public ID.
+func decodeID(codec *snowflake.IDCodec, s string) (*idView, error) {
+	view := &idView{}
+	if id, err := strconv.ParseInt(s, 10, 64); err == nil && id >= 0 {
+		view.ID = id
+		if codec != nil {
+			view.PublicID = codec.Encode(id)
+		}
+	} else if codec != nil {
+		if view.ID, err = codec.Decode(s); err != nil {
+			return nil, err
+		}
+		view.PublicID = s
+	} else {
+		return nil, fmt.Errorf("invalid ID %q, set a key to decode public IDs", s)
+	}
+
+	timestamp, worker, sequence := snowflake.ParseID(view.ID)
+	view.Time = time.UnixMilli(timestamp).UTC()
+	view.Worker = worker
+	view.Sequence = sequence
+	return view, nil
+}
+
+// encode prints the public IDs of the decimal IDs in args, one per line.
+func encode(codec *snowflake.IDCodec, args []string) error {
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || id < 0 {
+			return fmt.Errorf("invalid ID %q", arg)
+		}
+		if _, err := fmt.Println(codec.Encode(id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
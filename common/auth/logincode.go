@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aether-defense-system/common/redis"
+)
+
+// ErrInvalidLoginCode is returned when a login verification code is missing, expired or wrong.
+var ErrInvalidLoginCode = errors.New("invalid or expired login code")
+
+// LoginCodeRedis defines the minimal Redis operations required by LoginCodeStore.
+type LoginCodeRedis interface {
+	// ReleaseKey deletes key if it holds marker, and reports whether it did.
+	ReleaseKey(ctx context.Context, key, marker string) (bool, error)
+}
+
+// LoginCodeStore verifies one-time login codes (e.g. SMS codes) stored under
+// KeyNamingHelper.LoginCodeKey. Delivering the codes is the job of the notification side.
+type LoginCodeStore struct {
+	rdb  LoginCodeRedis
+	keys *redis.KeyNamingHelper
+}
+
+// NewLoginCodeStore creates a LoginCodeStore.
+func NewLoginCodeStore(rdb LoginCodeRedis) *LoginCodeStore {
+	return &LoginCodeStore{
+		rdb:  rdb,
+		keys: redis.NewKeyNamingHelper(),
+	}
+}
+
+// Verify checks the code for a mobile number and consumes it on success.
+// The code is compared and deleted in one step, so that it cannot be redeemed twice, while a
+// wrong code leaves it in place.
+func (s *LoginCodeStore) Verify(ctx context.Context, mobile, code string) error {
+	if code == "" {
+		return ErrInvalidLoginCode
+	}
+
+	consumed, err := s.rdb.ReleaseKey(ctx, s.keys.LoginCodeKey(mobile), code)
+	if err != nil {
+		return fmt.Errorf("failed to consume login code: %w", err)
+	}
+	if !consumed {
+		return ErrInvalidLoginCode
+	}
+
+	return nil
+}
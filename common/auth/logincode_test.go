@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLoginCodeStore_Verify(t *testing.T) {
+	ctx := context.Background()
+	rdb := newFakeRedis()
+	store := NewLoginCodeStore(rdb)
+	key := store.keys.LoginCodeKey("13800138000")
+
+	rdb.strings[key] = "123456"
+
+	if err := store.Verify(ctx, "13800138000", "000000"); !errors.Is(err, ErrInvalidLoginCode) {
+		t.Fatalf("Verify() with wrong code error = %v, want ErrInvalidLoginCode", err)
+	}
+	if err := store.Verify(ctx, "13800138000", ""); !errors.Is(err, ErrInvalidLoginCode) {
+		t.Fatalf("Verify() with empty code error = %v, want ErrInvalidLoginCode", err)
+	}
+	if err := store.Verify(ctx, "13800138000", "123456"); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	// Codes are single use
+	if err := store.Verify(ctx, "13800138000", "123456"); !errors.Is(err, ErrInvalidLoginCode) {
+		t.Fatalf("Verify() replay error = %v, want ErrInvalidLoginCode", err)
+	}
+}
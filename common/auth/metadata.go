@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataAuthorization is the gRPC metadata key used to forward the caller's access token
+// from a gateway to RPC services that enforce permissions.
+const MetadataAuthorization = "authorization"
+
+const bearerPrefix = "Bearer "
+
+// WithOutgoingAuthorization forwards an HTTP Authorization header value to outgoing gRPC calls.
+// An empty header leaves the context unchanged.
+func WithOutgoingAuthorization(ctx context.Context, authorization string) context.Context {
+	if authorization == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, MetadataAuthorization, authorization)
+}
+
+// TokenFromIncomingContext returns the bearer token forwarded in incoming gRPC metadata.
+func TokenFromIncomingContext(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+
+	values := md.Get(MetadataAuthorization)
+	if len(values) == 0 {
+		return "", false
+	}
+
+	token := values[0]
+	if len(token) >= len(bearerPrefix) && strings.EqualFold(token[:len(bearerPrefix)], bearerPrefix) {
+		token = token[len(bearerPrefix):]
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
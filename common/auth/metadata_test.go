@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestTokenFromIncomingContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   string
+		wantOK bool
+	}{
+		{name: "bearer token", values: []string{"Bearer abc"}, want: "abc", wantOK: true},
+		{name: "lowercase scheme", values: []string{"bearer abc"}, want: "abc", wantOK: true},
+		{name: "raw token", values: []string{"abc"}, want: "abc", wantOK: true},
+		{name: "empty bearer", values: []string{"Bearer "}, wantOK: false},
+		{name: "missing", values: nil, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := metadata.MD{}
+			if tt.values != nil {
+				md.Set(MetadataAuthorization, tt.values...)
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+
+			got, ok := TokenFromIncomingContext(ctx)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("TokenFromIncomingContext() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if _, ok := TokenFromIncomingContext(context.Background()); ok {
+		t.Errorf("expected no token without metadata")
+	}
+}
+
+func TestWithOutgoingAuthorization(t *testing.T) {
+	ctx := WithOutgoingAuthorization(context.Background(), "Bearer abc")
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if got := md.Get(MetadataAuthorization); len(got) != 1 || got[0] != "Bearer abc" {
+		t.Errorf("outgoing authorization = %v", got)
+	}
+
+	if ctx := WithOutgoingAuthorization(context.Background(), ""); ctx != context.Background() {
+		t.Errorf("expected context unchanged for empty header")
+	}
+}
@@ -0,0 +1,96 @@
+package auth
+
+import "errors"
+
+// ErrPermissionDenied is returned when none of the caller's roles grants a required permission.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Roles known to the user domain. Role assignments are stored in the user_role table
+// and carried in access tokens as the ClaimRoles claim.
+const (
+	RoleAdmin    = "admin"    // Full access to every admin operation
+	RoleOperator = "operator" // Runs promotions and inventory
+	RoleSupport  = "support"  // Handles customer accounts and refunds
+)
+
+// Permissions checked by the gateways and RPC services.
+// Permission strings follow the "<resource>:<action>" convention.
+const (
+	PermUserBan              = "user:ban"
+	PermUserSessionRevoke    = "user:session:revoke"
+	PermCouponTemplateCreate = "coupon:template:create"
+	PermOrderRead            = "order:read"
+	PermOrderRefund          = "order:refund"
+	PermStockPreheat         = "stock:preheat"
+	PermDeadLetterRead       = "mq:deadletter:read"
+	PermDeadLetterReplay     = "mq:deadletter:replay"
+
+	// PermAll grants every permission. Only the admin role should hold it.
+	PermAll = "*"
+)
+
+// Policy maps roles to the permissions they grant.
+// The catalogue belongs to the user domain but lives here so every enforcement point
+// can evaluate it locally instead of calling user-rpc on each request.
+type Policy struct {
+	grants map[string]map[string]struct{}
+}
+
+// NewPolicy creates a Policy from a role -> permissions table.
+func NewPolicy(grants map[string][]string) *Policy {
+	p := &Policy{grants: make(map[string]map[string]struct{}, len(grants))}
+	for role, perms := range grants {
+		set := make(map[string]struct{}, len(perms))
+		for _, perm := range perms {
+			set[perm] = struct{}{}
+		}
+		p.grants[role] = set
+	}
+	return p
+}
+
+// DefaultPolicy returns the built-in role catalogue.
+func DefaultPolicy() *Policy {
+	return NewPolicy(map[string][]string{
+		RoleAdmin: {PermAll},
+		RoleOperator: {
+			PermCouponTemplateCreate,
+			PermStockPreheat,
+		},
+		RoleSupport: {
+			PermUserBan,
+			PermUserSessionRevoke,
+			PermOrderRead,
+			PermOrderRefund,
+		},
+	})
+}
+
+// Allows reports whether any of the roles grants the permission.
+// Unknown roles grant nothing and an empty permission is never allowed.
+func (p *Policy) Allows(roles []string, permission string) bool {
+	if permission == "" {
+		return false
+	}
+	for _, role := range roles {
+		perms, ok := p.grants[role]
+		if !ok {
+			continue
+		}
+		if _, ok := perms[permission]; ok {
+			return true
+		}
+		if _, ok := perms[PermAll]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns ErrPermissionDenied unless the roles grant the permission.
+func (p *Policy) Check(roles []string, permission string) error {
+	if !p.Allows(roles, permission) {
+		return ErrPermissionDenied
+	}
+	return nil
+}
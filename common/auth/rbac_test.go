@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPolicy_Allows(t *testing.T) {
+	policy := DefaultPolicy()
+
+	tests := []struct {
+		name       string
+		permission string
+		roles      []string
+		want       bool
+	}{
+		{name: "admin has everything", roles: []string{RoleAdmin}, permission: PermOrderRefund, want: true},
+		{name: "support can refund", roles: []string{RoleSupport}, permission: PermOrderRefund, want: true},
+		{name: "operator cannot refund", roles: []string{RoleOperator}, permission: PermOrderRefund, want: false},
+		{name: "any role grants", roles: []string{RoleOperator, RoleSupport}, permission: PermUserBan, want: true},
+		{name: "no roles", roles: nil, permission: PermStockPreheat, want: false},
+		{name: "unknown role", roles: []string{"root"}, permission: PermStockPreheat, want: false},
+		{name: "empty permission", roles: []string{RoleAdmin}, permission: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Allows(tt.roles, tt.permission); got != tt.want {
+				t.Errorf("Allows(%v, %q) = %v, want %v", tt.roles, tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	policy := NewPolicy(map[string][]string{"auditor": {PermOrderRead}})
+
+	if err := policy.Check([]string{"auditor"}, PermOrderRead); err != nil {
+		t.Errorf("Check() error = %v, want nil", err)
+	}
+	if err := policy.Check([]string{"auditor"}, PermOrderRefund); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("Check() error = %v, want ErrPermissionDenied", err)
+	}
+}
@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	redisv9 "github.com/redis/go-redis/v9"
+
+	"github.com/aether-defense-system/common/redis"
+)
+
+// Session errors.
+var (
+	ErrSessionNotFound     = errors.New("session not found or revoked")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+	errReadOnlyStore = errors.New("session store has no refresh expire configured")
+)
+
+// SessionRedis defines the minimal Redis operations required by SessionStore.
+// This indirection keeps unit tests fast and hermetic (no external Redis required).
+type SessionRedis interface {
+	HSet(ctx context.Context, key string, values ...interface{}) error
+	HGet(ctx context.Context, key, field string) (string, error)
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HDel(ctx context.Context, key string, fields ...string) error
+	Del(ctx context.Context, keys ...string) error
+	Expire(ctx context.Context, key string, expiration time.Duration) error
+	SwapHashField(ctx context.Context, key, field, oldValue, newValue string, ttl time.Duration) (bool, error)
+}
+
+// Session represents one logged-in device of a user.
+//
+//nolint:govet // Field order optimized for logical grouping
+type Session struct {
+	SessionID      string    `json:"sessionId"`
+	UserID         int64     `json:"userId"`
+	Device         string    `json:"device"`
+	CreateTime     time.Time `json:"createTime"`
+	LastActiveTime time.Time `json:"lastActiveTime"` // Last login or token refresh
+	ExpireTime     time.Time `json:"expireTime"`     // Refresh token expiry
+	RefreshHash    string    `json:"refreshHash"`    // SHA-256 of the current refresh token
+}
+
+// SessionStore keeps user sessions in Redis so that tokens can be revoked before they expire.
+//
+// All sessions of a user live in a single hash (KeyNamingHelper.UserSessionKey) keyed by
+// session ID. Hash fields cannot expire individually, so each record carries its own
+// ExpireTime and expired records are pruned lazily on read.
+type SessionStore struct {
+	rdb  SessionRedis
+	keys *redis.KeyNamingHelper
+	ttl  time.Duration
+}
+
+// NewSessionStore creates a SessionStore.
+// refreshExpire is the refresh token (and therefore session) lifetime in seconds.
+// Gateways that only check sessions may pass 0; Create and Rotate then fail.
+func NewSessionStore(rdb SessionRedis, refreshExpire int64) (*SessionStore, error) {
+	if rdb == nil {
+		return nil, fmt.Errorf("session redis cannot be nil")
+	}
+	if refreshExpire < 0 {
+		return nil, fmt.Errorf("refresh expire cannot be negative, got %d", refreshExpire)
+	}
+
+	return &SessionStore{
+		rdb:  rdb,
+		keys: redis.NewKeyNamingHelper(),
+		ttl:  time.Duration(refreshExpire) * time.Second,
+	}, nil
+}
+
+// Create opens a new session for the user and returns it with its refresh token.
+// The refresh token is only returned here and by Rotate; the store keeps its hash.
+func (s *SessionStore) Create(ctx context.Context, userID int64, device string) (*Session, string, error) {
+	if s.ttl <= 0 {
+		return nil, "", errReadOnlyStore
+	}
+
+	sessionID, err := randomHex(16)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+
+	refreshToken, err := newRefreshToken(userID, sessionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	session := &Session{
+		SessionID:      sessionID,
+		UserID:         userID,
+		Device:         device,
+		CreateTime:     now,
+		LastActiveTime: now,
+		ExpireTime:     now.Add(s.ttl),
+		RefreshHash:    hashToken(refreshToken),
+	}
+
+	if err := s.save(ctx, session); err != nil {
+		return nil, "", err
+	}
+
+	return session, refreshToken, nil
+}
+
+// Rotate validates a refresh token and replaces it with a new one.
+// The old refresh token stops working immediately, so a leaked token can be used at most once.
+// The record is replaced only if it has not changed since it was read: of concurrent refreshes
+// with the same token only one succeeds, and a session revoked meanwhile stays revoked.
+func (s *SessionStore) Rotate(ctx context.Context, refreshToken string) (*Session, string, error) {
+	if s.ttl <= 0 {
+		return nil, "", errReadOnlyStore
+	}
+
+	userID, sessionID, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	session, raw, err := s.load(ctx, userID, sessionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(session.RefreshHash), []byte(hashToken(refreshToken))) != 1 {
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	newToken, err := newRefreshToken(userID, sessionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	session.LastActiveTime = now
+	session.ExpireTime = now.Add(s.ttl)
+	session.RefreshHash = hashToken(newToken)
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode session: %w", err)
+	}
+	swapped, err := s.rdb.SwapHashField(ctx, s.keys.UserSessionKey(userID), sessionID, raw, string(data), s.ttl)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to save session: %w", err)
+	}
+	if !swapped {
+		// Rotated by a concurrent refresh with the same token, or revoked.
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	return session, newToken, nil
+}
+
+// IsActive reports whether the session exists and has not expired.
+func (s *SessionStore) IsActive(ctx context.Context, userID int64, sessionID string) (bool, error) {
+	_, err := s.get(ctx, userID, sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// List returns the active sessions of a user, pruning expired ones.
+func (s *SessionStore) List(ctx context.Context, userID int64) ([]*Session, error) {
+	key := s.keys.UserSessionKey(userID)
+
+	fields, err := s.rdb.HGetAll(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	now := time.Now()
+	sessions := make([]*Session, 0, len(fields))
+	var expired []string
+	for sessionID, raw := range fields {
+		var session Session
+		if err := json.Unmarshal([]byte(raw), &session); err != nil || !now.Before(session.ExpireTime) {
+			expired = append(expired, sessionID)
+			continue
+		}
+		sessions = append(sessions, &session)
+	}
+
+	if len(expired) > 0 {
+		if err := s.rdb.HDel(ctx, key, expired...); err != nil {
+			return nil, fmt.Errorf("failed to prune expired sessions: %w", err)
+		}
+	}
+
+	return sessions, nil
+}
+
+// Revoke ends a single session. Revoking an unknown session is not an error.
+func (s *SessionStore) Revoke(ctx context.Context, userID int64, sessionID string) error {
+	if err := s.rdb.HDel(ctx, s.keys.UserSessionKey(userID), sessionID); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeAll ends every session of a user (force logout).
+func (s *SessionStore) RevokeAll(ctx context.Context, userID int64) error {
+	if err := s.rdb.Del(ctx, s.keys.UserSessionKey(userID)); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
+
+// get loads a session, treating missing and expired records alike.
+func (s *SessionStore) get(ctx context.Context, userID int64, sessionID string) (*Session, error) {
+	session, _, err := s.load(ctx, userID, sessionID)
+	return session, err
+}
+
+// load loads a session like get, along with its record as stored.
+func (s *SessionStore) load(ctx context.Context, userID int64, sessionID string) (*Session, string, error) {
+	raw, err := s.rdb.HGet(ctx, s.keys.UserSessionKey(userID), sessionID)
+	if err != nil {
+		if errors.Is(err, redisv9.Nil) {
+			return nil, "", ErrSessionNotFound
+		}
+		return nil, "", fmt.Errorf("failed to get session: %w", err)
+	}
+
+	var session Session
+	if err := json.Unmarshal([]byte(raw), &session); err != nil {
+		return nil, "", fmt.Errorf("failed to decode session: %w", err)
+	}
+
+	if !time.Now().Before(session.ExpireTime) {
+		return nil, "", ErrSessionNotFound
+	}
+
+	return &session, raw, nil
+}
+
+// save writes a session and extends the hash TTL to cover its expiry.
+func (s *SessionStore) save(ctx context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	key := s.keys.UserSessionKey(session.UserID)
+	if err := s.rdb.HSet(ctx, key, session.SessionID, string(data)); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	if err := s.rdb.Expire(ctx, key, s.ttl); err != nil {
+		return fmt.Errorf("failed to set session expiry: %w", err)
+	}
+
+	return nil
+}
+
+// newRefreshToken builds an opaque refresh token of the form "<userID>.<sessionID>.<secret>".
+// The user and session IDs let Rotate locate the record without a secondary index.
+func newRefreshToken(userID int64, sessionID string) (string, error) {
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return fmt.Sprintf("%d.%s.%s", userID, sessionID, secret), nil
+}
+
+// parseRefreshToken extracts the user and session IDs from a refresh token.
+func parseRefreshToken(token string) (userID int64, sessionID string, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return 0, "", ErrInvalidRefreshToken
+	}
+
+	userID, err = strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || userID <= 0 {
+		return 0, "", ErrInvalidRefreshToken
+	}
+
+	return userID, parts[1], nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
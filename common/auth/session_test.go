@@ -0,0 +1,305 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	redisv9 "github.com/redis/go-redis/v9"
+)
+
+// fakeRedis is an in-memory stand-in for the Redis operations used by this package.
+type fakeRedis struct {
+	hashes     map[string]map[string]string
+	strings    map[string]string
+	err        error
+	beforeSwap func() // Runs before SwapHashField compares, like a concurrent client would
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		hashes:  make(map[string]map[string]string),
+		strings: make(map[string]string),
+	}
+}
+
+func (f *fakeRedis) HSet(_ context.Context, key string, values ...interface{}) error {
+	if f.err != nil {
+		return f.err
+	}
+	if f.hashes[key] == nil {
+		f.hashes[key] = make(map[string]string)
+	}
+	for i := 0; i+1 < len(values); i += 2 {
+		f.hashes[key][values[i].(string)] = values[i+1].(string)
+	}
+	return nil
+}
+
+func (f *fakeRedis) HGet(_ context.Context, key, field string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	v, ok := f.hashes[key][field]
+	if !ok {
+		return "", redisv9.Nil
+	}
+	return v, nil
+}
+
+func (f *fakeRedis) HGetAll(_ context.Context, key string) (map[string]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := make(map[string]string, len(f.hashes[key]))
+	for k, v := range f.hashes[key] {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func (f *fakeRedis) HDel(_ context.Context, key string, fields ...string) error {
+	if f.err != nil {
+		return f.err
+	}
+	for _, field := range fields {
+		delete(f.hashes[key], field)
+	}
+	return nil
+}
+
+func (f *fakeRedis) Get(_ context.Context, key string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	v, ok := f.strings[key]
+	if !ok {
+		return "", redisv9.Nil
+	}
+	return v, nil
+}
+
+func (f *fakeRedis) Del(_ context.Context, keys ...string) error {
+	if f.err != nil {
+		return f.err
+	}
+	for _, key := range keys {
+		delete(f.hashes, key)
+		delete(f.strings, key)
+	}
+	return nil
+}
+
+func (f *fakeRedis) Expire(_ context.Context, _ string, _ time.Duration) error {
+	return f.err
+}
+
+func (f *fakeRedis) SwapHashField(_ context.Context, key, field, oldValue, newValue string, _ time.Duration) (bool, error) {
+	if f.err != nil {
+		return false, f.err
+	}
+	if hook := f.beforeSwap; hook != nil {
+		f.beforeSwap = nil
+		hook()
+	}
+	if v, ok := f.hashes[key][field]; !ok || v != oldValue {
+		return false, nil
+	}
+	f.hashes[key][field] = newValue
+	return true, nil
+}
+
+func (f *fakeRedis) ReleaseKey(_ context.Context, key, marker string) (bool, error) {
+	if f.err != nil {
+		return false, f.err
+	}
+	if v, ok := f.strings[key]; !ok || v != marker {
+		return false, nil
+	}
+	delete(f.strings, key)
+	return true, nil
+}
+
+func TestNewSessionStore(t *testing.T) {
+	if _, err := NewSessionStore(nil, 60); err == nil {
+		t.Errorf("expected error for nil redis")
+	}
+	if _, err := NewSessionStore(newFakeRedis(), -1); err == nil {
+		t.Errorf("expected error for negative expire")
+	}
+
+	// A check-only store cannot create sessions
+	store, err := NewSessionStore(newFakeRedis(), 0)
+	if err != nil {
+		t.Fatalf("NewSessionStore() error = %v", err)
+	}
+	if _, _, err := store.Create(context.Background(), 1, "web"); err == nil {
+		t.Errorf("expected Create to fail on a check-only store")
+	}
+}
+
+func TestSessionStore_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewSessionStore(newFakeRedis(), 3600)
+	if err != nil {
+		t.Fatalf("NewSessionStore() error = %v", err)
+	}
+
+	session, refreshToken, err := store.Create(ctx, 1, "iPhone")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if session.SessionID == "" || !strings.HasPrefix(refreshToken, "1."+session.SessionID+".") {
+		t.Fatalf("unexpected session/refresh token: %+v, %s", session, refreshToken)
+	}
+
+	active, err := store.IsActive(ctx, 1, session.SessionID)
+	if err != nil || !active {
+		t.Fatalf("IsActive() = %v, %v, want true", active, err)
+	}
+
+	// Sessions are scoped per user
+	active, err = store.IsActive(ctx, 2, session.SessionID)
+	if err != nil || active {
+		t.Fatalf("IsActive() for other user = %v, %v, want false", active, err)
+	}
+
+	// Rotation invalidates the old refresh token
+	rotated, newToken, err := store.Rotate(ctx, refreshToken)
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if rotated.SessionID != session.SessionID || newToken == refreshToken {
+		t.Fatalf("unexpected rotation result: %+v, %s", rotated, newToken)
+	}
+	if _, _, err = store.Rotate(ctx, refreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("Rotate() with old token error = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	second, _, err := store.Create(ctx, 1, "Chrome")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	sessions, err := store.List(ctx, 1)
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("List() = %d sessions, %v, want 2", len(sessions), err)
+	}
+
+	if err := store.Revoke(ctx, 1, second.SessionID); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if active, _ := store.IsActive(ctx, 1, second.SessionID); active {
+		t.Fatalf("expected revoked session to be inactive")
+	}
+
+	if err := store.RevokeAll(ctx, 1); err != nil {
+		t.Fatalf("RevokeAll() error = %v", err)
+	}
+	if _, _, err := store.Rotate(ctx, newToken); !errors.Is(err, ErrSessionNotFound) {
+		t.Fatalf("Rotate() after RevokeAll error = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestSessionStore_RotateRaces(t *testing.T) {
+	ctx := context.Background()
+	rdb := newFakeRedis()
+	store, err := NewSessionStore(rdb, 3600)
+	if err != nil {
+		t.Fatalf("NewSessionStore() error = %v", err)
+	}
+
+	// Of two refreshes with the same token, only the first to write succeeds.
+	session, refreshToken, err := store.Create(ctx, 1, "web")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	var concurrentToken string
+	rdb.beforeSwap = func() {
+		if _, concurrentToken, err = store.Rotate(ctx, refreshToken); err != nil {
+			t.Fatalf("concurrent Rotate() error = %v", err)
+		}
+	}
+	if _, _, err = store.Rotate(ctx, refreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("Rotate() losing the race error = %v, want ErrInvalidRefreshToken", err)
+	}
+	if _, _, err = store.Rotate(ctx, concurrentToken); err != nil {
+		t.Fatalf("Rotate() with the winning token error = %v", err)
+	}
+
+	// A session revoked during a refresh stays revoked.
+	session, refreshToken, err = store.Create(ctx, 1, "web")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	rdb.beforeSwap = func() {
+		if revokeErr := store.Revoke(ctx, 1, session.SessionID); revokeErr != nil {
+			t.Fatalf("Revoke() error = %v", revokeErr)
+		}
+	}
+	if _, _, err = store.Rotate(ctx, refreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("Rotate() of a revoked session error = %v, want ErrInvalidRefreshToken", err)
+	}
+	if active, _ := store.IsActive(ctx, 1, session.SessionID); active {
+		t.Errorf("expected the revoked session to stay inactive")
+	}
+}
+
+func TestSessionStore_ExpiredSessionsArePruned(t *testing.T) {
+	ctx := context.Background()
+	rdb := newFakeRedis()
+	store, err := NewSessionStore(rdb, 3600)
+	if err != nil {
+		t.Fatalf("NewSessionStore() error = %v", err)
+	}
+
+	session, _, err := store.Create(ctx, 1, "web")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// Move the session expiry into the past
+	session.ExpireTime = time.Now().Add(-time.Minute)
+	if err = store.save(ctx, session); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	if active, _ := store.IsActive(ctx, 1, session.SessionID); active {
+		t.Errorf("expected expired session to be inactive")
+	}
+
+	sessions, err := store.List(ctx, 1)
+	if err != nil || len(sessions) != 0 {
+		t.Fatalf("List() = %d sessions, %v, want 0", len(sessions), err)
+	}
+	if len(rdb.hashes[store.keys.UserSessionKey(1)]) != 0 {
+		t.Errorf("expected expired session to be pruned")
+	}
+}
+
+func TestSessionStore_InvalidRefreshTokens(t *testing.T) {
+	store, err := NewSessionStore(newFakeRedis(), 3600)
+	if err != nil {
+		t.Fatalf("NewSessionStore() error = %v", err)
+	}
+
+	for _, token := range []string{"", "abc", "0.sid.secret", "x.sid.secret", "1..secret", "1.sid."} {
+		if _, _, err := store.Rotate(context.Background(), token); err == nil {
+			t.Errorf("Rotate(%q) expected error", token)
+		}
+	}
+}
+
+func TestSessionStore_RedisError(t *testing.T) {
+	rdb := newFakeRedis()
+	rdb.err = errors.New("redis down")
+	store, err := NewSessionStore(rdb, 3600)
+	if err != nil {
+		t.Fatalf("NewSessionStore() error = %v", err)
+	}
+
+	if _, err := store.IsActive(context.Background(), 1, "sid"); err == nil {
+		t.Errorf("expected IsActive to surface redis errors")
+	}
+}
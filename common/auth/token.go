@@ -0,0 +1,214 @@
+// Package auth provides token issuance and session management shared by the
+// user domain (which issues tokens) and the HTTP gateways (which verify them).
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// ClaimUserID is the JWT claim carrying the authenticated user ID.
+	// go-zero's JWT middleware copies every custom claim into the request context
+	// under its claim name, so this is also the context key handlers read.
+	ClaimUserID = "userId"
+	// ClaimSessionID is the JWT claim carrying the session ID the token belongs to.
+	// We cannot use the standard "jti" claim because go-zero drops standard claims
+	// before populating the request context.
+	ClaimSessionID = "sid"
+	// ClaimRoles is the JWT claim carrying the user's roles (see Policy).
+	ClaimRoles = "roles"
+)
+
+// Errors returned when extracting identity from a request context.
+var (
+	ErrMissingUserID    = errors.New("missing user_id in token")
+	ErrInvalidUserID    = errors.New("invalid user_id in token")
+	ErrMissingSessionID = errors.New("missing session id in token")
+	ErrInvalidToken     = errors.New("invalid access token")
+)
+
+// Claims is the identity carried by an access token.
+type Claims struct {
+	SessionID string
+	Roles     []string
+	UserID    int64
+}
+
+// TokenIssuer signs short-lived access tokens (HS256) for authenticated sessions.
+type TokenIssuer struct {
+	secret []byte
+	expire time.Duration
+}
+
+// NewTokenIssuer creates a TokenIssuer.
+// accessExpire is the access token lifetime in seconds, matching go-zero's AccessExpire convention.
+func NewTokenIssuer(accessSecret string, accessExpire int64) (*TokenIssuer, error) {
+	if accessSecret == "" {
+		return nil, fmt.Errorf("access secret is required")
+	}
+	if accessExpire <= 0 {
+		return nil, fmt.Errorf("access expire must be greater than 0, got %d", accessExpire)
+	}
+
+	return &TokenIssuer{
+		secret: []byte(accessSecret),
+		expire: time.Duration(accessExpire) * time.Second,
+	}, nil
+}
+
+// IssueAccessToken signs an access token for the given user, session and roles.
+// It returns the token together with its expiration time.
+func (t *TokenIssuer) IssueAccessToken(userID int64, sessionID string, roles []string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(t.expire)
+
+	claims := jwt.MapClaims{
+		ClaimUserID:    userID,
+		ClaimSessionID: sessionID,
+		"iat":          now.Unix(),
+		"exp":          expiresAt.Unix(),
+	}
+	if len(roles) > 0 {
+		claims[ClaimRoles] = roles
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	return token, expiresAt, nil
+}
+
+// TokenVerifier validates access tokens outside of go-zero's HTTP JWT middleware,
+// e.g. tokens forwarded to RPC services in gRPC metadata.
+type TokenVerifier struct {
+	secret []byte
+}
+
+// NewTokenVerifier creates a TokenVerifier.
+func NewTokenVerifier(accessSecret string) (*TokenVerifier, error) {
+	if accessSecret == "" {
+		return nil, fmt.Errorf("access secret is required")
+	}
+	return &TokenVerifier{secret: []byte(accessSecret)}, nil
+}
+
+// Verify checks the token signature and expiry and returns its claims.
+func (v *TokenVerifier) Verify(tokenString string) (*Claims, error) {
+	parser := jwt.Parser{UseJSONNumber: true, ValidMethods: []string{jwt.SigningMethodHS256.Alg()}}
+
+	token, err := parser.Parse(tokenString, func(*jwt.Token) (interface{}, error) {
+		return v.secret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	userID, err := parseUserID(mapClaims[ClaimUserID])
+	if err != nil {
+		return nil, err
+	}
+	sessionID, _ := mapClaims[ClaimSessionID].(string)
+
+	return &Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		Roles:     parseRoles(mapClaims[ClaimRoles]),
+	}, nil
+}
+
+// claimsKey is the context key of the claims of a verified access token.
+type claimsKey struct{}
+
+// WithClaims returns ctx carrying the claims of the caller's verified access token, so that RPC
+// handlers behind a permission check know who called them.
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims placed in ctx by WithClaims.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok && claims != nil
+}
+
+// UserIDFromContext extracts the user ID placed in the context by go-zero's JWT middleware.
+//
+// go-zero decodes claims with json.Number, so numeric claims do not arrive as int64;
+// we accept the representations produced by the common JWT decoders.
+func UserIDFromContext(ctx context.Context) (int64, error) {
+	return parseUserID(ctx.Value(ClaimUserID))
+}
+
+func parseUserID(val interface{}) (int64, error) {
+	if val == nil {
+		return 0, ErrMissingUserID
+	}
+
+	var userID int64
+	switch v := val.(type) {
+	case json.Number:
+		id, err := v.Int64()
+		if err != nil {
+			return 0, ErrInvalidUserID
+		}
+		userID = id
+	case int64:
+		userID = v
+	case float64:
+		userID = int64(v)
+	default:
+		return 0, ErrInvalidUserID
+	}
+
+	if userID <= 0 {
+		return 0, ErrInvalidUserID
+	}
+
+	return userID, nil
+}
+
+// SessionIDFromContext extracts the session ID placed in the context by go-zero's JWT middleware.
+func SessionIDFromContext(ctx context.Context) (string, error) {
+	sessionID, ok := ctx.Value(ClaimSessionID).(string)
+	if !ok || sessionID == "" {
+		return "", ErrMissingSessionID
+	}
+	return sessionID, nil
+}
+
+// RolesFromContext extracts the roles placed in the context by go-zero's JWT middleware.
+// Tokens without a roles claim yield no roles.
+func RolesFromContext(ctx context.Context) []string {
+	return parseRoles(ctx.Value(ClaimRoles))
+}
+
+// parseRoles accepts the decoded JSON array form ([]interface{}) as well as []string.
+func parseRoles(val interface{}) []string {
+	switch v := val.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		roles := make([]string, 0, len(v))
+		for _, item := range v {
+			if role, ok := item.(string); ok && role != "" {
+				roles = append(roles, role)
+			}
+		}
+		return roles
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestNewTokenIssuer(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		expire  int64
+		wantErr bool
+	}{
+		{name: "valid", secret: "secret", expire: 60},
+		{name: "empty secret", secret: "", expire: 60, wantErr: true},
+		{name: "zero expire", secret: "secret", expire: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTokenIssuer(tt.secret, tt.expire)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTokenIssuer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTokenIssuer_IssueAccessToken(t *testing.T) {
+	issuer, err := NewTokenIssuer("test-secret", 60)
+	if err != nil {
+		t.Fatalf("NewTokenIssuer() error = %v", err)
+	}
+
+	token, expiresAt, err := issuer.IssueAccessToken(42, "sess-1", []string{RoleSupport})
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+	if expiresAt.IsZero() {
+		t.Fatalf("expected non-zero expiry")
+	}
+
+	// Parse the same way go-zero does (json.Number for numeric claims)
+	parser := jwt.Parser{UseJSONNumber: true}
+	parsed, err := parser.Parse(token, func(*jwt.Token) (interface{}, error) {
+		return []byte("test-secret"), nil
+	})
+	if err != nil || !parsed.Valid {
+		t.Fatalf("failed to parse issued token: %v", err)
+	}
+
+	claims := parsed.Claims.(jwt.MapClaims)
+	if claims[ClaimSessionID] != "sess-1" {
+		t.Errorf("sid claim = %v, want sess-1", claims[ClaimSessionID])
+	}
+
+	ctx := context.Background()
+	for k, v := range claims {
+		ctx = context.WithValue(ctx, k, v) //nolint:staticcheck // mirrors go-zero's JWT middleware
+	}
+
+	userID, err := UserIDFromContext(ctx)
+	if err != nil || userID != 42 {
+		t.Errorf("UserIDFromContext() = %d, %v, want 42", userID, err)
+	}
+	sessionID, err := SessionIDFromContext(ctx)
+	if err != nil || sessionID != "sess-1" {
+		t.Errorf("SessionIDFromContext() = %q, %v, want sess-1", sessionID, err)
+	}
+	if roles := RolesFromContext(ctx); len(roles) != 1 || roles[0] != RoleSupport {
+		t.Errorf("RolesFromContext() = %v, want [%s]", roles, RoleSupport)
+	}
+}
+
+func TestTokenVerifier_Verify(t *testing.T) {
+	issuer, _ := NewTokenIssuer("test-secret", 60)
+	token, _, err := issuer.IssueAccessToken(42, "sess-1", []string{RoleAdmin})
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+
+	verifier, err := NewTokenVerifier("test-secret")
+	if err != nil {
+		t.Fatalf("NewTokenVerifier() error = %v", err)
+	}
+
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if claims.UserID != 42 || claims.SessionID != "sess-1" || len(claims.Roles) != 1 || claims.Roles[0] != RoleAdmin {
+		t.Errorf("Verify() claims = %+v", claims)
+	}
+
+	other, _ := NewTokenVerifier("other-secret")
+	if _, err := other.Verify(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() with wrong secret error = %v, want ErrInvalidToken", err)
+	}
+	if _, err := verifier.Verify("not-a-token"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() of garbage error = %v, want ErrInvalidToken", err)
+	}
+	if _, err := NewTokenVerifier(""); err == nil {
+		t.Errorf("expected error for empty secret")
+	}
+}
+
+func TestRolesFromContext(t *testing.T) {
+	if roles := RolesFromContext(context.Background()); roles != nil {
+		t.Errorf("RolesFromContext() without claim = %v, want nil", roles)
+	}
+
+	ctx := context.WithValue(context.Background(), ClaimRoles, []interface{}{"admin", 1, ""}) //nolint:staticcheck // go-zero uses string keys
+	if roles := RolesFromContext(ctx); len(roles) != 1 || roles[0] != "admin" {
+		t.Errorf("RolesFromContext() = %v, want [admin]", roles)
+	}
+}
+
+func TestUserIDFromContext(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		name    string
+		want    int64
+		wantErr bool
+	}{
+		{name: "json number", value: json.Number("7"), want: 7},
+		{name: "int64", value: int64(8), want: 8},
+		{name: "float64", value: float64(9), want: 9},
+		{name: "missing", value: nil, wantErr: true},
+		{name: "non numeric", value: json.Number("abc"), wantErr: true},
+		{name: "zero", value: int64(0), wantErr: true},
+		{name: "wrong type", value: "7", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.value != nil {
+				ctx = context.WithValue(ctx, ClaimUserID, tt.value) //nolint:staticcheck // go-zero uses string keys
+			}
+
+			got, err := UserIDFromContext(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UserIDFromContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("UserIDFromContext() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
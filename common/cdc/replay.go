@@ -0,0 +1,76 @@
+package cdc
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aether-defense-system/common/mq"
+)
+
+// maxFlatMessageSize bounds a line of a fixture file. Canal splits large transactions over
+// several flat messages, so a line stays well below it.
+const maxFlatMessageSize = 16 << 20
+
+// Handler handles a row event. Returning an error stops a replay, and redelivers the event when it
+// came from the message queue.
+type Handler func(ctx context.Context, e *RowEvent) error
+
+// MessageHandler adapts handle to an mq.Handler for the topic Canal publishes flat messages to.
+// Messages that are not row events fail like any other handler error, so they end up in the
+// dead-letter topic.
+func MessageHandler(handle Handler) mq.Handler {
+	return func(ctx context.Context, msg *mq.Message) error {
+		e, err := ParseRowEvent(msg.Body)
+		if err != nil {
+			return fmt.Errorf("message %s: %w", msg.MsgID, err)
+		}
+		return handle(ctx, e)
+	}
+}
+
+// Replay passes the row events read from r, one flat message per line, to handle in order. Blank
+// lines and lines starting with # are skipped. It stops at the first error and returns the number
+// of events handled.
+func Replay(ctx context.Context, r io.Reader, handle Handler) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxFlatMessageSize)
+
+	handled := 0
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return handled, err
+		}
+		e, err := ParseRowEvent([]byte(text))
+		if err != nil {
+			return handled, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := handle(ctx, e); err != nil {
+			return handled, fmt.Errorf("line %d: %w", line, err)
+		}
+		handled++
+	}
+	if err := scanner.Err(); err != nil {
+		return handled, fmt.Errorf("failed to read row events: %w", err)
+	}
+	return handled, nil
+}
+
+// ReplayFile replays the row events of the fixture file at path (see Replay).
+func ReplayFile(ctx context.Context, path string, handle Handler) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open row events: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return Replay(ctx, f, handle)
+}
@@ -0,0 +1,83 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/mq"
+)
+
+const insertEvent = `{"data":[{"id":"100","user_id":"7"}],"database":"aether_trade","table":"trade_order",` +
+	`"type":"INSERT","es":1699668000000}`
+
+func TestReplay(t *testing.T) {
+	fixture := strings.Join([]string{
+		"# order 100 is placed, then paid",
+		insertEvent,
+		"",
+		updateEvent,
+	}, "\n")
+
+	var types []string
+	handled, err := Replay(context.Background(), strings.NewReader(fixture), func(_ context.Context, e *RowEvent) error {
+		types = append(types, e.Type)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, handled)
+	assert.Equal(t, []string{TypeInsert, TypeUpdate}, types)
+}
+
+func TestReplay_StopsAtFirstError(t *testing.T) {
+	fixture := insertEvent + "\n{\n" + updateEvent
+	handled, err := Replay(context.Background(), strings.NewReader(fixture), func(context.Context, *RowEvent) error {
+		return nil
+	})
+	assert.ErrorContains(t, err, "line 2")
+	assert.Equal(t, 1, handled)
+
+	handlerErr := errors.New("store unavailable")
+	handled, err = Replay(context.Background(), strings.NewReader(insertEvent), func(context.Context, *RowEvent) error {
+		return handlerErr
+	})
+	assert.ErrorIs(t, err, handlerErr)
+	assert.Zero(t, handled)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Replay(ctx, strings.NewReader(insertEvent), func(context.Context, *RowEvent) error { return nil })
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestReplayFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "binlog.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(insertEvent+"\n"+updateEvent+"\n"), 0o600))
+
+	handled, err := ReplayFile(context.Background(), path, func(context.Context, *RowEvent) error { return nil })
+	require.NoError(t, err)
+	assert.Equal(t, 2, handled)
+
+	_, err = ReplayFile(context.Background(), filepath.Join(t.TempDir(), "missing.jsonl"), nil)
+	assert.Error(t, err)
+}
+
+func TestMessageHandler(t *testing.T) {
+	var got *RowEvent
+	handler := MessageHandler(func(_ context.Context, e *RowEvent) error {
+		got = e
+		return nil
+	})
+
+	require.NoError(t, handler(context.Background(), mq.NewMessage("canal-topic", []byte(insertEvent))))
+	require.NotNil(t, got)
+	assert.Equal(t, "aether_trade.trade_order", got.Source())
+
+	assert.Error(t, handler(context.Background(), mq.NewMessage("canal-topic", []byte("not a row event"))))
+}
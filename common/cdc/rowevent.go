@@ -0,0 +1,177 @@
+// Package cdc reads the row changes MySQL writes to its binlog in the flat message format of
+// Canal, which publishes one message per binlog event to RocketMQ. Fixture files of such messages,
+// one per line, replay a binlog locally.
+package cdc
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Row event types, as Canal names them.
+const (
+	TypeInsert = "INSERT"
+	TypeUpdate = "UPDATE"
+	TypeDelete = "DELETE"
+)
+
+// datetimeLayout is how Canal prints DATETIME and TIMESTAMP columns; fractional seconds are
+// appended when the column has them.
+const datetimeLayout = "2006-01-02 15:04:05"
+
+// RowEvent is a binlog event in the flat message format of Canal.
+type RowEvent struct {
+	Database string `json:"database"`
+	Table    string `json:"table"`
+	Type     string `json:"type"`
+	// Data holds the rows after an insert or update, and before a delete.
+	Data []Columns `json:"data"`
+	// Old holds the previous values of the columns an update changed, one entry per row of Data.
+	Old     []Columns `json:"old"`
+	PKNames []string  `json:"pkNames"`
+	ID      int64     `json:"id"` // Batch ID Canal assigns
+	ES      int64     `json:"es"` // When the event was written to the binlog (unix milliseconds)
+	TS      int64     `json:"ts"` // When Canal read the event (unix milliseconds)
+	IsDDL   bool      `json:"isDdl"`
+}
+
+// ParseRowEvent decodes a flat message. DDL events are returned as they are; row events must name
+// their table, a known type and at least one row.
+func ParseRowEvent(body []byte) (*RowEvent, error) {
+	e := &RowEvent{}
+	if err := json.Unmarshal(body, e); err != nil {
+		return nil, fmt.Errorf("failed to parse row event: %w", err)
+	}
+	if e.IsDDL {
+		return e, nil
+	}
+	if e.Database == "" || e.Table == "" {
+		return nil, fmt.Errorf("row event %d does not name its table", e.ID)
+	}
+	switch e.Type {
+	case TypeInsert, TypeDelete:
+	case TypeUpdate:
+		if len(e.Old) != len(e.Data) {
+			return nil, fmt.Errorf("update of %s has %d rows but %d old values", e.Source(), len(e.Data), len(e.Old))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported row event type %q on %s", e.Type, e.Source())
+	}
+	if len(e.Data) == 0 {
+		return nil, fmt.Errorf("%s of %s has no rows", e.Type, e.Source())
+	}
+	return e, nil
+}
+
+// Source returns the database and table of the event, e.g. aether_trade.trade_order.
+func (e *RowEvent) Source() string {
+	return e.Database + "." + e.Table
+}
+
+// Rows returns the rows the event changed.
+func (e *RowEvent) Rows() []Row {
+	rows := make([]Row, 0, len(e.Data))
+	for i, columns := range e.Data {
+		row := Row{Columns: columns}
+		if i < len(e.Old) {
+			row.Old = e.Old[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// Row is one row changed by a RowEvent.
+type Row struct {
+	// Columns are the values after an insert or update, and before a delete.
+	Columns Columns
+	// Old are the previous values of the columns an update changed.
+	Old Columns
+}
+
+// ChangedColumns returns the names of the columns an update changed, sorted.
+func (r Row) ChangedColumns() []string {
+	names := make([]string, 0, len(r.Old))
+	for name := range r.Old {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Columns are the column values of a row, as MySQL prints them; NULL is nil.
+type Columns map[string]*string
+
+// value returns the value of a column that must be present and not NULL.
+func (c Columns) value(name string) (string, error) {
+	v, ok := c[name]
+	if !ok {
+		return "", fmt.Errorf("column %s is missing", name)
+	}
+	if v == nil {
+		return "", fmt.Errorf("column %s is NULL", name)
+	}
+	return *v, nil
+}
+
+// String returns a column value; NULL is the empty string.
+func (c Columns) String(name string) (string, error) {
+	v, ok := c[name]
+	if !ok {
+		return "", fmt.Errorf("column %s is missing", name)
+	}
+	if v == nil {
+		return "", nil
+	}
+	return *v, nil
+}
+
+// Int64 returns an integer column value; NULL is zero.
+func (c Columns) Int64(name string) (int64, error) {
+	v, ok := c[name]
+	if !ok {
+		return 0, fmt.Errorf("column %s is missing", name)
+	}
+	if v == nil {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(*v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("column %s is not an integer: %w", name, err)
+	}
+	return n, nil
+}
+
+// Time returns a DATETIME column value, which has no time zone, in loc; NULL is the zero time.
+func (c Columns) Time(name string, loc *time.Location) (time.Time, error) {
+	v, ok := c[name]
+	if !ok {
+		return time.Time{}, fmt.Errorf("column %s is missing", name)
+	}
+	if v == nil {
+		return time.Time{}, nil
+	}
+	layout := datetimeLayout
+	if i := strings.IndexByte(*v, '.'); i >= 0 {
+		layout += "." + strings.Repeat("0", len(*v)-i-1)
+	}
+	t, err := time.ParseInLocation(layout, *v, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("column %s is not a datetime: %w", name, err)
+	}
+	return t, nil
+}
+
+// Require checks that the columns are present and not NULL.
+func (c Columns) Require(names ...string) error {
+	for _, name := range names {
+		if _, err := c.value(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
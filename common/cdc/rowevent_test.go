@@ -0,0 +1,91 @@
+package cdc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const updateEvent = `{"data":[{"id":"100","status":"3","pay_time":"2023-11-11 10:00:05","out_trade_no":null}],` +
+	`"old":[{"status":"1","pay_time":null}],"pkNames":["id"],"database":"aether_trade","table":"trade_order",` +
+	`"type":"UPDATE","id":7,"es":1699668005000,"ts":1699668005123,"isDdl":false}`
+
+func TestParseRowEvent(t *testing.T) {
+	e, err := ParseRowEvent([]byte(updateEvent))
+	require.NoError(t, err)
+	assert.Equal(t, "aether_trade.trade_order", e.Source())
+	assert.Equal(t, TypeUpdate, e.Type)
+	assert.Equal(t, int64(1699668005000), e.ES)
+
+	rows := e.Rows()
+	require.Len(t, rows, 1)
+	assert.Equal(t, []string{"pay_time", "status"}, rows[0].ChangedColumns())
+
+	id, err := rows[0].Columns.Int64("id")
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), id)
+	outTradeNo, err := rows[0].Columns.String("out_trade_no")
+	require.NoError(t, err)
+	assert.Empty(t, outTradeNo)
+	payTime, err := rows[0].Columns.Time("pay_time", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 11, 11, 10, 0, 5, 0, time.UTC), payTime)
+
+	assert.NoError(t, rows[0].Columns.Require("id", "status"))
+	assert.Error(t, rows[0].Columns.Require("out_trade_no"), "NULL")
+	assert.Error(t, rows[0].Columns.Require("user_id"), "missing")
+}
+
+func TestParseRowEvent_DDL(t *testing.T) {
+	e, err := ParseRowEvent([]byte(`{"database":"aether_trade","table":"trade_order","type":"ALTER",` +
+		`"isDdl":true,"data":null}`))
+	require.NoError(t, err)
+	assert.True(t, e.IsDDL)
+	assert.Empty(t, e.Rows())
+}
+
+func TestParseRowEvent_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "not JSON", body: `{`},
+		{name: "no table", body: `{"database":"aether_trade","type":"INSERT","data":[{"id":"1"}]}`},
+		{name: "unknown type", body: `{"database":"d","table":"t","type":"TRUNCATE","data":[{"id":"1"}]}`},
+		{name: "no rows", body: `{"database":"d","table":"t","type":"INSERT","data":[]}`},
+		{name: "update without old values", body: `{"database":"d","table":"t","type":"UPDATE","data":[{"id":"1"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRowEvent([]byte(tt.body))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestColumns(t *testing.T) {
+	value := func(s string) *string { return &s }
+	columns := Columns{
+		"id":          value("x"),
+		"create_time": value("2023-11-11 00:00:00.123"),
+		"update_time": value("yesterday"),
+		"pay_time":    nil,
+	}
+
+	_, err := columns.Int64("id")
+	assert.Error(t, err, "not an integer")
+	_, err = columns.Int64("user_id")
+	assert.Error(t, err, "missing")
+
+	loc := time.FixedZone("UTC+8", 8*3600)
+	createTime, err := columns.Time("create_time", loc)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 11, 11, 0, 0, 0, 123e6, loc), createTime)
+	_, err = columns.Time("update_time", loc)
+	assert.Error(t, err, "not a datetime")
+	payTime, err := columns.Time("pay_time", loc)
+	require.NoError(t, err)
+	assert.True(t, payTime.IsZero())
+}
@@ -5,12 +5,17 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	_ "github.com/go-sql-driver/mysql" // MySQL driver
 )
 
+// mysqlErrDuplicateEntry is the MySQL error number of a unique or primary key violation.
+const mysqlErrDuplicateEntry = 1062
+
 // Config holds database connection configuration.
 type Config struct {
 	// Note: We mark fields as optional for go-zero conf.MustLoad.
@@ -18,6 +23,11 @@ type Config struct {
 	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
 	DSN string `json:"dsn,optional"` // Data Source Name
 
+	// Replicas are the DSNs of read replicas of DSN, with the same pool settings. Without them
+	// every query goes to the primary.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Replicas []string `json:"replicas,optional"`
+
 	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
 	MaxOpenConns int `json:"max_open_conns,optional"`
 
@@ -29,21 +39,32 @@ type Config struct {
 
 	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
 	ConnMaxIdleTime time.Duration `json:"conn_max_idle_time,optional"`
+
+	// ReplicaCheckInterval is how often replicas are pinged (default: 5s). Reads skip a replica
+	// from a failed ping until it answers again.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	ReplicaCheckInterval time.Duration `json:"replica_check_interval,optional"`
 }
 
 // DefaultConfig returns a default database configuration optimized for high concurrency.
 func DefaultConfig() *Config {
 	return &Config{
-		MaxOpenConns:    100,              // High connection pool for concurrent operations
-		MaxIdleConns:    10,               // Keep connections warm
-		ConnMaxLifetime: 30 * time.Minute, // Recycle connections periodically
-		ConnMaxIdleTime: 10 * time.Minute, // Close idle connections
+		MaxOpenConns:         100,              // High connection pool for concurrent operations
+		MaxIdleConns:         10,               // Keep connections warm
+		ConnMaxLifetime:      30 * time.Minute, // Recycle connections periodically
+		ConnMaxIdleTime:      10 * time.Minute, // Close idle connections
+		ReplicaCheckInterval: 5 * time.Second,  // Notice failed replicas quickly
 	}
 }
 
 // Client wraps the database connection with connection pool management.
+// With replicas, it also splits reads from writes: see ReadDB.
 type Client struct {
-	db *sql.DB
+	db       *sql.DB
+	stop     chan struct{} // Closed to stop checking replicas
+	replicas []*replica
+	checks   sync.WaitGroup
+	next     atomic.Uint64 // Round-robin position over replicas
 }
 
 // NewClient creates a new database client with the given configuration.
@@ -69,18 +90,14 @@ func NewClient(config *Config) (*Client, error) {
 	if config.ConnMaxIdleTime == 0 {
 		config.ConnMaxIdleTime = DefaultConfig().ConnMaxIdleTime
 	}
+	if config.ReplicaCheckInterval == 0 {
+		config.ReplicaCheckInterval = DefaultConfig().ReplicaCheckInterval
+	}
 
-	db, err := sql.Open("mysql", config.DSN)
+	db, err := openDB(config, config.DSN)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
-
-	// Configure connection pool
-	db.SetMaxOpenConns(config.MaxOpenConns)
-	db.SetMaxIdleConns(config.MaxIdleConns)
-	db.SetConnMaxLifetime(config.ConnMaxLifetime)
-	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
-
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -93,20 +110,52 @@ func NewClient(config *Config) (*Client, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &Client{db: db}, nil
+	client := &Client{db: db}
+	if err := client.startReplicas(config); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	return client, nil
 }
 
-// DB returns the underlying *sql.DB instance.
+// openDB opens dsn with the pool settings of config.
+func openDB(config *Config, dsn string) (*sql.DB, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Configure connection pool
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	return db, nil
+}
+
+// DB returns the underlying *sql.DB instance: the primary, for writes and transactions.
 func (c *Client) DB() *sql.DB {
 	return c.db
 }
 
-// Close closes the database connection.
+// Close closes the database connection, and those of the replicas.
 func (c *Client) Close() error {
+	if c.stop != nil {
+		close(c.stop)
+		c.checks.Wait()
+	}
+	var errs []error
+	for _, r := range c.replicas {
+		if err := r.db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if c.db != nil {
-		return c.db.Close()
+		if err := c.db.Close(); err != nil {
+			errs = append(errs, err)
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 // Ping checks if the database connection is alive.
@@ -135,3 +184,10 @@ func BuildDSN(user, password, host, port, dbName string, params map[string]strin
 
 	return dsn
 }
+
+// IsDuplicateKey reports whether err, or an error it wraps, is a MySQL unique or primary key
+// violation.
+func IsDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
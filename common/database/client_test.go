@@ -1,7 +1,11 @@
 package database
 
 import (
+	"errors"
+	"fmt"
 	"testing"
+
+	"github.com/go-sql-driver/mysql"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -114,3 +118,25 @@ func TestNewClient_Integration(t *testing.T) {
 	}
 }
 */
+
+func TestIsDuplicateKey(t *testing.T) {
+	duplicate := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'PRIMARY'"}
+	tests := []struct {
+		err  error
+		name string
+		want bool
+	}{
+		{name: "duplicate entry", err: duplicate, want: true},
+		{name: "wrapped", err: fmt.Errorf("failed to create order: %w", duplicate), want: true},
+		{name: "other MySQL error", err: &mysql.MySQLError{Number: 1205}, want: false},
+		{name: "other error", err: errors.New("duplicate entry"), want: false},
+		{name: "nil", err: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsDuplicateKey(tt.err); got != tt.want {
+				t.Errorf("IsDuplicateKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ConsumedMessageStore records, durably, the messages consumer groups have claimed and processed.
+type ConsumedMessageStore struct {
+	db *sql.DB
+}
+
+// NewConsumedMessageStore creates a new ConsumedMessageStore instance.
+func NewConsumedMessageStore(db *sql.DB) *ConsumedMessageStore {
+	return &ConsumedMessageStore{db: db}
+}
+
+// Claim marks a message in flight for group until the lease expires, under token. It reports
+// whether this call claimed the message and, if it did not, the status recorded by the claim that
+// holds it. An in-flight claim whose lease has expired is taken over.
+func (s *ConsumedMessageStore) Claim(
+	ctx context.Context, group, key, token string, lease time.Duration,
+) (claimed bool, status int8, err error) {
+	now := time.Now()
+	leaseUntil := now.Add(lease)
+
+	// Affects 1 row when inserted, 2 when an expired claim is taken over and 0 otherwise. The
+	// token is assigned first, while lease_until still tells whether the claim has expired.
+	result, err := s.db.ExecContext(ctx,
+		`INSERT INTO mq_consumed_message (consumer_group, message_key, status, claim_token, lease_until)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON DUPLICATE KEY UPDATE
+		   claim_token = IF(status = ? AND lease_until < ?, ?, claim_token),
+		   lease_until = IF(status = ? AND lease_until < ?, ?, lease_until)`,
+		group, key, ConsumedStatusInFlight, token, leaseUntil,
+		ConsumedStatusInFlight, now, token, ConsumedStatusInFlight, now, leaseUntil)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to claim consumed message: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected > 0 {
+		return true, ConsumedStatusInFlight, nil
+	}
+
+	err = s.db.QueryRowContext(ctx,
+		`SELECT status FROM mq_consumed_message WHERE consumer_group = ? AND message_key = ?`,
+		group, key).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Released in the meantime; report it in flight so that the delivery is retried.
+		return false, ConsumedStatusInFlight, nil
+	}
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to query consumed message: %w", err)
+	}
+	return false, status, nil
+}
+
+// Complete marks a message processed if the claim of token still holds it, and reports whether
+// it did.
+func (s *ConsumedMessageStore) Complete(ctx context.Context, group, key, token string) (bool, error) {
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE mq_consumed_message SET status = ?
+		 WHERE consumer_group = ? AND message_key = ? AND status = ? AND claim_token = ?`,
+		ConsumedStatusCompleted, group, key, ConsumedStatusInFlight, token)
+	if err != nil {
+		return false, fmt.Errorf("failed to complete consumed message: %w", err)
+	}
+	return affectedOne(result)
+}
+
+// Release drops the in-flight claim of token on a message whose processing failed, so that a
+// redelivery processes it again, and reports whether the claim still held the message.
+func (s *ConsumedMessageStore) Release(ctx context.Context, group, key, token string) (bool, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM mq_consumed_message
+		 WHERE consumer_group = ? AND message_key = ? AND status = ? AND claim_token = ?`,
+		group, key, ConsumedStatusInFlight, token)
+	if err != nil {
+		return false, fmt.Errorf("failed to release consumed message: %w", err)
+	}
+	return affectedOne(result)
+}
+
+// affectedOne reports whether a statement affected a row.
+func affectedOne(result sql.Result) (bool, error) {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// PurgeCompleted deletes the records of messages processed before the given time, once
+// redeliveries of them are no longer expected, and returns how many were deleted.
+func (s *ConsumedMessageStore) PurgeCompleted(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM mq_consumed_message WHERE status = ? AND update_time < ?`,
+		ConsumedStatusCompleted, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge consumed messages: %w", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return purged, nil
+}
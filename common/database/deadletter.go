@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxReplayReasonLen bounds dead_letter_replay.reason to its column size.
+const maxReplayReasonLen = 256
+
+// ConsumeFailureStore records why deliveries to consumer groups failed.
+type ConsumeFailureStore struct {
+	db *sql.DB
+}
+
+// NewConsumeFailureStore creates a new ConsumeFailureStore instance.
+func NewConsumeFailureStore(db *sql.DB) *ConsumeFailureStore {
+	return &ConsumeFailureStore{db: db}
+}
+
+// Record stores failure as the last failed delivery of its message to its group.
+func (s *ConsumeFailureStore) Record(ctx context.Context, failure *ConsumeFailure) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO mq_consume_failure (consumer_group, msg_id, topic, reconsume_times, reason)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON DUPLICATE KEY UPDATE topic = VALUES(topic), reconsume_times = VALUES(reconsume_times),
+		                         reason = VALUES(reason), update_time = CURRENT_TIMESTAMP`,
+		failure.ConsumerGroup, failure.MsgID, failure.Topic, failure.ReconsumeTimes, truncateError(failure.Reason))
+	if err != nil {
+		return fmt.Errorf("failed to record consume failure: %w", err)
+	}
+	return nil
+}
+
+// Get returns the recorded failures of the messages of group. Messages without a failure are
+// missing from the result.
+func (s *ConsumeFailureStore) Get(ctx context.Context, group string, msgIDs []string) ([]*ConsumeFailure, error) {
+	if len(msgIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(msgIDs)+1)
+	args = append(args, group)
+	for _, id := range msgIDs {
+		args = append(args, id)
+	}
+	query := `SELECT consumer_group, msg_id, topic, reconsume_times, reason, create_time, update_time
+	          FROM mq_consume_failure
+	          WHERE consumer_group = ? AND msg_id IN (?` + strings.Repeat(", ?", len(msgIDs)-1) + `)`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query consume failures: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var failures []*ConsumeFailure
+	for rows.Next() {
+		f := &ConsumeFailure{}
+		if err := rows.Scan(&f.ConsumerGroup, &f.MsgID, &f.Topic, &f.ReconsumeTimes, &f.Reason,
+			&f.CreateTime, &f.UpdateTime); err != nil {
+			return nil, fmt.Errorf("failed to scan consume failure: %w", err)
+		}
+		failures = append(failures, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate consume failures: %w", err)
+	}
+	return failures, nil
+}
+
+// Purge deletes the failures last recorded before the given time, once the broker no longer keeps
+// the messages, and returns how many were deleted.
+func (s *ConsumeFailureStore) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM mq_consume_failure WHERE update_time < ?`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge consume failures: %w", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return purged, nil
+}
+
+// DeadLetterReplayStore keeps the audit log of dead-letter replays.
+type DeadLetterReplayStore struct {
+	db *sql.DB
+}
+
+// NewDeadLetterReplayStore creates a new DeadLetterReplayStore instance.
+func NewDeadLetterReplayStore(db *sql.DB) *DeadLetterReplayStore {
+	return &DeadLetterReplayStore{db: db}
+}
+
+// Insert records replay and sets its ID.
+func (s *DeadLetterReplayStore) Insert(ctx context.Context, replay *DeadLetterReplay) error {
+	var replayErr *string
+	if replay.Error != nil {
+		truncated := truncateError(*replay.Error)
+		replayErr = &truncated
+	}
+	reason := replay.Reason
+	if len(reason) > maxReplayReasonLen {
+		reason = strings.ToValidUTF8(reason[:maxReplayReasonLen], "")
+	}
+
+	result, err := s.db.ExecContext(ctx,
+		`INSERT INTO dead_letter_replay
+		 (consumer_group, msg_id, event_id, event_type, target_topic, dry_run, new_msg_id, operator, reason, error)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		replay.ConsumerGroup, replay.MsgID, replay.EventID, replay.EventType, replay.TargetTopic,
+		replay.DryRun, replay.NewMsgID, replay.Operator, reason, replayErr)
+	if err != nil {
+		return fmt.Errorf("failed to record dead letter replay: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get dead letter replay id: %w", err)
+	}
+	replay.ID = id
+	return nil
+}
+
+// ListByMessage returns the replays of a dead-lettered message, oldest first.
+func (s *DeadLetterReplayStore) ListByMessage(
+	ctx context.Context, group, msgID string,
+) ([]*DeadLetterReplay, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, consumer_group, msg_id, event_id, event_type, target_topic, dry_run, new_msg_id,
+		        operator, reason, error, create_time
+		 FROM dead_letter_replay
+		 WHERE consumer_group = ? AND msg_id = ?
+		 ORDER BY id`, group, msgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letter replays: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var replays []*DeadLetterReplay
+	for rows.Next() {
+		r := &DeadLetterReplay{}
+		if err := rows.Scan(&r.ID, &r.ConsumerGroup, &r.MsgID, &r.EventID, &r.EventType, &r.TargetTopic,
+			&r.DryRun, &r.NewMsgID, &r.Operator, &r.Reason, &r.Error, &r.CreateTime); err != nil {
+			return nil, fmt.Errorf("failed to scan dead letter replay: %w", err)
+		}
+		replays = append(replays, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate dead letter replays: %w", err)
+	}
+	return replays, nil
+}
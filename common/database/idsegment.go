@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownBizTag is returned when allocating a segment for a business tag without an id_segment row.
+var ErrUnknownBizTag = errors.New("unknown ID segment business tag")
+
+// IDSegmentStore hands out ranges of IDs from the id_segment table.
+type IDSegmentStore struct {
+	db *sql.DB
+}
+
+// NewIDSegmentStore creates a new IDSegmentStore instance.
+func NewIDSegmentStore(db *sql.DB) *IDSegmentStore {
+	return &IDSegmentStore{db: db}
+}
+
+// Allocate reserves the next segment of bizTag: the IDs after the previous MaxID up to and
+// including the returned MaxID. The row lock of the update serializes concurrent allocations, so
+// every segment is handed out once.
+func (s *IDSegmentStore) Allocate(ctx context.Context, bizTag string) (segment *IDSegment, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE id_segment SET max_id = max_id + step WHERE biz_tag = ?`, bizTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to advance ID segment: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		err = fmt.Errorf("%w: %s", ErrUnknownBizTag, bizTag)
+		return nil, err
+	}
+
+	segment = &IDSegment{}
+	err = tx.QueryRowContext(ctx,
+		`SELECT biz_tag, max_id, step, description, update_time FROM id_segment WHERE biz_tag = ?`,
+		bizTag).Scan(&segment.BizTag, &segment.MaxID, &segment.Step, &segment.Description, &segment.UpdateTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ID segment: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return segment, nil
+}
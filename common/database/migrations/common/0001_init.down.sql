@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `id_segment`;
+DROP TABLE IF EXISTS `dead_letter_replay`;
+DROP TABLE IF EXISTS `mq_consume_failure`;
+DROP TABLE IF EXISTS `mq_consumed_message`;
@@ -0,0 +1,60 @@
+-- Consumed message table
+-- Durable deduplication of message redeliveries, per consumer group.
+CREATE TABLE IF NOT EXISTS `mq_consumed_message` (
+  `consumer_group` VARCHAR(128) NOT NULL COMMENT 'Consumer group',
+  `message_key` VARCHAR(128) NOT NULL COMMENT 'Deduplication key, usually the event ID',
+  `status` TINYINT NOT NULL DEFAULT 0 COMMENT 'Status: 0=In flight, 1=Completed',
+  `lease_until` DATETIME(3) NOT NULL COMMENT 'An in-flight claim expires after this time',
+  `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`consumer_group`, `message_key`),
+  KEY `idx_status_update_time` (`status`, `update_time`) COMMENT 'Purge index'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Consumed message table';
+
+-- Consume failure table
+-- The last failed delivery of each message per consumer group; brokers do not keep the reason.
+CREATE TABLE IF NOT EXISTS `mq_consume_failure` (
+  `consumer_group` VARCHAR(128) NOT NULL COMMENT 'Consumer group',
+  `msg_id` VARCHAR(128) NOT NULL COMMENT 'Message ID, unchanged by redelivery and dead-lettering',
+  `topic` VARCHAR(128) NOT NULL COMMENT 'Topic the message was delivered from',
+  `reconsume_times` INT NOT NULL DEFAULT 0 COMMENT 'Failed deliveries before the last one',
+  `reason` VARCHAR(512) NOT NULL COMMENT 'Error of the last failed delivery',
+  `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Time of the last failure',
+  PRIMARY KEY (`consumer_group`, `msg_id`),
+  KEY `idx_update_time` (`update_time`) COMMENT 'Purge index'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Consume failure table';
+
+-- Dead letter replay table
+-- Audit log of dead-lettered messages sent back to their topic or to a sandbox topic.
+CREATE TABLE IF NOT EXISTS `dead_letter_replay` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `consumer_group` VARCHAR(128) NOT NULL COMMENT 'Consumer group that dead-lettered the message',
+  `msg_id` VARCHAR(128) NOT NULL COMMENT 'Dead-lettered message ID',
+  `event_id` VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'Event ID, empty when the message carries no event',
+  `event_type` VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'Event type',
+  `target_topic` VARCHAR(128) NOT NULL COMMENT 'Topic the message was replayed to',
+  `dry_run` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '1=Replayed to the sandbox topic',
+  `new_msg_id` VARCHAR(128) NOT NULL DEFAULT '' COMMENT 'ID of the replayed message, empty on failure',
+  `operator` VARCHAR(64) NOT NULL COMMENT 'Who replayed the message',
+  `reason` VARCHAR(256) NOT NULL DEFAULT '' COMMENT 'Why the message was replayed',
+  `error` VARCHAR(512) DEFAULT NULL COMMENT 'Replay error',
+  `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_group_msg` (`consumer_group`, `msg_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Dead letter replay audit table';
+
+-- ID segment table
+-- Dense, increasing IDs handed out a segment at a time, per business tag.
+CREATE TABLE IF NOT EXISTS `id_segment` (
+  `biz_tag` VARCHAR(64) NOT NULL COMMENT 'Business tag, e.g. trade_refund',
+  `max_id` BIGINT NOT NULL DEFAULT 0 COMMENT 'Last ID of the last segment handed out',
+  `step` INT NOT NULL DEFAULT 1000 COMMENT 'Size of a segment',
+  `description` VARCHAR(256) NOT NULL DEFAULT '' COMMENT 'What the IDs identify',
+  `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`biz_tag`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='ID segment table';
+
+INSERT IGNORE INTO `id_segment` (`biz_tag`, `max_id`, `step`, `description`)
+VALUES ('trade_refund', 0, 1000, 'Refund numbers, when trade RefundIDs.BizTag selects this tag');
@@ -0,0 +1 @@
+ALTER TABLE `mq_consumed_message` DROP COLUMN `claim_token`;
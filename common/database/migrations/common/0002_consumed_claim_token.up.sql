@@ -0,0 +1,6 @@
+-- Claim tokens. Each claim of a consumed message records a random token, and only the delivery
+-- holding it may complete or release the message: a delivery whose lease expired and was taken
+-- over can no longer complete or release the claim of the delivery that took over.
+
+ALTER TABLE `mq_consumed_message`
+  ADD COLUMN `claim_token` VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'Token of the delivery holding the claim' AFTER `status`;
@@ -0,0 +1,74 @@
+// Package migrations embeds the schema migrations of the system, one set per directory:
+//
+//   - common: tables of the shared packages (message consumption, dead letters, ID segments)
+//   - trade: orders, refunds, the outbox and the order archive, in the trade database or every
+//     sharding database
+//   - promotion: coupon records, in the promotion database or every sharding database
+//   - user: users and their roles
+//   - reporting: orders of every shard for admin queries, in the reporting database of the CDC service
+//
+// Migrations are never edited once released: a schema change is a new pair of files with the
+// next version, e.g. trade/0002_add_order_remark.up.sql and its down file.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/aether-defense-system/common/migrate"
+)
+
+// Names of the migration sets.
+const (
+	Common    = "common"
+	Trade     = "trade"
+	Promotion = "promotion"
+	User      = "user"
+	Reporting = "reporting"
+)
+
+// ShardedTables lists the sharded tables of each set, by set; sets without any run unsharded.
+var ShardedTables = map[string][]string{
+	Trade: {
+		"trade_order", "trade_order_item", "trade_refund", "trade_refund_item",
+		"trade_order_archive", "trade_order_item_archive",
+	},
+	Promotion: {"promotion_coupon_record"},
+}
+
+//go:embed */*.sql
+var files embed.FS
+
+// Load returns the migration set name.
+func Load(name string) (*migrate.Set, error) {
+	sub, err := fs.Sub(files, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migration set %s: %w", name, err)
+	}
+	set, err := migrate.LoadSet(name, sub)
+	if err != nil {
+		return nil, err
+	}
+	if len(set.Migrations) == 0 {
+		return nil, fmt.Errorf("unknown migration set %q", name)
+	}
+	return set, nil
+}
+
+// Up applies the pending migrations of the sets names, in order, with each of migrators.
+func Up(ctx context.Context, migrators []*migrate.Migrator, names ...string) error {
+	for _, name := range names {
+		set, err := Load(name)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrators {
+			if _, err := m.Up(ctx, set); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	for _, name := range []string{Common, Trade, Promotion, User, Reporting} {
+		set, err := Load(name)
+		if err != nil {
+			t.Fatalf("failed to load %s: %v", name, err)
+		}
+		for _, m := range set.Migrations {
+			if m.Down == "" {
+				t.Errorf("expected %s/%d_%s to be reversible", name, m.Version, m.Name)
+			}
+		}
+	}
+
+	if _, err := Load("billing"); err == nil {
+		t.Errorf("expected an unknown set to fail")
+	}
+}
+
+func TestShardedTables(t *testing.T) {
+	for name, tables := range ShardedTables {
+		set, err := Load(name)
+		if err != nil {
+			t.Fatalf("failed to load %s: %v", name, err)
+		}
+		var up strings.Builder
+		for _, m := range set.Migrations {
+			up.WriteString(m.Up)
+		}
+		for _, table := range tables {
+			if !strings.Contains(up.String(), "CREATE TABLE IF NOT EXISTS `"+table+"`") {
+				t.Errorf("expected %s to create the sharded table %s", name, table)
+			}
+		}
+	}
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `promotion_coupon_record`;
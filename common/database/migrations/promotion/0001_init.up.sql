@@ -0,0 +1,18 @@
+-- Promotion tables. With sharding configured, this set also runs on every sharding database,
+-- where promotion_coupon_record is created once per shard, e.g. promotion_coupon_record_07.
+
+-- Coupon record table
+CREATE TABLE IF NOT EXISTS `promotion_coupon_record` (
+  `id` BIGINT NOT NULL COMMENT 'Primary key, Snowflake algorithm ID',
+  `user_id` BIGINT NOT NULL COMMENT 'User ID, sharding key',
+  `template_id` BIGINT NOT NULL COMMENT 'Coupon template ID',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT '1=Unused, 2=Used, 3=Expired',
+  `use_time` DATETIME DEFAULT NULL COMMENT 'Usage time',
+  `order_id` BIGINT DEFAULT NULL COMMENT 'Associated order ID',
+  `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uniq_user_template` (`user_id`, `template_id`) COMMENT 'Limit: one coupon per user per template (optional)',
+  KEY `idx_user_status` (`user_id`, `status`),
+  KEY `idx_order` (`order_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Coupon record table';
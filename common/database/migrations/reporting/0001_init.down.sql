@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `report_order_item`;
+DROP TABLE IF EXISTS `report_order`;
@@ -0,0 +1,60 @@
+-- Reporting tables, in the reporting database the CDC service keeps: every order and order item
+-- of every trade shard, as last captured from the binlog, for admin queries across users.
+--
+-- Both tables are partitioned by month of create_time, the dimension admin queries filter on, so
+-- that a query of a day or a month reads one partition. Rows older than the first monthly
+-- partition go to p0. The CDC service splits pmax into the monthly partitions of the coming
+-- months as time passes (OrderReportStore.EnsurePartitions).
+--
+-- Every row records the binlog time of the change it holds (committed_at): changes delivered
+-- out of order or twice never overwrite a later one. Deleted rows are kept, flagged deleted.
+
+-- Reported orders
+CREATE TABLE IF NOT EXISTS `report_order` (
+  `id` BIGINT NOT NULL COMMENT 'Order ID',
+  `create_time` DATETIME NOT NULL COMMENT 'Order creation time, partitioning key',
+  `user_id` BIGINT NOT NULL COMMENT 'User ID',
+  `status` TINYINT NOT NULL COMMENT 'Status: 1=Pending Payment, 2=Closed, 3=Paid, 4=Finished, 5=Refunded',
+  `total_amount` INT NOT NULL COMMENT 'Total order amount in cents',
+  `pay_amount` INT NOT NULL COMMENT 'Actual payment amount in cents',
+  `pay_channel` TINYINT DEFAULT NULL COMMENT 'Payment channel: 1=Alipay, 2=WeChat',
+  `out_trade_no` VARCHAR(64) DEFAULT NULL COMMENT 'Third-party payment transaction number',
+  `pay_time` DATETIME DEFAULT NULL COMMENT 'Payment success time',
+  `update_time` DATETIME NOT NULL COMMENT 'Last update time of the order',
+  `version` INT NOT NULL COMMENT 'Optimistic lock version number of the order',
+  `deleted` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '1 once the order was deleted from the trade database',
+  `source` VARCHAR(128) NOT NULL COMMENT 'Database and table the order was captured from',
+  `committed_at` BIGINT NOT NULL COMMENT 'Binlog time of the last applied change (unix milliseconds)',
+  PRIMARY KEY (`id`, `create_time`),
+  KEY `idx_create_time` (`create_time`, `id`) COMMENT 'Admin queries by creation time, keyset pagination on (create_time, id)',
+  KEY `idx_status_create_time` (`status`, `create_time`),
+  KEY `idx_user_create_time` (`user_id`, `create_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Orders of all shards, partitioned by create_time'
+PARTITION BY RANGE (TO_DAYS(`create_time`)) (
+  PARTITION `p0` VALUES LESS THAN (TO_DAYS('2023-01-01')),
+  PARTITION `pmax` VALUES LESS THAN MAXVALUE
+);
+
+-- Reported order items
+CREATE TABLE IF NOT EXISTS `report_order_item` (
+  `id` BIGINT NOT NULL COMMENT 'Order item ID',
+  `create_time` DATETIME NOT NULL COMMENT 'Order item creation time, partitioning key',
+  `order_id` BIGINT NOT NULL COMMENT 'Order ID',
+  `user_id` BIGINT NOT NULL COMMENT 'User ID',
+  `course_id` BIGINT NOT NULL COMMENT 'Course ID',
+  `course_name` VARCHAR(128) NOT NULL COMMENT 'Course name at purchase time',
+  `price` INT NOT NULL COMMENT 'Unit price at purchase time in cents',
+  `real_pay_amount` INT NOT NULL COMMENT 'Actual payment allocation amount in cents',
+  `refund_status` TINYINT NOT NULL COMMENT 'Refund status: 0=None, 1=Refunding, 2=Refunded',
+  `update_time` DATETIME NOT NULL COMMENT 'Last update time of the order item',
+  `deleted` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '1 once the item was deleted from the trade database',
+  `source` VARCHAR(128) NOT NULL COMMENT 'Database and table the item was captured from',
+  `committed_at` BIGINT NOT NULL COMMENT 'Binlog time of the last applied change (unix milliseconds)',
+  PRIMARY KEY (`id`, `create_time`),
+  KEY `idx_order` (`order_id`),
+  KEY `idx_course_create_time` (`course_id`, `create_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Order items of all shards, partitioned by create_time'
+PARTITION BY RANGE (TO_DAYS(`create_time`)) (
+  PARTITION `p0` VALUES LESS THAN (TO_DAYS('2023-01-01')),
+  PARTITION `pmax` VALUES LESS THAN MAXVALUE
+);
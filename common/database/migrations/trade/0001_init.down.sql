@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS `outbox_event`;
+DROP TABLE IF EXISTS `trade_refund_item`;
+DROP TABLE IF EXISTS `trade_refund`;
+DROP TABLE IF EXISTS `trade_order_item`;
+DROP TABLE IF EXISTS `trade_order`;
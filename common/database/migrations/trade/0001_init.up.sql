@@ -0,0 +1,89 @@
+-- Trade tables. With sharding configured, this set also runs on every sharding database:
+-- trade_order, trade_order_item, trade_refund and trade_refund_item are created once per shard
+-- with the shard number as suffix, e.g. trade_order_07 for user_id % 32 = 7, next to a single
+-- outbox_event table per database.
+
+-- Order main table
+CREATE TABLE IF NOT EXISTS `trade_order` (
+  `id` BIGINT NOT NULL COMMENT 'Primary key, Snowflake algorithm ID',
+  `user_id` BIGINT NOT NULL COMMENT 'User ID, sharding key',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT 'Status: 1=Pending Payment, 2=Closed, 3=Paid, 4=Finished, 5=Refunded',
+  `total_amount` INT NOT NULL COMMENT 'Total order amount in cents',
+  `pay_amount` INT NOT NULL COMMENT 'Actual payment amount in cents',
+  `pay_channel` TINYINT DEFAULT NULL COMMENT 'Payment channel: 1=Alipay, 2=WeChat',
+  `out_trade_no` VARCHAR(64) DEFAULT NULL COMMENT 'Third-party payment transaction number',
+  `pay_time` DATETIME DEFAULT NULL COMMENT 'Payment success time',
+  `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  `version` INT NOT NULL DEFAULT 0 COMMENT 'Optimistic lock version number',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_status` (`user_id`, `status`, `create_time`) COMMENT 'Client-side query index, also serves keyset pagination on (create_time, id)',
+  KEY `idx_out_trade_no` (`out_trade_no`) COMMENT 'Payment callback idempotency index'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Order main table';
+
+-- Order items table
+CREATE TABLE IF NOT EXISTS `trade_order_item` (
+  `id` BIGINT NOT NULL COMMENT 'Primary key, Snowflake algorithm ID',
+  `order_id` BIGINT NOT NULL COMMENT 'Order ID',
+  `user_id` BIGINT NOT NULL COMMENT 'Redundant field for sharding binding',
+  `course_id` BIGINT NOT NULL COMMENT 'Course ID',
+  `course_name` VARCHAR(128) NOT NULL COMMENT 'Snapshot: course name at purchase time',
+  `price` INT NOT NULL COMMENT 'Snapshot: unit price at purchase time in cents',
+  `real_pay_amount` INT NOT NULL COMMENT 'Actual payment allocation amount in cents',
+  `refund_status` TINYINT NOT NULL DEFAULT 0 COMMENT 'Refund status: 0=None, 1=Refunding, 2=Refunded',
+  `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_order` (`order_id`),
+  KEY `idx_user` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Order items table';
+
+-- Refund table
+CREATE TABLE IF NOT EXISTS `trade_refund` (
+  `id` BIGINT NOT NULL COMMENT 'Primary key, Snowflake algorithm ID',
+  `order_id` BIGINT NOT NULL COMMENT 'Refunded order ID',
+  `user_id` BIGINT NOT NULL COMMENT 'Redundant field for sharding binding',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT 'Status: 1=Requested, 2=Approved, 3=Succeeded, 4=Failed',
+  `amount` INT NOT NULL COMMENT 'Refund amount in cents, sum of the items'' real_pay_amount',
+  `reason` VARCHAR(256) NOT NULL DEFAULT '' COMMENT 'Refund reason given by the user',
+  `out_refund_no` VARCHAR(64) DEFAULT NULL COMMENT 'Third-party refund transaction number',
+  `fail_reason` VARCHAR(256) DEFAULT NULL COMMENT 'Payment gateway failure reason',
+  `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_order` (`order_id`),
+  KEY `idx_user` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Refund table';
+
+-- Refund items table
+CREATE TABLE IF NOT EXISTS `trade_refund_item` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `refund_id` BIGINT NOT NULL COMMENT 'Refund ID',
+  `order_item_id` BIGINT NOT NULL COMMENT 'Refunded order item ID',
+  `course_id` BIGINT NOT NULL COMMENT 'Course ID, used to restore inventory',
+  `amount` INT NOT NULL COMMENT 'Refunded amount in cents, equals the item''s real_pay_amount',
+  `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_refund` (`refund_id`),
+  KEY `idx_order_item` (`order_item_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Refund items table';
+
+-- Transactional outbox table
+-- Rows are written in the transaction that writes the business rows they announce, and published by a relay.
+CREATE TABLE IF NOT EXISTS `outbox_event` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT COMMENT 'Publication order',
+  `aggregate_type` VARCHAR(32) NOT NULL COMMENT 'Aggregate type, e.g. order',
+  `aggregate_id` VARCHAR(64) NOT NULL COMMENT 'Aggregate ID; events of one aggregate are published in order',
+  `topic` VARCHAR(128) NOT NULL COMMENT 'Destination topic',
+  `tag` VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'Message tag',
+  `msg_keys` VARCHAR(256) NOT NULL DEFAULT '' COMMENT 'Message keys, space separated',
+  `properties` TEXT NOT NULL COMMENT 'Message properties as a JSON object (trace context, request ID)',
+  `payload` BLOB NOT NULL COMMENT 'Message body',
+  `status` TINYINT NOT NULL DEFAULT 0 COMMENT 'Status: 0=Pending, 1=Published',
+  `attempts` INT NOT NULL DEFAULT 0 COMMENT 'Failed publication attempts',
+  `last_error` VARCHAR(512) DEFAULT NULL COMMENT 'Last publication error',
+  `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `publish_time` DATETIME DEFAULT NULL COMMENT 'Publication time',
+  PRIMARY KEY (`id`),
+  KEY `idx_status_id` (`status`, `id`) COMMENT 'Relay polling index'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Transactional outbox table';
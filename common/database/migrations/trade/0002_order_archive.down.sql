@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `trade_archive_checkpoint`;
+ALTER TABLE `trade_order` DROP KEY `idx_create_time`;
+DROP TABLE IF EXISTS `trade_order_item_archive`;
+DROP TABLE IF EXISTS `trade_order_archive`;
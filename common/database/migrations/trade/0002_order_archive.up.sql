@@ -0,0 +1,52 @@
+-- Order archive. Closed, finished and refunded orders past the retention period move here with
+-- their items, in batches, so that trade_order stays small. With sharding configured, the archive
+-- tables are sharded like the tables they archive, e.g. trade_order_archive_07, next to a single
+-- checkpoint table per database.
+
+-- Archived orders
+CREATE TABLE IF NOT EXISTS `trade_order_archive` (
+  `id` BIGINT NOT NULL COMMENT 'Order ID',
+  `user_id` BIGINT NOT NULL COMMENT 'User ID, sharding key',
+  `status` TINYINT NOT NULL COMMENT 'Status: 2=Closed, 4=Finished, 5=Refunded',
+  `total_amount` INT NOT NULL COMMENT 'Total order amount in cents',
+  `pay_amount` INT NOT NULL COMMENT 'Actual payment amount in cents',
+  `pay_channel` TINYINT DEFAULT NULL COMMENT 'Payment channel: 1=Alipay, 2=WeChat',
+  `out_trade_no` VARCHAR(64) DEFAULT NULL COMMENT 'Third-party payment transaction number',
+  `pay_time` DATETIME DEFAULT NULL COMMENT 'Payment success time',
+  `create_time` DATETIME NOT NULL,
+  `update_time` DATETIME NOT NULL,
+  `version` INT NOT NULL DEFAULT 0 COMMENT 'Optimistic lock version number when archived',
+  `archive_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_user_create` (`user_id`, `create_time`) COMMENT 'Serves keyset pagination of a user''s orders'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Archived orders';
+
+-- Items of archived orders
+CREATE TABLE IF NOT EXISTS `trade_order_item_archive` (
+  `id` BIGINT NOT NULL COMMENT 'Order item ID',
+  `order_id` BIGINT NOT NULL COMMENT 'Order ID',
+  `user_id` BIGINT NOT NULL COMMENT 'Redundant field for sharding binding',
+  `course_id` BIGINT NOT NULL COMMENT 'Course ID',
+  `course_name` VARCHAR(128) NOT NULL COMMENT 'Snapshot: course name at purchase time',
+  `price` INT NOT NULL COMMENT 'Snapshot: unit price at purchase time in cents',
+  `real_pay_amount` INT NOT NULL COMMENT 'Actual payment allocation amount in cents',
+  `refund_status` TINYINT NOT NULL DEFAULT 0 COMMENT 'Refund status: 0=None, 1=Refunding, 2=Refunded',
+  `create_time` DATETIME NOT NULL,
+  `update_time` DATETIME NOT NULL,
+  `archive_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_order` (`order_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Items of archived orders';
+
+-- Archival scans orders by creation time.
+ALTER TABLE `trade_order` ADD KEY `idx_create_time` (`create_time`) COMMENT 'Archival scan index';
+
+-- Archival progress, one row per shard of the database
+CREATE TABLE IF NOT EXISTS `trade_archive_checkpoint` (
+  `shard` INT NOT NULL COMMENT 'Shard number, 0 when unsharded',
+  `last_create_time` DATETIME DEFAULT NULL COMMENT 'Creation time of the last order scanned by the current pass, NULL between passes',
+  `last_order_id` BIGINT NOT NULL DEFAULT 0 COMMENT 'ID of the last order scanned by the current pass',
+  `archived_orders` BIGINT NOT NULL DEFAULT 0 COMMENT 'Orders archived from the shard so far',
+  `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`shard`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='Order archival checkpoints';
@@ -0,0 +1,6 @@
+ALTER TABLE `outbox_event`
+  DROP KEY `idx_status_publish_time`,
+  DROP COLUMN `retry_time`,
+  DROP COLUMN `claim_time`,
+  DROP COLUMN `claim_owner`,
+  MODIFY COLUMN `status` TINYINT NOT NULL DEFAULT 0 COMMENT 'Status: 0=Pending, 1=Published';
@@ -0,0 +1,12 @@
+-- Outbox leases. A relay claims events by writing its owner token and the claim time in a short
+-- transaction, publishes them without holding row locks, and records the results in a second one.
+-- A claim that is not recorded within the lease, e.g. because the relay died, expires and the
+-- events are claimed again. A failed event is retried after a delay, and set aside as failed once
+-- it has used up its attempts. Published events are purged after a retention period.
+
+ALTER TABLE `outbox_event`
+  MODIFY COLUMN `status` TINYINT NOT NULL DEFAULT 0 COMMENT 'Status: 0=Pending, 1=Published, 2=Failed (attempts used up)',
+  ADD COLUMN `claim_owner` VARCHAR(64) DEFAULT NULL COMMENT 'Token of the claim holding the lease, NULL when unclaimed' AFTER `last_error`,
+  ADD COLUMN `claim_time` DATETIME(3) DEFAULT NULL COMMENT 'Start of the lease' AFTER `claim_owner`,
+  ADD COLUMN `retry_time` DATETIME(3) DEFAULT NULL COMMENT 'Earliest time of the next attempt after a failure' AFTER `claim_time`,
+  ADD KEY `idx_status_publish_time` (`status`, `publish_time`) COMMENT 'Retention purge index';
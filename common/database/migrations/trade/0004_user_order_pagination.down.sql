@@ -0,0 +1,8 @@
+ALTER TABLE `trade_order_archive`
+  DROP KEY `idx_user_create_time`,
+  ADD KEY `idx_user_create` (`user_id`, `create_time`) COMMENT 'Serves keyset pagination of a user''s orders';
+
+ALTER TABLE `trade_order`
+  DROP KEY `idx_user_create_time`,
+  DROP KEY `idx_user_status`,
+  ADD KEY `idx_user_status` (`user_id`, `status`, `create_time`) COMMENT 'Client-side query index, also serves keyset pagination on (create_time, id)';
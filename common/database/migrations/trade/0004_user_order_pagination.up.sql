@@ -0,0 +1,14 @@
+-- Pagination of a user's orders. The orders of a user are listed newest first with a keyset
+-- cursor on (create_time, id), with or without a status. idx_user_status seeks on the cursor only
+-- when a status is given: without one, MySQL reads and sorts every order of the user on every
+-- page. idx_user_create_time serves the listing of all statuses, in trade_order and in
+-- trade_order_archive alike. The comment of idx_user_status is corrected to match.
+
+ALTER TABLE `trade_order`
+  DROP KEY `idx_user_status`,
+  ADD KEY `idx_user_status` (`user_id`, `status`, `create_time`) COMMENT 'Client-side query index by status, also serves keyset pagination of a status on (create_time, id)',
+  ADD KEY `idx_user_create_time` (`user_id`, `create_time`, `id`) COMMENT 'Keyset pagination of a user''s orders on (create_time, id)';
+
+ALTER TABLE `trade_order_archive`
+  DROP KEY `idx_user_create`,
+  ADD KEY `idx_user_create_time` (`user_id`, `create_time`, `id`) COMMENT 'Keyset pagination of a user''s archived orders on (create_time, id)';
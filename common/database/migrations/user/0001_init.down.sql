@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `user_role`;
+DROP TABLE IF EXISTS `user`;
@@ -0,0 +1,26 @@
+-- User table
+CREATE TABLE IF NOT EXISTS `user` (
+  `id` BIGINT NOT NULL COMMENT 'Primary key, Snowflake algorithm ID',
+  `username` VARCHAR(64) NOT NULL COMMENT 'Username',
+  `mobile` VARCHAR(20) NOT NULL COMMENT 'Mobile phone number',
+  `email` VARCHAR(128) DEFAULT NULL COMMENT 'Email address',
+  `avatar` VARCHAR(256) DEFAULT NULL COMMENT 'Avatar URL',
+  `status` TINYINT NOT NULL DEFAULT 1 COMMENT 'Status: 1=Normal, 2=Banned',
+  `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `update_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uniq_mobile` (`mobile`),
+  UNIQUE KEY `uniq_username` (`username`),
+  KEY `idx_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='User table';
+
+-- User role assignment table
+-- Roles map to permissions in code (common/auth.Policy); this table only records who holds which role.
+CREATE TABLE IF NOT EXISTS `user_role` (
+  `id` BIGINT NOT NULL AUTO_INCREMENT,
+  `user_id` BIGINT NOT NULL COMMENT 'User ID',
+  `role` VARCHAR(32) NOT NULL COMMENT 'Role code: admin, operator, support',
+  `create_time` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uniq_user_role` (`user_id`, `role`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_general_ci COMMENT='User role assignment table';
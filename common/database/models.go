@@ -31,10 +31,39 @@ type TradeOrderItem struct {
 	CourseName    string    `db:"course_name"`
 	Price         int32     `db:"price"`           // Amount in cents
 	RealPayAmount int32     `db:"real_pay_amount"` // Amount in cents
+	RefundStatus  int8      `db:"refund_status"`   // ItemRefundStatus: 0=None, 1=Refunding, 2=Refunded
 	CreateTime    time.Time `db:"create_time"`
 	UpdateTime    time.Time `db:"update_time"`
 }
 
+// TradeRefund represents the trade_refund table.
+//
+//nolint:govet // Field order optimized for logical grouping
+type TradeRefund struct {
+	ID          int64     `db:"id"`
+	OrderID     int64     `db:"order_id"`
+	UserID      int64     `db:"user_id"`
+	Status      int8      `db:"status"` // RefundStatus: 1=Requested, 2=Approved, 3=Succeeded, 4=Failed
+	Amount      int32     `db:"amount"` // Amount in cents
+	Reason      string    `db:"reason"`
+	OutRefundNo *string   `db:"out_refund_no"`
+	FailReason  *string   `db:"fail_reason"`
+	CreateTime  time.Time `db:"create_time"`
+	UpdateTime  time.Time `db:"update_time"`
+}
+
+// TradeRefundItem represents the trade_refund_item table.
+//
+//nolint:govet // Field order optimized for logical grouping
+type TradeRefundItem struct {
+	ID          int64     `db:"id"`
+	RefundID    int64     `db:"refund_id"`
+	OrderItemID int64     `db:"order_item_id"`
+	CourseID    int64     `db:"course_id"`
+	Amount      int32     `db:"amount"` // Amount in cents
+	CreateTime  time.Time `db:"create_time"`
+}
+
 // PromotionCouponRecord represents the promotion_coupon_record table.
 //
 //nolint:govet // Field order optimized for logical grouping
@@ -63,6 +92,139 @@ type User struct {
 	UpdateTime time.Time `db:"update_time"`
 }
 
+// UserRole represents the user_role table.
+//
+//nolint:govet // Field order optimized for logical grouping
+type UserRole struct {
+	ID         int64     `db:"id"`
+	UserID     int64     `db:"user_id"`
+	Role       string    `db:"role"` // Role code, see common/auth
+	CreateTime time.Time `db:"create_time"`
+}
+
+// OutboxEvent represents the outbox_event table: a message written in the same transaction as the
+// business rows it announces, and published by a relay afterwards.
+//
+//nolint:govet // Field order optimized for logical grouping
+type OutboxEvent struct {
+	ID            int64      `db:"id"` // Auto-increment; events are published in id order
+	AggregateType string     `db:"aggregate_type"`
+	AggregateID   string     `db:"aggregate_id"` // Events of one aggregate are published in order
+	Topic         string     `db:"topic"`
+	Tag           string     `db:"tag"`
+	Keys          string     `db:"msg_keys"`   // Message keys, space separated
+	Properties    string     `db:"properties"` // Message properties as a JSON object
+	Payload       []byte     `db:"payload"`
+	Status        int8       `db:"status"` // OutboxStatus: 0=Pending, 1=Published, 2=Failed
+	Attempts      int32      `db:"attempts"`
+	LastError     *string    `db:"last_error"`
+	ClaimOwner    *string    `db:"claim_owner"` // Token of the claim holding the lease
+	ClaimTime     *time.Time `db:"claim_time"`  // Start of the lease
+	RetryTime     *time.Time `db:"retry_time"`  // Earliest time of the next attempt after a failure
+	CreateTime    time.Time  `db:"create_time"`
+	PublishTime   *time.Time `db:"publish_time"`
+}
+
+// ConsumedMessage represents the mq_consumed_message table: a message a consumer group has
+// claimed or processed, kept for deduplication of redeliveries.
+//
+//nolint:govet // Field order optimized for logical grouping
+type ConsumedMessage struct {
+	ConsumerGroup string    `db:"consumer_group"`
+	MessageKey    string    `db:"message_key"`
+	Status        int8      `db:"status"`      // ConsumedStatus: 0=In flight, 1=Completed
+	ClaimToken    string    `db:"claim_token"` // Token of the delivery holding the claim
+	LeaseUntil    time.Time `db:"lease_until"` // An in-flight claim expires after this time
+	CreateTime    time.Time `db:"create_time"`
+	UpdateTime    time.Time `db:"update_time"`
+}
+
+// ConsumeFailure represents the mq_consume_failure table: the last failed delivery of a message
+// to a consumer group, kept so that dead-lettered messages can be inspected with their reason.
+//
+//nolint:govet // Field order optimized for logical grouping
+type ConsumeFailure struct {
+	ConsumerGroup  string    `db:"consumer_group"`
+	MsgID          string    `db:"msg_id"` // Stays the same through redeliveries and the dead-letter topic
+	Topic          string    `db:"topic"`
+	ReconsumeTimes int32     `db:"reconsume_times"` // Failed deliveries before this one
+	Reason         string    `db:"reason"`
+	CreateTime     time.Time `db:"create_time"`
+	UpdateTime     time.Time `db:"update_time"` // Time of the last failure
+}
+
+// DeadLetterReplay represents the dead_letter_replay table: the audit log of dead-lettered
+// messages sent back for processing.
+//
+//nolint:govet // Field order optimized for logical grouping
+type DeadLetterReplay struct {
+	ID            int64     `db:"id"`
+	ConsumerGroup string    `db:"consumer_group"`
+	MsgID         string    `db:"msg_id"`   // The dead-lettered message
+	EventID       string    `db:"event_id"` // Empty when the message does not carry an event
+	EventType     string    `db:"event_type"`
+	TargetTopic   string    `db:"target_topic"`
+	DryRun        bool      `db:"dry_run"`    // Sent to the sandbox topic rather than the original one
+	NewMsgID      string    `db:"new_msg_id"` // Empty when the replay failed
+	Operator      string    `db:"operator"`
+	Reason        string    `db:"reason"`
+	Error         *string   `db:"error"`
+	CreateTime    time.Time `db:"create_time"`
+}
+
+// IDSegment represents the id_segment table: the IDs of a business tag handed out so far, a
+// segment of Step IDs at a time.
+//
+//nolint:govet // Field order optimized for logical grouping
+type IDSegment struct {
+	BizTag      string    `db:"biz_tag"`
+	MaxID       int64     `db:"max_id"` // Last ID of the last segment handed out
+	Step        int64     `db:"step"`   // Size of a segment
+	Description string    `db:"description"`
+	UpdateTime  time.Time `db:"update_time"`
+}
+
+// ReportOrder represents the report_order table: an order of any shard as the CDC service last
+// captured it from the binlog.
+//
+//nolint:govet // Field order optimized for logical grouping
+type ReportOrder struct {
+	ID          int64      `db:"id"`
+	CreateTime  time.Time  `db:"create_time"` // Partitioning key
+	UserID      int64      `db:"user_id"`
+	Status      int8       `db:"status"`
+	TotalAmount int32      `db:"total_amount"`
+	PayAmount   int32      `db:"pay_amount"`
+	PayChannel  *int8      `db:"pay_channel"`
+	OutTradeNo  *string    `db:"out_trade_no"`
+	PayTime     *time.Time `db:"pay_time"`
+	UpdateTime  time.Time  `db:"update_time"`
+	Version     int32      `db:"version"`
+	Deleted     bool       `db:"deleted"`      // Deleted from the trade database
+	Source      string     `db:"source"`       // Database and table the order was captured from
+	CommittedAt int64      `db:"committed_at"` // Binlog time of the change (unix milliseconds)
+}
+
+// ReportOrderItem represents the report_order_item table: an order item of any shard as the CDC
+// service last captured it from the binlog.
+//
+//nolint:govet // Field order optimized for logical grouping
+type ReportOrderItem struct {
+	ID            int64     `db:"id"`
+	CreateTime    time.Time `db:"create_time"` // Partitioning key
+	OrderID       int64     `db:"order_id"`
+	UserID        int64     `db:"user_id"`
+	CourseID      int64     `db:"course_id"`
+	CourseName    string    `db:"course_name"`
+	Price         int32     `db:"price"`
+	RealPayAmount int32     `db:"real_pay_amount"`
+	RefundStatus  int8      `db:"refund_status"`
+	UpdateTime    time.Time `db:"update_time"`
+	Deleted       bool      `db:"deleted"`      // Deleted from the trade database
+	Source        string    `db:"source"`       // Database and table the item was captured from
+	CommittedAt   int64     `db:"committed_at"` // Binlog time of the change (unix milliseconds)
+}
+
 // OrderStatus constants.
 const (
 	OrderStatusPendingPayment = 1 // Pending payment
@@ -72,6 +234,21 @@ const (
 	OrderStatusRefunded       = 5 // Refunded
 )
 
+// RefundStatus constants.
+const (
+	RefundStatusRequested = 1 // Requested by the user, awaiting approval
+	RefundStatusApproved  = 2 // Approved, payment gateway refund in progress
+	RefundStatusSucceeded = 3 // Money returned
+	RefundStatusFailed    = 4 // Payment gateway rejected the refund
+)
+
+// ItemRefundStatus constants.
+const (
+	ItemRefundStatusNone      = 0 // Not refunded
+	ItemRefundStatusRefunding = 1 // Claimed by an open refund
+	ItemRefundStatusRefunded  = 2 // Refunded
+)
+
 // CouponStatus constants.
 const (
 	CouponStatusUnused  = 1 // Unused
@@ -90,3 +267,16 @@ const (
 	PayChannelAlipay = 1 // Alipay
 	PayChannelWeChat = 2 // WeChat
 )
+
+// OutboxStatus constants.
+const (
+	OutboxStatusPending   = 0 // Awaiting publication
+	OutboxStatusPublished = 1 // Published to the broker
+	OutboxStatusFailed    = 2 // Gave up after the maximum number of attempts
+)
+
+// ConsumedStatus constants.
+const (
+	ConsumedStatusInFlight  = 0 // Claimed by a delivery that is still being processed
+	ConsumedStatusCompleted = 1 // Processed
+)
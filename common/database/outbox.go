@@ -0,0 +1,271 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxOutboxErrorLen bounds last_error, and the other error columns of the messaging tables, to
+// their column size.
+const maxOutboxErrorLen = 512
+
+// Execer executes statements. *sql.DB and *sql.Tx satisfy it, so an outbox event can be written
+// with the transaction that writes the business rows.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// InsertOutboxEvent writes event as pending and sets its ID. Call it with the transaction that
+// writes the rows the event announces, so that the event exists exactly when they do.
+func InsertOutboxEvent(ctx context.Context, exec Execer, event *OutboxEvent) error {
+	query := `INSERT INTO outbox_event
+	          (aggregate_type, aggregate_id, topic, tag, msg_keys, properties, payload, status)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := exec.ExecContext(ctx, query,
+		event.AggregateType, event.AggregateID, event.Topic, event.Tag, event.Keys,
+		event.Properties, event.Payload, OutboxStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to create outbox event: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get outbox event id: %w", err)
+	}
+	event.ID = id
+	event.Status = OutboxStatusPending
+	return nil
+}
+
+// OutboxResult reports the outcome of publishing an outbox event.
+//
+//nolint:govet // Field order optimized for logical grouping
+type OutboxResult struct {
+	// Err is the publication error; nil means the event was published.
+	Err error
+	ID  int64
+	// Retry is how long a failed event waits before its next attempt.
+	Retry time.Duration
+	// GiveUp marks a failed event failed for good instead of retrying it.
+	GiveUp bool
+}
+
+// OutboxStore gives a relay access to pending outbox events.
+//
+// A relay claims events with a lease in a short transaction, publishes them without holding any
+// lock, and records the results in a second transaction. Results are only recorded for events
+// whose lease the claim still holds: once a lease expires, the events can be claimed and
+// published again by another relay.
+type OutboxStore struct {
+	db *sql.DB
+}
+
+// NewOutboxStore creates a new OutboxStore instance.
+func NewOutboxStore(db *sql.DB) *OutboxStore {
+	return &OutboxStore{db: db}
+}
+
+// ClaimPending leases up to limit pending events to owner, oldest first, and returns them.
+//
+// To keep the events of an aggregate in id order, an event is not claimed while an earlier event
+// of its aggregate is leased to another claim or waiting for a retry. The candidates are read
+// FOR UPDATE, so that concurrent claims do not lease the same events, but the locks are released
+// as soon as the leases are written.
+func (s *OutboxStore) ClaimPending(
+	ctx context.Context, owner string, limit int, lease time.Duration,
+) (events []*OutboxEvent, err error) {
+	if owner == "" {
+		return nil, fmt.Errorf("claim owner cannot be empty")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				// Log rollback error but don't override original error
+				_ = rollbackErr
+			}
+		}
+	}()
+
+	candidates, unavailable, err := s.lockPending(ctx, tx, limit, lease)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked := make(map[string]bool)
+	args := []interface{}{owner}
+	for i, event := range candidates {
+		aggregate := event.AggregateType + "/" + event.AggregateID
+		if unavailable[i] || blocked[aggregate] {
+			blocked[aggregate] = true
+			continue
+		}
+		events = append(events, event)
+		args = append(args, event.ID)
+	}
+	if len(events) > 0 {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE outbox_event SET claim_owner = ?, claim_time = NOW(3) WHERE id IN ("+
+				placeholders(len(events))+")",
+			args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	for _, event := range events {
+		event.ClaimOwner = &owner
+	}
+	return events, nil
+}
+
+// RecordResults records the results of publishing events claimed by owner, and releases the
+// events without a result for the next claim.
+//
+// Published events are marked published. A failed event stays pending until its Retry delay has
+// passed, or is marked failed when GiveUp is set; either way the attempt and error are recorded.
+func (s *OutboxStore) RecordResults(
+	ctx context.Context, owner string, events []*OutboxEvent, results []OutboxResult,
+) (err error) {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				// Log rollback error but don't override original error
+				_ = rollbackErr
+			}
+		}
+	}()
+
+	for _, result := range results {
+		if result.Err == nil {
+			_, err = tx.ExecContext(ctx,
+				`UPDATE outbox_event
+				 SET status = ?, publish_time = NOW(), claim_owner = NULL, claim_time = NULL, retry_time = NULL
+				 WHERE id = ? AND claim_owner = ?`,
+				OutboxStatusPublished, result.ID, owner)
+		} else {
+			status := OutboxStatusPending
+			if result.GiveUp {
+				status = OutboxStatusFailed
+			}
+			_, err = tx.ExecContext(ctx,
+				`UPDATE outbox_event
+				 SET status = ?, attempts = attempts + 1, last_error = ?, claim_owner = NULL, claim_time = NULL,
+				     retry_time = NOW(3) + INTERVAL ? MICROSECOND
+				 WHERE id = ? AND claim_owner = ?`,
+				status, truncateError(result.Err.Error()), result.Retry.Microseconds(), result.ID, owner)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to record outbox result: %w", err)
+		}
+	}
+
+	args := []interface{}{owner}
+	for _, event := range events {
+		args = append(args, event.ID)
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE outbox_event SET claim_owner = NULL, claim_time = NULL WHERE claim_owner = ? AND id IN ("+
+			placeholders(len(events))+")",
+		args...)
+	if err != nil {
+		return fmt.Errorf("failed to release outbox events: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// PurgePublished deletes up to limit events published more than retention ago, and returns how
+// many it deleted. Failed events are kept for inspection.
+func (s *OutboxStore) PurgePublished(ctx context.Context, retention time.Duration, limit int) (int, error) {
+	result, err := s.db.ExecContext(ctx,
+		`DELETE FROM outbox_event WHERE status = ? AND publish_time < NOW() - INTERVAL ? SECOND LIMIT ?`,
+		OutboxStatusPublished, int64(retention.Seconds()), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge outbox events: %w", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get purged outbox events: %w", err)
+	}
+	return int(purged), nil
+}
+
+// lockPending selects up to limit pending events FOR UPDATE, and reports for each whether it is
+// unavailable: leased to a claim whose lease has not expired, or waiting for a retry.
+func (s *OutboxStore) lockPending(
+	ctx context.Context, tx *sql.Tx, limit int, lease time.Duration,
+) ([]*OutboxEvent, []bool, error) {
+	query := `SELECT id, aggregate_type, aggregate_id, topic, tag, msg_keys, properties, payload,
+	                 status, attempts, last_error, create_time, publish_time,
+	                 (claim_owner IS NOT NULL AND claim_time > NOW(3) - INTERVAL ? MICROSECOND)
+	                   OR retry_time > NOW(3)
+	          FROM outbox_event WHERE status = ? ORDER BY id LIMIT ? FOR UPDATE`
+
+	rows, err := tx.QueryContext(ctx, query, lease.Microseconds(), OutboxStatusPending, limit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query outbox events: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			_ = closeErr
+		}
+	}()
+
+	var (
+		events      []*OutboxEvent
+		unavailable []bool
+	)
+	for rows.Next() {
+		var (
+			event OutboxEvent
+			busy  sql.NullBool
+		)
+		if err := rows.Scan(&event.ID, &event.AggregateType, &event.AggregateID, &event.Topic,
+			&event.Tag, &event.Keys, &event.Properties, &event.Payload, &event.Status,
+			&event.Attempts, &event.LastError, &event.CreateTime, &event.PublishTime, &busy); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		events = append(events, &event)
+		unavailable = append(unavailable, busy.Valid && busy.Bool)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating outbox events: %w", err)
+	}
+	return events, unavailable, nil
+}
+
+// placeholders returns n comma-separated query placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// truncateError shortens msg to fit an error column.
+func truncateError(msg string) string {
+	if len(msg) <= maxOutboxErrorLen {
+		return msg
+	}
+	return strings.ToValidUTF8(msg[:maxOutboxErrorLen], "")
+}
@@ -0,0 +1,226 @@
+//go:build integration
+// +build integration
+
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/database/migrations"
+	"github.com/aether-defense-system/common/migrate"
+)
+
+// outboxTestDSNEnv names a MySQL server allowed to create and drop the test schema, e.g.
+// root:root@tcp(localhost:3306)/.
+const outboxTestDSNEnv = "OUTBOX_TEST_MYSQL_DSN"
+
+// outboxTestSchema is the local schema standing in for a trade database.
+const outboxTestSchema = "aether_outbox_test"
+
+// setupOutboxSchema creates the test schema with the trade tables, and returns a connection to it.
+func setupOutboxSchema(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv(outboxTestDSNEnv)
+	if dsn == "" {
+		t.Skipf("Set %s to run outbox integration tests.", outboxTestDSNEnv)
+	}
+	base, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", outboxTestDSNEnv, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	server, err := sql.Open("mysql", base.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open MySQL: %v", err)
+	}
+	defer func() { _ = server.Close() }()
+	if err := server.PingContext(ctx); err != nil {
+		t.Skipf("MySQL not available for integration test: %v", err)
+	}
+	for _, stmt := range []string{
+		"DROP DATABASE IF EXISTS " + outboxTestSchema,
+		"CREATE DATABASE " + outboxTestSchema,
+	} {
+		if _, err := server.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("failed to create schema %s: %v", outboxTestSchema, err)
+		}
+	}
+	t.Cleanup(func() {
+		dropDB, err := sql.Open("mysql", base.FormatDSN())
+		if err != nil {
+			return
+		}
+		defer func() { _ = dropDB.Close() }()
+		_, _ = dropDB.Exec("DROP DATABASE IF EXISTS " + outboxTestSchema)
+	})
+
+	cfg := base.Clone()
+	cfg.DBName = outboxTestSchema
+	cfg.ParseTime = true
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", outboxTestSchema, err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	set, err := migrations.Load(migrations.Trade)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := migrate.New(db).Up(ctx, set); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func insertOutboxEvents(t *testing.T, db *sql.DB, aggregateIDs ...string) []int64 {
+	t.Helper()
+	ids := make([]int64, 0, len(aggregateIDs))
+	for _, aggregateID := range aggregateIDs {
+		event := &database.OutboxEvent{
+			AggregateType: "order", AggregateID: aggregateID, Topic: "orders", Properties: "{}", Payload: []byte("x"),
+		}
+		if err := database.InsertOutboxEvent(context.Background(), db, event); err != nil {
+			t.Fatalf("failed to insert outbox event: %v", err)
+		}
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func claimedIDs(events []*database.OutboxEvent) []int64 {
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestIntegration_OutboxStore_Lease(t *testing.T) {
+	db := setupOutboxSchema(t)
+	store := database.NewOutboxStore(db)
+	ctx := context.Background()
+	ids := insertOutboxEvents(t, db, "1", "2", "1")
+
+	first, err := store.ClaimPending(ctx, "first", 1, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimPending failed: %v", err)
+	}
+	if got := claimedIDs(first); len(got) != 1 || got[0] != ids[0] {
+		t.Fatalf("expected the first claim to get event %d, got %v", ids[0], got)
+	}
+
+	// The lease is held without a transaction: a second claim skips the leased event and the
+	// later event of its aggregate.
+	second, err := store.ClaimPending(ctx, "second", 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimPending failed: %v", err)
+	}
+	if got := claimedIDs(second); len(got) != 1 || got[0] != ids[1] {
+		t.Fatalf("expected the second claim to get event %d only, got %v", ids[1], got)
+	}
+
+	// Results of a claim that no longer holds the lease are ignored.
+	if err := store.RecordResults(ctx, "second", first, []database.OutboxResult{{ID: ids[0]}}); err != nil {
+		t.Fatalf("RecordResults failed: %v", err)
+	}
+	if err := store.RecordResults(ctx, "first", first, []database.OutboxResult{{ID: ids[0]}}); err != nil {
+		t.Fatalf("RecordResults failed: %v", err)
+	}
+	if err := store.RecordResults(ctx, "second", second, []database.OutboxResult{
+		{ID: ids[1], Err: errors.New("broker unavailable"), Retry: time.Hour},
+	}); err != nil {
+		t.Fatalf("RecordResults failed: %v", err)
+	}
+
+	var status int8
+	var attempts int32
+	if err := db.QueryRow("SELECT status, attempts FROM outbox_event WHERE id = ?", ids[0]).
+		Scan(&status, &attempts); err != nil {
+		t.Fatalf("failed to read event: %v", err)
+	}
+	if status != database.OutboxStatusPublished {
+		t.Errorf("expected event %d published, got status %d", ids[0], status)
+	}
+	if err := db.QueryRow("SELECT status, attempts FROM outbox_event WHERE id = ?", ids[1]).
+		Scan(&status, &attempts); err != nil {
+		t.Fatalf("failed to read event: %v", err)
+	}
+	if status != database.OutboxStatusPending || attempts != 1 {
+		t.Errorf("expected event %d pending after 1 attempt, got status %d and %d attempts", ids[1], status, attempts)
+	}
+
+	// Event 2 waits for its retry; event 3 follows the published event 1.
+	third, err := store.ClaimPending(ctx, "third", 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimPending failed: %v", err)
+	}
+	if got := claimedIDs(third); len(got) != 1 || got[0] != ids[2] {
+		t.Fatalf("expected the third claim to get event %d only, got %v", ids[2], got)
+	}
+
+	// An expired lease is claimed again.
+	time.Sleep(20 * time.Millisecond)
+	fourth, err := store.ClaimPending(ctx, "fourth", 10, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("ClaimPending failed: %v", err)
+	}
+	if got := claimedIDs(fourth); len(got) != 1 || got[0] != ids[2] {
+		t.Fatalf("expected the expired lease of event %d to be claimed again, got %v", ids[2], got)
+	}
+}
+
+func TestIntegration_OutboxStore_GiveUpAndPurge(t *testing.T) {
+	db := setupOutboxSchema(t)
+	store := database.NewOutboxStore(db)
+	ctx := context.Background()
+	ids := insertOutboxEvents(t, db, "1", "2", "3")
+
+	events, err := store.ClaimPending(ctx, "relay", 10, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimPending failed: %v", err)
+	}
+	if err := store.RecordResults(ctx, "relay", events, []database.OutboxResult{
+		{ID: ids[0]},
+		{ID: ids[1]},
+		{ID: ids[2], Err: errors.New("message too large"), GiveUp: true},
+	}); err != nil {
+		t.Fatalf("RecordResults failed: %v", err)
+	}
+	if _, err := db.Exec("UPDATE outbox_event SET publish_time = NOW() - INTERVAL 8 DAY WHERE id = ?", ids[0]); err != nil {
+		t.Fatalf("failed to age event: %v", err)
+	}
+
+	purged, err := store.PurgePublished(ctx, 7*24*time.Hour, 10)
+	if err != nil {
+		t.Fatalf("PurgePublished failed: %v", err)
+	}
+	if purged != 1 {
+		t.Errorf("expected 1 purged event, got %d", purged)
+	}
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM outbox_event WHERE status = ?", database.OutboxStatusFailed).
+		Scan(&count); err != nil {
+		t.Fatalf("failed to count failed events: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected the failed event to be kept, got %d", count)
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM outbox_event").Scan(&count); err != nil {
+		t.Fatalf("failed to count events: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 events left, got %d", count)
+	}
+}
@@ -0,0 +1,22 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateError(t *testing.T) {
+	if got := truncateError("broker unavailable"); got != "broker unavailable" {
+		t.Errorf("short errors must be kept, got %q", got)
+	}
+
+	long := strings.Repeat("é", maxOutboxErrorLen)
+	got := truncateError(long)
+	if len(got) > maxOutboxErrorLen {
+		t.Errorf("expected at most %d bytes, got %d", maxOutboxErrorLen, len(got))
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("truncation must not split a character")
+	}
+}
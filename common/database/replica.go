@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// replicaPingTimeout bounds a health check of a replica.
+const replicaPingTimeout = 2 * time.Second
+
+// primaryKey is the context key of ForcePrimary.
+type primaryKey struct{}
+
+// ForcePrimary returns a context whose reads go to the primary, like a hint forcing the master.
+// Replicas lag behind, so use it to read what was just written, e.g. an order right after it is
+// paid, and wherever what is read decides what is written, e.g. a status checked before it is
+// changed. Reads in a WithTx transaction go to the primary without it.
+func ForcePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryForced reports whether ForcePrimary applies to ctx.
+func PrimaryForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryKey{}).(bool)
+	return forced
+}
+
+// replica is a read replica and its health.
+type replica struct {
+	db      *sql.DB
+	addr    string // Address of the replica, for logs
+	healthy atomic.Bool
+}
+
+// ReadDB returns the database to read from in ctx: a healthy replica, taken in turn, or the
+// primary if the context forces it, carries a transaction, or no replica is healthy.
+func (c *Client) ReadDB(ctx context.Context) *sql.DB {
+	n := uint64(len(c.replicas))
+	if n == 0 || PrimaryForced(ctx) || InTx(ctx) {
+		return c.db
+	}
+	start := c.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := c.replicas[(start+i)%n]; r.healthy.Load() {
+			return r.db
+		}
+	}
+	return c.db
+}
+
+// startReplicas opens the replicas of config, checks them once, and keeps checking them in the
+// background. Replicas down at startup are skipped until they answer.
+func (c *Client) startReplicas(config *Config) error {
+	if len(config.Replicas) == 0 {
+		return nil
+	}
+	if config.ReplicaCheckInterval < 0 {
+		return fmt.Errorf("replica check interval must be positive, got %v", config.ReplicaCheckInterval)
+	}
+	for _, dsn := range config.Replicas {
+		db, err := openDB(config, dsn)
+		if err != nil {
+			return fmt.Errorf("failed to open replica: %w", err)
+		}
+		r := &replica{db: db, addr: replicaAddr(dsn)}
+		r.healthy.Store(true) // So that failing the first check is logged
+		c.replicas = append(c.replicas, r)
+	}
+	c.checkReplicas(context.Background())
+
+	c.stop = make(chan struct{})
+	c.checks.Add(1)
+	go func() {
+		defer c.checks.Done()
+		ticker := time.NewTicker(config.ReplicaCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				c.checkReplicas(context.Background())
+			}
+		}
+	}()
+	return nil
+}
+
+// checkReplicas pings every replica and records whether it answered.
+func (c *Client) checkReplicas(ctx context.Context) {
+	for i, r := range c.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+		err := r.db.PingContext(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) != healthy {
+			if healthy {
+				logx.Infof("database replica %d (%s) is back, reading from it", i, r.addr)
+			} else {
+				logx.Errorf("database replica %d (%s) failed its health check, reading elsewhere: %v",
+					i, r.addr, err)
+			}
+		}
+	}
+}
+
+// replicaAddr returns the address in dsn, leaving out the credentials.
+func replicaAddr(dsn string) string {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "invalid DSN"
+	}
+	return cfg.Addr
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+)
+
+// newReplicaClient returns a client whose primary and replicas are never reached: nothing listens
+// on their port.
+func newReplicaClient(t *testing.T, replicas int) *Client {
+	t.Helper()
+	open := func(name string) *sql.DB {
+		db, err := sql.Open("mysql", fmt.Sprintf("user@tcp(127.0.0.1:1)/%s?timeout=1s", name))
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		return db
+	}
+	c := &Client{db: open("primary")}
+	for i := 0; i < replicas; i++ {
+		r := &replica{db: open(fmt.Sprintf("replica%d", i)), addr: "127.0.0.1:1"}
+		r.healthy.Store(true)
+		c.replicas = append(c.replicas, r)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestClient_ReadDB(t *testing.T) {
+	c := newReplicaClient(t, 2)
+	ctx := context.Background()
+
+	// Reads alternate between the replicas.
+	seen := make(map[*sql.DB]int)
+	for i := 0; i < 4; i++ {
+		seen[c.ReadDB(ctx)]++
+	}
+	if seen[c.replicas[0].db] != 2 || seen[c.replicas[1].db] != 2 {
+		t.Errorf("expected reads spread over both replicas, got %v", seen)
+	}
+
+	if got := c.ReadDB(ForcePrimary(ctx)); got != c.DB() {
+		t.Errorf("expected the primary when forced")
+	}
+	// Reads in a transaction see its writes only on the primary.
+	if got := c.ReadDB(context.WithValue(ctx, txKey{}, &txState{})); got != c.DB() {
+		t.Errorf("expected the primary in a transaction")
+	}
+
+	// An unhealthy replica is skipped; with none left, reads fall back to the primary.
+	c.replicas[0].healthy.Store(false)
+	for i := 0; i < 3; i++ {
+		if got := c.ReadDB(ctx); got != c.replicas[1].db {
+			t.Fatalf("expected the healthy replica")
+		}
+	}
+	c.replicas[1].healthy.Store(false)
+	if got := c.ReadDB(ctx); got != c.DB() {
+		t.Errorf("expected the primary without healthy replicas")
+	}
+}
+
+func TestClient_ReadDB_NoReplicas(t *testing.T) {
+	c := newReplicaClient(t, 0)
+	if got := c.ReadDB(context.Background()); got != c.DB() {
+		t.Errorf("expected the primary without replicas")
+	}
+}
+
+func TestClient_CheckReplicas(t *testing.T) {
+	c := newReplicaClient(t, 1)
+
+	c.checkReplicas(context.Background())
+	if c.replicas[0].healthy.Load() {
+		t.Errorf("expected an unreachable replica to fail its health check")
+	}
+	if got := c.ReadDB(context.Background()); got != c.DB() {
+		t.Errorf("expected reads to fall back to the primary")
+	}
+}
+
+func TestPrimaryForced(t *testing.T) {
+	ctx := context.Background()
+	if PrimaryForced(ctx) {
+		t.Errorf("expected a plain context not to force the primary")
+	}
+	if !PrimaryForced(ForcePrimary(ctx)) {
+		t.Errorf("expected ForcePrimary to force the primary")
+	}
+}
+
+func TestReplicaAddr(t *testing.T) {
+	if got := replicaAddr("user:secret@tcp(db-replica:3306)/app"); got != "db-replica:3306" {
+		t.Errorf("expected the address without credentials, got %q", got)
+	}
+}
@@ -0,0 +1,295 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// reportTables are the partitioned tables of the reporting database.
+var reportTables = []string{"report_order", "report_order_item"}
+
+// reportPartitionStart is the first month with its own partition: older rows go to partition p0,
+// as created by the reporting migrations.
+var reportPartitionStart = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// reportPartitionName matches the names of the monthly partitions, p followed by YYYYMM.
+var reportPartitionName = regexp.MustCompile(`^p(\d{6})$`)
+
+// reportOrderColumns are the columns of report_order, primary key first and binlog time last.
+var reportOrderColumns = []string{
+	"id", "create_time", "user_id", "status", "total_amount", "pay_amount", "pay_channel",
+	"out_trade_no", "pay_time", "update_time", "version", "deleted", "source", "committed_at",
+}
+
+// reportOrderItemColumns are the columns of report_order_item, primary key first and binlog time
+// last.
+var reportOrderItemColumns = []string{
+	"id", "create_time", "order_id", "user_id", "course_id", "course_name", "price", "real_pay_amount",
+	"refund_status", "update_time", "deleted", "source", "committed_at",
+}
+
+// OrderReportCursor is the position of the last order of a page of OrderReportStore.SearchOrders.
+type OrderReportCursor struct {
+	CreateTime time.Time
+	ID         int64
+}
+
+// OrderReportQuery selects the reported orders created in [CreatedFrom, CreatedTo).
+type OrderReportQuery struct {
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Status      *int8              // Orders in this status, any status when nil
+	After       *OrderReportCursor // Orders after this one, from the first when nil
+	UserID      int64              // Orders of this user, any user when 0
+	Limit       int
+	// IncludeDeleted also returns orders deleted from the trade database.
+	IncludeDeleted bool
+}
+
+// OrderReportStore keeps the reporting tables: the orders and order items of every trade shard,
+// partitioned by create_time, as captured from the binlog.
+//
+// Changes are applied in binlog time order per row: a change older than the one a row holds is
+// ignored, so redelivered and reordered changes are harmless. Changes with the same binlog time,
+// which has millisecond precision, are applied in the order they arrive.
+type OrderReportStore struct {
+	db *sql.DB
+}
+
+// NewOrderReportStore creates a new OrderReportStore instance.
+func NewOrderReportStore(db *sql.DB) *OrderReportStore {
+	return &OrderReportStore{db: db}
+}
+
+// ApplyOrder stores order unless the stored row holds a later change. A deleted order is stored
+// flagged deleted, with its last values.
+func (s *OrderReportStore) ApplyOrder(ctx context.Context, order *ReportOrder) error {
+	_, err := s.db.ExecContext(ctx, upsertNewer("report_order", reportOrderColumns),
+		order.ID, order.CreateTime, order.UserID, order.Status, order.TotalAmount, order.PayAmount,
+		order.PayChannel, order.OutTradeNo, order.PayTime, order.UpdateTime, order.Version, order.Deleted,
+		order.Source, order.CommittedAt)
+	if err != nil {
+		return fmt.Errorf("failed to apply reported order %d: %w", order.ID, err)
+	}
+	return nil
+}
+
+// ApplyOrderItem stores item unless the stored row holds a later change. A deleted item is stored
+// flagged deleted, with its last values.
+func (s *OrderReportStore) ApplyOrderItem(ctx context.Context, item *ReportOrderItem) error {
+	_, err := s.db.ExecContext(ctx, upsertNewer("report_order_item", reportOrderItemColumns),
+		item.ID, item.CreateTime, item.OrderID, item.UserID, item.CourseID, item.CourseName, item.Price,
+		item.RealPayAmount, item.RefundStatus, item.UpdateTime, item.Deleted, item.Source, item.CommittedAt)
+	if err != nil {
+		return fmt.Errorf("failed to apply reported order item %d: %w", item.ID, err)
+	}
+	return nil
+}
+
+// SearchOrders returns up to q.Limit reported orders matching q, newest first. Only the partitions
+// of the months of [q.CreatedFrom, q.CreatedTo) are read.
+func (s *OrderReportStore) SearchOrders(ctx context.Context, q *OrderReportQuery) ([]*ReportOrder, error) {
+	query := "SELECT " + strings.Join(reportOrderColumns, ", ") + ` FROM report_order
+	          WHERE create_time >= ? AND create_time < ?`
+	args := []interface{}{q.CreatedFrom, q.CreatedTo}
+	if q.Status != nil {
+		query += " AND status = ?"
+		args = append(args, *q.Status)
+	}
+	if q.UserID != 0 {
+		query += " AND user_id = ?"
+		args = append(args, q.UserID)
+	}
+	if !q.IncludeDeleted {
+		query += " AND deleted = 0"
+	}
+	if q.After != nil {
+		query += " AND (create_time < ? OR (create_time = ? AND id < ?))"
+		args = append(args, q.After.CreateTime, q.After.CreateTime, q.After.ID)
+	}
+	query += " ORDER BY create_time DESC, id DESC LIMIT ?"
+	args = append(args, q.Limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search reported orders: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var orders []*ReportOrder
+	for rows.Next() {
+		o := &ReportOrder{}
+		if err := rows.Scan(&o.ID, &o.CreateTime, &o.UserID, &o.Status, &o.TotalAmount, &o.PayAmount,
+			&o.PayChannel, &o.OutTradeNo, &o.PayTime, &o.UpdateTime, &o.Version, &o.Deleted, &o.Source,
+			&o.CommittedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reported order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate reported orders: %w", err)
+	}
+	return orders, nil
+}
+
+// GetItemsByOrderIDs returns the reported items of the orders, deleted ones included, by order
+// and then by ID.
+func (s *OrderReportStore) GetItemsByOrderIDs(ctx context.Context, orderIDs []int64) ([]*ReportOrderItem, error) {
+	if len(orderIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, 0, len(orderIDs))
+	for _, id := range orderIDs {
+		args = append(args, id)
+	}
+	query := "SELECT " + strings.Join(reportOrderItemColumns, ", ") + ` FROM report_order_item
+	          WHERE order_id IN (?` + strings.Repeat(", ?", len(orderIDs)-1) + `)
+	          ORDER BY order_id, id`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reported order items: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var items []*ReportOrderItem
+	for rows.Next() {
+		item := &ReportOrderItem{}
+		if err := rows.Scan(&item.ID, &item.CreateTime, &item.OrderID, &item.UserID, &item.CourseID,
+			&item.CourseName, &item.Price, &item.RealPayAmount, &item.RefundStatus, &item.UpdateTime,
+			&item.Deleted, &item.Source, &item.CommittedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reported order item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate reported order items: %w", err)
+	}
+	return items, nil
+}
+
+// EnsurePartitions gives every month from the month of from through the month of through its own
+// partition in the reporting tables, by splitting the catch-all partition pmax, and returns the
+// number of partitions added. Months before the last monthly partition, or before 2023, stay in
+// the partition that covers them. Call it ahead of time: splitting pmax copies the rows it holds.
+func (s *OrderReportStore) EnsurePartitions(ctx context.Context, from, through time.Time) (int, error) {
+	added := 0
+	for _, table := range reportTables {
+		existing, err := s.partitions(ctx, table)
+		if err != nil {
+			return added, err
+		}
+		months := missingPartitionMonths(existing, from, through)
+		if len(months) == 0 {
+			continue
+		}
+
+		defs := make([]string, 0, len(months)+1)
+		for _, month := range months {
+			defs = append(defs, fmt.Sprintf("PARTITION %s VALUES LESS THAN (TO_DAYS('%s'))",
+				partitionName(month), month.AddDate(0, 1, 0).Format("2006-01-02")))
+		}
+		defs = append(defs, "PARTITION pmax VALUES LESS THAN MAXVALUE")
+		stmt := "ALTER TABLE " + table + " REORGANIZE PARTITION pmax INTO (" + strings.Join(defs, ", ") + ")"
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return added, fmt.Errorf("failed to add partitions to %s: %w", table, err)
+		}
+		added += len(months)
+	}
+	return added, nil
+}
+
+// partitions returns the partition names of table, which must have the catch-all partition pmax.
+func (s *OrderReportStore) partitions(ctx context.Context, table string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT PARTITION_NAME FROM information_schema.PARTITIONS
+		 WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query partitions of %s: %w", table, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var names []string
+	hasMax := false
+	for rows.Next() {
+		var name sql.NullString
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan partition of %s: %w", table, err)
+		}
+		if name.String == "pmax" {
+			hasMax = true
+		}
+		names = append(names, name.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate partitions of %s: %w", table, err)
+	}
+	if !hasMax {
+		return nil, fmt.Errorf("table %s has no pmax partition; apply the reporting migrations", table)
+	}
+	return names, nil
+}
+
+// missingPartitionMonths returns the first days of the months from the month of from through the
+// month of through that come after every existing monthly partition.
+func missingPartitionMonths(existing []string, from, through time.Time) []time.Time {
+	start := firstOfMonth(from)
+	if start.Before(reportPartitionStart) {
+		start = reportPartitionStart
+	}
+	for _, name := range existing {
+		m := reportPartitionName.FindStringSubmatch(name)
+		if m == nil {
+			continue
+		}
+		month, err := time.Parse("200601", m[1])
+		if err != nil {
+			continue
+		}
+		if next := month.AddDate(0, 1, 0); !next.Before(start) {
+			start = next
+		}
+	}
+
+	var months []time.Time
+	for month := start; !month.After(firstOfMonth(through)); month = month.AddDate(0, 1, 0) {
+		months = append(months, month)
+	}
+	return months
+}
+
+// firstOfMonth returns the first day of the month of t, as a UTC date.
+func firstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// partitionName returns the name of the partition of month.
+func partitionName(month time.Time) string {
+	return "p" + month.Format("200601")
+}
+
+// upsertNewer returns an upsert of columns into table that leaves a stored row alone when it holds
+// a later change. The first two columns are the primary key (id, create_time) and the last one the
+// binlog time of the row, assigned last since MySQL evaluates the assignments in order.
+func upsertNewer(table string, columns []string) string {
+	newer := columns[len(columns)-1]
+	updates := make([]string, 0, len(columns)-2)
+	for _, c := range columns[2 : len(columns)-1] {
+		updates = append(updates, fmt.Sprintf("%s = IF(VALUES(%s) >= %s, VALUES(%s), %s)", c, newer, newer, c, c))
+	}
+	updates = append(updates, fmt.Sprintf("%s = GREATEST(%s, VALUES(%s))", newer, newer, newer))
+
+	return "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (?" +
+		strings.Repeat(", ?", len(columns)-1) + ") ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+}
@@ -0,0 +1,172 @@
+//go:build integration
+// +build integration
+
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/database/migrations"
+	"github.com/aether-defense-system/common/migrate"
+)
+
+// reportTestDSNEnv names a MySQL server allowed to create and drop the test schema, e.g.
+// root:root@tcp(localhost:3306)/.
+const reportTestDSNEnv = "REPORT_TEST_MYSQL_DSN"
+
+// reportTestSchema is the local schema standing in for the reporting database.
+const reportTestSchema = "aether_report_test"
+
+// setupReportSchema creates the test schema with the reporting tables, and returns a connection
+// to it.
+func setupReportSchema(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv(reportTestDSNEnv)
+	if dsn == "" {
+		t.Skipf("Set %s to run reporting integration tests.", reportTestDSNEnv)
+	}
+	base, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", reportTestDSNEnv, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	server, err := sql.Open("mysql", base.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open MySQL: %v", err)
+	}
+	defer func() { _ = server.Close() }()
+	if err := server.PingContext(ctx); err != nil {
+		t.Skipf("MySQL not available for integration test: %v", err)
+	}
+	for _, stmt := range []string{
+		"DROP DATABASE IF EXISTS " + reportTestSchema,
+		"CREATE DATABASE " + reportTestSchema,
+	} {
+		if _, err := server.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("failed to create schema %s: %v", reportTestSchema, err)
+		}
+	}
+	t.Cleanup(func() {
+		dropDB, err := sql.Open("mysql", base.FormatDSN())
+		if err != nil {
+			return
+		}
+		defer func() { _ = dropDB.Close() }()
+		_, _ = dropDB.Exec("DROP DATABASE IF EXISTS " + reportTestSchema)
+	})
+
+	cfg := base.Clone()
+	cfg.DBName = reportTestSchema
+	cfg.ParseTime = true
+	db, err := sql.Open("mysql", cfg.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", reportTestSchema, err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	set, err := migrations.Load(migrations.Reporting)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := migrate.New(db).Up(ctx, set); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestIntegration_OrderReportStore(t *testing.T) {
+	db := setupReportSchema(t)
+	store := database.NewOrderReportStore(db)
+	ctx := context.Background()
+	created := time.Date(2023, 11, 11, 10, 0, 0, 0, time.UTC)
+
+	added, err := store.EnsurePartitions(ctx, created, created.AddDate(0, 2, 0))
+	if err != nil {
+		t.Fatalf("failed to add partitions: %v", err)
+	}
+	if added != 6 {
+		t.Errorf("expected 3 months in 2 tables, got %d partitions", added)
+	}
+	if added, err = store.EnsurePartitions(ctx, created, created.AddDate(0, 2, 0)); err != nil || added != 0 {
+		t.Errorf("expected existing partitions to be kept, got %d, %v", added, err)
+	}
+
+	order := func(id int64, status int8, committedAt int64) *database.ReportOrder {
+		return &database.ReportOrder{
+			ID: id, CreateTime: created.Add(time.Duration(id) * time.Second), UserID: 7, Status: status,
+			TotalAmount: 100, PayAmount: 100, UpdateTime: created, Source: "aether_trade.trade_order",
+			CommittedAt: committedAt,
+		}
+	}
+	// The payment of order 1 arrives before its creation.
+	for _, o := range []*database.ReportOrder{
+		order(1, database.OrderStatusPaid, 2000),
+		order(1, database.OrderStatusPendingPayment, 1000),
+		order(2, database.OrderStatusPendingPayment, 1000),
+		order(3, database.OrderStatusClosed, 1000),
+	} {
+		if err := store.ApplyOrder(ctx, o); err != nil {
+			t.Fatalf("failed to apply order: %v", err)
+		}
+	}
+	deleted := order(3, database.OrderStatusClosed, 3000)
+	deleted.Deleted = true
+	if err := store.ApplyOrder(ctx, deleted); err != nil {
+		t.Fatalf("failed to apply deletion: %v", err)
+	}
+
+	q := &database.OrderReportQuery{CreatedFrom: created, CreatedTo: created.AddDate(0, 0, 1), Limit: 10}
+	orders, err := store.SearchOrders(ctx, q)
+	if err != nil {
+		t.Fatalf("failed to search orders: %v", err)
+	}
+	if len(orders) != 2 || orders[0].ID != 2 || orders[1].ID != 1 {
+		t.Fatalf("expected orders 2 and 1, newest first, got %+v", orders)
+	}
+	if orders[1].Status != database.OrderStatusPaid || orders[1].CommittedAt != 2000 {
+		t.Errorf("expected the later change to be kept, got status %d at %d", orders[1].Status, orders[1].CommittedAt)
+	}
+
+	q.IncludeDeleted = true
+	q.Limit = 1
+	q.After = &database.OrderReportCursor{CreateTime: orders[0].CreateTime, ID: orders[0].ID}
+	page, err := store.SearchOrders(ctx, q)
+	if err != nil {
+		t.Fatalf("failed to search orders: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != 1 {
+		t.Errorf("expected order 1 after order 2, got %+v", page)
+	}
+
+	status := int8(database.OrderStatusClosed)
+	q = &database.OrderReportQuery{
+		CreatedFrom: created, CreatedTo: created.AddDate(0, 0, 1), Status: &status, Limit: 10, IncludeDeleted: true,
+	}
+	if page, err = store.SearchOrders(ctx, q); err != nil || len(page) != 1 || !page[0].Deleted {
+		t.Errorf("expected the deleted order 3, got %+v, %v", page, err)
+	}
+
+	item := &database.ReportOrderItem{
+		ID: 11, CreateTime: created, OrderID: 1, UserID: 7, CourseID: 5, CourseName: "Go",
+		Price: 100, RealPayAmount: 100, UpdateTime: created, Source: "aether_trade.trade_order_item", CommittedAt: 1000,
+	}
+	if err := store.ApplyOrderItem(ctx, item); err != nil {
+		t.Fatalf("failed to apply item: %v", err)
+	}
+	items, err := store.GetItemsByOrderIDs(ctx, []int64{1, 2})
+	if err != nil {
+		t.Fatalf("failed to get items: %v", err)
+	}
+	if len(items) != 1 || items[0].ID != 11 || items[0].CourseName != "Go" {
+		t.Errorf("expected item 11, got %+v", items)
+	}
+}
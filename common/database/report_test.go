@@ -0,0 +1,79 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMissingPartitionMonths(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 12, 0, 0, 0, time.Local)
+	}
+	names := func(months []time.Time) []string {
+		out := make([]string, 0, len(months))
+		for _, m := range months {
+			out = append(out, partitionName(m))
+		}
+		return out
+	}
+
+	tests := []struct {
+		from     time.Time
+		through  time.Time
+		name     string
+		existing []string
+		want     []string
+	}{
+		{
+			name:     "fresh tables",
+			existing: []string{"p0", "pmax"},
+			from:     day(2023, 11, 11),
+			through:  day(2024, 1, 31),
+			want:     []string{"p202311", "p202312", "p202401"},
+		},
+		{
+			name:     "after the last monthly partition",
+			existing: []string{"p0", "p202311", "p202312", "pmax"},
+			from:     day(2023, 11, 1),
+			through:  day(2024, 2, 1),
+			want:     []string{"p202401", "p202402"},
+		},
+		{
+			name:     "covered",
+			existing: []string{"p0", "p202311", "pmax"},
+			from:     day(2023, 11, 1),
+			through:  day(2023, 11, 30),
+			want:     []string{},
+		},
+		{
+			name:     "before the first monthly month",
+			existing: []string{"p0", "pmax"},
+			from:     day(2022, 11, 1),
+			through:  day(2023, 1, 1),
+			want:     []string{"p202301"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(missingPartitionMonths(tt.existing, tt.from, tt.through))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUpsertNewer(t *testing.T) {
+	got := upsertNewer("report_order", []string{"id", "create_time", "status", "committed_at"})
+	want := "INSERT INTO report_order (id, create_time, status, committed_at) VALUES (?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE status = IF(VALUES(committed_at) >= committed_at, VALUES(status), status), " +
+		"committed_at = GREATEST(committed_at, VALUES(committed_at))"
+	if got != want {
+		t.Errorf("unexpected upsert:\n%s", got)
+	}
+	if strings.Contains(got, "id = ") {
+		t.Errorf("the primary key must not be updated")
+	}
+}
@@ -0,0 +1,318 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Policies for queries without a sharding key.
+const (
+	// ShardPolicyFanOut runs them on every shard.
+	ShardPolicyFanOut = "fanout"
+	// ShardPolicyReject fails them with ErrShardKeyRequired.
+	ShardPolicyReject = "reject"
+)
+
+// DefaultShards is the number of logical shards of a sharded table: rows live in the table
+// suffixed with user_id % 32.
+const DefaultShards = 32
+
+// ErrShardKeyRequired is returned for a query without a sharding key when the policy rejects them.
+var ErrShardKeyRequired = errors.New("query without a sharding key rejected by the sharding policy")
+
+// ShardingConfig configures a ShardRouter.
+type ShardingConfig struct {
+	// Unkeyed is the policy for queries without a sharding key: fanout (default) or reject.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Unkeyed string `json:"unkeyed,optional"`
+
+	// Databases hold the logical shards, spread over them in contiguous ranges: with 32 shards
+	// and 4 databases, shards 0-7 live in the first one. Sharding is off when empty.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Databases []Config `json:"databases,optional"`
+	// Shards is the number of logical shards (default: 32). It cannot change without moving data.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Shards int `json:"shards,optional"`
+}
+
+// Enabled reports whether tables are sharded.
+func (c *ShardingConfig) Enabled() bool {
+	return len(c.Databases) > 0
+}
+
+// ShardRouter routes the queries of a group of binding tables, sharded by the same key, to the
+// database and tables of the shard of the key.
+//
+// Queries name the logical tables, e.g. trade_order; the router rewrites them to the physical
+// tables of a shard, e.g. trade_order_07. Table names are replaced wherever they appear as whole
+// words, so they must not be used as column names or inside string literals. Other tables are
+// left alone and resolve to the database of the shard.
+//
+// Writes and transactions go to the primary of a database; reads outside transactions go to its
+// replicas, as Client.ReadDB picks them.
+type ShardRouter struct {
+	tables  *regexp.Regexp // Matches the sharded tables; nil when unsharded
+	shards  []*Shard
+	clients []*Client
+	owned   bool // Whether Close closes the clients
+	reject  bool
+}
+
+// NewShardRouter connects to the databases of c and routes the queries of tables, which are
+// sharded by the same key.
+func NewShardRouter(c *ShardingConfig, tables ...string) (*ShardRouter, error) {
+	if c == nil || !c.Enabled() {
+		return nil, fmt.Errorf("sharding databases are required")
+	}
+	clients := make([]*Client, 0, len(c.Databases))
+	closeAll := func() {
+		for _, client := range clients {
+			_ = client.Close()
+		}
+	}
+	for i := range c.Databases {
+		client, err := NewClient(&c.Databases[i])
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to connect to shard database %d: %w", i, err)
+		}
+		clients = append(clients, client)
+	}
+
+	r, err := newShardRouter(clients, c, tables)
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	r.owned = true
+	return r, nil
+}
+
+// NewUnshardedRouter routes every query to client unchanged. Repositories take a ShardRouter
+// either way, so that sharding is a matter of configuration.
+func NewUnshardedRouter(client *Client) *ShardRouter {
+	r := &ShardRouter{clients: []*Client{client}}
+	r.shards = []*Shard{{router: r, client: client}}
+	return r
+}
+
+// newShardRouter creates a router over connected databases.
+func newShardRouter(clients []*Client, c *ShardingConfig, tables []string) (*ShardRouter, error) {
+	shards := c.Shards
+	if shards == 0 {
+		shards = DefaultShards
+	}
+	if shards < len(clients) {
+		return nil, fmt.Errorf("shards (%d) cannot be fewer than databases (%d)", shards, len(clients))
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("sharded tables are required")
+	}
+	r := &ShardRouter{clients: clients}
+	switch c.Unkeyed {
+	case "", ShardPolicyFanOut:
+	case ShardPolicyReject:
+		r.reject = true
+	default:
+		return nil, fmt.Errorf("unknown policy for queries without a sharding key: %q", c.Unkeyed)
+	}
+
+	// Longest first, so that trade_order_item is not taken for trade_order.
+	names := make([]string, len(tables))
+	for i, table := range tables {
+		names[i] = regexp.QuoteMeta(table)
+	}
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	r.tables = regexp.MustCompile(`\b(` + strings.Join(names, "|") + `)\b`)
+
+	width := len(strconv.Itoa(shards - 1))
+	r.shards = make([]*Shard, shards)
+	for i := range r.shards {
+		r.shards[i] = &Shard{
+			router: r,
+			client: clients[i*len(clients)/shards],
+			suffix: fmt.Sprintf("_%0*d", width, i),
+			index:  i,
+		}
+	}
+	return r, nil
+}
+
+// Route returns the shard of the sharding key, e.g. a user ID.
+func (r *ShardRouter) Route(key int64) *Shard {
+	n := int64(len(r.shards))
+	return r.shards[((key%n)+n)%n]
+}
+
+// Shards returns every shard, in order.
+func (r *ShardRouter) Shards() []*Shard {
+	return r.shards
+}
+
+// Databases returns the primaries of the databases holding the shards, e.g. to run a relay or a
+// migration in each.
+func (r *ShardRouter) Databases() []*sql.DB {
+	dbs := make([]*sql.DB, len(r.clients))
+	for i, client := range r.clients {
+		dbs[i] = client.DB()
+	}
+	return dbs
+}
+
+// Close closes the databases the router connected to. Databases passed in are left open.
+func (r *ShardRouter) Close() error {
+	if !r.owned {
+		return nil
+	}
+	var errs []error
+	for _, client := range r.clients {
+		if err := client.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FanOut runs fn on every shard concurrently, for a query without a sharding key, and returns
+// the results in shard order. It fails with ErrShardKeyRequired if the policy rejects such
+// queries, and with the errors of fn otherwise.
+func FanOut[T any](ctx context.Context, r *ShardRouter, fn func(ctx context.Context, s *Shard) (T, error)) ([]T, error) {
+	if len(r.shards) == 1 {
+		result, err := fn(ctx, r.shards[0])
+		if err != nil {
+			return nil, err
+		}
+		return []T{result}, nil
+	}
+	if r.reject {
+		return nil, ErrShardKeyRequired
+	}
+
+	results := make([]T, len(r.shards))
+	errs := make([]error, len(r.shards))
+	var wg sync.WaitGroup
+	for i, s := range r.shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = fn(ctx, s)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ExecAll executes query, which has no sharding key, on every shard as FanOut does, and returns
+// the number of rows affected in all of them.
+func (r *ShardRouter) ExecAll(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	affected, err := FanOut(ctx, r, func(ctx context.Context, s *Shard) (int64, error) {
+		result, err := s.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	})
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, n := range affected {
+		total += n
+	}
+	return total, nil
+}
+
+// Shard is one logical shard: a suffix for the sharded tables and the database holding them.
+// It runs queries on the logical tables against the tables of the shard.
+type Shard struct {
+	router *ShardRouter
+	client *Client
+	suffix string // Empty when unsharded
+	index  int
+}
+
+// Index returns the number of the shard.
+func (s *Shard) Index() int {
+	return s.index
+}
+
+// DB returns the primary of the database holding the shard.
+func (s *Shard) DB() *sql.DB {
+	return s.client.DB()
+}
+
+// Rewrite replaces the logical tables in query with the tables of the shard.
+func (s *Shard) Rewrite(query string) string {
+	if s.suffix == "" {
+		return query
+	}
+	return s.router.tables.ReplaceAllString(query, "${1}"+s.suffix)
+}
+
+// ExecContext executes query on the primary of the shard.
+func (s *Shard) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.client.DB().ExecContext(ctx, s.Rewrite(query), args...)
+}
+
+// QueryContext runs query on a replica of the shard, or on the primary if ctx forces it or
+// carries a transaction (see Client.ReadDB).
+func (s *Shard) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return s.client.ReadDB(ctx).QueryContext(ctx, s.Rewrite(query), args...)
+}
+
+// QueryRowContext runs query, which returns at most one row, on a replica of the shard, or on the
+// primary if ctx forces it or carries a transaction.
+func (s *Shard) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return s.client.ReadDB(ctx).QueryRowContext(ctx, s.Rewrite(query), args...)
+}
+
+// BeginTx starts a transaction on the primary of the shard.
+func (s *Shard) BeginTx(ctx context.Context, opts *sql.TxOptions) (*ShardTx, error) {
+	tx, err := s.client.DB().BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &ShardTx{tx: tx, shard: s}, nil
+}
+
+// ShardTx is a transaction in the database of a shard. Like Shard, it rewrites queries on the
+// logical tables; other tables of the database, such as outbox_event, take part in it as usual.
+type ShardTx struct {
+	tx    *sql.Tx
+	shard *Shard
+}
+
+// ExecContext executes query in the transaction.
+func (t *ShardTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return t.tx.ExecContext(ctx, t.shard.Rewrite(query), args...)
+}
+
+// QueryContext runs query in the transaction.
+func (t *ShardTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return t.tx.QueryContext(ctx, t.shard.Rewrite(query), args...)
+}
+
+// QueryRowContext runs query, which returns at most one row, in the transaction.
+func (t *ShardTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return t.tx.QueryRowContext(ctx, t.shard.Rewrite(query), args...)
+}
+
+// Commit commits the transaction.
+func (t *ShardTx) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback aborts the transaction.
+func (t *ShardTx) Rollback() error {
+	return t.tx.Rollback()
+}
@@ -0,0 +1,207 @@
+//go:build integration
+// +build integration
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// shardTestDSNEnv names a MySQL server allowed to create and drop the test schemas, e.g.
+// root:root@tcp(localhost:3306)/.
+const shardTestDSNEnv = "SHARDING_TEST_MYSQL_DSN"
+
+// shardTestSchemas are the local schemas standing in for the sharding databases.
+var shardTestSchemas = []string{"aether_shard_test_0", "aether_shard_test_1"}
+
+// shardTestTables is a pair of binding tables, created in each shard.
+var shardTestTables = []string{
+	`CREATE TABLE shard_test_order (
+	  id BIGINT NOT NULL,
+	  user_id BIGINT NOT NULL,
+	  status TINYINT NOT NULL,
+	  PRIMARY KEY (id)
+	)`,
+	`CREATE TABLE shard_test_order_item (
+	  id BIGINT NOT NULL,
+	  order_id BIGINT NOT NULL,
+	  user_id BIGINT NOT NULL,
+	  PRIMARY KEY (id)
+	)`,
+}
+
+// setupShardSchemas creates the test schemas with the tables of shards shards, and returns a
+// router over them.
+func setupShardSchemas(t *testing.T, shards int, unkeyed string) *ShardRouter {
+	t.Helper()
+	dsn := os.Getenv(shardTestDSNEnv)
+	if dsn == "" {
+		t.Skipf("Set %s to run sharding integration tests.", shardTestDSNEnv)
+	}
+	base, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		t.Fatalf("invalid %s: %v", shardTestDSNEnv, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	server, err := sql.Open("mysql", base.FormatDSN())
+	if err != nil {
+		t.Fatalf("failed to open MySQL: %v", err)
+	}
+	defer func() { _ = server.Close() }()
+	if err := server.PingContext(ctx); err != nil {
+		t.Skipf("MySQL not available for integration test: %v", err)
+	}
+
+	c := &ShardingConfig{Shards: shards, Unkeyed: unkeyed}
+	for _, schema := range shardTestSchemas {
+		for _, stmt := range []string{
+			"DROP DATABASE IF EXISTS " + schema,
+			"CREATE DATABASE " + schema,
+		} {
+			if _, err := server.ExecContext(ctx, stmt); err != nil {
+				t.Fatalf("failed to create schema %s: %v", schema, err)
+			}
+		}
+		cfg := base.Clone()
+		cfg.DBName = schema
+		c.Databases = append(c.Databases, Config{DSN: cfg.FormatDSN()})
+	}
+	t.Cleanup(func() {
+		dropDB, err := sql.Open("mysql", base.FormatDSN())
+		if err != nil {
+			return
+		}
+		defer func() { _ = dropDB.Close() }()
+		for _, schema := range shardTestSchemas {
+			_, _ = dropDB.Exec("DROP DATABASE IF EXISTS " + schema)
+		}
+	})
+
+	r, err := NewShardRouter(c, "shard_test_order", "shard_test_order_item")
+	if err != nil {
+		t.Fatalf("failed to create router: %v", err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+
+	// The DDL names the logical tables too: each shard creates its own.
+	for _, s := range r.Shards() {
+		for _, ddl := range shardTestTables {
+			if _, err := s.ExecContext(ctx, ddl); err != nil {
+				t.Fatalf("failed to create tables of shard %d: %v", s.Index(), err)
+			}
+		}
+	}
+	return r
+}
+
+// countRows counts the rows of a physical table in the schema of db.
+func countRows(t *testing.T, db *sql.DB, table string) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatalf("failed to count rows of %s: %v", table, err)
+	}
+	return n
+}
+
+func TestIntegration_ShardRouter_RoutesToSchemas(t *testing.T) {
+	r := setupShardSchemas(t, 4, ShardPolicyFanOut)
+	ctx := context.Background()
+	dbs := r.Databases()
+
+	// Users 1 and 6 land in shards 1 and 2, in the first and second schema.
+	for _, userID := range []int64{1, 6} {
+		tx, err := r.Route(userID).BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("failed to begin transaction: %v", err)
+		}
+		orderID := userID * 100
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO shard_test_order (id, user_id, status) VALUES (?, ?, 1)", orderID, userID); err != nil {
+			t.Fatalf("failed to insert order: %v", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO shard_test_order_item (id, order_id, user_id) VALUES (?, ?, ?)",
+			orderID+1, orderID, userID); err != nil {
+			t.Fatalf("failed to insert order item: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+	}
+
+	if got := countRows(t, dbs[0], "shard_test_order_1"); got != 1 {
+		t.Errorf("expected the order of user 1 in the first schema, got %d rows", got)
+	}
+	if got := countRows(t, dbs[0], "shard_test_order_item_1"); got != 1 {
+		t.Errorf("expected the item of user 1 beside its order, got %d rows", got)
+	}
+	if got := countRows(t, dbs[1], "shard_test_order_2"); got != 1 {
+		t.Errorf("expected the order of user 6 in the second schema, got %d rows", got)
+	}
+
+	// Keyed reads only see their shard.
+	var status int
+	err := r.Route(6).QueryRowContext(ctx, "SELECT status FROM shard_test_order WHERE id = ?", 100).Scan(&status)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected no order of user 1 in the shard of user 6, got %v", err)
+	}
+
+	// Unkeyed reads and writes reach every schema.
+	found, err := FanOut(ctx, r, func(ctx context.Context, s *Shard) (int, error) {
+		var n int
+		err := s.QueryRowContext(ctx, "SELECT COUNT(*) FROM shard_test_order").Scan(&n)
+		return n, err
+	})
+	if err != nil {
+		t.Fatalf("failed to fan out: %v", err)
+	}
+	total := 0
+	for _, n := range found {
+		total += n
+	}
+	if total != 2 {
+		t.Errorf("expected 2 orders over all shards, got %d", total)
+	}
+
+	affected, err := r.ExecAll(ctx, "UPDATE shard_test_order SET status = 2 WHERE id = ?", 600)
+	if err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	if affected != 1 {
+		t.Errorf("expected 1 row updated, got %d", affected)
+	}
+}
+
+func TestIntegration_ShardRouter_RollbackStaysInShard(t *testing.T) {
+	r := setupShardSchemas(t, 4, ShardPolicyReject)
+	ctx := context.Background()
+
+	tx, err := r.Route(3).BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO shard_test_order (id, user_id, status) VALUES (?, ?, 1)", 300, 3); err != nil {
+		t.Fatalf("failed to insert order: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("failed to roll back: %v", err)
+	}
+	if got := countRows(t, r.Route(3).DB(), "shard_test_order_3"); got != 0 {
+		t.Errorf("expected the rollback to discard the order, got %d rows", got)
+	}
+
+	if _, err := r.ExecAll(ctx, "DELETE FROM shard_test_order"); !errors.Is(err, ErrShardKeyRequired) {
+		t.Errorf("expected unkeyed writes to be rejected, got %v", err)
+	}
+}
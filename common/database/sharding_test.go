@@ -0,0 +1,200 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// openShardClients opens n databases without connecting to them.
+func openShardClients(t *testing.T, n int) []*Client {
+	t.Helper()
+	clients := make([]*Client, n)
+	for i := range clients {
+		db, err := sql.Open("mysql", fmt.Sprintf("user@tcp(127.0.0.1:1)/shard%d", i))
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		clients[i] = &Client{db: db}
+	}
+	return clients
+}
+
+func newTestShardRouter(t *testing.T, clients []*Client, c *ShardingConfig) *ShardRouter {
+	t.Helper()
+	r, err := newShardRouter(clients, c, []string{"trade_order", "trade_order_item"})
+	if err != nil {
+		t.Fatalf("failed to create router: %v", err)
+	}
+	return r
+}
+
+func TestShardRouter_Route(t *testing.T) {
+	clients := openShardClients(t, 4)
+	r := newTestShardRouter(t, clients, &ShardingConfig{})
+	if got := len(r.Shards()); got != DefaultShards {
+		t.Fatalf("expected %d shards, got %d", DefaultShards, got)
+	}
+	tests := []struct {
+		key   int64
+		shard int
+		db    int
+	}{
+		{key: 0, shard: 0, db: 0},
+		{key: 7, shard: 7, db: 0},
+		{key: 8, shard: 8, db: 1},
+		{key: 1031, shard: 7, db: 0},
+		{key: 63, shard: 31, db: 3},
+		{key: -1, shard: 31, db: 3},
+	}
+	for _, tt := range tests {
+		s := r.Route(tt.key)
+		if s.Index() != tt.shard {
+			t.Errorf("key %d: expected shard %d, got %d", tt.key, tt.shard, s.Index())
+		}
+		if s.DB() != clients[tt.db].DB() {
+			t.Errorf("key %d: expected database %d", tt.key, tt.db)
+		}
+	}
+}
+
+func TestShard_Rewrite(t *testing.T) {
+	r := newTestShardRouter(t, openShardClients(t, 1), &ShardingConfig{})
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			query: "SELECT id FROM trade_order WHERE user_id = ?",
+			want:  "SELECT id FROM trade_order_07 WHERE user_id = ?",
+		},
+		{
+			query: "SELECT i.id FROM trade_order_item i JOIN trade_order o ON o.id = i.order_id",
+			want:  "SELECT i.id FROM trade_order_item_07 i JOIN trade_order_07 o ON o.id = i.order_id",
+		},
+		{
+			query: "INSERT INTO `trade_order` (id) VALUES (?)",
+			want:  "INSERT INTO `trade_order_07` (id) VALUES (?)",
+		},
+		{
+			query: "INSERT INTO outbox_event (aggregate_type) VALUES ('trade_orders')",
+			want:  "INSERT INTO outbox_event (aggregate_type) VALUES ('trade_orders')",
+		},
+	}
+	for _, tt := range tests {
+		if got := r.Route(7).Rewrite(tt.query); got != tt.want {
+			t.Errorf("Rewrite(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	// Suffixes are as wide as the largest shard number.
+	r = newTestShardRouter(t, openShardClients(t, 1), &ShardingConfig{Shards: 4})
+	if got := r.Route(3).Rewrite("trade_order"); got != "trade_order_3" {
+		t.Errorf("expected trade_order_3, got %q", got)
+	}
+}
+
+func TestUnshardedRouter(t *testing.T) {
+	client := openShardClients(t, 1)[0]
+	r := NewUnshardedRouter(client)
+
+	s := r.Route(12345)
+	if s.DB() != client.DB() {
+		t.Fatalf("expected the only database")
+	}
+	const query = "SELECT id FROM trade_order"
+	if got := s.Rewrite(query); got != query {
+		t.Errorf("expected the query unchanged, got %q", got)
+	}
+	if err := r.Close(); err != nil {
+		t.Errorf("expected Close to succeed, got %v", err)
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	r := newTestShardRouter(t, openShardClients(t, 2), &ShardingConfig{Shards: 8})
+
+	var calls atomic.Int32
+	results, err := FanOut(context.Background(), r, func(_ context.Context, s *Shard) (int, error) {
+		calls.Add(1)
+		return s.Index(), nil
+	})
+	if err != nil {
+		t.Fatalf("expected FanOut to succeed, got %v", err)
+	}
+	if calls.Load() != 8 {
+		t.Errorf("expected 8 calls, got %d", calls.Load())
+	}
+	for i, got := range results {
+		if got != i {
+			t.Errorf("expected results in shard order, got %v", results)
+			break
+		}
+	}
+
+	failure := errors.New("shard down")
+	_, err = FanOut(context.Background(), r, func(_ context.Context, s *Shard) (int, error) {
+		if s.Index() == 5 {
+			return 0, failure
+		}
+		return 0, nil
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("expected the shard error, got %v", err)
+	}
+}
+
+func TestFanOut_Reject(t *testing.T) {
+	r := newTestShardRouter(t, openShardClients(t, 2), &ShardingConfig{Unkeyed: ShardPolicyReject})
+
+	_, err := FanOut(context.Background(), r, func(context.Context, *Shard) (int, error) {
+		t.Errorf("no shard must be queried")
+		return 0, nil
+	})
+	if !errors.Is(err, ErrShardKeyRequired) {
+		t.Errorf("expected ErrShardKeyRequired, got %v", err)
+	}
+	if _, err = r.ExecAll(context.Background(), "DELETE FROM trade_order"); !errors.Is(err, ErrShardKeyRequired) {
+		t.Errorf("expected ErrShardKeyRequired from ExecAll, got %v", err)
+	}
+
+	// A single shard has every key, so nothing needs rejecting.
+	unsharded := NewUnshardedRouter(openShardClients(t, 1)[0])
+	unsharded.reject = true
+	results, err := FanOut(context.Background(), unsharded, func(context.Context, *Shard) (int, error) {
+		return 1, nil
+	})
+	if err != nil || len(results) != 1 {
+		t.Errorf("expected the single shard to be queried, got %v, %v", results, err)
+	}
+}
+
+func TestNewShardRouter_Invalid(t *testing.T) {
+	clients := openShardClients(t, 4)
+	tables := []string{"trade_order"}
+	tests := []struct {
+		config *ShardingConfig
+		name   string
+		tables []string
+	}{
+		{name: "fewer shards than databases", config: &ShardingConfig{Shards: 2}, tables: tables},
+		{name: "unknown policy", config: &ShardingConfig{Unkeyed: "random"}, tables: tables},
+		{name: "no tables", config: &ShardingConfig{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newShardRouter(clients, tt.config, tt.tables); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+
+	if _, err := NewShardRouter(&ShardingConfig{}, "trade_order"); err == nil {
+		t.Errorf("expected an error without databases")
+	}
+}
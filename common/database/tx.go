@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// MySQL errors after which a transaction is rolled back and worth running again.
+const (
+	mysqlErrLockWaitTimeout = 1205
+	mysqlErrDeadlock        = 1213
+)
+
+// Defaults of TxConfig.
+const (
+	defaultTxMaxAttempts = 3
+	defaultTxBaseBackoff = 20 * time.Millisecond
+	defaultTxMaxBackoff  = time.Second
+)
+
+// DBTX runs queries. *sql.DB, *sql.Tx, *Shard and *ShardTx satisfy it, so that a repository can
+// run the same queries inside or outside a transaction; see Executor.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// txKey is the context key of the transaction opened by WithTx.
+type txKey struct{}
+
+// txState is a transaction opened by WithTx and the database it runs on.
+type txState struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// TxConfig configures WithTx.
+type TxConfig struct {
+	Options     *sql.TxOptions // Isolation level and read-only mode; the database defaults when nil
+	MaxAttempts int            // Runs of the transaction, the first one included (default: 3)
+	BaseBackoff time.Duration  // Wait before the second run, doubled before each next one (default: 20ms)
+	MaxBackoff  time.Duration  // Upper bound of the wait (default: 1s)
+}
+
+// TxOption configures WithTx.
+type TxOption func(c *TxConfig)
+
+// WithTxOptions sets the isolation level and read-only mode of the transaction.
+func WithTxOptions(opts *sql.TxOptions) TxOption {
+	return func(c *TxConfig) {
+		c.Options = opts
+	}
+}
+
+// WithTxRetries sets how many times a transaction runs at most, and the backoff between runs.
+func WithTxRetries(maxAttempts int, baseBackoff, maxBackoff time.Duration) TxOption {
+	return func(c *TxConfig) {
+		c.MaxAttempts = maxAttempts
+		c.BaseBackoff = baseBackoff
+		c.MaxBackoff = maxBackoff
+	}
+}
+
+// WithTx runs fn in a transaction on db, committed if fn returns nil and rolled back otherwise.
+// The transaction travels in the context passed to fn: repositories reach it through Executor,
+// so that the writes of several of them, e.g. an order and its outbox event, commit together.
+//
+// If ctx already carries a transaction on db, fn joins it, and the outermost WithTx commits. A
+// transaction on another database is an error: the work could not be atomic.
+//
+// When the transaction fails on a deadlock or a lock wait timeout, MySQL has rolled it back, and
+// it runs again after a backoff, from the start of fn. fn must therefore be safe to repeat, and
+// keep its side effects outside of the database until WithTx returns.
+func WithTx(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error, opts ...TxOption) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		if state.db != db {
+			return fmt.Errorf("a transaction on another database is already open")
+		}
+		return fn(ctx)
+	}
+
+	c := TxConfig{MaxAttempts: defaultTxMaxAttempts, BaseBackoff: defaultTxBaseBackoff, MaxBackoff: defaultTxMaxBackoff}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 1
+	}
+
+	backoff := c.BaseBackoff
+	for attempt := 1; ; attempt++ {
+		err := runTx(ctx, db, c.Options, fn)
+		if err == nil || attempt >= c.MaxAttempts || !IsRetryableTx(err) {
+			return err
+		}
+
+		// Jitter keeps the transactions that deadlocked together from running into each other again.
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		logx.WithContext(ctx).Infof("transaction failed on attempt %d, retrying in %v: %v", attempt, wait, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, c.MaxBackoff)
+	}
+}
+
+// runTx runs fn in one transaction on db.
+func runTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(ctx context.Context) error) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			// The error of fn matters more than a failed rollback, which MySQL completes anyway
+			// when the connection is dropped.
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, &txState{db: db, tx: tx})); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Executor returns the transaction of ctx if it runs on db, and db otherwise.
+func Executor(ctx context.Context, db *sql.DB) DBTX {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.db == db {
+		return state.tx
+	}
+	return db
+}
+
+// InTx reports whether ctx carries a transaction opened by WithTx.
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
+
+// IsRetryableTx reports whether err, or an error it wraps, is a MySQL deadlock or lock wait
+// timeout, after which the transaction can run again.
+func IsRetryableTx(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == mysqlErrDeadlock || mysqlErr.Number == mysqlErrLockWaitTimeout
+}
+
+// WithTx runs fn in a transaction on the database of the shard; see WithTx.
+func (s *Shard) WithTx(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	return WithTx(ctx, s.DB(), fn, opts...)
+}
+
+// Executor returns the transaction of ctx, on the tables of the shard, if it runs on the database
+// of the shard, and the shard otherwise.
+func (s *Shard) Executor(ctx context.Context) DBTX {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.db == s.DB() {
+		return &ShardTx{tx: state.tx, shard: s}
+	}
+	return s
+}
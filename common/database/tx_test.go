@@ -0,0 +1,316 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// txDriver is a database/sql driver recording transactions. Statements containing "fail" fail
+// with the next error of errs, if any.
+type txDriver struct {
+	errs      []error
+	execs     []string
+	begins    int
+	commits   int
+	rollbacks int
+	mu        sync.Mutex
+}
+
+func (d *txDriver) Open(string) (driver.Conn, error) {
+	return &txConn{driver: d}, nil
+}
+
+// txConn is a connection of txDriver.
+type txConn struct {
+	driver *txDriver
+}
+
+func (c *txConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *txConn) Close() error {
+	return nil
+}
+
+func (c *txConn) Begin() (driver.Tx, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.begins++
+	return &txTx{driver: c.driver}, nil
+}
+
+func (c *txConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	d := c.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if strings.Contains(query, "fail") && len(d.errs) > 0 {
+		err := d.errs[0]
+		d.errs = d.errs[1:]
+		return nil, err
+	}
+	d.execs = append(d.execs, query)
+	return txResult{}, nil
+}
+
+func (c *txConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil // Accept any argument, such as the []byte payload of an outbox event
+}
+
+// txResult is the result of every statement: one row affected, with ID 1.
+type txResult struct{}
+
+func (txResult) LastInsertId() (int64, error) { return 1, nil }
+func (txResult) RowsAffected() (int64, error) { return 1, nil }
+
+// txTx is a transaction of txDriver.
+type txTx struct {
+	driver *txDriver
+}
+
+func (t *txTx) Commit() error {
+	t.driver.mu.Lock()
+	defer t.driver.mu.Unlock()
+	t.driver.commits++
+	return nil
+}
+
+func (t *txTx) Rollback() error {
+	t.driver.mu.Lock()
+	defer t.driver.mu.Unlock()
+	t.driver.rollbacks++
+	return nil
+}
+
+// txDriverSeq names the registered txDrivers.
+var txDriverSeq atomic.Int64
+
+// openTxDB returns a database of a new txDriver failing with errs.
+func openTxDB(t *testing.T, errs ...error) (*sql.DB, *txDriver) {
+	t.Helper()
+	d := &txDriver{errs: errs}
+	name := fmt.Sprintf("txtest%d", txDriverSeq.Add(1))
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db, d
+}
+
+// noBackoff runs transactions up to 3 times without waiting.
+var noBackoff = WithTxRetries(3, 0, 0)
+
+func TestWithTx_Commit(t *testing.T) {
+	db, d := openTxDB(t)
+	ctx := context.Background()
+
+	err := WithTx(ctx, db, func(ctx context.Context) error {
+		if _, ok := Executor(ctx, db).(*sql.Tx); !ok {
+			t.Errorf("expected the transaction inside WithTx")
+		}
+		if !InTx(ctx) {
+			t.Errorf("expected the context to carry the transaction")
+		}
+		_, err := Executor(ctx, db).ExecContext(ctx, "INSERT INTO trade_order VALUES (1)")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.begins != 1 || d.commits != 1 || d.rollbacks != 0 {
+		t.Errorf("expected one committed transaction, got %d begins, %d commits, %d rollbacks",
+			d.begins, d.commits, d.rollbacks)
+	}
+	if got := Executor(ctx, db); got != db {
+		t.Errorf("expected the database outside WithTx")
+	}
+}
+
+func TestWithTx_Rollback(t *testing.T) {
+	db, d := openTxDB(t)
+	errBusiness := errors.New("coupon already used")
+
+	err := WithTx(context.Background(), db, func(context.Context) error {
+		return errBusiness
+	}, noBackoff)
+	if !errors.Is(err, errBusiness) {
+		t.Fatalf("expected the error of fn, got %v", err)
+	}
+	if d.begins != 1 || d.commits != 0 || d.rollbacks != 1 {
+		t.Errorf("expected one rolled back transaction, got %d begins, %d commits, %d rollbacks",
+			d.begins, d.commits, d.rollbacks)
+	}
+}
+
+func TestWithTx_Panic(t *testing.T) {
+	db, d := openTxDB(t)
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected the panic to propagate")
+		}
+		if d.rollbacks != 1 {
+			t.Errorf("expected the transaction rolled back, got %d rollbacks", d.rollbacks)
+		}
+	}()
+	_ = WithTx(context.Background(), db, func(context.Context) error {
+		panic("boom")
+	})
+}
+
+func TestWithTx_RetriesDeadlock(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	lockWait := &mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}
+	db, d := openTxDB(t, deadlock, lockWait)
+
+	runs := 0
+	err := WithTx(context.Background(), db, func(ctx context.Context) error {
+		runs++
+		_, err := Executor(ctx, db).ExecContext(ctx, "UPDATE fail")
+		return err
+	}, noBackoff)
+	if err != nil {
+		t.Fatalf("expected the third run to succeed, got %v", err)
+	}
+	if runs != 3 || d.commits != 1 || d.rollbacks != 2 {
+		t.Errorf("expected 3 runs, 1 commit and 2 rollbacks, got %d, %d and %d", runs, d.commits, d.rollbacks)
+	}
+}
+
+func TestWithTx_GivesUp(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: 1213}
+	db, _ := openTxDB(t, deadlock, deadlock, deadlock, deadlock)
+
+	runs := 0
+	err := WithTx(context.Background(), db, func(ctx context.Context) error {
+		runs++
+		_, err := Executor(ctx, db).ExecContext(ctx, "UPDATE fail")
+		return fmt.Errorf("failed to use coupon: %w", err)
+	}, noBackoff)
+	if !IsRetryableTx(err) {
+		t.Fatalf("expected the deadlock, got %v", err)
+	}
+	if runs != 3 {
+		t.Errorf("expected 3 runs, got %d", runs)
+	}
+
+	// Other errors are not retried.
+	db, _ = openTxDB(t, &mysql.MySQLError{Number: 1062})
+	runs = 0
+	_ = WithTx(context.Background(), db, func(ctx context.Context) error {
+		runs++
+		_, err := Executor(ctx, db).ExecContext(ctx, "INSERT fail")
+		return err
+	}, noBackoff)
+	if runs != 1 {
+		t.Errorf("expected a duplicate key not to be retried, got %d runs", runs)
+	}
+}
+
+func TestWithTx_StopsOnCancel(t *testing.T) {
+	db, _ := openTxDB(t, &mysql.MySQLError{Number: 1213}, &mysql.MySQLError{Number: 1213})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runs := 0
+	err := WithTx(ctx, db, func(ctx context.Context) error {
+		runs++
+		_, err := Executor(ctx, db).ExecContext(ctx, "UPDATE fail")
+		cancel()
+		return err
+	}, WithTxRetries(3, time.Hour, time.Hour))
+	if !IsRetryableTx(err) || runs != 1 {
+		t.Errorf("expected no retry after cancellation, got %d runs and %v", runs, err)
+	}
+}
+
+func TestWithTx_Nested(t *testing.T) {
+	db, d := openTxDB(t)
+	other, _ := openTxDB(t)
+
+	err := WithTx(context.Background(), db, func(ctx context.Context) error {
+		outer := Executor(ctx, db)
+		err := WithTx(ctx, db, func(ctx context.Context) error {
+			if Executor(ctx, db) != outer {
+				t.Errorf("expected the nested call to join the transaction")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if Executor(ctx, other) != other {
+			t.Errorf("expected another database outside the transaction")
+		}
+		return WithTx(ctx, other, func(context.Context) error { return nil })
+	})
+	if err == nil {
+		t.Errorf("expected a transaction on another database to fail")
+	}
+	if d.begins != 1 {
+		t.Errorf("expected a single transaction, got %d", d.begins)
+	}
+}
+
+func TestShard_Executor(t *testing.T) {
+	db, d := openTxDB(t)
+	r := newTestShardRouter(t, []*Client{{db: db}}, &ShardingConfig{Shards: 4})
+	shard := r.Route(6)
+
+	if shard.Executor(context.Background()) != shard {
+		t.Errorf("expected the shard outside a transaction")
+	}
+	err := shard.WithTx(context.Background(), func(ctx context.Context) error {
+		exec := shard.Executor(ctx)
+		if _, ok := exec.(*ShardTx); !ok {
+			t.Errorf("expected the transaction of the shard, got %T", exec)
+		}
+		if _, err := exec.ExecContext(ctx, "INSERT INTO trade_order VALUES (1)"); err != nil {
+			return err
+		}
+		return InsertOutboxEvent(ctx, Executor(ctx, shard.DB()), &OutboxEvent{})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(d.execs) != 2 || d.execs[0] != "INSERT INTO trade_order_2 VALUES (1)" ||
+		!strings.Contains(d.execs[1], "INSERT INTO outbox_event") {
+		t.Errorf("expected the order in its shard and the event beside it, got %q", d.execs)
+	}
+	if d.begins != 1 || d.commits != 1 {
+		t.Errorf("expected one committed transaction, got %d begins and %d commits", d.begins, d.commits)
+	}
+}
+
+func TestIsRetryableTx(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		want bool
+	}{
+		{name: "deadlock", err: &mysql.MySQLError{Number: 1213}, want: true},
+		{name: "lock wait timeout", err: &mysql.MySQLError{Number: 1205}, want: true},
+		{name: "wrapped", err: fmt.Errorf("failed to create order: %w", &mysql.MySQLError{Number: 1213}), want: true},
+		{name: "duplicate entry", err: &mysql.MySQLError{Number: 1062}, want: false},
+		{name: "other error", err: errors.New("deadlock"), want: false},
+		{name: "nil", err: nil, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableTx(tt.err); got != tt.want {
+				t.Errorf("IsRetryableTx() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+// Package deadletter lists the messages consumer groups gave up on, with their decoded events and
+// the reasons they failed, and replays them to their original topic or to a sandbox topic.
+// The trade admin RPCs and the dlq-admin command are built on it.
+package deadletter
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/mq"
+)
+
+// Defaults of Config and Filter.
+const (
+	defaultGroup     = "dlq-inspector"
+	defaultScanLimit = 1000
+	defaultListLimit = 50
+)
+
+// PropertyDryRun marks messages replayed to the sandbox topic, so that sandbox consumers can tell
+// them apart from the traffic they mirror.
+const PropertyDryRun = "x-dry-run"
+
+// Config configures dead-letter inspection.
+type Config struct {
+	// Group is the consumer group dead-letter topics are read as (default: "dlq-inspector").
+	// It must not be used by any application consumer.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Group string `json:"group,optional" yaml:"group"`
+	// SandboxTopic receives dry-run replays; dry runs are rejected when it is unset.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	SandboxTopic string `json:"sandboxTopic,optional" yaml:"sandboxTopic"`
+	// ScanLimit bounds the messages read from a dead-letter topic per call (default: 1000).
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	ScanLimit int `json:"scanLimit,optional" yaml:"scanLimit"`
+}
+
+// GetGroup returns the consumer group, defaulting to "dlq-inspector".
+func (c Config) GetGroup() string {
+	if c.Group == "" {
+		return defaultGroup
+	}
+	return c.Group
+}
+
+// GetScanLimit returns the scan limit, defaulting to 1000.
+func (c Config) GetScanLimit() int {
+	if c.ScanLimit <= 0 {
+		return defaultScanLimit
+	}
+	return c.ScanLimit
+}
+
+// ReaderConfig returns the RocketMQ configuration to read dead-letter topics with: the name
+// servers of base with the inspection group.
+func (c Config) ReaderConfig(base mq.Config) *mq.Config {
+	return &mq.Config{NameServer: base.NameServer, Group: c.GetGroup()}
+}
+
+// AuditLog records replays. *database.DeadLetterReplayStore implements it.
+type AuditLog interface {
+	Insert(ctx context.Context, replay *database.DeadLetterReplay) error
+}
+
+// DeadLetter is a dead-lettered message with what is known about it.
+type DeadLetter struct {
+	Message *mq.Message
+	// Event is the event the message carries, upcast to the current version; nil if it carries
+	// none, in which case DecodeErr says why.
+	Event     *event.Event
+	DecodeErr error
+	// Failure is the last recorded failed delivery; nil when none was recorded.
+	Failure *mq.ConsumeFailure
+	Group   string
+	// OriginTopic is the topic the message was sent to before it was dead-lettered.
+	OriginTopic string
+}
+
+// EventType returns the type of the event, or the message tag when it carries no event.
+func (d *DeadLetter) EventType() string {
+	if d.Event != nil {
+		return d.Event.Envelope.Type
+	}
+	return d.Message.Tag
+}
+
+// EventID returns the ID of the event, or "" when the message carries no event.
+func (d *DeadLetter) EventID() string {
+	if d.Event != nil {
+		return d.Event.Envelope.EventId
+	}
+	return ""
+}
+
+// OrderID returns the order the event is about, if its payload has an order ID.
+func (d *DeadLetter) OrderID() (int64, bool) {
+	if d.Event == nil {
+		return 0, false
+	}
+	payload, ok := d.Event.Payload.(interface{ GetOrderId() int64 })
+	if !ok {
+		return 0, false
+	}
+	return payload.GetOrderId(), true
+}
+
+// PayloadJSON returns the event payload as JSON, or "" when the message carries no event.
+func (d *DeadLetter) PayloadJSON() string {
+	if d.Event == nil {
+		return ""
+	}
+	out, err := protojson.Marshal(d.Event.Payload)
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+// Inspector lists and replays dead-lettered messages.
+type Inspector struct {
+	reader   mq.DeadLetterReader
+	producer mq.Producer
+	audit    AuditLog
+	failures mq.FailureLog
+	registry *event.Registry
+	config   Config
+}
+
+// Option configures an Inspector.
+type Option func(i *Inspector)
+
+// WithFailureLog sets where failure reasons are looked up. Without it, dead letters are listed
+// without reasons.
+func WithFailureLog(failures mq.FailureLog) Option {
+	return func(i *Inspector) {
+		i.failures = failures
+	}
+}
+
+// WithRegistry sets the registry events are decoded with (default: event.Default).
+func WithRegistry(registry *event.Registry) Option {
+	return func(i *Inspector) {
+		i.registry = registry
+	}
+}
+
+// NewInspector creates an Inspector that reads dead letters through reader, replays them through
+// producer and records replays in audit.
+func NewInspector(
+	cfg Config, reader mq.DeadLetterReader, producer mq.Producer, audit AuditLog, opts ...Option,
+) (*Inspector, error) {
+	if reader == nil {
+		return nil, fmt.Errorf("dead letter reader cannot be nil")
+	}
+	if producer == nil {
+		return nil, fmt.Errorf("producer cannot be nil")
+	}
+	if audit == nil {
+		return nil, fmt.Errorf("audit log cannot be nil")
+	}
+
+	i := &Inspector{
+		reader:   reader,
+		producer: producer,
+		audit:    audit,
+		registry: event.Default,
+		config:   cfg,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i, nil
+}
+
+// Filter selects dead letters.
+type Filter struct {
+	// Group is the consumer group whose dead letters are listed. Required.
+	Group string
+	// EventType keeps events of this type, or messages with this tag if they carry no event.
+	EventType string
+	// OrderID keeps events about this order.
+	OrderID int64
+	// Limit bounds the dead letters returned (default: 50).
+	Limit int
+}
+
+// List returns the dead letters of a group that match filter, oldest first. Only the first
+// Config.ScanLimit messages of the dead-letter topic are searched.
+func (i *Inspector) List(ctx context.Context, filter Filter) ([]*DeadLetter, error) {
+	if filter.Group == "" {
+		return nil, fmt.Errorf("consumer group is required")
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	all, err := i.read(ctx, filter.Group)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*DeadLetter
+	for _, d := range all {
+		if len(matched) == limit {
+			break
+		}
+		if filter.EventType != "" && d.EventType() != filter.EventType {
+			continue
+		}
+		if filter.OrderID != 0 {
+			if orderID, ok := d.OrderID(); !ok || orderID != filter.OrderID {
+				continue
+			}
+		}
+		matched = append(matched, d)
+	}
+
+	if err := i.attachFailures(ctx, filter.Group, matched); err != nil {
+		return nil, err
+	}
+	return matched, nil
+}
+
+// read reads and decodes the dead letters of group.
+func (i *Inspector) read(ctx context.Context, group string) ([]*DeadLetter, error) {
+	messages, err := i.reader.ReadDeadLetters(ctx, group, i.config.GetScanLimit())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letters of %s: %w", group, err)
+	}
+
+	dead := make([]*DeadLetter, 0, len(messages))
+	for _, msg := range messages {
+		d := &DeadLetter{Message: msg, Group: group, OriginTopic: msg.Property(mq.PropertyOriginTopic)}
+		d.Event, d.DecodeErr = i.registry.Parse(msg)
+		dead = append(dead, d)
+	}
+	return dead, nil
+}
+
+// attachFailures looks up the failure reasons of dead.
+func (i *Inspector) attachFailures(ctx context.Context, group string, dead []*DeadLetter) error {
+	if i.failures == nil || len(dead) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(dead))
+	for _, d := range dead {
+		ids = append(ids, d.Message.MsgID)
+	}
+	failures, err := i.failures.Failures(ctx, group, ids)
+	if err != nil {
+		return fmt.Errorf("failed to look up failure reasons: %w", err)
+	}
+	for _, d := range dead {
+		d.Failure = failures[d.Message.MsgID]
+	}
+	return nil
+}
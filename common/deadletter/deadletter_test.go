@@ -0,0 +1,280 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/mq"
+)
+
+const (
+	testTopic   = "orders"
+	testGroup   = "promotion"
+	testSandbox = "orders-sandbox"
+)
+
+// memoryFailureLog is an in-memory mq.FailureLog.
+type memoryFailureLog struct {
+	failures map[string]*mq.ConsumeFailure
+	mu       sync.Mutex
+}
+
+func (l *memoryFailureLog) RecordFailure(_ context.Context, group string, msg *mq.Message, reason string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures[group+"/"+msg.MsgID] = &mq.ConsumeFailure{
+		FailedAt: time.Now(), Reason: reason, ReconsumeTimes: msg.ReconsumeTimes,
+	}
+	return nil
+}
+
+func (l *memoryFailureLog) Failures(
+	_ context.Context, group string, msgIDs []string,
+) (map[string]*mq.ConsumeFailure, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make(map[string]*mq.ConsumeFailure)
+	for _, id := range msgIDs {
+		if f, ok := l.failures[group+"/"+id]; ok {
+			out[id] = f
+		}
+	}
+	return out, nil
+}
+
+// memoryAuditLog is an in-memory AuditLog.
+type memoryAuditLog struct {
+	err     error
+	replays []*database.DeadLetterReplay
+}
+
+func (l *memoryAuditLog) Insert(_ context.Context, replay *database.DeadLetterReplay) error {
+	if l.err != nil {
+		return l.err
+	}
+	l.replays = append(l.replays, replay)
+	return nil
+}
+
+type fixture struct {
+	broker    *mq.MemoryBroker
+	inspector *Inspector
+	audit     *memoryAuditLog
+	// healthy makes the consumer succeed from now on.
+	healthy  atomic.Bool
+	consumed atomic.Int32
+}
+
+// newFixture starts a consumer group that dead-letters every message on its first failure and
+// records why.
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{
+		broker: mq.NewMemoryBroker(mq.WithRetryDelay(time.Millisecond), mq.WithMaxReconsumeTimes(0)),
+		audit:  &memoryAuditLog{},
+	}
+	t.Cleanup(f.broker.Shutdown)
+
+	failures := &memoryFailureLog{failures: make(map[string]*mq.ConsumeFailure)}
+	consumer, err := f.broker.NewConsumer(testGroup)
+	require.NoError(t, err)
+	handler := event.Default.Handler(func(context.Context, *event.Event) error {
+		if !f.healthy.Load() {
+			return errors.New("inventory unavailable")
+		}
+		f.consumed.Add(1)
+		return nil
+	})
+	require.NoError(t, consumer.Subscribe(testTopic, "*", mq.RecordFailures(testGroup, failures, handler)))
+	require.NoError(t, consumer.Start())
+
+	f.inspector, err = NewInspector(Config{SandboxTopic: testSandbox}, f.broker, f.broker.NewProducer(), f.audit,
+		WithFailureLog(failures))
+	require.NoError(t, err)
+	return f
+}
+
+// publish sends msg and waits until it has been dead-lettered.
+func (f *fixture) publish(t *testing.T, msg *mq.Message) string {
+	t.Helper()
+	sent, err := f.broker.NewProducer().Send(context.Background(), msg)
+	require.NoError(t, err)
+	f.wait(t)
+	return sent.MsgID
+}
+
+func (f *fixture) wait(t *testing.T) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, f.broker.WaitIdle(ctx))
+}
+
+func newOrderMessage(t *testing.T, eventType string, orderID int64) *mq.Message {
+	t.Helper()
+	var payload proto.Message = &event.OrderPlaced{OrderId: orderID, UserId: 7, CourseIds: []int64{1}}
+	if eventType == event.TypeOrderCancelled {
+		payload = &event.OrderCancelled{OrderId: orderID, UserId: 7}
+	}
+	env, err := event.Default.NewEnvelope(eventType, strconv.FormatInt(orderID, 10), payload)
+	require.NoError(t, err)
+	msg, err := event.Default.NewMessage(testTopic, env)
+	require.NoError(t, err)
+	return msg
+}
+
+func TestInspector_List(t *testing.T) {
+	f := newFixture(t)
+	placed := f.publish(t, newOrderMessage(t, event.TypeOrderPlaced, 42))
+	f.publish(t, newOrderMessage(t, event.TypeOrderCancelled, 42))
+	f.publish(t, newOrderMessage(t, event.TypeOrderPlaced, 43))
+	garbage := f.publish(t, mq.NewMessage(testTopic, []byte("not an event")).WithTag("UNKNOWN"))
+
+	all, err := f.inspector.List(context.Background(), Filter{Group: testGroup})
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+
+	first := all[0]
+	assert.Equal(t, placed, first.Message.MsgID)
+	assert.Equal(t, testTopic, first.OriginTopic)
+	assert.Equal(t, event.TypeOrderPlaced, first.EventType())
+	assert.NotEmpty(t, first.EventID())
+	assert.JSONEq(t, `{"orderId":"42","userId":"7","courseIds":["1"]}`, first.PayloadJSON())
+	require.NotNil(t, first.Failure)
+	assert.Equal(t, "inventory unavailable", first.Failure.Reason)
+
+	last := all[3]
+	assert.Equal(t, garbage, last.Message.MsgID)
+	assert.Nil(t, last.Event)
+	assert.Error(t, last.DecodeErr)
+	assert.Equal(t, "UNKNOWN", last.EventType())
+	require.NotNil(t, last.Failure, "decoding failures are recorded too")
+
+	byOrder, err := f.inspector.List(context.Background(), Filter{Group: testGroup, OrderID: 42})
+	require.NoError(t, err)
+	assert.Len(t, byOrder, 2)
+
+	byTypeAndOrder, err := f.inspector.List(context.Background(),
+		Filter{Group: testGroup, EventType: event.TypeOrderPlaced, OrderID: 42})
+	require.NoError(t, err)
+	require.Len(t, byTypeAndOrder, 1)
+	assert.Equal(t, placed, byTypeAndOrder[0].Message.MsgID)
+
+	limited, err := f.inspector.List(context.Background(), Filter{Group: testGroup, Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, limited, 1)
+
+	_, err = f.inspector.List(context.Background(), Filter{})
+	assert.Error(t, err)
+}
+
+func TestInspector_Replay(t *testing.T) {
+	f := newFixture(t)
+	msgID := f.publish(t, newOrderMessage(t, event.TypeOrderPlaced, 42))
+	f.healthy.Store(true)
+
+	results, err := f.inspector.Replay(context.Background(), &ReplayRequest{
+		Group: testGroup, Operator: "ops", Reason: "inventory restored",
+		MsgIDs: []string{msgID, "missing", msgID},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2, "duplicate IDs are replayed once")
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, testTopic, results[0].TargetTopic)
+	assert.NotEmpty(t, results[0].NewMsgID)
+	assert.Error(t, results[1].Err)
+
+	f.wait(t)
+	assert.Equal(t, int32(1), f.consumed.Load(), "the replay is consumed")
+	replayed := f.broker.Messages(testTopic)
+	assert.Equal(t, msgID, replayed[len(replayed)-1].Property(mq.PropertyReplayOf))
+
+	require.Len(t, f.audit.replays, 2, "every attempt is audited")
+	ok := f.audit.replays[0]
+	assert.Equal(t, testGroup, ok.ConsumerGroup)
+	assert.Equal(t, msgID, ok.MsgID)
+	assert.Equal(t, event.TypeOrderPlaced, ok.EventType)
+	assert.NotEmpty(t, ok.EventID)
+	assert.Equal(t, results[0].NewMsgID, ok.NewMsgID)
+	assert.Equal(t, "ops", ok.Operator)
+	assert.Equal(t, "inventory restored", ok.Reason)
+	assert.False(t, ok.DryRun)
+	assert.Nil(t, ok.Error)
+	require.NotNil(t, f.audit.replays[1].Error)
+}
+
+func TestInspector_Replay_Sandbox(t *testing.T) {
+	f := newFixture(t)
+	msgID := f.publish(t, newOrderMessage(t, event.TypeOrderPlaced, 42))
+
+	results, err := f.inspector.Replay(context.Background(), &ReplayRequest{
+		Group: testGroup, Operator: "ops", MsgIDs: []string{msgID}, Target: TargetSandbox,
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, testSandbox, results[0].TargetTopic)
+
+	f.wait(t)
+	sandbox := f.broker.Messages(testSandbox)
+	require.Len(t, sandbox, 1)
+	assert.Equal(t, "true", sandbox[0].Property(PropertyDryRun))
+	assert.Len(t, f.broker.Messages(testTopic), 1, "nothing is replayed to the original topic")
+	assert.True(t, f.audit.replays[0].DryRun)
+}
+
+func TestInspector_Replay_Invalid(t *testing.T) {
+	f := newFixture(t)
+	msgID := f.publish(t, newOrderMessage(t, event.TypeOrderPlaced, 42))
+
+	tests := []struct {
+		req  *ReplayRequest
+		name string
+	}{
+		{name: "nil", req: nil},
+		{name: "no group", req: &ReplayRequest{Operator: "ops", MsgIDs: []string{msgID}}},
+		{name: "no operator", req: &ReplayRequest{Group: testGroup, MsgIDs: []string{msgID}}},
+		{name: "no messages", req: &ReplayRequest{Group: testGroup, Operator: "ops"}},
+		{name: "unknown target", req: &ReplayRequest{Group: testGroup, Operator: "ops", MsgIDs: []string{msgID}, Target: 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.inspector.Replay(context.Background(), tt.req)
+			assert.Error(t, err)
+		})
+	}
+
+	noSandbox, err := NewInspector(Config{}, f.broker, f.broker.NewProducer(), f.audit)
+	require.NoError(t, err)
+	_, err = noSandbox.Replay(context.Background(), &ReplayRequest{
+		Group: testGroup, Operator: "ops", MsgIDs: []string{msgID}, Target: TargetSandbox,
+	})
+	assert.Error(t, err)
+	assert.Empty(t, f.audit.replays)
+}
+
+func TestInspector_Replay_AuditFailure(t *testing.T) {
+	f := newFixture(t)
+	first := f.publish(t, newOrderMessage(t, event.TypeOrderPlaced, 42))
+	second := f.publish(t, newOrderMessage(t, event.TypeOrderPlaced, 43))
+	f.audit.err = errors.New("database unavailable")
+
+	results, err := f.inspector.Replay(context.Background(), &ReplayRequest{
+		Group: testGroup, Operator: "ops", MsgIDs: []string{first, second},
+	})
+	assert.Error(t, err)
+	assert.Empty(t, results)
+	f.wait(t)
+	assert.Len(t, f.broker.Messages(testTopic), 3, "replaying stops at the first unaudited attempt")
+}
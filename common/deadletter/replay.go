@@ -0,0 +1,164 @@
+package deadletter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/mq"
+)
+
+// Target is where dead letters are replayed to.
+type Target int
+
+const (
+	// TargetOrigin replays messages to the topic they were dead-lettered from. Every group that
+	// subscribes to the topic receives them again; groups that deduplicate by event ID (see
+	// mq.IdempotentHandler) drop the events they processed already.
+	TargetOrigin Target = iota
+	// TargetSandbox replays messages to Config.SandboxTopic, marked with PropertyDryRun, for a
+	// sandbox consumer to try them without touching production state.
+	TargetSandbox
+)
+
+// ReplayRequest selects the dead letters to replay.
+type ReplayRequest struct {
+	// Group is the consumer group that dead-lettered the messages. Required.
+	Group string
+	// Operator is who replays the messages, for the audit log. Required.
+	Operator string
+	// Reason says why the messages are replayed, for the audit log.
+	Reason string
+	// MsgIDs are the IDs of the dead-lettered messages. Required.
+	MsgIDs []string
+	Target Target
+}
+
+// ReplayResult is the outcome of replaying one message.
+type ReplayResult struct {
+	// Err is why the message was not replayed; nil when it was.
+	Err         error
+	MsgID       string
+	TargetTopic string
+	// NewMsgID is the ID of the replayed message.
+	NewMsgID string
+}
+
+// Replay sends the selected dead letters to the target and records every attempt in the audit
+// log. A message that cannot be replayed does not stop the others; its result carries the error.
+// Replayed messages stay in the dead-letter topic, which the broker expires on its own.
+//
+// An error is returned for an invalid request, when the dead letters cannot be read, or when an
+// attempt cannot be audited, in which case the remaining messages are not replayed.
+func (i *Inspector) Replay(ctx context.Context, req *ReplayRequest) ([]*ReplayResult, error) {
+	if req == nil {
+		return nil, fmt.Errorf("replay request cannot be nil")
+	}
+	if req.Group == "" {
+		return nil, fmt.Errorf("consumer group is required")
+	}
+	if req.Operator == "" {
+		return nil, fmt.Errorf("operator is required")
+	}
+	if len(req.MsgIDs) == 0 {
+		return nil, fmt.Errorf("no messages selected")
+	}
+	switch req.Target {
+	case TargetOrigin:
+	case TargetSandbox:
+		if i.config.SandboxTopic == "" {
+			return nil, fmt.Errorf("sandbox topic is not configured")
+		}
+	default:
+		return nil, fmt.Errorf("unknown replay target: %d", req.Target)
+	}
+
+	all, err := i.read(ctx, req.Group)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*DeadLetter, len(all))
+	for _, d := range all {
+		if _, ok := byID[d.Message.MsgID]; !ok {
+			byID[d.Message.MsgID] = d
+		}
+	}
+
+	results := make([]*ReplayResult, 0, len(req.MsgIDs))
+	seen := make(map[string]bool, len(req.MsgIDs))
+	for _, id := range req.MsgIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		d := byID[id]
+		result := i.replay(ctx, req, id, d)
+		if err := i.record(ctx, req, result, d); err != nil {
+			logx.WithContext(ctx).Errorf("failed to audit replay of message %s to %s (new message %q): %v",
+				id, result.TargetTopic, result.NewMsgID, err)
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// replay sends d, the dead letter with ID id or nil if it was not found, to the target.
+func (i *Inspector) replay(ctx context.Context, req *ReplayRequest, id string, d *DeadLetter) *ReplayResult {
+	result := &ReplayResult{MsgID: id}
+	if d == nil {
+		result.Err = fmt.Errorf("message %s not found in the dead-letter topic of %s", id, req.Group)
+		return result
+	}
+
+	switch req.Target {
+	case TargetSandbox:
+		result.TargetTopic = i.config.SandboxTopic
+	default:
+		result.TargetTopic = d.OriginTopic
+	}
+	if result.TargetTopic == "" {
+		result.Err = fmt.Errorf("original topic of message %s is unknown", id)
+		return result
+	}
+
+	msg := mq.NewReplayMessage(d.Message, result.TargetTopic)
+	if req.Target == TargetSandbox {
+		msg.WithProperty(PropertyDryRun, "true")
+	}
+	sent, err := i.producer.Send(mq.ExtractContext(ctx, d.Message), msg)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to replay message %s: %w", id, err)
+		return result
+	}
+	result.NewMsgID = sent.MsgID
+	return result
+}
+
+// record writes result to the audit log.
+func (i *Inspector) record(ctx context.Context, req *ReplayRequest, result *ReplayResult, d *DeadLetter) error {
+	replay := &database.DeadLetterReplay{
+		ConsumerGroup: req.Group,
+		MsgID:         result.MsgID,
+		TargetTopic:   result.TargetTopic,
+		DryRun:        req.Target == TargetSandbox,
+		NewMsgID:      result.NewMsgID,
+		Operator:      req.Operator,
+		Reason:        req.Reason,
+	}
+	if d != nil {
+		replay.EventID = d.EventID()
+		replay.EventType = d.EventType()
+	}
+	if result.Err != nil {
+		msg := result.Err.Error()
+		replay.Error = &msg
+	}
+	if err := i.audit.Insert(ctx, replay); err != nil {
+		return fmt.Errorf("failed to audit replay: %w", err)
+	}
+	return nil
+}
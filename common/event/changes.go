@@ -0,0 +1,18 @@
+package event
+
+import "google.golang.org/protobuf/proto"
+
+// Change event types, published by the CDC service for every row change of the order tables it
+// reads from the binlog. Each is also the tag of the messages that carry it.
+const (
+	TypeOrderRowChanged     = "ORDER_ROW_CHANGED"
+	TypeOrderItemRowChanged = "ORDER_ITEM_ROW_CHANGED"
+)
+
+// RegisterChangeEvents registers the change event types with r.
+func RegisterChangeEvents(r *Registry) error {
+	if err := r.Register(TypeOrderRowChanged, 1, func() proto.Message { return &OrderRowChanged{} }); err != nil {
+		return err
+	}
+	return r.Register(TypeOrderItemRowChanged, 1, func() proto.Message { return &OrderItemRowChanged{} })
+}
@@ -0,0 +1,1028 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v3.21.12
+// source: common/event/event.proto
+
+package event
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Kind of change of a table row
+type RowOp int32
+
+const (
+	RowOp_ROW_OP_UNSPECIFIED RowOp = 0
+	RowOp_ROW_OP_INSERT      RowOp = 1
+	RowOp_ROW_OP_UPDATE      RowOp = 2
+	RowOp_ROW_OP_DELETE      RowOp = 3
+)
+
+// Enum value maps for RowOp.
+var (
+	RowOp_name = map[int32]string{
+		0: "ROW_OP_UNSPECIFIED",
+		1: "ROW_OP_INSERT",
+		2: "ROW_OP_UPDATE",
+		3: "ROW_OP_DELETE",
+	}
+	RowOp_value = map[string]int32{
+		"ROW_OP_UNSPECIFIED": 0,
+		"ROW_OP_INSERT":      1,
+		"ROW_OP_UPDATE":      2,
+		"ROW_OP_DELETE":      3,
+	}
+)
+
+func (x RowOp) Enum() *RowOp {
+	p := new(RowOp)
+	*p = x
+	return p
+}
+
+func (x RowOp) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RowOp) Descriptor() protoreflect.EnumDescriptor {
+	return file_common_event_event_proto_enumTypes[0].Descriptor()
+}
+
+func (RowOp) Type() protoreflect.EnumType {
+	return &file_common_event_event_proto_enumTypes[0]
+}
+
+func (x RowOp) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RowOp.Descriptor instead.
+func (RowOp) EnumDescriptor() ([]byte, []int) {
+	return file_common_event_event_proto_rawDescGZIP(), []int{0}
+}
+
+// Envelope wraps every event published between services
+type Envelope struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       string                 `protobuf:"bytes,1,opt,name=eventId,proto3" json:"eventId,omitempty"`         // Unique event ID, the same for every delivery of the event
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`               // Event type, also used as the message tag (e.g. ORDER_PLACED)
+	Version       int32                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`        // Schema version of the payload
+	OccurredAt    int64                  `protobuf:"varint,4,opt,name=occurredAt,proto3" json:"occurredAt,omitempty"`  // When the event happened (unix milliseconds), 0 if unknown
+	AggregateId   string                 `protobuf:"bytes,5,opt,name=aggregateId,proto3" json:"aggregateId,omitempty"` // ID of the entity the event is about, e.g. the order ID
+	Payload       []byte                 `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`         // Protobuf-encoded payload message of the type and version
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_common_event_event_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_common_event_event_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_common_event_event_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Envelope) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *Envelope) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Envelope) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Envelope) GetOccurredAt() int64 {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return 0
+}
+
+func (x *Envelope) GetAggregateId() string {
+	if x != nil {
+		return x.AggregateId
+	}
+	return ""
+}
+
+func (x *Envelope) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+// ORDER_PLACED payload, version 2 (version 1 was the JSON order message)
+type OrderPlaced struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=orderId,proto3" json:"orderId,omitempty"`            // Order ID
+	UserId        int64                  `protobuf:"varint,2,opt,name=userId,proto3" json:"userId,omitempty"`              // Buyer user ID
+	CourseIds     []int64                `protobuf:"varint,3,rep,packed,name=courseIds,proto3" json:"courseIds,omitempty"` // Purchased course ID list
+	RealAmount    int32                  `protobuf:"varint,4,opt,name=realAmount,proto3" json:"realAmount,omitempty"`      // Order actual payment amount (cents)
+	ItemIds       []int64                `protobuf:"varint,5,rep,packed,name=itemIds,proto3" json:"itemIds,omitempty"`     // Order item IDs, one per course ID in the same order; required
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderPlaced) Reset() {
+	*x = OrderPlaced{}
+	mi := &file_common_event_event_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderPlaced) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderPlaced) ProtoMessage() {}
+
+func (x *OrderPlaced) ProtoReflect() protoreflect.Message {
+	mi := &file_common_event_event_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderPlaced.ProtoReflect.Descriptor instead.
+func (*OrderPlaced) Descriptor() ([]byte, []int) {
+	return file_common_event_event_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OrderPlaced) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderPlaced) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *OrderPlaced) GetCourseIds() []int64 {
+	if x != nil {
+		return x.CourseIds
+	}
+	return nil
+}
+
+func (x *OrderPlaced) GetRealAmount() int32 {
+	if x != nil {
+		return x.RealAmount
+	}
+	return 0
+}
+
+func (x *OrderPlaced) GetItemIds() []int64 {
+	if x != nil {
+		return x.ItemIds
+	}
+	return nil
+}
+
+// ORDER_CANCELLED payload, version 1
+type OrderCancelled struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=orderId,proto3" json:"orderId,omitempty"`            // Order ID
+	UserId        int64                  `protobuf:"varint,2,opt,name=userId,proto3" json:"userId,omitempty"`              // Buyer user ID
+	CourseIds     []int64                `protobuf:"varint,3,rep,packed,name=courseIds,proto3" json:"courseIds,omitempty"` // Course IDs of the cancelled order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderCancelled) Reset() {
+	*x = OrderCancelled{}
+	mi := &file_common_event_event_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderCancelled) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderCancelled) ProtoMessage() {}
+
+func (x *OrderCancelled) ProtoReflect() protoreflect.Message {
+	mi := &file_common_event_event_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderCancelled.ProtoReflect.Descriptor instead.
+func (*OrderCancelled) Descriptor() ([]byte, []int) {
+	return file_common_event_event_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OrderCancelled) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderCancelled) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *OrderCancelled) GetCourseIds() []int64 {
+	if x != nil {
+		return x.CourseIds
+	}
+	return nil
+}
+
+// ORDER_PAID payload, version 1
+type OrderPaid struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=orderId,proto3" json:"orderId,omitempty"`       // Order ID
+	UserId        int64                  `protobuf:"varint,2,opt,name=userId,proto3" json:"userId,omitempty"`         // Buyer user ID
+	PayAmount     int32                  `protobuf:"varint,3,opt,name=payAmount,proto3" json:"payAmount,omitempty"`   // Paid amount (cents)
+	PayChannel    int32                  `protobuf:"varint,4,opt,name=payChannel,proto3" json:"payChannel,omitempty"` // Payment channel (1: Alipay, 2: WeChat)
+	OutTradeNo    string                 `protobuf:"bytes,5,opt,name=outTradeNo,proto3" json:"outTradeNo,omitempty"`  // Third-party transaction number
+	PayTime       int64                  `protobuf:"varint,6,opt,name=payTime,proto3" json:"payTime,omitempty"`       // Payment time (unix seconds)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderPaid) Reset() {
+	*x = OrderPaid{}
+	mi := &file_common_event_event_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderPaid) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderPaid) ProtoMessage() {}
+
+func (x *OrderPaid) ProtoReflect() protoreflect.Message {
+	mi := &file_common_event_event_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderPaid.ProtoReflect.Descriptor instead.
+func (*OrderPaid) Descriptor() ([]byte, []int) {
+	return file_common_event_event_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OrderPaid) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderPaid) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *OrderPaid) GetPayAmount() int32 {
+	if x != nil {
+		return x.PayAmount
+	}
+	return 0
+}
+
+func (x *OrderPaid) GetPayChannel() int32 {
+	if x != nil {
+		return x.PayChannel
+	}
+	return 0
+}
+
+func (x *OrderPaid) GetOutTradeNo() string {
+	if x != nil {
+		return x.OutTradeNo
+	}
+	return ""
+}
+
+func (x *OrderPaid) GetPayTime() int64 {
+	if x != nil {
+		return x.PayTime
+	}
+	return 0
+}
+
+// ORDER_REFUNDED payload, version 1
+type OrderRefunded struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RefundId      int64                  `protobuf:"varint,1,opt,name=refundId,proto3" json:"refundId,omitempty"`           // Refund ID
+	OrderId       int64                  `protobuf:"varint,2,opt,name=orderId,proto3" json:"orderId,omitempty"`             // Refunded order ID
+	UserId        int64                  `protobuf:"varint,3,opt,name=userId,proto3" json:"userId,omitempty"`               // Buyer user ID
+	Amount        int32                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`               // Refunded amount (cents)
+	CourseIds     []int64                `protobuf:"varint,5,rep,packed,name=courseIds,proto3" json:"courseIds,omitempty"`  // Course IDs of the refunded items
+	OrderRefunded bool                   `protobuf:"varint,6,opt,name=orderRefunded,proto3" json:"orderRefunded,omitempty"` // Whether the refund completed the order, whose coupons are then returned
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderRefunded) Reset() {
+	*x = OrderRefunded{}
+	mi := &file_common_event_event_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderRefunded) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderRefunded) ProtoMessage() {}
+
+func (x *OrderRefunded) ProtoReflect() protoreflect.Message {
+	mi := &file_common_event_event_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderRefunded.ProtoReflect.Descriptor instead.
+func (*OrderRefunded) Descriptor() ([]byte, []int) {
+	return file_common_event_event_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OrderRefunded) GetRefundId() int64 {
+	if x != nil {
+		return x.RefundId
+	}
+	return 0
+}
+
+func (x *OrderRefunded) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderRefunded) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *OrderRefunded) GetAmount() int32 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *OrderRefunded) GetCourseIds() []int64 {
+	if x != nil {
+		return x.CourseIds
+	}
+	return nil
+}
+
+func (x *OrderRefunded) GetOrderRefunded() bool {
+	if x != nil {
+		return x.OrderRefunded
+	}
+	return false
+}
+
+// Columns of a trade_order row
+type OrderRow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=orderId,proto3" json:"orderId,omitempty"`         // Order ID
+	UserId        int64                  `protobuf:"varint,2,opt,name=userId,proto3" json:"userId,omitempty"`           // Buyer user ID
+	Status        int32                  `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`           // Order status (1: PendingPayment, 2: Closed, 3: Paid, 4: Finished, 5: Refunded)
+	TotalAmount   int32                  `protobuf:"varint,4,opt,name=totalAmount,proto3" json:"totalAmount,omitempty"` // Total amount (cents)
+	PayAmount     int32                  `protobuf:"varint,5,opt,name=payAmount,proto3" json:"payAmount,omitempty"`     // Paid amount (cents)
+	PayChannel    int32                  `protobuf:"varint,6,opt,name=payChannel,proto3" json:"payChannel,omitempty"`   // Payment channel, 0 if not paid
+	OutTradeNo    string                 `protobuf:"bytes,7,opt,name=outTradeNo,proto3" json:"outTradeNo,omitempty"`    // Third-party transaction number, empty if not paid
+	PayTime       int64                  `protobuf:"varint,8,opt,name=payTime,proto3" json:"payTime,omitempty"`         // Payment time (unix seconds), 0 if not paid
+	CreateTime    int64                  `protobuf:"varint,9,opt,name=createTime,proto3" json:"createTime,omitempty"`   // Creation time (unix seconds)
+	UpdateTime    int64                  `protobuf:"varint,10,opt,name=updateTime,proto3" json:"updateTime,omitempty"`  // Last update time (unix seconds)
+	Version       int32                  `protobuf:"varint,11,opt,name=version,proto3" json:"version,omitempty"`        // Optimistic lock version
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderRow) Reset() {
+	*x = OrderRow{}
+	mi := &file_common_event_event_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderRow) ProtoMessage() {}
+
+func (x *OrderRow) ProtoReflect() protoreflect.Message {
+	mi := &file_common_event_event_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderRow.ProtoReflect.Descriptor instead.
+func (*OrderRow) Descriptor() ([]byte, []int) {
+	return file_common_event_event_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *OrderRow) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderRow) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *OrderRow) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *OrderRow) GetTotalAmount() int32 {
+	if x != nil {
+		return x.TotalAmount
+	}
+	return 0
+}
+
+func (x *OrderRow) GetPayAmount() int32 {
+	if x != nil {
+		return x.PayAmount
+	}
+	return 0
+}
+
+func (x *OrderRow) GetPayChannel() int32 {
+	if x != nil {
+		return x.PayChannel
+	}
+	return 0
+}
+
+func (x *OrderRow) GetOutTradeNo() string {
+	if x != nil {
+		return x.OutTradeNo
+	}
+	return ""
+}
+
+func (x *OrderRow) GetPayTime() int64 {
+	if x != nil {
+		return x.PayTime
+	}
+	return 0
+}
+
+func (x *OrderRow) GetCreateTime() int64 {
+	if x != nil {
+		return x.CreateTime
+	}
+	return 0
+}
+
+func (x *OrderRow) GetUpdateTime() int64 {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return 0
+}
+
+func (x *OrderRow) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// ORDER_ROW_CHANGED payload, version 1: a trade_order row captured from the binlog
+type OrderRowChanged struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Op             RowOp                  `protobuf:"varint,1,opt,name=op,proto3,enum=event.RowOp" json:"op,omitempty"`       // Kind of change
+	Row            *OrderRow              `protobuf:"bytes,2,opt,name=row,proto3" json:"row,omitempty"`                       // Row after the change; before it for deletes
+	ChangedColumns []string               `protobuf:"bytes,3,rep,name=changedColumns,proto3" json:"changedColumns,omitempty"` // Columns an update changed
+	Source         string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`                 // Database and table the row was read from, e.g. aether_trade_01.trade_order_05
+	CommittedAt    int64                  `protobuf:"varint,5,opt,name=committedAt,proto3" json:"committedAt,omitempty"`      // When the change was written to the binlog (unix milliseconds)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *OrderRowChanged) Reset() {
+	*x = OrderRowChanged{}
+	mi := &file_common_event_event_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderRowChanged) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderRowChanged) ProtoMessage() {}
+
+func (x *OrderRowChanged) ProtoReflect() protoreflect.Message {
+	mi := &file_common_event_event_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderRowChanged.ProtoReflect.Descriptor instead.
+func (*OrderRowChanged) Descriptor() ([]byte, []int) {
+	return file_common_event_event_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *OrderRowChanged) GetOp() RowOp {
+	if x != nil {
+		return x.Op
+	}
+	return RowOp_ROW_OP_UNSPECIFIED
+}
+
+func (x *OrderRowChanged) GetRow() *OrderRow {
+	if x != nil {
+		return x.Row
+	}
+	return nil
+}
+
+func (x *OrderRowChanged) GetChangedColumns() []string {
+	if x != nil {
+		return x.ChangedColumns
+	}
+	return nil
+}
+
+func (x *OrderRowChanged) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *OrderRowChanged) GetCommittedAt() int64 {
+	if x != nil {
+		return x.CommittedAt
+	}
+	return 0
+}
+
+// Columns of a trade_order_item row
+type OrderItemRow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemId        int64                  `protobuf:"varint,1,opt,name=itemId,proto3" json:"itemId,omitempty"`               // Order item ID
+	OrderId       int64                  `protobuf:"varint,2,opt,name=orderId,proto3" json:"orderId,omitempty"`             // Order ID
+	UserId        int64                  `protobuf:"varint,3,opt,name=userId,proto3" json:"userId,omitempty"`               // Buyer user ID
+	CourseId      int64                  `protobuf:"varint,4,opt,name=courseId,proto3" json:"courseId,omitempty"`           // Course ID
+	CourseName    string                 `protobuf:"bytes,5,opt,name=courseName,proto3" json:"courseName,omitempty"`        // Course name
+	Price         int32                  `protobuf:"varint,6,opt,name=price,proto3" json:"price,omitempty"`                 // Course price (cents)
+	RealPayAmount int32                  `protobuf:"varint,7,opt,name=realPayAmount,proto3" json:"realPayAmount,omitempty"` // Paid amount allocated to the item (cents)
+	RefundStatus  int32                  `protobuf:"varint,8,opt,name=refundStatus,proto3" json:"refundStatus,omitempty"`   // Refund status (0: None, 1: Refunding, 2: Refunded)
+	CreateTime    int64                  `protobuf:"varint,9,opt,name=createTime,proto3" json:"createTime,omitempty"`       // Creation time (unix seconds)
+	UpdateTime    int64                  `protobuf:"varint,10,opt,name=updateTime,proto3" json:"updateTime,omitempty"`      // Last update time (unix seconds)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderItemRow) Reset() {
+	*x = OrderItemRow{}
+	mi := &file_common_event_event_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderItemRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderItemRow) ProtoMessage() {}
+
+func (x *OrderItemRow) ProtoReflect() protoreflect.Message {
+	mi := &file_common_event_event_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderItemRow.ProtoReflect.Descriptor instead.
+func (*OrderItemRow) Descriptor() ([]byte, []int) {
+	return file_common_event_event_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *OrderItemRow) GetItemId() int64 {
+	if x != nil {
+		return x.ItemId
+	}
+	return 0
+}
+
+func (x *OrderItemRow) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderItemRow) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *OrderItemRow) GetCourseId() int64 {
+	if x != nil {
+		return x.CourseId
+	}
+	return 0
+}
+
+func (x *OrderItemRow) GetCourseName() string {
+	if x != nil {
+		return x.CourseName
+	}
+	return ""
+}
+
+func (x *OrderItemRow) GetPrice() int32 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *OrderItemRow) GetRealPayAmount() int32 {
+	if x != nil {
+		return x.RealPayAmount
+	}
+	return 0
+}
+
+func (x *OrderItemRow) GetRefundStatus() int32 {
+	if x != nil {
+		return x.RefundStatus
+	}
+	return 0
+}
+
+func (x *OrderItemRow) GetCreateTime() int64 {
+	if x != nil {
+		return x.CreateTime
+	}
+	return 0
+}
+
+func (x *OrderItemRow) GetUpdateTime() int64 {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return 0
+}
+
+// ORDER_ITEM_ROW_CHANGED payload, version 1: a trade_order_item row captured from the binlog
+type OrderItemRowChanged struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Op             RowOp                  `protobuf:"varint,1,opt,name=op,proto3,enum=event.RowOp" json:"op,omitempty"`       // Kind of change
+	Row            *OrderItemRow          `protobuf:"bytes,2,opt,name=row,proto3" json:"row,omitempty"`                       // Row after the change; before it for deletes
+	ChangedColumns []string               `protobuf:"bytes,3,rep,name=changedColumns,proto3" json:"changedColumns,omitempty"` // Columns an update changed
+	Source         string                 `protobuf:"bytes,4,opt,name=source,proto3" json:"source,omitempty"`                 // Database and table the row was read from
+	CommittedAt    int64                  `protobuf:"varint,5,opt,name=committedAt,proto3" json:"committedAt,omitempty"`      // When the change was written to the binlog (unix milliseconds)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *OrderItemRowChanged) Reset() {
+	*x = OrderItemRowChanged{}
+	mi := &file_common_event_event_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderItemRowChanged) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderItemRowChanged) ProtoMessage() {}
+
+func (x *OrderItemRowChanged) ProtoReflect() protoreflect.Message {
+	mi := &file_common_event_event_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderItemRowChanged.ProtoReflect.Descriptor instead.
+func (*OrderItemRowChanged) Descriptor() ([]byte, []int) {
+	return file_common_event_event_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *OrderItemRowChanged) GetOp() RowOp {
+	if x != nil {
+		return x.Op
+	}
+	return RowOp_ROW_OP_UNSPECIFIED
+}
+
+func (x *OrderItemRowChanged) GetRow() *OrderItemRow {
+	if x != nil {
+		return x.Row
+	}
+	return nil
+}
+
+func (x *OrderItemRowChanged) GetChangedColumns() []string {
+	if x != nil {
+		return x.ChangedColumns
+	}
+	return nil
+}
+
+func (x *OrderItemRowChanged) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *OrderItemRowChanged) GetCommittedAt() int64 {
+	if x != nil {
+		return x.CommittedAt
+	}
+	return 0
+}
+
+var File_common_event_event_proto protoreflect.FileDescriptor
+
+const file_common_event_event_proto_rawDesc = "" +
+	"\n" +
+	"\x18common/event/event.proto\x12\x05event\"\xae\x01\n" +
+	"\bEnvelope\x12\x18\n" +
+	"\aeventId\x18\x01 \x01(\tR\aeventId\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x05R\aversion\x12\x1e\n" +
+	"\n" +
+	"occurredAt\x18\x04 \x01(\x03R\n" +
+	"occurredAt\x12 \n" +
+	"\vaggregateId\x18\x05 \x01(\tR\vaggregateId\x12\x18\n" +
+	"\apayload\x18\x06 \x01(\fR\apayload\"\x97\x01\n" +
+	"\vOrderPlaced\x12\x18\n" +
+	"\aorderId\x18\x01 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06userId\x18\x02 \x01(\x03R\x06userId\x12\x1c\n" +
+	"\tcourseIds\x18\x03 \x03(\x03R\tcourseIds\x12\x1e\n" +
+	"\n" +
+	"realAmount\x18\x04 \x01(\x05R\n" +
+	"realAmount\x12\x18\n" +
+	"\aitemIds\x18\x05 \x03(\x03R\aitemIds\"`\n" +
+	"\x0eOrderCancelled\x12\x18\n" +
+	"\aorderId\x18\x01 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06userId\x18\x02 \x01(\x03R\x06userId\x12\x1c\n" +
+	"\tcourseIds\x18\x03 \x03(\x03R\tcourseIds\"\xb5\x01\n" +
+	"\tOrderPaid\x12\x18\n" +
+	"\aorderId\x18\x01 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06userId\x18\x02 \x01(\x03R\x06userId\x12\x1c\n" +
+	"\tpayAmount\x18\x03 \x01(\x05R\tpayAmount\x12\x1e\n" +
+	"\n" +
+	"payChannel\x18\x04 \x01(\x05R\n" +
+	"payChannel\x12\x1e\n" +
+	"\n" +
+	"outTradeNo\x18\x05 \x01(\tR\n" +
+	"outTradeNo\x12\x18\n" +
+	"\apayTime\x18\x06 \x01(\x03R\apayTime\"\xb9\x01\n" +
+	"\rOrderRefunded\x12\x1a\n" +
+	"\brefundId\x18\x01 \x01(\x03R\brefundId\x12\x18\n" +
+	"\aorderId\x18\x02 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06userId\x18\x03 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x05R\x06amount\x12\x1c\n" +
+	"\tcourseIds\x18\x05 \x03(\x03R\tcourseIds\x12$\n" +
+	"\rorderRefunded\x18\x06 \x01(\bR\rorderRefunded\"\xc8\x02\n" +
+	"\bOrderRow\x12\x18\n" +
+	"\aorderId\x18\x01 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06userId\x18\x02 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\x05R\x06status\x12 \n" +
+	"\vtotalAmount\x18\x04 \x01(\x05R\vtotalAmount\x12\x1c\n" +
+	"\tpayAmount\x18\x05 \x01(\x05R\tpayAmount\x12\x1e\n" +
+	"\n" +
+	"payChannel\x18\x06 \x01(\x05R\n" +
+	"payChannel\x12\x1e\n" +
+	"\n" +
+	"outTradeNo\x18\a \x01(\tR\n" +
+	"outTradeNo\x12\x18\n" +
+	"\apayTime\x18\b \x01(\x03R\apayTime\x12\x1e\n" +
+	"\n" +
+	"createTime\x18\t \x01(\x03R\n" +
+	"createTime\x12\x1e\n" +
+	"\n" +
+	"updateTime\x18\n" +
+	" \x01(\x03R\n" +
+	"updateTime\x12\x18\n" +
+	"\aversion\x18\v \x01(\x05R\aversion\"\xb4\x01\n" +
+	"\x0fOrderRowChanged\x12\x1c\n" +
+	"\x02op\x18\x01 \x01(\x0e2\f.event.RowOpR\x02op\x12!\n" +
+	"\x03row\x18\x02 \x01(\v2\x0f.event.OrderRowR\x03row\x12&\n" +
+	"\x0echangedColumns\x18\x03 \x03(\tR\x0echangedColumns\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12 \n" +
+	"\vcommittedAt\x18\x05 \x01(\x03R\vcommittedAt\"\xb4\x02\n" +
+	"\fOrderItemRow\x12\x16\n" +
+	"\x06itemId\x18\x01 \x01(\x03R\x06itemId\x12\x18\n" +
+	"\aorderId\x18\x02 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06userId\x18\x03 \x01(\x03R\x06userId\x12\x1a\n" +
+	"\bcourseId\x18\x04 \x01(\x03R\bcourseId\x12\x1e\n" +
+	"\n" +
+	"courseName\x18\x05 \x01(\tR\n" +
+	"courseName\x12\x14\n" +
+	"\x05price\x18\x06 \x01(\x05R\x05price\x12$\n" +
+	"\rrealPayAmount\x18\a \x01(\x05R\rrealPayAmount\x12\"\n" +
+	"\frefundStatus\x18\b \x01(\x05R\frefundStatus\x12\x1e\n" +
+	"\n" +
+	"createTime\x18\t \x01(\x03R\n" +
+	"createTime\x12\x1e\n" +
+	"\n" +
+	"updateTime\x18\n" +
+	" \x01(\x03R\n" +
+	"updateTime\"\xbc\x01\n" +
+	"\x13OrderItemRowChanged\x12\x1c\n" +
+	"\x02op\x18\x01 \x01(\x0e2\f.event.RowOpR\x02op\x12%\n" +
+	"\x03row\x18\x02 \x01(\v2\x13.event.OrderItemRowR\x03row\x12&\n" +
+	"\x0echangedColumns\x18\x03 \x03(\tR\x0echangedColumns\x12\x16\n" +
+	"\x06source\x18\x04 \x01(\tR\x06source\x12 \n" +
+	"\vcommittedAt\x18\x05 \x01(\x03R\vcommittedAt*X\n" +
+	"\x05RowOp\x12\x16\n" +
+	"\x12ROW_OP_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rROW_OP_INSERT\x10\x01\x12\x11\n" +
+	"\rROW_OP_UPDATE\x10\x02\x12\x11\n" +
+	"\rROW_OP_DELETE\x10\x03B/Z-github.com/aether-defense-system/common/eventb\x06proto3"
+
+var (
+	file_common_event_event_proto_rawDescOnce sync.Once
+	file_common_event_event_proto_rawDescData []byte
+)
+
+func file_common_event_event_proto_rawDescGZIP() []byte {
+	file_common_event_event_proto_rawDescOnce.Do(func() {
+		file_common_event_event_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_common_event_event_proto_rawDesc), len(file_common_event_event_proto_rawDesc)))
+	})
+	return file_common_event_event_proto_rawDescData
+}
+
+var file_common_event_event_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_common_event_event_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_common_event_event_proto_goTypes = []any{
+	(RowOp)(0),                  // 0: event.RowOp
+	(*Envelope)(nil),            // 1: event.Envelope
+	(*OrderPlaced)(nil),         // 2: event.OrderPlaced
+	(*OrderCancelled)(nil),      // 3: event.OrderCancelled
+	(*OrderPaid)(nil),           // 4: event.OrderPaid
+	(*OrderRefunded)(nil),       // 5: event.OrderRefunded
+	(*OrderRow)(nil),            // 6: event.OrderRow
+	(*OrderRowChanged)(nil),     // 7: event.OrderRowChanged
+	(*OrderItemRow)(nil),        // 8: event.OrderItemRow
+	(*OrderItemRowChanged)(nil), // 9: event.OrderItemRowChanged
+}
+var file_common_event_event_proto_depIdxs = []int32{
+	0, // 0: event.OrderRowChanged.op:type_name -> event.RowOp
+	6, // 1: event.OrderRowChanged.row:type_name -> event.OrderRow
+	0, // 2: event.OrderItemRowChanged.op:type_name -> event.RowOp
+	8, // 3: event.OrderItemRowChanged.row:type_name -> event.OrderItemRow
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_common_event_event_proto_init() }
+func file_common_event_event_proto_init() {
+	if File_common_event_event_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_common_event_event_proto_rawDesc), len(file_common_event_event_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_common_event_event_proto_goTypes,
+		DependencyIndexes: file_common_event_event_proto_depIdxs,
+		EnumInfos:         file_common_event_event_proto_enumTypes,
+		MessageInfos:      file_common_event_event_proto_msgTypes,
+	}.Build()
+	File_common_event_event_proto = out.File
+	file_common_event_event_proto_goTypes = nil
+	file_common_event_event_proto_depIdxs = nil
+}
@@ -0,0 +1,76 @@
+package event
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/aether-defense-system/common/mq"
+)
+
+// Event is a decoded event: its envelope, upcast to the current version, and its payload.
+type Event struct {
+	Envelope *Envelope
+	Payload  proto.Message
+}
+
+// NewMessage validates env and builds the message that publishes it on topic, tagged with the
+// event type so that consumers can subscribe to the types they handle.
+func (r *Registry) NewMessage(topic string, env *Envelope) (*mq.Message, error) {
+	if err := r.Validate(env); err != nil {
+		return nil, fmt.Errorf("invalid event: %w", err)
+	}
+	body, err := proto.Marshal(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event envelope: %w", err)
+	}
+	return mq.NewMessage(topic, body).WithTag(env.Type), nil
+}
+
+// Parse decodes the event carried by msg. Bodies published before the envelope existed are
+// recognized by the message tag (see RegisterLegacy), wrapped with the message ID as event ID and
+// upcast like any older version.
+func (r *Registry) Parse(msg *mq.Message) (*Event, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+
+	env := &Envelope{}
+	if err := proto.Unmarshal(msg.Body, env); err != nil || env.Type == "" {
+		version, ok := r.legacy[msg.Tag]
+		if !ok {
+			return nil, fmt.Errorf("message %s does not carry an event envelope", msg.MsgID)
+		}
+		env = &Envelope{EventId: msg.MsgID, Type: msg.Tag, Version: version, Payload: msg.Body}
+	}
+
+	payload, err := r.Decode(env)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event in message %s: %w", msg.MsgID, err)
+	}
+	return &Event{Envelope: env, Payload: payload}, nil
+}
+
+// EventID returns the ID of the event carried by msg. Use it as the deduplication key of
+// consumers (see mq.WithDedupeKey): unlike the message ID, it is the same for every publication
+// of the event.
+func (r *Registry) EventID(msg *mq.Message) (string, error) {
+	e, err := r.Parse(msg)
+	if err != nil {
+		return "", err
+	}
+	return e.Envelope.EventId, nil
+}
+
+// Handler adapts handle to an mq.Handler. Messages that do not carry a valid event fail like any
+// other handler error, so they are redelivered and end up in the dead-letter topic.
+func (r *Registry) Handler(handle func(ctx context.Context, e *Event) error) mq.Handler {
+	return func(ctx context.Context, msg *mq.Message) error {
+		e, err := r.Parse(msg)
+		if err != nil {
+			return err
+		}
+		return handle(ctx, e)
+	}
+}
@@ -0,0 +1,75 @@
+package event
+
+import (
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Order event types. Each is also the tag of the messages that carry it.
+const (
+	TypeOrderPlaced    = "ORDER_PLACED"
+	TypeOrderCancelled = "ORDER_CANCELLED"
+	TypeOrderPaid      = "ORDER_PAID"
+	TypeOrderRefunded  = "ORDER_REFUNDED"
+)
+
+// Default is the registry of every event type exchanged between services.
+var Default = mustNewDefault()
+
+func mustNewDefault() *Registry {
+	r := NewRegistry()
+	if err := RegisterOrderEvents(r); err != nil {
+		panic(fmt.Sprintf("failed to register order events: %v", err))
+	}
+	if err := RegisterChangeEvents(r); err != nil {
+		panic(fmt.Sprintf("failed to register change events: %v", err))
+	}
+	return r
+}
+
+// RegisterOrderEvents registers the order event types and their upcasters with r.
+func RegisterOrderEvents(r *Registry) error {
+	schemas := []struct {
+		newPayload func() proto.Message
+		eventType  string
+		version    int32
+	}{
+		{eventType: TypeOrderPlaced, version: 2, newPayload: func() proto.Message { return &OrderPlaced{} }},
+		{eventType: TypeOrderCancelled, version: 1, newPayload: func() proto.Message { return &OrderCancelled{} }},
+		{eventType: TypeOrderPaid, version: 1, newPayload: func() proto.Message { return &OrderPaid{} }},
+		{eventType: TypeOrderRefunded, version: 1, newPayload: func() proto.Message { return &OrderRefunded{} }},
+	}
+	for _, s := range schemas {
+		if err := r.Register(s.eventType, s.version, s.newPayload); err != nil {
+			return err
+		}
+	}
+
+	if err := r.RegisterUpcaster(TypeOrderPlaced, 1, upcastOrderPlacedV1); err != nil {
+		return err
+	}
+	// Trade published version 1 as a bare JSON body; half messages and outbox rows written before
+	// the envelope may still be delivered.
+	return r.RegisterLegacy(TypeOrderPlaced, 1)
+}
+
+// upcastOrderPlacedV1 converts the JSON order message of version 1, whose field names match
+// OrderPlaced, to the protobuf payload of version 2.
+func upcastOrderPlacedV1(env *Envelope) error {
+	var placed OrderPlaced
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(env.Payload, &placed); err != nil {
+		return fmt.Errorf("failed to parse JSON order message: %w", err)
+	}
+	payload, err := proto.Marshal(&placed)
+	if err != nil {
+		return fmt.Errorf("failed to marshal order placed payload: %w", err)
+	}
+	env.Payload = payload
+	if env.AggregateId == "" {
+		env.AggregateId = strconv.FormatInt(placed.OrderId, 10)
+	}
+	return nil
+}
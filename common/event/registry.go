@@ -0,0 +1,197 @@
+// Package event defines the versioned envelope and the protobuf payloads of the events services
+// exchange through the message queue, and a registry that validates envelopes and upcasts payloads
+// of older versions to the current one.
+package event
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Upcaster upgrades an envelope from the version it is registered for to the next version, by
+// rewriting its payload and filling in fields the older version did not carry.
+type Upcaster func(env *Envelope) error
+
+// schema describes one event type.
+type schema struct {
+	newPayload func() proto.Message
+	upcasters  map[int32]Upcaster // keyed by the version they upgrade from
+	version    int32
+}
+
+// Registry maps event types to their current payload schema and the upcasters of older versions.
+// Register every type before the registry is shared; lookups are safe for concurrent use.
+type Registry struct {
+	schemas map[string]*schema
+	legacy  map[string]int32 // event type -> version of bodies published without an envelope
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{schemas: make(map[string]*schema), legacy: make(map[string]int32)}
+}
+
+// Register registers eventType with its current version and a constructor of its payload message.
+func (r *Registry) Register(eventType string, version int32, newPayload func() proto.Message) error {
+	if eventType == "" {
+		return fmt.Errorf("event type cannot be empty")
+	}
+	if version < 1 {
+		return fmt.Errorf("invalid version %d of event type %s", version, eventType)
+	}
+	if newPayload == nil {
+		return fmt.Errorf("payload constructor of event type %s cannot be nil", eventType)
+	}
+	if _, ok := r.schemas[eventType]; ok {
+		return fmt.Errorf("event type %s already registered", eventType)
+	}
+	r.schemas[eventType] = &schema{newPayload: newPayload, upcasters: make(map[int32]Upcaster), version: version}
+	return nil
+}
+
+// RegisterUpcaster registers the upcaster from version from of eventType to version from+1.
+func (r *Registry) RegisterUpcaster(eventType string, from int32, upcast Upcaster) error {
+	s, ok := r.schemas[eventType]
+	if !ok {
+		return fmt.Errorf("unknown event type: %s", eventType)
+	}
+	if from < 1 || from >= s.version {
+		return fmt.Errorf("cannot upcast event type %s from version %d, current version is %d",
+			eventType, from, s.version)
+	}
+	if upcast == nil {
+		return fmt.Errorf("upcaster cannot be nil")
+	}
+	s.upcasters[from] = upcast
+	return nil
+}
+
+// RegisterLegacy declares that messages tagged eventType were published without an envelope,
+// with bodies of the given version. Parse wraps such bodies in an envelope and upcasts them.
+func (r *Registry) RegisterLegacy(eventType string, version int32) error {
+	s, ok := r.schemas[eventType]
+	if !ok {
+		return fmt.Errorf("unknown event type: %s", eventType)
+	}
+	if version < 1 || version >= s.version {
+		return fmt.Errorf("invalid legacy version %d of event type %s", version, eventType)
+	}
+	r.legacy[eventType] = version
+	return nil
+}
+
+// Version returns the current version of eventType.
+func (r *Registry) Version(eventType string) (int32, error) {
+	s, ok := r.schemas[eventType]
+	if !ok {
+		return 0, fmt.Errorf("unknown event type: %s", eventType)
+	}
+	return s.version, nil
+}
+
+// NewEnvelope wraps payload, which must be the payload message of eventType, in an envelope of
+// the current version with a new event ID, occurring now.
+func (r *Registry) NewEnvelope(eventType, aggregateID string, payload proto.Message) (*Envelope, error) {
+	s, ok := r.schemas[eventType]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type: %s", eventType)
+	}
+	if payload == nil {
+		return nil, fmt.Errorf("payload cannot be nil")
+	}
+	want := s.newPayload().ProtoReflect().Descriptor().FullName()
+	if got := payload.ProtoReflect().Descriptor().FullName(); got != want {
+		return nil, fmt.Errorf("payload of event type %s must be %s, got %s", eventType, want, got)
+	}
+
+	body, err := proto.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+	eventID, err := newEventID()
+	if err != nil {
+		return nil, err
+	}
+
+	env := &Envelope{
+		EventId:     eventID,
+		Type:        eventType,
+		Version:     s.version,
+		OccurredAt:  time.Now().UnixMilli(),
+		AggregateId: aggregateID,
+		Payload:     body,
+	}
+	if err := r.Validate(env); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// Validate checks that env carries an ID, a registered type, a version that can be upcast to the
+// current one and an aggregate ID. It does not decode the payload; Decode does.
+func (r *Registry) Validate(env *Envelope) error {
+	if env == nil {
+		return fmt.Errorf("envelope cannot be nil")
+	}
+	if env.EventId == "" {
+		return fmt.Errorf("event ID is required")
+	}
+	s, ok := r.schemas[env.Type]
+	if !ok {
+		return fmt.Errorf("unknown event type: %q", env.Type)
+	}
+	if env.Version < 1 || env.Version > s.version {
+		return fmt.Errorf("unsupported version %d of event type %s, current version is %d",
+			env.Version, env.Type, s.version)
+	}
+	for v := env.Version; v < s.version; v++ {
+		if s.upcasters[v] == nil {
+			return fmt.Errorf("no upcaster for version %d of event type %s", v, env.Type)
+		}
+	}
+	if env.OccurredAt < 0 {
+		return fmt.Errorf("invalid occurredAt %d", env.OccurredAt)
+	}
+	if env.AggregateId == "" && env.Version == s.version {
+		// Older versions may get their aggregate ID from an upcaster.
+		return fmt.Errorf("aggregate ID is required")
+	}
+	return nil
+}
+
+// Decode validates env, upcasts it in place to the current version and returns its payload.
+func (r *Registry) Decode(env *Envelope) (proto.Message, error) {
+	if err := r.Validate(env); err != nil {
+		return nil, err
+	}
+
+	s := r.schemas[env.Type]
+	for env.Version < s.version {
+		if err := s.upcasters[env.Version](env); err != nil {
+			return nil, fmt.Errorf("failed to upcast %s from version %d: %w", env.Type, env.Version, err)
+		}
+		env.Version++
+	}
+	if env.AggregateId == "" {
+		return nil, fmt.Errorf("aggregate ID is required")
+	}
+
+	payload := s.newPayload()
+	if err := proto.Unmarshal(env.Payload, payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s payload: %w", env.Type, err)
+	}
+	return payload, nil
+}
+
+// newEventID returns a random 128-bit hex event ID.
+func newEventID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate event ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,167 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/aether-defense-system/common/mq"
+)
+
+func TestRegistry_NewMessageParse(t *testing.T) {
+	placed := &OrderPlaced{OrderId: 42, UserId: 7, CourseIds: []int64{1, 2}, RealAmount: 100}
+	env, err := Default.NewEnvelope(TypeOrderPlaced, "42", placed)
+	require.NoError(t, err)
+	assert.Len(t, env.EventId, 32)
+	assert.Equal(t, int32(2), env.Version)
+	assert.Positive(t, env.OccurredAt)
+
+	msg, err := Default.NewMessage("orders", env)
+	require.NoError(t, err)
+	assert.Equal(t, TypeOrderPlaced, msg.Tag)
+
+	e, err := Default.Parse(msg)
+	require.NoError(t, err)
+	assert.Equal(t, env.EventId, e.Envelope.EventId)
+	assert.Equal(t, "42", e.Envelope.AggregateId)
+	assert.True(t, proto.Equal(placed, e.Payload))
+}
+
+func TestRegistry_NewEnvelope_Invalid(t *testing.T) {
+	_, err := Default.NewEnvelope("ORDER_SHIPPED", "1", &OrderPlaced{})
+	assert.Error(t, err, "unknown type")
+
+	_, err = Default.NewEnvelope(TypeOrderPaid, "1", &OrderPlaced{})
+	assert.Error(t, err, "payload of another type")
+
+	_, err = Default.NewEnvelope(TypeOrderPaid, "", &OrderPaid{})
+	assert.Error(t, err, "no aggregate ID")
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	valid := func() *Envelope {
+		return &Envelope{EventId: "e1", Type: TypeOrderCancelled, Version: 1, OccurredAt: 1, AggregateId: "1"}
+	}
+	assert.NoError(t, Default.Validate(valid()))
+
+	tests := []struct {
+		mutate func(env *Envelope)
+		name   string
+	}{
+		{name: "no event ID", mutate: func(env *Envelope) { env.EventId = "" }},
+		{name: "unknown type", mutate: func(env *Envelope) { env.Type = "ORDER_SHIPPED" }},
+		{name: "version zero", mutate: func(env *Envelope) { env.Version = 0 }},
+		{name: "future version", mutate: func(env *Envelope) { env.Version = 2 }},
+		{name: "no aggregate ID", mutate: func(env *Envelope) { env.AggregateId = "" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := valid()
+			tt.mutate(env)
+			assert.Error(t, Default.Validate(env))
+		})
+	}
+}
+
+func TestRegistry_Parse_UpcastsLegacyOrderPlaced(t *testing.T) {
+	msg := mq.NewMessage("orders", []byte(`{"courseIds":[3],"orderId":9,"userId":5,"realAmount":300,"extra":1}`)).
+		WithTag(TypeOrderPlaced)
+	msg.MsgID = "msg-1"
+
+	e, err := Default.Parse(msg)
+	require.NoError(t, err)
+	assert.Equal(t, "msg-1", e.Envelope.EventId)
+	assert.Equal(t, int32(2), e.Envelope.Version)
+	assert.Equal(t, "9", e.Envelope.AggregateId)
+	assert.True(t, proto.Equal(&OrderPlaced{OrderId: 9, UserId: 5, CourseIds: []int64{3}, RealAmount: 300}, e.Payload))
+
+	// An enveloped version 1 payload is upcast the same way.
+	body, err := proto.Marshal(&Envelope{
+		EventId: "e1", Type: TypeOrderPlaced, Version: 1, Payload: []byte(`{"orderId":9,"courseIds":[3]}`),
+	})
+	require.NoError(t, err)
+	e, err = Default.Parse(mq.NewMessage("orders", body))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3}, e.Payload.(*OrderPlaced).CourseIds)
+}
+
+func TestRegistry_Parse_Invalid(t *testing.T) {
+	_, err := Default.Parse(mq.NewMessage("orders", []byte("not an event")).WithTag(TypeOrderPaid))
+	assert.Error(t, err, "no legacy format for ORDER_PAID")
+
+	msg := mq.NewMessage("orders", []byte("{")).WithTag(TypeOrderPlaced)
+	msg.MsgID = "msg-1"
+	_, err = Default.Parse(msg)
+	assert.Error(t, err, "malformed legacy body")
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry()
+	newPayload := func() proto.Message { return &OrderPaid{} }
+	require.NoError(t, r.Register(TypeOrderPaid, 3, newPayload))
+	assert.Error(t, r.Register(TypeOrderPaid, 3, newPayload), "duplicate")
+	assert.Error(t, r.RegisterUpcaster(TypeOrderPaid, 3, func(*Envelope) error { return nil }),
+		"no upcaster from the current version")
+
+	env := &Envelope{EventId: "e1", Type: TypeOrderPaid, Version: 1, AggregateId: "1"}
+	assert.Error(t, r.Validate(env), "missing upcasters")
+
+	var upcasts []int32
+	for from := int32(1); from < 3; from++ {
+		require.NoError(t, r.RegisterUpcaster(TypeOrderPaid, from, func(env *Envelope) error {
+			upcasts = append(upcasts, env.Version)
+			return nil
+		}))
+	}
+	_, err := r.Decode(env)
+	require.NoError(t, err)
+	assert.Equal(t, []int32{1, 2}, upcasts, "upcasters run in version order")
+	assert.Equal(t, int32(3), env.Version)
+
+	failing := &Envelope{EventId: "e2", Type: TypeOrderPaid, Version: 2, AggregateId: "1"}
+	require.NoError(t, r.RegisterUpcaster(TypeOrderPaid, 2, func(*Envelope) error { return errors.New("boom") }))
+	_, err = r.Decode(failing)
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestDefault_ChangeEvents(t *testing.T) {
+	changed := &OrderItemRowChanged{
+		Op:          RowOp_ROW_OP_UPDATE,
+		Row:         &OrderItemRow{ItemId: 11, OrderId: 1, RefundStatus: 2},
+		Source:      "aether_trade.trade_order_item",
+		CommittedAt: 1700000000000,
+	}
+	env, err := Default.NewEnvelope(TypeOrderItemRowChanged, "1", changed)
+	require.NoError(t, err)
+	msg, err := Default.NewMessage("order-changes", env)
+	require.NoError(t, err)
+	assert.Equal(t, TypeOrderItemRowChanged, msg.Tag)
+
+	e, err := Default.Parse(msg)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(changed, e.Payload))
+
+	_, err = Default.NewEnvelope(TypeOrderRowChanged, "1", changed)
+	assert.Error(t, err, "item change as an order change")
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	env, err := Default.NewEnvelope(TypeOrderCancelled, "1", &OrderCancelled{OrderId: 1})
+	require.NoError(t, err)
+	msg, err := Default.NewMessage("orders", env)
+	require.NoError(t, err)
+
+	var got *Event
+	handler := Default.Handler(func(_ context.Context, e *Event) error {
+		got = e
+		return nil
+	})
+	require.NoError(t, handler(context.Background(), msg))
+	assert.Equal(t, env.EventId, got.Envelope.EventId)
+
+	assert.Error(t, handler(context.Background(), mq.NewMessage("orders", []byte("junk"))))
+}
@@ -0,0 +1,153 @@
+// Package idempotency provides one-time request tokens that make retried writes safe.
+//
+// A client first obtains a token, then sends it with the write. The first request carrying
+// the token consumes it atomically; a replay of the same token gets the original result back
+// instead of performing the write twice.
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aether-defense-system/common/redis"
+)
+
+var (
+	// ErrInvalidToken is returned when a token was never issued, has expired or is malformed.
+	ErrInvalidToken = errors.New("invalid or expired idempotency token")
+	// ErrTokenInFlight is returned when a replay arrives while the original request is still running.
+	ErrTokenInFlight = errors.New("request with this idempotency token is still being processed")
+)
+
+// tokenBytes is the entropy of a token; tokens are hex encoded.
+const tokenBytes = 16
+
+// pendingMarker is stored as the result while the original request is running.
+// Results are JSON documents, so they can never collide with it.
+const pendingMarker = "pending"
+
+// TokenRedis defines the minimal Redis operations required by TokenStore.
+type TokenRedis interface {
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+	ConsumeToken(ctx context.Context, tokenKey, resultKey, marker string,
+		resultTTL time.Duration) (state int64, value string, err error)
+}
+
+// Claim is the outcome of consuming a token.
+type Claim struct {
+	Payload  string // Value bound to the token when it was issued; empty for replays
+	Result   string // Result recorded by the original request; set only for replays
+	Replayed bool   // Whether the token had already been consumed
+}
+
+// TokenStore issues and consumes one-time tokens scoped to a user and an operation.
+type TokenStore struct {
+	rdb       TokenRedis
+	keys      *redis.KeyNamingHelper
+	scope     string
+	tokenTTL  time.Duration
+	resultTTL time.Duration
+}
+
+// NewTokenStore creates a TokenStore for one operation (e.g. "checkout").
+// Unused tokens expire after tokenTTL; results of consumed tokens are kept for resultTTL.
+func NewTokenStore(rdb TokenRedis, scope string, tokenTTL, resultTTL time.Duration) (*TokenStore, error) {
+	if scope == "" {
+		return nil, fmt.Errorf("token scope cannot be empty")
+	}
+	if tokenTTL <= 0 || resultTTL <= 0 {
+		return nil, fmt.Errorf("token and result TTLs must be positive")
+	}
+
+	return &TokenStore{
+		rdb:       rdb,
+		keys:      redis.NewKeyNamingHelper(),
+		scope:     scope,
+		tokenTTL:  tokenTTL,
+		resultTTL: resultTTL,
+	}, nil
+}
+
+// Issue creates a token for the user and binds payload to it.
+func (s *TokenStore) Issue(ctx context.Context, userID int64, payload string) (string, time.Time, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	expireAt := time.Now().Add(s.tokenTTL)
+	if err := s.rdb.Set(ctx, s.keys.IdempotencyTokenKey(s.scope, userID, token), payload, s.tokenTTL); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to store token: %w", err)
+	}
+
+	return token, expireAt, nil
+}
+
+// Consume claims a token for the request that carries it.
+//
+// The first call returns the token's payload; the caller must then either Complete the token
+// with the request's result or Release it if the request failed. Later calls return the
+// recorded result, or ErrTokenInFlight while the first request is still running.
+func (s *TokenStore) Consume(ctx context.Context, userID int64, token string) (*Claim, error) {
+	if !validToken(token) {
+		return nil, ErrInvalidToken
+	}
+
+	state, value, err := s.rdb.ConsumeToken(ctx,
+		s.keys.IdempotencyTokenKey(s.scope, userID, token),
+		s.keys.IdempotencyResultKey(s.scope, userID, token),
+		pendingMarker, s.resultTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume token: %w", err)
+	}
+
+	switch state {
+	case redis.TokenConsumed:
+		return &Claim{Payload: value}, nil
+	case redis.TokenReplayed:
+		if value == pendingMarker {
+			return nil, ErrTokenInFlight
+		}
+		return &Claim{Result: value, Replayed: true}, nil
+	default:
+		return nil, ErrInvalidToken
+	}
+}
+
+// Complete records the result of the request that consumed the token, for replays to return.
+func (s *TokenStore) Complete(ctx context.Context, userID int64, token, result string) error {
+	if err := s.rdb.Set(ctx, s.keys.IdempotencyResultKey(s.scope, userID, token), result, s.resultTTL); err != nil {
+		return fmt.Errorf("failed to store token result: %w", err)
+	}
+	return nil
+}
+
+// Release makes a consumed token usable again after its request failed, keeping the same payload
+// so that the retry repeats the same operation.
+//
+// The result is cleared before the token is restored: a failure in between leaves the token
+// spent, never both usable and replayable.
+func (s *TokenStore) Release(ctx context.Context, userID int64, token, payload string) error {
+	if err := s.rdb.Del(ctx, s.keys.IdempotencyResultKey(s.scope, userID, token)); err != nil {
+		return fmt.Errorf("failed to clear token result: %w", err)
+	}
+	if err := s.rdb.Set(ctx, s.keys.IdempotencyTokenKey(s.scope, userID, token), payload, s.tokenTTL); err != nil {
+		return fmt.Errorf("failed to restore token: %w", err)
+	}
+	return nil
+}
+
+// validToken rejects anything that Issue could not have produced, before it reaches a Redis key.
+func validToken(token string) bool {
+	if len(token) != 2*tokenBytes {
+		return false
+	}
+	_, err := hex.DecodeString(token)
+	return err == nil
+}
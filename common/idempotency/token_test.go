@@ -0,0 +1,207 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aether-defense-system/common/redis"
+)
+
+// fakeRedis is an in-memory stand-in for the Redis operations used by TokenStore.
+// ConsumeToken mirrors the Lua script in common/redis.
+type fakeRedis struct {
+	strings map[string]string
+	err     error
+	setErr  error // Fails Set only
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{strings: make(map[string]string)}
+}
+
+func (f *fakeRedis) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	if f.err != nil {
+		return f.err
+	}
+	if f.setErr != nil {
+		return f.setErr
+	}
+	f.strings[key] = fmt.Sprint(value)
+	return nil
+}
+
+func (f *fakeRedis) Del(_ context.Context, keys ...string) error {
+	if f.err != nil {
+		return f.err
+	}
+	for _, key := range keys {
+		delete(f.strings, key)
+	}
+	return nil
+}
+
+func (f *fakeRedis) ConsumeToken(
+	_ context.Context, tokenKey, resultKey, marker string, _ time.Duration,
+) (int64, string, error) {
+	if f.err != nil {
+		return 0, "", f.err
+	}
+	if value, ok := f.strings[tokenKey]; ok {
+		delete(f.strings, tokenKey)
+		f.strings[resultKey] = marker
+		return redis.TokenConsumed, value, nil
+	}
+	if result, ok := f.strings[resultKey]; ok {
+		return redis.TokenReplayed, result, nil
+	}
+	return redis.TokenMissing, "", nil
+}
+
+func newTestStore(t *testing.T, rdb TokenRedis) *TokenStore {
+	t.Helper()
+	store, err := NewTokenStore(rdb, "checkout", time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("NewTokenStore() error = %v", err)
+	}
+	return store
+}
+
+func TestNewTokenStore_InvalidConfig(t *testing.T) {
+	if _, err := NewTokenStore(newFakeRedis(), "", time.Minute, time.Minute); err == nil {
+		t.Errorf("expected error for empty scope")
+	}
+	if _, err := NewTokenStore(newFakeRedis(), "checkout", 0, time.Minute); err == nil {
+		t.Errorf("expected error for zero token TTL")
+	}
+}
+
+func TestTokenStore_ConsumeCompleteReplay(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t, newFakeRedis())
+
+	token, expireAt, err := store.Issue(ctx, 7, "order-1")
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if !expireAt.After(time.Now()) {
+		t.Errorf("expected expiry in the future, got %v", expireAt)
+	}
+
+	claim, err := store.Consume(ctx, 7, token)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if claim.Replayed || claim.Payload != "order-1" {
+		t.Fatalf("Consume() = %+v, want fresh claim with payload order-1", claim)
+	}
+
+	if _, err = store.Consume(ctx, 7, token); !errors.Is(err, ErrTokenInFlight) {
+		t.Fatalf("Consume() while in flight error = %v, want ErrTokenInFlight", err)
+	}
+
+	if err = store.Complete(ctx, 7, token, `{"orderId":1}`); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	claim, err = store.Consume(ctx, 7, token)
+	if err != nil {
+		t.Fatalf("Consume() replay error = %v", err)
+	}
+	if !claim.Replayed || claim.Result != `{"orderId":1}` {
+		t.Fatalf("Consume() replay = %+v, want recorded result", claim)
+	}
+}
+
+func TestTokenStore_Release(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t, newFakeRedis())
+
+	token, _, err := store.Issue(ctx, 7, "order-1")
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if _, err = store.Consume(ctx, 7, token); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+
+	if err = store.Release(ctx, 7, token, "order-1"); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	claim, err := store.Consume(ctx, 7, token)
+	if err != nil {
+		t.Fatalf("Consume() after release error = %v", err)
+	}
+	if claim.Replayed || claim.Payload != "order-1" {
+		t.Fatalf("Consume() after release = %+v, want fresh claim with the same payload", claim)
+	}
+}
+
+func TestTokenStore_Release_Interrupted(t *testing.T) {
+	ctx := context.Background()
+	rdb := newFakeRedis()
+	store := newTestStore(t, rdb)
+
+	token, _, err := store.Issue(ctx, 7, "order-1")
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if _, err = store.Consume(ctx, 7, token); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+
+	// A release that fails halfway leaves the token spent rather than usable and in flight at once.
+	rdb.setErr = errors.New("connection reset")
+	if err = store.Release(ctx, 7, token, "order-1"); err == nil {
+		t.Fatalf("Release() expected error")
+	}
+	rdb.setErr = nil
+	if _, err = store.Consume(ctx, 7, token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("Consume() after interrupted release error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestTokenStore_Consume_InvalidTokens(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t, newFakeRedis())
+
+	token, _, err := store.Issue(ctx, 7, "order-1")
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		userID int64
+	}{
+		{name: "empty", token: "", userID: 7},
+		{name: "malformed", token: "not-a-token", userID: 7},
+		{name: "never issued", token: "00112233445566778899aabbccddeeff", userID: 7},
+		{name: "other user's token", token: token, userID: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, consumeErr := store.Consume(ctx, tt.userID, tt.token); !errors.Is(consumeErr, ErrInvalidToken) {
+				t.Fatalf("Consume() error = %v, want ErrInvalidToken", consumeErr)
+			}
+		})
+	}
+}
+
+func TestTokenStore_RedisError(t *testing.T) {
+	rdb := newFakeRedis()
+	rdb.err = errors.New("connection refused")
+	store := newTestStore(t, rdb)
+
+	if _, _, err := store.Issue(context.Background(), 7, "order-1"); !errors.Is(err, rdb.err) {
+		t.Errorf("Issue() error = %v, want wrapped redis error", err)
+	}
+	if _, err := store.Consume(context.Background(), 7, "00112233445566778899aabbccddeeff"); !errors.Is(err, rdb.err) {
+		t.Errorf("Consume() error = %v, want wrapped redis error", err)
+	}
+}
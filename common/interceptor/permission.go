@@ -0,0 +1,144 @@
+// Package interceptor provides gRPC server interceptors shared by the RPC services.
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aether-defense-system/common/auth"
+)
+
+// TokenVerifier validates forwarded access tokens.
+// *auth.TokenVerifier satisfies this interface.
+type TokenVerifier interface {
+	Verify(token string) (*auth.Claims, error)
+}
+
+// SessionChecker reports whether a user session is still active.
+// *auth.SessionStore satisfies this interface.
+type SessionChecker interface {
+	IsActive(ctx context.Context, userID int64, sessionID string) (bool, error)
+}
+
+// PermissionInterceptor enforces per-method permissions on RPC calls.
+//
+// The caller's access token is read from the "authorization" metadata (see
+// auth.WithOutgoingAuthorization). Methods under the admin prefix are denied unless a
+// permission has been registered; other methods are only checked when registered,
+// so internal service-to-service calls keep working without a token.
+type PermissionInterceptor struct {
+	verifier    TokenVerifier
+	sessions    SessionChecker
+	policy      *auth.Policy
+	methods     map[string]string
+	adminPrefix string
+}
+
+// NewPermissionInterceptor creates a PermissionInterceptor.
+// adminPrefix is matched against the full method name, e.g. "/rpc.UserService/Admin".
+// A nil verifier rejects every protected call.
+func NewPermissionInterceptor(policy *auth.Policy, verifier TokenVerifier, adminPrefix string) *PermissionInterceptor {
+	return &PermissionInterceptor{
+		policy:      policy,
+		verifier:    verifier,
+		adminPrefix: adminPrefix,
+		methods:     make(map[string]string),
+	}
+}
+
+// Require registers the permission needed to call a method.
+// fullMethod is the gRPC full method name, e.g. rpc.UserService_ForceLogout_FullMethodName.
+func (i *PermissionInterceptor) Require(fullMethod, permission string) *PermissionInterceptor {
+	i.methods[fullMethod] = permission
+	return i
+}
+
+// WithSessions makes protected calls check the session of the access token, like the HTTP
+// middleware.SessionMiddleware: tokens of revoked or expired sessions are rejected, and so is
+// every protected call while the session store cannot be reached.
+func (i *PermissionInterceptor) WithSessions(checker SessionChecker) *PermissionInterceptor {
+	i.sessions = checker
+	return i
+}
+
+// Unary implements grpc.UnaryServerInterceptor.
+// Handlers of protected methods find the caller's claims with auth.ClaimsFromContext.
+func (i *PermissionInterceptor) Unary(
+	ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+) (interface{}, error) {
+	claims, err := i.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	if claims != nil {
+		ctx = auth.WithClaims(ctx, claims)
+	}
+	return handler(ctx, req)
+}
+
+// authorize checks the caller may call fullMethod and returns its claims, or nil claims for an
+// unprotected method.
+func (i *PermissionInterceptor) authorize(ctx context.Context, fullMethod string) (*auth.Claims, error) {
+	permission, ok := i.methods[fullMethod]
+	if !ok {
+		if i.adminPrefix != "" && strings.HasPrefix(fullMethod, i.adminPrefix) {
+			logx.WithContext(ctx).Errorf("no permission registered for admin method: %s", fullMethod)
+			return nil, status.Error(codes.PermissionDenied, "permission denied")
+		}
+		return nil, nil
+	}
+
+	if i.verifier == nil {
+		logx.WithContext(ctx).Errorf("token verifier not configured, rejecting %s", fullMethod)
+		return nil, status.Error(codes.Unauthenticated, "authentication not available")
+	}
+
+	token, ok := auth.TokenFromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing access token")
+	}
+
+	claims, err := i.verifier.Verify(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := i.checkSession(ctx, claims); err != nil {
+		return nil, err
+	}
+
+	if !i.policy.Allows(claims.Roles, permission) {
+		logx.WithContext(ctx).Infof("permission denied: userId=%d, roles=%v, permission=%s, method=%s",
+			claims.UserID, claims.Roles, permission, fullMethod)
+		return nil, status.Error(codes.PermissionDenied, "missing permission "+permission)
+	}
+
+	return claims, nil
+}
+
+// checkSession rejects claims whose session is no longer active, when sessions are checked.
+func (i *PermissionInterceptor) checkSession(ctx context.Context, claims *auth.Claims) error {
+	if i.sessions == nil {
+		return nil
+	}
+	if claims.SessionID == "" {
+		return status.Error(codes.Unauthenticated, auth.ErrMissingSessionID.Error())
+	}
+
+	active, err := i.sessions.IsActive(ctx, claims.UserID, claims.SessionID)
+	if err != nil {
+		// Fail closed: without the session store we cannot tell revoked tokens apart.
+		logx.WithContext(ctx).Errorf("failed to check session: %v, userId=%d", err, claims.UserID)
+		return status.Error(codes.Unavailable, "session check unavailable")
+	}
+	if !active {
+		logx.WithContext(ctx).Infof("rejected revoked session: userId=%d, sessionId=%s", claims.UserID, claims.SessionID)
+		return status.Error(codes.Unauthenticated, "session revoked or expired")
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/aether-defense-system/common/auth"
+)
+
+const (
+	forceLogoutMethod = "/rpc.UserService/ForceLogout"
+	getUserMethod     = "/rpc.UserService/GetUser"
+	adminBanMethod    = "/rpc.UserService/AdminBanUser"
+)
+
+func withToken(t *testing.T, roles []string) context.Context {
+	t.Helper()
+	issuer, err := auth.NewTokenIssuer("secret", 60)
+	if err != nil {
+		t.Fatalf("NewTokenIssuer() error = %v", err)
+	}
+	token, _, err := issuer.IssueAccessToken(1, "sid", roles)
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+	md := metadata.Pairs(auth.MetadataAuthorization, "Bearer "+token)
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func TestPermissionInterceptor_Unary(t *testing.T) {
+	verifier, _ := auth.NewTokenVerifier("secret")
+	i := NewPermissionInterceptor(auth.DefaultPolicy(), verifier, "/rpc.UserService/Admin").
+		Require(forceLogoutMethod, auth.PermUserSessionRevoke)
+
+	tests := []struct {
+		ctx      context.Context
+		name     string
+		method   string
+		wantCode codes.Code
+	}{
+		{name: "granted", ctx: withToken(t, []string{auth.RoleSupport}), method: forceLogoutMethod, wantCode: codes.OK},
+		{
+			name: "denied", ctx: withToken(t, []string{auth.RoleOperator}),
+			method: forceLogoutMethod, wantCode: codes.PermissionDenied,
+		},
+		{name: "missing token", ctx: context.Background(), method: forceLogoutMethod, wantCode: codes.Unauthenticated},
+		{
+			name: "invalid token",
+			ctx: metadata.NewIncomingContext(context.Background(),
+				metadata.Pairs(auth.MetadataAuthorization, "Bearer garbage")),
+			method: forceLogoutMethod, wantCode: codes.Unauthenticated,
+		},
+		{name: "unprotected method", ctx: context.Background(), method: getUserMethod, wantCode: codes.OK},
+		{
+			name: "unregistered admin method", ctx: withToken(t, []string{auth.RoleAdmin}),
+			method: adminBanMethod, wantCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			_, err := i.Unary(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(context.Context, interface{}) (interface{}, error) {
+					called = true
+					return nil, nil
+				})
+
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %v, want %v (err=%v)", got, tt.wantCode, err)
+			}
+			if called != (tt.wantCode == codes.OK) {
+				t.Errorf("handler called = %v", called)
+			}
+		})
+	}
+}
+
+// fakeSessions reports the sessions in active as active, or fails with err.
+type fakeSessions struct {
+	err    error
+	active map[string]bool
+}
+
+func (f *fakeSessions) IsActive(_ context.Context, _ int64, sessionID string) (bool, error) {
+	return f.active[sessionID], f.err
+}
+
+func TestPermissionInterceptor_Sessions(t *testing.T) {
+	verifier, _ := auth.NewTokenVerifier("secret")
+	issuer, _ := auth.NewTokenIssuer("secret", 60)
+	noSession, _, err := issuer.IssueAccessToken(1, "", []string{auth.RoleSupport})
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+
+	tests := []struct {
+		ctx      context.Context
+		sessions *fakeSessions
+		name     string
+		method   string
+		wantCode codes.Code
+	}{
+		{name: "active session", ctx: withToken(t, []string{auth.RoleSupport}), method: forceLogoutMethod,
+			sessions: &fakeSessions{active: map[string]bool{"sid": true}}, wantCode: codes.OK},
+		{name: "revoked session", ctx: withToken(t, []string{auth.RoleSupport}), method: forceLogoutMethod,
+			sessions: &fakeSessions{}, wantCode: codes.Unauthenticated},
+		{name: "session store down", ctx: withToken(t, []string{auth.RoleSupport}), method: forceLogoutMethod,
+			sessions: &fakeSessions{err: errors.New("redis down")}, wantCode: codes.Unavailable},
+		{name: "token without session",
+			ctx: metadata.NewIncomingContext(context.Background(),
+				metadata.Pairs(auth.MetadataAuthorization, "Bearer "+noSession)),
+			method:   forceLogoutMethod,
+			sessions: &fakeSessions{active: map[string]bool{"": true}}, wantCode: codes.Unauthenticated},
+		{name: "unprotected method", ctx: context.Background(), method: getUserMethod,
+			sessions: &fakeSessions{err: errors.New("redis down")}, wantCode: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := NewPermissionInterceptor(auth.DefaultPolicy(), verifier, "").
+				Require(forceLogoutMethod, auth.PermUserSessionRevoke).
+				WithSessions(tt.sessions)
+			_, err := i.Unary(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method},
+				func(context.Context, interface{}) (interface{}, error) { return nil, nil })
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %v, want %v (err=%v)", got, tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestPermissionInterceptor_NoVerifier(t *testing.T) {
+	i := NewPermissionInterceptor(auth.DefaultPolicy(), nil, "").Require(forceLogoutMethod, auth.PermUserSessionRevoke)
+
+	_, err := i.Unary(withToken(t, []string{auth.RoleAdmin}), nil, &grpc.UnaryServerInfo{FullMethod: forceLogoutMethod},
+		func(context.Context, interface{}) (interface{}, error) { return nil, nil })
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("code = %v, want Unauthenticated", status.Code(err))
+	}
+}
+
+func TestPermissionInterceptor_Claims(t *testing.T) {
+	verifier, _ := auth.NewTokenVerifier("secret")
+	i := NewPermissionInterceptor(auth.DefaultPolicy(), verifier, "").Require(forceLogoutMethod, auth.PermUserSessionRevoke)
+
+	var claims *auth.Claims
+	_, err := i.Unary(withToken(t, []string{auth.RoleSupport}), nil, &grpc.UnaryServerInfo{FullMethod: forceLogoutMethod},
+		func(ctx context.Context, _ interface{}) (interface{}, error) {
+			claims, _ = auth.ClaimsFromContext(ctx)
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatalf("Unary() error = %v", err)
+	}
+	if claims == nil || claims.UserID != 1 {
+		t.Errorf("handler claims = %+v, want user 1", claims)
+	}
+
+	_, err = i.Unary(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: getUserMethod},
+		func(ctx context.Context, _ interface{}) (interface{}, error) {
+			if _, ok := auth.ClaimsFromContext(ctx); ok {
+				t.Error("unprotected methods must not carry claims")
+			}
+			return nil, nil
+		})
+	if err != nil {
+		t.Fatalf("Unary() error = %v", err)
+	}
+}
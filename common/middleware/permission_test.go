@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aether-defense-system/common/auth"
+)
+
+func TestPermissionMiddleware_Handle(t *testing.T) {
+	m := NewPermissionMiddleware(auth.DefaultPolicy(), "/v1/admin/").
+		Require(http.MethodPost, "/v1/admin/users/:userId/logout", auth.PermUserSessionRevoke).
+		Require(http.MethodGet, "/v1/reports", auth.PermOrderRead)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		roles      interface{}
+		wantStatus int
+	}{
+		{
+			name:       "granted by role",
+			method:     http.MethodPost,
+			path:       "/v1/admin/users/42/logout",
+			roles:      []interface{}{auth.RoleSupport},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "role lacks permission",
+			method:     http.MethodPost,
+			path:       "/v1/admin/users/42/logout",
+			roles:      []interface{}{auth.RoleOperator},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "no roles claim",
+			method:     http.MethodPost,
+			path:       "/v1/admin/users/42/logout",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "unregistered admin route is denied",
+			method:     http.MethodDelete,
+			path:       "/v1/admin/users/42",
+			roles:      []interface{}{auth.RoleAdmin},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "registered route outside admin prefix",
+			method:     http.MethodGet,
+			path:       "/v1/reports",
+			roles:      []interface{}{auth.RoleOperator},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "unregistered public route passes",
+			method:     http.MethodGet,
+			path:       "/v1/users/42",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.roles != nil {
+				req = req.WithContext(context.WithValue(req.Context(), auth.ClaimRoles, tt.roles)) //nolint:staticcheck // go-zero uses string keys
+			}
+			rec := httptest.NewRecorder()
+
+			m.Handle(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestMatchSegments(t *testing.T) {
+	pattern := splitPath("/v1/orders/:orderId/items")
+
+	if !matchSegments(pattern, splitPath("/v1/orders/7/items")) {
+		t.Errorf("expected match")
+	}
+	if matchSegments(pattern, splitPath("/v1/orders/7")) {
+		t.Errorf("expected length mismatch")
+	}
+	if matchSegments(pattern, splitPath("/v1/orders//items")) {
+		t.Errorf("expected empty path parameter not to match")
+	}
+}
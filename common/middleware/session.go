@@ -0,0 +1,65 @@
+// Package middleware provides HTTP middleware shared by the API gateways.
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/common/auth"
+)
+
+// SessionChecker reports whether a user session is still active.
+// *auth.SessionStore satisfies this interface.
+type SessionChecker interface {
+	IsActive(ctx context.Context, userID int64, sessionID string) (bool, error)
+}
+
+// SessionMiddleware rejects requests whose JWT belongs to a revoked or expired session.
+// It must run after go-zero's JWT middleware, which places the token claims in the context.
+type SessionMiddleware struct {
+	checker SessionChecker
+}
+
+// NewSessionMiddleware creates a new SessionMiddleware.
+func NewSessionMiddleware(checker SessionChecker) *SessionMiddleware {
+	return &SessionMiddleware{checker: checker}
+}
+
+// Handle implements rest.Middleware.
+func (m *SessionMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		userID, err := auth.UserIDFromContext(ctx)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("session check failed: %v", err)
+			http.Error(w, "unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		sessionID, err := auth.SessionIDFromContext(ctx)
+		if err != nil {
+			logx.WithContext(ctx).Errorf("session check failed: %v, userId=%d", err, userID)
+			http.Error(w, "unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		active, err := m.checker.IsActive(ctx, userID, sessionID)
+		if err != nil {
+			// Fail closed: without the session store we cannot tell revoked tokens apart.
+			logx.WithContext(ctx).Errorf("failed to check session: %v, userId=%d", err, userID)
+			http.Error(w, "session check unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		if !active {
+			logx.WithContext(ctx).Infof("rejected revoked session: userId=%d, sessionId=%s", userID, sessionID)
+			http.Error(w, "unauthorized: session revoked or expired", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aether-defense-system/common/auth"
+)
+
+type fakeChecker struct {
+	err    error
+	active bool
+}
+
+func (f *fakeChecker) IsActive(_ context.Context, _ int64, _ string) (bool, error) {
+	return f.active, f.err
+}
+
+func TestSessionMiddleware_Handle(t *testing.T) {
+	tests := []struct {
+		checker    *fakeChecker
+		userID     interface{}
+		sessionID  interface{}
+		name       string
+		wantStatus int
+	}{
+		{
+			name:       "active session",
+			checker:    &fakeChecker{active: true},
+			userID:     json.Number("1"),
+			sessionID:  "sid",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "revoked session",
+			checker:    &fakeChecker{active: false},
+			userID:     json.Number("1"),
+			sessionID:  "sid",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing user id",
+			checker:    &fakeChecker{active: true},
+			sessionID:  "sid",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "token without session",
+			checker:    &fakeChecker{active: true},
+			userID:     json.Number("1"),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "store unavailable",
+			checker:    &fakeChecker{err: errors.New("redis down")},
+			userID:     json.Number("1"),
+			sessionID:  "sid",
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSessionMiddleware(tt.checker).Handle(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			ctx := context.Background()
+			if tt.userID != nil {
+				ctx = context.WithValue(ctx, auth.ClaimUserID, tt.userID) //nolint:staticcheck // go-zero uses string keys
+			}
+			if tt.sessionID != nil {
+				ctx = context.WithValue(ctx, auth.ClaimSessionID, tt.sessionID) //nolint:staticcheck // go-zero uses string keys
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody).WithContext(ctx)
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// lockName is the MySQL user lock held while migrating a database.
+const lockName = "schema_migration"
+
+// createHistoryTable creates the history table of a database.
+const createHistoryTable = `CREATE TABLE IF NOT EXISTS schema_migration (
+  migration_set VARCHAR(64) NOT NULL COMMENT 'Migration set',
+  version BIGINT NOT NULL COMMENT 'Migration version',
+  name VARCHAR(255) NOT NULL COMMENT 'Migration name',
+  checksum CHAR(64) NOT NULL COMMENT 'SHA-256 of the up file',
+  dirty TINYINT NOT NULL DEFAULT 0 COMMENT '1 while running, or after failing halfway',
+  apply_time DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Apply time',
+  PRIMARY KEY (migration_set, version)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Applied schema migrations'`
+
+// sqlSession is a session over a connection holding the migration lock.
+type sqlSession struct {
+	conn *sql.Conn
+}
+
+// openSQLSession takes the migration lock of db, waiting up to timeout, and creates the history
+// table if needed.
+func openSQLSession(ctx context.Context, db *sql.DB, timeout time.Duration) (session, error) {
+	// User locks belong to a connection, so the whole session runs on one.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the database: %w", err)
+	}
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int64(timeout.Seconds())).
+		Scan(&locked); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to take the migration lock: %w", err)
+	}
+	if locked.Int64 != 1 {
+		_ = conn.Close()
+		return nil, fmt.Errorf("another migration holds the lock of the database after %v", timeout)
+	}
+
+	s := &sqlSession{conn: conn}
+	if _, err := conn.ExecContext(ctx, createHistoryTable); err != nil {
+		_ = s.Close()
+		return nil, fmt.Errorf("failed to create the migration history table: %w", err)
+	}
+	return s, nil
+}
+
+func (s *sqlSession) Exec(ctx context.Context, stmt string) error {
+	_, err := s.conn.ExecContext(ctx, stmt)
+	return err
+}
+
+func (s *sqlSession) Records(ctx context.Context, set string) (map[int64]*record, error) {
+	rows, err := s.conn.QueryContext(ctx,
+		"SELECT version, name, checksum, dirty, UNIX_TIMESTAMP(apply_time) FROM schema_migration WHERE migration_set = ?", set)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	records := make(map[int64]*record)
+	for rows.Next() {
+		// Read as a timestamp, since the DSN may not parse times.
+		rec := &record{}
+		var applyTime int64
+		if err := rows.Scan(&rec.version, &rec.name, &rec.checksum, &rec.dirty, &applyTime); err != nil {
+			return nil, err
+		}
+		rec.applyTime = time.Unix(applyTime, 0)
+		records[rec.version] = rec
+	}
+	return records, rows.Err()
+}
+
+func (s *sqlSession) Insert(ctx context.Context, set string, m *Migration, dirty bool) error {
+	_, err := s.conn.ExecContext(ctx,
+		"INSERT INTO schema_migration (migration_set, version, name, checksum, dirty) VALUES (?, ?, ?, ?, ?)",
+		set, m.Version, m.Name, m.Checksum, dirty)
+	return err
+}
+
+func (s *sqlSession) SetDirty(ctx context.Context, set string, version int64, dirty bool) error {
+	_, err := s.conn.ExecContext(ctx,
+		"UPDATE schema_migration SET dirty = ? WHERE migration_set = ? AND version = ?", dirty, set, version)
+	return err
+}
+
+func (s *sqlSession) Delete(ctx context.Context, set string, version int64) error {
+	_, err := s.conn.ExecContext(ctx,
+		"DELETE FROM schema_migration WHERE migration_set = ? AND version = ?", set, version)
+	return err
+}
+
+func (s *sqlSession) Close() error {
+	// Closing the connection would release the lock too, but it goes back to the pool instead.
+	_, err := s.conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+	if closeErr := s.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,178 @@
+// Package migrate applies versioned schema migrations to MySQL databases.
+//
+// A Set is an ordered list of migrations owned by one part of the system, e.g. the trade tables.
+// Each migration is a pair of files named VERSION_NAME.up.sql and VERSION_NAME.down.sql, where
+// VERSION is a positive number: 0001_create_order_tables.up.sql. Down files are optional; a
+// migration without one cannot be reverted.
+//
+// Applied migrations are recorded in the schema_migration table of each database, with the
+// checksum of their up file: a migration edited after it was applied stops further migrations
+// until the difference is resolved. A migration is marked dirty while it runs, since MySQL
+// commits DDL statements one by one; a dirty migration also stops further migrations until an
+// operator resolves it with Force or Forget.
+//
+// Migrations name the logical tables. Run over sharded databases, statements on sharded tables
+// run once per shard of the database, on the tables of the shard, and other statements once.
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Errors reported by migrations.
+var (
+	// ErrDirty is returned when a migration failed halfway and has to be resolved first.
+	ErrDirty = errors.New("schema has a dirty migration")
+	// ErrChecksumMismatch is returned when an applied migration was changed afterwards.
+	ErrChecksumMismatch = errors.New("applied migration was modified")
+	// ErrIrreversible is returned when reverting a migration without a down file.
+	ErrIrreversible = errors.New("migration cannot be reverted")
+)
+
+// Migration is one version of a Set.
+type Migration struct {
+	Name     string
+	Up       string // Statements applying the migration
+	Down     string // Statements reverting it; empty when it cannot be reverted
+	Checksum string // SHA-256 of Up, hex-encoded
+	Version  int64
+}
+
+// Set is the migrations of one part of the system, in version order.
+type Set struct {
+	Name       string
+	Migrations []*Migration
+}
+
+// LoadSet reads the migrations of the set name from the .sql files of fsys.
+func LoadSet(name string, fsys fs.FS) (*Set, error) {
+	if name == "" {
+		return nil, fmt.Errorf("migration set name is required")
+	}
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations of %s: %w", name, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, file := range files {
+		version, migrationName, up, err := parseFileName(file)
+		if err != nil {
+			return nil, fmt.Errorf("migration set %s: %w", name, err)
+		}
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s/%s: %w", name, file, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: migrationName}
+			byVersion[version] = m
+		} else if m.Name != migrationName {
+			return nil, fmt.Errorf("migration set %s: version %d is both %s and %s", name, version, m.Name, migrationName)
+		}
+		if up {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	set := &Set{Name: name}
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("migration set %s: version %d has no up statements", name, m.Version)
+		}
+		m.Checksum = checksum(m.Up)
+		set.Migrations = append(set.Migrations, m)
+	}
+	sort.Slice(set.Migrations, func(i, j int) bool { return set.Migrations[i].Version < set.Migrations[j].Version })
+	return set, nil
+}
+
+// parseFileName splits a file name like 0001_create_order_tables.up.sql.
+func parseFileName(file string) (version int64, name string, up bool, err error) {
+	base := strings.TrimSuffix(path.Base(file), ".sql")
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		up = true
+		base = strings.TrimSuffix(base, ".up")
+	case strings.HasSuffix(base, ".down"):
+		base = strings.TrimSuffix(base, ".down")
+	default:
+		return 0, "", false, fmt.Errorf("migration file %s must end in .up.sql or .down.sql", file)
+	}
+
+	prefix, name, _ := strings.Cut(base, "_")
+	version, err = strconv.ParseInt(prefix, 10, 64)
+	if err != nil || version <= 0 || name == "" {
+		return 0, "", false, fmt.Errorf("migration file %s must be named VERSION_NAME, with a positive version", file)
+	}
+	return version, name, up, nil
+}
+
+// checksum returns the hex-encoded SHA-256 of s.
+func checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// splitStatements splits a script into its statements, at semicolons outside of quotes and
+// comments. Comments are kept with the statement that follows them; statements holding nothing
+// but comments are dropped.
+func splitStatements(script string) []string {
+	var statements []string
+	var quote byte // Quote character of the literal or identifier being read, if any
+	start := 0
+	hasCode := false // Whether the current statement has more than comments and spaces
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++ // Skip the escaped character
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '-' && strings.HasPrefix(script[i:], "-- "), c == '#':
+			if end := strings.IndexByte(script[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(script)
+			}
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			if end := strings.Index(script[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(script)
+			}
+		case c == ';':
+			if hasCode {
+				statements = append(statements, strings.TrimSpace(script[start:i]))
+			}
+			start = i + 1
+			hasCode = false
+		default:
+			if c == '\'' || c == '"' || c == '`' {
+				quote = c
+			}
+			if !unicode.IsSpace(rune(c)) {
+				hasCode = true
+			}
+		}
+	}
+	if hasCode {
+		statements = append(statements, strings.TrimSpace(script[start:]))
+	}
+	return statements
+}
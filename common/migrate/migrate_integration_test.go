@@ -0,0 +1,129 @@
+//go:build integration
+// +build integration
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+// migrateTestDSNEnv names a MySQL server allowed to create and drop the test schema, e.g.
+// root:root@tcp(localhost:3306)/.
+const migrateTestDSNEnv = "MIGRATE_TEST_MYSQL_DSN"
+
+// migrateTestSchema is the local schema migrated by the tests.
+const migrateTestSchema = "aether_migrate_test"
+
+// setupMigrateSchema creates an empty test schema and returns a router of 4 shards over it,
+// sharding the table orders.
+func setupMigrateSchema(t *testing.T) *database.ShardRouter {
+	t.Helper()
+	dsn := os.Getenv(migrateTestDSNEnv)
+	if dsn == "" {
+		t.Skipf("Set %s to run migration integration tests.", migrateTestDSNEnv)
+	}
+	base, err := mysql.ParseDSN(dsn)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	server, err := sql.Open("mysql", base.FormatDSN())
+	require.NoError(t, err)
+	defer func() { _ = server.Close() }()
+	if err := server.PingContext(ctx); err != nil {
+		t.Skipf("MySQL not available for integration test: %v", err)
+	}
+	for _, stmt := range []string{
+		"DROP DATABASE IF EXISTS " + migrateTestSchema,
+		"CREATE DATABASE " + migrateTestSchema,
+	} {
+		_, err := server.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	t.Cleanup(func() {
+		dropDB, err := sql.Open("mysql", base.FormatDSN())
+		if err != nil {
+			return
+		}
+		defer func() { _ = dropDB.Close() }()
+		_, _ = dropDB.Exec("DROP DATABASE IF EXISTS " + migrateTestSchema)
+	})
+
+	cfg := base.Clone()
+	cfg.DBName = migrateTestSchema
+	r, err := database.NewShardRouter(&database.ShardingConfig{
+		Shards:    4,
+		Databases: []database.Config{{DSN: cfg.FormatDSN()}},
+	}, "orders")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+// tableExists reports whether the test schema has table.
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+	var n int
+	err := db.QueryRow("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = ? AND table_name = ?",
+		migrateTestSchema, table).Scan(&n)
+	require.NoError(t, err)
+	return n == 1
+}
+
+func TestIntegration_Migrator_Shards(t *testing.T) {
+	r := setupMigrateSchema(t)
+	db := r.Databases()[0]
+	ctx := context.Background()
+	migrators := ForRouter(r, WithLockTimeout(5*time.Second))
+	require.Len(t, migrators, 1)
+	m := migrators[0]
+	set := newTestSet()
+
+	applied, err := m.Up(ctx, set)
+	require.NoError(t, err)
+	assert.Len(t, applied, 2)
+	for _, table := range []string{"orders_0", "orders_3", "users", "schema_migration"} {
+		assert.True(t, tableExists(t, db, table), "expected table %s", table)
+	}
+	assert.False(t, tableExists(t, db, "orders"), "expected no unsharded orders table")
+
+	statuses, err := m.Status(ctx, set)
+	require.NoError(t, err)
+	for _, st := range statuses {
+		assert.Equal(t, StateApplied, st.State)
+	}
+
+	_, err = m.Down(ctx, set, 2)
+	require.NoError(t, err)
+	assert.False(t, tableExists(t, db, "orders_0"))
+	assert.False(t, tableExists(t, db, "users"))
+}
+
+func TestIntegration_Migrator_Failure(t *testing.T) {
+	r := setupMigrateSchema(t)
+	ctx := context.Background()
+	m := New(r.Databases()[0])
+	set := newTestSet()
+	set.Migrations[1].Up = "ALTER TABLE missing ADD remark VARCHAR(64);"
+	set.Migrations[1].Checksum = checksum(set.Migrations[1].Up)
+
+	_, err := m.Up(ctx, set)
+	require.Error(t, err)
+	_, err = m.Up(ctx, set)
+	assert.ErrorIs(t, err, ErrDirty)
+
+	require.NoError(t, m.Force(ctx, set, 2))
+	statuses, err := m.Status(ctx, set)
+	require.NoError(t, err)
+	assert.Equal(t, StateApplied, statuses[1].State)
+}
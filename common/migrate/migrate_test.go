@@ -0,0 +1,84 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSet(t *testing.T) {
+	set, err := LoadSet("trade", fstest.MapFS{
+		"0002_add_remark.up.sql":   {Data: []byte("ALTER TABLE trade_order ADD remark VARCHAR(64);")},
+		"0001_init.up.sql":         {Data: []byte("CREATE TABLE trade_order (id BIGINT);")},
+		"0001_init.down.sql":       {Data: []byte("DROP TABLE trade_order;")},
+		"README.md":                {Data: []byte("not a migration")},
+		"0010_backfill.up.sql":     {Data: []byte("UPDATE trade_order SET remark = '';")},
+		"0010_backfill.down.sql":   {Data: []byte("UPDATE trade_order SET remark = NULL;")},
+		"0002_add_remark.down.sql": {Data: []byte("ALTER TABLE trade_order DROP remark;")},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "trade", set.Name)
+	require.Len(t, set.Migrations, 3)
+	assert.Equal(t, []int64{1, 2, 10},
+		[]int64{set.Migrations[0].Version, set.Migrations[1].Version, set.Migrations[2].Version})
+	first := set.Migrations[0]
+	assert.Equal(t, "init", first.Name)
+	assert.Equal(t, "CREATE TABLE trade_order (id BIGINT);", first.Up)
+	assert.Equal(t, "DROP TABLE trade_order;", first.Down)
+	assert.Equal(t, checksum(first.Up), first.Checksum)
+	assert.Len(t, first.Checksum, 64)
+}
+
+func TestLoadSet_Invalid(t *testing.T) {
+	tests := []struct {
+		files fstest.MapFS
+		name  string
+	}{
+		{name: "no direction", files: fstest.MapFS{"0001_init.sql": {Data: []byte("SELECT 1;")}}},
+		{name: "no version", files: fstest.MapFS{"init.up.sql": {Data: []byte("SELECT 1;")}}},
+		{name: "zero version", files: fstest.MapFS{"0000_init.up.sql": {Data: []byte("SELECT 1;")}}},
+		{name: "no name", files: fstest.MapFS{"0001.up.sql": {Data: []byte("SELECT 1;")}}},
+		{name: "down only", files: fstest.MapFS{"0001_init.down.sql": {Data: []byte("SELECT 1;")}}},
+		{name: "two names", files: fstest.MapFS{
+			"0001_init.up.sql":  {Data: []byte("SELECT 1;")},
+			"0001_other.up.sql": {Data: []byte("SELECT 2;")},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadSet("trade", tt.files)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- Order table
+CREATE TABLE t (
+  a VARCHAR(8) DEFAULT 'x;y' COMMENT 'the item''s amount; in cents',
+  b INT COMMENT "it's; fine" -- trailing; comment
+);
+# hash; comment
+/* block; comment */
+INSERT INTO t (a) VALUES ('back\'slash;');
+
+-- only a comment;
+;
+SELECT ` + "`weird;name`" + ` FROM t`
+
+	stmts := splitStatements(script)
+	require.Len(t, stmts, 3)
+	assert.Contains(t, stmts[0], "CREATE TABLE t")
+	assert.Contains(t, stmts[0], "-- Order table")
+	assert.Contains(t, stmts[0], "'the item''s amount; in cents'")
+	assert.Contains(t, stmts[1], `VALUES ('back\'slash;')`)
+	assert.Equal(t, "SELECT `weird;name` FROM t", stmts[2])
+}
+
+func TestSplitStatements_Empty(t *testing.T) {
+	assert.Empty(t, splitStatements(""))
+	assert.Empty(t, splitStatements("-- nothing here\n/* nor here */;\n"))
+}
@@ -0,0 +1,382 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+// defaultLockTimeout bounds how long a Migrator waits for another one migrating the same database.
+const defaultLockTimeout = time.Minute
+
+// defaultTimeout bounds how long a Migrator applies or reverts migrations.
+const defaultTimeout = 5 * time.Minute
+
+// Migration states reported by Status.
+const (
+	StatePending  = "pending"  // Not applied yet
+	StateApplied  = "applied"  // Applied as it is
+	StateModified = "modified" // Applied, but changed since
+	StateDirty    = "dirty"    // Failed halfway, or still running
+	StateUnknown  = "unknown"  // Applied by a newer version of the code
+)
+
+// Status is the state of a migration in a database.
+type Status struct {
+	AppliedAt *time.Time
+	Name      string
+	State     string
+	Version   int64
+}
+
+// record is a row of the history table.
+type record struct {
+	applyTime time.Time
+	name      string
+	checksum  string
+	version   int64
+	dirty     bool
+}
+
+// session is a database being migrated, locked against other Migrators until it is closed.
+type session interface {
+	// Exec executes a statement of a migration.
+	Exec(ctx context.Context, stmt string) error
+	// Records returns the history of set, by version.
+	Records(ctx context.Context, set string) (map[int64]*record, error)
+	// Insert records m as applied, or as running if dirty.
+	Insert(ctx context.Context, set string, m *Migration, dirty bool) error
+	// SetDirty marks the migration version of set as running, or as applied.
+	SetDirty(ctx context.Context, set string, version int64, dirty bool) error
+	// Delete forgets the migration version of set.
+	Delete(ctx context.Context, set string, version int64) error
+	// Close unlocks the database.
+	Close() error
+}
+
+// rewriter names the tables of a shard in statements; *database.Shard implements it.
+type rewriter interface {
+	Rewrite(query string) string
+}
+
+// Migrator migrates one database.
+type Migrator struct {
+	open        func(ctx context.Context) (session, error)
+	name        string     // Names the database in logs and errors
+	shards      []rewriter // Shards held by the database, if sharded
+	lockTimeout time.Duration
+	timeout     time.Duration
+}
+
+// Option configures a Migrator.
+type Option func(m *Migrator)
+
+// WithShards runs the statements on sharded tables once per shard in shards, which must all live
+// in the database being migrated.
+func WithShards(shards []*database.Shard) Option {
+	return func(m *Migrator) {
+		m.shards = make([]rewriter, len(shards))
+		for i, s := range shards {
+			m.shards[i] = s
+		}
+	}
+}
+
+// WithLockTimeout sets how long to wait for another Migrator of the same database (default: 1m).
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(m *Migrator) {
+		m.lockTimeout = timeout
+	}
+}
+
+// WithTimeout sets how long Up and Down may run, lock wait included (default: 5m); 0 disables it.
+func WithTimeout(timeout time.Duration) Option {
+	return func(m *Migrator) {
+		m.timeout = timeout
+	}
+}
+
+// WithName names the database in logs and errors, e.g. "shard database 1".
+func WithName(name string) Option {
+	return func(m *Migrator) {
+		m.name = name
+	}
+}
+
+// New creates a Migrator of db.
+func New(db *sql.DB, opts ...Option) *Migrator {
+	m := &Migrator{name: "database", lockTimeout: defaultLockTimeout, timeout: defaultTimeout}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.open = func(ctx context.Context) (session, error) {
+		return openSQLSession(ctx, db, m.lockTimeout)
+	}
+	return m
+}
+
+// ForRouter creates a Migrator for each database of r, which runs the statements on sharded
+// tables in every shard of its database.
+func ForRouter(r *database.ShardRouter, opts ...Option) []*Migrator {
+	dbs := r.Databases()
+	migrators := make([]*Migrator, len(dbs))
+	for i, db := range dbs {
+		var shards []*database.Shard
+		for _, s := range r.Shards() {
+			if s.DB() == db {
+				shards = append(shards, s)
+			}
+		}
+		dbOpts := []Option{WithShards(shards)}
+		if len(dbs) > 1 {
+			dbOpts = append(dbOpts, WithName(fmt.Sprintf("shard database %d", i)))
+		}
+		migrators[i] = New(db, append(dbOpts, opts...)...)
+	}
+	return migrators
+}
+
+// Name returns the name of the database in logs and errors.
+func (m *Migrator) Name() string {
+	return m.name
+}
+
+// Up applies the pending migrations of set in version order and returns them. It applies none if
+// a migration is dirty or an applied one was modified.
+func (m *Migrator) Up(ctx context.Context, set *Set) ([]*Migration, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+	s, err := m.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = s.Close() }()
+
+	records, err := m.check(ctx, s, set)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []*Migration
+	for _, mig := range set.Migrations {
+		if records[mig.Version] != nil {
+			continue
+		}
+		if err := s.Insert(ctx, set.Name, mig, true); err != nil {
+			return applied, fmt.Errorf("failed to record migration %s/%d in %s: %w", set.Name, mig.Version, m.name, err)
+		}
+		if err := m.exec(ctx, s, mig.Up); err != nil {
+			return applied, fmt.Errorf("migration %s/%d_%s failed in %s and is left dirty: %w",
+				set.Name, mig.Version, mig.Name, m.name, err)
+		}
+		if err := s.SetDirty(ctx, set.Name, mig.Version, false); err != nil {
+			return applied, fmt.Errorf("failed to record migration %s/%d in %s: %w", set.Name, mig.Version, m.name, err)
+		}
+		logx.Infof("applied migration %s/%d_%s to %s", set.Name, mig.Version, mig.Name, m.name)
+		applied = append(applied, mig)
+	}
+	return applied, nil
+}
+
+// Down reverts the last steps applied migrations of set, newest first, and returns them.
+func (m *Migrator) Down(ctx context.Context, set *Set, steps int) ([]*Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+	s, err := m.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = s.Close() }()
+
+	records, err := m.check(ctx, s, set)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []*Migration
+	for i := len(set.Migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		mig := set.Migrations[i]
+		if records[mig.Version] == nil {
+			continue
+		}
+		if mig.Down == "" {
+			return reverted, fmt.Errorf("%w: %s/%d_%s has no down file", ErrIrreversible, set.Name, mig.Version, mig.Name)
+		}
+		if err := s.SetDirty(ctx, set.Name, mig.Version, true); err != nil {
+			return reverted, fmt.Errorf("failed to record migration %s/%d in %s: %w", set.Name, mig.Version, m.name, err)
+		}
+		if err := m.exec(ctx, s, mig.Down); err != nil {
+			return reverted, fmt.Errorf("reverting migration %s/%d_%s failed in %s and left it dirty: %w",
+				set.Name, mig.Version, mig.Name, m.name, err)
+		}
+		if err := s.Delete(ctx, set.Name, mig.Version); err != nil {
+			return reverted, fmt.Errorf("failed to record migration %s/%d in %s: %w", set.Name, mig.Version, m.name, err)
+		}
+		logx.Infof("reverted migration %s/%d_%s in %s", set.Name, mig.Version, mig.Name, m.name)
+		reverted = append(reverted, mig)
+	}
+	return reverted, nil
+}
+
+// Status returns the state of every migration of set, and of those applied by newer code.
+func (m *Migrator) Status(ctx context.Context, set *Set) ([]Status, error) {
+	s, err := m.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = s.Close() }()
+
+	records, err := s.Records(ctx, set.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration history of %s: %w", m.name, err)
+	}
+
+	statuses := make([]Status, 0, len(set.Migrations))
+	for _, mig := range set.Migrations {
+		st := Status{Version: mig.Version, Name: mig.Name, State: StatePending}
+		if rec := records[mig.Version]; rec != nil {
+			st.State = recordState(rec, mig)
+			st.AppliedAt = &rec.applyTime
+			delete(records, mig.Version)
+		}
+		statuses = append(statuses, st)
+	}
+	unknown := make([]*record, 0, len(records))
+	for _, rec := range records {
+		unknown = append(unknown, rec)
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].version < unknown[j].version })
+	for _, rec := range unknown {
+		statuses = append(statuses, Status{
+			Version: rec.version, Name: rec.name, State: recordState(rec, nil), AppliedAt: &rec.applyTime,
+		})
+	}
+	return statuses, nil
+}
+
+// Force records the migration version of set as applied, without running it: after an operator
+// completed a dirty migration by hand, or applied it otherwise.
+func (m *Migrator) Force(ctx context.Context, set *Set, version int64) error {
+	mig := set.migration(version)
+	if mig == nil {
+		return fmt.Errorf("migration set %s has no version %d", set.Name, version)
+	}
+	s, err := m.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	records, err := s.Records(ctx, set.Name)
+	if err != nil {
+		return fmt.Errorf("failed to read migration history of %s: %w", m.name, err)
+	}
+	if records[version] != nil {
+		if err := s.Delete(ctx, set.Name, version); err != nil {
+			return fmt.Errorf("failed to record migration %s/%d in %s: %w", set.Name, version, m.name, err)
+		}
+	}
+	if err := s.Insert(ctx, set.Name, mig, false); err != nil {
+		return fmt.Errorf("failed to record migration %s/%d in %s: %w", set.Name, version, m.name, err)
+	}
+	return nil
+}
+
+// Forget removes the migration version of set from the history, without reverting it: after an
+// operator undid a dirty migration by hand, so that Up runs it again.
+func (m *Migrator) Forget(ctx context.Context, set *Set, version int64) error {
+	s, err := m.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	if err := s.Delete(ctx, set.Name, version); err != nil {
+		return fmt.Errorf("failed to forget migration %s/%d in %s: %w", set.Name, version, m.name, err)
+	}
+	return nil
+}
+
+// withTimeout bounds ctx by the timeout of m, if any.
+func (m *Migrator) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, m.timeout)
+}
+
+// check reads the history of set and fails if a migration is dirty or was modified.
+func (m *Migrator) check(ctx context.Context, s session, set *Set) (map[int64]*record, error) {
+	records, err := s.Records(ctx, set.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration history of %s: %w", m.name, err)
+	}
+	for _, rec := range records {
+		if rec.dirty {
+			return nil, fmt.Errorf("%w: %s/%d_%s in %s; complete or undo it, then force or forget it",
+				ErrDirty, set.Name, rec.version, rec.name, m.name)
+		}
+		if mig := set.migration(rec.version); mig != nil && mig.Checksum != rec.checksum {
+			return nil, fmt.Errorf("%w: %s/%d_%s in %s; add a new migration instead",
+				ErrChecksumMismatch, set.Name, rec.version, rec.name, m.name)
+		}
+	}
+	return records, nil
+}
+
+// exec executes the statements of script, those on sharded tables in every shard.
+func (m *Migrator) exec(ctx context.Context, s session, script string) error {
+	for _, stmt := range splitStatements(script) {
+		for _, shardStmt := range m.expand(stmt) {
+			if err := s.Exec(ctx, shardStmt); err != nil {
+				return fmt.Errorf("%w, in: %s", err, shardStmt)
+			}
+		}
+	}
+	return nil
+}
+
+// expand returns stmt once per shard if it names sharded tables, and as it is otherwise.
+func (m *Migrator) expand(stmt string) []string {
+	if len(m.shards) == 0 || m.shards[0].Rewrite(stmt) == stmt {
+		return []string{stmt}
+	}
+	stmts := make([]string, len(m.shards))
+	for i, shard := range m.shards {
+		stmts[i] = shard.Rewrite(stmt)
+	}
+	return stmts
+}
+
+// migration returns the migration version of s, or nil.
+func (s *Set) migration(version int64) *Migration {
+	for _, m := range s.Migrations {
+		if m.Version == version {
+			return m
+		}
+	}
+	return nil
+}
+
+// recordState returns the state of an applied migration, mig being nil if unknown to the code.
+func recordState(rec *record, mig *Migration) string {
+	switch {
+	case rec.dirty:
+		return StateDirty
+	case mig == nil:
+		return StateUnknown
+	case mig.Checksum != rec.checksum:
+		return StateModified
+	default:
+		return StateApplied
+	}
+}
@@ -0,0 +1,268 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSession is an in-memory session.
+type fakeSession struct {
+	records map[string]map[int64]*record
+	failOn  string   // Exec fails on statements containing it, when set
+	execs   []string // Executed statements
+	opened  int
+	closed  int
+}
+
+func newFakeSession() *fakeSession {
+	return &fakeSession{records: make(map[string]map[int64]*record)}
+}
+
+func (s *fakeSession) Exec(_ context.Context, stmt string) error {
+	if s.failOn != "" && strings.Contains(stmt, s.failOn) {
+		return errors.New("syntax error")
+	}
+	s.execs = append(s.execs, stmt)
+	return nil
+}
+
+func (s *fakeSession) Records(_ context.Context, set string) (map[int64]*record, error) {
+	records := make(map[int64]*record)
+	for v, rec := range s.records[set] {
+		copied := *rec
+		records[v] = &copied
+	}
+	return records, nil
+}
+
+func (s *fakeSession) Insert(_ context.Context, set string, m *Migration, dirty bool) error {
+	if s.records[set] == nil {
+		s.records[set] = make(map[int64]*record)
+	}
+	if s.records[set][m.Version] != nil {
+		return errors.New("duplicate entry")
+	}
+	s.records[set][m.Version] = &record{
+		version: m.Version, name: m.Name, checksum: m.Checksum, dirty: dirty, applyTime: time.Now(),
+	}
+	return nil
+}
+
+func (s *fakeSession) SetDirty(_ context.Context, set string, version int64, dirty bool) error {
+	s.records[set][version].dirty = dirty
+	return nil
+}
+
+func (s *fakeSession) Delete(_ context.Context, set string, version int64) error {
+	delete(s.records[set], version)
+	return nil
+}
+
+func (s *fakeSession) Close() error {
+	s.closed++
+	return nil
+}
+
+// suffixShard rewrites the table order like a shard.
+type suffixShard string
+
+func (s suffixShard) Rewrite(query string) string {
+	return strings.ReplaceAll(query, "orders", "orders"+string(s))
+}
+
+// newTestMigrator returns a Migrator over s.
+func newTestMigrator(s *fakeSession, shards ...rewriter) *Migrator {
+	return &Migrator{
+		name:   "database",
+		shards: shards,
+		open: func(context.Context) (session, error) {
+			s.opened++
+			return s, nil
+		},
+	}
+}
+
+// newTestSet returns a set of two migrations, the first creating orders and users.
+func newTestSet() *Set {
+	up1 := "CREATE TABLE orders (id BIGINT);\nCREATE TABLE users (id BIGINT);"
+	up2 := "ALTER TABLE orders ADD remark VARCHAR(64);"
+	return &Set{Name: "test", Migrations: []*Migration{
+		{Version: 1, Name: "init", Up: up1, Down: "DROP TABLE users;\nDROP TABLE orders;", Checksum: checksum(up1)},
+		{Version: 2, Name: "add_remark", Up: up2, Down: "ALTER TABLE orders DROP remark;", Checksum: checksum(up2)},
+	}}
+}
+
+func TestMigrator_Up(t *testing.T) {
+	s := newFakeSession()
+	m := newTestMigrator(s)
+	set := newTestSet()
+
+	applied, err := m.Up(context.Background(), set)
+	require.NoError(t, err)
+	assert.Len(t, applied, 2)
+	assert.Equal(t, []string{
+		"CREATE TABLE orders (id BIGINT)",
+		"CREATE TABLE users (id BIGINT)",
+		"ALTER TABLE orders ADD remark VARCHAR(64)",
+	}, s.execs)
+	require.Len(t, s.records["test"], 2)
+	assert.False(t, s.records["test"][1].dirty)
+	assert.Equal(t, set.Migrations[1].Checksum, s.records["test"][2].checksum)
+	assert.Equal(t, s.opened, s.closed, "expected the lock released")
+
+	// Applied migrations are not run again.
+	applied, err = m.Up(context.Background(), set)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Len(t, s.execs, 3)
+}
+
+func TestMigrator_Up_Timeout(t *testing.T) {
+	s := newFakeSession()
+	m := newTestMigrator(s)
+	var deadline time.Time
+	m.timeout = time.Minute
+	m.open = func(ctx context.Context) (session, error) {
+		deadline, _ = ctx.Deadline()
+		return s, nil
+	}
+
+	_, err := m.Up(context.Background(), newTestSet())
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+
+	// A zero timeout leaves the context unbounded.
+	m.timeout = 0
+	_, err = m.Up(context.Background(), newTestSet())
+	require.NoError(t, err)
+	assert.True(t, deadline.IsZero())
+}
+
+func TestMigrator_Up_Failure(t *testing.T) {
+	s := newFakeSession()
+	s.failOn = "remark"
+	m := newTestMigrator(s)
+	set := newTestSet()
+
+	applied, err := m.Up(context.Background(), set)
+	require.Error(t, err)
+	assert.Len(t, applied, 1)
+	assert.True(t, s.records["test"][2].dirty, "expected the failed migration left dirty")
+
+	// A dirty migration stops further migrations until it is resolved.
+	s.failOn = ""
+	_, err = m.Up(context.Background(), set)
+	assert.ErrorIs(t, err, ErrDirty)
+
+	require.NoError(t, m.Forget(context.Background(), set, 2))
+	applied, err = m.Up(context.Background(), set)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, int64(2), applied[0].Version)
+}
+
+func TestMigrator_Up_ChecksumMismatch(t *testing.T) {
+	s := newFakeSession()
+	m := newTestMigrator(s)
+	set := newTestSet()
+	_, err := m.Up(context.Background(), set)
+	require.NoError(t, err)
+
+	set.Migrations[0].Up += "\nCREATE TABLE extra (id BIGINT);"
+	set.Migrations[0].Checksum = checksum(set.Migrations[0].Up)
+	set.Migrations = append(set.Migrations, &Migration{Version: 3, Name: "next", Up: "SELECT 1;", Checksum: checksum("SELECT 1;")})
+
+	_, err = m.Up(context.Background(), set)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.Len(t, s.records["test"], 2, "expected nothing applied")
+
+	statuses, err := m.Status(context.Background(), set)
+	require.NoError(t, err)
+	assert.Equal(t, StateModified, statuses[0].State)
+	assert.Equal(t, StatePending, statuses[2].State)
+}
+
+func TestMigrator_Up_Shards(t *testing.T) {
+	s := newFakeSession()
+	m := newTestMigrator(s, suffixShard("_0"), suffixShard("_1"))
+
+	_, err := m.Up(context.Background(), newTestSet())
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"CREATE TABLE orders_0 (id BIGINT)",
+		"CREATE TABLE orders_1 (id BIGINT)",
+		"CREATE TABLE users (id BIGINT)",
+		"ALTER TABLE orders_0 ADD remark VARCHAR(64)",
+		"ALTER TABLE orders_1 ADD remark VARCHAR(64)",
+	}, s.execs)
+}
+
+func TestMigrator_Down(t *testing.T) {
+	s := newFakeSession()
+	m := newTestMigrator(s)
+	set := newTestSet()
+	_, err := m.Up(context.Background(), set)
+	require.NoError(t, err)
+	s.execs = nil
+
+	reverted, err := m.Down(context.Background(), set, 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	assert.Equal(t, int64(2), reverted[0].Version)
+	assert.Equal(t, []string{"ALTER TABLE orders DROP remark"}, s.execs)
+	assert.Len(t, s.records["test"], 1)
+
+	reverted, err = m.Down(context.Background(), set, 5)
+	require.NoError(t, err)
+	assert.Len(t, reverted, 1)
+	assert.Empty(t, s.records["test"])
+
+	_, err = m.Down(context.Background(), set, 0)
+	assert.Error(t, err)
+}
+
+func TestMigrator_Down_Irreversible(t *testing.T) {
+	s := newFakeSession()
+	m := newTestMigrator(s)
+	set := newTestSet()
+	set.Migrations[1].Down = ""
+	_, err := m.Up(context.Background(), set)
+	require.NoError(t, err)
+
+	_, err = m.Down(context.Background(), set, 1)
+	assert.ErrorIs(t, err, ErrIrreversible)
+	assert.Len(t, s.records["test"], 2)
+}
+
+func TestMigrator_ForceAndStatus(t *testing.T) {
+	s := newFakeSession()
+	m := newTestMigrator(s)
+	set := newTestSet()
+
+	require.NoError(t, m.Force(context.Background(), set, 1))
+	assert.Empty(t, s.execs, "expected force not to run the migration")
+	assert.Error(t, m.Force(context.Background(), set, 9))
+
+	// A migration applied by newer code is reported as unknown.
+	s.records["test"][7] = &record{version: 7, name: "newer", checksum: "x", applyTime: time.Now()}
+
+	statuses, err := m.Status(context.Background(), set)
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.Equal(t, StateApplied, statuses[0].State)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.Equal(t, StatePending, statuses[1].State)
+	assert.Nil(t, statuses[1].AppliedAt)
+	assert.Equal(t, Status{Version: 7, Name: "newer", State: StateUnknown, AppliedAt: statuses[2].AppliedAt}, statuses[2])
+
+	// Unknown migrations do not stop the known ones.
+	applied, err := m.Up(context.Background(), set)
+	require.NoError(t, err)
+	assert.Len(t, applied, 1)
+}
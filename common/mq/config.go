@@ -1,4 +1,3 @@
-// Package mq provides message queue configuration and client wrappers.
 package mq
 
 // Config represents RocketMQ configuration.
@@ -6,7 +5,7 @@ type Config struct {
 	// NameServer addresses (comma-separated or semicolon-separated)
 	// Example: "127.0.0.1:9876" or "127.0.0.1:9876;192.168.1.1:9876"
 	NameServer string `json:"nameServer" yaml:"nameServer"`
-	// Producer group name
+	// Producer or consumer group name
 	Group string `json:"group" yaml:"group"`
 	// Topic name for messages
 	Topic string `json:"topic" yaml:"topic"`
@@ -14,4 +13,7 @@ type Config struct {
 	RetryTimes int `json:"retryTimes,omitempty" yaml:"retryTimes,omitempty"`
 	// Timeout for sending messages in milliseconds (default: 3000)
 	SendTimeout int `json:"sendTimeout,omitempty" yaml:"sendTimeout,omitempty"`
+	// Deliveries of a failing message before it moves to the dead-letter topic (default: 16)
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	MaxReconsumeTimes int32 `json:"maxReconsumeTimes,optional" yaml:"maxReconsumeTimes,omitempty"`
 }
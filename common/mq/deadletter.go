@@ -0,0 +1,214 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	rocketmq "github.com/apache/rocketmq-client-go/v2"
+	"github.com/apache/rocketmq-client-go/v2/admin"
+	"github.com/apache/rocketmq-client-go/v2/consumer"
+	rmqerrors "github.com/apache/rocketmq-client-go/v2/errors"
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+// PropertyReplayOf holds the ID of the dead-lettered message a replayed message was copied from.
+const PropertyReplayOf = "x-replay-of"
+
+// DeadLetterReader reads the messages consumer groups gave up on, without consuming them.
+type DeadLetterReader interface {
+	// ReadDeadLetters returns up to limit messages of the dead-letter topic of group, oldest first.
+	ReadDeadLetters(ctx context.Context, group string, limit int) ([]*Message, error)
+}
+
+// ReadDeadLetters implements DeadLetterReader.
+func (b *MemoryBroker) ReadDeadLetters(_ context.Context, group string, limit int) ([]*Message, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	dead := b.DeadLetters(group)
+	if len(dead) > limit {
+		dead = dead[:limit]
+	}
+	return dead, nil
+}
+
+// NewReplayMessage returns a copy of a dead-lettered message addressed to topic, with the tag, keys
+// and body of the original but none of the properties the broker set for delivery. Send it with
+// ExtractContext(ctx, dead) so that the trace context, request ID and user properties of the
+// original travel with the replay.
+func NewReplayMessage(dead *Message, topic string) *Message {
+	msg := NewMessage(topic, append([]byte(nil), dead.Body...)).
+		WithTag(dead.Tag).
+		WithKeys(append([]string(nil), dead.Keys...)...)
+	return msg.WithProperty(PropertyReplayOf, dead.MsgID)
+}
+
+// ConsumeFailure is the last failed delivery of a message to a consumer group.
+type ConsumeFailure struct {
+	FailedAt       time.Time
+	Reason         string
+	ReconsumeTimes int32
+}
+
+// FailureLog records why deliveries failed. Brokers keep dead-lettered messages, but not the
+// errors that put them there.
+type FailureLog interface {
+	// RecordFailure records that delivering msg to group failed for reason.
+	RecordFailure(ctx context.Context, group string, msg *Message, reason string) error
+	// Failures returns the last recorded failure of each message of group, by message ID.
+	// Messages without a recorded failure are missing from the result.
+	Failures(ctx context.Context, group string, msgIDs []string) (map[string]*ConsumeFailure, error)
+}
+
+// RecordFailures wraps handler so that the errors it returns for group are recorded in log under
+// the message ID, which stays the same through redeliveries and the dead-letter topic.
+// A failure to record is logged; the delivery fails with the handler error either way.
+func RecordFailures(group string, log FailureLog, handler Handler) Handler {
+	return func(ctx context.Context, msg *Message) error {
+		err := handler(ctx, msg)
+		if err != nil {
+			if recordErr := log.RecordFailure(ctx, group, msg, err.Error()); recordErr != nil {
+				logx.WithContext(ctx).Errorf("failed to record failure of message %s: %v", msg.MsgID, recordErr)
+			}
+		}
+		return err
+	}
+}
+
+// SQLFailureLog is a FailureLog backed by the mq_consume_failure table.
+type SQLFailureLog struct {
+	store *database.ConsumeFailureStore
+}
+
+// NewSQLFailureLog creates a SQLFailureLog.
+func NewSQLFailureLog(store *database.ConsumeFailureStore) *SQLFailureLog {
+	return &SQLFailureLog{store: store}
+}
+
+// RecordFailure implements FailureLog.
+func (l *SQLFailureLog) RecordFailure(ctx context.Context, group string, msg *Message, reason string) error {
+	return l.store.Record(ctx, &database.ConsumeFailure{
+		ConsumerGroup:  group,
+		MsgID:          msg.MsgID,
+		Topic:          msg.Topic,
+		ReconsumeTimes: msg.ReconsumeTimes,
+		Reason:         reason,
+	})
+}
+
+// Failures implements FailureLog.
+func (l *SQLFailureLog) Failures(
+	ctx context.Context, group string, msgIDs []string,
+) (map[string]*ConsumeFailure, error) {
+	rows, err := l.store.Get(ctx, group, msgIDs)
+	if err != nil {
+		return nil, err
+	}
+	failures := make(map[string]*ConsumeFailure, len(rows))
+	for _, row := range rows {
+		failures[row.MsgID] = &ConsumeFailure{
+			FailedAt:       row.UpdateTime,
+			Reason:         row.Reason,
+			ReconsumeTimes: row.ReconsumeTimes,
+		}
+	}
+	return failures, nil
+}
+
+// deadLetterPullBatch is how many messages RocketMQDeadLetterReader pulls per request.
+const deadLetterPullBatch = 32
+
+// RocketMQDeadLetterReader reads RocketMQ dead-letter topics with a pull consumer that starts at
+// the oldest message the broker keeps and never commits offsets, so reading does not remove
+// anything. Config.Group is the consumer group it reads as; it must not be a group in use.
+type RocketMQDeadLetterReader struct {
+	config *Config
+	// mu serializes reads: the group can only be registered once per client at a time.
+	mu sync.Mutex
+}
+
+// NewDeadLetterReader creates a RocketMQDeadLetterReader. Clients are created per read, since
+// reads are rare and a pull consumer is bound to the topic it was started with.
+func NewDeadLetterReader(cfg *Config) (*RocketMQDeadLetterReader, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &RocketMQDeadLetterReader{config: cfg}, nil
+}
+
+// ReadDeadLetters implements DeadLetterReader.
+func (r *RocketMQDeadLetterReader) ReadDeadLetters(ctx context.Context, group string, limit int) ([]*Message, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	topic := DLQTopic(group)
+	nameServers := parseNameServers(r.config.NameServer)
+
+	mqAdmin, err := admin.NewAdmin(admin.WithResolver(primitive.NewPassthroughResolver(nameServers)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admin client: %w", err)
+	}
+	defer func() {
+		if closeErr := mqAdmin.Close(); closeErr != nil {
+			logx.WithContext(ctx).Errorf("failed to close admin client: %v", closeErr)
+		}
+	}()
+	queues, err := mqAdmin.FetchPublishMessageQueues(ctx, topic)
+	if err != nil {
+		// A group that never dead-lettered a message has no dead-letter topic.
+		if errors.Is(err, rmqerrors.ErrTopicNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to fetch queues of %s: %w", topic, err)
+	}
+
+	pull, err := rocketmq.NewPullConsumer(
+		consumer.WithNameServer(nameServers),
+		consumer.WithGroupName(r.config.Group),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pull consumer: %w", err)
+	}
+	if err := pull.Subscribe(topic, consumer.MessageSelector{Type: consumer.TAG, Expression: "*"}); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+	}
+	if err := pull.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start pull consumer: %w", err)
+	}
+	defer func() {
+		if shutdownErr := pull.Shutdown(); shutdownErr != nil {
+			logx.WithContext(ctx).Errorf("failed to shut down pull consumer: %v", shutdownErr)
+		}
+	}()
+
+	var out []*Message
+	for _, queue := range queues {
+		// Offset 0 is usually below the oldest message kept; the broker answers with the offset to
+		// start from instead.
+		for offset := int64(0); len(out) < limit; {
+			result, err := pull.PullFrom(ctx, queue, offset, min(deadLetterPullBatch, limit-len(out)))
+			if err != nil {
+				return nil, fmt.Errorf("failed to pull from %s: %w", topic, err)
+			}
+			if result.Status == primitive.PullFound {
+				for _, ext := range result.GetMessageExts() {
+					out = append(out, fromMessageExt(ext))
+				}
+			}
+			if result.NextBeginOffset <= offset {
+				break
+			}
+			offset = result.NextBeginOffset
+		}
+	}
+	return out, nil
+}
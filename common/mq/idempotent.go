@@ -0,0 +1,339 @@
+package mq
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/metric"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/redis"
+)
+
+// ErrDuplicateInFlight is returned for a delivery of a message that another delivery is still
+// processing. The broker redelivers it later, when the first delivery has completed the message
+// or given it up.
+var ErrDuplicateInFlight = errors.New("message is already being processed")
+
+// ErrClaimLost is returned by DedupeStore.Complete and Release when the claim no longer holds the
+// message: its lease expired and another delivery took the message over, or it was released.
+var ErrClaimLost = errors.New("message claim was lost")
+
+// duplicatesTotal counts dropped and deferred duplicate deliveries.
+var duplicatesTotal = metric.NewCounterVec(&metric.CounterVecOpts{
+	Namespace: "mq",
+	Subsystem: "consumer",
+	Name:      "duplicates_total",
+	Help:      "Duplicate message deliveries, by consumer group and state (completed: dropped, in_flight: retried).",
+	Labels:    []string{"group", "state"},
+})
+
+// DedupeState is the state of a message key in a DedupeStore.
+type DedupeState int
+
+const (
+	// DedupeClaimed means the caller claimed the key and must process the message.
+	DedupeClaimed DedupeState = iota
+	// DedupeInFlight means another delivery holds the key and is processing the message.
+	DedupeInFlight
+	// DedupeCompleted means the message was processed.
+	DedupeCompleted
+)
+
+// DedupeClaim is the outcome of DedupeStore.Claim.
+type DedupeClaim struct {
+	// Token identifies the claim when State is DedupeClaimed. Complete and Release only act while
+	// the claim of their token holds the message, so that a delivery whose lease has expired
+	// cannot complete or release the claim of the delivery that took the message over.
+	Token string
+	State DedupeState
+}
+
+// DedupeStore records which messages a consumer group has processed.
+type DedupeStore interface {
+	// Claim claims key for group for the lease, unless it is already claimed or completed.
+	Claim(ctx context.Context, group, key string, lease time.Duration) (DedupeClaim, error)
+	// Complete records that the message was processed, or returns ErrClaimLost when the claim of
+	// token no longer holds it.
+	Complete(ctx context.Context, group, key, token string) error
+	// Release gives up the claim of token after processing failed, so that a redelivery processes
+	// the message, or returns ErrClaimLost when the claim no longer holds it.
+	Release(ctx context.Context, group, key, token string) error
+}
+
+// DuplicateCounts are the duplicate deliveries an IdempotentHandler has seen.
+type DuplicateCounts struct {
+	Completed uint64 // Dropped because the message was already processed
+	InFlight  uint64 // Failed for redelivery because the message was being processed
+}
+
+// IdempotentHandler runs a handler at most once per message key and consumer group, so that
+// redeliveries and duplicate publications have their effect only once.
+type IdempotentHandler struct {
+	store     DedupeStore
+	handler   Handler
+	key       func(msg *Message) (string, error)
+	group     string
+	lease     time.Duration
+	completed atomic.Uint64
+	inFlight  atomic.Uint64
+}
+
+// IdempotentOption configures an IdempotentHandler.
+type IdempotentOption func(h *IdempotentHandler)
+
+// WithDedupeKey sets how the deduplication key of a message is derived (default: its message ID).
+// Use an ID the publisher assigns, such as an event ID, when a message can be published twice.
+func WithDedupeKey(key func(msg *Message) (string, error)) IdempotentOption {
+	return func(h *IdempotentHandler) {
+		h.key = key
+	}
+}
+
+// WithDedupeLease sets how long a claim lasts before another delivery may take it over
+// (default: 5m). It must exceed the longest processing time of a message.
+func WithDedupeLease(lease time.Duration) IdempotentOption {
+	return func(h *IdempotentHandler) {
+		h.lease = lease
+	}
+}
+
+// NewIdempotentHandler wraps handler for the consumer group with deduplication through store.
+func NewIdempotentHandler(
+	group string, store DedupeStore, handler Handler, opts ...IdempotentOption,
+) (*IdempotentHandler, error) {
+	if group == "" {
+		return nil, fmt.Errorf("consumer group cannot be empty")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("dedupe store cannot be nil")
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("handler cannot be nil")
+	}
+
+	h := &IdempotentHandler{
+		store:   store,
+		handler: handler,
+		key:     messageIDKey,
+		group:   group,
+		lease:   5 * time.Minute,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.lease <= 0 {
+		return nil, fmt.Errorf("dedupe lease must be positive")
+	}
+	return h, nil
+}
+
+// messageIDKey is the default deduplication key: the ID the broker assigned to the message.
+func messageIDKey(msg *Message) (string, error) {
+	if msg.MsgID == "" {
+		return "", fmt.Errorf("message has no ID")
+	}
+	return msg.MsgID, nil
+}
+
+// Handle implements Handler.
+//
+// The first delivery of a message claims its key and runs the handler; the key is completed when
+// the handler succeeds and released when it fails, so that the redelivery runs it again. A
+// duplicate of a completed message is acknowledged without running the handler. A duplicate that
+// arrives while the message is being processed fails with ErrDuplicateInFlight, so the broker
+// delivers it again instead of losing it in case the first delivery fails.
+func (h *IdempotentHandler) Handle(ctx context.Context, msg *Message) error {
+	logger := logx.WithContext(ctx)
+
+	key, err := h.key(msg)
+	if err != nil {
+		return fmt.Errorf("failed to get deduplication key of message %s: %w", msg.MsgID, err)
+	}
+
+	claim, err := h.store.Claim(ctx, h.group, key, h.lease)
+	if err != nil {
+		return fmt.Errorf("failed to claim message %s: %w", key, err)
+	}
+
+	switch claim.State {
+	case DedupeCompleted:
+		h.completed.Add(1)
+		duplicatesTotal.Inc(h.group, "completed")
+		logger.Infof("dropping duplicate of processed message: group=%s, key=%s", h.group, key)
+		return nil
+	case DedupeInFlight:
+		h.inFlight.Add(1)
+		duplicatesTotal.Inc(h.group, "in_flight")
+		logger.Infof("deferring duplicate of message in flight: group=%s, key=%s", h.group, key)
+		return ErrDuplicateInFlight
+	}
+
+	if err := h.handler(ctx, msg); err != nil {
+		if releaseErr := h.store.Release(ctx, h.group, key, claim.Token); releaseErr != nil {
+			// The claim expires with its lease; until then redeliveries are deferred.
+			logger.Errorf("failed to release message %s: %v", key, releaseErr)
+		}
+		return err
+	}
+
+	if err := h.store.Complete(ctx, h.group, key, claim.Token); err != nil {
+		// The message was processed, so it must not be redelivered for this. A duplicate arriving
+		// after the lease expires would be processed again.
+		logger.Errorf("failed to complete message %s: %v", key, err)
+	}
+	return nil
+}
+
+// Duplicates returns the duplicate deliveries seen so far.
+func (h *IdempotentHandler) Duplicates() DuplicateCounts {
+	return DuplicateCounts{Completed: h.completed.Load(), InFlight: h.inFlight.Load()}
+}
+
+// inFlightPrefix and completedMarker are the values RedisDedupeStore keeps under message keys: an
+// in-flight key holds the prefix followed by the token of its claim.
+const (
+	inFlightPrefix  = "in_flight:"
+	completedMarker = "completed"
+)
+
+// DedupeRedis defines the Redis operations RedisDedupeStore needs. *redis.Client implements it.
+type DedupeRedis interface {
+	ClaimKey(ctx context.Context, key, marker string, ttl time.Duration) (string, error)
+	SwapKey(ctx context.Context, key, marker, value string, ttl time.Duration) (bool, error)
+	ReleaseKey(ctx context.Context, key, marker string) (bool, error)
+}
+
+// RedisDedupeStore is a DedupeStore that keeps processed message keys in Redis for a retention
+// period, after which a duplicate would be processed again.
+type RedisDedupeStore struct {
+	rdb       DedupeRedis
+	keys      *redis.KeyNamingHelper
+	retention time.Duration
+}
+
+// NewRedisDedupeStore creates a RedisDedupeStore that remembers processed messages for retention.
+func NewRedisDedupeStore(rdb DedupeRedis, retention time.Duration) (*RedisDedupeStore, error) {
+	if rdb == nil {
+		return nil, fmt.Errorf("redis client cannot be nil")
+	}
+	if retention <= 0 {
+		return nil, fmt.Errorf("retention must be positive")
+	}
+	return &RedisDedupeStore{rdb: rdb, keys: redis.NewKeyNamingHelper(), retention: retention}, nil
+}
+
+// Claim implements DedupeStore.
+func (s *RedisDedupeStore) Claim(ctx context.Context, group, key string, lease time.Duration) (DedupeClaim, error) {
+	token, err := newClaimToken()
+	if err != nil {
+		return DedupeClaim{}, err
+	}
+	existing, err := s.rdb.ClaimKey(ctx, s.keys.ConsumedMessageKey(group, key), inFlightPrefix+token, lease)
+	if err != nil {
+		return DedupeClaim{}, err
+	}
+	switch existing {
+	case "":
+		return DedupeClaim{Token: token, State: DedupeClaimed}, nil
+	case completedMarker:
+		return DedupeClaim{State: DedupeCompleted}, nil
+	default:
+		return DedupeClaim{State: DedupeInFlight}, nil
+	}
+}
+
+// Complete implements DedupeStore.
+func (s *RedisDedupeStore) Complete(ctx context.Context, group, key, token string) error {
+	swapped, err := s.rdb.SwapKey(ctx, s.keys.ConsumedMessageKey(group, key), inFlightPrefix+token,
+		completedMarker, s.retention)
+	if err != nil {
+		return err
+	}
+	if !swapped {
+		return ErrClaimLost
+	}
+	return nil
+}
+
+// Release implements DedupeStore.
+func (s *RedisDedupeStore) Release(ctx context.Context, group, key, token string) error {
+	released, err := s.rdb.ReleaseKey(ctx, s.keys.ConsumedMessageKey(group, key), inFlightPrefix+token)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrClaimLost
+	}
+	return nil
+}
+
+// SQLDedupeStore is a DedupeStore backed by the mq_consumed_message table, for effects that must
+// be deduplicated for as long as the records are kept.
+type SQLDedupeStore struct {
+	store *database.ConsumedMessageStore
+}
+
+// NewSQLDedupeStore creates a SQLDedupeStore.
+func NewSQLDedupeStore(store *database.ConsumedMessageStore) *SQLDedupeStore {
+	return &SQLDedupeStore{store: store}
+}
+
+// Claim implements DedupeStore.
+func (s *SQLDedupeStore) Claim(ctx context.Context, group, key string, lease time.Duration) (DedupeClaim, error) {
+	token, err := newClaimToken()
+	if err != nil {
+		return DedupeClaim{}, err
+	}
+	claimed, status, err := s.store.Claim(ctx, group, key, token, lease)
+	if err != nil {
+		return DedupeClaim{}, err
+	}
+	switch {
+	case claimed:
+		return DedupeClaim{Token: token, State: DedupeClaimed}, nil
+	case status == database.ConsumedStatusCompleted:
+		return DedupeClaim{State: DedupeCompleted}, nil
+	default:
+		return DedupeClaim{State: DedupeInFlight}, nil
+	}
+}
+
+// Complete implements DedupeStore.
+func (s *SQLDedupeStore) Complete(ctx context.Context, group, key, token string) error {
+	completed, err := s.store.Complete(ctx, group, key, token)
+	if err != nil {
+		return err
+	}
+	if !completed {
+		return ErrClaimLost
+	}
+	return nil
+}
+
+// Release implements DedupeStore.
+func (s *SQLDedupeStore) Release(ctx context.Context, group, key, token string) error {
+	released, err := s.store.Release(ctx, group, key, token)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrClaimLost
+	}
+	return nil
+}
+
+// newClaimToken returns a random token identifying one claim of a message.
+func newClaimToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate claim token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,229 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDedupeRedis is an in-memory DedupeRedis that ignores expiration.
+type fakeDedupeRedis struct {
+	values map[string]string
+	mu     sync.Mutex
+}
+
+func newFakeDedupeRedis() *fakeDedupeRedis {
+	return &fakeDedupeRedis{values: make(map[string]string)}
+}
+
+func (f *fakeDedupeRedis) ClaimKey(_ context.Context, key, marker string, _ time.Duration) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if value, ok := f.values[key]; ok {
+		return value, nil
+	}
+	f.values[key] = marker
+	return "", nil
+}
+
+func (f *fakeDedupeRedis) SwapKey(_ context.Context, key, marker, value string, _ time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.values[key] != marker {
+		return false, nil
+	}
+	f.values[key] = value
+	return true, nil
+}
+
+func (f *fakeDedupeRedis) ReleaseKey(_ context.Context, key, marker string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.values[key] != marker {
+		return false, nil
+	}
+	delete(f.values, key)
+	return true, nil
+}
+
+// expire drops key, as Redis does when its lease expires.
+func (f *fakeDedupeRedis) expire(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.values, key)
+}
+
+func newTestDedupeStore(t *testing.T) (*RedisDedupeStore, *fakeDedupeRedis) {
+	t.Helper()
+	rdb := newFakeDedupeRedis()
+	store, err := NewRedisDedupeStore(rdb, time.Hour)
+	require.NoError(t, err)
+	return store, rdb
+}
+
+func TestIdempotentHandler_Handle(t *testing.T) {
+	store, rdb := newTestDedupeStore(t)
+	var calls int
+	failures := 1
+	h, err := NewIdempotentHandler("promotion", store, func(context.Context, *Message) error {
+		calls++
+		if failures > 0 {
+			failures--
+			return errors.New("redis unavailable")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	msg := NewMessage("orders", []byte("body"))
+	msg.MsgID = "m1"
+
+	assert.Error(t, h.Handle(context.Background(), msg))
+	assert.Empty(t, rdb.values, "a failed delivery releases its claim")
+
+	require.NoError(t, h.Handle(context.Background(), msg))
+	assert.Equal(t, completedMarker, rdb.values["mq:consumed:promotion:m1"])
+
+	require.NoError(t, h.Handle(context.Background(), msg), "a duplicate is acknowledged")
+	assert.Equal(t, 2, calls, "the duplicate does not run the handler")
+	assert.Equal(t, DuplicateCounts{Completed: 1}, h.Duplicates())
+
+	// Another consumer group processes the message independently.
+	other, err := NewIdempotentHandler("points", store, func(context.Context, *Message) error {
+		calls++
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, other.Handle(context.Background(), msg))
+	assert.Equal(t, 3, calls)
+}
+
+func TestIdempotentHandler_Handle_InFlight(t *testing.T) {
+	store, _ := newTestDedupeStore(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	h, err := NewIdempotentHandler("promotion", store, func(context.Context, *Message) error {
+		close(started)
+		<-release
+		return nil
+	})
+	require.NoError(t, err)
+
+	msg := NewMessage("orders", nil)
+	msg.MsgID = "m1"
+
+	done := make(chan error)
+	go func() {
+		done <- h.Handle(context.Background(), msg)
+	}()
+	<-started
+
+	assert.ErrorIs(t, h.Handle(context.Background(), msg), ErrDuplicateInFlight)
+	close(release)
+	assert.NoError(t, <-done)
+	assert.Equal(t, DuplicateCounts{InFlight: 1}, h.Duplicates())
+}
+
+func TestRedisDedupeStore_ClaimTakenOver(t *testing.T) {
+	store, rdb := newTestDedupeStore(t)
+	ctx := context.Background()
+	key := "mq:consumed:promotion:m1"
+
+	first, err := store.Claim(ctx, "promotion", "m1", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, DedupeClaimed, first.State)
+
+	// The lease of the first delivery expires and a redelivery claims the message.
+	rdb.expire(key)
+	second, err := store.Claim(ctx, "promotion", "m1", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, DedupeClaimed, second.State)
+	require.NotEqual(t, first.Token, second.Token)
+
+	assert.ErrorIs(t, store.Release(ctx, "promotion", "m1", first.Token), ErrClaimLost)
+	assert.ErrorIs(t, store.Complete(ctx, "promotion", "m1", first.Token), ErrClaimLost)
+	assert.Equal(t, inFlightPrefix+second.Token, rdb.values[key], "the second claim still holds the message")
+
+	duplicate, err := store.Claim(ctx, "promotion", "m1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, DedupeInFlight, duplicate.State)
+	assert.Empty(t, duplicate.Token)
+
+	require.NoError(t, store.Complete(ctx, "promotion", "m1", second.Token))
+	assert.Equal(t, completedMarker, rdb.values[key])
+	assert.ErrorIs(t, store.Release(ctx, "promotion", "m1", second.Token), ErrClaimLost,
+		"a completed message cannot be released")
+}
+
+func TestIdempotentHandler_Handle_Key(t *testing.T) {
+	store, _ := newTestDedupeStore(t)
+	h, err := NewIdempotentHandler("promotion", store, func(context.Context, *Message) error { return nil },
+		WithDedupeKey(func(msg *Message) (string, error) {
+			if len(msg.Body) == 0 {
+				return "", errors.New("no event")
+			}
+			return string(msg.Body), nil
+		}))
+	require.NoError(t, err)
+
+	// The same event published twice arrives with different message IDs.
+	first, second := NewMessage("orders", []byte("evt-1")), NewMessage("orders", []byte("evt-1"))
+	first.MsgID, second.MsgID = "m1", "m2"
+	require.NoError(t, h.Handle(context.Background(), first))
+	require.NoError(t, h.Handle(context.Background(), second))
+	assert.Equal(t, DuplicateCounts{Completed: 1}, h.Duplicates())
+
+	assert.Error(t, h.Handle(context.Background(), NewMessage("orders", nil)))
+}
+
+func TestIdempotentHandler_ThroughBroker(t *testing.T) {
+	broker := NewMemoryBroker(WithRetryDelay(time.Millisecond))
+	defer broker.Shutdown()
+
+	store, _ := newTestDedupeStore(t)
+	var calls atomic.Int32
+	h, err := NewIdempotentHandler("promotion", store, func(context.Context, *Message) error {
+		calls.Add(1)
+		return nil
+	}, WithDedupeKey(func(msg *Message) (string, error) { return string(msg.Body), nil }))
+	require.NoError(t, err)
+
+	consumer, err := broker.NewConsumer("promotion")
+	require.NoError(t, err)
+	require.NoError(t, consumer.Subscribe("orders", "*", h.Handle))
+	require.NoError(t, consumer.Start())
+
+	producer := broker.NewProducer()
+	for i := 0; i < 3; i++ {
+		_, err := producer.Send(context.Background(), NewMessage("orders", []byte("evt-1")))
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, broker.WaitIdle(ctx))
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Empty(t, broker.DeadLetters("promotion"))
+}
+
+func TestNewIdempotentHandler_Validation(t *testing.T) {
+	store, _ := newTestDedupeStore(t)
+	handler := func(context.Context, *Message) error { return nil }
+
+	_, err := NewIdempotentHandler("", store, handler)
+	assert.Error(t, err)
+	_, err = NewIdempotentHandler("g", nil, handler)
+	assert.Error(t, err)
+	_, err = NewIdempotentHandler("g", store, nil)
+	assert.Error(t, err)
+	_, err = NewIdempotentHandler("g", store, handler, WithDedupeLease(0))
+	assert.Error(t, err)
+
+	_, err = NewRedisDedupeStore(newFakeDedupeRedis(), 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,432 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Defaults of the in-memory broker, short so that tests resolve transactions and retries quickly.
+const (
+	defaultMemoryCheckBackDelay    = 10 * time.Millisecond
+	defaultMemoryRetryDelay        = 10 * time.Millisecond
+	defaultMemoryMaxCheckTimes     = 15
+	defaultMemoryMaxReconsumeTimes = 16
+)
+
+// MemoryOption configures a MemoryBroker.
+type MemoryOption func(*MemoryBroker)
+
+// WithCheckBackDelay sets how long a transaction stays unknown before the broker checks it back.
+func WithCheckBackDelay(d time.Duration) MemoryOption {
+	return func(b *MemoryBroker) { b.checkBackDelay = d }
+}
+
+// WithMaxCheckTimes sets how often an unknown transaction is checked back before it is discarded.
+func WithMaxCheckTimes(n int) MemoryOption {
+	return func(b *MemoryBroker) { b.maxCheckTimes = n }
+}
+
+// WithRetryDelay sets the delay before a failed message is redelivered.
+func WithRetryDelay(d time.Duration) MemoryOption {
+	return func(b *MemoryBroker) { b.retryDelay = d }
+}
+
+// WithMaxReconsumeTimes sets how often a failed message is redelivered before it is dead-lettered.
+func WithMaxReconsumeTimes(n int32) MemoryOption {
+	return func(b *MemoryBroker) { b.maxReconsumeTimes = n }
+}
+
+// MemoryBroker is an in-process broker for tests. It simulates the RocketMQ behavior application
+// code relies on:
+//   - transactional messages stay invisible as half messages until the local transaction commits,
+//     and are dropped on rollback
+//   - an unknown transaction is checked back after a delay, repeatedly, until it is resolved or
+//     the check limit is reached
+//   - every consumer group receives each matching message; a handler error redelivers it after a
+//     delay with ReconsumeTimes incremented, and past the limit it moves to DLQTopic(group)
+//   - the trace context, request ID and user properties of the sender reach handlers
+//
+// Delivery is asynchronous, as with a real broker; WaitIdle waits until nothing is in flight.
+type MemoryBroker struct {
+	topics            map[string][]*Message
+	groups            map[string]*MemoryConsumer
+	half              map[string]*Message
+	idle              chan struct{}
+	pending           int
+	nextID            int64
+	checkBackDelay    time.Duration
+	retryDelay        time.Duration
+	maxCheckTimes     int
+	mu                sync.Mutex
+	maxReconsumeTimes int32
+	closed            bool
+}
+
+// NewMemoryBroker creates an in-memory broker.
+func NewMemoryBroker(opts ...MemoryOption) *MemoryBroker {
+	b := &MemoryBroker{
+		topics:            make(map[string][]*Message),
+		groups:            make(map[string]*MemoryConsumer),
+		half:              make(map[string]*Message),
+		checkBackDelay:    defaultMemoryCheckBackDelay,
+		retryDelay:        defaultMemoryRetryDelay,
+		maxCheckTimes:     defaultMemoryMaxCheckTimes,
+		maxReconsumeTimes: defaultMemoryMaxReconsumeTimes,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// NewProducer returns a producer that sends to the broker.
+func (b *MemoryBroker) NewProducer() *MemoryProducer {
+	return &MemoryProducer{broker: b}
+}
+
+// NewTransactionProducer returns a transaction producer that sends to the broker.
+func (b *MemoryBroker) NewTransactionProducer(
+	executor LocalTransactionExecutor,
+	checkBack CheckBackExecutor,
+) (*MemoryTransactionProducer, error) {
+	if executor == nil {
+		return nil, fmt.Errorf("local transaction executor cannot be nil")
+	}
+	if checkBack == nil {
+		return nil, fmt.Errorf("check-back executor cannot be nil")
+	}
+	return &MemoryTransactionProducer{broker: b, executor: executor, checkBack: checkBack}, nil
+}
+
+// NewConsumer returns a consumer for group. Each group may have one consumer at a time.
+func (b *MemoryBroker) NewConsumer(group string) (*MemoryConsumer, error) {
+	if group == "" {
+		return nil, fmt.Errorf("group is required")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.groups[group]; ok {
+		return nil, fmt.Errorf("consumer group %s already exists", group)
+	}
+	c := &MemoryConsumer{broker: b, group: group}
+	b.groups[group] = c
+	return c, nil
+}
+
+// Messages returns copies of the messages stored on topic, in the order they were committed.
+// Half messages are not visible until committed.
+func (b *MemoryBroker) Messages(topic string) []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]*Message, 0, len(b.topics[topic]))
+	for _, msg := range b.topics[topic] {
+		out = append(out, msg.clone())
+	}
+	return out
+}
+
+// DeadLetters returns copies of the messages group gave up on.
+func (b *MemoryBroker) DeadLetters(group string) []*Message {
+	return b.Messages(DLQTopic(group))
+}
+
+// HalfMessages returns the number of transactional messages awaiting check-back.
+func (b *MemoryBroker) HalfMessages() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.half)
+}
+
+// WaitIdle waits until no delivery, redelivery or check-back is in flight or scheduled.
+func (b *MemoryBroker) WaitIdle(ctx context.Context) error {
+	b.mu.Lock()
+	if b.pending == 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	idle, pending := b.idle, b.pending
+	b.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("broker still has %d pending operations: %w", pending, ctx.Err())
+	}
+}
+
+// Shutdown stops the broker. Scheduled deliveries and check-backs are abandoned.
+func (b *MemoryBroker) Shutdown() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+}
+
+// publishLocked stores msg on its topic and schedules delivery to every subscribed group.
+func (b *MemoryBroker) publishLocked(msg *Message) {
+	b.topics[msg.Topic] = append(b.topics[msg.Topic], msg)
+	for _, c := range b.groups {
+		if !c.running {
+			continue
+		}
+		for _, sub := range c.subs {
+			if sub.topic == msg.Topic && matchTag(sub.tagExpr, msg.Tag) {
+				delivery := msg.clone()
+				b.scheduleLocked(0, func() { b.deliver(c, sub.handler, delivery) })
+			}
+		}
+	}
+}
+
+// deliver runs handler for msg and schedules a redelivery or dead-letters the message on failure.
+func (b *MemoryBroker) deliver(c *MemoryConsumer, handler Handler, msg *Message) {
+	defer b.done()
+
+	b.mu.Lock()
+	active := !b.closed && c.running
+	b.mu.Unlock()
+	if !active {
+		return
+	}
+
+	ctx := ExtractContext(context.Background(), msg)
+	err := handler(ctx, msg.clone())
+	if err == nil {
+		return
+	}
+	logx.WithContext(ctx).Errorf("failed to consume message %s in group %s: %v", msg.MsgID, c.group, err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	if msg.ReconsumeTimes >= b.maxReconsumeTimes {
+		dead := msg.clone()
+		dead.Topic = DLQTopic(c.group)
+		dead.WithProperty(PropertyOriginTopic, msg.Topic)
+		b.publishLocked(dead)
+		return
+	}
+	retry := msg.clone()
+	retry.ReconsumeTimes++
+	b.scheduleLocked(b.retryDelay, func() { b.deliver(c, handler, retry) })
+}
+
+// checkBack resolves an unknown transaction with checkBack, scheduling another check while it stays unknown.
+func (b *MemoryBroker) checkBack(checkBack CheckBackExecutor, msg *Message, checks int) {
+	defer b.done()
+
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return
+	}
+
+	ctx := ExtractContext(context.Background(), msg)
+	state, err := checkBack(ctx, msg.clone())
+	if err != nil {
+		logx.WithContext(ctx).Errorf("check-back execution failed: %v", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch resolveState(state, err) {
+	case CommitMessageState:
+		delete(b.half, msg.MsgID)
+		b.publishLocked(msg)
+	case RollbackMessageState:
+		delete(b.half, msg.MsgID)
+	default:
+		if checks+1 >= b.maxCheckTimes {
+			logx.WithContext(ctx).Errorf("discarding half message %s after %d check-backs", msg.MsgID, checks+1)
+			delete(b.half, msg.MsgID)
+			return
+		}
+		b.scheduleLocked(b.checkBackDelay, func() { b.checkBack(checkBack, msg, checks+1) })
+	}
+}
+
+// scheduleLocked runs fn after delay, counting it as pending until it calls done.
+func (b *MemoryBroker) scheduleLocked(delay time.Duration, fn func()) {
+	if b.pending == 0 {
+		b.idle = make(chan struct{})
+	}
+	b.pending++
+	if delay <= 0 {
+		go fn()
+		return
+	}
+	time.AfterFunc(delay, fn)
+}
+
+// done marks a scheduled operation finished.
+func (b *MemoryBroker) done() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending--
+	if b.pending == 0 {
+		close(b.idle)
+	}
+}
+
+// newMsgIDLocked returns a broker-unique message ID.
+func (b *MemoryBroker) newMsgIDLocked() string {
+	b.nextID++
+	return fmt.Sprintf("MEM%016X", b.nextID)
+}
+
+// prepareLocked injects the context of ctx into msg, assigns its ID and returns the copy the broker keeps.
+func (b *MemoryBroker) prepareLocked(ctx context.Context, msg *Message) (*Message, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+	if msg.Topic == "" {
+		return nil, fmt.Errorf("message topic is required")
+	}
+	if b.closed {
+		return nil, fmt.Errorf("broker is shut down")
+	}
+	InjectContext(ctx, msg)
+	msg.MsgID = b.newMsgIDLocked()
+	return msg.clone(), nil
+}
+
+// MemoryProducer sends messages to a MemoryBroker. It implements Producer.
+type MemoryProducer struct {
+	broker *MemoryBroker
+}
+
+// Send stores msg and schedules its delivery.
+func (p *MemoryProducer) Send(ctx context.Context, msg *Message) (*SendResult, error) {
+	p.broker.mu.Lock()
+	defer p.broker.mu.Unlock()
+
+	stored, err := p.broker.prepareLocked(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	p.broker.publishLocked(stored)
+	return &SendResult{MsgID: stored.MsgID}, nil
+}
+
+// Shutdown is a no-op; the broker owns all state.
+func (p *MemoryProducer) Shutdown() error {
+	return nil
+}
+
+// MemoryTransactionProducer sends transactional messages to a MemoryBroker.
+// It implements TransactionProducer.
+type MemoryTransactionProducer struct {
+	broker    *MemoryBroker
+	executor  LocalTransactionExecutor
+	checkBack CheckBackExecutor
+}
+
+// SendMessageInTransaction stores msg as a half message and runs the local transaction executor,
+// with the context rebuilt from the message as RocketMQ does. A committed message is delivered,
+// a rolled back one dropped and an unknown one checked back later.
+func (p *MemoryTransactionProducer) SendMessageInTransaction(
+	ctx context.Context,
+	msg *Message,
+) (*TransactionSendResult, error) {
+	b := p.broker
+	b.mu.Lock()
+	stored, err := b.prepareLocked(ctx, msg)
+	if err == nil {
+		b.half[stored.MsgID] = stored
+	}
+	b.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	execCtx := ExtractContext(context.Background(), stored)
+	state, localErr := p.executor(execCtx, stored.clone())
+	if localErr != nil {
+		logx.WithContext(execCtx).Errorf("local transaction execution failed: %v", localErr)
+	}
+	state = resolveState(state, localErr)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch state {
+	case CommitMessageState:
+		delete(b.half, stored.MsgID)
+		b.publishLocked(stored)
+	case RollbackMessageState:
+		delete(b.half, stored.MsgID)
+	default:
+		b.scheduleLocked(b.checkBackDelay, func() { b.checkBack(p.checkBack, stored, 0) })
+	}
+
+	return &TransactionSendResult{MsgID: stored.MsgID, LocalErr: localErr, State: state}, nil
+}
+
+// Shutdown is a no-op; the broker owns all state.
+func (p *MemoryTransactionProducer) Shutdown() error {
+	return nil
+}
+
+// memorySubscription is a handler registered with a MemoryConsumer.
+type memorySubscription struct {
+	handler Handler
+	topic   string
+	tagExpr string
+}
+
+// MemoryConsumer receives messages from a MemoryBroker for one consumer group.
+// It implements Consumer.
+type MemoryConsumer struct {
+	broker  *MemoryBroker
+	group   string
+	subs    []memorySubscription
+	running bool
+}
+
+// Subscribe registers handler for messages on topic whose tag matches tagExpr.
+func (c *MemoryConsumer) Subscribe(topic, tagExpr string, handler Handler) error {
+	if topic == "" {
+		return fmt.Errorf("topic is required")
+	}
+	if handler == nil {
+		return fmt.Errorf("handler cannot be nil")
+	}
+
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	if c.running {
+		return fmt.Errorf("cannot subscribe after the consumer has started")
+	}
+	c.subs = append(c.subs, memorySubscription{handler: handler, topic: topic, tagExpr: tagExpr})
+	return nil
+}
+
+// Start starts delivering messages sent from now on, like a new RocketMQ group consuming from the
+// last offset.
+func (c *MemoryConsumer) Start() error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	if c.broker.groups[c.group] != c {
+		return fmt.Errorf("consumer group %s has been shut down", c.group)
+	}
+	c.running = true
+	return nil
+}
+
+// Shutdown stops delivery to the consumer and releases its group.
+func (c *MemoryConsumer) Shutdown() error {
+	c.broker.mu.Lock()
+	defer c.broker.mu.Unlock()
+	c.running = false
+	if c.broker.groups[c.group] == c {
+		delete(c.broker.groups, c.group)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder collects the messages a handler receives.
+type recorder struct {
+	msgs       []*Message
+	requestIDs []string
+	mu         sync.Mutex
+}
+
+func (r *recorder) handle(ctx context.Context, msg *Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, msg)
+	r.requestIDs = append(r.requestIDs, RequestIDFromContext(ctx))
+	return nil
+}
+
+func (r *recorder) received() []*Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Message(nil), r.msgs...)
+}
+
+func waitIdle(t *testing.T, broker *MemoryBroker) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, broker.WaitIdle(ctx))
+}
+
+func startConsumer(t *testing.T, broker *MemoryBroker, group, topic, tagExpr string, handler Handler) {
+	t.Helper()
+	c, err := broker.NewConsumer(group)
+	require.NoError(t, err)
+	require.NoError(t, c.Subscribe(topic, tagExpr, handler))
+	require.NoError(t, c.Start())
+	t.Cleanup(func() { _ = c.Shutdown() })
+}
+
+func TestMemoryBroker_Send(t *testing.T) {
+	broker := NewMemoryBroker()
+	placed, all := &recorder{}, &recorder{}
+	startConsumer(t, broker, "placed", "orders", "ORDER_PLACED", placed.handle)
+	startConsumer(t, broker, "all", "orders", "*", all.handle)
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	producer := broker.NewProducer()
+	result, err := producer.Send(ctx, NewMessage("orders", []byte("1")).WithTag("ORDER_PLACED"))
+	require.NoError(t, err)
+	_, err = producer.Send(ctx, NewMessage("orders", []byte("2")).WithTag("ORDER_PAID"))
+	require.NoError(t, err)
+	waitIdle(t, broker)
+
+	require.Len(t, placed.received(), 1)
+	assert.Equal(t, result.MsgID, placed.received()[0].MsgID)
+	assert.Equal(t, []string{"req-1"}, placed.requestIDs)
+	assert.Len(t, all.received(), 2, "every group receives the messages it subscribes to")
+	assert.Len(t, broker.Messages("orders"), 2)
+
+	_, err = producer.Send(ctx, NewMessage("", nil))
+	assert.Error(t, err)
+}
+
+func TestMemoryBroker_NewConsumer(t *testing.T) {
+	broker := NewMemoryBroker()
+	c, err := broker.NewConsumer("group")
+	require.NoError(t, err)
+
+	_, err = broker.NewConsumer("group")
+	assert.Error(t, err, "a group has one consumer at a time")
+
+	require.NoError(t, c.Start())
+	assert.Error(t, c.Subscribe("topic", "*", (&recorder{}).handle), "subscriptions are fixed once started")
+
+	require.NoError(t, c.Shutdown())
+	_, err = broker.NewConsumer("group")
+	assert.NoError(t, err)
+}
+
+func TestMemoryBroker_Transaction(t *testing.T) {
+	errInsert := errors.New("duplicate entry")
+
+	tests := []struct {
+		localErr      error
+		name          string
+		state         LocalTransactionState
+		checkState    LocalTransactionState
+		wantState     LocalTransactionState
+		wantDelivered bool
+	}{
+		{name: "commit", state: CommitMessageState, wantState: CommitMessageState, wantDelivered: true},
+		{name: "rollback", state: RollbackMessageState, wantState: RollbackMessageState},
+		{name: "error rolls back", state: CommitMessageState, localErr: errInsert, wantState: RollbackMessageState},
+		{
+			name: "unknown committed by check-back", state: UnknownState, checkState: CommitMessageState,
+			wantState: UnknownState, wantDelivered: true,
+		},
+		{
+			name: "unknown rolled back by check-back", state: UnknownState, checkState: RollbackMessageState,
+			wantState: UnknownState,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := NewMemoryBroker(WithCheckBackDelay(time.Millisecond))
+			received := &recorder{}
+			startConsumer(t, broker, "group", "orders", "*", received.handle)
+
+			producer, err := broker.NewTransactionProducer(
+				func(_ context.Context, _ *Message) (LocalTransactionState, error) { return tt.state, tt.localErr },
+				func(_ context.Context, _ *Message) (LocalTransactionState, error) { return tt.checkState, nil },
+			)
+			require.NoError(t, err)
+
+			result, err := producer.SendMessageInTransaction(context.Background(), NewMessage("orders", []byte("1")))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantState, result.State)
+			assert.Equal(t, tt.localErr, result.LocalErr)
+			if tt.state != UnknownState {
+				assert.Zero(t, broker.HalfMessages())
+			}
+
+			waitIdle(t, broker)
+			assert.Zero(t, broker.HalfMessages())
+			if tt.wantDelivered {
+				require.Len(t, received.received(), 1)
+				assert.Equal(t, result.MsgID, received.received()[0].MsgID)
+			} else {
+				assert.Empty(t, received.received())
+				assert.Empty(t, broker.Messages("orders"), "the half message is never visible")
+			}
+		})
+	}
+}
+
+func TestMemoryBroker_CheckBackUntilResolved(t *testing.T) {
+	broker := NewMemoryBroker(WithCheckBackDelay(time.Millisecond), WithMaxCheckTimes(3))
+
+	var mu sync.Mutex
+	checks := 0
+	producer, err := broker.NewTransactionProducer(
+		func(_ context.Context, _ *Message) (LocalTransactionState, error) {
+			return UnknownState, context.DeadlineExceeded
+		},
+		func(ctx context.Context, _ *Message) (LocalTransactionState, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			checks++
+			assert.Equal(t, "req-1", RequestIDFromContext(ctx))
+			return UnknownState, errors.New("connection refused")
+		},
+	)
+	require.NoError(t, err)
+
+	result, err := producer.SendMessageInTransaction(
+		WithRequestID(context.Background(), "req-1"), NewMessage("orders", []byte("1")))
+	require.NoError(t, err)
+	assert.Equal(t, UnknownState, result.State)
+	assert.ErrorIs(t, result.LocalErr, context.DeadlineExceeded)
+	assert.Equal(t, 1, broker.HalfMessages())
+
+	waitIdle(t, broker)
+	assert.Equal(t, 3, checks, "an unresolved transaction is checked back up to the limit")
+	assert.Zero(t, broker.HalfMessages(), "and then discarded")
+	assert.Empty(t, broker.Messages("orders"))
+}
+
+func TestMemoryBroker_Redelivery(t *testing.T) {
+	broker := NewMemoryBroker(WithRetryDelay(time.Millisecond))
+
+	var mu sync.Mutex
+	var attempts []int32
+	startConsumer(t, broker, "group", "orders", "*", func(_ context.Context, msg *Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts = append(attempts, msg.ReconsumeTimes)
+		if len(attempts) < 3 {
+			return errors.New("stock service unavailable")
+		}
+		return nil
+	})
+
+	_, err := broker.NewProducer().Send(context.Background(), NewMessage("orders", []byte("1")))
+	require.NoError(t, err)
+	waitIdle(t, broker)
+
+	assert.Equal(t, []int32{0, 1, 2}, attempts)
+	assert.Empty(t, broker.DeadLetters("group"))
+}
+
+func TestMemoryBroker_DeadLetter(t *testing.T) {
+	broker := NewMemoryBroker(WithRetryDelay(time.Millisecond), WithMaxReconsumeTimes(2))
+
+	var mu sync.Mutex
+	calls := 0
+	startConsumer(t, broker, "group", "orders", "*", func(_ context.Context, _ *Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return errors.New("malformed order")
+	})
+	dlq := &recorder{}
+	startConsumer(t, broker, "dlq-monitor", DLQTopic("group"), "*", dlq.handle)
+
+	result, err := broker.NewProducer().Send(context.Background(),
+		NewMessage("orders", []byte("1")).WithTag("ORDER_PLACED"))
+	require.NoError(t, err)
+	waitIdle(t, broker)
+
+	assert.Equal(t, 3, calls, "the first delivery and two redeliveries")
+	dead := broker.DeadLetters("group")
+	require.Len(t, dead, 1)
+	assert.Equal(t, result.MsgID, dead[0].MsgID)
+	assert.Equal(t, "orders", dead[0].Property(PropertyOriginTopic))
+	assert.Equal(t, []byte("1"), dead[0].Body)
+	assert.Len(t, dlq.received(), 1, "dead letters can be consumed from the DLQ topic")
+}
+
+func TestMemoryBroker_Shutdown(t *testing.T) {
+	broker := NewMemoryBroker()
+	broker.Shutdown()
+
+	_, err := broker.NewProducer().Send(context.Background(), NewMessage("orders", nil))
+	assert.Error(t, err)
+}
+
+func TestMatchTag(t *testing.T) {
+	assert.True(t, matchTag("*", "A"))
+	assert.True(t, matchTag("", "A"))
+	assert.True(t, matchTag("A || B", "B"))
+	assert.False(t, matchTag("A||B", "C"))
+}
@@ -0,0 +1,59 @@
+package mq
+
+// Message is a broker-independent message. Producers set the topic, tag, keys, body and
+// properties; consumers also see the ID the broker assigned and how often delivery failed.
+type Message struct {
+	Properties map[string]string
+	Topic      string
+	Tag        string
+	// MsgID is assigned by the broker when the message is sent.
+	MsgID string
+	// Keys index the message for lookup in the broker, e.g. "order_42".
+	Keys []string
+	Body []byte
+	// ReconsumeTimes counts previous failed deliveries to the consuming group.
+	ReconsumeTimes int32
+}
+
+// NewMessage creates a message for topic.
+func NewMessage(topic string, body []byte) *Message {
+	return &Message{Topic: topic, Body: body}
+}
+
+// WithTag sets the tag consumers filter on.
+func (m *Message) WithTag(tag string) *Message {
+	m.Tag = tag
+	return m
+}
+
+// WithKeys sets the message keys.
+func (m *Message) WithKeys(keys ...string) *Message {
+	m.Keys = keys
+	return m
+}
+
+// WithProperty sets a property.
+func (m *Message) WithProperty(key, value string) *Message {
+	if m.Properties == nil {
+		m.Properties = make(map[string]string)
+	}
+	m.Properties[key] = value
+	return m
+}
+
+// Property returns the property stored under key, or "" if it is not set.
+func (m *Message) Property(key string) string {
+	return m.Properties[key]
+}
+
+// clone returns a deep copy of m, so that brokers never share state with senders or handlers.
+func (m *Message) clone() *Message {
+	out := *m
+	out.Keys = append([]string(nil), m.Keys...)
+	out.Body = append([]byte(nil), m.Body...)
+	out.Properties = make(map[string]string, len(m.Properties))
+	for k, v := range m.Properties {
+		out.Properties[k] = v
+	}
+	return &out
+}
@@ -0,0 +1,114 @@
+// Package mq defines broker-independent messaging interfaces with a RocketMQ implementation
+// for production and an in-memory broker for tests.
+package mq
+
+import (
+	"context"
+	"strings"
+)
+
+// SendResult is the result of sending a message.
+type SendResult struct {
+	// MsgID is the ID the broker assigned to the message.
+	MsgID string
+}
+
+// TransactionSendResult is the result of sending a transactional message.
+type TransactionSendResult struct {
+	// LocalErr is the error returned by the local transaction executor, if any.
+	LocalErr error
+	// MsgID is the ID the broker assigned to the half message.
+	MsgID string
+	// State is the local transaction outcome reported to the broker.
+	// UnknownState means the broker will decide through check-back.
+	State LocalTransactionState
+}
+
+// LocalTransactionState represents the result of local transaction execution.
+type LocalTransactionState int
+
+const (
+	// CommitMessageState indicates the local transaction succeeded and the message should be committed.
+	CommitMessageState LocalTransactionState = iota
+	// RollbackMessageState indicates the local transaction failed and the message should be rolled back.
+	RollbackMessageState
+	// UnknownState indicates the local transaction state is unknown and needs check-back.
+	UnknownState
+)
+
+// LocalTransactionExecutor executes a local transaction and returns the state.
+// This function is called by the broker client after sending a half message.
+type LocalTransactionExecutor func(ctx context.Context, msg *Message) (LocalTransactionState, error)
+
+// CheckBackExecutor checks the status of a local transaction.
+// This function is called by the broker when the transaction state is unknown.
+// It should be stateless and efficient (query database, not perform heavy operations).
+type CheckBackExecutor func(ctx context.Context, msg *Message) (LocalTransactionState, error)
+
+// Handler processes a delivered message. ctx carries the trace context, request ID and user
+// properties of the request that produced the message (see ExtractContext).
+// Returning an error redelivers the message later; once the broker's retry limit is reached the
+// message moves to the dead-letter topic of the consumer group.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Producer sends messages.
+type Producer interface {
+	// Send sends msg and returns once the broker has stored it.
+	Send(ctx context.Context, msg *Message) (*SendResult, error)
+	Shutdown() error
+}
+
+// TransactionProducer sends messages whose delivery depends on a local transaction.
+type TransactionProducer interface {
+	// SendMessageInTransaction sends msg as a half message, runs the local transaction executor
+	// and returns its outcome. The message is delivered only if the transaction commits.
+	SendMessageInTransaction(ctx context.Context, msg *Message) (*TransactionSendResult, error)
+	Shutdown() error
+}
+
+// Consumer delivers messages of subscribed topics to handlers.
+type Consumer interface {
+	// Subscribe registers handler for messages on topic whose tag matches tagExpr,
+	// either "*" or tags separated by "||". It must be called before Start.
+	Subscribe(topic, tagExpr string, handler Handler) error
+	Start() error
+	Shutdown() error
+}
+
+// PropertyOriginTopic holds the topic a dead-lettered message was sent to.
+// RocketMQ sets it when a consumer first sends the message back for retry.
+const PropertyOriginTopic = "RETRY_TOPIC"
+
+// DLQTopic returns the dead-letter topic of a consumer group, named as RocketMQ names it.
+func DLQTopic(group string) string {
+	return "%DLQ%" + group
+}
+
+// resolveState returns the state to report to the broker for the result of a local transaction or
+// check-back. An error rolls the message back unless the executor reports UnknownState, in which
+// case the broker resolves the transaction through check-back.
+func resolveState(state LocalTransactionState, err error) LocalTransactionState {
+	if err != nil && state != UnknownState {
+		return RollbackMessageState
+	}
+	switch state {
+	case CommitMessageState, RollbackMessageState, UnknownState:
+		return state
+	default:
+		return RollbackMessageState
+	}
+}
+
+// matchTag reports whether tag matches a subscription expression.
+func matchTag(tagExpr, tag string) bool {
+	tagExpr = strings.TrimSpace(tagExpr)
+	if tagExpr == "" || tagExpr == "*" {
+		return true
+	}
+	for _, t := range strings.Split(tagExpr, "||") {
+		if strings.TrimSpace(t) == tag {
+			return true
+		}
+	}
+	return false
+}
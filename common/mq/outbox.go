@@ -0,0 +1,326 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+// OutboxConfig configures the outbox relay.
+type OutboxConfig struct {
+	// PollInterval between polls of an empty outbox, in milliseconds (default: 500)
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	PollInterval int `json:"pollInterval,optional" yaml:"pollInterval"`
+	// BatchSize is the number of events published per poll (default: 100)
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	BatchSize int `json:"batchSize,optional" yaml:"batchSize"`
+	// LeaseTimeout is how long a claim holds its events, in milliseconds (default: 30000). A relay
+	// that has not recorded its results by then loses them, and the events are published again.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	LeaseTimeout int `json:"leaseTimeout,optional" yaml:"leaseTimeout"`
+	// RetryInterval is the delay before retrying a failed event, in milliseconds, doubled after
+	// each further failure up to 5 minutes (default: 1000)
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	RetryInterval int `json:"retryInterval,optional" yaml:"retryInterval"`
+	// MaxAttempts is the number of attempts after which a failing event is marked failed and no
+	// longer blocks its aggregate (default: 16)
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	MaxAttempts int `json:"maxAttempts,optional" yaml:"maxAttempts"`
+	// Retention is how long published events are kept before they are purged, in hours (default: 168)
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Retention int `json:"retention,optional" yaml:"retention"`
+}
+
+// Outbox relay timing that is not configurable.
+const (
+	// maxOutboxRetryInterval caps the retry delay of a failing event.
+	maxOutboxRetryInterval = 5 * time.Minute
+	// outboxPurgeInterval is the interval between purges of published events.
+	outboxPurgeInterval = time.Hour
+)
+
+// getPollInterval returns the poll interval, defaulting to 500ms.
+func (c OutboxConfig) getPollInterval() time.Duration {
+	if c.PollInterval <= 0 {
+		return 500 * time.Millisecond
+	}
+	return time.Duration(c.PollInterval) * time.Millisecond
+}
+
+// getBatchSize returns the batch size, defaulting to 100.
+func (c OutboxConfig) getBatchSize() int {
+	if c.BatchSize <= 0 {
+		return 100
+	}
+	return c.BatchSize
+}
+
+// getLeaseTimeout returns the lease timeout, defaulting to 30s.
+func (c OutboxConfig) getLeaseTimeout() time.Duration {
+	if c.LeaseTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.LeaseTimeout) * time.Millisecond
+}
+
+// getRetryDelay returns the delay before the attempt after attempts failed ones: the retry
+// interval, defaulting to 1s, doubled after each further failure up to maxOutboxRetryInterval.
+func (c OutboxConfig) getRetryDelay(attempts int32) time.Duration {
+	delay := time.Second
+	if c.RetryInterval > 0 {
+		delay = time.Duration(c.RetryInterval) * time.Millisecond
+	}
+	for i := int32(1); i < attempts && delay < maxOutboxRetryInterval; i++ {
+		delay *= 2
+	}
+	if delay > maxOutboxRetryInterval {
+		return maxOutboxRetryInterval
+	}
+	return delay
+}
+
+// getMaxAttempts returns the maximum number of attempts, defaulting to 16.
+func (c OutboxConfig) getMaxAttempts() int32 {
+	if c.MaxAttempts <= 0 {
+		return 16
+	}
+	return int32(c.MaxAttempts)
+}
+
+// getRetention returns the retention of published events, defaulting to 7 days.
+func (c OutboxConfig) getRetention() time.Duration {
+	if c.Retention <= 0 {
+		return 7 * 24 * time.Hour
+	}
+	return time.Duration(c.Retention) * time.Hour
+}
+
+// OutboxStore holds outbox events for the relay. *database.OutboxStore implements it.
+type OutboxStore interface {
+	// ClaimPending leases up to limit pending events to owner, oldest first, skipping the events
+	// that follow an unavailable event of their aggregate, and returns them.
+	ClaimPending(ctx context.Context, owner string, limit int, lease time.Duration) ([]*database.OutboxEvent, error)
+	// RecordResults records the results of publishing events claimed by owner, while the claim
+	// still holds them, and releases the events without a result.
+	RecordResults(ctx context.Context, owner string, events []*database.OutboxEvent, results []database.OutboxResult) error
+	// PurgePublished deletes up to limit events published more than retention ago and returns how
+	// many it deleted.
+	PurgePublished(ctx context.Context, retention time.Duration, limit int) (int, error)
+}
+
+// NewOutboxEvent converts msg into an outbox event of an aggregate, with the trace context,
+// request ID and user properties of ctx (see InjectContext). Write it with
+// database.InsertOutboxEvent in the transaction that changes the aggregate.
+func NewOutboxEvent(ctx context.Context, aggregateType, aggregateID string, msg *Message) (*database.OutboxEvent, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+	if msg.Topic == "" {
+		return nil, fmt.Errorf("message topic is required")
+	}
+	InjectContext(ctx, msg)
+
+	props, err := json.Marshal(msg.Properties)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message properties: %w", err)
+	}
+
+	return &database.OutboxEvent{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Topic:         msg.Topic,
+		Tag:           msg.Tag,
+		Keys:          strings.Join(msg.Keys, " "),
+		Properties:    string(props),
+		Payload:       msg.Body,
+		Status:        database.OutboxStatusPending,
+	}, nil
+}
+
+// outboxMessage converts an outbox event back into the message it was created from.
+func outboxMessage(event *database.OutboxEvent) (*Message, error) {
+	msg := NewMessage(event.Topic, event.Payload).WithTag(event.Tag).WithKeys(strings.Fields(event.Keys)...)
+	if event.Properties != "" {
+		if err := json.Unmarshal([]byte(event.Properties), &msg.Properties); err != nil {
+			return nil, fmt.Errorf("failed to parse properties of outbox event %d: %w", event.ID, err)
+		}
+	}
+	return msg, nil
+}
+
+// OutboxRelay publishes outbox events with at-least-once delivery.
+//
+// Events are claimed with a lease and published without holding database locks, so that a slow
+// broker does not block the writers of the outbox. Events are published in id order within each
+// aggregate: when an event fails, later events of the same aggregate wait until it is retried,
+// while other aggregates proceed. An event that has failed MaxAttempts times is marked failed and
+// no longer blocks its aggregate. An event whose result could not be recorded within its lease is
+// published again, so consumers must tolerate duplicates.
+//
+// Published events are purged once past their retention.
+type OutboxRelay struct {
+	store    OutboxStore
+	producer Producer
+	cancel   context.CancelFunc
+	done     chan struct{}
+	config   OutboxConfig
+	stopOnce sync.Once
+}
+
+// NewOutboxRelay creates a relay that publishes events from store through producer.
+func NewOutboxRelay(store OutboxStore, producer Producer, cfg OutboxConfig) (*OutboxRelay, error) {
+	if store == nil {
+		return nil, fmt.Errorf("outbox store cannot be nil")
+	}
+	if producer == nil {
+		return nil, fmt.Errorf("producer cannot be nil")
+	}
+	return &OutboxRelay{store: store, producer: producer, config: cfg}, nil
+}
+
+// Start polls the outbox in the background until Stop is called.
+func (r *OutboxRelay) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go r.run(ctx)
+}
+
+// Stop stops polling and waits for the batch in progress. The producer is not shut down.
+func (r *OutboxRelay) Stop() {
+	r.stopOnce.Do(func() {
+		if r.cancel == nil {
+			return
+		}
+		r.cancel()
+		<-r.done
+	})
+}
+
+// run polls until ctx is canceled. A full batch is followed by another poll right away.
+func (r *OutboxRelay) run(ctx context.Context) {
+	defer close(r.done)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	var lastPurge time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if time.Since(lastPurge) >= outboxPurgeInterval {
+			lastPurge = time.Now()
+			if _, err := r.Purge(ctx); err != nil && ctx.Err() == nil {
+				logx.Errorf("outbox purge failed: %v", err)
+			}
+		}
+
+		wait := r.config.getPollInterval()
+		published, err := r.RelayOnce(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logx.Errorf("outbox relay failed: %v", err)
+		} else if published == r.config.getBatchSize() {
+			wait = 0
+		}
+		timer.Reset(wait)
+	}
+}
+
+// RelayOnce publishes one batch of pending events and returns how many were published.
+//
+// The results are recorded even when ctx is canceled during the batch, so that stopping the relay
+// does not cause the events it has published to be published again.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	owner, err := newClaimToken()
+	if err != nil {
+		return 0, err
+	}
+	lease := r.config.getLeaseTimeout()
+	events, err := r.store.ClaimPending(ctx, owner, r.config.getBatchSize(), lease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	// Sends after the lease has expired could duplicate those of the next claim.
+	publishCtx, cancel := context.WithTimeout(ctx, lease)
+	results := r.publish(publishCtx, events)
+	cancel()
+
+	published := 0
+	for _, result := range results {
+		if result.Err == nil {
+			published++
+		}
+	}
+	if err := r.store.RecordResults(context.WithoutCancel(ctx), owner, events, results); err != nil {
+		return published, fmt.Errorf("failed to record outbox results: %w", err)
+	}
+	return published, nil
+}
+
+// Purge deletes the published events past their retention, in batches, and returns how many it
+// deleted.
+func (r *OutboxRelay) Purge(ctx context.Context) (int, error) {
+	purged := 0
+	for {
+		n, err := r.store.PurgePublished(ctx, r.config.getRetention(), r.config.getBatchSize())
+		purged += n
+		if err != nil {
+			return purged, err
+		}
+		if n < r.config.getBatchSize() {
+			return purged, nil
+		}
+	}
+}
+
+// publish sends events in order and reports each attempt. After a failure, the remaining events
+// of that aggregate are skipped and get no result, so they are released pending in order.
+func (r *OutboxRelay) publish(ctx context.Context, events []*database.OutboxEvent) []database.OutboxResult {
+	results := make([]database.OutboxResult, 0, len(events))
+	blocked := make(map[string]bool)
+
+	for _, event := range events {
+		aggregate := event.AggregateType + "/" + event.AggregateID
+		if blocked[aggregate] {
+			continue
+		}
+
+		msg, err := outboxMessage(event)
+		if err == nil {
+			// Publish as part of the request that wrote the event.
+			_, err = r.producer.Send(ExtractContext(ctx, msg), msg)
+		}
+		result := database.OutboxResult{ID: event.ID, Err: err}
+		if err != nil {
+			attempts := event.Attempts + 1
+			result.GiveUp = attempts >= r.config.getMaxAttempts()
+			result.Retry = r.config.getRetryDelay(attempts)
+			if result.GiveUp {
+				logx.WithContext(ctx).Errorf("giving up on outbox event %d of %s after %d attempts: %v",
+					event.ID, aggregate, attempts, err)
+			} else {
+				logx.WithContext(ctx).Errorf("failed to publish outbox event %d of %s: %v", event.ID, aggregate, err)
+			}
+			blocked[aggregate] = true
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,322 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+// fakeOutboxStore is an in-memory OutboxStore.
+type fakeOutboxStore struct {
+	events []*database.OutboxEvent
+	mu     sync.Mutex
+}
+
+func (f *fakeOutboxStore) add(t *testing.T, ctx context.Context, orderID int64, tag string) {
+	t.Helper()
+	msg := NewMessage("orders", []byte(tag)).WithTag(tag).WithKeys("order_" + strconv.FormatInt(orderID, 10))
+	event, err := NewOutboxEvent(ctx, "order", strconv.FormatInt(orderID, 10), msg)
+	require.NoError(t, err)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	event.ID = int64(len(f.events) + 1)
+	f.events = append(f.events, event)
+}
+
+func (f *fakeOutboxStore) ClaimPending(
+	_ context.Context, owner string, limit int, lease time.Duration,
+) ([]*database.OutboxEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+	var claimed []*database.OutboxEvent
+	blocked := make(map[string]bool)
+	scanned := 0
+	for _, event := range f.events {
+		if event.Status != database.OutboxStatusPending {
+			continue
+		}
+		if scanned++; scanned > limit {
+			break
+		}
+		aggregate := event.AggregateType + "/" + event.AggregateID
+		leased := event.ClaimOwner != nil && event.ClaimTime.Add(lease).After(now)
+		waiting := event.RetryTime != nil && event.RetryTime.After(now)
+		if leased || waiting || blocked[aggregate] {
+			blocked[aggregate] = true
+			continue
+		}
+		claimOwner, claimTime := owner, now
+		event.ClaimOwner, event.ClaimTime = &claimOwner, &claimTime
+		claimed = append(claimed, event)
+	}
+	return claimed, nil
+}
+
+func (f *fakeOutboxStore) RecordResults(
+	_ context.Context, owner string, events []*database.OutboxEvent, results []database.OutboxResult,
+) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	byID := make(map[int64]*database.OutboxEvent)
+	for _, event := range f.events {
+		byID[event.ID] = event
+	}
+	holds := func(event *database.OutboxEvent) bool {
+		return event.ClaimOwner != nil && *event.ClaimOwner == owner
+	}
+	for _, result := range results {
+		event := byID[result.ID]
+		if !holds(event) {
+			continue
+		}
+		if result.Err == nil {
+			event.Status = database.OutboxStatusPublished
+			publishTime := time.Now()
+			event.PublishTime = &publishTime
+		} else {
+			event.Attempts++
+			reason := result.Err.Error()
+			event.LastError = &reason
+			retryTime := time.Now().Add(result.Retry)
+			event.RetryTime = &retryTime
+			if result.GiveUp {
+				event.Status = database.OutboxStatusFailed
+			}
+		}
+	}
+	for _, event := range events {
+		if event = byID[event.ID]; holds(event) {
+			event.ClaimOwner, event.ClaimTime = nil, nil
+		}
+	}
+	return nil
+}
+
+func (f *fakeOutboxStore) PurgePublished(_ context.Context, retention time.Duration, limit int) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cutoff := time.Now().Add(-retention)
+	kept := f.events[:0]
+	purged := 0
+	for _, event := range f.events {
+		if purged < limit && event.Status == database.OutboxStatusPublished && event.PublishTime.Before(cutoff) {
+			purged++
+			continue
+		}
+		kept = append(kept, event)
+	}
+	f.events = kept
+	return purged, nil
+}
+
+// flakyProducer fails the sends of messages whose tag is in failures, once per listed entry.
+type flakyProducer struct {
+	Producer
+	failures map[string]int
+	mu       sync.Mutex
+}
+
+func (f *flakyProducer) Send(ctx context.Context, msg *Message) (*SendResult, error) {
+	f.mu.Lock()
+	if f.failures[msg.Tag] > 0 {
+		f.failures[msg.Tag]--
+		f.mu.Unlock()
+		return nil, errors.New("broker unavailable")
+	}
+	f.mu.Unlock()
+	return f.Producer.Send(ctx, msg)
+}
+
+func tags(msgs []*Message) []string {
+	out := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		out = append(out, msg.Tag)
+	}
+	return out
+}
+
+func TestNewOutboxEvent(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-1")
+	msg := NewMessage("orders", []byte("body")).WithTag("ORDER_PLACED").WithKeys("order_1", "user_2")
+
+	event, err := NewOutboxEvent(ctx, "order", "1", msg)
+	require.NoError(t, err)
+	assert.Equal(t, "order", event.AggregateType)
+	assert.Equal(t, "1", event.AggregateID)
+	assert.Equal(t, "order_1 user_2", event.Keys)
+	assert.Equal(t, int8(database.OutboxStatusPending), event.Status)
+
+	got, err := outboxMessage(event)
+	require.NoError(t, err)
+	assert.Equal(t, "orders", got.Topic)
+	assert.Equal(t, "ORDER_PLACED", got.Tag)
+	assert.Equal(t, []string{"order_1", "user_2"}, got.Keys)
+	assert.Equal(t, []byte("body"), got.Body)
+	assert.Equal(t, "req-1", got.Property(PropertyRequestID))
+
+	_, err = NewOutboxEvent(ctx, "order", "1", NewMessage("", nil))
+	assert.Error(t, err)
+}
+
+func TestOutboxRelay_RelayOnce_OrderedPerAggregate(t *testing.T) {
+	broker := NewMemoryBroker()
+	producer := &flakyProducer{Producer: broker.NewProducer(), failures: map[string]int{"A1": 1}}
+	store := &fakeOutboxStore{}
+	ctx := WithRequestID(context.Background(), "req-1")
+	store.add(t, ctx, 1, "A1")
+	store.add(t, ctx, 2, "B1")
+	store.add(t, ctx, 1, "A2")
+
+	relay, err := NewOutboxRelay(store, producer, OutboxConfig{RetryInterval: 50})
+	require.NoError(t, err)
+
+	published, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, []string{"B1"}, tags(broker.Messages("orders")), "A2 waits for A1")
+	assert.Equal(t, int32(1), store.events[0].Attempts)
+	assert.Equal(t, "broker unavailable", *store.events[0].LastError)
+	assert.Zero(t, store.events[2].Attempts, "a skipped event is not an attempt")
+	assert.Nil(t, store.events[2].ClaimOwner, "a skipped event is released")
+
+	published, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, published, "A1 waits for its retry delay, and A2 for A1")
+
+	time.Sleep(60 * time.Millisecond)
+	published, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, []string{"B1", "A1", "A2"}, tags(broker.Messages("orders")))
+	for _, msg := range broker.Messages("orders") {
+		assert.Equal(t, "req-1", msg.Property(PropertyRequestID), "the writer's request ID travels with the event")
+	}
+
+	published, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, published)
+}
+
+func TestOutboxRelay_RelayOnce_LeasedEventsBlockTheirAggregate(t *testing.T) {
+	broker := NewMemoryBroker()
+	store := &fakeOutboxStore{}
+	store.add(t, context.Background(), 1, "A1")
+	store.add(t, context.Background(), 2, "B1")
+	store.add(t, context.Background(), 1, "A2")
+
+	// Another relay holds A1 while it publishes.
+	claimed, err := store.ClaimPending(context.Background(), "other", 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	relay, err := NewOutboxRelay(store, broker.NewProducer(), OutboxConfig{})
+	require.NoError(t, err)
+	published, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, []string{"B1"}, tags(broker.Messages("orders")), "A2 waits for the claim of A1")
+
+	require.NoError(t, store.RecordResults(context.Background(), "other", claimed,
+		[]database.OutboxResult{{ID: claimed[0].ID}}))
+	published, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, []string{"B1", "A2"}, tags(broker.Messages("orders")))
+}
+
+func TestOutboxRelay_RelayOnce_GivesUp(t *testing.T) {
+	broker := NewMemoryBroker()
+	producer := &flakyProducer{Producer: broker.NewProducer(), failures: map[string]int{"A1": 2}}
+	store := &fakeOutboxStore{}
+	store.add(t, context.Background(), 1, "A1")
+	store.add(t, context.Background(), 1, "A2")
+
+	relay, err := NewOutboxRelay(store, producer, OutboxConfig{RetryInterval: 1, MaxAttempts: 2})
+	require.NoError(t, err)
+	var published int
+	for i := 0; i < 2; i++ {
+		published, err = relay.RelayOnce(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, published)
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, int8(database.OutboxStatusFailed), store.events[0].Status)
+	assert.Equal(t, int32(2), store.events[0].Attempts)
+
+	published, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, []string{"A2"}, tags(broker.Messages("orders")), "a failed event no longer blocks its aggregate")
+}
+
+func TestOutboxRelay_Purge(t *testing.T) {
+	store := &fakeOutboxStore{}
+	for i := int64(1); i <= 5; i++ {
+		store.add(t, context.Background(), i, "ORDER_PLACED")
+	}
+	old, recent := time.Now().Add(-8*24*time.Hour), time.Now()
+	for i, event := range store.events {
+		switch {
+		case i < 3:
+			event.Status, event.PublishTime = database.OutboxStatusPublished, &old
+		case i == 3:
+			event.Status, event.PublishTime = database.OutboxStatusPublished, &recent
+		}
+	}
+
+	relay, err := NewOutboxRelay(store, NewMemoryBroker().NewProducer(), OutboxConfig{BatchSize: 2})
+	require.NoError(t, err)
+	purged, err := relay.Purge(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, purged)
+	require.Len(t, store.events, 2, "recent and pending events are kept")
+	assert.Equal(t, int64(4), store.events[0].ID)
+	assert.Equal(t, int64(5), store.events[1].ID)
+}
+
+func TestOutboxConfig_GetRetryDelay(t *testing.T) {
+	c := OutboxConfig{RetryInterval: 1000}
+	assert.Equal(t, time.Second, c.getRetryDelay(1))
+	assert.Equal(t, 4*time.Second, c.getRetryDelay(3))
+	assert.Equal(t, maxOutboxRetryInterval, c.getRetryDelay(30))
+}
+
+func TestOutboxRelay_StartStop(t *testing.T) {
+	broker := NewMemoryBroker()
+	store := &fakeOutboxStore{}
+	for i := int64(1); i <= 5; i++ {
+		store.add(t, context.Background(), i, "ORDER_PLACED")
+	}
+
+	relay, err := NewOutboxRelay(store, broker.NewProducer(), OutboxConfig{PollInterval: 1, BatchSize: 2})
+	require.NoError(t, err)
+	relay.Start()
+	defer relay.Stop()
+
+	assert.Eventually(t, func() bool {
+		return len(broker.Messages("orders")) == 5
+	}, 5*time.Second, time.Millisecond)
+
+	relay.Stop()
+	relay.Stop() // idempotent
+}
+
+func TestNewOutboxRelay_Validation(t *testing.T) {
+	_, err := NewOutboxRelay(nil, NewMemoryBroker().NewProducer(), OutboxConfig{})
+	assert.Error(t, err)
+	_, err = NewOutboxRelay(&fakeOutboxStore{}, nil, OutboxConfig{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package mq
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Message properties that carry request context across the broker.
+// Trace context uses the W3C names so that other tracers can read it.
+const (
+	PropertyTraceParent = "traceparent"
+	PropertyTraceState  = "tracestate"
+	PropertyRequestID   = "x-request-id"
+	// UserPropertyPrefix marks properties set through WithUserProperties.
+	UserPropertyPrefix = "x-user-"
+)
+
+type requestIDKey struct{}
+
+type userPropertiesKey struct{}
+
+// traceContext injects and extracts the W3C trace context, independent of the global otel propagator.
+var traceContext = propagation.TraceContext{}
+
+// WithRequestID returns a context carrying the request ID. Logs written with the context include it.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	return logx.ContextWithFields(ctx, logx.Field("requestId", requestID))
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or "" if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// WithUserProperties returns a context carrying application properties to send with messages.
+// They are merged with properties already in ctx, the new values winning.
+func WithUserProperties(ctx context.Context, props map[string]string) context.Context {
+	merged := make(map[string]string, len(props))
+	for k, v := range UserPropertiesFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range props {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, userPropertiesKey{}, merged)
+}
+
+// UserPropertiesFromContext returns a copy of the application properties carried by ctx.
+func UserPropertiesFromContext(ctx context.Context) map[string]string {
+	props, _ := ctx.Value(userPropertiesKey{}).(map[string]string)
+	out := make(map[string]string, len(props))
+	for k, v := range props {
+		out[k] = v
+	}
+	return out
+}
+
+// InjectContext writes the trace context, request ID and user properties of ctx into msg properties.
+// A request ID is generated when ctx has none, so that every message can be correlated.
+func InjectContext(ctx context.Context, msg *Message) {
+	traceContext.Inject(ctx, messageCarrier{msg: msg})
+
+	requestID := RequestIDFromContext(ctx)
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	msg.WithProperty(PropertyRequestID, requestID)
+
+	for k, v := range UserPropertiesFromContext(ctx) {
+		msg.WithProperty(UserPropertyPrefix+k, v)
+	}
+}
+
+// ExtractContext returns ctx enriched with the trace context, request ID and user properties
+// carried by msg, so that handlers log and trace as part of the originating request.
+func ExtractContext(ctx context.Context, msg *Message) context.Context {
+	ctx = traceContext.Extract(ctx, messageCarrier{msg: msg})
+	ctx = WithRequestID(ctx, msg.Property(PropertyRequestID))
+
+	props := make(map[string]string)
+	for k, v := range msg.Properties {
+		if name, ok := strings.CutPrefix(k, UserPropertyPrefix); ok {
+			props[name] = v
+		}
+	}
+	if len(props) > 0 {
+		ctx = WithUserProperties(ctx, props)
+	}
+	return ctx
+}
+
+// newRequestID returns a random 16-byte hex request ID.
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// messageCarrier adapts message properties to propagation.TextMapCarrier.
+type messageCarrier struct {
+	msg *Message
+}
+
+// Get returns the property stored under key.
+func (c messageCarrier) Get(key string) string {
+	return c.msg.Property(key)
+}
+
+// Set stores a property.
+func (c messageCarrier) Set(key, value string) {
+	c.msg.WithProperty(key, value)
+}
+
+// Keys lists the property names.
+func (c messageCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.msg.Properties))
+	for k := range c.msg.Properties {
+		keys = append(keys, k)
+	}
+	return keys
+}
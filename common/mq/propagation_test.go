@@ -0,0 +1,93 @@
+package mq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTracedContext(t *testing.T) (context.Context, trace.SpanContext) {
+	t.Helper()
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	if err != nil {
+		t.Fatalf("invalid trace id: %v", err)
+	}
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	if err != nil {
+		t.Fatalf("invalid span id: %v", err)
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	return trace.ContextWithSpanContext(context.Background(), sc), sc
+}
+
+func TestInjectExtractContext(t *testing.T) {
+	ctx, sc := newTracedContext(t)
+	ctx = WithRequestID(ctx, "req-1")
+	ctx = WithUserProperties(ctx, map[string]string{"userId": "7"})
+
+	msg := NewMessage("topic", []byte("body"))
+	InjectContext(ctx, msg)
+
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", msg.Property(PropertyTraceParent))
+	assert.Equal(t, "req-1", msg.Property(PropertyRequestID))
+	assert.Equal(t, "7", msg.Property(UserPropertyPrefix+"userId"))
+
+	extracted := ExtractContext(context.Background(), msg)
+	got := trace.SpanContextFromContext(extracted)
+	assert.Equal(t, sc.TraceID(), got.TraceID())
+	assert.Equal(t, sc.SpanID(), got.SpanID())
+	assert.True(t, got.IsRemote())
+	assert.Equal(t, "req-1", RequestIDFromContext(extracted))
+	assert.Equal(t, map[string]string{"userId": "7"}, UserPropertiesFromContext(extracted))
+}
+
+func TestInjectContext_GeneratesRequestID(t *testing.T) {
+	msg := NewMessage("topic", []byte("body"))
+	InjectContext(context.Background(), msg)
+
+	assert.Len(t, msg.Property(PropertyRequestID), 32)
+	assert.Empty(t, msg.Property(PropertyTraceParent), "no trace context without a span")
+}
+
+func TestWithUserProperties_Merges(t *testing.T) {
+	ctx := WithUserProperties(context.Background(), map[string]string{"a": "1", "b": "2"})
+	ctx = WithUserProperties(ctx, map[string]string{"b": "3"})
+
+	assert.Equal(t, map[string]string{"a": "1", "b": "3"}, UserPropertiesFromContext(ctx))
+}
+
+func TestTransactionListener_PropagatesContext(t *testing.T) {
+	ctx, sc := newTracedContext(t)
+	msg := NewMessage("topic", []byte("body")).WithTag("ORDER_PLACED").WithKeys("order_1")
+	InjectContext(WithRequestID(ctx, "req-1"), msg)
+	pmsg := toPrimitiveMessage(msg)
+
+	var gotCtx context.Context
+	var gotMsg *Message
+	listener := &transactionListener{
+		executor: func(ctx context.Context, msg *Message) (LocalTransactionState, error) {
+			gotCtx, gotMsg = ctx, msg
+			return CommitMessageState, nil
+		},
+		checkBack: func(ctx context.Context, _ *Message) (LocalTransactionState, error) {
+			gotCtx = ctx
+			return CommitMessageState, nil
+		},
+	}
+
+	assert.Equal(t, primitive.CommitMessageState, listener.ExecuteLocalTransaction(pmsg))
+	assert.Equal(t, sc.TraceID(), trace.SpanContextFromContext(gotCtx).TraceID())
+	assert.Equal(t, "req-1", RequestIDFromContext(gotCtx))
+	assert.Equal(t, []string{"order_1"}, gotMsg.Keys)
+	assert.Equal(t, "ORDER_PLACED", gotMsg.Tag)
+	assert.Equal(t, []byte("body"), gotMsg.Body)
+
+	checked := &primitive.MessageExt{Message: primitive.Message{Topic: "topic"}}
+	checked.WithProperties(pmsg.GetProperties())
+	assert.Equal(t, primitive.CommitMessageState, listener.CheckLocalTransaction(checked))
+	assert.Equal(t, sc.TraceID(), trace.SpanContextFromContext(gotCtx).TraceID())
+	assert.Equal(t, "req-1", RequestIDFromContext(gotCtx))
+}
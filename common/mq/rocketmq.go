@@ -0,0 +1,412 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	rocketmq "github.com/apache/rocketmq-client-go/v2"
+	"github.com/apache/rocketmq-client-go/v2/consumer"
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+	"github.com/apache/rocketmq-client-go/v2/producer"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// RocketMQTransactionProducer wraps RocketMQ transaction producer with check-back support.
+// It implements TransactionProducer.
+type RocketMQTransactionProducer struct {
+	producer rocketmq.TransactionProducer
+	listener *transactionListener
+	config   *Config
+}
+
+// transactionListener implements primitive.TransactionListener interface.
+type transactionListener struct {
+	executor  LocalTransactionExecutor
+	checkBack CheckBackExecutor
+	// localErrs holds executor errors keyed by the sent *primitive.Message until
+	// SendMessageInTransaction collects them. The RocketMQ client calls the executor
+	// synchronously with the message being sent, so the pointer identifies the call.
+	localErrs sync.Map
+}
+
+// ExecuteLocalTransaction executes the local transaction.
+// RocketMQ API doesn't provide context, so the context of the sending request is rebuilt
+// from the message properties written by InjectContext.
+//
+// An executor error rolls the message back unless the executor reports UnknownState,
+// in which case the broker resolves the transaction through check-back.
+func (tl *transactionListener) ExecuteLocalTransaction(msg *primitive.Message) primitive.LocalTransactionState {
+	m := fromPrimitiveMessage(msg)
+	ctx := ExtractContext(context.Background(), m)
+
+	state, err := tl.executor(ctx, m)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("local transaction execution failed: %v", err)
+		tl.localErrs.Store(msg, err)
+	}
+	return toPrimitiveState(resolveState(state, err))
+}
+
+// CheckLocalTransaction checks the status of a local transaction.
+// As with ExecuteLocalTransaction, the context is rebuilt from the message properties, and an
+// error keeps the transaction unknown only if the executor says so.
+func (tl *transactionListener) CheckLocalTransaction(msg *primitive.MessageExt) primitive.LocalTransactionState {
+	m := fromMessageExt(msg)
+	ctx := ExtractContext(context.Background(), m)
+
+	state, err := tl.checkBack(ctx, m)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("check-back execution failed: %v", err)
+	}
+	return toPrimitiveState(resolveState(state, err))
+}
+
+// NewTransactionProducer creates a new RocketMQ transaction producer.
+func NewTransactionProducer(
+	cfg *Config,
+	executor LocalTransactionExecutor,
+	checkBack CheckBackExecutor,
+) (*RocketMQTransactionProducer, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if executor == nil {
+		return nil, fmt.Errorf("local transaction executor cannot be nil")
+	}
+	if checkBack == nil {
+		return nil, fmt.Errorf("check-back executor cannot be nil")
+	}
+
+	// Create TransactionListener that implements both ExecuteLocalTransaction and CheckLocalTransaction
+	listener := &transactionListener{
+		executor:  executor,
+		checkBack: checkBack,
+	}
+
+	p, err := rocketmq.NewTransactionProducer(
+		listener,
+		cfg.producerOptions()...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction producer: %w", err)
+	}
+
+	if err := p.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start transaction producer: %w", err)
+	}
+
+	return &RocketMQTransactionProducer{
+		producer: p,
+		listener: listener,
+		config:   cfg,
+	}, nil
+}
+
+// SendMessageInTransaction sends a transactional message.
+// It returns after the local transaction executor has run, reporting its outcome.
+// The trace context, request ID and user properties of ctx travel with the message (see InjectContext).
+func (tp *RocketMQTransactionProducer) SendMessageInTransaction(
+	ctx context.Context,
+	msg *Message,
+) (*TransactionSendResult, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+
+	// Set topic if not set
+	if msg.Topic == "" {
+		msg.Topic = tp.config.Topic
+	}
+	InjectContext(ctx, msg)
+
+	pmsg := toPrimitiveMessage(msg)
+	result, err := tp.producer.SendMessageInTransaction(ctx, pmsg)
+	localErr, _ := tp.listener.localErrs.LoadAndDelete(pmsg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send transactional message: %w", err)
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("transaction send result is nil")
+	}
+
+	sendResult := &TransactionSendResult{
+		State: fromPrimitiveState(result.State),
+	}
+	if result.SendResult != nil {
+		sendResult.MsgID = result.MsgID
+	}
+	if executorErr, ok := localErr.(error); ok {
+		sendResult.LocalErr = executorErr
+	} else if result.SendResult == nil || result.Status != primitive.SendOK {
+		// The client rolls back without running the executor when the half message was not stored.
+		status := primitive.SendUnknownError
+		if result.SendResult != nil {
+			status = result.Status
+		}
+		sendResult.LocalErr = fmt.Errorf("message broker did not store the message (send status %d)", status)
+	}
+
+	return sendResult, nil
+}
+
+// Shutdown gracefully shuts down the producer.
+func (tp *RocketMQTransactionProducer) Shutdown() error {
+	if tp.producer != nil {
+		return tp.producer.Shutdown()
+	}
+	return nil
+}
+
+// RocketMQProducer wraps a RocketMQ producer. It implements Producer.
+type RocketMQProducer struct {
+	producer rocketmq.Producer
+	config   *Config
+}
+
+// NewProducer creates and starts a RocketMQ producer.
+func NewProducer(cfg *Config) (*RocketMQProducer, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	p, err := rocketmq.NewProducer(cfg.producerOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create producer: %w", err)
+	}
+	if err := p.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start producer: %w", err)
+	}
+
+	return &RocketMQProducer{producer: p, config: cfg}, nil
+}
+
+// Send sends msg synchronously. The context of ctx travels with the message (see InjectContext).
+func (p *RocketMQProducer) Send(ctx context.Context, msg *Message) (*SendResult, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+	if msg.Topic == "" {
+		msg.Topic = p.config.Topic
+	}
+	InjectContext(ctx, msg)
+
+	result, err := p.producer.SendSync(ctx, toPrimitiveMessage(msg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+	if result.Status != primitive.SendOK {
+		return nil, fmt.Errorf("message broker did not store the message (send status %d)", result.Status)
+	}
+	return &SendResult{MsgID: result.MsgID}, nil
+}
+
+// Shutdown gracefully shuts down the producer.
+func (p *RocketMQProducer) Shutdown() error {
+	if p.producer != nil {
+		return p.producer.Shutdown()
+	}
+	return nil
+}
+
+// RocketMQConsumer wraps a RocketMQ push consumer in clustering mode. It implements Consumer.
+// Config.Group is used as the consumer group.
+type RocketMQConsumer struct {
+	consumer rocketmq.PushConsumer
+}
+
+// NewConsumer creates a RocketMQ push consumer. Subscribe handlers, then call Start.
+func NewConsumer(cfg *Config) (*RocketMQConsumer, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	opts := []consumer.Option{
+		consumer.WithNameServer(parseNameServers(cfg.NameServer)),
+		consumer.WithGroupName(cfg.Group),
+		consumer.WithConsumerModel(consumer.Clustering),
+	}
+	if cfg.MaxReconsumeTimes > 0 {
+		opts = append(opts, consumer.WithMaxReconsumeTimes(cfg.MaxReconsumeTimes))
+	}
+
+	c, err := rocketmq.NewPushConsumer(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer: %w", err)
+	}
+	return &RocketMQConsumer{consumer: c}, nil
+}
+
+// Subscribe registers handler for messages on topic whose tag matches tagExpr.
+func (c *RocketMQConsumer) Subscribe(topic, tagExpr string, handler Handler) error {
+	if handler == nil {
+		return fmt.Errorf("handler cannot be nil")
+	}
+	selector := consumer.MessageSelector{Type: consumer.TAG, Expression: tagExpr}
+	if err := c.consumer.Subscribe(topic, selector, consumeFunc(handler)); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Start starts consuming.
+func (c *RocketMQConsumer) Start() error {
+	if err := c.consumer.Start(); err != nil {
+		return fmt.Errorf("failed to start consumer: %w", err)
+	}
+	return nil
+}
+
+// Shutdown gracefully shuts down the consumer.
+func (c *RocketMQConsumer) Shutdown() error {
+	return c.consumer.Shutdown()
+}
+
+// consumeFunc adapts handler for PushConsumer.Subscribe. It runs the handler once per message with
+// that message's context extracted, and stops at the first failure so that the broker redelivers it.
+func consumeFunc(handler Handler) func(context.Context, ...*primitive.MessageExt) (consumer.ConsumeResult, error) {
+	return func(ctx context.Context, msgs ...*primitive.MessageExt) (consumer.ConsumeResult, error) {
+		for _, msg := range msgs {
+			m := fromMessageExt(msg)
+			if err := handler(ExtractContext(ctx, m), m); err != nil {
+				logx.WithContext(ctx).Errorf("failed to consume message %s: %v", m.MsgID, err)
+				return consumer.ConsumeRetryLater, err
+			}
+		}
+		return consumer.ConsumeSuccess, nil
+	}
+}
+
+// toPrimitiveMessage converts msg to a RocketMQ message.
+func toPrimitiveMessage(msg *Message) *primitive.Message {
+	pmsg := primitive.NewMessage(msg.Topic, msg.Body)
+	pmsg.WithProperties(msg.Properties)
+	if msg.Tag != "" {
+		pmsg.WithTag(msg.Tag)
+	}
+	if len(msg.Keys) > 0 {
+		pmsg.WithKeys(msg.Keys)
+	}
+	return pmsg
+}
+
+// fromPrimitiveMessage converts a RocketMQ message to a Message.
+func fromPrimitiveMessage(msg *primitive.Message) *Message {
+	m := &Message{
+		Topic:      msg.Topic,
+		Tag:        msg.GetTags(),
+		Keys:       strings.Fields(msg.GetKeys()),
+		Body:       msg.Body,
+		Properties: make(map[string]string),
+	}
+	for k, v := range msg.GetProperties() {
+		// Tags and keys are carried as properties by RocketMQ; expose them only as fields.
+		if k != primitive.PropertyTags && k != primitive.PropertyKeys {
+			m.Properties[k] = v
+		}
+	}
+	return m
+}
+
+// fromMessageExt converts a delivered RocketMQ message to a Message.
+func fromMessageExt(msg *primitive.MessageExt) *Message {
+	m := fromPrimitiveMessage(&msg.Message)
+	m.MsgID = msg.MsgId
+	m.ReconsumeTimes = msg.ReconsumeTimes
+	return m
+}
+
+// toPrimitiveState converts LocalTransactionState to a RocketMQ transaction state.
+func toPrimitiveState(state LocalTransactionState) primitive.LocalTransactionState {
+	switch state {
+	case CommitMessageState:
+		return primitive.CommitMessageState
+	case UnknownState:
+		return primitive.UnknowState
+	default:
+		return primitive.RollbackMessageState
+	}
+}
+
+// fromPrimitiveState converts a RocketMQ transaction state to LocalTransactionState.
+func fromPrimitiveState(state primitive.LocalTransactionState) LocalTransactionState {
+	switch state {
+	case primitive.CommitMessageState:
+		return CommitMessageState
+	case primitive.RollbackMessageState:
+		return RollbackMessageState
+	default:
+		return UnknownState
+	}
+}
+
+// validate checks the settings every RocketMQ client needs.
+func (c *Config) validate() error {
+	if c == nil {
+		return fmt.Errorf("mq config cannot be nil")
+	}
+	if c.NameServer == "" {
+		return fmt.Errorf("nameServer is required")
+	}
+	if c.Group == "" {
+		return fmt.Errorf("group is required")
+	}
+	// Parse NameServer addresses (support comma or semicolon separated)
+	if len(parseNameServers(c.NameServer)) == 0 {
+		return fmt.Errorf("invalid nameServer configuration: %s", c.NameServer)
+	}
+	return nil
+}
+
+// producerOptions returns the options shared by plain and transaction producers.
+func (c *Config) producerOptions() []producer.Option {
+	return []producer.Option{
+		producer.WithNameServer(parseNameServers(c.NameServer)),
+		producer.WithGroupName(c.Group),
+		producer.WithRetry(c.getRetryTimes()),
+		producer.WithSendMsgTimeout(time.Duration(c.getSendTimeout()) * time.Millisecond),
+	}
+}
+
+// getRetryTimes returns the retry times, defaulting to 2.
+func (c *Config) getRetryTimes() int {
+	if c.RetryTimes <= 0 {
+		return 2
+	}
+	return c.RetryTimes
+}
+
+// getSendTimeout returns the send timeout in milliseconds, defaulting to 3000.
+func (c *Config) getSendTimeout() int {
+	if c.SendTimeout <= 0 {
+		return 3000
+	}
+	return c.SendTimeout
+}
+
+// parseNameServers parses NameServer string into a slice of addresses.
+// Supports comma or semicolon separated addresses.
+func parseNameServers(nameServer string) []string {
+	if nameServer == "" {
+		return nil
+	}
+
+	// Try semicolon first (RocketMQ standard), then comma
+	separator := ";"
+	if !strings.Contains(nameServer, ";") && strings.Contains(nameServer, ",") {
+		separator = ","
+	}
+
+	addresses := strings.Split(nameServer, separator)
+	result := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		addr = strings.TrimSpace(addr)
+		if addr != "" {
+			result = append(result, addr)
+		}
+	}
+	return result
+}
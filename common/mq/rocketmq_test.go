@@ -0,0 +1,135 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/apache/rocketmq-client-go/v2/consumer"
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionListener_ExecuteLocalTransaction(t *testing.T) {
+	errDB := errors.New("db unavailable")
+
+	tests := []struct {
+		err     error
+		name    string
+		state   LocalTransactionState
+		want    primitive.LocalTransactionState
+		wantErr bool
+	}{
+		{name: "commit", state: CommitMessageState, want: primitive.CommitMessageState},
+		{name: "rollback", state: RollbackMessageState, want: primitive.RollbackMessageState},
+		{name: "unknown", state: UnknownState, want: primitive.UnknowState},
+		{name: "error rolls back", state: CommitMessageState, err: errDB, want: primitive.RollbackMessageState, wantErr: true},
+		{name: "error with unknown state", state: UnknownState, err: errDB, want: primitive.UnknowState, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener := &transactionListener{
+				executor: func(_ context.Context, _ *Message) (LocalTransactionState, error) {
+					return tt.state, tt.err
+				},
+			}
+			msg := primitive.NewMessage("topic", []byte("body"))
+
+			assert.Equal(t, tt.want, listener.ExecuteLocalTransaction(msg))
+
+			localErr, ok := listener.localErrs.LoadAndDelete(msg)
+			assert.Equal(t, tt.wantErr, ok)
+			if tt.wantErr {
+				assert.Equal(t, tt.err, localErr)
+			}
+		})
+	}
+}
+
+func TestFromPrimitiveState(t *testing.T) {
+	assert.Equal(t, CommitMessageState, fromPrimitiveState(primitive.CommitMessageState))
+	assert.Equal(t, RollbackMessageState, fromPrimitiveState(primitive.RollbackMessageState))
+	assert.Equal(t, UnknownState, fromPrimitiveState(primitive.UnknowState))
+}
+
+func TestToPrimitiveState(t *testing.T) {
+	assert.Equal(t, primitive.CommitMessageState, toPrimitiveState(CommitMessageState))
+	assert.Equal(t, primitive.RollbackMessageState, toPrimitiveState(RollbackMessageState))
+	assert.Equal(t, primitive.UnknowState, toPrimitiveState(UnknownState))
+	assert.Equal(t, primitive.RollbackMessageState, toPrimitiveState(LocalTransactionState(42)))
+}
+
+func TestMessageConversion(t *testing.T) {
+	msg := NewMessage("topic", []byte("body")).WithTag("ORDER_PLACED").WithKeys("order_1", "user_2")
+	msg.WithProperty(PropertyRequestID, "req-1")
+
+	pmsg := toPrimitiveMessage(msg)
+	assert.Equal(t, "ORDER_PLACED", pmsg.GetTags())
+	assert.Equal(t, "order_1 user_2", pmsg.GetKeys())
+	assert.Equal(t, "req-1", pmsg.GetProperty(PropertyRequestID))
+
+	ext := &primitive.MessageExt{Message: primitive.Message{Topic: pmsg.Topic, Body: pmsg.Body}, MsgId: "id-1", ReconsumeTimes: 2}
+	ext.WithProperties(pmsg.GetProperties())
+	got := fromMessageExt(ext)
+	assert.Equal(t, "topic", got.Topic)
+	assert.Equal(t, "ORDER_PLACED", got.Tag)
+	assert.Equal(t, []string{"order_1", "user_2"}, got.Keys)
+	assert.Equal(t, []byte("body"), got.Body)
+	assert.Equal(t, map[string]string{PropertyRequestID: "req-1"}, got.Properties)
+	assert.Equal(t, "id-1", got.MsgID)
+	assert.Equal(t, int32(2), got.ReconsumeTimes)
+}
+
+func TestConsumeFunc(t *testing.T) {
+	newMsg := func(requestID string) *primitive.MessageExt {
+		msg := &primitive.MessageExt{Message: primitive.Message{Topic: "topic"}}
+		msg.WithProperty(PropertyRequestID, requestID)
+		return msg
+	}
+
+	var seen []string
+	consume := consumeFunc(func(ctx context.Context, _ *Message) error {
+		seen = append(seen, RequestIDFromContext(ctx))
+		if RequestIDFromContext(ctx) == "bad" {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+
+	result, err := consume(context.Background(), newMsg("a"), newMsg("b"))
+	assert.NoError(t, err)
+	assert.Equal(t, consumer.ConsumeSuccess, result)
+	assert.Equal(t, []string{"a", "b"}, seen)
+
+	seen = nil
+	result, err = consume(context.Background(), newMsg("bad"), newMsg("c"))
+	assert.Error(t, err)
+	assert.Equal(t, consumer.ConsumeRetryLater, result)
+	assert.Equal(t, []string{"bad"}, seen, "messages after a failure are redelivered, not handled")
+}
+
+func TestParseNameServers(t *testing.T) {
+	assert.Nil(t, parseNameServers(""))
+	assert.Equal(t, []string{"127.0.0.1:9876"}, parseNameServers("127.0.0.1:9876"))
+	assert.Equal(t, []string{"a:9876", "b:9876"}, parseNameServers("a:9876; b:9876"))
+	assert.Equal(t, []string{"a:9876", "b:9876"}, parseNameServers("a:9876,b:9876,"))
+}
+
+func TestTransactionListener_CheckLocalTransaction(t *testing.T) {
+	errDB := errors.New("db unavailable")
+	msg := &primitive.MessageExt{Message: primitive.Message{Topic: "topic"}}
+
+	newListener := func(state LocalTransactionState, err error) *transactionListener {
+		return &transactionListener{
+			checkBack: func(_ context.Context, _ *Message) (LocalTransactionState, error) {
+				return state, err
+			},
+		}
+	}
+
+	assert.Equal(t, primitive.CommitMessageState, newListener(CommitMessageState, nil).CheckLocalTransaction(msg))
+	assert.Equal(t, primitive.RollbackMessageState, newListener(CommitMessageState, errDB).CheckLocalTransaction(msg))
+	assert.Equal(t, primitive.UnknowState, newListener(UnknownState, errDB).CheckLocalTransaction(msg),
+		"a failed lookup leaves the transaction for the next check-back")
+}
@@ -122,6 +122,19 @@ end
 
 local newStock = redis.call('DECRBY', KEYS[1], ARGV[1])
 return newStock
+`
+
+	// Inventory restoration script, the inverse of decrStock.
+	// A missing key means the stock was never preheated (or already dropped), so nothing is recreated.
+	incrStockScript := `
+-- KEYS[1]: Inventory Key
+-- ARGV[1]: Restored Quantity
+
+if redis.call('EXISTS', KEYS[1]) == 0 then
+    return {err = "Inventory Key does not exist"}
+end
+
+return redis.call('INCRBY', KEYS[1], ARGV[1])
 `
 
 	// Enhanced inventory deduction with user tracking
@@ -179,13 +192,112 @@ if current == 1 then
 end
 
 return current
+`
+
+	// One-time token consumption: GET+DEL the token and, in the same step, leave a marker under
+	// the result key so a replay can tell "already used" apart from "never issued".
+	consumeTokenScript := `
+-- KEYS[1]: Token Key
+-- KEYS[2]: Result Key
+-- ARGV[1]: Marker stored under the result key when the token is consumed
+-- ARGV[2]: Result key expiration in seconds
+
+local value = redis.call('GET', KEYS[1])
+if value then
+    redis.call('DEL', KEYS[1])
+    redis.call('SET', KEYS[2], ARGV[1], 'EX', ARGV[2])
+    return {1, value}
+end
+
+local result = redis.call('GET', KEYS[2])
+if result then
+    return {2, result}
+end
+
+return {0, ''}
+`
+
+	// Claim a key unless it exists, returning the value of an existing key in the same step.
+	claimKeyScript := `
+-- KEYS[1]: Key
+-- ARGV[1]: Marker stored when the key is claimed
+-- ARGV[2]: Expiration in milliseconds
+
+local value = redis.call('GET', KEYS[1])
+if value then
+    return value
+end
+
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return ''
+`
+
+	// Extend the expiration of a key only while it holds the caller's marker.
+	renewKeyScript := `
+-- KEYS[1]: Key
+-- ARGV[1]: Marker the key must hold
+-- ARGV[2]: Expiration in milliseconds
+
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`
+
+	// Delete a key only while it holds the caller's marker.
+	releaseKeyScript := `
+-- KEYS[1]: Key
+-- ARGV[1]: Marker the key must hold
+
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+	// Replace the value of a key only while it holds the caller's marker.
+	swapKeyScript := `
+-- KEYS[1]: Key
+-- ARGV[1]: Marker the key must hold
+-- ARGV[2]: New value
+-- ARGV[3]: Expiration in milliseconds
+
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+    return 1
+end
+return 0
+`
+
+	// Replace a hash field only while it holds the value the caller read, extending the hash expiry.
+	// A missing key or field never matches.
+	swapHashFieldScript := `
+-- KEYS[1]: Hash Key
+-- ARGV[1]: Field
+-- ARGV[2]: Value the field must hold
+-- ARGV[3]: New value
+-- ARGV[4]: Expiration in milliseconds
+
+if redis.call('HGET', KEYS[1], ARGV[1]) == ARGV[2] then
+    redis.call('HSET', KEYS[1], ARGV[1], ARGV[3])
+    redis.call('PEXPIRE', KEYS[1], ARGV[4])
+    return 1
+end
+return 0
 `
 
 	scripts := map[string]string{
+		"swapHashField":     swapHashFieldScript,
+		"claimKey":          claimKeyScript,
+		"renewKey":          renewKeyScript,
+		"releaseKey":        releaseKeyScript,
+		"swapKey":           swapKeyScript,
 		"decrStock":         decrStockScript,
+		"incrStock":         incrStockScript,
 		"decrStockWithUser": decrStockWithUserScript,
 		"setNXWithExpire":   setNXWithExpireScript,
 		"incrWithExpire":    incrWithExpireScript,
+		"consumeToken":      consumeTokenScript,
 	}
 
 	for name, script := range scripts {
@@ -261,6 +373,21 @@ func (c *Client) DecrStock(ctx context.Context, inventoryKey string, quantity in
 	return nil
 }
 
+// IncrStock atomically returns quantity units to an existing inventory key.
+// Returns error if the key doesn't exist.
+func (c *Client) IncrStock(ctx context.Context, inventoryKey string, quantity int64) error {
+	script, exists := c.scripts["incrStock"]
+	if !exists {
+		return fmt.Errorf("incrStock script not found")
+	}
+
+	if _, err := script.Run(ctx, c.rdb, []string{inventoryKey}, quantity).Result(); err != nil {
+		return fmt.Errorf("failed to execute incrStock script: %w", err)
+	}
+
+	return nil
+}
+
 // DecrStockWithUser performs atomic inventory deduction with user tracking.
 // Prevents duplicate purchases by the same user.
 func (c *Client) DecrStockWithUser(ctx context.Context, inventoryKey, userSetKey string, quantity, userID int64) error {
@@ -346,6 +473,123 @@ func (c *Client) IncrWithExpire(ctx context.Context, key string, expiration time
 	return count, nil
 }
 
+// Token consumption outcomes returned by ConsumeToken.
+const (
+	TokenMissing  = 0 // Neither the token nor a result exists
+	TokenConsumed = 1 // The token was consumed by this call; value is the token's value
+	TokenReplayed = 2 // The token was consumed earlier; value is what is stored under the result key
+)
+
+// ConsumeToken atomically consumes a one-time token.
+// On first use it deletes tokenKey and stores marker under resultKey for resultTTL.
+func (c *Client) ConsumeToken(ctx context.Context, tokenKey, resultKey, marker string,
+	resultTTL time.Duration,
+) (state int64, value string, err error) {
+	script, exists := c.scripts["consumeToken"]
+	if !exists {
+		return 0, "", fmt.Errorf("consumeToken script not found")
+	}
+
+	result, err := script.Run(ctx, c.rdb, []string{tokenKey, resultKey}, marker, int64(resultTTL.Seconds())).Result()
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to execute consumeToken script: %w", err)
+	}
+
+	resultSlice, ok := result.([]interface{})
+	if !ok || len(resultSlice) != 2 {
+		return 0, "", fmt.Errorf("unexpected script result format: %T", result)
+	}
+	state, ok = resultSlice[0].(int64)
+	if !ok {
+		return 0, "", fmt.Errorf("unexpected script state type: %T", resultSlice[0])
+	}
+	value, ok = resultSlice[1].(string)
+	if !ok {
+		return 0, "", fmt.Errorf("unexpected script value type: %T", resultSlice[1])
+	}
+
+	return state, value, nil
+}
+
+// ClaimKey stores marker under key for ttl unless the key exists.
+// It returns the existing value, or an empty string if this call claimed the key.
+func (c *Client) ClaimKey(ctx context.Context, key, marker string, ttl time.Duration) (string, error) {
+	script, exists := c.scripts["claimKey"]
+	if !exists {
+		return "", fmt.Errorf("claimKey script not found")
+	}
+
+	result, err := script.Run(ctx, c.rdb, []string{key}, marker, ttl.Milliseconds()).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to execute claimKey script: %w", err)
+	}
+
+	value, ok := result.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected script result type: %T", result)
+	}
+	return value, nil
+}
+
+// RenewKey extends the expiration of key to ttl if it still holds marker.
+// It reports whether the key was renewed.
+func (c *Client) RenewKey(ctx context.Context, key, marker string, ttl time.Duration) (bool, error) {
+	return c.runMarkerScript(ctx, "renewKey", key, marker, ttl.Milliseconds())
+}
+
+// ReleaseKey deletes key if it still holds marker. It reports whether the key was deleted.
+func (c *Client) ReleaseKey(ctx context.Context, key, marker string) (bool, error) {
+	return c.runMarkerScript(ctx, "releaseKey", key, marker)
+}
+
+// SwapKey replaces the value of key with value, expiring after ttl, if it still holds marker.
+// It reports whether the key was replaced.
+func (c *Client) SwapKey(ctx context.Context, key, marker, value string, ttl time.Duration) (bool, error) {
+	return c.runMarkerScript(ctx, "swapKey", key, marker, value, ttl.Milliseconds())
+}
+
+// SwapHashField sets field of the hash at key to newValue if it still holds oldValue, and extends
+// the expiration of the hash to ttl. It reports whether the field was swapped; it is not when the
+// hash or field is missing.
+func (c *Client) SwapHashField(ctx context.Context, key, field, oldValue, newValue string,
+	ttl time.Duration,
+) (bool, error) {
+	script, exists := c.scripts["swapHashField"]
+	if !exists {
+		return false, fmt.Errorf("swapHashField script not found")
+	}
+
+	result, err := script.Run(ctx, c.rdb, []string{key}, field, oldValue, newValue, ttl.Milliseconds()).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to execute swapHashField script: %w", err)
+	}
+
+	n, ok := result.(int64)
+	if !ok {
+		return false, fmt.Errorf("unexpected script result type: %T", result)
+	}
+	return n == 1, nil
+}
+
+// runMarkerScript runs a script that acts on key only while it holds marker and returns 1 if it did.
+func (c *Client) runMarkerScript(ctx context.Context, name, key, marker string, args ...interface{}) (bool, error) {
+	script, exists := c.scripts[name]
+	if !exists {
+		return false, fmt.Errorf("%s script not found", name)
+	}
+
+	result, err := script.Run(ctx, c.rdb, []string{key}, append([]interface{}{marker}, args...)...).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to execute %s script: %w", name, err)
+	}
+
+	n, ok := result.(int64)
+	if !ok {
+		return false, fmt.Errorf("unexpected script result type: %T", result)
+	}
+	return n == 1, nil
+}
+
 // ExecuteScript executes a custom Lua script.
 func (c *Client) ExecuteScript(ctx context.Context, script string, keys []string,
 	args ...interface{},
@@ -409,6 +653,11 @@ func (c *Client) HGetAll(ctx context.Context, key string) (map[string]string, er
 	return c.rdb.HGetAll(ctx, key).Result()
 }
 
+// HDel deletes fields from a hash.
+func (c *Client) HDel(ctx context.Context, key string, fields ...string) error {
+	return c.rdb.HDel(ctx, key, fields...).Err()
+}
+
 // SAdd adds members to a set.
 func (c *Client) SAdd(ctx context.Context, key string, members ...interface{}) error {
 	return c.rdb.SAdd(ctx, key, members...).Err()
@@ -437,6 +686,26 @@ func (k *KeyNamingHelper) UserPurchaseKey(courseID int64) string {
 	return fmt.Sprintf("promotion:purchased:%d", courseID)
 }
 
+// IdempotencyTokenKey generates a key for a one-time request token of a user.
+func (k *KeyNamingHelper) IdempotencyTokenKey(scope string, userID int64, token string) string {
+	return fmt.Sprintf("idempotency:%s:token:%d:%s", scope, userID, token)
+}
+
+// IdempotencyResultKey generates a key for the cached outcome of a one-time request token.
+func (k *KeyNamingHelper) IdempotencyResultKey(scope string, userID int64, token string) string {
+	return fmt.Sprintf("idempotency:%s:result:%d:%s", scope, userID, token)
+}
+
+// ConsumedMessageKey generates a key recording that a consumer group processed a message.
+func (k *KeyNamingHelper) ConsumedMessageKey(group, messageKey string) string {
+	return fmt.Sprintf("mq:consumed:%s:%s", group, messageKey)
+}
+
+// SnowflakeWorkerKey generates a key for the lease on a snowflake worker ID within a namespace.
+func (k *KeyNamingHelper) SnowflakeWorkerKey(namespace string, workerID int64) string {
+	return fmt.Sprintf("snowflake:worker:%s:%d", namespace, workerID)
+}
+
 // OrderLockKey generates a key for order processing locks.
 func (k *KeyNamingHelper) OrderLockKey(orderID int64) string {
 	return fmt.Sprintf("trade:lock:%d", orderID)
@@ -447,6 +716,11 @@ func (k *KeyNamingHelper) UserSessionKey(userID int64) string {
 	return fmt.Sprintf("user:session:%d", userID)
 }
 
+// LoginCodeKey generates a key for one-time login verification codes.
+func (k *KeyNamingHelper) LoginCodeKey(mobile string) string {
+	return fmt.Sprintf("user:logincode:%s", mobile)
+}
+
 // RateLimitKey generates a key for rate limiting.
 func (k *KeyNamingHelper) RateLimitKey(userID int64, action string) string {
 	return fmt.Sprintf("ratelimit:%s:%d", action, userID)
@@ -146,6 +146,18 @@ func TestClient_HashOperations(t *testing.T) {
 	if allFields["field1"] != testValue1 || allFields["field2"] != "value2" {
 		t.Errorf("HGetAll() = %v, want field1:%s, field2:value2", allFields, testValue1)
 	}
+
+	// Test HDel
+	if err = client.HDel(ctx, "test:hash", "field1"); err != nil {
+		t.Errorf("HDel() error = %v", err)
+	}
+	allFields, err = client.HGetAll(ctx, "test:hash")
+	if err != nil {
+		t.Errorf("HGetAll() error = %v", err)
+	}
+	if _, ok := allFields["field1"]; ok || len(allFields) != 1 {
+		t.Errorf("HGetAll() after HDel = %v, want only field2", allFields)
+	}
 }
 
 func TestClient_SetOperations(t *testing.T) {
@@ -228,6 +240,194 @@ func TestClient_DecrStock(t *testing.T) {
 	}
 }
 
+func TestClient_IncrStock(t *testing.T) {
+	client := setupTestClient(t)
+	defer func() {
+		if err := client.Close(); err != nil {
+			t.Logf("Warning: failed to close Redis client: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	inventoryKey := "test:inventory:456"
+
+	err := client.Set(ctx, inventoryKey, "10", time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to set initial inventory: %v", err)
+	}
+
+	err = client.IncrStock(ctx, inventoryKey, 2)
+	if err != nil {
+		t.Errorf("IncrStock() error = %v", err)
+	}
+
+	remaining, err := client.Get(ctx, inventoryKey)
+	if err != nil {
+		t.Errorf("Get() error = %v", err)
+	}
+	if remaining != "12" {
+		t.Errorf("Remaining inventory = %v, want 12", remaining)
+	}
+
+	// Restoring stock must not create inventory that was never preheated
+	err = client.IncrStock(ctx, "nonexistent:key", 1)
+	if err == nil {
+		t.Errorf("IncrStock() expected error for non-existent key")
+	}
+}
+
+func TestClient_ConsumeToken(t *testing.T) {
+	client := setupTestClient(t)
+	defer func() {
+		if err := client.Close(); err != nil {
+			t.Logf("Warning: failed to close Redis client: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	tokenKey, resultKey := "test:token", "test:token:result"
+
+	err := client.Set(ctx, tokenKey, "payload", time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to set token: %v", err)
+	}
+
+	state, value, err := client.ConsumeToken(ctx, tokenKey, resultKey, "pending", time.Minute)
+	if err != nil || state != TokenConsumed || value != "payload" {
+		t.Fatalf("ConsumeToken() = (%d, %q, %v), want (%d, payload, nil)", state, value, err, TokenConsumed)
+	}
+
+	state, value, err = client.ConsumeToken(ctx, tokenKey, resultKey, "pending", time.Minute)
+	if err != nil || state != TokenReplayed || value != "pending" {
+		t.Fatalf("ConsumeToken() replay = (%d, %q, %v), want (%d, pending, nil)", state, value, err, TokenReplayed)
+	}
+
+	state, _, err = client.ConsumeToken(ctx, "test:unknown", "test:unknown:result", "pending", time.Minute)
+	if err != nil || state != TokenMissing {
+		t.Fatalf("ConsumeToken() unknown = (%d, %v), want (%d, nil)", state, err, TokenMissing)
+	}
+}
+
+func TestClient_ClaimKey(t *testing.T) {
+	client := setupTestClient(t)
+	defer func() {
+		if err := client.Close(); err != nil {
+			t.Logf("Warning: failed to close Redis client: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	key := "test:claim"
+
+	existing, err := client.ClaimKey(ctx, key, "pending", time.Minute)
+	if err != nil || existing != "" {
+		t.Fatalf("ClaimKey() = (%q, %v), want (\"\", nil)", existing, err)
+	}
+
+	existing, err = client.ClaimKey(ctx, key, "pending", time.Minute)
+	if err != nil || existing != "pending" {
+		t.Fatalf("ClaimKey() again = (%q, %v), want (pending, nil)", existing, err)
+	}
+}
+
+func TestClient_RenewAndReleaseKey(t *testing.T) {
+	client := setupTestClient(t)
+	defer func() {
+		if err := client.Close(); err != nil {
+			t.Logf("Warning: failed to close Redis client: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	key := "test:lease"
+
+	if _, err := client.ClaimKey(ctx, key, "owner-a", time.Second); err != nil {
+		t.Fatalf("ClaimKey() error = %v", err)
+	}
+
+	if renewed, err := client.RenewKey(ctx, key, "owner-b", time.Minute); err != nil || renewed {
+		t.Fatalf("RenewKey() by another owner = (%v, %v), want (false, nil)", renewed, err)
+	}
+	if renewed, err := client.RenewKey(ctx, key, "owner-a", time.Minute); err != nil || !renewed {
+		t.Fatalf("RenewKey() = (%v, %v), want (true, nil)", renewed, err)
+	}
+	if ttl := client.rdb.PTTL(ctx, key).Val(); ttl <= time.Second {
+		t.Errorf("PTTL after RenewKey() = %v, want more than 1s", ttl)
+	}
+
+	if released, err := client.ReleaseKey(ctx, key, "owner-b"); err != nil || released {
+		t.Fatalf("ReleaseKey() by another owner = (%v, %v), want (false, nil)", released, err)
+	}
+	if released, err := client.ReleaseKey(ctx, key, "owner-a"); err != nil || !released {
+		t.Fatalf("ReleaseKey() = (%v, %v), want (true, nil)", released, err)
+	}
+	if renewed, err := client.RenewKey(ctx, key, "owner-a", time.Minute); err != nil || renewed {
+		t.Fatalf("RenewKey() after release = (%v, %v), want (false, nil)", renewed, err)
+	}
+}
+
+func TestClient_SwapKey(t *testing.T) {
+	client := setupTestClient(t)
+	defer func() {
+		if err := client.Close(); err != nil {
+			t.Logf("Warning: failed to close Redis client: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	key := "test:swap-key"
+
+	if swapped, err := client.SwapKey(ctx, key, "owner-a", "done", time.Minute); err != nil || swapped {
+		t.Fatalf("SwapKey() of a missing key = (%v, %v), want (false, nil)", swapped, err)
+	}
+	if _, err := client.ClaimKey(ctx, key, "owner-a", time.Second); err != nil {
+		t.Fatalf("ClaimKey() error = %v", err)
+	}
+	if swapped, err := client.SwapKey(ctx, key, "owner-b", "done", time.Minute); err != nil || swapped {
+		t.Fatalf("SwapKey() by another owner = (%v, %v), want (false, nil)", swapped, err)
+	}
+	if swapped, err := client.SwapKey(ctx, key, "owner-a", "done", time.Minute); err != nil || !swapped {
+		t.Fatalf("SwapKey() = (%v, %v), want (true, nil)", swapped, err)
+	}
+	if value := client.rdb.Get(ctx, key).Val(); value != "done" {
+		t.Errorf("value after SwapKey() = %q, want done", value)
+	}
+	if ttl := client.rdb.PTTL(ctx, key).Val(); ttl <= time.Second {
+		t.Errorf("PTTL after SwapKey() = %v, want more than 1s", ttl)
+	}
+}
+
+func TestClient_SwapHashField(t *testing.T) {
+	client := setupTestClient(t)
+	defer func() {
+		if err := client.Close(); err != nil {
+			t.Logf("Warning: failed to close Redis client: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	key := "test:swap"
+
+	if swapped, err := client.SwapHashField(ctx, key, "field", "", "v1", time.Minute); err != nil || swapped {
+		t.Fatalf("SwapHashField() of a missing hash = (%v, %v), want (false, nil)", swapped, err)
+	}
+	if err := client.HSet(ctx, key, "field", "v1"); err != nil {
+		t.Fatalf("HSet() error = %v", err)
+	}
+	if swapped, err := client.SwapHashField(ctx, key, "field", "v0", "v2", time.Minute); err != nil || swapped {
+		t.Fatalf("SwapHashField() of a changed field = (%v, %v), want (false, nil)", swapped, err)
+	}
+	if swapped, err := client.SwapHashField(ctx, key, "field", "v1", "v2", time.Minute); err != nil || !swapped {
+		t.Fatalf("SwapHashField() = (%v, %v), want (true, nil)", swapped, err)
+	}
+	if value, err := client.HGet(ctx, key, "field"); err != nil || value != "v2" {
+		t.Errorf("HGet() after SwapHashField() = (%q, %v), want (v2, nil)", value, err)
+	}
+	if ttl := client.rdb.PTTL(ctx, key).Val(); ttl <= 0 {
+		t.Errorf("PTTL after SwapHashField() = %v, want an expiry", ttl)
+	}
+}
+
 func TestClient_DecrStockWithUser(t *testing.T) {
 	client := setupTestClient(t)
 	defer func() {
@@ -455,6 +655,31 @@ func TestKeyNamingHelper(t *testing.T) {
 			method:   func() string { return helper.UserSessionKey(999) },
 			expected: "user:session:999",
 		},
+		{
+			name:     "LoginCodeKey",
+			method:   func() string { return helper.LoginCodeKey("13800138000") },
+			expected: "user:logincode:13800138000",
+		},
+		{
+			name:     "IdempotencyTokenKey",
+			method:   func() string { return helper.IdempotencyTokenKey("checkout", 7, "abc") },
+			expected: "idempotency:checkout:token:7:abc",
+		},
+		{
+			name:     "IdempotencyResultKey",
+			method:   func() string { return helper.IdempotencyResultKey("checkout", 7, "abc") },
+			expected: "idempotency:checkout:result:7:abc",
+		},
+		{
+			name:     "ConsumedMessageKey",
+			method:   func() string { return helper.ConsumedMessageKey("promotion", "evt-1") },
+			expected: "mq:consumed:promotion:evt-1",
+		},
+		{
+			name:     "SnowflakeWorkerKey",
+			method:   func() string { return helper.SnowflakeWorkerKey("trade", 42) },
+			expected: "snowflake:worker:trade:42",
+		},
 		{
 			name:     "RateLimitKey",
 			method:   func() string { return helper.RateLimitKey(111, "login") },
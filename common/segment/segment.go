@@ -0,0 +1,223 @@
+// Package segment generates dense, increasing IDs from ranges reserved in the database, as an
+// alternative to snowflake IDs for records with short, human-facing numbers.
+//
+// Each business tag has a row in the id_segment table holding the last ID handed out. A Generator
+// reserves a segment of IDs at a time by advancing the row, and serves IDs from memory until the
+// segment runs out. Segments are double-buffered: once part of the current segment is used, the
+// next one is reserved in the background, so that Next rarely waits for the database.
+//
+// IDs are unique and increase within a process. Processes sharing a tag interleave their
+// segments, and a process that stops discards the rest of its segments, so IDs have gaps.
+package segment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+// Defaults of an Allocator.
+const (
+	defaultLoadThreshold = 0.1
+	defaultLoadTimeout   = 3 * time.Second
+)
+
+// Store reserves segments of IDs. *database.IDSegmentStore implements it.
+type Store interface {
+	// Allocate reserves the next segment of bizTag: the Step IDs up to and including MaxID.
+	Allocate(ctx context.Context, bizTag string) (*database.IDSegment, error)
+}
+
+// IDGenerator is the Next method shared by *Generator and *snowflake.Generator, so that services
+// can take the IDs of a kind of record from either by configuration.
+type IDGenerator interface {
+	Next() (int64, error)
+}
+
+// Config selects where a service takes the IDs of a kind of record from.
+type Config struct {
+	// BizTag is the id_segment business tag IDs are allocated from. Snowflake IDs are used instead
+	// when it is empty.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	BizTag string `json:"bizTag,optional" yaml:"bizTag"`
+}
+
+// Enabled reports whether IDs come from an ID segment.
+func (c *Config) Enabled() bool {
+	return c.BizTag != ""
+}
+
+// Allocator creates the Generators of business tags, sharing a Store.
+type Allocator struct {
+	store         Store
+	generators    map[string]*Generator
+	loadThreshold float64
+	loadTimeout   time.Duration
+	mu            sync.Mutex
+}
+
+// Option configures an Allocator.
+type Option func(a *Allocator)
+
+// WithLoadThreshold sets the fraction of a segment used before the next one is reserved (default:
+// 0.1). Reserving early leaves the rest of the segment to absorb a slow database.
+func WithLoadThreshold(threshold float64) Option {
+	return func(a *Allocator) {
+		a.loadThreshold = threshold
+	}
+}
+
+// WithLoadTimeout bounds how long reserving a segment may take (default: 3s).
+func WithLoadTimeout(timeout time.Duration) Option {
+	return func(a *Allocator) {
+		a.loadTimeout = timeout
+	}
+}
+
+// NewAllocator creates an Allocator reserving segments from store.
+func NewAllocator(store Store, opts ...Option) (*Allocator, error) {
+	if store == nil {
+		return nil, fmt.Errorf("segment store cannot be nil")
+	}
+	a := &Allocator{
+		store:         store,
+		generators:    make(map[string]*Generator),
+		loadThreshold: defaultLoadThreshold,
+		loadTimeout:   defaultLoadTimeout,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.loadThreshold <= 0 || a.loadThreshold > 1 {
+		return nil, fmt.Errorf("load threshold must be in (0, 1], got %v", a.loadThreshold)
+	}
+	if a.loadTimeout <= 0 {
+		return nil, fmt.Errorf("load timeout must be positive, got %v", a.loadTimeout)
+	}
+	return a, nil
+}
+
+// Generator returns the generator of bizTag, creating it on first use. No segment is reserved
+// until the first ID is requested.
+func (a *Allocator) Generator(bizTag string) *Generator {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	g, ok := a.generators[bizTag]
+	if !ok {
+		g = &Generator{alloc: a, bizTag: bizTag}
+		a.generators[bizTag] = g
+	}
+	return g
+}
+
+// Next returns the next ID of bizTag.
+func (a *Allocator) Next(bizTag string) (int64, error) {
+	return a.Generator(bizTag).Next()
+}
+
+// Generator hands out the IDs of one business tag.
+type Generator struct {
+	alloc *Allocator
+	// current is the segment IDs are served from; spare is the one reserved after it, if any.
+	current *segment
+	spare   *segment
+	// load is the reservation in progress, if any.
+	load   *load
+	bizTag string
+	mu     sync.Mutex
+}
+
+// segment is a range of reserved IDs.
+type segment struct {
+	start int64 // First ID
+	next  int64 // Next ID to hand out
+	max   int64 // Last ID
+}
+
+// load is a reservation of a segment in progress.
+type load struct {
+	err  error         // Set before done is closed
+	done chan struct{} // Closed when the reservation finishes
+}
+
+// BizTag returns the business tag of the generator.
+func (g *Generator) BizTag() string {
+	return g.bizTag
+}
+
+// Next returns the next ID. It only waits for the database when no reserved ID is left, and fails
+// if the segment it waits for cannot be reserved.
+// This method is thread-safe and can be called concurrently.
+func (g *Generator) Next() (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for {
+		if cur := g.current; cur != nil && cur.next <= cur.max {
+			id := cur.next
+			cur.next++
+			if g.spare == nil && g.load == nil &&
+				float64(cur.next-cur.start) >= g.alloc.loadThreshold*float64(cur.max-cur.start+1) {
+				g.startLoad()
+			}
+			return id, nil
+		}
+
+		if g.spare != nil {
+			g.current, g.spare = g.spare, nil
+			continue
+		}
+
+		// Out of IDs: wait for the next segment.
+		if g.load == nil {
+			g.startLoad()
+		}
+		l := g.load
+		g.mu.Unlock()
+		<-l.done
+		g.mu.Lock()
+		if l.err != nil && g.spare == nil {
+			return 0, l.err
+		}
+	}
+}
+
+// startLoad reserves a spare segment in the background. g.mu must be held.
+func (g *Generator) startLoad() {
+	l := &load{done: make(chan struct{})}
+	g.load = l
+	go func() {
+		seg, err := g.reserve()
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if err != nil {
+			logx.Errorf("ID segment load failed: %v", err)
+			l.err = err
+		} else {
+			g.spare = seg
+		}
+		g.load = nil
+		close(l.done)
+	}()
+}
+
+// reserve reserves the next segment from the store.
+func (g *Generator) reserve() (*segment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.alloc.loadTimeout)
+	defer cancel()
+	row, err := g.alloc.store.Allocate(ctx, g.bizTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve ID segment of %s: %w", g.bizTag, err)
+	}
+	if row.Step <= 0 || row.MaxID < row.Step {
+		return nil, fmt.Errorf("invalid ID segment of %s: max ID %d, step %d", g.bizTag, row.MaxID, row.Step)
+	}
+	start := row.MaxID - row.Step + 1
+	return &segment{start: start, next: start, max: row.MaxID}, nil
+}
@@ -0,0 +1,239 @@
+package segment
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+// memoryStore is an in-memory Store.
+type memoryStore struct {
+	err    error
+	block  chan struct{} // When set, Allocate waits for it to be closed
+	maxIDs map[string]int64
+	steps  map[string]int64
+	calls  int
+	mu     sync.Mutex
+}
+
+func newMemoryStore(steps map[string]int64) *memoryStore {
+	return &memoryStore{maxIDs: make(map[string]int64), steps: steps}
+}
+
+func (s *memoryStore) Allocate(ctx context.Context, bizTag string) (*database.IDSegment, error) {
+	s.mu.Lock()
+	block := s.block
+	s.mu.Unlock()
+	if block != nil {
+		select {
+		case <-block:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	step, ok := s.steps[bizTag]
+	if !ok {
+		return nil, database.ErrUnknownBizTag
+	}
+	s.maxIDs[bizTag] += step
+	return &database.IDSegment{BizTag: bizTag, MaxID: s.maxIDs[bizTag], Step: step}, nil
+}
+
+func (s *memoryStore) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *memoryStore) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func newTestAllocator(t *testing.T, store Store, opts ...Option) *Allocator {
+	t.Helper()
+	a, err := NewAllocator(store, opts...)
+	require.NoError(t, err)
+	return a
+}
+
+func TestGenerator_DenseAndIncreasing(t *testing.T) {
+	store := newMemoryStore(map[string]int64{"refund": 10})
+	g := newTestAllocator(t, store).Generator("refund")
+
+	for want := int64(1); want <= 35; want++ {
+		id, err := g.Next()
+		require.NoError(t, err)
+		require.Equal(t, want, id)
+	}
+}
+
+func TestGenerator_PreloadsNextSegment(t *testing.T) {
+	store := newMemoryStore(map[string]int64{"refund": 10})
+	g := newTestAllocator(t, store, WithLoadThreshold(0.5)).Generator("refund")
+
+	for i := 0; i < 5; i++ {
+		_, err := g.Next()
+		require.NoError(t, err)
+	}
+	// Half of the first segment is used: the second one is reserved in the background.
+	assert.Eventually(t, func() bool { return store.callCount() == 2 }, time.Second, time.Millisecond)
+
+	// The database fails, but the spare segment carries on.
+	store.setErr(errors.New("database down"))
+	for want := int64(6); want <= 20; want++ {
+		id, err := g.Next()
+		require.NoError(t, err)
+		assert.Equal(t, want, id)
+	}
+
+	// Both segments used up: the error surfaces, until the database recovers.
+	assert.Eventually(t, func() bool {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		return g.load == nil
+	}, time.Second, time.Millisecond)
+	_, err := g.Next()
+	require.Error(t, err)
+
+	store.setErr(nil)
+	id, err := g.Next()
+	require.NoError(t, err)
+	assert.Greater(t, id, int64(20))
+}
+
+func TestGenerator_WaitersShareOneLoad(t *testing.T) {
+	store := newMemoryStore(map[string]int64{"refund": 1000})
+	block := make(chan struct{})
+	store.block = block
+	g := newTestAllocator(t, store).Generator("refund")
+
+	var wg sync.WaitGroup
+	ids := make([]int64, 50)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id, err := g.Next()
+			assert.NoError(t, err)
+			ids[i] = id
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(block)
+	wg.Wait()
+
+	seen := make(map[int64]bool)
+	for _, id := range ids {
+		assert.False(t, seen[id], "ID %d handed out twice", id)
+		seen[id] = true
+	}
+	// One reservation served every waiter; the threshold triggered at most one more.
+	assert.LessOrEqual(t, store.callCount(), 2)
+}
+
+func TestGenerator_Concurrent(t *testing.T) {
+	store := newMemoryStore(map[string]int64{"refund": 7})
+	g := newTestAllocator(t, store).Generator("refund")
+
+	const workers, perWorker = 8, 500
+	var mu sync.Mutex
+	seen := make(map[int64]bool)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := int64(0)
+			for i := 0; i < perWorker; i++ {
+				id, err := g.Next()
+				if !assert.NoError(t, err) {
+					return
+				}
+				assert.Greater(t, id, last, "IDs must increase")
+				last = id
+				mu.Lock()
+				assert.False(t, seen[id], "ID %d handed out twice", id)
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, workers*perWorker)
+}
+
+func TestAllocator_Tags(t *testing.T) {
+	store := newMemoryStore(map[string]int64{"refund": 100, "coupon": 50})
+	a := newTestAllocator(t, store)
+
+	assert.Same(t, a.Generator("refund"), a.Generator("refund"))
+	assert.Equal(t, "coupon", a.Generator("coupon").BizTag())
+
+	refund, err := a.Next("refund")
+	require.NoError(t, err)
+	coupon, err := a.Next("coupon")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), refund)
+	assert.Equal(t, int64(1), coupon)
+
+	_, err = a.Next("unknown")
+	assert.ErrorIs(t, err, database.ErrUnknownBizTag)
+}
+
+func TestGenerator_LoadTimeout(t *testing.T) {
+	store := newMemoryStore(map[string]int64{"refund": 10})
+	store.block = make(chan struct{})
+	g := newTestAllocator(t, store, WithLoadTimeout(20*time.Millisecond)).Generator("refund")
+
+	_, err := g.Next()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestGenerator_InvalidSegment(t *testing.T) {
+	store := newMemoryStore(map[string]int64{"refund": 0})
+	_, err := newTestAllocator(t, store).Next("refund")
+	assert.Error(t, err)
+}
+
+func TestNewAllocator_Invalid(t *testing.T) {
+	store := newMemoryStore(nil)
+	tests := []struct {
+		name  string
+		store Store
+		opts  []Option
+	}{
+		{name: "nil store"},
+		{name: "zero threshold", store: store, opts: []Option{WithLoadThreshold(0)}},
+		{name: "threshold above 1", store: store, opts: []Option{WithLoadThreshold(1.5)}},
+		{name: "zero timeout", store: store, opts: []Option{WithLoadTimeout(0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAllocator(tt.store, tt.opts...)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestConfig_Enabled(t *testing.T) {
+	var c Config
+	assert.False(t, c.Enabled())
+	c.BizTag = "trade_refund"
+	assert.True(t, c.Enabled())
+}
@@ -0,0 +1,67 @@
+package snowflake
+
+import (
+	"fmt"
+	"time"
+)
+
+// Default clock rollback tolerance of a Generator.
+const (
+	defaultMaxRollbackWait = 5 * time.Millisecond
+	defaultMaxRollback     = time.Second
+)
+
+// Clock tells a Generator the time. Tests substitute fakes to move it at will.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// systemClock is the Clock of the operating system.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time        { return time.Now() }
+func (systemClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// ClockRollbackError is returned by Next when the clock moved back further than the generator
+// tolerates.
+type ClockRollbackError struct {
+	// Rollback is how far the clock is behind the last ID issued.
+	Rollback time.Duration
+	// Limit is the largest rollback the generator tolerates.
+	Limit time.Duration
+}
+
+func (e *ClockRollbackError) Error() string {
+	return fmt.Sprintf("clock moved backwards by %v, more than the %v tolerated", e.Rollback, e.Limit)
+}
+
+// WithClock sets the clock IDs are timestamped with (default: the system clock).
+func WithClock(clock Clock) Option {
+	return func(g *Generator) {
+		g.clock = clock
+	}
+}
+
+// WithRollbackTolerance sets how the generator bears with a clock that moved backwards, as NTP
+// corrections do. Rollbacks up to maxWait (default: 5ms) are waited out. Larger ones, up to limit
+// (default: 1s), are bridged: IDs come from the backup worker ID if one is set and unused at the
+// current time, and otherwise borrow sequence numbers from the millisecond of the last ID, running
+// ahead of the clock until it catches up. Beyond limit, Next fails with a *ClockRollbackError.
+//
+// A zero limit restores the strict behaviour of failing on any rollback.
+func WithRollbackTolerance(maxWait, limit time.Duration) Option {
+	return func(g *Generator) {
+		g.maxRollbackWait = maxWait
+		g.maxRollback = limit
+	}
+}
+
+// WithBackupWorkerID reserves a second worker ID for IDs issued while the clock is behind, so that
+// they keep the current time instead of borrowing from the future. Like the primary worker ID, it
+// must not be used by any other process.
+func WithBackupWorkerID(workerID int64) Option {
+	return func(g *Generator) {
+		g.backup = &workerState{workerID: workerID}
+	}
+}
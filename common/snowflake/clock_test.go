@@ -0,0 +1,184 @@
+package snowflake
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock that only moves when told to or when slept on.
+type fakeClock struct {
+	now   time.Time
+	slept time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.UnixMilli(Epoch + 1_000_000)}
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	if d > 0 {
+		c.now = c.now.Add(d)
+		c.slept += d
+	}
+}
+
+func (c *fakeClock) move(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestGenerator(t *testing.T, clock *fakeClock, opts ...Option) *Generator {
+	t.Helper()
+	gen, err := NewGenerator(1, append([]Option{WithClock(clock)}, opts...)...)
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+	return gen
+}
+
+func mustNextID(t *testing.T, gen *Generator) int64 {
+	t.Helper()
+	id, err := gen.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	return id
+}
+
+func TestGenerator_SmallRollbackIsWaitedOut(t *testing.T) {
+	clock := newFakeClock()
+	gen := newTestGenerator(t, clock, WithRollbackTolerance(5*time.Millisecond, time.Second))
+	first := mustNextID(t, gen)
+
+	clock.move(-3 * time.Millisecond)
+	second := mustNextID(t, gen)
+
+	if clock.slept != 3*time.Millisecond {
+		t.Errorf("slept %v, want 3ms", clock.slept)
+	}
+	if second <= first {
+		t.Errorf("IDs not increasing: %d -> %d", first, second)
+	}
+}
+
+func TestGenerator_LargeRollbackBorrowsSequence(t *testing.T) {
+	clock := newFakeClock()
+	gen := newTestGenerator(t, clock, WithRollbackTolerance(5*time.Millisecond, time.Second))
+	first := mustNextID(t, gen)
+	firstTS, _, _ := ParseID(first)
+
+	clock.move(-100 * time.Millisecond)
+	second := mustNextID(t, gen)
+	ts, worker, seq := ParseID(second)
+
+	if clock.slept != 0 {
+		t.Errorf("slept %v, want no wait beyond the tolerance", clock.slept)
+	}
+	if ts != firstTS || worker != 1 || seq != 1 {
+		t.Errorf("ParseID() = (%d, %d, %d), want the next sequence of (%d, 1)", ts, worker, seq, firstTS)
+	}
+
+	// Running out of sequence numbers borrows the next millisecond instead of waiting.
+	gen.mu.Lock()
+	gen.sequence = MaxSequence
+	gen.mu.Unlock()
+	third := mustNextID(t, gen)
+	if ts, _, seq := ParseID(third); ts != firstTS+1 || seq != 0 {
+		t.Errorf("ParseID() after overflow = (%d, %d), want (%d, 0)", ts, seq, firstTS+1)
+	}
+	if clock.slept != 0 {
+		t.Errorf("slept %v while borrowing", clock.slept)
+	}
+}
+
+func TestGenerator_BorrowingIsBounded(t *testing.T) {
+	clock := newFakeClock()
+	gen := newTestGenerator(t, clock, WithRollbackTolerance(time.Millisecond, 10*time.Millisecond))
+	mustNextID(t, gen)
+	clock.move(-5 * time.Millisecond)
+
+	// Every 4096 IDs borrow another millisecond, until the lead exceeds the limit.
+	var err error
+	for i := 0; i < 10*(MaxSequence+1) && err == nil; i++ {
+		_, err = gen.Next()
+	}
+	var rollbackErr *ClockRollbackError
+	if !errors.As(err, &rollbackErr) {
+		t.Fatalf("Next() error = %v, want *ClockRollbackError", err)
+	}
+	if rollbackErr.Limit != 10*time.Millisecond {
+		t.Errorf("Limit = %v, want 10ms", rollbackErr.Limit)
+	}
+}
+
+func TestGenerator_RollbackUsesBackupWorker(t *testing.T) {
+	clock := newFakeClock()
+	gen := newTestGenerator(t, clock, WithRollbackTolerance(5*time.Millisecond, time.Second), WithBackupWorkerID(7))
+	first := mustNextID(t, gen)
+	firstTS, _, _ := ParseID(first)
+
+	clock.move(-100 * time.Millisecond)
+	backupID := mustNextID(t, gen)
+	if ts, worker, _ := ParseID(backupID); worker != 7 || ts != firstTS-100 {
+		t.Errorf("ParseID() during rollback = (%d, %d), want (%d, 7)", ts, worker, firstTS-100)
+	}
+
+	// The clock catches up; the primary worker takes over again.
+	clock.move(101 * time.Millisecond)
+	if _, worker, _ := ParseID(mustNextID(t, gen)); worker != 1 {
+		t.Errorf("worker after the clock caught up = %d, want 1", worker)
+	}
+
+	// A second rollback to before the last backup ID cannot use the backup worker, and borrows.
+	clock.move(-150 * time.Millisecond)
+	if _, worker, _ := ParseID(mustNextID(t, gen)); worker != 1 {
+		t.Errorf("worker when the backup is used up = %d, want 1", worker)
+	}
+}
+
+func TestGenerator_RollbackBeyondLimit(t *testing.T) {
+	clock := newFakeClock()
+	gen := newTestGenerator(t, clock)
+	mustNextID(t, gen)
+
+	clock.move(-2 * time.Second)
+	_, err := gen.Next()
+	var rollbackErr *ClockRollbackError
+	if !errors.As(err, &rollbackErr) {
+		t.Fatalf("Next() error = %v, want *ClockRollbackError", err)
+	}
+	if rollbackErr.Rollback != 2*time.Second || rollbackErr.Limit != time.Second {
+		t.Errorf("ClockRollbackError = %+v, want a 2s rollback over a 1s limit", rollbackErr)
+	}
+}
+
+func TestGenerator_StrictRollback(t *testing.T) {
+	clock := newFakeClock()
+	gen := newTestGenerator(t, clock, WithRollbackTolerance(0, 0))
+	mustNextID(t, gen)
+
+	clock.move(-time.Millisecond)
+	var rollbackErr *ClockRollbackError
+	if _, err := gen.Next(); !errors.As(err, &rollbackErr) {
+		t.Fatalf("Next() error = %v, want *ClockRollbackError", err)
+	}
+}
+
+func TestNewGenerator_InvalidRollbackOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "nil clock", opts: []Option{WithClock(nil)}},
+		{name: "negative tolerance", opts: []Option{WithRollbackTolerance(-time.Millisecond, time.Second)}},
+		{name: "backup is the worker", opts: []Option{WithBackupWorkerID(1)}},
+		{name: "backup out of range", opts: []Option{WithBackupWorkerID(MaxWorkerID + 1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGenerator(1, tt.opts...); err == nil {
+				t.Error("NewGenerator() should fail")
+			}
+		})
+	}
+}
@@ -1,10 +1,16 @@
 package snowflake
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/aether-defense-system/common/redis"
 )
 
 // Config holds configuration for Snowflake ID generation.
@@ -18,7 +24,8 @@ type Config struct {
 //   - HOSTNAME: Kubernetes pod hostname (used to extract StatefulSet ordinal)
 //   - POD_NAME: Kubernetes pod name (alternative to HOSTNAME)
 //
-// If none of these are set, it defaults to worker ID 0.
+// If none of these are set, it defaults to worker ID 0. Deployments whose pods have no stable
+// ordinal should lease worker IDs instead; see LeaseConfig.
 func NewConfigFromEnv() (*Config, error) {
 	// Try direct worker ID specification first
 	if workerIDStr := os.Getenv("SNOWFLAKE_WORKER_ID"); workerIDStr != "" {
@@ -109,3 +116,106 @@ func InitializeDefault() error {
 	DefaultGenerator = generator
 	return nil
 }
+
+// defaultLeaseNamespace is the namespace worker IDs are leased in unless configured otherwise.
+const defaultLeaseNamespace = "default"
+
+// etcdDialTimeout bounds connecting to etcd for leasing.
+const etcdDialTimeout = 5 * time.Second
+
+// LeaseConfig configures leasing worker IDs from Redis or etcd. Exactly one of them is set when
+// leasing is enabled.
+type LeaseConfig struct {
+	// EtcdHosts are the etcd endpoints worker IDs are leased from.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	EtcdHosts []string `json:"etcdHosts,optional" yaml:"etcdHosts"`
+	// Namespace scopes worker IDs (default: "default"). Processes whose IDs can meet in one table
+	// must share a namespace.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Namespace string `json:"namespace,optional" yaml:"namespace"`
+	// Redis is the Redis server worker IDs are leased from.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Redis redis.Config `json:"redis,optional" yaml:"redis"`
+	// TTL is how long a lease lasts without heartbeats (default: 30s).
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	TTL time.Duration `json:"ttl,optional" yaml:"ttl"`
+}
+
+// Enabled reports whether a lease store is configured.
+func (c *LeaseConfig) Enabled() bool {
+	return c.Redis.Addr != "" || c.Redis.Host != "" || len(c.EtcdHosts) > 0
+}
+
+// GetNamespace returns the namespace, defaulting to "default".
+func (c *LeaseConfig) GetNamespace() string {
+	if c.Namespace == "" {
+		return defaultLeaseNamespace
+	}
+	return c.Namespace
+}
+
+// Acquire connects to the configured store and leases a worker ID from it. Releasing the lease
+// closes the connection.
+func (c *LeaseConfig) Acquire(ctx context.Context) (*WorkerLease, error) {
+	store, closeStore, err := c.newStore()
+	if err != nil {
+		return nil, err
+	}
+	lease, err := AcquireWorkerID(ctx, store, WithLeaseTTL(c.TTL))
+	if err != nil {
+		_ = closeStore()
+		return nil, err
+	}
+	lease.closeStore = closeStore
+	return lease, nil
+}
+
+// newStore connects to the configured store.
+func (c *LeaseConfig) newStore() (LeaseStore, func() error, error) {
+	switch {
+	case len(c.EtcdHosts) > 0 && (c.Redis.Addr != "" || c.Redis.Host != ""):
+		return nil, nil, fmt.Errorf("configure either Redis or etcd for worker ID leasing, not both")
+	case len(c.EtcdHosts) > 0:
+		cli, err := clientv3.New(clientv3.Config{Endpoints: c.EtcdHosts, DialTimeout: etcdDialTimeout})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to etcd: %w", err)
+		}
+		store, err := NewEtcdLeaseStore(cli, c.GetNamespace())
+		if err != nil {
+			_ = cli.Close()
+			return nil, nil, err
+		}
+		return store, cli.Close, nil
+	case c.Redis.Addr != "" || c.Redis.Host != "":
+		rdb, err := redis.NewClient(&c.Redis)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		store, err := NewRedisLeaseStore(rdb, c.GetNamespace())
+		if err != nil {
+			_ = rdb.Close()
+			return nil, nil, err
+		}
+		return store, rdb.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("no worker ID lease store configured")
+	}
+}
+
+// InitializeDefaultFromLease leases a worker ID as configured and replaces the default generator
+// with one fenced by the lease. Release the returned lease on shutdown.
+func InitializeDefaultFromLease(ctx context.Context, c *LeaseConfig) (*WorkerLease, error) {
+	lease, err := c.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lease snowflake worker ID: %w", err)
+	}
+
+	generator, err := NewGenerator(lease.WorkerID(), WithFence(lease.Check))
+	if err != nil {
+		_ = lease.Release(ctx)
+		return nil, fmt.Errorf("failed to create snowflake generator: %w", err)
+	}
+
+	DefaultGenerator = generator
+	return lease, nil
+}
@@ -39,22 +39,69 @@ const (
 
 // Generator represents a Snowflake ID generator instance.
 type Generator struct {
-	mu            sync.Mutex
+	clock Clock
+	fence func() error
+	// backup issues IDs while the clock is behind the primary worker; nil unless configured.
+	backup *workerState
+	workerState
+	maxRollbackWait time.Duration
+	maxRollback     time.Duration
+	mu              sync.Mutex
+}
+
+// workerState is the last ID issued with a worker ID.
+type workerState struct {
 	workerID      int64
 	sequence      int64
 	lastTimestamp int64
 }
 
+// Option configures a Generator.
+type Option func(g *Generator)
+
+// WithFence makes the generator call fence before every ID and fail with its error, if any.
+// Use it with WorkerLease.Check so that a generator stops once its worker ID may be reused.
+func WithFence(fence func() error) Option {
+	return func(g *Generator) {
+		g.fence = fence
+	}
+}
+
 // NewGenerator creates a new Snowflake ID generator with the specified worker ID.
 // The worker ID must be between 0 and 1023 (inclusive).
-func NewGenerator(workerID int64) (*Generator, error) {
+func NewGenerator(workerID int64, opts ...Option) (*Generator, error) {
 	if workerID < 0 || workerID > MaxWorkerID {
 		return nil, fmt.Errorf("worker ID must be between 0 and %d, got %d", MaxWorkerID, workerID)
 	}
 
-	return &Generator{
-		workerID: workerID,
-	}, nil
+	g := &Generator{
+		workerState:     workerState{workerID: workerID},
+		clock:           systemClock{},
+		maxRollbackWait: defaultMaxRollbackWait,
+		maxRollback:     defaultMaxRollback,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	if g.clock == nil {
+		return nil, fmt.Errorf("clock cannot be nil")
+	}
+	if g.maxRollbackWait < 0 || g.maxRollback < 0 {
+		return nil, fmt.Errorf("clock rollback tolerance cannot be negative")
+	}
+	if g.maxRollbackWait > g.maxRollback {
+		g.maxRollbackWait = g.maxRollback
+	}
+	if g.backup != nil {
+		if g.backup.workerID < 0 || g.backup.workerID > MaxWorkerID {
+			return nil, fmt.Errorf("backup worker ID must be between 0 and %d, got %d", MaxWorkerID, g.backup.workerID)
+		}
+		if g.backup.workerID == workerID {
+			return nil, fmt.Errorf("backup worker ID must differ from worker ID %d", workerID)
+		}
+	}
+	return g, nil
 }
 
 // Next generates the next unique ID.
@@ -63,37 +110,107 @@ func (g *Generator) Next() (int64, error) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	if err := g.checkFence(); err != nil {
+		return 0, err
+	}
+	return g.next()
+}
+
+// NextN generates n unique IDs at once, e.g. for the rows of a bulk insert. The block is reserved
+// under a single lock, so the IDs are increasing and no concurrent call interleaves with them.
+func (g *Generator) NextN(n int) ([]int64, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("number of IDs must be positive, got %d", n)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.checkFence(); err != nil {
+		return nil, err
+	}
+	ids := make([]int64, n)
+	for i := range ids {
+		id, err := g.next()
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+// checkFence returns the error of the fence, if any.
+func (g *Generator) checkFence() error {
+	if g.fence != nil {
+		if err := g.fence(); err != nil {
+			return fmt.Errorf("refusing to generate ID: %w", err)
+		}
+	}
+	return nil
+}
+
+// next generates the next ID. g.mu must be held.
+func (g *Generator) next() (int64, error) {
+	worker := &g.workerState
 	timestamp := g.getCurrentTimestamp()
 
 	// Handle clock going backwards
 	if timestamp < g.lastTimestamp {
-		return 0, fmt.Errorf("clock moved backwards, refusing to generate ID for %d milliseconds",
-			g.lastTimestamp-timestamp)
+		var err error
+		worker, timestamp, err = g.tolerateRollback(timestamp)
+		if err != nil {
+			return 0, err
+		}
 	}
 
 	// Same millisecond as last ID generation
-	if timestamp == g.lastTimestamp {
-		g.sequence = (g.sequence + 1) & MaxSequence
+	if timestamp == worker.lastTimestamp {
+		worker.sequence = (worker.sequence + 1) & MaxSequence
 
-		// Sequence overflow - wait for next millisecond
-		if g.sequence == 0 {
-			timestamp = g.waitNextMillis(g.lastTimestamp)
+		// Sequence overflow - move on to the next millisecond
+		if worker.sequence == 0 {
+			timestamp = g.waitNextMillis(worker.lastTimestamp)
 		}
 	} else {
 		// New millisecond - reset sequence
-		g.sequence = 0
+		worker.sequence = 0
 	}
 
-	g.lastTimestamp = timestamp
+	worker.lastTimestamp = timestamp
 
 	// Construct the ID
 	id := ((timestamp - Epoch) << TimestampShift) |
-		(g.workerID << WorkerIDShift) |
-		g.sequence
+		(worker.workerID << WorkerIDShift) |
+		worker.sequence
 
 	return id, nil
 }
 
+// tolerateRollback picks the worker and timestamp of an ID when the clock, at timestamp, is behind
+// the last ID of the primary worker, or fails if it is too far behind.
+func (g *Generator) tolerateRollback(timestamp int64) (*workerState, int64, error) {
+	rollback := time.Duration(g.lastTimestamp-timestamp) * time.Millisecond
+	if rollback <= g.maxRollbackWait {
+		g.clock.Sleep(rollback)
+		timestamp = g.getCurrentTimestamp()
+		if timestamp >= g.lastTimestamp {
+			return &g.workerState, timestamp, nil
+		}
+		// Still behind, e.g. because the clock moved back again.
+		rollback = time.Duration(g.lastTimestamp-timestamp) * time.Millisecond
+	}
+	if rollback > g.maxRollback {
+		return nil, 0, &ClockRollbackError{Rollback: rollback, Limit: g.maxRollback}
+	}
+
+	if g.backup != nil && timestamp >= g.backup.lastTimestamp {
+		return g.backup, timestamp, nil
+	}
+	// Borrow: carry on in the millisecond of the last ID, which is ahead of the clock.
+	return &g.workerState, g.lastTimestamp, nil
+}
+
 // MustNext generates the next unique ID and panics on error.
 // Use this method only when you're certain the generator is properly configured.
 func (g *Generator) MustNext() int64 {
@@ -106,13 +223,18 @@ func (g *Generator) MustNext() int64 {
 
 // getCurrentTimestamp returns the current timestamp in milliseconds.
 func (g *Generator) getCurrentTimestamp() int64 {
-	return time.Now().UnixMilli()
+	return g.clock.Now().UnixMilli()
 }
 
-// waitNextMillis waits until the next millisecond.
+// waitNextMillis waits until the millisecond after lastTimestamp. While borrowing, when the clock
+// is behind lastTimestamp, it borrows that millisecond instead of waiting.
 func (g *Generator) waitNextMillis(lastTimestamp int64) int64 {
 	timestamp := g.getCurrentTimestamp()
+	if timestamp < lastTimestamp {
+		return lastTimestamp + 1
+	}
 	for timestamp <= lastTimestamp {
+		g.clock.Sleep(time.UnixMilli(lastTimestamp + 1).Sub(g.clock.Now()))
 		timestamp = g.getCurrentTimestamp()
 	}
 	return timestamp
@@ -149,6 +271,11 @@ func Next() (int64, error) {
 	return DefaultGenerator.Next()
 }
 
+// NextN generates n unique IDs using the default generator.
+func NextN(n int) ([]int64, error) {
+	return DefaultGenerator.NextN(n)
+}
+
 // MustNext generates the next unique ID using the default generator and panics on error.
 func MustNext() int64 {
 	return DefaultGenerator.MustNext()
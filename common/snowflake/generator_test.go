@@ -1,6 +1,7 @@
 package snowflake
 
 import (
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -230,6 +231,65 @@ func TestSequenceOverflow(t *testing.T) {
 	}
 }
 
+func TestGenerator_NextN(t *testing.T) {
+	gen, err := NewGenerator(1)
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+
+	// More IDs than one millisecond holds, while another goroutine takes IDs too.
+	const n = 3 * (MaxSequence + 1)
+	var others []int64
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			others = append(others, gen.MustNext())
+		}
+	}()
+	ids, err := gen.NextN(n)
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("NextN() error = %v", err)
+	}
+
+	if len(ids) != n {
+		t.Fatalf("NextN() returned %d IDs, want %d", len(ids), n)
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("IDs not increasing at %d: %d -> %d", i, ids[i-1], ids[i])
+		}
+	}
+	// The block is reserved at once: no other ID falls inside it.
+	for _, id := range others {
+		if id >= ids[0] && id <= ids[n-1] {
+			t.Fatalf("ID %d of another caller falls inside the block [%d, %d]", id, ids[0], ids[n-1])
+		}
+	}
+}
+
+func TestGenerator_NextNInvalid(t *testing.T) {
+	gen, err := NewGenerator(1)
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+	for _, n := range []int{0, -1} {
+		if _, err = gen.NextN(n); err == nil {
+			t.Errorf("NextN(%d) should fail", n)
+		}
+	}
+
+	fenced, err := NewGenerator(1, WithFence(func() error { return ErrLeaseExpired }))
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+	if _, err := fenced.NextN(10); !errors.Is(err, ErrLeaseExpired) {
+		t.Errorf("NextN() of a fenced generator error = %v, want ErrLeaseExpired", err)
+	}
+}
+
 // Benchmark tests.
 func BenchmarkGenerator_Next(b *testing.B) {
 	gen, err := NewGenerator(1)
@@ -269,3 +329,17 @@ func BenchmarkMustNext(b *testing.B) {
 		MustNext()
 	}
 }
+
+func BenchmarkGenerator_NextN(b *testing.B) {
+	gen, err := NewGenerator(1)
+	if err != nil {
+		b.Fatalf("NewGenerator() error = %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gen.NextN(100); err != nil {
+			b.Fatalf("NextN() error = %v", err)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+var (
+	// ErrNoFreeWorkerID is returned when every worker ID is leased by another process.
+	ErrNoFreeWorkerID = errors.New("no free worker ID")
+	// ErrLeaseLost is returned when the store no longer holds the lease for its owner, because it
+	// expired there and may have been taken by another process.
+	ErrLeaseLost = errors.New("worker ID lease lost")
+	// ErrLeaseExpired is returned by a fenced generator whose lease could not be renewed in time.
+	ErrLeaseExpired = errors.New("worker ID lease expired")
+)
+
+// defaultLeaseTTL is how long a lease lasts without heartbeats unless configured otherwise.
+const defaultLeaseTTL = 30 * time.Second
+
+// LeaseStore grants exclusive, expiring claims on worker IDs.
+type LeaseStore interface {
+	// TryAcquire claims workerID for owner for ttl. It reports false if another owner holds it.
+	TryAcquire(ctx context.Context, workerID int64, owner string, ttl time.Duration) (bool, error)
+	// Renew extends the claim of owner on workerID by ttl. It returns ErrLeaseLost if owner no
+	// longer holds the claim.
+	Renew(ctx context.Context, workerID int64, owner string, ttl time.Duration) error
+	// Release gives up the claim of owner on workerID, if it still holds it.
+	Release(ctx context.Context, workerID int64, owner string) error
+}
+
+// WorkerLease is a worker ID leased from a LeaseStore and kept by heartbeats.
+//
+// A generator fenced by the lease (see WithFence) stops issuing IDs once the lease has not been
+// renewed for most of its TTL, which is before the store lets another process take the worker ID.
+// The margin between the two absorbs clock drift between the process and the store.
+type WorkerLease struct {
+	store      LeaseStore
+	closeStore func() error
+	stop       chan struct{}
+	done       chan struct{}
+	err        error
+	owner      string
+	workerID   int64
+	ttl        time.Duration
+	// validUntil is when, in unix nanoseconds, the fence trips unless the lease is renewed.
+	validUntil atomic.Int64
+	mu         sync.Mutex
+	stopOnce   sync.Once
+}
+
+// LeaseOption configures AcquireWorkerID.
+type LeaseOption func(l *WorkerLease)
+
+// WithLeaseTTL sets how long the lease lasts without heartbeats (default: 30s). Heartbeats are
+// sent every third of it.
+func WithLeaseTTL(ttl time.Duration) LeaseOption {
+	return func(l *WorkerLease) {
+		if ttl > 0 {
+			l.ttl = ttl
+		}
+	}
+}
+
+// WithLeaseOwner sets the name the lease is held under (default: hostname, process ID and a random
+// suffix). Owners must be unique per process.
+func WithLeaseOwner(owner string) LeaseOption {
+	return func(l *WorkerLease) {
+		if owner != "" {
+			l.owner = owner
+		}
+	}
+}
+
+// AcquireWorkerID leases a free worker ID from store and keeps it with heartbeats until Release.
+// The search starts at a random ID so that processes starting together rarely contend.
+func AcquireWorkerID(ctx context.Context, store LeaseStore, opts ...LeaseOption) (*WorkerLease, error) {
+	if store == nil {
+		return nil, fmt.Errorf("lease store cannot be nil")
+	}
+	l := &WorkerLease{
+		store: store,
+		owner: defaultLeaseOwner(),
+		ttl:   defaultLeaseTTL,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	start := rand.Int64N(MaxWorkerID + 1)
+	for i := int64(0); i <= MaxWorkerID; i++ {
+		workerID := (start + i) % (MaxWorkerID + 1)
+		requestedAt := time.Now()
+		acquired, err := store.TryAcquire(ctx, workerID, l.owner, l.ttl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire worker ID %d: %w", workerID, err)
+		}
+		if acquired {
+			l.workerID = workerID
+			l.extend(requestedAt)
+			go l.heartbeat()
+			logx.Infof("leased snowflake worker ID %d as %s", workerID, l.owner)
+			return l, nil
+		}
+	}
+	return nil, ErrNoFreeWorkerID
+}
+
+// defaultLeaseOwner names the process in leases.
+func defaultLeaseOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return host + "-" + strconv.Itoa(os.Getpid()) + "-" + strconv.FormatUint(rand.Uint64(), 36)
+}
+
+// WorkerID returns the leased worker ID.
+func (l *WorkerLease) WorkerID() int64 {
+	return l.workerID
+}
+
+// Owner returns the name the lease is held under.
+func (l *WorkerLease) Owner() string {
+	return l.owner
+}
+
+// Check returns nil while the lease is valid, and ErrLeaseExpired or ErrLeaseLost otherwise.
+// It is the fence to pass to WithFence.
+func (l *WorkerLease) Check() error {
+	if time.Now().UnixNano() < l.validUntil.Load() {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return l.err
+	}
+	return fmt.Errorf("%w: worker ID %d", ErrLeaseExpired, l.workerID)
+}
+
+// Release stops the heartbeats, fences the lease and returns the worker ID to the store.
+func (l *WorkerLease) Release(ctx context.Context) error {
+	l.stopOnce.Do(func() { close(l.stop) })
+	<-l.done
+	l.fence(fmt.Errorf("%w: worker ID %d was released", ErrLeaseExpired, l.workerID))
+
+	err := l.store.Release(ctx, l.workerID, l.owner)
+	if err != nil {
+		err = fmt.Errorf("failed to release worker ID %d: %w", l.workerID, err)
+	}
+	if l.closeStore != nil {
+		if closeErr := l.closeStore(); closeErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to close lease store: %w", closeErr))
+		}
+	}
+	return err
+}
+
+// heartbeat renews the lease every third of its TTL until it is released or lost.
+func (l *WorkerLease) heartbeat() {
+	defer close(l.done)
+	interval := l.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+
+		requestedAt := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := l.store.Renew(ctx, l.workerID, l.owner, l.ttl)
+		cancel()
+		switch {
+		case err == nil:
+			l.extend(requestedAt)
+		case errors.Is(err, ErrLeaseLost):
+			logx.Errorf("lost the lease on snowflake worker ID %d, no more IDs are generated: %v", l.workerID, err)
+			l.fence(fmt.Errorf("%w: worker ID %d", ErrLeaseLost, l.workerID))
+			return
+		default:
+			// The next heartbeat may still succeed; the fence trips if none does in time.
+			logx.Errorf("failed to renew the lease on snowflake worker ID %d: %v", l.workerID, err)
+		}
+	}
+}
+
+// extend moves the fence to most of a TTL after requestedAt, when the store was asked to extend
+// the lease. The store counts its TTL from later, so the fence trips first.
+func (l *WorkerLease) extend(requestedAt time.Time) {
+	l.validUntil.Store(requestedAt.Add(l.ttl - l.ttl/5).UnixNano())
+}
+
+// fence trips the fence for good with err.
+func (l *WorkerLease) fence(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.validUntil.Store(0)
+	if l.err == nil {
+		l.err = err
+	}
+}
@@ -0,0 +1,207 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryLeaseStore is an in-memory LeaseStore.
+type memoryLeaseStore struct {
+	renewErr error
+	owners   map[int64]string
+	expiry   map[int64]time.Time
+	mu       sync.Mutex
+}
+
+func newMemoryLeaseStore() *memoryLeaseStore {
+	return &memoryLeaseStore{owners: make(map[int64]string), expiry: make(map[int64]time.Time)}
+}
+
+func (s *memoryLeaseStore) TryAcquire(_ context.Context, workerID int64, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.owners[workerID]; ok && time.Now().Before(s.expiry[workerID]) {
+		return false, nil
+	}
+	s.owners[workerID] = owner
+	s.expiry[workerID] = time.Now().Add(ttl)
+	return true, nil
+}
+
+func (s *memoryLeaseStore) Renew(_ context.Context, workerID int64, owner string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.renewErr != nil {
+		return s.renewErr
+	}
+	if s.owners[workerID] != owner || time.Now().After(s.expiry[workerID]) {
+		return ErrLeaseLost
+	}
+	s.expiry[workerID] = time.Now().Add(ttl)
+	return nil
+}
+
+func (s *memoryLeaseStore) Release(_ context.Context, workerID int64, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.owners[workerID] == owner {
+		delete(s.owners, workerID)
+		delete(s.expiry, workerID)
+	}
+	return nil
+}
+
+func (s *memoryLeaseStore) setRenewErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.renewErr = err
+}
+
+func (s *memoryLeaseStore) steal(workerID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.owners[workerID] = "thief"
+}
+
+func (s *memoryLeaseStore) held(workerID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.owners[workerID]
+	return ok
+}
+
+func acquireForTest(t *testing.T, store LeaseStore, ttl time.Duration) *WorkerLease {
+	t.Helper()
+	lease, err := AcquireWorkerID(context.Background(), store, WithLeaseTTL(ttl))
+	if err != nil {
+		t.Fatalf("AcquireWorkerID() error = %v", err)
+	}
+	t.Cleanup(func() { _ = lease.Release(context.Background()) })
+	return lease
+}
+
+func TestAcquireWorkerID_Unique(t *testing.T) {
+	store := newMemoryLeaseStore()
+	seen := make(map[int64]bool)
+	for i := 0; i < 50; i++ {
+		lease := acquireForTest(t, store, time.Minute)
+		if seen[lease.WorkerID()] {
+			t.Fatalf("worker ID %d leased twice", lease.WorkerID())
+		}
+		seen[lease.WorkerID()] = true
+		if err := lease.Check(); err != nil {
+			t.Errorf("Check() of a fresh lease = %v", err)
+		}
+	}
+}
+
+func TestAcquireWorkerID_NoneFree(t *testing.T) {
+	store := newMemoryLeaseStore()
+	for id := int64(0); id <= MaxWorkerID; id++ {
+		store.owners[id] = "other"
+		store.expiry[id] = time.Now().Add(time.Minute)
+	}
+	if _, err := AcquireWorkerID(context.Background(), store); !errors.Is(err, ErrNoFreeWorkerID) {
+		t.Fatalf("AcquireWorkerID() error = %v, want ErrNoFreeWorkerID", err)
+	}
+}
+
+func TestWorkerLease_Heartbeat(t *testing.T) {
+	store := newMemoryLeaseStore()
+	lease := acquireForTest(t, store, 60*time.Millisecond)
+
+	// Several TTLs pass; heartbeats keep the lease.
+	time.Sleep(200 * time.Millisecond)
+	if err := lease.Check(); err != nil {
+		t.Fatalf("Check() after heartbeats = %v", err)
+	}
+}
+
+func TestWorkerLease_Expired(t *testing.T) {
+	store := newMemoryLeaseStore()
+	lease := acquireForTest(t, store, 60*time.Millisecond)
+	generator, err := NewGenerator(lease.WorkerID(), WithFence(lease.Check))
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+
+	store.setRenewErr(errors.New("redis unavailable"))
+	time.Sleep(100 * time.Millisecond)
+
+	if err := lease.Check(); !errors.Is(err, ErrLeaseExpired) {
+		t.Fatalf("Check() without renewals = %v, want ErrLeaseExpired", err)
+	}
+	if _, err := generator.Next(); !errors.Is(err, ErrLeaseExpired) {
+		t.Fatalf("Next() of a fenced generator error = %v, want ErrLeaseExpired", err)
+	}
+
+	// The store recovers, but has expired the lease meanwhile: the fence stays up for good.
+	store.setRenewErr(nil)
+	time.Sleep(60 * time.Millisecond)
+	if _, err := generator.Next(); !errors.Is(err, ErrLeaseLost) {
+		t.Fatalf("Next() after the store expired the lease error = %v, want ErrLeaseLost", err)
+	}
+}
+
+func TestWorkerLease_Lost(t *testing.T) {
+	store := newMemoryLeaseStore()
+	lease := acquireForTest(t, store, 60*time.Millisecond)
+	store.steal(lease.WorkerID())
+	time.Sleep(60 * time.Millisecond)
+
+	if err := lease.Check(); !errors.Is(err, ErrLeaseLost) {
+		t.Fatalf("Check() after the lease was taken = %v, want ErrLeaseLost", err)
+	}
+}
+
+func TestWorkerLease_Release(t *testing.T) {
+	store := newMemoryLeaseStore()
+	lease, err := AcquireWorkerID(context.Background(), store, WithLeaseOwner("test"))
+	if err != nil {
+		t.Fatalf("AcquireWorkerID() error = %v", err)
+	}
+	if lease.Owner() != "test" {
+		t.Errorf("Owner() = %q, want test", lease.Owner())
+	}
+
+	if err := lease.Release(context.Background()); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if store.held(lease.WorkerID()) {
+		t.Error("worker ID still held after Release()")
+	}
+	if err := lease.Check(); !errors.Is(err, ErrLeaseExpired) {
+		t.Errorf("Check() after Release() = %v, want ErrLeaseExpired", err)
+	}
+}
+
+func TestNewLeaseStores(t *testing.T) {
+	if _, err := NewRedisLeaseStore(nil, "trade"); err == nil {
+		t.Error("NewRedisLeaseStore(nil) should fail")
+	}
+	if _, err := NewEtcdLeaseStore(nil, "trade"); err == nil {
+		t.Error("NewEtcdLeaseStore(nil) should fail")
+	}
+}
+
+func TestLeaseConfig(t *testing.T) {
+	var c LeaseConfig
+	if c.Enabled() {
+		t.Error("Enabled() of an empty config = true")
+	}
+	if c.GetNamespace() != "default" {
+		t.Errorf("GetNamespace() = %q, want default", c.GetNamespace())
+	}
+
+	c.EtcdHosts = []string{"127.0.0.1:2379"}
+	c.Redis.Addr = "127.0.0.1:6379"
+	if !c.Enabled() {
+		t.Error("Enabled() = false with stores configured")
+	}
+	if _, err := c.Acquire(context.Background()); err == nil {
+		t.Error("Acquire() with both Redis and etcd configured should fail")
+	}
+}
@@ -0,0 +1,163 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/aether-defense-system/common/redis"
+)
+
+// LeaseRedis defines the Redis operations RedisLeaseStore needs. *redis.Client implements it.
+type LeaseRedis interface {
+	ClaimKey(ctx context.Context, key, marker string, ttl time.Duration) (string, error)
+	RenewKey(ctx context.Context, key, marker string, ttl time.Duration) (bool, error)
+	ReleaseKey(ctx context.Context, key, marker string) (bool, error)
+}
+
+// RedisLeaseStore is a LeaseStore that keeps each leased worker ID as a key holding its owner,
+// expiring after the lease TTL.
+type RedisLeaseStore struct {
+	rdb       LeaseRedis
+	keys      *redis.KeyNamingHelper
+	namespace string
+}
+
+// NewRedisLeaseStore creates a RedisLeaseStore. Worker IDs are unique within namespace.
+func NewRedisLeaseStore(rdb LeaseRedis, namespace string) (*RedisLeaseStore, error) {
+	if rdb == nil {
+		return nil, fmt.Errorf("redis client cannot be nil")
+	}
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	return &RedisLeaseStore{rdb: rdb, keys: redis.NewKeyNamingHelper(), namespace: namespace}, nil
+}
+
+// TryAcquire implements LeaseStore.
+func (s *RedisLeaseStore) TryAcquire(ctx context.Context, workerID int64, owner string, ttl time.Duration) (bool, error) {
+	existing, err := s.rdb.ClaimKey(ctx, s.keys.SnowflakeWorkerKey(s.namespace, workerID), owner, ttl)
+	if err != nil {
+		return false, err
+	}
+	return existing == "", nil
+}
+
+// Renew implements LeaseStore.
+func (s *RedisLeaseStore) Renew(ctx context.Context, workerID int64, owner string, ttl time.Duration) error {
+	renewed, err := s.rdb.RenewKey(ctx, s.keys.SnowflakeWorkerKey(s.namespace, workerID), owner, ttl)
+	if err != nil {
+		return err
+	}
+	if !renewed {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Release implements LeaseStore.
+func (s *RedisLeaseStore) Release(ctx context.Context, workerID int64, owner string) error {
+	_, err := s.rdb.ReleaseKey(ctx, s.keys.SnowflakeWorkerKey(s.namespace, workerID), owner)
+	return err
+}
+
+// EtcdLeaseStore is a LeaseStore that keeps each leased worker ID as a key attached to an etcd
+// lease, so that etcd deletes it when the lease expires.
+type EtcdLeaseStore struct {
+	cli       *clientv3.Client
+	leases    map[int64]clientv3.LeaseID
+	namespace string
+	mu        sync.Mutex
+}
+
+// NewEtcdLeaseStore creates an EtcdLeaseStore. Worker IDs are unique within namespace.
+func NewEtcdLeaseStore(cli *clientv3.Client, namespace string) (*EtcdLeaseStore, error) {
+	if cli == nil {
+		return nil, fmt.Errorf("etcd client cannot be nil")
+	}
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	return &EtcdLeaseStore{cli: cli, leases: make(map[int64]clientv3.LeaseID), namespace: namespace}, nil
+}
+
+// key returns the etcd key of a worker ID.
+func (s *EtcdLeaseStore) key(workerID int64) string {
+	return "/snowflake/worker/" + s.namespace + "/" + strconv.FormatInt(workerID, 10)
+}
+
+// TryAcquire implements LeaseStore. etcd leases have a TTL in whole seconds; ttl is rounded up.
+func (s *EtcdLeaseStore) TryAcquire(ctx context.Context, workerID int64, owner string, ttl time.Duration) (bool, error) {
+	grant, err := s.cli.Grant(ctx, int64(math.Ceil(ttl.Seconds())))
+	if err != nil {
+		return false, fmt.Errorf("failed to grant etcd lease: %w", err)
+	}
+
+	key := s.key(workerID)
+	resp, err := s.cli.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, owner, clientv3.WithLease(grant.ID))).
+		Commit()
+	if err != nil || !resp.Succeeded {
+		// An unused lease that cannot be revoked expires on its own.
+		_, _ = s.cli.Revoke(ctx, grant.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to claim %s: %w", key, err)
+		}
+		return false, nil
+	}
+
+	s.mu.Lock()
+	s.leases[workerID] = grant.ID
+	s.mu.Unlock()
+	return true, nil
+}
+
+// Renew implements LeaseStore. The etcd lease is renewed to the TTL it was granted with.
+func (s *EtcdLeaseStore) Renew(ctx context.Context, workerID int64, _ string, _ time.Duration) error {
+	leaseID, ok := s.lease(workerID)
+	if !ok {
+		return ErrLeaseLost
+	}
+	resp, err := s.cli.KeepAliveOnce(ctx, leaseID)
+	if err != nil {
+		if errors.Is(err, rpctypes.ErrLeaseNotFound) {
+			return ErrLeaseLost
+		}
+		return fmt.Errorf("failed to renew etcd lease: %w", err)
+	}
+	if resp.TTL <= 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Release implements LeaseStore. Revoking the etcd lease deletes the key.
+func (s *EtcdLeaseStore) Release(ctx context.Context, workerID int64, _ string) error {
+	leaseID, ok := s.lease(workerID)
+	if !ok {
+		return nil
+	}
+	if _, err := s.cli.Revoke(ctx, leaseID); err != nil && !errors.Is(err, rpctypes.ErrLeaseNotFound) {
+		return fmt.Errorf("failed to revoke etcd lease: %w", err)
+	}
+	s.mu.Lock()
+	delete(s.leases, workerID)
+	s.mu.Unlock()
+	return nil
+}
+
+// lease returns the etcd lease holding workerID.
+func (s *EtcdLeaseStore) lease(workerID int64) (clientv3.LeaseID, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	leaseID, ok := s.leases[workerID]
+	return leaseID, ok
+}
@@ -0,0 +1,131 @@
+package snowflake
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// ErrInvalidPublicID is returned when decoding a string that no IDCodec with the same key produced.
+var ErrInvalidPublicID = errors.New("invalid public ID")
+
+const (
+	// base62Alphabet are the digits of public IDs, in order of value.
+	base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// PublicIDLength is the length of every public ID: 11 base62 digits hold 64 bits.
+	PublicIDLength = 11
+	// MinIDCodecKeyLength is the shortest key NewIDCodec accepts.
+	MinIDCodecKeyLength = 16
+
+	// feistelRounds is the number of rounds of the permutation; four make it a strong pseudorandom
+	// permutation.
+	feistelRounds = 4
+)
+
+// base62Values maps each byte to its value as a base62 digit, or -1.
+var base62Values = func() [256]int8 {
+	var values [256]int8
+	for i := range values {
+		values[i] = -1
+	}
+	for i := 0; i < len(base62Alphabet); i++ {
+		values[base62Alphabet[i]] = int8(i)
+	}
+	return values
+}()
+
+// IDCodec converts IDs to and from public IDs: fixed-length base62 strings that reveal neither the
+// time, the worker nor the sequence of an ID. Consecutive IDs have unrelated public IDs, so that
+// they do not leak how many orders are placed.
+//
+// Public IDs are a keyed permutation of IDs, not encryption with integrity: anyone can submit a
+// made-up public ID, and it decodes to some ID. Authorize access to what it names as usual.
+type IDCodec struct {
+	// roundKeys are derived from the key, one per round.
+	roundKeys [feistelRounds][]byte
+}
+
+// NewIDCodec creates an IDCodec with key, which must be at least MinIDCodecKeyLength bytes and
+// the same wherever public IDs are decoded. Changing the key changes every public ID.
+func NewIDCodec(key []byte) (*IDCodec, error) {
+	if len(key) < MinIDCodecKeyLength {
+		return nil, fmt.Errorf("ID codec key must be at least %d bytes, got %d", MinIDCodecKeyLength, len(key))
+	}
+	c := &IDCodec{}
+	for round := range c.roundKeys {
+		mac := hmac.New(sha256.New, key)
+		_, _ = mac.Write([]byte{byte(round)})
+		c.roundKeys[round] = mac.Sum(nil)
+	}
+	return c, nil
+}
+
+// Encode returns the public ID of id, which must not be negative.
+func (c *IDCodec) Encode(id int64) string {
+	x := c.permute(uint64(id))
+
+	var buf [PublicIDLength]byte
+	for i := PublicIDLength - 1; i >= 0; i-- {
+		buf[i] = base62Alphabet[x%62]
+		x /= 62
+	}
+	return string(buf[:])
+}
+
+// Decode returns the ID whose public ID is s.
+func (c *IDCodec) Decode(s string) (int64, error) {
+	if len(s) != PublicIDLength {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidPublicID, s)
+	}
+	var x uint64
+	for i := 0; i < len(s); i++ {
+		digit := base62Values[s[i]]
+		if digit < 0 {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidPublicID, s)
+		}
+		hi, lo := bits.Mul64(x, 62)
+		var carry uint64
+		x, carry = bits.Add64(lo, uint64(digit), 0)
+		if hi != 0 || carry != 0 {
+			// Beyond 64 bits.
+			return 0, fmt.Errorf("%w: %q", ErrInvalidPublicID, s)
+		}
+	}
+
+	id := int64(c.unpermute(x))
+	if id < 0 {
+		// Encode never produces it: IDs have the sign bit clear.
+		return 0, fmt.Errorf("%w: %q", ErrInvalidPublicID, s)
+	}
+	return id, nil
+}
+
+// permute is a balanced Feistel network over the two 32-bit halves of x.
+func (c *IDCodec) permute(x uint64) uint64 {
+	left, right := uint32(x>>32), uint32(x)
+	for round := 0; round < feistelRounds; round++ {
+		left, right = right, left^c.roundFunc(round, right)
+	}
+	return uint64(left)<<32 | uint64(right)
+}
+
+// unpermute inverts permute.
+func (c *IDCodec) unpermute(x uint64) uint64 {
+	left, right := uint32(x>>32), uint32(x)
+	for round := feistelRounds - 1; round >= 0; round-- {
+		left, right = right^c.roundFunc(round, left), left
+	}
+	return uint64(left)<<32 | uint64(right)
+}
+
+// roundFunc is the keyed pseudorandom function of a round.
+func (c *IDCodec) roundFunc(round int, half uint32) uint32 {
+	var in [4]byte
+	binary.BigEndian.PutUint32(in[:], half)
+	mac := hmac.New(sha256.New, c.roundKeys[round])
+	_, _ = mac.Write(in[:])
+	return binary.BigEndian.Uint32(mac.Sum(nil))
+}
@@ -0,0 +1,96 @@
+package snowflake
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func newTestIDCodec(t *testing.T, key string) *IDCodec {
+	t.Helper()
+	codec, err := NewIDCodec([]byte(key))
+	if err != nil {
+		t.Fatalf("NewIDCodec() error = %v", err)
+	}
+	return codec
+}
+
+func TestIDCodec_RoundTrip(t *testing.T) {
+	codec := newTestIDCodec(t, "0123456789abcdef")
+	ids := []int64{0, 1, 2, MaxSequence, 1 << TimestampShift, math.MaxInt64, MustNext()}
+	for _, id := range ids {
+		public := codec.Encode(id)
+		if len(public) != PublicIDLength {
+			t.Errorf("Encode(%d) = %q, want %d characters", id, public, PublicIDLength)
+		}
+		got, err := codec.Decode(public)
+		if err != nil {
+			t.Fatalf("Decode(%q) error = %v", public, err)
+		}
+		if got != id {
+			t.Errorf("Decode(Encode(%d)) = %d", id, got)
+		}
+	}
+}
+
+func TestIDCodec_HidesSequence(t *testing.T) {
+	codec := newTestIDCodec(t, "0123456789abcdef")
+	ids, err := NextN(100)
+	if err != nil {
+		t.Fatalf("NextN() error = %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var sharedPrefix int
+	prev := codec.Encode(ids[0])
+	for _, id := range ids {
+		public := codec.Encode(id)
+		if seen[public] {
+			t.Fatalf("public ID %q issued twice", public)
+		}
+		seen[public] = true
+		if public[:3] == prev[:3] {
+			sharedPrefix++
+		}
+		prev = public
+	}
+	// Consecutive IDs differ in their low bits only; their public IDs should look unrelated.
+	if sharedPrefix > 10 {
+		t.Errorf("%d of %d consecutive public IDs share a prefix", sharedPrefix, len(ids))
+	}
+}
+
+func TestIDCodec_Key(t *testing.T) {
+	if _, err := NewIDCodec([]byte("short")); err == nil {
+		t.Error("NewIDCodec() with a short key should fail")
+	}
+
+	a := newTestIDCodec(t, "0123456789abcdef")
+	b := newTestIDCodec(t, "fedcba9876543210")
+	id := MustNext()
+	if a.Encode(id) == b.Encode(id) {
+		t.Error("different keys produced the same public ID")
+	}
+}
+
+func TestIDCodec_DecodeInvalid(t *testing.T) {
+	codec := newTestIDCodec(t, "0123456789abcdef")
+	tests := []string{
+		"",
+		"abc",
+		strings.Repeat("0", PublicIDLength+1),
+		"0000000000-",
+		strings.Repeat("z", PublicIDLength), // beyond 64 bits
+	}
+	for _, s := range tests {
+		if _, err := codec.Decode(s); !errors.Is(err, ErrInvalidPublicID) {
+			t.Errorf("Decode(%q) error = %v, want ErrInvalidPublicID", s, err)
+		}
+	}
+
+	// Half of the 64-bit values are negative numbers, which are not IDs.
+	if _, err := codec.Decode(codec.Encode(-1)); !errors.Is(err, ErrInvalidPublicID) {
+		t.Errorf("Decode() of a negative ID error = %v, want ErrInvalidPublicID", err)
+	}
+}
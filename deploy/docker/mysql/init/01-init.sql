@@ -9,7 +9,8 @@ SET CHARACTER SET utf8mb4;
 -- CREATE DATABASE IF NOT EXISTS aether_defense CHARACTER SET utf8mb4 COLLATE utf8mb4_general_ci;
 -- USE aether_defense;
 
--- Note: Tables will be created by application migrations or manually
+-- Note: Tables are created by the schema migrations in common/database/migrations, applied
+-- by the services at startup (AutoMigrate) or with: go run ./cmd/tool/migrate -set ... up
 -- This file is for any initial setup needed
 
 -- Example: Create a test user for development
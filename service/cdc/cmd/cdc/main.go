@@ -0,0 +1,64 @@
+// Package main starts the CDC service. It consumes the binlog row events Canal publishes for the
+// trade databases, publishes the changes of the orders and order items as change events, and
+// applies those to the reporting database that the admin order search reads.
+//
+// Usage:
+//
+//	cdc [-f config]
+//	cdc [-f config] -replay FILE
+//
+// -replay publishes the row events of a file, one Canal flat message per line, and exits. Without
+// a change event name server the events go through an in-memory broker straight to the reporting
+// database, which makes a local stand-in for the binlog.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/service/cdc/internal/config"
+	"github.com/aether-defense-system/service/cdc/internal/svc"
+)
+
+var (
+	configFile = flag.String("f", "service/cdc/etc/cdc.yaml", "the config file")
+	replayFile = flag.String("replay", "", "a file of binlog row events to replay instead of consuming")
+)
+
+func main() {
+	flag.Parse()
+
+	var c config.Config
+	conf.MustLoad(*configFile, &c, conf.UseEnv())
+	c.MustSetUp()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	svcCtx := svc.NewServiceContext(&c)
+	defer svcCtx.Close()
+	if err := svcCtx.Start(ctx); err != nil {
+		logx.Errorf("failed to start: %v", err)
+		return
+	}
+
+	if *replayFile != "" {
+		handled, err := svcCtx.Replay(ctx, *replayFile)
+		if err != nil {
+			logx.Errorf("failed to replay %s: %v", *replayFile, err)
+			return
+		}
+		_, _ = fmt.Printf("Replayed %d row events from %s\n", handled, *replayFile)
+		return
+	}
+
+	_, _ = fmt.Printf("Starting %s...\n", c.Name)
+	<-ctx.Done()
+}
@@ -0,0 +1,227 @@
+// Package changes turns the binlog row events of the order tables into order change events.
+package changes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/aether-defense-system/common/cdc"
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/mq"
+)
+
+// Tables whose rows are published, with the shard suffix stripped.
+const (
+	tableOrder     = "trade_order"
+	tableOrderItem = "trade_order_item"
+)
+
+// shardedTable matches the order tables and their archives, unsharded or with the suffix of a
+// shard, e.g. trade_order_item_07 or trade_order_archive_07.
+var shardedTable = regexp.MustCompile(`^(trade_order|trade_order_item)(_archive)?(?:_\d+)?$`)
+
+// rowOps maps row event types to change operations.
+var rowOps = map[string]event.RowOp{
+	cdc.TypeInsert: event.RowOp_ROW_OP_INSERT,
+	cdc.TypeUpdate: event.RowOp_ROW_OP_UPDATE,
+	cdc.TypeDelete: event.RowOp_ROW_OP_DELETE,
+}
+
+// Publisher publishes an ORDER_ROW_CHANGED or ORDER_ITEM_ROW_CHANGED event for every row change of
+// trade_order and trade_order_item, in every trade database and shard. Events are keyed by order,
+// so that the changes of an order can be looked up together.
+//
+// Orders and items leave these tables only when archived: the archiver copies them to
+// trade_order_archive and trade_order_item_archive and deletes them in the same transaction. Those
+// deletes are not published, since the order still exists; the copies are published instead, as
+// changes of the archive tables, and so is their deletion once purged from the archive.
+//
+// A row event is handled again when publishing one of its rows fails, so consumers must tolerate
+// duplicates: each change carries its binlog time for them to keep the latest one.
+type Publisher struct {
+	producer mq.Producer
+	loc      *time.Location
+	topic    string
+}
+
+// NewPublisher creates a publisher sending to topic. loc is the time zone of the DATETIME columns.
+func NewPublisher(producer mq.Producer, topic string, loc *time.Location) (*Publisher, error) {
+	if producer == nil {
+		return nil, fmt.Errorf("producer cannot be nil")
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("change event topic is required")
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	return &Publisher{producer: producer, loc: loc, topic: topic}, nil
+}
+
+// Handle publishes the changes of e. Events of other tables, DDL events and the deletes of
+// archived rows from the order tables are ignored.
+func (p *Publisher) Handle(ctx context.Context, e *cdc.RowEvent) error {
+	if e.IsDDL {
+		logx.WithContext(ctx).Infof("ignoring DDL on %s", e.Source())
+		return nil
+	}
+	m := shardedTable.FindStringSubmatch(e.Table)
+	if m == nil {
+		return nil
+	}
+	if e.Type == cdc.TypeDelete && m[2] == "" {
+		return nil
+	}
+
+	for _, row := range e.Rows() {
+		var (
+			orderID   int64
+			eventType string
+			payload   proto.Message
+			err       error
+		)
+		switch m[1] {
+		case tableOrder:
+			orderID, eventType, payload, err = p.orderChange(e, row)
+		case tableOrderItem:
+			orderID, eventType, payload, err = p.itemChange(e, row)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s row event %d: %w", e.Source(), e.ID, err)
+		}
+		if err := p.publish(ctx, orderID, eventType, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publish sends the change event of an order.
+func (p *Publisher) publish(ctx context.Context, orderID int64, eventType string, payload proto.Message) error {
+	aggregateID := strconv.FormatInt(orderID, 10)
+	env, err := event.Default.NewEnvelope(eventType, aggregateID, payload)
+	if err != nil {
+		return err
+	}
+	msg, err := event.Default.NewMessage(p.topic, env)
+	if err != nil {
+		return err
+	}
+	if _, err := p.producer.Send(ctx, msg.WithKeys("order_"+aggregateID)); err != nil {
+		return fmt.Errorf("failed to publish %s of order %d: %w", eventType, orderID, err)
+	}
+	return nil
+}
+
+// orderChange converts a trade_order row.
+func (p *Publisher) orderChange(e *cdc.RowEvent, row cdc.Row) (int64, string, proto.Message, error) {
+	if err := row.Columns.Require("id", "user_id", "status", "create_time"); err != nil {
+		return 0, "", nil, err
+	}
+	c := &columnReader{columns: row.Columns, loc: p.loc}
+	r := &event.OrderRow{
+		OrderId:     c.int64("id"),
+		UserId:      c.int64("user_id"),
+		Status:      c.int32("status"),
+		TotalAmount: c.int32("total_amount"),
+		PayAmount:   c.int32("pay_amount"),
+		PayChannel:  c.int32("pay_channel"),
+		OutTradeNo:  c.string("out_trade_no"),
+		PayTime:     c.unix("pay_time"),
+		CreateTime:  c.unix("create_time"),
+		UpdateTime:  c.unix("update_time"),
+		Version:     c.int32("version"),
+	}
+	if c.err != nil {
+		return 0, "", nil, c.err
+	}
+	return r.OrderId, event.TypeOrderRowChanged, &event.OrderRowChanged{
+		Op:             rowOps[e.Type],
+		Row:            r,
+		ChangedColumns: row.ChangedColumns(),
+		Source:         e.Source(),
+		CommittedAt:    e.ES,
+	}, nil
+}
+
+// itemChange converts a trade_order_item row.
+func (p *Publisher) itemChange(e *cdc.RowEvent, row cdc.Row) (int64, string, proto.Message, error) {
+	if err := row.Columns.Require("id", "order_id", "user_id", "create_time"); err != nil {
+		return 0, "", nil, err
+	}
+	c := &columnReader{columns: row.Columns, loc: p.loc}
+	r := &event.OrderItemRow{
+		ItemId:        c.int64("id"),
+		OrderId:       c.int64("order_id"),
+		UserId:        c.int64("user_id"),
+		CourseId:      c.int64("course_id"),
+		CourseName:    c.string("course_name"),
+		Price:         c.int32("price"),
+		RealPayAmount: c.int32("real_pay_amount"),
+		RefundStatus:  c.int32("refund_status"),
+		CreateTime:    c.unix("create_time"),
+		UpdateTime:    c.unix("update_time"),
+	}
+	if c.err != nil {
+		return 0, "", nil, c.err
+	}
+	return r.OrderId, event.TypeOrderItemRowChanged, &event.OrderItemRowChanged{
+		Op:             rowOps[e.Type],
+		Row:            r,
+		ChangedColumns: row.ChangedColumns(),
+		Source:         e.Source(),
+		CommittedAt:    e.ES,
+	}, nil
+}
+
+// columnReader reads the columns of a row, keeping the first error. NULL reads as the zero value.
+type columnReader struct {
+	err     error
+	columns cdc.Columns
+	loc     *time.Location
+}
+
+func (r *columnReader) int64(name string) int64 {
+	if r.err != nil {
+		return 0
+	}
+	n, err := r.columns.Int64(name)
+	r.err = err
+	return n
+}
+
+func (r *columnReader) int32(name string) int32 {
+	n := r.int64(name)
+	if r.err == nil && int64(int32(n)) != n {
+		r.err = fmt.Errorf("column %s is out of range: %d", name, n)
+	}
+	return int32(n)
+}
+
+func (r *columnReader) string(name string) string {
+	if r.err != nil {
+		return ""
+	}
+	s, err := r.columns.String(name)
+	r.err = err
+	return s
+}
+
+// unix reads a DATETIME column in unix seconds; NULL is 0.
+func (r *columnReader) unix(name string) int64 {
+	if r.err != nil {
+		return 0
+	}
+	t, err := r.columns.Time(name, r.loc)
+	r.err = err
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,194 @@
+package changes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/cdc"
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/mq"
+)
+
+const changeTopic = "order-change-topic"
+
+// shanghai is the time zone of the DATETIME columns of the fixture.
+var shanghai = time.FixedZone("CST", 8*3600)
+
+// failingProducer fails every send.
+type failingProducer struct {
+	err error
+}
+
+func (p *failingProducer) Send(context.Context, *mq.Message) (*mq.SendResult, error) {
+	return nil, p.err
+}
+
+func (p *failingProducer) Shutdown() error {
+	return nil
+}
+
+// replayFixture publishes the changes of the fixture binlog and returns the published events.
+func replayFixture(t *testing.T) []*event.Event {
+	t.Helper()
+	broker := mq.NewMemoryBroker()
+	publisher, err := NewPublisher(broker.NewProducer(), changeTopic, shanghai)
+	require.NoError(t, err)
+
+	handled, err := cdc.ReplayFile(context.Background(), "../../testdata/binlog.jsonl", publisher.Handle)
+	require.NoError(t, err)
+	assert.Equal(t, 19, handled)
+
+	var events []*event.Event
+	for _, msg := range broker.Messages(changeTopic) {
+		e, err := event.Default.Parse(msg)
+		require.NoError(t, err)
+		assert.Equal(t, msg.Tag, e.Envelope.Type)
+		assert.Equal(t, []string{"order_" + e.Envelope.AggregateId}, msg.Keys)
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestPublisher_Fixture(t *testing.T) {
+	events := replayFixture(t)
+
+	// The outbox event, the refund and the DDL are not published.
+	var orders, items int
+	for _, e := range events {
+		switch e.Payload.(type) {
+		case *event.OrderRowChanged:
+			orders++
+		case *event.OrderItemRowChanged:
+			items++
+		}
+	}
+	assert.Equal(t, 9, orders)
+	assert.Equal(t, 6, items)
+
+	placed, ok := events[0].Payload.(*event.OrderRowChanged)
+	require.True(t, ok)
+	assert.Equal(t, event.RowOp_ROW_OP_INSERT, placed.Op)
+	assert.Equal(t, "aether_trade_0.trade_order_07", placed.Source)
+	assert.Equal(t, int64(1699668000000), placed.CommittedAt)
+	assert.Equal(t, int64(1001), placed.Row.OrderId)
+	assert.Equal(t, int32(19800), placed.Row.TotalAmount)
+	assert.Equal(t, time.Date(2023, 11, 11, 10, 0, 0, 0, shanghai).Unix(), placed.Row.CreateTime)
+	assert.Zero(t, placed.Row.PayTime)
+	assert.Empty(t, placed.ChangedColumns)
+
+	item, ok := events[1].Payload.(*event.OrderItemRowChanged)
+	require.True(t, ok)
+	assert.Equal(t, "1001", events[1].Envelope.AggregateId)
+	assert.Equal(t, int64(10011), item.Row.ItemId)
+	assert.Equal(t, "Go in Practice", item.Row.CourseName)
+
+	paid, ok := events[3].Payload.(*event.OrderRowChanged)
+	require.True(t, ok)
+	assert.Equal(t, event.RowOp_ROW_OP_UPDATE, paid.Op)
+	assert.Equal(t, int32(3), paid.Row.Status)
+	assert.Equal(t, int32(1), paid.Row.PayChannel)
+	assert.Equal(t, "ALI20231111100500", paid.Row.OutTradeNo)
+	assert.Equal(t, time.Date(2023, 11, 11, 10, 5, 0, 0, shanghai).Unix(), paid.Row.PayTime)
+	assert.Equal(t, []string{"out_trade_no", "pay_channel", "pay_time", "status", "update_time", "version"},
+		paid.ChangedColumns)
+
+	// The archival of order 1002 is published as its copy to the archive tables, not as its deletion.
+	archived, ok := events[len(events)-2].Payload.(*event.OrderRowChanged)
+	require.True(t, ok)
+	assert.Equal(t, event.RowOp_ROW_OP_INSERT, archived.Op)
+	assert.Equal(t, "aether_trade_1.trade_order_archive_08", archived.Source)
+	assert.Equal(t, int64(1002), archived.Row.OrderId)
+	assert.Equal(t, int32(2), archived.Row.Status)
+	archivedItem, ok := events[len(events)-1].Payload.(*event.OrderItemRowChanged)
+	require.True(t, ok)
+	assert.Equal(t, event.RowOp_ROW_OP_INSERT, archivedItem.Op)
+	assert.Equal(t, int64(10021), archivedItem.Row.ItemId)
+}
+
+func TestPublisher_Deletes(t *testing.T) {
+	value := func(s string) *string { return &s }
+	row := cdc.Columns{
+		"id": value("1002"), "user_id": value("40"), "status": value("2"), "total_amount": value("4900"),
+		"pay_amount": value("4900"), "pay_channel": nil, "out_trade_no": nil, "pay_time": nil,
+		"create_time": value("2023-11-11 23:59:59"), "update_time": value("2023-11-12 00:29:59"), "version": value("1"),
+	}
+	tests := []struct {
+		name      string
+		table     string
+		published bool
+	}{
+		{name: "archived order", table: "trade_order_08"},
+		{name: "unsharded archived order", table: "trade_order"},
+		{name: "order purged from the archive", table: "trade_order_archive_08", published: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := mq.NewMemoryBroker()
+			publisher, err := NewPublisher(broker.NewProducer(), changeTopic, shanghai)
+			require.NoError(t, err)
+
+			e := &cdc.RowEvent{Database: "aether_trade_1", Table: tt.table, Type: cdc.TypeDelete, Data: []cdc.Columns{row}}
+			require.NoError(t, publisher.Handle(context.Background(), e))
+			msgs := broker.Messages(changeTopic)
+			if !tt.published {
+				assert.Empty(t, msgs)
+				return
+			}
+			require.Len(t, msgs, 1)
+			got, err := event.Default.Parse(msgs[0])
+			require.NoError(t, err)
+			changed, ok := got.Payload.(*event.OrderRowChanged)
+			require.True(t, ok)
+			assert.Equal(t, event.RowOp_ROW_OP_DELETE, changed.Op)
+			assert.Equal(t, int64(1002), changed.Row.OrderId)
+		})
+	}
+}
+
+func TestPublisher_InvalidRow(t *testing.T) {
+	broker := mq.NewMemoryBroker()
+	publisher, err := NewPublisher(broker.NewProducer(), changeTopic, shanghai)
+	require.NoError(t, err)
+
+	value := func(s string) *string { return &s }
+	tests := []struct {
+		row  cdc.Columns
+		name string
+	}{
+		{name: "no user", row: cdc.Columns{"id": value("1"), "status": value("1"), "create_time": value("2023-11-11 10:00:00")}},
+		{name: "bad status", row: cdc.Columns{
+			"id": value("1"), "user_id": value("7"), "status": value("paid"), "create_time": value("2023-11-11 10:00:00"),
+		}},
+		{name: "status out of range", row: cdc.Columns{
+			"id": value("1"), "user_id": value("7"), "status": value("4294967296"), "create_time": value("2023-11-11 10:00:00"),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &cdc.RowEvent{Database: "aether_trade", Table: "trade_order", Type: cdc.TypeInsert, Data: []cdc.Columns{tt.row}}
+			assert.Error(t, publisher.Handle(context.Background(), e))
+		})
+	}
+	assert.Empty(t, broker.Messages(changeTopic))
+}
+
+func TestPublisher_SendFailure(t *testing.T) {
+	sendErr := errors.New("broker unavailable")
+	publisher, err := NewPublisher(&failingProducer{err: sendErr}, changeTopic, shanghai)
+	require.NoError(t, err)
+
+	_, err = cdc.ReplayFile(context.Background(), "../../testdata/binlog.jsonl", publisher.Handle)
+	assert.ErrorIs(t, err, sendErr)
+}
+
+func TestNewPublisher(t *testing.T) {
+	_, err := NewPublisher(nil, changeTopic, nil)
+	assert.Error(t, err)
+	_, err = NewPublisher(mq.NewMemoryBroker().NewProducer(), "", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+// Package config contains configuration for the CDC service.
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/service"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/mq"
+)
+
+// defaultPartitionsAhead is how many months ahead the reporting tables get their partitions.
+const defaultPartitionsAhead = 3
+
+// ReportingConf configures the consumer that keeps the reporting database.
+type ReportingConf struct {
+	// Group consuming the change events into the reporting database.
+	Group string `json:"group" yaml:"group"`
+	// PartitionsAhead is how many months after the current one have partitions (default: 3).
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	PartitionsAhead int `json:"partitionsAhead,optional" yaml:"partitionsAhead"`
+}
+
+// GetPartitionsAhead returns the months with partitions after the current one, defaulting to 3.
+func (c ReportingConf) GetPartitionsAhead() int {
+	if c.PartitionsAhead <= 0 {
+		return defaultPartitionsAhead
+	}
+	return c.PartitionsAhead
+}
+
+// Config represents the configuration for the CDC service.
+type Config struct {
+	service.ServiceConf
+
+	// RowEvents is the topic Canal publishes the binlog of the trade databases to, as flat messages,
+	// and the group reading it. Without a name server the service only replays fixture files.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	RowEvents mq.Config `json:"rowEvents,optional" yaml:"rowEvents"`
+
+	// ChangeEvents is the topic the order change events are published to. Without a name server
+	// they go through an in-memory broker, which only serves replays.
+	ChangeEvents mq.Config `json:"changeEvents" yaml:"changeEvents"`
+
+	// Reporting configures the consumer of the change events.
+	Reporting ReportingConf `json:"reporting" yaml:"reporting"`
+
+	// Database is the reporting database.
+	Database database.Config `json:"database" yaml:"database"`
+
+	// TimeZone of the DATETIME columns of the trade databases, which Canal prints without one,
+	// e.g. Asia/Shanghai. It must match the loc of the DSN of trade (default: Local).
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	TimeZone string `json:"timeZone,optional" yaml:"timeZone"`
+
+	// AutoMigrate applies the pending reporting schema migrations at startup. Without it the schema
+	// is migrated with the migrate command.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	AutoMigrate bool `json:"autoMigrate,optional" yaml:"autoMigrate"`
+}
+
+// Location returns the time zone of the DATETIME columns of the trade databases.
+func (c *Config) Location() (*time.Location, error) {
+	if c.TimeZone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", c.TimeZone, err)
+	}
+	return loc, nil
+}
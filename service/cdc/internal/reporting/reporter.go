@@ -0,0 +1,140 @@
+// Package reporting keeps the reporting database from the order change events.
+package reporting
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/event"
+)
+
+// Store defines the reporting table operations required by Reporter. Every
+// database.OrderReportStore satisfies this interface.
+type Store interface {
+	ApplyOrder(ctx context.Context, order *database.ReportOrder) error
+	ApplyOrderItem(ctx context.Context, item *database.ReportOrderItem) error
+	EnsurePartitions(ctx context.Context, from, through time.Time) (int, error)
+}
+
+// Reporter applies order change events to the reporting tables. Archived orders are reported
+// like the others, from their copies in the archive tables. Deleted rows are kept, flagged deleted.
+type Reporter struct {
+	store Store
+}
+
+// NewReporter creates a reporter writing to store.
+func NewReporter(store Store) (*Reporter, error) {
+	if store == nil {
+		return nil, fmt.Errorf("store cannot be nil")
+	}
+	return &Reporter{store: store}, nil
+}
+
+// Handle applies one change event. Events of other types are ignored.
+func (r *Reporter) Handle(ctx context.Context, e *event.Event) error {
+	switch payload := e.Payload.(type) {
+	case *event.OrderRowChanged:
+		if payload.Row == nil {
+			return fmt.Errorf("event %s has no order row", e.Envelope.EventId)
+		}
+		return r.store.ApplyOrder(ctx, reportOrder(payload))
+	case *event.OrderItemRowChanged:
+		if payload.Row == nil {
+			return fmt.Errorf("event %s has no order item row", e.Envelope.EventId)
+		}
+		return r.store.ApplyOrderItem(ctx, reportOrderItem(payload))
+	default:
+		logx.WithContext(ctx).Infof("ignoring event %s of type %s", e.Envelope.EventId, e.Envelope.Type)
+		return nil
+	}
+}
+
+// EnsurePartitions gives the reporting tables partitions for the month of now and the monthsAhead
+// months after it.
+func (r *Reporter) EnsurePartitions(ctx context.Context, now time.Time, monthsAhead int) error {
+	added, err := r.store.EnsurePartitions(ctx, now, now.AddDate(0, monthsAhead, 0))
+	if err != nil {
+		return err
+	}
+	if added > 0 {
+		logx.WithContext(ctx).Infof("added %d reporting partitions", added)
+	}
+	return nil
+}
+
+// MaintainPartitions ensures the partitions of the coming months every interval until ctx is done.
+// Failures are logged and retried at the next interval.
+func (r *Reporter) MaintainPartitions(ctx context.Context, monthsAhead int, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := r.EnsurePartitions(ctx, now, monthsAhead); err != nil {
+				logx.WithContext(ctx).Errorf("failed to add reporting partitions: %v", err)
+			}
+		}
+	}
+}
+
+// reportOrder converts an order change to its reporting row.
+func reportOrder(c *event.OrderRowChanged) *database.ReportOrder {
+	row := c.Row
+	order := &database.ReportOrder{
+		ID:          row.OrderId,
+		CreateTime:  unixTime(row.CreateTime),
+		UserID:      row.UserId,
+		Status:      int8(row.Status),
+		TotalAmount: row.TotalAmount,
+		PayAmount:   row.PayAmount,
+		UpdateTime:  unixTime(row.UpdateTime),
+		Version:     row.Version,
+		Deleted:     c.Op == event.RowOp_ROW_OP_DELETE,
+		Source:      c.Source,
+		CommittedAt: c.CommittedAt,
+	}
+	if row.PayChannel != 0 {
+		channel := int8(row.PayChannel)
+		order.PayChannel = &channel
+	}
+	if row.OutTradeNo != "" {
+		outTradeNo := row.OutTradeNo
+		order.OutTradeNo = &outTradeNo
+	}
+	if row.PayTime != 0 {
+		payTime := unixTime(row.PayTime)
+		order.PayTime = &payTime
+	}
+	return order
+}
+
+// reportOrderItem converts an order item change to its reporting row.
+func reportOrderItem(c *event.OrderItemRowChanged) *database.ReportOrderItem {
+	row := c.Row
+	return &database.ReportOrderItem{
+		ID:            row.ItemId,
+		CreateTime:    unixTime(row.CreateTime),
+		OrderID:       row.OrderId,
+		UserID:        row.UserId,
+		CourseID:      row.CourseId,
+		CourseName:    row.CourseName,
+		Price:         row.Price,
+		RealPayAmount: row.RealPayAmount,
+		RefundStatus:  int8(row.RefundStatus),
+		UpdateTime:    unixTime(row.UpdateTime),
+		Deleted:       c.Op == event.RowOp_ROW_OP_DELETE,
+		Source:        c.Source,
+		CommittedAt:   c.CommittedAt,
+	}
+}
+
+// unixTime converts unix seconds; the driver stores it in the time zone of the reporting DSN.
+func unixTime(sec int64) time.Time {
+	return time.Unix(sec, 0)
+}
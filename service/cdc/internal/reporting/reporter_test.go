@@ -0,0 +1,201 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/cdc"
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/mq"
+	"github.com/aether-defense-system/service/cdc/internal/changes"
+)
+
+const changeTopic = "order-change-topic"
+
+// shanghai is the time zone of the DATETIME columns of the fixture.
+var shanghai = time.FixedZone("CST", 8*3600)
+
+// fakeStore keeps the reporting rows in memory, applying changes in binlog time order per row
+// like database.OrderReportStore.
+type fakeStore struct {
+	err        error
+	orders     map[int64]*database.ReportOrder
+	items      map[int64]*database.ReportOrderItem
+	partitions [][2]time.Time
+	mu         sync.Mutex
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		orders: make(map[int64]*database.ReportOrder),
+		items:  make(map[int64]*database.ReportOrderItem),
+	}
+}
+
+func (s *fakeStore) ApplyOrder(_ context.Context, order *database.ReportOrder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if stored, ok := s.orders[order.ID]; !ok || order.CommittedAt >= stored.CommittedAt {
+		s.orders[order.ID] = order
+	}
+	return nil
+}
+
+func (s *fakeStore) ApplyOrderItem(_ context.Context, item *database.ReportOrderItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if stored, ok := s.items[item.ID]; !ok || item.CommittedAt >= stored.CommittedAt {
+		s.items[item.ID] = item
+	}
+	return nil
+}
+
+func (s *fakeStore) EnsurePartitions(_ context.Context, from, through time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	s.partitions = append(s.partitions, [2]time.Time{from, through})
+	return 2, nil
+}
+
+// search returns the IDs of the orders created in [from, to), newest first.
+func (s *fakeStore) search(from, to time.Time, includeDeleted bool) []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var orders []*database.ReportOrder
+	for _, o := range s.orders {
+		if o.CreateTime.Before(from) || !o.CreateTime.Before(to) || (o.Deleted && !includeDeleted) {
+			continue
+		}
+		orders = append(orders, o)
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].CreateTime.Equal(orders[j].CreateTime) {
+			return orders[i].CreateTime.After(orders[j].CreateTime)
+		}
+		return orders[i].ID > orders[j].ID
+	})
+	ids := make([]int64, 0, len(orders))
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	return ids
+}
+
+func TestReporter_Fixture(t *testing.T) {
+	broker := mq.NewMemoryBroker()
+	store := newFakeStore()
+	reporter, err := NewReporter(store)
+	require.NoError(t, err)
+
+	consumer, err := broker.NewConsumer("report-consumer-group")
+	require.NoError(t, err)
+	require.NoError(t, consumer.Subscribe(changeTopic, "*", event.Default.Handler(reporter.Handle)))
+	require.NoError(t, consumer.Start())
+
+	publisher, err := changes.NewPublisher(broker.NewProducer(), changeTopic, shanghai)
+	require.NoError(t, err)
+	_, err = cdc.ReplayFile(context.Background(), "../../testdata/binlog.jsonl", publisher.Handle)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, broker.WaitIdle(ctx))
+	assert.Empty(t, broker.DeadLetters("report-consumer-group"))
+
+	// The orders created on November 11, order 1002 included: archived orders are not deleted.
+	day := time.Date(2023, 11, 11, 0, 0, 0, 0, shanghai)
+	assert.Equal(t, []int64{1002, 1004, 1001}, store.search(day, day.AddDate(0, 0, 1), false))
+	assert.Equal(t, []int64{1003}, store.search(day.AddDate(0, 0, 1), day.AddDate(0, 0, 2), false))
+
+	paid := store.orders[1001]
+	assert.Equal(t, int8(database.OrderStatusPaid), paid.Status)
+	require.NotNil(t, paid.PayTime)
+	assert.True(t, paid.PayTime.Equal(time.Date(2023, 11, 11, 10, 5, 0, 0, shanghai)))
+	assert.Equal(t, "aether_trade_0.trade_order_07", paid.Source)
+
+	assert.Equal(t, int8(database.OrderStatusRefunded), store.orders[1004].Status)
+	assert.Equal(t, int8(database.OrderStatusClosed), store.orders[1002].Status)
+	assert.False(t, store.orders[1002].Deleted)
+	assert.Equal(t, "aether_trade_1.trade_order_archive_08", store.orders[1002].Source)
+
+	require.Len(t, store.items, 4)
+	assert.Equal(t, int8(2), store.items[10041].RefundStatus)
+	assert.False(t, store.items[10021].Deleted)
+	assert.False(t, store.items[10011].Deleted)
+}
+
+func TestReporter_Handle(t *testing.T) {
+	store := newFakeStore()
+	reporter, err := NewReporter(store)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	placed := &event.OrderRowChanged{
+		Op: event.RowOp_ROW_OP_INSERT,
+		Row: &event.OrderRow{
+			OrderId: 1, UserId: 7, Status: database.OrderStatusPendingPayment, TotalAmount: 100, PayAmount: 100,
+			CreateTime: 1699668000, UpdateTime: 1699668000,
+		},
+		Source:      "aether_trade.trade_order",
+		CommittedAt: 1699668000000,
+	}
+	require.NoError(t, reporter.Handle(ctx, &event.Event{Envelope: &event.Envelope{}, Payload: placed}))
+	order := store.orders[1]
+	require.NotNil(t, order)
+	assert.Nil(t, order.PayChannel)
+	assert.Nil(t, order.OutTradeNo)
+	assert.Nil(t, order.PayTime)
+	assert.Equal(t, int64(1699668000), order.CreateTime.Unix())
+	assert.False(t, order.Deleted)
+
+	deleted := &event.OrderItemRowChanged{
+		Op:          event.RowOp_ROW_OP_DELETE,
+		Row:         &event.OrderItemRow{ItemId: 11, OrderId: 1, UserId: 7, CourseName: "Go", CreateTime: 1699668000},
+		CommittedAt: 1699668000000,
+	}
+	require.NoError(t, reporter.Handle(ctx, &event.Event{Envelope: &event.Envelope{}, Payload: deleted}))
+	assert.True(t, store.items[11].Deleted)
+
+	// Other events are ignored, and changes without a row are rejected.
+	other := &event.Event{Envelope: &event.Envelope{Type: event.TypeOrderPlaced}, Payload: &event.OrderPlaced{}}
+	require.NoError(t, reporter.Handle(ctx, other))
+	assert.Error(t, reporter.Handle(ctx, &event.Event{Envelope: &event.Envelope{}, Payload: &event.OrderRowChanged{}}))
+
+	store.err = errors.New("database unavailable")
+	assert.ErrorIs(t, reporter.Handle(ctx, &event.Event{Envelope: &event.Envelope{}, Payload: placed}), store.err)
+}
+
+func TestReporter_EnsurePartitions(t *testing.T) {
+	store := newFakeStore()
+	reporter, err := NewReporter(store)
+	require.NoError(t, err)
+
+	now := time.Date(2023, 11, 20, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, reporter.EnsurePartitions(context.Background(), now, 3))
+	require.Len(t, store.partitions, 1)
+	assert.Equal(t, now, store.partitions[0][0])
+	assert.Equal(t, time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC), store.partitions[0][1])
+
+	store.err = errors.New("database unavailable")
+	assert.Error(t, reporter.EnsurePartitions(context.Background(), now, 3))
+
+	_, err = NewReporter(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+// Package svc provides service context for the CDC service.
+package svc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/common/cdc"
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/database/migrations"
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/migrate"
+	"github.com/aether-defense-system/common/mq"
+	"github.com/aether-defense-system/service/cdc/internal/changes"
+	"github.com/aether-defense-system/service/cdc/internal/config"
+	"github.com/aether-defense-system/service/cdc/internal/reporting"
+)
+
+// partitionCheckInterval is how often the partitions of the coming months are ensured.
+const partitionCheckInterval = 24 * time.Hour
+
+// ServiceContext represents the service context for the CDC service.
+type ServiceContext struct {
+	Config    *config.Config
+	DB        *database.Client
+	Publisher *changes.Publisher
+	Reporter  *reporting.Reporter
+	producer  mq.Producer
+	// broker carries the change events when ChangeEvents has no name server; nil otherwise.
+	broker    *mq.MemoryBroker
+	stop      context.CancelFunc
+	consumers []mq.Consumer
+	wg        sync.WaitGroup
+}
+
+// NewServiceContext creates a new service context.
+func NewServiceContext(c *config.Config) *ServiceContext {
+	loc, err := c.Location()
+	if err != nil {
+		panic(err.Error())
+	}
+
+	client, err := database.NewClient(&c.Database)
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialize database: %v", err))
+	}
+	if c.AutoMigrate {
+		err = migrations.Up(context.Background(), []*migrate.Migrator{migrate.New(client.DB())}, migrations.Reporting)
+		if err != nil {
+			panic(fmt.Sprintf("failed to migrate the database: %v", err))
+		}
+	}
+
+	var (
+		producer mq.Producer
+		broker   *mq.MemoryBroker
+	)
+	if c.ChangeEvents.NameServer != "" {
+		producer, err = mq.NewProducer(&c.ChangeEvents)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize change event producer: %v", err))
+		}
+	} else {
+		broker = mq.NewMemoryBroker()
+		producer = broker.NewProducer()
+	}
+
+	publisher, err := changes.NewPublisher(producer, c.ChangeEvents.Topic, loc)
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialize change event publisher: %v", err))
+	}
+	reporter, err := reporting.NewReporter(database.NewOrderReportStore(client.DB()))
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialize reporter: %v", err))
+	}
+
+	return &ServiceContext{
+		Config:    c,
+		DB:        client,
+		Publisher: publisher,
+		Reporter:  reporter,
+		producer:  producer,
+		broker:    broker,
+	}
+}
+
+// Start ensures the reporting partitions of the coming months, starts consuming the change events
+// into the reporting database and, when RowEvents is configured, the binlog into change events.
+func (s *ServiceContext) Start(ctx context.Context) error {
+	ahead := s.Config.Reporting.GetPartitionsAhead()
+	if err := s.Reporter.EnsurePartitions(ctx, time.Now(), ahead); err != nil {
+		return err
+	}
+
+	consumer, err := s.newChangeConsumer()
+	if err != nil {
+		return err
+	}
+	handler := event.Default.Handler(s.Reporter.Handle)
+	err = s.start(consumer, s.Config.ChangeEvents.Topic, event.TypeOrderRowChanged+" || "+event.TypeOrderItemRowChanged,
+		handler)
+	if err != nil {
+		return fmt.Errorf("failed to start change event consumer: %w", err)
+	}
+
+	if s.Config.RowEvents.NameServer != "" {
+		consumer, err = mq.NewConsumer(&s.Config.RowEvents)
+		if err != nil {
+			return fmt.Errorf("failed to create row event consumer: %w", err)
+		}
+		if err = s.start(consumer, s.Config.RowEvents.Topic, "*", cdc.MessageHandler(s.Publisher.Handle)); err != nil {
+			return fmt.Errorf("failed to start row event consumer: %w", err)
+		}
+	}
+
+	maintainCtx, stop := context.WithCancel(context.Background())
+	s.stop = stop
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.Reporter.MaintainPartitions(maintainCtx, ahead, partitionCheckInterval)
+	}()
+	return nil
+}
+
+// Replay publishes the changes of a file of binlog row events, one Canal flat message per line.
+// Without a change event name server it also waits until the reporting database has applied them.
+func (s *ServiceContext) Replay(ctx context.Context, path string) (int, error) {
+	handled, err := cdc.ReplayFile(ctx, path, s.Publisher.Handle)
+	if err != nil {
+		return handled, err
+	}
+	if s.broker != nil {
+		if err := s.broker.WaitIdle(ctx); err != nil {
+			return handled, fmt.Errorf("failed to wait for the reporting database: %w", err)
+		}
+	}
+	return handled, nil
+}
+
+// Close stops the consumers and releases the producer and the database.
+func (s *ServiceContext) Close() {
+	if s.stop != nil {
+		s.stop()
+	}
+	s.wg.Wait()
+	for _, consumer := range s.consumers {
+		if err := consumer.Shutdown(); err != nil {
+			logx.Errorf("failed to shut down consumer: %v", err)
+		}
+	}
+	if err := s.producer.Shutdown(); err != nil {
+		logx.Errorf("failed to shut down change event producer: %v", err)
+	}
+	if s.broker != nil {
+		s.broker.Shutdown()
+	}
+	if err := s.DB.Close(); err != nil {
+		logx.Errorf("failed to close database: %v", err)
+	}
+}
+
+// newChangeConsumer creates the consumer of the change events in the reporting group. Applying a
+// change is idempotent, so redelivered events need no deduplication.
+func (s *ServiceContext) newChangeConsumer() (mq.Consumer, error) {
+	group := s.Config.Reporting.Group
+	if s.broker != nil {
+		return s.broker.NewConsumer(group)
+	}
+	cfg := s.Config.ChangeEvents
+	cfg.Group = group
+	consumer, err := mq.NewConsumer(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create change event consumer: %w", err)
+	}
+	return consumer, nil
+}
+
+// start subscribes consumer to topic and starts it, shutting it down along with the service.
+func (s *ServiceContext) start(consumer mq.Consumer, topic, tagExpr string, handler mq.Handler) error {
+	if err := consumer.Subscribe(topic, tagExpr, handler); err != nil {
+		return err
+	}
+	if err := consumer.Start(); err != nil {
+		return err
+	}
+	s.consumers = append(s.consumers, consumer)
+	return nil
+}
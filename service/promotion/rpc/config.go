@@ -5,6 +5,7 @@ import (
 	"github.com/zeromicro/go-zero/zrpc"
 
 	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/mq"
 	"github.com/aether-defense-system/common/redis"
 )
 
@@ -14,8 +15,18 @@ import (
 type Config struct {
 	zrpc.RpcServerConf
 	Database database.Config `json:"database" yaml:"database"`
+	// Sharding spreads coupon records over databases by user_id; they live in Database when unset.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Sharding database.ShardingConfig `json:"sharding,optional" yaml:"sharding"`
+	// OrderEvents is the RocketMQ consumer of trade's order events; consuming is off without a NameServer.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	OrderEvents mq.Config `json:"orderEvents,optional" yaml:"orderEvents"`
 	// InventoryRedis is the Redis used by business logic (stock deduction, etc.).
 	// zrpc.RpcServerConf already contains a Redis field (redis.RedisKeyConf) used for RPC auth,
 	// so we must not reuse the same config key here.
 	InventoryRedis redis.Config `json:"inventoryRedis" yaml:"inventoryRedis"`
+	// AutoMigrate applies the pending common and promotion schema migrations at startup, in every
+	// sharding database too. Without it the schema is migrated with the migrate command.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	AutoMigrate bool `json:"autoMigrate,optional" yaml:"autoMigrate"`
 }
@@ -0,0 +1,161 @@
+// Package consumer consumes the events other services publish for promotion.
+package consumer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/mq"
+	"github.com/aether-defense-system/service/promotion/rpc/svc"
+)
+
+// SubscribedEvents is the tag expression of the order events OrderEvents handles.
+const SubscribedEvents = event.TypeOrderPlaced + " || " + event.TypeOrderRefunded
+
+// OrderEvents deducts course inventory for the orders trade places, and gives back the inventory
+// and coupons of the orders it refunds.
+type OrderEvents struct {
+	svcCtx *svc.ServiceContext
+}
+
+// NewOrderEvents creates an OrderEvents handler.
+func NewOrderEvents(svcCtx *svc.ServiceContext) *OrderEvents {
+	return &OrderEvents{svcCtx: svcCtx}
+}
+
+// Start subscribes to ORDER_PLACED and ORDER_REFUNDED events and starts consuming them, each event
+// at most once when a dedupe store is available and with failures recorded when a failure log is.
+// It returns a nil consumer when OrderEvents is not configured.
+func Start(svcCtx *svc.ServiceContext) (mq.Consumer, error) {
+	cfg := svcCtx.Config.OrderEvents
+	if cfg.NameServer == "" {
+		return nil, nil
+	}
+
+	consumer, err := mq.NewConsumer(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order events consumer: %w", err)
+	}
+	handler := event.Default.Handler(NewOrderEvents(svcCtx).Handle)
+	if svcCtx.ConsumedMessages != nil {
+		var idempotent *mq.IdempotentHandler
+		idempotent, err = mq.NewIdempotentHandler(cfg.Group, svcCtx.ConsumedMessages, handler,
+			mq.WithDedupeKey(event.Default.EventID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create idempotent handler: %w", err)
+		}
+		handler = idempotent.Handle
+	}
+	if svcCtx.ConsumeFailures != nil {
+		handler = mq.RecordFailures(cfg.Group, svcCtx.ConsumeFailures, handler)
+	}
+	if err := consumer.Subscribe(cfg.Topic, SubscribedEvents, handler); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to order events: %w", err)
+	}
+	if err := consumer.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start order events consumer: %w", err)
+	}
+	return consumer, nil
+}
+
+// Handle handles one order event. Events of other types are ignored.
+func (o *OrderEvents) Handle(ctx context.Context, e *event.Event) error {
+	switch payload := e.Payload.(type) {
+	case *event.OrderPlaced:
+		return o.orderPlaced(ctx, payload)
+	case *event.OrderRefunded:
+		return o.orderRefunded(ctx, payload)
+	default:
+		logx.WithContext(ctx).Infof("ignoring event %s of type %s", e.Envelope.EventId, e.Envelope.Type)
+		return nil
+	}
+}
+
+// orderPlaced deducts one unit of every course of the order. If a course cannot be deducted, the
+// units already deducted are restored and the error is returned, so that the broker redelivers
+// the event.
+func (o *OrderEvents) orderPlaced(ctx context.Context, placed *event.OrderPlaced) error {
+	logger := logx.WithContext(ctx)
+
+	if o.svcCtx.Redis == nil {
+		logger.Errorf("Redis client not initialized")
+		return fmt.Errorf("redis client not available")
+	}
+
+	for i, courseID := range placed.CourseIds {
+		inventoryKey := fmt.Sprintf("inventory:course:%d", courseID)
+		if err := o.svcCtx.Redis.DecrStock(ctx, inventoryKey, 1); err != nil {
+			logger.Errorf("failed to decrement stock: %v, orderId=%d, courseId=%d", err, placed.OrderId, courseID)
+			o.restore(ctx, placed.OrderId, placed.CourseIds[:i])
+			return fmt.Errorf("failed to decrement stock of course %d: %w", courseID, err)
+		}
+	}
+
+	logger.Infof("deducted stock for order: orderId=%d, courses=%d", placed.OrderId, len(placed.CourseIds))
+	return nil
+}
+
+// orderRefunded returns the coupons of a fully refunded order, then restores one unit of every
+// refunded course. Returning coupons is idempotent, so it goes first: if a course cannot be
+// restored, the units already restored are deducted again and the error is returned, so that the
+// broker redelivers the event.
+func (o *OrderEvents) orderRefunded(ctx context.Context, refunded *event.OrderRefunded) error {
+	logger := logx.WithContext(ctx)
+
+	if o.svcCtx.Redis == nil {
+		logger.Errorf("Redis client not initialized")
+		return fmt.Errorf("redis client not available")
+	}
+
+	// Partially refunded orders keep their coupons consumed: the discount stays with the items kept.
+	if refunded.OrderRefunded {
+		if o.svcCtx.CouponRepo == nil {
+			logger.Errorf("coupon repository not initialized")
+			return fmt.Errorf("coupon repository not available")
+		}
+		returned, err := o.svcCtx.CouponRepo.ReturnByOrderID(ctx, refunded.UserId, refunded.OrderId)
+		if err != nil {
+			logger.Errorf("failed to return coupons: %v, orderId=%d", err, refunded.OrderId)
+			return fmt.Errorf("failed to return coupons of order %d: %w", refunded.OrderId, err)
+		}
+		logger.Infof("returned coupons of refunded order: orderId=%d, count=%d", refunded.OrderId, returned)
+	}
+
+	for i, courseID := range refunded.CourseIds {
+		inventoryKey := fmt.Sprintf("inventory:course:%d", courseID)
+		if err := o.svcCtx.Redis.IncrStock(ctx, inventoryKey, 1); err != nil {
+			logger.Errorf("failed to restore stock: %v, refundId=%d, courseId=%d", err, refunded.RefundId, courseID)
+			o.deduct(ctx, refunded.RefundId, refunded.CourseIds[:i])
+			return fmt.Errorf("failed to restore stock of course %d: %w", courseID, err)
+		}
+	}
+
+	logger.Infof("restored stock for refund: refundId=%d, orderId=%d, courses=%d",
+		refunded.RefundId, refunded.OrderId, len(refunded.CourseIds))
+	return nil
+}
+
+// deduct takes back one unit of each course, undoing a partial restoration.
+func (o *OrderEvents) deduct(ctx context.Context, refundID int64, courseIDs []int64) {
+	for _, courseID := range courseIDs {
+		inventoryKey := fmt.Sprintf("inventory:course:%d", courseID)
+		if err := o.svcCtx.Redis.DecrStock(ctx, inventoryKey, 1); err != nil {
+			logx.WithContext(ctx).Errorf("failed to take back restored stock: %v, refundId=%d, courseId=%d",
+				err, refundID, courseID)
+		}
+	}
+}
+
+// restore returns one unit of each course, undoing a partial deduction.
+func (o *OrderEvents) restore(ctx context.Context, orderID int64, courseIDs []int64) {
+	for _, courseID := range courseIDs {
+		inventoryKey := fmt.Sprintf("inventory:course:%d", courseID)
+		if err := o.svcCtx.Redis.IncrStock(ctx, inventoryKey, 1); err != nil {
+			logx.WithContext(ctx).Errorf("failed to restore stock: %v, orderId=%d, courseId=%d",
+				err, orderID, courseID)
+		}
+	}
+}
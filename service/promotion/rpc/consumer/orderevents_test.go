@@ -0,0 +1,194 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/mq"
+	"github.com/aether-defense-system/service/promotion/rpc/internal/config"
+	"github.com/aether-defense-system/service/promotion/rpc/svc"
+)
+
+// fakeInventoryRedis keeps stock in a map and refuses to go below zero, like the Lua script.
+type fakeInventoryRedis struct {
+	store map[string]int64
+}
+
+func (f *fakeInventoryRedis) Get(_ context.Context, key string) (string, error) {
+	return fmt.Sprint(f.store[key]), nil
+}
+
+func (f *fakeInventoryRedis) DecrStock(_ context.Context, inventoryKey string, quantity int64) error {
+	if f.store[inventoryKey] < quantity {
+		return fmt.Errorf("insufficient stock")
+	}
+	f.store[inventoryKey] -= quantity
+	return nil
+}
+
+func (f *fakeInventoryRedis) IncrStock(_ context.Context, inventoryKey string, quantity int64) error {
+	f.store[inventoryKey] += quantity
+	return nil
+}
+
+// fakeCouponRepo records the orders whose coupons were returned.
+type fakeCouponRepo struct {
+	err      error
+	returned []int64
+}
+
+func (f *fakeCouponRepo) ReturnByOrderID(_ context.Context, _, orderID int64) (int64, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	f.returned = append(f.returned, orderID)
+	return 1, nil
+}
+
+func newOrderPlaced(t *testing.T, placed *event.OrderPlaced) *event.Event {
+	t.Helper()
+	env, err := event.Default.NewEnvelope(event.TypeOrderPlaced, fmt.Sprint(placed.OrderId), placed)
+	if err != nil {
+		t.Fatalf("failed to build event: %v", err)
+	}
+	return &event.Event{Envelope: env, Payload: placed}
+}
+
+func TestOrderEvents_Handle_OrderPlaced(t *testing.T) {
+	redis := &fakeInventoryRedis{store: map[string]int64{
+		"inventory:course:1": 2,
+		"inventory:course:2": 1,
+	}}
+	handler := NewOrderEvents(&svc.ServiceContext{Config: &config.Config{}, Redis: redis})
+
+	err := handler.Handle(context.Background(), newOrderPlaced(t, &event.OrderPlaced{OrderId: 1, CourseIds: []int64{1, 2}}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if redis.store["inventory:course:1"] != 1 || redis.store["inventory:course:2"] != 0 {
+		t.Fatalf("unexpected stock after deduction: %v", redis.store)
+	}
+
+	// Course 2 is sold out: course 1 is restored and the event fails, to be redelivered.
+	err = handler.Handle(context.Background(), newOrderPlaced(t, &event.OrderPlaced{OrderId: 2, CourseIds: []int64{1, 2}}))
+	if err == nil {
+		t.Fatalf("expected error for sold out course")
+	}
+	if redis.store["inventory:course:1"] != 1 {
+		t.Errorf("expected course 1 stock to be restored to 1, got %d", redis.store["inventory:course:1"])
+	}
+}
+
+func newOrderRefunded(t *testing.T, refunded *event.OrderRefunded) *event.Event {
+	t.Helper()
+	env, err := event.Default.NewEnvelope(event.TypeOrderRefunded, fmt.Sprint(refunded.OrderId), refunded)
+	if err != nil {
+		t.Fatalf("failed to build event: %v", err)
+	}
+	return &event.Event{Envelope: env, Payload: refunded}
+}
+
+func TestOrderEvents_Handle_OrderRefunded(t *testing.T) {
+	redis := &fakeInventoryRedis{store: map[string]int64{"inventory:course:1": 0, "inventory:course:2": 3}}
+	coupons := &fakeCouponRepo{}
+	handler := NewOrderEvents(&svc.ServiceContext{Config: &config.Config{}, Redis: redis, CouponRepo: coupons})
+
+	// A partial refund restores the stock of its courses and leaves the coupons consumed.
+	err := handler.Handle(context.Background(), newOrderRefunded(t, &event.OrderRefunded{
+		RefundId: 10, OrderId: 1, UserId: 7, CourseIds: []int64{1},
+	}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if redis.store["inventory:course:1"] != 1 || len(coupons.returned) != 0 {
+		t.Fatalf("unexpected state after partial refund: stock %v, coupons %v", redis.store, coupons.returned)
+	}
+
+	// The refund completing the order also returns its coupons.
+	err = handler.Handle(context.Background(), newOrderRefunded(t, &event.OrderRefunded{
+		RefundId: 11, OrderId: 1, UserId: 7, CourseIds: []int64{2}, OrderRefunded: true,
+	}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if redis.store["inventory:course:2"] != 4 || len(coupons.returned) != 1 || coupons.returned[0] != 1 {
+		t.Fatalf("unexpected state after full refund: stock %v, coupons %v", redis.store, coupons.returned)
+	}
+
+	// When the coupons cannot be returned, no stock is restored and the event is redelivered.
+	coupons.err = fmt.Errorf("database unavailable")
+	err = handler.Handle(context.Background(), newOrderRefunded(t, &event.OrderRefunded{
+		RefundId: 12, OrderId: 2, UserId: 7, CourseIds: []int64{2}, OrderRefunded: true,
+	}))
+	if err == nil {
+		t.Fatalf("expected error when coupons cannot be returned")
+	}
+	if redis.store["inventory:course:2"] != 4 {
+		t.Errorf("expected course 2 stock to stay 4, got %d", redis.store["inventory:course:2"])
+	}
+}
+
+func TestOrderEvents_Handle_IgnoresOtherEvents(t *testing.T) {
+	handler := NewOrderEvents(&svc.ServiceContext{Config: &config.Config{}})
+	env, err := event.Default.NewEnvelope(event.TypeOrderPaid, "1", &event.OrderPaid{OrderId: 1})
+	if err != nil {
+		t.Fatalf("failed to build event: %v", err)
+	}
+
+	if err := handler.Handle(context.Background(), &event.Event{Envelope: env, Payload: &event.OrderPaid{}}); err != nil {
+		t.Fatalf("expected other events to be ignored, got %v", err)
+	}
+}
+
+func TestOrderEvents_Handle_NoRedis(t *testing.T) {
+	handler := NewOrderEvents(&svc.ServiceContext{Config: &config.Config{}})
+	err := handler.Handle(context.Background(), newOrderPlaced(t, &event.OrderPlaced{OrderId: 1, CourseIds: []int64{1}}))
+	if err == nil {
+		t.Fatalf("expected error when Redis is not configured")
+	}
+}
+
+func TestOrderEvents_ThroughBroker(t *testing.T) {
+	broker := mq.NewMemoryBroker()
+	defer broker.Shutdown()
+
+	redis := &fakeInventoryRedis{store: map[string]int64{"inventory:course:1": 5}}
+	handler := NewOrderEvents(&svc.ServiceContext{Config: &config.Config{}, Redis: redis})
+	consumer, err := broker.NewConsumer("promotion")
+	if err != nil {
+		t.Fatalf("failed to create consumer: %v", err)
+	}
+	if err := consumer.Subscribe("orders", SubscribedEvents, event.Default.Handler(handler.Handle)); err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	if err := consumer.Start(); err != nil {
+		t.Fatalf("failed to start consumer: %v", err)
+	}
+
+	// A JSON order message published before the event envelope is still understood.
+	producer := broker.NewProducer()
+	legacy := mq.NewMessage("orders", []byte(`{"orderId":1,"userId":1,"courseIds":[1],"realAmount":100}`)).
+		WithTag(event.TypeOrderPlaced)
+	if _, err := producer.Send(context.Background(), legacy); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := broker.WaitIdle(ctx); err != nil {
+		t.Fatalf("broker did not settle: %v", err)
+	}
+	if redis.store["inventory:course:1"] != 4 {
+		t.Errorf("expected stock 4, got %d", redis.store["inventory:course:1"])
+	}
+}
+
+func TestStart_NotConfigured(t *testing.T) {
+	consumer, err := Start(&svc.ServiceContext{Config: &config.Config{}})
+	if err != nil || consumer != nil {
+		t.Fatalf("expected no consumer without a NameServer, got %v, %v", consumer, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/zeromicro/go-zero/zrpc"
 
 	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/mq"
 	"github.com/aether-defense-system/common/redis"
 )
 
@@ -13,6 +14,12 @@ import (
 // The public Config is defined in the parent rpc package.
 type Config struct {
 	zrpc.RpcServerConf
-	Database       database.Config `json:"database" yaml:"database"`
-	InventoryRedis redis.Config    `json:"inventoryRedis" yaml:"inventoryRedis"`
+	Database database.Config `json:"database" yaml:"database"`
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Sharding database.ShardingConfig `json:"sharding,optional" yaml:"sharding"`
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	OrderEvents    mq.Config    `json:"orderEvents,optional" yaml:"orderEvents"`
+	InventoryRedis redis.Config `json:"inventoryRedis" yaml:"inventoryRedis"`
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	AutoMigrate bool `json:"autoMigrate,optional" yaml:"autoMigrate"`
 }
@@ -76,6 +76,7 @@ type fakeInventoryRedis struct {
 	getBeforeErr error
 	getAfterErr  error
 	decrErr      error
+	incrErr      error
 
 	getCall int
 }
@@ -115,6 +116,21 @@ func (f *fakeInventoryRedis) DecrStock(_ context.Context, inventoryKey string, q
 	return nil
 }
 
+func (f *fakeInventoryRedis) IncrStock(_ context.Context, inventoryKey string, quantity int64) error {
+	if f.incrErr != nil {
+		return f.incrErr
+	}
+	if f.store == nil {
+		return fmt.Errorf("store not initialized")
+	}
+	cur, ok := f.store[inventoryKey]
+	if !ok {
+		return fmt.Errorf("key not found")
+	}
+	f.store[inventoryKey] = cur + quantity
+	return nil
+}
+
 func TestDecrStockLogic_DecrStock_NoRedisConfigured(t *testing.T) {
 	cfg := &config.Config{}
 	svcCtx := &svc.ServiceContext{Config: cfg, Redis: nil}
@@ -0,0 +1,70 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aether-defense-system/service/promotion/rpc"
+	"github.com/aether-defense-system/service/promotion/rpc/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// IncrStockLogic handles inventory restoration logic.
+type IncrStockLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewIncrStockLogic creates a new IncrStockLogic instance.
+func NewIncrStockLogic(ctx context.Context, svcCtx *svc.ServiceContext) *IncrStockLogic {
+	return &IncrStockLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// IncrStock returns refunded units to a course's inventory.
+//
+// It mirrors DecrStock: failures of the inventory layer are reported in the
+// response rather than as an RPC error, so callers can decide whether to retry.
+func (l *IncrStockLogic) IncrStock(req *rpc.IncrStockRequest) (*rpc.IncrStockResponse, error) {
+	if req == nil {
+		l.Errorf("received nil IncrStockRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	if req.CourseId <= 0 {
+		l.Errorf("invalid course_id: %d", req.CourseId)
+		return nil, fmt.Errorf("invalid course_id: %d", req.CourseId)
+	}
+
+	if req.Num <= 0 {
+		l.Errorf("invalid num: %d for course_id: %d", req.Num, req.CourseId)
+		return nil, fmt.Errorf("num must be greater than 0")
+	}
+
+	if l.svcCtx.Redis == nil {
+		l.Errorf("Redis client not initialized")
+		return nil, fmt.Errorf("redis client not available")
+	}
+
+	inventoryKey := fmt.Sprintf("inventory:course:%d", req.CourseId)
+
+	if err := l.svcCtx.Redis.IncrStock(l.ctx, inventoryKey, int64(req.Num)); err != nil {
+		l.Errorf("failed to increment stock: %v, courseId=%d, num=%d", err, req.CourseId, req.Num)
+		return &rpc.IncrStockResponse{
+			Success: false,
+			Message: fmt.Sprintf("Inventory restoration failed: %v", err),
+		}, nil
+	}
+
+	l.Infof("successfully incremented stock: courseId=%d, num=%d", req.CourseId, req.Num)
+
+	return &rpc.IncrStockResponse{
+		Success: true,
+		Message: "Inventory restoration successful",
+	}, nil
+}
@@ -0,0 +1,73 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aether-defense-system/service/promotion/rpc"
+	"github.com/aether-defense-system/service/promotion/rpc/internal/config"
+	"github.com/aether-defense-system/service/promotion/rpc/svc"
+)
+
+func TestIncrStockLogic_IncrStock_Validation(t *testing.T) {
+	svcCtx := &svc.ServiceContext{Config: &config.Config{}, Redis: &fakeInventoryRedis{}}
+	logic := NewIncrStockLogic(context.Background(), svcCtx)
+
+	tests := []struct {
+		req  *rpc.IncrStockRequest
+		name string
+	}{
+		{name: "nil request", req: nil},
+		{name: "invalid course id", req: &rpc.IncrStockRequest{CourseId: 0, Num: 1}},
+		{name: "invalid num", req: &rpc.IncrStockRequest{CourseId: 1, Num: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := logic.IncrStock(tt.req); err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestIncrStockLogic_IncrStock_NoRedisConfigured(t *testing.T) {
+	svcCtx := &svc.ServiceContext{Config: &config.Config{}, Redis: nil}
+	logic := NewIncrStockLogic(context.Background(), svcCtx)
+
+	if _, err := logic.IncrStock(&rpc.IncrStockRequest{CourseId: 1, Num: 1}); err == nil {
+		t.Fatalf("expected error when Redis is nil")
+	}
+}
+
+func TestIncrStockLogic_IncrStock_Success(t *testing.T) {
+	fake := &fakeInventoryRedis{store: map[string]int64{"inventory:course:1": 5}}
+	svcCtx := &svc.ServiceContext{Config: &config.Config{}, Redis: fake}
+	logic := NewIncrStockLogic(context.Background(), svcCtx)
+
+	resp, err := logic.IncrStock(&rpc.IncrStockRequest{CourseId: 1, Num: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected Success=true, got false (message=%q)", resp.Message)
+	}
+	if got := fake.store["inventory:course:1"]; got != 7 {
+		t.Fatalf("expected stock 7, got %d", got)
+	}
+}
+
+func TestIncrStockLogic_IncrStock_RedisFailure(t *testing.T) {
+	fake := &fakeInventoryRedis{incrErr: fmt.Errorf("inventory key does not exist")}
+	svcCtx := &svc.ServiceContext{Config: &config.Config{}, Redis: fake}
+	logic := NewIncrStockLogic(context.Background(), svcCtx)
+
+	resp, err := logic.IncrStock(&rpc.IncrStockRequest{CourseId: 1, Num: 1})
+	if err != nil {
+		t.Fatalf("expected failure in response, got error: %v", err)
+	}
+	if resp.Success {
+		t.Fatalf("expected Success=false")
+	}
+}
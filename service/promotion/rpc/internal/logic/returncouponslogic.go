@@ -0,0 +1,65 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aether-defense-system/service/promotion/rpc"
+	"github.com/aether-defense-system/service/promotion/rpc/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ReturnCouponsLogic handles coupon return logic for refunded orders.
+type ReturnCouponsLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewReturnCouponsLogic creates a new ReturnCouponsLogic instance.
+func NewReturnCouponsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReturnCouponsLogic {
+	return &ReturnCouponsLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// ReturnCoupons restores the coupons used by an order to unused.
+//
+// Coupons are only returned once an order is fully refunded, as the ORDER_REFUNDED
+// consumer does: on a partial refund the discount stays consumed, because it is already
+// spread over the remaining items' real_pay_amount. The call is idempotent; a second
+// call returns zero coupons.
+func (l *ReturnCouponsLogic) ReturnCoupons(req *rpc.ReturnCouponsRequest) (*rpc.ReturnCouponsResponse, error) {
+	if req == nil {
+		l.Errorf("received nil ReturnCouponsRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	if req.UserId <= 0 {
+		l.Errorf("invalid user_id: %d", req.UserId)
+		return nil, fmt.Errorf("invalid user_id: %d", req.UserId)
+	}
+
+	if req.OrderId <= 0 {
+		l.Errorf("invalid order_id: %d", req.OrderId)
+		return nil, fmt.Errorf("invalid order_id: %d", req.OrderId)
+	}
+
+	if l.svcCtx.CouponRepo == nil {
+		l.Errorf("coupon repository not initialized")
+		return nil, fmt.Errorf("coupon repository not available")
+	}
+
+	returned, err := l.svcCtx.CouponRepo.ReturnByOrderID(l.ctx, req.UserId, req.OrderId)
+	if err != nil {
+		l.Errorf("failed to return coupons: %v, userId=%d, orderId=%d", err, req.UserId, req.OrderId)
+		return nil, fmt.Errorf("failed to return coupons: %w", err)
+	}
+
+	l.Infof("returned coupons: userId=%d, orderId=%d, count=%d", req.UserId, req.OrderId, returned)
+
+	return &rpc.ReturnCouponsResponse{ReturnedCount: int32(returned)}, nil
+}
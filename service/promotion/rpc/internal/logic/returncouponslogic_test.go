@@ -0,0 +1,113 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/service/promotion/rpc"
+	"github.com/aether-defense-system/service/promotion/rpc/internal/config"
+	"github.com/aether-defense-system/service/promotion/rpc/repo"
+	"github.com/aether-defense-system/service/promotion/rpc/svc"
+)
+
+// fakeCouponRepo counts the calls to an in-memory coupon repository, and fails them with err when set.
+type fakeCouponRepo struct {
+	*repo.MemoryCouponRepo
+	err   error
+	calls int
+}
+
+func newFakeCouponRepo(coupons ...*database.PromotionCouponRecord) *fakeCouponRepo {
+	memory := repo.NewMemoryCouponRepo()
+	memory.Put(coupons...)
+	return &fakeCouponRepo{MemoryCouponRepo: memory}
+}
+
+func (f *fakeCouponRepo) ReturnByOrderID(ctx context.Context, userID, orderID int64) (int64, error) {
+	f.calls++
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.MemoryCouponRepo.ReturnByOrderID(ctx, userID, orderID)
+}
+
+func TestReturnCouponsLogic_ReturnCoupons_Validation(t *testing.T) {
+	coupons := newFakeCouponRepo()
+	svcCtx := &svc.ServiceContext{Config: &config.Config{}, CouponRepo: coupons}
+	logic := NewReturnCouponsLogic(context.Background(), svcCtx)
+
+	tests := []struct {
+		req  *rpc.ReturnCouponsRequest
+		name string
+	}{
+		{name: "nil request", req: nil},
+		{name: "invalid user id", req: &rpc.ReturnCouponsRequest{UserId: 0, OrderId: 1}},
+		{name: "invalid order id", req: &rpc.ReturnCouponsRequest{UserId: 1, OrderId: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := logic.ReturnCoupons(tt.req); err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+		})
+	}
+
+	if coupons.calls != 0 {
+		t.Fatalf("expected no repository calls for invalid requests, got %d", coupons.calls)
+	}
+}
+
+func TestReturnCouponsLogic_ReturnCoupons_NoRepository(t *testing.T) {
+	svcCtx := &svc.ServiceContext{Config: &config.Config{}}
+	logic := NewReturnCouponsLogic(context.Background(), svcCtx)
+
+	if _, err := logic.ReturnCoupons(&rpc.ReturnCouponsRequest{UserId: 1, OrderId: 1}); err == nil {
+		t.Fatalf("expected error when coupon repository is nil")
+	}
+}
+
+func TestReturnCouponsLogic_ReturnCoupons_Idempotent(t *testing.T) {
+	orderID := int64(100)
+	coupons := newFakeCouponRepo(
+		&database.PromotionCouponRecord{
+			ID: 1, UserID: 1, TemplateID: 10, Status: database.CouponStatusUsed, OrderID: &orderID,
+		},
+		&database.PromotionCouponRecord{
+			ID: 2, UserID: 1, TemplateID: 11, Status: database.CouponStatusUsed, OrderID: &orderID,
+		},
+	)
+	svcCtx := &svc.ServiceContext{Config: &config.Config{}, CouponRepo: coupons}
+	logic := NewReturnCouponsLogic(context.Background(), svcCtx)
+
+	req := &rpc.ReturnCouponsRequest{UserId: 1, OrderId: 100}
+
+	resp, err := logic.ReturnCoupons(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.ReturnedCount != 2 {
+		t.Fatalf("expected 2 returned coupons, got %d", resp.ReturnedCount)
+	}
+
+	resp, err = logic.ReturnCoupons(req)
+	if err != nil {
+		t.Fatalf("unexpected error on retry: %v", err)
+	}
+	if resp.ReturnedCount != 0 {
+		t.Fatalf("expected 0 returned coupons on retry, got %d", resp.ReturnedCount)
+	}
+}
+
+func TestReturnCouponsLogic_ReturnCoupons_RepositoryError(t *testing.T) {
+	repoErr := errors.New("db down")
+	svcCtx := &svc.ServiceContext{Config: &config.Config{}, CouponRepo: &fakeCouponRepo{err: repoErr}}
+	logic := NewReturnCouponsLogic(context.Background(), svcCtx)
+
+	_, err := logic.ReturnCoupons(&rpc.ReturnCouponsRequest{UserId: 1, OrderId: 1})
+	if !errors.Is(err, repoErr) {
+		t.Fatalf("expected wrapped repository error, got %v", err)
+	}
+}
@@ -23,3 +23,13 @@ func (s *PromotionService) DecrStock(ctx context.Context, _ *DecrStockRequest) (
 		Message: "Service not properly initialized. Use server.PromotionServiceServer instead.",
 	}, nil
 }
+
+// IncrStock increment inventory.
+// This is a placeholder. Use internal/server.PromotionServiceServer for actual implementation.
+func (s *PromotionService) IncrStock(ctx context.Context, _ *IncrStockRequest) (*IncrStockResponse, error) {
+	logx.WithContext(ctx).Errorf("PromotionService.IncrStock: service not properly initialized")
+	return &IncrStockResponse{
+		Success: false,
+		Message: "Service not properly initialized. Use server.PromotionServiceServer instead.",
+	}, nil
+}
@@ -127,6 +127,210 @@ func (x *DecrStockResponse) GetMessage() string {
 	return ""
 }
 
+// Increment Inventory Request Parameters
+type IncrStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CourseId      int64                  `protobuf:"varint,1,opt,name=courseId,proto3" json:"courseId,omitempty"` // Course ID
+	Num           int32                  `protobuf:"varint,2,opt,name=num,proto3" json:"num,omitempty"`           // Restored Quantity
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IncrStockRequest) Reset() {
+	*x = IncrStockRequest{}
+	mi := &file_service_promotion_rpc_promotion_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncrStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncrStockRequest) ProtoMessage() {}
+
+func (x *IncrStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_promotion_rpc_promotion_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncrStockRequest.ProtoReflect.Descriptor instead.
+func (*IncrStockRequest) Descriptor() ([]byte, []int) {
+	return file_service_promotion_rpc_promotion_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IncrStockRequest) GetCourseId() int64 {
+	if x != nil {
+		return x.CourseId
+	}
+	return 0
+}
+
+func (x *IncrStockRequest) GetNum() int32 {
+	if x != nil {
+		return x.Num
+	}
+	return 0
+}
+
+// Increment Inventory Response Parameters
+type IncrStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"` // Success Status
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`  // Return Message
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IncrStockResponse) Reset() {
+	*x = IncrStockResponse{}
+	mi := &file_service_promotion_rpc_promotion_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncrStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncrStockResponse) ProtoMessage() {}
+
+func (x *IncrStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_promotion_rpc_promotion_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncrStockResponse.ProtoReflect.Descriptor instead.
+func (*IncrStockResponse) Descriptor() ([]byte, []int) {
+	return file_service_promotion_rpc_promotion_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *IncrStockResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *IncrStockResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Return Coupons Request Parameters
+type ReturnCouponsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=userId,proto3" json:"userId,omitempty"`   // Coupon owner user ID
+	OrderId       int64                  `protobuf:"varint,2,opt,name=orderId,proto3" json:"orderId,omitempty"` // Order whose coupons are returned
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReturnCouponsRequest) Reset() {
+	*x = ReturnCouponsRequest{}
+	mi := &file_service_promotion_rpc_promotion_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReturnCouponsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReturnCouponsRequest) ProtoMessage() {}
+
+func (x *ReturnCouponsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_promotion_rpc_promotion_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReturnCouponsRequest.ProtoReflect.Descriptor instead.
+func (*ReturnCouponsRequest) Descriptor() ([]byte, []int) {
+	return file_service_promotion_rpc_promotion_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ReturnCouponsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ReturnCouponsRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+// Return Coupons Response Parameters
+type ReturnCouponsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReturnedCount int32                  `protobuf:"varint,1,opt,name=returnedCount,proto3" json:"returnedCount,omitempty"` // Number of coupons restored to unused
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReturnCouponsResponse) Reset() {
+	*x = ReturnCouponsResponse{}
+	mi := &file_service_promotion_rpc_promotion_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReturnCouponsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReturnCouponsResponse) ProtoMessage() {}
+
+func (x *ReturnCouponsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_promotion_rpc_promotion_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReturnCouponsResponse.ProtoReflect.Descriptor instead.
+func (*ReturnCouponsResponse) Descriptor() ([]byte, []int) {
+	return file_service_promotion_rpc_promotion_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ReturnCouponsResponse) GetReturnedCount() int32 {
+	if x != nil {
+		return x.ReturnedCount
+	}
+	return 0
+}
+
 var File_service_promotion_rpc_promotion_proto protoreflect.FileDescriptor
 
 const file_service_promotion_rpc_promotion_proto_rawDesc = "" +
@@ -137,9 +341,22 @@ const file_service_promotion_rpc_promotion_proto_rawDesc = "" +
 	"\x03num\x18\x02 \x01(\x05R\x03num\"G\n" +
 	"\x11DecrStockResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage2Z\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"@\n" +
+	"\x10IncrStockRequest\x12\x1a\n" +
+	"\bcourseId\x18\x01 \x01(\x03R\bcourseId\x12\x10\n" +
+	"\x03num\x18\x02 \x01(\x05R\x03num\"G\n" +
+	"\x11IncrStockResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"H\n" +
+	"\x14ReturnCouponsRequest\x12\x16\n" +
+	"\x06userId\x18\x01 \x01(\x03R\x06userId\x12\x18\n" +
+	"\aorderId\x18\x02 \x01(\x03R\aorderId\"=\n" +
+	"\x15ReturnCouponsResponse\x12$\n" +
+	"\rreturnedCount\x18\x01 \x01(\x05R\rreturnedCount2\xf6\x01\n" +
 	"\x10PromotionService\x12F\n" +
-	"\tDecrStock\x12\x1b.promotion.DecrStockRequest\x1a\x1c.promotion.DecrStockResponseB8Z6github.com/aether-defense-system/service/promotion/rpcb\x06proto3"
+	"\tDecrStock\x12\x1b.promotion.DecrStockRequest\x1a\x1c.promotion.DecrStockResponse\x12F\n" +
+	"\tIncrStock\x12\x1b.promotion.IncrStockRequest\x1a\x1c.promotion.IncrStockResponse\x12R\n" +
+	"\rReturnCoupons\x12\x1f.promotion.ReturnCouponsRequest\x1a .promotion.ReturnCouponsResponseB8Z6github.com/aether-defense-system/service/promotion/rpcb\x06proto3"
 
 var (
 	file_service_promotion_rpc_promotion_proto_rawDescOnce sync.Once
@@ -153,16 +370,24 @@ func file_service_promotion_rpc_promotion_proto_rawDescGZIP() []byte {
 	return file_service_promotion_rpc_promotion_proto_rawDescData
 }
 
-var file_service_promotion_rpc_promotion_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_service_promotion_rpc_promotion_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_service_promotion_rpc_promotion_proto_goTypes = []any{
-	(*DecrStockRequest)(nil),  // 0: promotion.DecrStockRequest
-	(*DecrStockResponse)(nil), // 1: promotion.DecrStockResponse
+	(*DecrStockRequest)(nil),      // 0: promotion.DecrStockRequest
+	(*DecrStockResponse)(nil),     // 1: promotion.DecrStockResponse
+	(*IncrStockRequest)(nil),      // 2: promotion.IncrStockRequest
+	(*IncrStockResponse)(nil),     // 3: promotion.IncrStockResponse
+	(*ReturnCouponsRequest)(nil),  // 4: promotion.ReturnCouponsRequest
+	(*ReturnCouponsResponse)(nil), // 5: promotion.ReturnCouponsResponse
 }
 var file_service_promotion_rpc_promotion_proto_depIdxs = []int32{
 	0, // 0: promotion.PromotionService.DecrStock:input_type -> promotion.DecrStockRequest
-	1, // 1: promotion.PromotionService.DecrStock:output_type -> promotion.DecrStockResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
+	2, // 1: promotion.PromotionService.IncrStock:input_type -> promotion.IncrStockRequest
+	4, // 2: promotion.PromotionService.ReturnCoupons:input_type -> promotion.ReturnCouponsRequest
+	1, // 3: promotion.PromotionService.DecrStock:output_type -> promotion.DecrStockResponse
+	3, // 4: promotion.PromotionService.IncrStock:output_type -> promotion.IncrStockResponse
+	5, // 5: promotion.PromotionService.ReturnCoupons:output_type -> promotion.ReturnCouponsResponse
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -179,7 +404,7 @@ func file_service_promotion_rpc_promotion_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_service_promotion_rpc_promotion_proto_rawDesc), len(file_service_promotion_rpc_promotion_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,7 +19,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	PromotionService_DecrStock_FullMethodName = "/promotion.PromotionService/DecrStock"
+	PromotionService_DecrStock_FullMethodName     = "/promotion.PromotionService/DecrStock"
+	PromotionService_IncrStock_FullMethodName     = "/promotion.PromotionService/IncrStock"
+	PromotionService_ReturnCoupons_FullMethodName = "/promotion.PromotionService/ReturnCoupons"
 )
 
 // PromotionServiceClient is the client API for PromotionService service.
@@ -30,6 +32,10 @@ const (
 type PromotionServiceClient interface {
 	// Decrement Inventory Interface
 	DecrStock(ctx context.Context, in *DecrStockRequest, opts ...grpc.CallOption) (*DecrStockResponse, error)
+	// Increment Inventory Interface, restores stock released by refunds
+	IncrStock(ctx context.Context, in *IncrStockRequest, opts ...grpc.CallOption) (*IncrStockResponse, error)
+	// Return Coupons Interface, restores the coupons used by a fully refunded order
+	ReturnCoupons(ctx context.Context, in *ReturnCouponsRequest, opts ...grpc.CallOption) (*ReturnCouponsResponse, error)
 }
 
 type promotionServiceClient struct {
@@ -50,6 +56,26 @@ func (c *promotionServiceClient) DecrStock(ctx context.Context, in *DecrStockReq
 	return out, nil
 }
 
+func (c *promotionServiceClient) IncrStock(ctx context.Context, in *IncrStockRequest, opts ...grpc.CallOption) (*IncrStockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IncrStockResponse)
+	err := c.cc.Invoke(ctx, PromotionService_IncrStock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *promotionServiceClient) ReturnCoupons(ctx context.Context, in *ReturnCouponsRequest, opts ...grpc.CallOption) (*ReturnCouponsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReturnCouponsResponse)
+	err := c.cc.Invoke(ctx, PromotionService_ReturnCoupons_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PromotionServiceServer is the server API for PromotionService service.
 // All implementations must embed UnimplementedPromotionServiceServer
 // for forward compatibility.
@@ -58,6 +84,10 @@ func (c *promotionServiceClient) DecrStock(ctx context.Context, in *DecrStockReq
 type PromotionServiceServer interface {
 	// Decrement Inventory Interface
 	DecrStock(context.Context, *DecrStockRequest) (*DecrStockResponse, error)
+	// Increment Inventory Interface, restores stock released by refunds
+	IncrStock(context.Context, *IncrStockRequest) (*IncrStockResponse, error)
+	// Return Coupons Interface, restores the coupons used by a fully refunded order
+	ReturnCoupons(context.Context, *ReturnCouponsRequest) (*ReturnCouponsResponse, error)
 	mustEmbedUnimplementedPromotionServiceServer()
 }
 
@@ -71,6 +101,12 @@ type UnimplementedPromotionServiceServer struct{}
 func (UnimplementedPromotionServiceServer) DecrStock(context.Context, *DecrStockRequest) (*DecrStockResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method DecrStock not implemented")
 }
+func (UnimplementedPromotionServiceServer) IncrStock(context.Context, *IncrStockRequest) (*IncrStockResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method IncrStock not implemented")
+}
+func (UnimplementedPromotionServiceServer) ReturnCoupons(context.Context, *ReturnCouponsRequest) (*ReturnCouponsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReturnCoupons not implemented")
+}
 func (UnimplementedPromotionServiceServer) mustEmbedUnimplementedPromotionServiceServer() {}
 func (UnimplementedPromotionServiceServer) testEmbeddedByValue()                          {}
 
@@ -110,6 +146,42 @@ func _PromotionService_DecrStock_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PromotionService_IncrStock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IncrStockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PromotionServiceServer).IncrStock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PromotionService_IncrStock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PromotionServiceServer).IncrStock(ctx, req.(*IncrStockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PromotionService_ReturnCoupons_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReturnCouponsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PromotionServiceServer).ReturnCoupons(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PromotionService_ReturnCoupons_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PromotionServiceServer).ReturnCoupons(ctx, req.(*ReturnCouponsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PromotionService_ServiceDesc is the grpc.ServiceDesc for PromotionService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -121,6 +193,14 @@ var PromotionService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DecrStock",
 			Handler:    _PromotionService_DecrStock_Handler,
 		},
+		{
+			MethodName: "IncrStock",
+			Handler:    _PromotionService_IncrStock_Handler,
+		},
+		{
+			MethodName: "ReturnCoupons",
+			Handler:    _PromotionService_ReturnCoupons_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "service/promotion/rpc/promotion.proto",
@@ -14,12 +14,20 @@ import (
 )
 
 type (
-	DecrStockRequest  = rpc.DecrStockRequest
-	DecrStockResponse = rpc.DecrStockResponse
+	DecrStockRequest      = rpc.DecrStockRequest
+	DecrStockResponse     = rpc.DecrStockResponse
+	IncrStockRequest      = rpc.IncrStockRequest
+	IncrStockResponse     = rpc.IncrStockResponse
+	ReturnCouponsRequest  = rpc.ReturnCouponsRequest
+	ReturnCouponsResponse = rpc.ReturnCouponsResponse
 
 	PromotionService interface {
 		// Decrement Inventory Interface
 		DecrStock(ctx context.Context, in *DecrStockRequest, opts ...grpc.CallOption) (*DecrStockResponse, error)
+		// Increment Inventory Interface, restores stock released by refunds
+		IncrStock(ctx context.Context, in *IncrStockRequest, opts ...grpc.CallOption) (*IncrStockResponse, error)
+		// Return Coupons Interface, restores the coupons used by a fully refunded order
+		ReturnCoupons(ctx context.Context, in *ReturnCouponsRequest, opts ...grpc.CallOption) (*ReturnCouponsResponse, error)
 	}
 
 	defaultPromotionService struct {
@@ -38,3 +46,15 @@ func (m *defaultPromotionService) DecrStock(ctx context.Context, in *DecrStockRe
 	client := rpc.NewPromotionServiceClient(m.cli.Conn())
 	return client.DecrStock(ctx, in, opts...)
 }
+
+// Increment Inventory Interface, restores stock released by refunds
+func (m *defaultPromotionService) IncrStock(ctx context.Context, in *IncrStockRequest, opts ...grpc.CallOption) (*IncrStockResponse, error) {
+	client := rpc.NewPromotionServiceClient(m.cli.Conn())
+	return client.IncrStock(ctx, in, opts...)
+}
+
+// Return Coupons Interface, restores the coupons used by a fully refunded order
+func (m *defaultPromotionService) ReturnCoupons(ctx context.Context, in *ReturnCouponsRequest, opts ...grpc.CallOption) (*ReturnCouponsResponse, error) {
+	client := rpc.NewPromotionServiceClient(m.cli.Conn())
+	return client.ReturnCoupons(ctx, in, opts...)
+}
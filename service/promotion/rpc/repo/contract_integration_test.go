@@ -0,0 +1,80 @@
+//go:build integration
+// +build integration
+
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/database/migrations"
+	"github.com/aether-defense-system/common/migrate"
+)
+
+// repoTestDSNEnv names a MySQL server allowed to create and drop the test schema, e.g.
+// root:root@tcp(localhost:3306)/.
+const repoTestDSNEnv = "PROMOTION_REPO_TEST_MYSQL_DSN"
+
+// repoTestSchema is the local schema holding the promotion tables of the tests.
+const repoTestSchema = "aether_promotion_repo_test"
+
+// setupPromotionSchema creates the test schema with the promotion tables, and returns a client of it.
+func setupPromotionSchema(t *testing.T) *database.Client {
+	t.Helper()
+	dsn := os.Getenv(repoTestDSNEnv)
+	if dsn == "" {
+		t.Skipf("Set %s to run promotion repository integration tests.", repoTestDSNEnv)
+	}
+	base, err := mysql.ParseDSN(dsn)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	server, err := sql.Open("mysql", base.FormatDSN())
+	require.NoError(t, err)
+	defer func() { _ = server.Close() }()
+	if err := server.PingContext(ctx); err != nil {
+		t.Skipf("MySQL not available for integration test: %v", err)
+	}
+	for _, stmt := range []string{
+		"DROP DATABASE IF EXISTS " + repoTestSchema,
+		"CREATE DATABASE " + repoTestSchema,
+	} {
+		_, err := server.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	t.Cleanup(func() {
+		dropDB, err := sql.Open("mysql", base.FormatDSN())
+		if err != nil {
+			return
+		}
+		defer func() { _ = dropDB.Close() }()
+		_, _ = dropDB.Exec("DROP DATABASE IF EXISTS " + repoTestSchema)
+	})
+
+	cfg := base.Clone()
+	cfg.DBName = repoTestSchema
+	cfg.ParseTime = true
+	client, err := database.NewClient(&database.Config{DSN: cfg.FormatDSN()})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	set, err := migrations.Load(migrations.Promotion)
+	require.NoError(t, err)
+	_, err = migrate.New(client.DB()).Up(ctx, set)
+	require.NoError(t, err)
+	return client
+}
+
+func TestIntegration_CouponRepo_Contract(t *testing.T) {
+	testCouponStoreContract(t, func(t *testing.T) CouponStore {
+		return NewCouponRepo(setupPromotionSchema(t))
+	})
+}
@@ -0,0 +1,195 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+// testCouponStoreContract checks the behavior CouponStore implementations share, so that
+// MemoryCouponRepo behaves like the CouponRepo logic tests stand in for. newStore returns a store
+// over an empty table.
+func testCouponStoreContract(t *testing.T, newStore func(t *testing.T) CouponStore) {
+	tests := []struct {
+		run  func(t *testing.T, coupons CouponStore)
+		name string
+	}{
+		{name: "Create", run: testCreateCoupon},
+		{name: "GetByUserID", run: testGetCouponsByUserID},
+		{name: "UpdateStatus", run: testUpdateCouponStatus},
+		{name: "UpdateStatusConcurrent", run: testUpdateCouponStatusConcurrent},
+		{name: "ReturnByOrderID", run: testReturnCouponsByOrderID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newStore(t))
+		})
+	}
+}
+
+// createCoupon creates an unused coupon of a user.
+func createCoupon(t *testing.T, coupons CouponStore, id, userID, templateID int64) {
+	t.Helper()
+	require.NoError(t, coupons.Create(context.Background(), &database.PromotionCouponRecord{
+		ID: id, UserID: userID, TemplateID: templateID, Status: database.CouponStatusUnused,
+	}))
+}
+
+// useCoupon spends an unused coupon on an order.
+func useCoupon(t *testing.T, coupons CouponStore, id, orderID int64) {
+	t.Helper()
+	require.NoError(t, coupons.UpdateStatus(context.Background(), id, database.CouponStatusUnused,
+		database.CouponStatusUsed, &orderID))
+}
+
+func testCreateCoupon(t *testing.T, coupons CouponStore) {
+	ctx := context.Background()
+	createCoupon(t, coupons, 1, 10, 100)
+
+	got, err := coupons.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), got.UserID)
+	assert.Equal(t, int8(database.CouponStatusUnused), got.Status)
+	assert.Nil(t, got.UseTime)
+	assert.Nil(t, got.OrderID)
+	assert.False(t, got.CreateTime.IsZero(), "expected the create time set by the store")
+
+	got, err = coupons.GetByUserIDAndTemplateID(ctx, 10, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.ID)
+
+	// The ID and the template of a user are unique.
+	err = coupons.Create(ctx, &database.PromotionCouponRecord{ID: 1, UserID: 11, TemplateID: 101, Status: 1})
+	assert.ErrorIs(t, err, ErrCouponExists)
+	err = coupons.Create(ctx, &database.PromotionCouponRecord{ID: 2, UserID: 10, TemplateID: 100, Status: 1})
+	assert.ErrorIs(t, err, ErrCouponExists)
+	_, err = coupons.GetByID(ctx, 2)
+	assert.Error(t, err)
+
+	// Another user may hold a coupon of the same template.
+	createCoupon(t, coupons, 3, 11, 100)
+
+	_, err = coupons.GetByUserIDAndTemplateID(ctx, 10, 999)
+	assert.Error(t, err)
+}
+
+func testGetCouponsByUserID(t *testing.T, coupons CouponStore) {
+	ctx := context.Background()
+	for id := int64(1); id <= 4; id++ {
+		createCoupon(t, coupons, id, 10, 100+id)
+	}
+	createCoupon(t, coupons, 5, 11, 101)
+	useCoupon(t, coupons, 2, 900)
+
+	all, err := coupons.GetByUserID(ctx, 10, nil, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, all, 4)
+
+	used := int8(database.CouponStatusUsed)
+	page, err := coupons.GetByUserID(ctx, 10, &used, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, int64(2), page[0].ID)
+
+	// Pages do not overlap.
+	seen := make(map[int64]bool)
+	for offset := 0; offset < 4; offset += 3 {
+		page, err = coupons.GetByUserID(ctx, 10, nil, 3, offset)
+		require.NoError(t, err)
+		for _, coupon := range page {
+			assert.False(t, seen[coupon.ID], "coupon %d on two pages", coupon.ID)
+			seen[coupon.ID] = true
+		}
+	}
+	assert.Len(t, seen, 4)
+
+	page, err = coupons.GetByUserID(ctx, 10, nil, 3, 10)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+}
+
+func testUpdateCouponStatus(t *testing.T, coupons CouponStore) {
+	ctx := context.Background()
+	createCoupon(t, coupons, 1, 10, 100)
+	orderID := int64(900)
+
+	assert.Error(t, coupons.UpdateStatus(ctx, 1, database.CouponStatusUsed, database.CouponStatusExpired, nil),
+		"expected a wrong status to be rejected")
+	assert.Error(t, coupons.UpdateStatus(ctx, 99, database.CouponStatusUnused, database.CouponStatusUsed, &orderID))
+
+	require.NoError(t, coupons.UpdateStatus(ctx, 1, database.CouponStatusUnused, database.CouponStatusUsed, &orderID))
+	got, err := coupons.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.CouponStatusUsed), got.Status)
+	assert.NotNil(t, got.UseTime)
+	require.NotNil(t, got.OrderID)
+	assert.Equal(t, orderID, *got.OrderID)
+}
+
+func testUpdateCouponStatusConcurrent(t *testing.T, coupons CouponStore) {
+	ctx := context.Background()
+	createCoupon(t, coupons, 1, 10, 100)
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(orderID int64) {
+			defer wg.Done()
+			errs <- coupons.UpdateStatus(ctx, 1, database.CouponStatusUnused, database.CouponStatusUsed, &orderID)
+		}(int64(900 + i))
+	}
+	wg.Wait()
+	close(errs)
+
+	used := 0
+	for err := range errs {
+		if err == nil {
+			used++
+		}
+	}
+	assert.Equal(t, 1, used, "expected the coupon spent exactly once")
+}
+
+func testReturnCouponsByOrderID(t *testing.T, coupons CouponStore) {
+	ctx := context.Background()
+	createCoupon(t, coupons, 1, 10, 100)
+	createCoupon(t, coupons, 2, 10, 101)
+	createCoupon(t, coupons, 3, 10, 102)
+	useCoupon(t, coupons, 1, 900)
+	useCoupon(t, coupons, 2, 900)
+	useCoupon(t, coupons, 3, 901)
+	orderID := int64(900)
+	require.NoError(t, coupons.UpdateStatus(ctx, 2, database.CouponStatusUsed, database.CouponStatusExpired, &orderID))
+
+	// Expired coupons and coupons of other orders are left alone.
+	returned, err := coupons.ReturnByOrderID(ctx, 10, 900)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), returned)
+
+	got, err := coupons.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.CouponStatusUnused), got.Status)
+	assert.Nil(t, got.UseTime)
+	assert.Nil(t, got.OrderID)
+	got, err = coupons.GetByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.CouponStatusExpired), got.Status)
+	got, err = coupons.GetByID(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.CouponStatusUsed), got.Status)
+
+	// Returning is idempotent, and only returns the coupons of the user.
+	returned, err = coupons.ReturnByOrderID(ctx, 10, 900)
+	require.NoError(t, err)
+	assert.Zero(t, returned)
+	returned, err = coupons.ReturnByOrderID(ctx, 11, 901)
+	require.NoError(t, err)
+	assert.Zero(t, returned)
+}
@@ -4,30 +4,68 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/database/migrations"
+)
+
+// ErrCouponExists is returned when a coupon with the same ID, or of the same template for the
+// same user, has already been created.
+var ErrCouponExists = errors.New("coupon already exists")
+
+// ShardedTables are the promotion tables sharded by user_id.
+var ShardedTables = migrations.ShardedTables[migrations.Promotion]
+
+// CouponStore is the persistence of coupons. CouponRepo implements it over MySQL, and
+// MemoryCouponRepo in memory for tests.
+type CouponStore interface {
+	Create(ctx context.Context, coupon *database.PromotionCouponRecord) error
+	GetByID(ctx context.Context, couponID int64) (*database.PromotionCouponRecord, error)
+	GetByUserIDAndTemplateID(ctx context.Context, userID, templateID int64) (*database.PromotionCouponRecord, error)
+	GetByUserID(
+		ctx context.Context, userID int64, status *int8, limit, offset int,
+	) ([]*database.PromotionCouponRecord, error)
+	UpdateStatus(ctx context.Context, couponID int64, oldStatus, newStatus int8, orderID *int64) error
+	ReturnByOrderID(ctx context.Context, userID, orderID int64) (int64, error)
+}
+
+var (
+	_ CouponStore = (*CouponRepo)(nil)
+	_ CouponStore = (*MemoryCouponRepo)(nil)
 )
 
 // CouponRepo provides data access operations for coupon domain.
 type CouponRepo struct {
-	db *sql.DB
+	shards *database.ShardRouter
 }
 
-// NewCouponRepo creates a new CouponRepo instance.
-func NewCouponRepo(db *sql.DB) *CouponRepo {
-	return &CouponRepo{db: db}
+// NewCouponRepo creates a new CouponRepo instance over unsharded tables.
+func NewCouponRepo(client *database.Client) *CouponRepo {
+	return NewShardedCouponRepo(database.NewUnshardedRouter(client))
 }
 
-// Create creates a new coupon record.
+// NewShardedCouponRepo creates a new CouponRepo instance over the ShardedTables routed by shards.
+// Lookups by coupon ID have no sharding key and follow the policy of the router.
+func NewShardedCouponRepo(shards *database.ShardRouter) *CouponRepo {
+	return &CouponRepo{shards: shards}
+}
+
+// Create creates a new coupon record, in the transaction of ctx if any (see database.WithTx).
+// It returns ErrCouponExists if the ID is taken or the user already has a coupon of the template.
 func (r *CouponRepo) Create(ctx context.Context, coupon *database.PromotionCouponRecord) error {
 	query := `INSERT INTO promotion_coupon_record
 	          (id, user_id, template_id, status, order_id)
 	          VALUES (?, ?, ?, ?, ?)`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := r.shards.Route(coupon.UserID).Executor(ctx).ExecContext(ctx, query,
 		coupon.ID, coupon.UserID, coupon.TemplateID, coupon.Status, coupon.OrderID)
 	if err != nil {
+		if database.IsDuplicateKey(err) {
+			return fmt.Errorf("%w (id=%d, user_id=%d, template_id=%d)",
+				ErrCouponExists, coupon.ID, coupon.UserID, coupon.TemplateID)
+		}
 		return fmt.Errorf("failed to create coupon record: %w", err)
 	}
 
@@ -39,18 +77,27 @@ func (r *CouponRepo) GetByID(ctx context.Context, couponID int64) (*database.Pro
 	query := `SELECT id, user_id, template_id, status, use_time, order_id, create_time, update_time
 	          FROM promotion_coupon_record WHERE id = ?`
 
-	var coupon database.PromotionCouponRecord
-	err := r.db.QueryRowContext(ctx, query, couponID).
-		Scan(&coupon.ID, &coupon.UserID, &coupon.TemplateID, &coupon.Status,
-			&coupon.UseTime, &coupon.OrderID, &coupon.CreateTime, &coupon.UpdateTime)
+	found, err := database.FanOut(ctx, r.shards,
+		func(ctx context.Context, shard *database.Shard) (*database.PromotionCouponRecord, error) {
+			var coupon database.PromotionCouponRecord
+			err := shard.Executor(ctx).QueryRowContext(ctx, query, couponID).
+				Scan(&coupon.ID, &coupon.UserID, &coupon.TemplateID, &coupon.Status,
+					&coupon.UseTime, &coupon.OrderID, &coupon.CreateTime, &coupon.UpdateTime)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, nil
+			}
+			return &coupon, err
+		})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("coupon record not found: %d", couponID)
-		}
 		return nil, fmt.Errorf("failed to get coupon record: %w", err)
 	}
 
-	return &coupon, nil
+	for _, coupon := range found {
+		if coupon != nil {
+			return coupon, nil
+		}
+	}
+	return nil, fmt.Errorf("coupon record not found: %d", couponID)
 }
 
 // GetByUserIDAndTemplateID retrieves a coupon record by user ID and template ID.
@@ -61,7 +108,7 @@ func (r *CouponRepo) GetByUserIDAndTemplateID(
 	          FROM promotion_coupon_record WHERE user_id = ? AND template_id = ?`
 
 	var coupon database.PromotionCouponRecord
-	err := r.db.QueryRowContext(ctx, query, userID, templateID).
+	err := r.shards.Route(userID).Executor(ctx).QueryRowContext(ctx, query, userID, templateID).
 		Scan(&coupon.ID, &coupon.UserID, &coupon.TemplateID, &coupon.Status,
 			&coupon.UseTime, &coupon.OrderID, &coupon.CreateTime, &coupon.UpdateTime)
 	if err != nil {
@@ -90,7 +137,7 @@ func (r *CouponRepo) GetByUserID(
 	query += " ORDER BY create_time DESC LIMIT ? OFFSET ?"
 	args = append(args, limit, offset)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.shards.Route(userID).Executor(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query coupon records: %w", err)
 	}
@@ -125,16 +172,11 @@ func (r *CouponRepo) UpdateStatus(
 	query := `UPDATE promotion_coupon_record SET status = ?, use_time = NOW(), order_id = ?
 	          WHERE id = ? AND status = ?`
 
-	result, err := r.db.ExecContext(ctx, query, newStatus, orderID, couponID, oldStatus)
+	rowsAffected, err := r.shards.ExecAll(ctx, query, newStatus, orderID, couponID, oldStatus)
 	if err != nil {
 		return fmt.Errorf("failed to update coupon status: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-
 	if rowsAffected == 0 {
 		return fmt.Errorf(
 			"coupon status update failed: coupon not found or status mismatch "+
@@ -144,3 +186,23 @@ func (r *CouponRepo) UpdateStatus(
 
 	return nil
 }
+
+// ReturnByOrderID restores the coupons a user spent on an order to unused, in the transaction of
+// ctx if any. Expired coupons are left alone; it returns the number of coupons restored.
+func (r *CouponRepo) ReturnByOrderID(ctx context.Context, userID, orderID int64) (int64, error) {
+	query := `UPDATE promotion_coupon_record SET status = ?, use_time = NULL, order_id = NULL
+	          WHERE user_id = ? AND order_id = ? AND status = ?`
+
+	result, err := r.shards.Route(userID).Executor(ctx).ExecContext(ctx, query,
+		database.CouponStatusUnused, userID, orderID, database.CouponStatusUsed)
+	if err != nil {
+		return 0, fmt.Errorf("failed to return coupons: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
@@ -0,0 +1,177 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+// MemoryCouponRepo is an in-memory CouponStore for tests. It follows the SQL semantics of
+// CouponRepo, which the contract tests of this package check against MySQL: coupon IDs and
+// (user_id, template_id) are unique, status changes only apply to coupons in the expected
+// status, times are set with second precision, and records are copied in and out.
+//
+// Unlike CouponRepo, it does not join the transactions of database.WithTx.
+type MemoryCouponRepo struct {
+	coupons map[int64]*database.PromotionCouponRecord
+	mu      sync.Mutex
+}
+
+// NewMemoryCouponRepo creates an empty in-memory coupon repository.
+func NewMemoryCouponRepo() *MemoryCouponRepo {
+	return &MemoryCouponRepo{coupons: make(map[int64]*database.PromotionCouponRecord)}
+}
+
+// Put stores coupons as they are, replacing coupons with the same IDs, without the checks of
+// Create. Tests use it to set up coupons in any state.
+func (r *MemoryCouponRepo) Put(coupons ...*database.PromotionCouponRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, coupon := range coupons {
+		r.coupons[coupon.ID] = copyCoupon(coupon)
+	}
+}
+
+// now is the time of a write, with the second precision of DATETIME columns.
+func (r *MemoryCouponRepo) now() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+// Create creates a new coupon record.
+func (r *MemoryCouponRepo) Create(_ context.Context, coupon *database.PromotionCouponRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.coupons {
+		if existing.ID == coupon.ID ||
+			(existing.UserID == coupon.UserID && existing.TemplateID == coupon.TemplateID) {
+			return fmt.Errorf("%w (id=%d, user_id=%d, template_id=%d)",
+				ErrCouponExists, coupon.ID, coupon.UserID, coupon.TemplateID)
+		}
+	}
+	now := r.now()
+	r.coupons[coupon.ID] = &database.PromotionCouponRecord{
+		ID: coupon.ID, UserID: coupon.UserID, TemplateID: coupon.TemplateID, Status: coupon.Status,
+		OrderID: copyPtr(coupon.OrderID), CreateTime: now, UpdateTime: now,
+	}
+	return nil
+}
+
+// GetByID retrieves a coupon record by ID.
+func (r *MemoryCouponRepo) GetByID(_ context.Context, couponID int64) (*database.PromotionCouponRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	coupon, ok := r.coupons[couponID]
+	if !ok {
+		return nil, fmt.Errorf("coupon record not found: %d", couponID)
+	}
+	return copyCoupon(coupon), nil
+}
+
+// GetByUserIDAndTemplateID retrieves a coupon record by user ID and template ID.
+func (r *MemoryCouponRepo) GetByUserIDAndTemplateID(
+	_ context.Context, userID, templateID int64,
+) (*database.PromotionCouponRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, coupon := range r.coupons {
+		if coupon.UserID == userID && coupon.TemplateID == templateID {
+			return copyCoupon(coupon), nil
+		}
+	}
+	return nil, fmt.Errorf("coupon record not found: user_id=%d, template_id=%d", userID, templateID)
+}
+
+// GetByUserID retrieves coupon records by user ID with status filter, newest first. Coupons
+// created within the same second come highest ID first, an order MySQL does not guarantee.
+func (r *MemoryCouponRepo) GetByUserID(
+	_ context.Context, userID int64, status *int8, limit, offset int,
+) ([]*database.PromotionCouponRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var coupons []*database.PromotionCouponRecord
+	for _, coupon := range r.coupons {
+		if coupon.UserID == userID && (status == nil || coupon.Status == *status) {
+			coupons = append(coupons, copyCoupon(coupon))
+		}
+	}
+	sort.Slice(coupons, func(i, j int) bool {
+		if !coupons[i].CreateTime.Equal(coupons[j].CreateTime) {
+			return coupons[i].CreateTime.After(coupons[j].CreateTime)
+		}
+		return coupons[i].ID > coupons[j].ID
+	})
+	if offset >= len(coupons) {
+		return nil, nil
+	}
+	coupons = coupons[offset:]
+	if len(coupons) > limit {
+		coupons = coupons[:limit]
+	}
+	return coupons, nil
+}
+
+// UpdateStatus updates coupon status.
+func (r *MemoryCouponRepo) UpdateStatus(
+	_ context.Context, couponID int64, oldStatus, newStatus int8, orderID *int64,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	coupon, ok := r.coupons[couponID]
+	if !ok || coupon.Status != oldStatus {
+		return fmt.Errorf(
+			"coupon status update failed: coupon not found or status mismatch "+
+				"(id=%d, expected_status=%d)",
+			couponID, oldStatus)
+	}
+	now := r.now()
+	coupon.Status = newStatus
+	coupon.UseTime = &now
+	coupon.OrderID = copyPtr(orderID)
+	coupon.UpdateTime = now
+	return nil
+}
+
+// ReturnByOrderID restores the coupons a user spent on an order to unused, and returns their number.
+func (r *MemoryCouponRepo) ReturnByOrderID(_ context.Context, userID, orderID int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var returned int64
+	now := r.now()
+	for _, coupon := range r.coupons {
+		if coupon.UserID != userID || coupon.OrderID == nil || *coupon.OrderID != orderID ||
+			coupon.Status != database.CouponStatusUsed {
+			continue
+		}
+		coupon.Status = database.CouponStatusUnused
+		coupon.UseTime = nil
+		coupon.OrderID = nil
+		coupon.UpdateTime = now
+		returned++
+	}
+	return returned, nil
+}
+
+// copyCoupon returns a copy of coupon that shares no pointer with it.
+func copyCoupon(coupon *database.PromotionCouponRecord) *database.PromotionCouponRecord {
+	copied := *coupon
+	copied.UseTime = copyPtr(coupon.UseTime)
+	copied.OrderID = copyPtr(coupon.OrderID)
+	return &copied
+}
+
+// copyPtr returns a copy of an optional column value.
+func copyPtr[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	copied := *v
+	return &copied
+}
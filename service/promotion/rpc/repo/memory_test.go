@@ -0,0 +1,32 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+func TestMemoryCouponRepo_Contract(t *testing.T) {
+	testCouponStoreContract(t, func(*testing.T) CouponStore {
+		return NewMemoryCouponRepo()
+	})
+}
+
+func TestMemoryCouponRepo_Put(t *testing.T) {
+	coupons := NewMemoryCouponRepo()
+	orderID := int64(900)
+	coupon := &database.PromotionCouponRecord{
+		ID: 1, UserID: 10, TemplateID: 100, Status: database.CouponStatusUsed, OrderID: &orderID,
+	}
+	coupons.Put(coupon)
+
+	// The repository keeps its own copy.
+	orderID = 901
+	returned, err := coupons.ReturnByOrderID(context.Background(), 10, 900)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), returned)
+}
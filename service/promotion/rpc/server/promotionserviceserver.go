@@ -28,3 +28,15 @@ func (s *PromotionServiceServer) DecrStock(ctx context.Context, in *rpc.DecrStoc
 	l := logic.NewDecrStockLogic(ctx, s.svcCtx)
 	return l.DecrStock(in)
 }
+
+// IncrStock restores inventory released by refunds.
+func (s *PromotionServiceServer) IncrStock(ctx context.Context, in *rpc.IncrStockRequest) (*rpc.IncrStockResponse, error) {
+	l := logic.NewIncrStockLogic(ctx, s.svcCtx)
+	return l.IncrStock(in)
+}
+
+// ReturnCoupons restores the coupons used by a fully refunded order.
+func (s *PromotionServiceServer) ReturnCoupons(ctx context.Context, in *rpc.ReturnCouponsRequest) (*rpc.ReturnCouponsResponse, error) {
+	l := logic.NewReturnCouponsLogic(ctx, s.svcCtx)
+	return l.ReturnCoupons(in)
+}
@@ -4,8 +4,12 @@ package svc
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/database/migrations"
+	"github.com/aether-defense-system/common/migrate"
+	"github.com/aether-defense-system/common/mq"
 	"github.com/aether-defense-system/common/redis"
 	"github.com/aether-defense-system/service/promotion/rpc"
 	"github.com/aether-defense-system/service/promotion/rpc/internal/config"
@@ -17,21 +21,39 @@ import (
 type InventoryRedis interface {
 	Get(ctx context.Context, key string) (string, error)
 	DecrStock(ctx context.Context, inventoryKey string, quantity int64) error
+	IncrStock(ctx context.Context, inventoryKey string, quantity int64) error
 }
 
+// CouponRepository defines the coupon persistence operations required by promotion logic.
+// Every repo.CouponStore satisfies this interface; tests use repo.MemoryCouponRepo.
+type CouponRepository interface {
+	ReturnByOrderID(ctx context.Context, userID, orderID int64) (int64, error)
+}
+
+// consumedMessageRetention is how long consumed messages are remembered for deduplication.
+// It covers the broker's default message retention of three days.
+const consumedMessageRetention = 72 * time.Hour
+
 // ServiceContext represents the service context for promotion RPC service.
 type ServiceContext struct {
 	Config     *config.Config
 	DB         *database.Client
 	Redis      InventoryRedis
-	CouponRepo *repo.CouponRepo
+	CouponRepo CouponRepository
+	// ConsumedMessages deduplicates redelivered events; nil without InventoryRedis.
+	ConsumedMessages mq.DedupeStore
+	// ConsumeFailures records why events failed, for dead-letter inspection; nil without Database.
+	ConsumeFailures mq.FailureLog
 }
 
 // NewServiceContext creates a new service context.
 func NewServiceContext(c *config.Config) *ServiceContext {
 	var dbClient *database.Client
-	var couponRepo *repo.CouponRepo
+	var couponRepo CouponRepository
 	var redisClient InventoryRedis
+	var consumedMessages mq.DedupeStore
+	var consumeFailures mq.FailureLog
+	var shards *database.ShardRouter
 
 	// Initialize database client if DSN is configured
 	if c.Database.DSN != "" {
@@ -40,26 +62,58 @@ func NewServiceContext(c *config.Config) *ServiceContext {
 			panic(fmt.Sprintf("failed to initialize database: %v", err))
 		}
 		dbClient = client
-		couponRepo = repo.NewCouponRepo(client.DB())
+		shards = database.NewUnshardedRouter(client)
+		couponRepo = repo.NewCouponRepo(client)
+		consumeFailures = mq.NewSQLFailureLog(database.NewConsumeFailureStore(client.DB()))
 	}
 
+	// Coupon records sharded by user_id live in the sharding databases instead.
+	if c.Sharding.Enabled() {
+		router, err := database.NewShardRouter(&c.Sharding, repo.ShardedTables...)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize sharding: %v", err))
+		}
+		shards = router
+		couponRepo = repo.NewShardedCouponRepo(shards)
+	}
+	// Coupon records are migrated wherever they live: every sharding database when sharded.
+	if c.AutoMigrate {
+		if dbClient == nil {
+			panic("migrating at startup needs the database")
+		}
+		ctx := context.Background()
+		err := migrations.Up(ctx, []*migrate.Migrator{migrate.New(dbClient.DB())}, migrations.Common)
+		if err == nil {
+			err = migrations.Up(ctx, migrate.ForRouter(shards), migrations.Promotion)
+		}
+		if err != nil {
+			panic(fmt.Sprintf("failed to migrate the database: %v", err))
+		}
+	}
 	// Initialize Inventory Redis client only when configured.
 	//
 	// In unit tests (and some lightweight deployments) we don't always have Redis available.
 	// If inventoryRedis is not set in config, keep Redis nil and let business logic decide.
 	if c.InventoryRedis.Addr != "" || c.InventoryRedis.Host != "" {
-		var err error
-		redisClient, err = redis.NewClient(&c.InventoryRedis)
+		client, err := redis.NewClient(&c.InventoryRedis)
 		if err != nil {
 			panic(fmt.Sprintf("failed to initialize Redis: %v", err))
 		}
+		redisClient = client
+
+		consumedMessages, err = mq.NewRedisDedupeStore(client, consumedMessageRetention)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize consumed message store: %v", err))
+		}
 	}
 
 	return &ServiceContext{
-		Config:     c,
-		DB:         dbClient,
-		Redis:      redisClient,
-		CouponRepo: couponRepo,
+		Config:           c,
+		DB:               dbClient,
+		Redis:            redisClient,
+		CouponRepo:       couponRepo,
+		ConsumedMessages: consumedMessages,
+		ConsumeFailures:  consumeFailures,
 	}
 }
 
@@ -71,6 +125,9 @@ func NewServiceContextFromPublic(publicCfg *rpc.Config) *ServiceContext {
 		RpcServerConf:  publicCfg.RpcServerConf,
 		Database:       publicCfg.Database,
 		InventoryRedis: publicCfg.InventoryRedis,
+		OrderEvents:    publicCfg.OrderEvents,
+		Sharding:       publicCfg.Sharding,
+		AutoMigrate:    publicCfg.AutoMigrate,
 	}
 	return NewServiceContext(internalCfg)
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/aether-defense-system/service/trade/api/internal/svc"
 
 	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/rest"
 )
 
@@ -19,9 +20,15 @@ func main() {
 	flag.Parse()
 
 	var c config.Config
-	conf.MustLoad(*configFile, &c)
+	conf.MustLoad(*configFile, &c, conf.UseEnv())
 
 	ctx := svc.NewServiceContext(&c)
+	// Runs after server.Stop, so that no request generates an ID with a released worker ID.
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			logx.Errorf("failed to close service context: %v", err)
+		}
+	}()
 	server := rest.MustNewServer(c.RestConf)
 	defer server.Stop()
 
@@ -4,6 +4,9 @@ package config
 import (
 	"github.com/zeromicro/go-zero/rest"
 	"github.com/zeromicro/go-zero/zrpc"
+
+	"github.com/aether-defense-system/common/redis"
+	"github.com/aether-defense-system/common/snowflake"
 )
 
 // AuthConf represents JWT authentication configuration.
@@ -19,4 +22,22 @@ type Config struct { //nolint:govet
 	rest.RestConf
 	Auth     AuthConf            `json:"auth" yaml:"auth"`
 	TradeRPC *zrpc.RpcClientConf `json:"tradeRpc" yaml:"tradeRpc"`
+
+	// SessionRedis is the user session store; when set, tokens of revoked sessions are rejected.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	SessionRedis redis.Config `json:"sessionRedis,optional" yaml:"sessionRedis"`
+
+	// CheckoutRedis stores one-time checkout tokens; placing orders is disabled when it is unset.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	CheckoutRedis redis.Config `json:"checkoutRedis,optional" yaml:"checkoutRedis"`
+
+	// PublicIDKey keys the codec that turns order IDs into the public IDs of requests and
+	// responses, so that they do not reveal the order volume (see snowflake.IDCodec). It must be at
+	// least 16 bytes and the same on every gateway; changing it changes every public order ID.
+	PublicIDKey string `json:"publicIdKey" yaml:"publicIdKey"`
+
+	// Snowflake leases the worker ID of order IDs from Redis or etcd.
+	// Without it the worker ID comes from the environment.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Snowflake snowflake.LeaseConfig `json:"snowflake,optional" yaml:"snowflake"`
 }
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/aether-defense-system/common/auth"
+	"github.com/aether-defense-system/service/trade/api/internal/logic"
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+	"github.com/aether-defense-system/service/trade/api/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// ApproveRefundHandler handles POST /v1/admin/refunds/:refundId/approve requests.
+func ApproveRefundHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ApproveRefundReq
+		if err := httpx.ParsePath(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		// trade-rpc re-checks the admin's permission, so forward the caller's token.
+		ctx := auth.WithOutgoingAuthorization(r.Context(), r.Header.Get("Authorization"))
+
+		l := logic.NewApproveRefundLogic(ctx, svcCtx)
+		resp, err := l.ApproveRefund(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/aether-defense-system/common/auth"
+	"github.com/aether-defense-system/service/trade/api/internal/logic"
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+	"github.com/aether-defense-system/service/trade/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// GetOrderItemsHandler handles GET /v1/trade/order/:orderId/items requests.
+func GetOrderItemsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GetOrderItemsReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		userID, err := auth.UserIDFromContext(r.Context())
+		if err != nil {
+			logx.WithContext(r.Context()).Errorf("failed to get user_id from JWT token: %v", err)
+			http.Error(w, "unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		l := logic.NewGetOrderItemsLogic(r.Context(), svcCtx)
+		resp, err := l.GetOrderItems(&req, userID)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
@@ -4,6 +4,7 @@ package handler
 import (
 	"net/http"
 
+	"github.com/aether-defense-system/common/auth"
 	"github.com/aether-defense-system/service/trade/api/internal/logic"
 	"github.com/aether-defense-system/service/trade/api/internal/svc"
 	"github.com/aether-defense-system/service/trade/api/internal/types"
@@ -23,23 +24,10 @@ func PlaceOrderHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 
 		// Extract user_id from JWT token (set by go-zero JWT middleware)
 		// The JWT middleware validates the token and sets userId in context
-		userIDVal := r.Context().Value("userId")
-		if userIDVal == nil {
-			logx.WithContext(r.Context()).Errorf("missing user_id in JWT token")
-			http.Error(w, "unauthorized: missing user_id", http.StatusUnauthorized)
-			return
-		}
-
-		userID, ok := userIDVal.(int64)
-		if !ok {
-			logx.WithContext(r.Context()).Errorf("invalid user_id type in JWT token: %T", userIDVal)
-			http.Error(w, "unauthorized: invalid user_id", http.StatusUnauthorized)
-			return
-		}
-
-		if userID <= 0 {
-			logx.WithContext(r.Context()).Errorf("invalid user_id value: %d", userID)
-			http.Error(w, "unauthorized: invalid user_id", http.StatusUnauthorized)
+		userID, err := auth.UserIDFromContext(r.Context())
+		if err != nil {
+			logx.WithContext(r.Context()).Errorf("failed to get user_id from JWT token: %v", err)
+			http.Error(w, "unauthorized: "+err.Error(), http.StatusUnauthorized)
 			return
 		}
 
@@ -8,20 +8,61 @@ import (
 
 // RegisterHandlers registers all HTTP handlers.
 func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
+	// Server-level middleware runs after per-route JWT verification, so role claims are available.
+	server.Use(serverCtx.Permission)
+
 	// Enable JWT authentication if AccessSecret is configured
-	var jwtOption rest.RouteOption
+	var opts []rest.RouteOption
 	if serverCtx.JWTSecret != "" {
-		jwtOption = rest.WithJwt(serverCtx.JWTSecret)
+		opts = append(opts, rest.WithJwt(serverCtx.JWTSecret))
+	}
+
+	// Reject tokens whose session was revoked (logout, device removal, force logout)
+	var middlewares []rest.Middleware
+	if serverCtx.SessionCheck != nil {
+		middlewares = append(middlewares, serverCtx.SessionCheck)
 	}
 
 	server.AddRoutes(
-		[]rest.Route{
-			{
-				Method:  "POST",
-				Path:    "/v1/trade/order/place",
-				Handler: PlaceOrderHandler(serverCtx),
-			},
-		},
-		jwtOption,
+		rest.WithMiddlewares(middlewares,
+			[]rest.Route{
+				{
+					Method:  "POST",
+					Path:    "/v1/trade/order/token",
+					Handler: IssueCheckoutTokenHandler(serverCtx),
+				},
+				{
+					Method:  "POST",
+					Path:    "/v1/trade/order/place",
+					Handler: PlaceOrderHandler(serverCtx),
+				},
+				{
+					Method:  "GET",
+					Path:    "/v1/trade/order/list",
+					Handler: ListMyOrdersHandler(serverCtx),
+				},
+				{
+					Method:  "GET",
+					Path:    "/v1/trade/order/:orderId",
+					Handler: GetOrderHandler(serverCtx),
+				},
+				{
+					Method:  "GET",
+					Path:    "/v1/trade/order/:orderId/items",
+					Handler: GetOrderItemsHandler(serverCtx),
+				},
+				{
+					Method:  "POST",
+					Path:    "/v1/trade/order/:orderId/refund",
+					Handler: RequestRefundHandler(serverCtx),
+				},
+				{
+					Method:  "POST",
+					Path:    "/v1/admin/refunds/:refundId/approve",
+					Handler: ApproveRefundHandler(serverCtx),
+				},
+			}...,
+		),
+		opts...,
 	)
 }
@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+	"github.com/aether-defense-system/service/trade/api/internal/types"
+	"github.com/aether-defense-system/service/trade/rpc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ApproveRefundLogic handles admin refund approval.
+type ApproveRefundLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewApproveRefundLogic creates a new ApproveRefundLogic instance.
+// ctx must carry the admin's forwarded access token (see auth.WithOutgoingAuthorization),
+// since trade-rpc authorizes ApproveRefund itself.
+func NewApproveRefundLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ApproveRefundLogic {
+	return &ApproveRefundLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ApproveRefund approves a refund by calling Trade RPC.
+// The returned refund is Succeeded, or Failed when the payment gateway rejected it.
+func (l *ApproveRefundLogic) ApproveRefund(req *types.ApproveRefundReq) (*types.RefundInfo, error) {
+	if req.RefundID <= 0 {
+		l.Errorf("invalid refund_id: %d", req.RefundID)
+		return nil, fmt.Errorf("invalid refund_id: %d", req.RefundID)
+	}
+
+	rpcResp, err := l.svcCtx.TradeRPC.ApproveRefund(l.ctx, &rpc.ApproveRefundRequest{
+		RefundId: req.RefundID,
+	})
+	if err != nil {
+		l.Errorf("failed to approve refund via RPC: %v, refundID=%d", err, req.RefundID)
+		return nil, fmt.Errorf("failed to approve refund: %w", err)
+	}
+
+	info := toRefundInfo(l.svcCtx.OrderIDs, rpcResp.Refund)
+	l.Infof("refund approved: refundId=%d, status=%d", info.RefundID, info.Status)
+
+	return &info, nil
+}
@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+	"github.com/aether-defense-system/service/trade/api/internal/types"
+	"github.com/aether-defense-system/service/trade/rpc/tradeservice"
+)
+
+func TestApproveRefundLogic_ApproveRefund(t *testing.T) {
+	mockRPC := &mockTradeRPC{
+		approveRefundFunc: func(
+			_ context.Context,
+			req *tradeservice.ApproveRefundRequest,
+		) (*tradeservice.ApproveRefundResponse, error) {
+			if req.RefundId == 404 {
+				return nil, errors.New("refund not found")
+			}
+			return &tradeservice.ApproveRefundResponse{
+				Refund: &tradeservice.RefundInfo{
+					RefundId:    req.RefundId,
+					Status:      3,
+					Amount:      4500,
+					OutRefundNo: "FAKE-REFUND-900",
+				},
+			}, nil
+		},
+	}
+	logic := NewApproveRefundLogic(context.Background(), &svc.ServiceContext{TradeRPC: mockRPC, OrderIDs: testOrderIDs})
+
+	_, err := logic.ApproveRefund(&types.ApproveRefundReq{RefundID: 0})
+	assert.Error(t, err)
+
+	_, err = logic.ApproveRefund(&types.ApproveRefundReq{RefundID: 404})
+	assert.Error(t, err)
+
+	resp, err := logic.ApproveRefund(&types.ApproveRefundReq{RefundID: 900})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(900), resp.RefundID)
+	assert.Equal(t, 3, resp.Status)
+	assert.Equal(t, "FAKE-REFUND-900", resp.OutRefundNo)
+	assert.Empty(t, resp.Items)
+}
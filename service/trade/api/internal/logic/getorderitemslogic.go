@@ -0,0 +1,63 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+	"github.com/aether-defense-system/service/trade/api/internal/types"
+	"github.com/aether-defense-system/service/trade/rpc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GetOrderItemsLogic handles order item queries.
+type GetOrderItemsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewGetOrderItemsLogic creates a new GetOrderItemsLogic instance.
+func NewGetOrderItemsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetOrderItemsLogic {
+	return &GetOrderItemsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetOrderItems fetches the items of one of the caller's orders by calling Trade RPC.
+func (l *GetOrderItemsLogic) GetOrderItems(req *types.GetOrderItemsReq, userID int64) (*types.GetOrderItemsResp, error) {
+	orderID, err := parseOrderID(l.svcCtx.OrderIDs, req.OrderID)
+	if err != nil {
+		l.Errorf("%v for user_id: %d", err, userID)
+		return nil, err
+	}
+
+	rpcResp, err := l.svcCtx.TradeRPC.GetOrderItems(l.ctx, &rpc.GetOrderItemsRequest{
+		UserId:  userID,
+		OrderId: orderID,
+	})
+	if err != nil {
+		l.Errorf("failed to get order items via RPC: %v, userID=%d, orderID=%d", err, userID, orderID)
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+
+	items := make([]types.OrderItemInfo, 0, len(rpcResp.Items))
+	for _, item := range rpcResp.Items {
+		items = append(items, types.OrderItemInfo{
+			ItemID:        item.ItemId,
+			CourseID:      item.CourseId,
+			CourseName:    item.CourseName,
+			Price:         int(item.Price),
+			RealPayAmount: int(item.RealPayAmount),
+			RefundStatus:  int(item.RefundStatus),
+		})
+	}
+
+	return &types.GetOrderItemsResp{
+		OrderID: l.svcCtx.OrderIDs.Encode(rpcResp.OrderId),
+		Items:   items,
+	}, nil
+}
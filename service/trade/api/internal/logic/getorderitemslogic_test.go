@@ -0,0 +1,38 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+	"github.com/aether-defense-system/service/trade/api/internal/types"
+	"github.com/aether-defense-system/service/trade/rpc/tradeservice"
+)
+
+func TestGetOrderItemsLogic_GetOrderItems(t *testing.T) {
+	mockRPC := &mockTradeRPC{
+		getOrderItemsFunc: func(
+			_ context.Context,
+			req *tradeservice.GetOrderItemsRequest,
+		) (*tradeservice.GetOrderItemsResponse, error) {
+			return &tradeservice.GetOrderItemsResponse{
+				OrderId: req.OrderId,
+				Items: []*tradeservice.OrderItemInfo{
+					{ItemId: 1, CourseId: 10, CourseName: "Go", Price: 5000, RealPayAmount: 4500},
+				},
+			}, nil
+		},
+	}
+	logic := NewGetOrderItemsLogic(context.Background(), &svc.ServiceContext{TradeRPC: mockRPC, OrderIDs: testOrderIDs})
+
+	_, err := logic.GetOrderItems(&types.GetOrderItemsReq{OrderID: "not-an-id"}, 7)
+	assert.Error(t, err)
+
+	resp, err := logic.GetOrderItems(&types.GetOrderItemsReq{OrderID: testOrderIDs.Encode(42)}, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, testOrderIDs.Encode(42), resp.OrderID)
+	assert.Len(t, resp.Items, 1)
+	assert.Equal(t, 4500, resp.Items[0].RealPayAmount)
+}
@@ -0,0 +1,77 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aether-defense-system/common/snowflake"
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+	"github.com/aether-defense-system/service/trade/api/internal/types"
+	"github.com/aether-defense-system/service/trade/rpc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GetOrderLogic handles single order queries.
+type GetOrderLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewGetOrderLogic creates a new GetOrderLogic instance.
+func NewGetOrderLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetOrderLogic {
+	return &GetOrderLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetOrder fetches one of the caller's orders by calling Trade RPC.
+func (l *GetOrderLogic) GetOrder(req *types.GetOrderReq, userID int64) (*types.OrderInfo, error) {
+	orderID, err := parseOrderID(l.svcCtx.OrderIDs, req.OrderID)
+	if err != nil {
+		l.Errorf("%v for user_id: %d", err, userID)
+		return nil, err
+	}
+
+	rpcResp, err := l.svcCtx.TradeRPC.GetOrder(l.ctx, &rpc.GetOrderRequest{
+		UserId:  userID,
+		OrderId: orderID,
+	})
+	if err != nil {
+		l.Errorf("failed to get order via RPC: %v, userID=%d, orderID=%d", err, userID, orderID)
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	order := toOrderInfo(l.svcCtx.OrderIDs, rpcResp.Order)
+	return &order, nil
+}
+
+// parseOrderID decodes the public order ID of a request.
+func parseOrderID(codec *snowflake.IDCodec, publicID string) (int64, error) {
+	orderID, err := codec.Decode(publicID)
+	if err != nil || orderID <= 0 {
+		return 0, fmt.Errorf("invalid order_id: %q", publicID)
+	}
+	return orderID, nil
+}
+
+// toOrderInfo converts an RPC order to its HTTP representation.
+func toOrderInfo(codec *snowflake.IDCodec, o *rpc.OrderInfo) types.OrderInfo {
+	if o == nil {
+		return types.OrderInfo{}
+	}
+	return types.OrderInfo{
+		OrderID:     codec.Encode(o.OrderId),
+		Status:      int(o.Status),
+		TotalAmount: int(o.TotalAmount),
+		PayAmount:   int(o.PayAmount),
+		PayChannel:  int(o.PayChannel),
+		OutTradeNo:  o.OutTradeNo,
+		PayTime:     o.PayTime,
+		CreateTime:  o.CreateTime,
+		UpdateTime:  o.UpdateTime,
+	}
+}
@@ -0,0 +1,64 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+	"github.com/aether-defense-system/service/trade/api/internal/types"
+	"github.com/aether-defense-system/service/trade/rpc/tradeservice"
+)
+
+func TestGetOrderLogic_GetOrder(t *testing.T) {
+	var got *tradeservice.GetOrderRequest
+	mockRPC := &mockTradeRPC{
+		getOrderFunc: func(
+			_ context.Context,
+			req *tradeservice.GetOrderRequest,
+		) (*tradeservice.GetOrderResponse, error) {
+			got = req
+			return &tradeservice.GetOrderResponse{
+				Order: &tradeservice.OrderInfo{OrderId: req.OrderId, Status: 3, PayAmount: 9900, PayChannel: 1},
+			}, nil
+		},
+	}
+	logic := NewGetOrderLogic(context.Background(), &svc.ServiceContext{TradeRPC: mockRPC, OrderIDs: testOrderIDs})
+
+	_, err := logic.GetOrder(&types.GetOrderReq{OrderID: ""}, 1)
+	assert.Error(t, err)
+
+	// Raw numeric IDs are not accepted; order IDs only cross the API in their public form.
+	_, err = logic.GetOrder(&types.GetOrderReq{OrderID: "42"}, 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid order_id")
+	assert.Nil(t, got, "invalid requests must not reach trade-rpc")
+
+	resp, err := logic.GetOrder(&types.GetOrderReq{OrderID: testOrderIDs.Encode(42)}, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), got.UserId, "user id must come from the token")
+	assert.Equal(t, int64(42), got.OrderId)
+	assert.Equal(t, testOrderIDs.Encode(42), resp.OrderID)
+	assert.Equal(t, 3, resp.Status)
+	assert.Equal(t, 9900, resp.PayAmount)
+	assert.Equal(t, 1, resp.PayChannel)
+}
+
+func TestGetOrderLogic_GetOrder_RPCError(t *testing.T) {
+	mockRPC := &mockTradeRPC{
+		getOrderFunc: func(
+			_ context.Context,
+			_ *tradeservice.GetOrderRequest,
+		) (*tradeservice.GetOrderResponse, error) {
+			return nil, fmt.Errorf("order does not belong to user")
+		},
+	}
+	logic := NewGetOrderLogic(context.Background(), &svc.ServiceContext{TradeRPC: mockRPC, OrderIDs: testOrderIDs})
+
+	resp, err := logic.GetOrder(&types.GetOrderReq{OrderID: testOrderIDs.Encode(42)}, 7)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get order")
+	assert.Nil(t, resp)
+}
@@ -0,0 +1,62 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aether-defense-system/common/snowflake"
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+	"github.com/aether-defense-system/service/trade/api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// IssueCheckoutTokenLogic handles checkout token issuance.
+type IssueCheckoutTokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewIssueCheckoutTokenLogic creates a new IssueCheckoutTokenLogic instance.
+func NewIssueCheckoutTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *IssueCheckoutTokenLogic {
+	return &IssueCheckoutTokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// IssueCheckoutToken issues a one-time token for PlaceOrder.
+// The order ID is generated here and bound to the token, so every retry of the same
+// checkout targets the same order instead of creating a new one.
+func (l *IssueCheckoutTokenLogic) IssueCheckoutToken(userID int64) (*types.CheckoutTokenResp, error) {
+	if userID <= 0 {
+		l.Errorf("invalid user_id: %d", userID)
+		return nil, fmt.Errorf("invalid user_id: %d", userID)
+	}
+
+	if l.svcCtx.CheckoutTokens == nil {
+		l.Errorf("checkout token store not initialized")
+		return nil, fmt.Errorf("checkout token store not available")
+	}
+
+	orderID, err := snowflake.Next()
+	if err != nil {
+		l.Errorf("failed to generate order ID: %v", err)
+		return nil, fmt.Errorf("failed to generate order ID: %w", err)
+	}
+
+	token, expireAt, err := l.svcCtx.CheckoutTokens.Issue(l.ctx, userID, strconv.FormatInt(orderID, 10))
+	if err != nil {
+		l.Errorf("failed to issue checkout token: %v, userID=%d", err, userID)
+		return nil, fmt.Errorf("failed to issue checkout token: %w", err)
+	}
+
+	return &types.CheckoutTokenResp{
+		Token:      token,
+		OrderID:    l.svcCtx.OrderIDs.Encode(orderID),
+		ExpireTime: expireAt.Unix(),
+	}, nil
+}
@@ -0,0 +1,42 @@
+package logic
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+)
+
+func TestIssueCheckoutTokenLogic_IssueCheckoutToken(t *testing.T) {
+	tokens := newFakeCheckoutTokens()
+	logic := NewIssueCheckoutTokenLogic(context.Background(), &svc.ServiceContext{CheckoutTokens: tokens, OrderIDs: testOrderIDs})
+
+	resp, err := logic.IssueCheckoutToken(1)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.Token)
+	assert.Greater(t, resp.ExpireTime, int64(0))
+	orderID, err := testOrderIDs.Decode(resp.OrderID)
+	assert.NoError(t, err)
+	assert.Greater(t, orderID, int64(0))
+	assert.Equal(t, strconv.FormatInt(orderID, 10), tokens.tokens[resp.Token], "token is bound to the order ID")
+
+	second, err := logic.IssueCheckoutToken(1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, resp.Token, second.Token)
+	assert.NotEqual(t, resp.OrderID, second.OrderID)
+}
+
+func TestIssueCheckoutTokenLogic_IssueCheckoutToken_Errors(t *testing.T) {
+	logic := NewIssueCheckoutTokenLogic(context.Background(), &svc.ServiceContext{CheckoutTokens: newFakeCheckoutTokens()})
+	_, err := logic.IssueCheckoutToken(0)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid user_id")
+
+	noStore := NewIssueCheckoutTokenLogic(context.Background(), &svc.ServiceContext{})
+	_, err = noStore.IssueCheckoutToken(1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checkout token store not available")
+}
@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+	"github.com/aether-defense-system/service/trade/api/internal/types"
+	"github.com/aether-defense-system/service/trade/rpc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ListMyOrdersLogic handles the "my orders" listing.
+type ListMyOrdersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewListMyOrdersLogic creates a new ListMyOrdersLogic instance.
+func NewListMyOrdersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListMyOrdersLogic {
+	return &ListMyOrdersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListMyOrders lists the caller's orders by calling Trade RPC.
+// Status, cursor and page size are validated by Trade RPC.
+func (l *ListMyOrdersLogic) ListMyOrders(req *types.ListMyOrdersReq, userID int64) (*types.ListMyOrdersResp, error) {
+	rpcResp, err := l.svcCtx.TradeRPC.ListMyOrders(l.ctx, &rpc.ListMyOrdersRequest{
+		UserId: userID,
+		Status: int32(req.Status),
+		Cursor: req.Cursor,
+		Limit:  int32(req.Limit),
+	})
+	if err != nil {
+		l.Errorf("failed to list orders via RPC: %v, userID=%d", err, userID)
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	orders := make([]types.OrderInfo, 0, len(rpcResp.Orders))
+	for _, o := range rpcResp.Orders {
+		orders = append(orders, toOrderInfo(l.svcCtx.OrderIDs, o))
+	}
+
+	return &types.ListMyOrdersResp{
+		Orders:     orders,
+		NextCursor: rpcResp.NextCursor,
+		HasMore:    rpcResp.HasMore,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+	"github.com/aether-defense-system/service/trade/api/internal/types"
+	"github.com/aether-defense-system/service/trade/rpc/tradeservice"
+)
+
+func TestListMyOrdersLogic_ListMyOrders(t *testing.T) {
+	var got *tradeservice.ListMyOrdersRequest
+	mockRPC := &mockTradeRPC{
+		listMyOrdersFunc: func(
+			_ context.Context,
+			req *tradeservice.ListMyOrdersRequest,
+		) (*tradeservice.ListMyOrdersResponse, error) {
+			got = req
+			return &tradeservice.ListMyOrdersResponse{
+				Orders:     []*tradeservice.OrderInfo{{OrderId: 2}, {OrderId: 1}},
+				NextCursor: "next",
+				HasMore:    true,
+			}, nil
+		},
+	}
+	logic := NewListMyOrdersLogic(context.Background(), &svc.ServiceContext{TradeRPC: mockRPC, OrderIDs: testOrderIDs})
+
+	resp, err := logic.ListMyOrders(&types.ListMyOrdersReq{Status: 3, Cursor: "c", Limit: 2}, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), got.UserId)
+	assert.Equal(t, int32(3), got.Status)
+	assert.Equal(t, "c", got.Cursor)
+	assert.Equal(t, int32(2), got.Limit)
+	assert.Len(t, resp.Orders, 2)
+	assert.Equal(t, testOrderIDs.Encode(2), resp.Orders[0].OrderID)
+	assert.Equal(t, "next", resp.NextCursor)
+	assert.True(t, resp.HasMore)
+}
@@ -3,14 +3,17 @@ package logic
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 
-	"github.com/aether-defense-system/common/snowflake"
 	"github.com/aether-defense-system/service/trade/api/internal/svc"
 	"github.com/aether-defense-system/service/trade/api/internal/types"
 	"github.com/aether-defense-system/service/trade/rpc"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // PlaceOrderLogic handles order placement logic.
@@ -29,7 +32,21 @@ func NewPlaceOrderLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PlaceO
 	}
 }
 
+// Order states reported by PlaceOrder.
+const (
+	// OrderStateCommitted means the order was created.
+	OrderStateCommitted = "committed"
+	// OrderStateProcessing means the outcome was not known yet: the order was still being created
+	// when Trade RPC stopped waiting, or the call failed without telling whether it was created.
+	OrderStateProcessing = "processing"
+)
+
 // PlaceOrder places an order by calling Trade RPC.
+// It requires a one-time checkout token (see IssueCheckoutToken) that carries the order ID.
+//
+// A rolled-back or rejected order is returned as an error and its token stays usable. A processing
+// order, which includes an order whose RPC failed without a definite outcome, is polled by
+// replaying the same request, or through GetOrder, until it is committed.
 func (l *PlaceOrderLogic) PlaceOrder(req *types.PlaceOrderReq, userID int64) (resp *types.PlaceOrderResp, err error) {
 	// Input validation: CourseIDs
 	if len(req.CourseIDs) == 0 {
@@ -60,19 +77,66 @@ func (l *PlaceOrderLogic) PlaceOrder(req *types.PlaceOrderReq, userID int64) (re
 	}
 
 	// Input validation: OrderID (if provided)
-	if req.OrderID < 0 {
-		l.Errorf("invalid order_id: %d for user_id: %d", req.OrderID, userID)
-		return nil, fmt.Errorf("invalid order_id: %d", req.OrderID)
-	}
-
-	// Generate order ID if not provided
-	orderID := req.OrderID
-	if orderID <= 0 {
-		orderID, err = snowflake.Next()
-		if err != nil {
-			l.Errorf("failed to generate order ID: %v", err)
-			return nil, fmt.Errorf("failed to generate order ID: %w", err)
+	var requestedID int64
+	if req.OrderID != "" {
+		if requestedID, err = parseOrderID(l.svcCtx.OrderIDs, req.OrderID); err != nil {
+			l.Errorf("%v for user_id: %d", err, userID)
+			return nil, err
+		}
+	}
+
+	if req.Token == "" {
+		l.Errorf("missing checkout token for user_id: %d", userID)
+		return nil, fmt.Errorf("token cannot be empty")
+	}
+
+	if l.svcCtx.CheckoutTokens == nil {
+		l.Errorf("checkout token store not initialized")
+		return nil, fmt.Errorf("checkout token store not available")
+	}
+
+	// Consume the checkout token. A replay returns the original response, so a client that
+	// timed out can resend the same request without creating a second order.
+	claim, err := l.svcCtx.CheckoutTokens.Consume(l.ctx, userID, req.Token)
+	if err != nil {
+		l.Errorf("failed to consume checkout token: %v, userID=%d", err, userID)
+		return nil, fmt.Errorf("failed to place order: %w", err)
+	}
+	if claim.Replayed {
+		var replayed types.PlaceOrderResp
+		if err = json.Unmarshal([]byte(claim.Result), &replayed); err != nil {
+			l.Errorf("failed to decode replayed order response: %v, userID=%d", err, userID)
+			return nil, fmt.Errorf("failed to decode replayed order response: %w", err)
+		}
+		l.Infof("replayed checkout token: userID=%d, orderID=%s, state=%s", userID, replayed.OrderID, replayed.State)
+		if replayed.State == OrderStateProcessing {
+			return l.refreshProcessing(userID, req.Token, &replayed), nil
 		}
+		return &replayed, nil
+	}
+
+	orderID, err := strconv.ParseInt(claim.Payload, 10, 64)
+	if err != nil {
+		l.Errorf("invalid order ID bound to checkout token: %q, userID=%d", claim.Payload, userID)
+		return nil, fmt.Errorf("invalid checkout token payload: %w", err)
+	}
+
+	// Errors are only returned when the order was definitely not created, so the token stays usable
+	// for a retry; unknown outcomes are reported processing and keep it consumed, so that a retry
+	// cannot place a second order.
+	defer func() {
+		if err == nil {
+			return
+		}
+		if releaseErr := l.svcCtx.CheckoutTokens.Release(l.ctx, userID, req.Token, claim.Payload); releaseErr != nil {
+			l.Errorf("failed to release checkout token: %v, userID=%d, orderID=%d", releaseErr, userID, orderID)
+		}
+	}()
+
+	if requestedID != 0 && requestedID != orderID {
+		l.Errorf("order_id %d does not match checkout token order %d for user_id: %d", requestedID, orderID, userID)
+		err = fmt.Errorf("order_id does not match checkout token")
+		return nil, err
 	}
 
 	// Calculate real amount (simplified - in production, calculate from course prices and coupons)
@@ -88,15 +152,95 @@ func (l *PlaceOrderLogic) PlaceOrder(req *types.PlaceOrderReq, userID int64) (re
 		RealAmount: realAmount,
 	}
 
-	rpcResp, err := l.svcCtx.TradeRPC.PlaceOrder(l.ctx, rpcReq)
+	rpcResp, rpcErr := l.svcCtx.TradeRPC.PlaceOrder(l.ctx, rpcReq)
+	if rpcErr != nil {
+		if rejected(rpcErr) {
+			l.Errorf("order rejected by RPC: %v, userID=%d, orderID=%d", rpcErr, userID, orderID)
+			err = fmt.Errorf("failed to place order: %w", rpcErr)
+			return nil, err
+		}
+		// A timeout or transport error does not tell whether the order was created: it is reported
+		// processing, and replays of the token find out through GetOrder.
+		l.Errorf("order outcome unknown after RPC error: %v, userID=%d, orderID=%d", rpcErr, userID, orderID)
+		resp = &types.PlaceOrderResp{
+			OrderID: l.svcCtx.OrderIDs.Encode(orderID),
+			State:   OrderStateProcessing,
+			Reason:  fmt.Sprintf("order outcome unknown: %v", rpcErr),
+		}
+		l.complete(userID, req.Token, orderID, resp)
+		return resp, nil
+	}
+
+	switch rpcResp.Outcome {
+	case rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED:
+		resp = &types.PlaceOrderResp{
+			OrderID:   l.svcCtx.OrderIDs.Encode(rpcResp.OrderId),
+			PayAmount: int(rpcResp.PayAmount),
+			Status:    int(rpcResp.Status),
+			State:     OrderStateCommitted,
+		}
+	case rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_ROLLED_BACK:
+		// Nothing was created, so the released token can be retried for the same order ID.
+		l.Errorf("order rolled back: %s, userID=%d, orderID=%d", rpcResp.Reason, userID, orderID)
+		err = fmt.Errorf("order was not created: %s", rpcResp.Reason)
+		return nil, err
+	default:
+		l.Infof("order still processing: %s, userID=%d, orderID=%d", rpcResp.Reason, userID, orderID)
+		resp = &types.PlaceOrderResp{
+			OrderID: l.svcCtx.OrderIDs.Encode(rpcResp.OrderId),
+			State:   OrderStateProcessing,
+			Reason:  rpcResp.Reason,
+		}
+	}
+
+	// The token's fate is settled now; failing to record the result only affects replays, so it is logged, not returned.
+	l.complete(userID, req.Token, orderID, resp)
+	return resp, nil
+}
+
+// refreshProcessing re-checks an order that was still being created when its token was last used.
+// The order is reported committed once Trade RPC finds it; until then the recorded response is returned.
+func (l *PlaceOrderLogic) refreshProcessing(userID int64, token string, resp *types.PlaceOrderResp) *types.PlaceOrderResp {
+	orderID, err := parseOrderID(l.svcCtx.OrderIDs, resp.OrderID)
 	if err != nil {
-		l.Errorf("failed to place order via RPC: %v, userID=%d, orderID=%d", err, userID, orderID)
-		return nil, fmt.Errorf("failed to place order: %w", err)
+		l.Errorf("failed to decode replayed order ID: %v, userID=%d", err, userID)
+		return resp
 	}
 
-	return &types.PlaceOrderResp{
-		OrderID:   rpcResp.OrderId,
-		PayAmount: int(rpcResp.PayAmount),
-		Status:    int(rpcResp.Status),
-	}, nil
+	rpcResp, err := l.svcCtx.TradeRPC.GetOrder(l.ctx, &rpc.GetOrderRequest{UserId: userID, OrderId: orderID})
+	if err != nil || rpcResp.Order == nil {
+		l.Infof("order still processing: %v, userID=%d, orderID=%d", err, userID, orderID)
+		return resp
+	}
+
+	committed := &types.PlaceOrderResp{
+		OrderID:   l.svcCtx.OrderIDs.Encode(rpcResp.Order.OrderId),
+		PayAmount: int(rpcResp.Order.PayAmount),
+		Status:    int(rpcResp.Order.Status),
+		State:     OrderStateCommitted,
+	}
+	l.complete(userID, token, orderID, committed)
+	return committed
+}
+
+// rejected reports whether Trade RPC rejected a PlaceOrder request without creating the order.
+func rejected(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.AlreadyExists, codes.Unauthenticated, codes.PermissionDenied:
+		return true
+	default:
+		return false
+	}
+}
+
+// complete records resp, for order orderID, as the result that replays of the token return.
+func (l *PlaceOrderLogic) complete(userID int64, token string, orderID int64, resp *types.PlaceOrderResp) {
+	result, err := json.Marshal(resp)
+	if err != nil {
+		l.Errorf("failed to encode order response: %v, orderID=%d", err, orderID)
+		return
+	}
+	if err = l.svcCtx.CheckoutTokens.Complete(l.ctx, userID, token, string(result)); err != nil {
+		l.Errorf("failed to record checkout token result: %v, orderID=%d", err, orderID)
+	}
 }
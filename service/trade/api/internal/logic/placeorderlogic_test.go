@@ -4,15 +4,30 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/aether-defense-system/common/idempotency"
+	"github.com/aether-defense-system/common/snowflake"
 	"github.com/aether-defense-system/service/trade/api/internal/svc"
 	"github.com/aether-defense-system/service/trade/api/internal/types"
+	"github.com/aether-defense-system/service/trade/rpc"
 	"github.com/aether-defense-system/service/trade/rpc/tradeservice"
 )
 
+// testOrderIDs is the public order ID codec shared by the logic tests.
+var testOrderIDs = func() *snowflake.IDCodec {
+	codec, err := snowflake.NewIDCodec([]byte("trade-api-test-public-id-key"))
+	if err != nil {
+		panic(err)
+	}
+	return codec
+}()
+
 // mockTradeRPC mocks the TradeRPC service.
 type mockTradeRPC struct {
 	placeOrderFunc func(
@@ -23,6 +38,26 @@ type mockTradeRPC struct {
 		ctx context.Context,
 		req *tradeservice.CancelOrderRequest,
 	) (*tradeservice.CancelOrderResponse, error)
+	getOrderFunc func(
+		ctx context.Context,
+		req *tradeservice.GetOrderRequest,
+	) (*tradeservice.GetOrderResponse, error)
+	listMyOrdersFunc func(
+		ctx context.Context,
+		req *tradeservice.ListMyOrdersRequest,
+	) (*tradeservice.ListMyOrdersResponse, error)
+	getOrderItemsFunc func(
+		ctx context.Context,
+		req *tradeservice.GetOrderItemsRequest,
+	) (*tradeservice.GetOrderItemsResponse, error)
+	requestRefundFunc func(
+		ctx context.Context,
+		req *tradeservice.RequestRefundRequest,
+	) (*tradeservice.RequestRefundResponse, error)
+	approveRefundFunc func(
+		ctx context.Context,
+		req *tradeservice.ApproveRefundRequest,
+	) (*tradeservice.ApproveRefundResponse, error)
 }
 
 func (m *mockTradeRPC) PlaceOrder(
@@ -37,6 +72,7 @@ func (m *mockTradeRPC) PlaceOrder(
 		OrderId:   req.OrderId,
 		PayAmount: req.RealAmount,
 		Status:    1,
+		Outcome:   rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED,
 	}, nil
 }
 
@@ -55,8 +91,93 @@ func (m *mockTradeRPC) CancelOrder(
 	}, nil
 }
 
+func (m *mockTradeRPC) GetOrder(
+	ctx context.Context,
+	req *tradeservice.GetOrderRequest,
+	_ ...grpc.CallOption,
+) (*tradeservice.GetOrderResponse, error) {
+	if m.getOrderFunc != nil {
+		return m.getOrderFunc(ctx, req)
+	}
+	return &tradeservice.GetOrderResponse{
+		Order: &tradeservice.OrderInfo{OrderId: req.OrderId, UserId: req.UserId, Status: 1},
+	}, nil
+}
+
+func (m *mockTradeRPC) ListMyOrders(
+	ctx context.Context,
+	req *tradeservice.ListMyOrdersRequest,
+	_ ...grpc.CallOption,
+) (*tradeservice.ListMyOrdersResponse, error) {
+	if m.listMyOrdersFunc != nil {
+		return m.listMyOrdersFunc(ctx, req)
+	}
+	return &tradeservice.ListMyOrdersResponse{}, nil
+}
+
+func (m *mockTradeRPC) GetOrderItems(
+	ctx context.Context,
+	req *tradeservice.GetOrderItemsRequest,
+	_ ...grpc.CallOption,
+) (*tradeservice.GetOrderItemsResponse, error) {
+	if m.getOrderItemsFunc != nil {
+		return m.getOrderItemsFunc(ctx, req)
+	}
+	return &tradeservice.GetOrderItemsResponse{OrderId: req.OrderId}, nil
+}
+
+func (m *mockTradeRPC) RequestRefund(
+	ctx context.Context,
+	req *tradeservice.RequestRefundRequest,
+	_ ...grpc.CallOption,
+) (*tradeservice.RequestRefundResponse, error) {
+	if m.requestRefundFunc != nil {
+		return m.requestRefundFunc(ctx, req)
+	}
+	return &tradeservice.RequestRefundResponse{
+		Refund: &tradeservice.RefundInfo{OrderId: req.OrderId, UserId: req.UserId, Status: 1},
+	}, nil
+}
+
+func (m *mockTradeRPC) ApproveRefund(
+	ctx context.Context,
+	req *tradeservice.ApproveRefundRequest,
+	_ ...grpc.CallOption,
+) (*tradeservice.ApproveRefundResponse, error) {
+	if m.approveRefundFunc != nil {
+		return m.approveRefundFunc(ctx, req)
+	}
+	return &tradeservice.ApproveRefundResponse{
+		Refund: &tradeservice.RefundInfo{RefundId: req.RefundId, Status: 3},
+	}, nil
+}
+
+func (m *mockTradeRPC) AdminListDeadLetters(
+	_ context.Context,
+	_ *tradeservice.AdminListDeadLettersRequest,
+	_ ...grpc.CallOption,
+) (*tradeservice.AdminListDeadLettersResponse, error) {
+	return &tradeservice.AdminListDeadLettersResponse{}, nil
+}
+
+func (m *mockTradeRPC) AdminReplayDeadLetters(
+	_ context.Context,
+	_ *tradeservice.AdminReplayDeadLettersRequest,
+	_ ...grpc.CallOption,
+) (*tradeservice.AdminReplayDeadLettersResponse, error) {
+	return &tradeservice.AdminReplayDeadLettersResponse{}, nil
+}
+
+func (m *mockTradeRPC) AdminSearchOrders(
+	_ context.Context,
+	_ *tradeservice.AdminSearchOrdersRequest,
+	_ ...grpc.CallOption,
+) (*tradeservice.AdminSearchOrdersResponse, error) {
+	return &tradeservice.AdminSearchOrdersResponse{}, nil
+}
+
 func TestPlaceOrderLogic_PlaceOrder_ValidationErrors(t *testing.T) {
-	svcCtx := &svc.ServiceContext{}
+	svcCtx := &svc.ServiceContext{OrderIDs: testOrderIDs}
 	logic := NewPlaceOrderLogic(context.Background(), svcCtx)
 
 	tests := []struct {
@@ -142,14 +263,14 @@ func TestPlaceOrderLogic_PlaceOrder_ValidationErrors(t *testing.T) {
 			errMsg:  "invalid coupon_id: -1",
 		},
 		{
-			name: "invalid order id - negative",
+			name: "invalid order id - raw number",
 			req: &types.PlaceOrderReq{
 				CourseIDs: []int64{1},
-				OrderID:   -1,
+				OrderID:   "100",
 			},
 			userID:  1,
 			wantErr: true,
-			errMsg:  "invalid order_id: -1",
+			errMsg:  `invalid order_id: "100"`,
 		},
 	}
 
@@ -169,81 +290,214 @@ func TestPlaceOrderLogic_PlaceOrder_ValidationErrors(t *testing.T) {
 	}
 }
 
+// fakeCheckoutTokens is an in-memory CheckoutTokenStore with the same consume/replay semantics as
+// idempotency.TokenStore.
+type fakeCheckoutTokens struct {
+	tokens  map[string]string // token -> payload, for unused tokens
+	results map[string]string // token -> result, for consumed tokens ("" while in flight)
+	issued  int
+}
+
+func newFakeCheckoutTokens() *fakeCheckoutTokens {
+	return &fakeCheckoutTokens{tokens: make(map[string]string), results: make(map[string]string)}
+}
+
+func (f *fakeCheckoutTokens) Issue(_ context.Context, _ int64, payload string) (string, time.Time, error) {
+	f.issued++
+	token := fmt.Sprintf("token-%d", f.issued)
+	f.tokens[token] = payload
+	return token, time.Now().Add(time.Minute), nil
+}
+
+func (f *fakeCheckoutTokens) Consume(_ context.Context, _ int64, token string) (*idempotency.Claim, error) {
+	if payload, ok := f.tokens[token]; ok {
+		delete(f.tokens, token)
+		f.results[token] = ""
+		return &idempotency.Claim{Payload: payload}, nil
+	}
+	result, ok := f.results[token]
+	switch {
+	case !ok:
+		return nil, idempotency.ErrInvalidToken
+	case result == "":
+		return nil, idempotency.ErrTokenInFlight
+	default:
+		return &idempotency.Claim{Result: result, Replayed: true}, nil
+	}
+}
+
+func (f *fakeCheckoutTokens) Complete(_ context.Context, _ int64, token, result string) error {
+	f.results[token] = result
+	return nil
+}
+
+func (f *fakeCheckoutTokens) Release(_ context.Context, _ int64, token, payload string) error {
+	delete(f.results, token)
+	f.tokens[token] = payload
+	return nil
+}
+
 func TestPlaceOrderLogic_PlaceOrder_Success(t *testing.T) {
+	var calls int
 	mockRPC := &mockTradeRPC{
 		placeOrderFunc: func(
 			_ context.Context,
 			req *tradeservice.PlaceOrderRequest,
 		) (*tradeservice.PlaceOrderResponse, error) {
+			calls++
 			return &tradeservice.PlaceOrderResponse{
 				OrderId:   req.OrderId,
 				PayAmount: req.RealAmount,
 				Status:    1,
+				Outcome:   rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED,
 			}, nil
 		},
 	}
+	tokens := newFakeCheckoutTokens()
+	token, _, _ := tokens.Issue(context.Background(), 1, "100")
 	svcCtx := &svc.ServiceContext{
-		TradeRPC: mockRPC,
+		TradeRPC:       mockRPC,
+		CheckoutTokens: tokens,
+		OrderIDs:       testOrderIDs,
 	}
 	logic := NewPlaceOrderLogic(context.Background(), svcCtx)
 
 	req := &types.PlaceOrderReq{
+		Token:     token,
 		CourseIDs: []int64{1, 2, 3},
 		CouponIDs: []int64{10, 20},
-		OrderID:   100,
+		OrderID:   testOrderIDs.Encode(100),
 	}
 
 	resp, err := logic.PlaceOrder(req, 1)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
-	assert.Equal(t, int64(100), resp.OrderID)
+	assert.Equal(t, testOrderIDs.Encode(100), resp.OrderID)
 	assert.Equal(t, 10000, resp.PayAmount) // Placeholder amount
 	assert.Equal(t, 1, resp.Status)
+	assert.Equal(t, OrderStateCommitted, resp.State)
+
+	// A retry after a client timeout returns the original response without a second RPC.
+	replayed, err := logic.PlaceOrder(req, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, resp, replayed)
+	assert.Equal(t, 1, calls)
 }
 
-func TestPlaceOrderLogic_PlaceOrder_GenerateOrderID(t *testing.T) {
+func TestPlaceOrderLogic_PlaceOrder_UsesTokenOrderID(t *testing.T) {
 	mockRPC := &mockTradeRPC{
 		placeOrderFunc: func(
 			_ context.Context,
 			req *tradeservice.PlaceOrderRequest,
 		) (*tradeservice.PlaceOrderResponse, error) {
-			// Verify that order ID was generated (should be > 0)
-			assert.Greater(t, req.OrderId, int64(0))
+			assert.Equal(t, int64(200), req.OrderId)
 			return &tradeservice.PlaceOrderResponse{
 				OrderId:   req.OrderId,
 				PayAmount: req.RealAmount,
 				Status:    1,
+				Outcome:   rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED,
 			}, nil
 		},
 	}
-	svcCtx := &svc.ServiceContext{
-		TradeRPC: mockRPC,
+	tokens := newFakeCheckoutTokens()
+	token, _, _ := tokens.Issue(context.Background(), 1, "200")
+	logic := NewPlaceOrderLogic(context.Background(), &svc.ServiceContext{
+		TradeRPC:       mockRPC,
+		CheckoutTokens: tokens,
+		OrderIDs:       testOrderIDs,
+	})
+
+	resp, err := logic.PlaceOrder(&types.PlaceOrderReq{Token: token, CourseIDs: []int64{1}}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, testOrderIDs.Encode(200), resp.OrderID)
+}
+
+func TestPlaceOrderLogic_PlaceOrder_TokenErrors(t *testing.T) {
+	tokens := newFakeCheckoutTokens()
+	inFlight, _, _ := tokens.Issue(context.Background(), 1, "300")
+	_, _ = tokens.Consume(context.Background(), 1, inFlight)
+	mismatched, _, _ := tokens.Issue(context.Background(), 1, "400")
+	logic := NewPlaceOrderLogic(context.Background(), &svc.ServiceContext{
+		TradeRPC:       &mockTradeRPC{},
+		CheckoutTokens: tokens,
+		OrderIDs:       testOrderIDs,
+	})
+
+	tests := []struct {
+		req     *types.PlaceOrderReq
+		wantErr error
+		name    string
+		errMsg  string
+	}{
+		{
+			name:   "missing token",
+			req:    &types.PlaceOrderReq{CourseIDs: []int64{1}},
+			errMsg: "token cannot be empty",
+		},
+		{
+			name:    "unknown token",
+			req:     &types.PlaceOrderReq{Token: "unknown", CourseIDs: []int64{1}},
+			wantErr: idempotency.ErrInvalidToken,
+		},
+		{
+			name:    "token in flight",
+			req:     &types.PlaceOrderReq{Token: inFlight, CourseIDs: []int64{1}},
+			wantErr: idempotency.ErrTokenInFlight,
+		},
+		{
+			name:   "order id mismatch",
+			req:    &types.PlaceOrderReq{Token: mismatched, CourseIDs: []int64{1}, OrderID: testOrderIDs.Encode(401)},
+			errMsg: "order_id does not match checkout token",
+		},
 	}
-	logic := NewPlaceOrderLogic(context.Background(), svcCtx)
 
-	req := &types.PlaceOrderReq{
-		CourseIDs: []int64{1},
-		OrderID:   0, // Will trigger order ID generation
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := logic.PlaceOrder(tt.req, 1)
+			assert.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.Contains(t, err.Error(), tt.errMsg)
+			}
+			assert.Nil(t, resp)
+		})
 	}
 
-	resp, err := logic.PlaceOrder(req, 1)
-	assert.NoError(t, err)
-	assert.NotNil(t, resp)
-	assert.Greater(t, resp.OrderID, int64(0))
+	assert.Equal(t, "400", tokens.tokens[mismatched], "a rejected request leaves the token usable")
+
+	noStore := NewPlaceOrderLogic(context.Background(), &svc.ServiceContext{TradeRPC: &mockTradeRPC{}, OrderIDs: testOrderIDs})
+	_, err := noStore.PlaceOrder(&types.PlaceOrderReq{Token: "token", CourseIDs: []int64{1}}, 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checkout token store not available")
 }
 
 func TestPlaceOrderLogic_PlaceOrder_RPCError(t *testing.T) {
+	rpcErr := status.Error(codes.InvalidArgument, "real_amount must be greater than 0")
 	mockRPC := &mockTradeRPC{
-		placeOrderFunc: func(_ context.Context, _ *tradeservice.PlaceOrderRequest) (*tradeservice.PlaceOrderResponse, error) {
-			return nil, fmt.Errorf("rpc error")
+		placeOrderFunc: func(_ context.Context, req *tradeservice.PlaceOrderRequest) (*tradeservice.PlaceOrderResponse, error) {
+			if rpcErr != nil {
+				return nil, rpcErr
+			}
+			return &tradeservice.PlaceOrderResponse{
+				OrderId:   req.OrderId,
+				PayAmount: req.RealAmount,
+				Status:    1,
+				Outcome:   rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED,
+			}, nil
 		},
 	}
+	tokens := newFakeCheckoutTokens()
+	token, _, _ := tokens.Issue(context.Background(), 1, "100")
 	svcCtx := &svc.ServiceContext{
-		TradeRPC: mockRPC,
+		TradeRPC:       mockRPC,
+		CheckoutTokens: tokens,
+		OrderIDs:       testOrderIDs,
 	}
 	logic := NewPlaceOrderLogic(context.Background(), svcCtx)
 
 	req := &types.PlaceOrderReq{
+		Token:     token,
 		CourseIDs: []int64{1},
 	}
 
@@ -251,6 +505,121 @@ func TestPlaceOrderLogic_PlaceOrder_RPCError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to place order")
 	assert.Nil(t, resp)
+
+	// The rejected attempt released the token, so the retry places the same order.
+	rpcErr = nil
+	resp, err = logic.PlaceOrder(req, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, testOrderIDs.Encode(100), resp.OrderID)
+}
+
+func TestPlaceOrderLogic_PlaceOrder_RPCOutcomeUnknown(t *testing.T) {
+	var calls int
+	mockRPC := &mockTradeRPC{
+		placeOrderFunc: func(_ context.Context, _ *tradeservice.PlaceOrderRequest) (*tradeservice.PlaceOrderResponse, error) {
+			calls++
+			return nil, status.Error(codes.DeadlineExceeded, "context deadline exceeded")
+		},
+		getOrderFunc: func(_ context.Context, req *tradeservice.GetOrderRequest) (*tradeservice.GetOrderResponse, error) {
+			return &tradeservice.GetOrderResponse{
+				Order: &tradeservice.OrderInfo{OrderId: req.OrderId, UserId: req.UserId, Status: 1, PayAmount: 10000},
+			}, nil
+		},
+	}
+	tokens := newFakeCheckoutTokens()
+	token, _, _ := tokens.Issue(context.Background(), 1, "100")
+	logic := NewPlaceOrderLogic(context.Background(), &svc.ServiceContext{
+		TradeRPC:       mockRPC,
+		CheckoutTokens: tokens,
+		OrderIDs:       testOrderIDs,
+	})
+	req := &types.PlaceOrderReq{Token: token, CourseIDs: []int64{1}}
+
+	// The order may have been created, so the token stays consumed and the order is processing.
+	resp, err := logic.PlaceOrder(req, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStateProcessing, resp.State)
+	assert.Equal(t, testOrderIDs.Encode(100), resp.OrderID)
+	assert.NotContains(t, tokens.tokens, token)
+
+	// A replay finds the order through GetOrder instead of placing it again.
+	resp, err = logic.PlaceOrder(req, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStateCommitted, resp.State)
+	assert.Equal(t, 1, calls)
+}
+
+func TestPlaceOrderLogic_PlaceOrder_RolledBack(t *testing.T) {
+	mockRPC := &mockTradeRPC{
+		placeOrderFunc: func(_ context.Context, req *tradeservice.PlaceOrderRequest) (*tradeservice.PlaceOrderResponse, error) {
+			return &tradeservice.PlaceOrderResponse{
+				OrderId: req.OrderId,
+				Outcome: rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_ROLLED_BACK,
+				Reason:  "duplicate entry",
+			}, nil
+		},
+	}
+	tokens := newFakeCheckoutTokens()
+	token, _, _ := tokens.Issue(context.Background(), 1, "100")
+	logic := NewPlaceOrderLogic(context.Background(), &svc.ServiceContext{
+		TradeRPC:       mockRPC,
+		CheckoutTokens: tokens,
+		OrderIDs:       testOrderIDs,
+	})
+
+	resp, err := logic.PlaceOrder(&types.PlaceOrderReq{Token: token, CourseIDs: []int64{1}}, 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "order was not created: duplicate entry")
+	assert.Nil(t, resp)
+	assert.Equal(t, "100", tokens.tokens[token], "a rolled-back order leaves the token usable")
+}
+
+func TestPlaceOrderLogic_PlaceOrder_Processing(t *testing.T) {
+	var created bool
+	mockRPC := &mockTradeRPC{
+		placeOrderFunc: func(_ context.Context, req *tradeservice.PlaceOrderRequest) (*tradeservice.PlaceOrderResponse, error) {
+			return &tradeservice.PlaceOrderResponse{
+				OrderId: req.OrderId,
+				Outcome: rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_PENDING,
+				Reason:  "order creation outcome unknown; awaiting transaction check-back",
+			}, nil
+		},
+		getOrderFunc: func(_ context.Context, req *tradeservice.GetOrderRequest) (*tradeservice.GetOrderResponse, error) {
+			if !created {
+				return nil, fmt.Errorf("order not found: %d", req.OrderId)
+			}
+			return &tradeservice.GetOrderResponse{
+				Order: &tradeservice.OrderInfo{OrderId: req.OrderId, UserId: req.UserId, Status: 1, PayAmount: 10000},
+			}, nil
+		},
+	}
+	tokens := newFakeCheckoutTokens()
+	token, _, _ := tokens.Issue(context.Background(), 1, "100")
+	logic := NewPlaceOrderLogic(context.Background(), &svc.ServiceContext{
+		TradeRPC:       mockRPC,
+		CheckoutTokens: tokens,
+		OrderIDs:       testOrderIDs,
+	})
+	req := &types.PlaceOrderReq{Token: token, CourseIDs: []int64{1}}
+
+	resp, err := logic.PlaceOrder(req, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStateProcessing, resp.State)
+	assert.Equal(t, testOrderIDs.Encode(100), resp.OrderID)
+	assert.Contains(t, resp.Reason, "check-back")
+
+	// Polling by replaying the token reports processing until the order exists.
+	resp, err = logic.PlaceOrder(req, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStateProcessing, resp.State)
+
+	created = true
+	resp, err = logic.PlaceOrder(req, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStateCommitted, resp.State)
+	assert.Equal(t, 10000, resp.PayAmount)
+	assert.Equal(t, 1, resp.Status)
+	assert.Contains(t, tokens.results[token], `"state":"committed"`, "the committed response replaces the recorded one")
 }
 
 func TestPlaceOrderLogic_NewPlaceOrderLogic(t *testing.T) {
@@ -0,0 +1,82 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aether-defense-system/common/snowflake"
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+	"github.com/aether-defense-system/service/trade/api/internal/types"
+	"github.com/aether-defense-system/service/trade/rpc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// RequestRefundLogic handles refund requests.
+type RequestRefundLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRequestRefundLogic creates a new RequestRefundLogic instance.
+func NewRequestRefundLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RequestRefundLogic {
+	return &RequestRefundLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RequestRefund requests a refund of one of the caller's orders by calling Trade RPC.
+// Without order item IDs every item that is not yet refunded is included.
+func (l *RequestRefundLogic) RequestRefund(req *types.RequestRefundReq, userID int64) (*types.RefundInfo, error) {
+	orderID, err := parseOrderID(l.svcCtx.OrderIDs, req.OrderID)
+	if err != nil {
+		l.Errorf("%v for user_id: %d", err, userID)
+		return nil, err
+	}
+
+	for i, itemID := range req.OrderItemIDs {
+		if itemID <= 0 {
+			l.Errorf("invalid order_item_id at index %d: %d for user_id: %d", i, itemID, userID)
+			return nil, fmt.Errorf("invalid order_item_id: %d", itemID)
+		}
+	}
+
+	rpcResp, err := l.svcCtx.TradeRPC.RequestRefund(l.ctx, &rpc.RequestRefundRequest{
+		UserId:       userID,
+		OrderId:      orderID,
+		OrderItemIds: req.OrderItemIDs,
+		Reason:       req.Reason,
+	})
+	if err != nil {
+		l.Errorf("failed to request refund via RPC: %v, userID=%d, orderID=%d", err, userID, orderID)
+		return nil, fmt.Errorf("failed to request refund: %w", err)
+	}
+
+	info := toRefundInfo(l.svcCtx.OrderIDs, rpcResp.Refund)
+	return &info, nil
+}
+
+// toRefundInfo converts an RPC refund to its HTTP representation.
+func toRefundInfo(codec *snowflake.IDCodec, refund *rpc.RefundInfo) types.RefundInfo {
+	info := types.RefundInfo{
+		RefundID:    refund.GetRefundId(),
+		OrderID:     codec.Encode(refund.GetOrderId()),
+		Status:      int(refund.GetStatus()),
+		Amount:      int(refund.GetAmount()),
+		Reason:      refund.GetReason(),
+		OutRefundNo: refund.GetOutRefundNo(),
+		FailReason:  refund.GetFailReason(),
+		Items:       make([]types.RefundItemInfo, 0, len(refund.GetItems())),
+	}
+	for _, item := range refund.GetItems() {
+		info.Items = append(info.Items, types.RefundItemInfo{
+			OrderItemID: item.OrderItemId,
+			CourseID:    item.CourseId,
+			Amount:      int(item.Amount),
+		})
+	}
+	return info
+}
@@ -0,0 +1,61 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aether-defense-system/service/trade/api/internal/svc"
+	"github.com/aether-defense-system/service/trade/api/internal/types"
+	"github.com/aether-defense-system/service/trade/rpc/tradeservice"
+)
+
+func TestRequestRefundLogic_RequestRefund(t *testing.T) {
+	var got *tradeservice.RequestRefundRequest
+	mockRPC := &mockTradeRPC{
+		requestRefundFunc: func(
+			_ context.Context,
+			req *tradeservice.RequestRefundRequest,
+		) (*tradeservice.RequestRefundResponse, error) {
+			got = req
+			return &tradeservice.RequestRefundResponse{
+				Refund: &tradeservice.RefundInfo{
+					RefundId: 900,
+					OrderId:  req.OrderId,
+					UserId:   req.UserId,
+					Status:   1,
+					Amount:   4500,
+					Reason:   req.Reason,
+					Items: []*tradeservice.RefundItemInfo{
+						{OrderItemId: 11, CourseId: 10, Amount: 4500},
+					},
+				},
+			}, nil
+		},
+	}
+	logic := NewRequestRefundLogic(context.Background(), &svc.ServiceContext{TradeRPC: mockRPC, OrderIDs: testOrderIDs})
+
+	_, err := logic.RequestRefund(&types.RequestRefundReq{OrderID: ""}, 7)
+	assert.Error(t, err)
+
+	_, err = logic.RequestRefund(&types.RequestRefundReq{OrderID: testOrderIDs.Encode(42), OrderItemIDs: []int64{11, -1}}, 7)
+	assert.Error(t, err)
+	assert.Nil(t, got, "invalid requests must not reach trade-rpc")
+
+	resp, err := logic.RequestRefund(&types.RequestRefundReq{
+		OrderID:      testOrderIDs.Encode(42),
+		OrderItemIDs: []int64{11},
+		Reason:       "changed my mind",
+	}, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), got.UserId)
+	assert.Equal(t, int64(42), got.OrderId)
+	assert.Equal(t, testOrderIDs.Encode(42), resp.OrderID)
+	assert.Equal(t, []int64{11}, got.OrderItemIds)
+	assert.Equal(t, int64(900), resp.RefundID)
+	assert.Equal(t, 4500, resp.Amount)
+	assert.Equal(t, "changed my mind", resp.Reason)
+	assert.Len(t, resp.Items, 1)
+	assert.Equal(t, int64(11), resp.Items[0].OrderItemID)
+}
@@ -2,28 +2,132 @@
 package svc
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aether-defense-system/common/auth"
+	"github.com/aether-defense-system/common/idempotency"
+	"github.com/aether-defense-system/common/middleware"
+	"github.com/aether-defense-system/common/redis"
+	"github.com/aether-defense-system/common/snowflake"
 	"github.com/aether-defense-system/service/trade/api/internal/config"
 	"github.com/aether-defense-system/service/trade/rpc/tradeservice"
 
+	"github.com/zeromicro/go-zero/rest"
 	"github.com/zeromicro/go-zero/zrpc"
 )
 
+// CheckoutTokenStore issues and consumes one-time checkout tokens.
+// *idempotency.TokenStore satisfies this interface; tests substitute in-memory fakes.
+type CheckoutTokenStore interface {
+	Issue(ctx context.Context, userID int64, payload string) (string, time.Time, error)
+	Consume(ctx context.Context, userID int64, token string) (*idempotency.Claim, error)
+	Complete(ctx context.Context, userID int64, token, result string) error
+	Release(ctx context.Context, userID int64, token, payload string) error
+}
+
 // ServiceContext wires configuration and external dependencies for trade-api.
 type ServiceContext struct {
-	Config    *config.Config
-	TradeRPC  tradeservice.TradeService
-	JWTSecret string // JWT access secret for authentication
+	Config         *config.Config
+	TradeRPC       tradeservice.TradeService
+	CheckoutTokens CheckoutTokenStore     // Nil when checkout Redis is not configured
+	OrderIDs       *snowflake.IDCodec     // Converts order IDs to and from their public IDs
+	SessionCheck   rest.Middleware        // Rejects revoked sessions; nil when session Redis is not configured
+	Permission     rest.Middleware        // Enforces route permissions; denies unregistered admin routes
+	workerLease    *snowflake.WorkerLease // Worker ID of the default snowflake generator, when leased
+	JWTSecret      string                 // JWT access secret for authentication
 }
 
+// workerLeaseTimeout bounds leasing and releasing the snowflake worker ID.
+const workerLeaseTimeout = 10 * time.Second
+
+const (
+	// checkoutTokenTTL bounds how long a checkout page may stay open before ordering.
+	checkoutTokenTTL = 15 * time.Minute
+	// checkoutResultTTL is how long a used token keeps answering retries with the original order.
+	checkoutResultTTL = 24 * time.Hour
+)
+
+// AdminPathPrefix is the path prefix under which routes are denied unless a permission is registered.
+const AdminPathPrefix = "/v1/admin/"
+
 // NewServiceContext creates a new ServiceContext.
 func NewServiceContext(c *config.Config) *ServiceContext {
+	// Order IDs are only issued once the worker ID is leased, so that no two gateways share it.
+	var workerLease *snowflake.WorkerLease
+	if c.Snowflake.Enabled() {
+		ctx, cancel := context.WithTimeout(context.Background(), workerLeaseTimeout)
+		lease, err := snowflake.InitializeDefaultFromLease(ctx, &c.Snowflake)
+		cancel()
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize snowflake: %v", err))
+		}
+		workerLease = lease
+	}
+
+	orderIDs, err := snowflake.NewIDCodec([]byte(c.PublicIDKey))
+	if err != nil {
+		panic(fmt.Sprintf("failed to initialize public ID codec: %v", err))
+	}
+
 	var tradeRPC tradeservice.TradeService
 	if c.TradeRPC != nil {
 		tradeRPC = tradeservice.NewTradeService(zrpc.MustNewClient(*c.TradeRPC))
 	}
+
+	var sessionCheck rest.Middleware
+	if c.SessionRedis.Addr != "" || c.SessionRedis.Host != "" {
+		redisClient, err := redis.NewClient(&c.SessionRedis)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize session Redis: %v", err))
+		}
+		// The gateway only checks sessions; user-rpc creates and refreshes them.
+		sessions, err := auth.NewSessionStore(redisClient, 0)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize session store: %v", err))
+		}
+		sessionCheck = middleware.NewSessionMiddleware(sessions).Handle
+	}
+
+	var checkoutTokens CheckoutTokenStore
+	if c.CheckoutRedis.Addr != "" || c.CheckoutRedis.Host != "" {
+		redisClient, err := redis.NewClient(&c.CheckoutRedis)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize checkout Redis: %v", err))
+		}
+		tokens, err := idempotency.NewTokenStore(redisClient, "checkout", checkoutTokenTTL, checkoutResultTTL)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize checkout token store: %v", err))
+		}
+		checkoutTokens = tokens
+	}
+
 	return &ServiceContext{
-		Config:    c,
-		TradeRPC:  tradeRPC,
-		JWTSecret: c.Auth.AccessSecret,
+		Config:         c,
+		TradeRPC:       tradeRPC,
+		CheckoutTokens: checkoutTokens,
+		OrderIDs:       orderIDs,
+		JWTSecret:      c.Auth.AccessSecret,
+		SessionCheck:   sessionCheck,
+		Permission:     newPermissionMiddleware().Handle,
+		workerLease:    workerLease,
 	}
 }
+
+// Close releases the resources owned by the service context. It is called after the server stops.
+func (s *ServiceContext) Close() error {
+	if s.workerLease == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), workerLeaseTimeout)
+	defer cancel()
+	return s.workerLease.Release(ctx)
+}
+
+// newPermissionMiddleware registers the permission required by each protected route.
+func newPermissionMiddleware() *middleware.PermissionMiddleware {
+	return middleware.NewPermissionMiddleware(auth.DefaultPolicy(), AdminPathPrefix).
+		Require(http.MethodPost, "/v1/admin/refunds/:refundId/approve", auth.PermOrderRefund)
+}
@@ -3,14 +3,108 @@ package types
 
 // PlaceOrderReq represents the HTTP request to place an order.
 type PlaceOrderReq struct {
+	Token     string  `json:"token"`               // One-time checkout token from /v1/trade/order/token
 	CourseIDs []int64 `json:"courseIds"`           // Purchased course list
 	CouponIDs []int64 `json:"couponIds,omitempty"` // Selected coupon IDs, optional
-	OrderID   int64   `json:"orderId"`             // Pre-generated order ID
+	OrderID   string  `json:"orderId,optional"`    // Public order ID bound to the token, optional; must match when given
+}
+
+// CheckoutTokenResp represents the HTTP response carrying a one-time checkout token.
+type CheckoutTokenResp struct {
+	Token      string `json:"token"`      // Send with PlaceOrder; retries with the same token are safe
+	OrderID    string `json:"orderId"`    // Public ID of the order the token will create
+	ExpireTime int64  `json:"expireTime"` // Token expiry (unix seconds)
 }
 
 // PlaceOrderResp represents the HTTP response for placing an order.
 type PlaceOrderResp struct {
-	OrderID   int64 `json:"orderId"`   // Returned order number
-	PayAmount int   `json:"payAmount"` // Actual payment amount (in cents)
-	Status    int   `json:"status"`    // Order status (1: Pending Payment)
+	State     string `json:"state"`     // committed, or processing while the order is still being created
+	Reason    string `json:"reason"`    // Why the order is still processing
+	OrderID   string `json:"orderId"`   // Public order ID
+	PayAmount int    `json:"payAmount"` // Actual payment amount (in cents), 0 while processing
+	Status    int    `json:"status"`    // Order status (1: Pending Payment), 0 while processing
+}
+
+// OrderInfo represents an order in query responses.
+type OrderInfo struct {
+	OutTradeNo  string `json:"outTradeNo"`  // Third-party transaction number, empty if unpaid
+	OrderID     string `json:"orderId"`     // Public order ID
+	PayTime     int64  `json:"payTime"`     // Payment time (unix seconds), 0 if unpaid
+	CreateTime  int64  `json:"createTime"`  // Creation time (unix seconds)
+	UpdateTime  int64  `json:"updateTime"`  // Last update time (unix seconds)
+	Status      int    `json:"status"`      // Order status (1: Pending Payment, 2: Closed, 3: Paid, 4: Finished, 5: Refunded)
+	TotalAmount int    `json:"totalAmount"` // Total amount (in cents)
+	PayAmount   int    `json:"payAmount"`   // Actual payment amount (in cents)
+	PayChannel  int    `json:"payChannel"`  // Payment channel (0: unpaid, 1: Alipay, 2: WeChat)
+}
+
+// OrderItemInfo represents an order item in query responses.
+type OrderItemInfo struct {
+	CourseName    string `json:"courseName"`    // Course name at purchase time
+	ItemID        int64  `json:"itemId"`        // Order item ID
+	CourseID      int64  `json:"courseId"`      // Course ID
+	Price         int    `json:"price"`         // Unit price at purchase time (in cents)
+	RealPayAmount int    `json:"realPayAmount"` // Allocated payment amount (in cents)
+	RefundStatus  int    `json:"refundStatus"`  // Refund status (0: None, 1: Refunding, 2: Refunded)
+}
+
+// GetOrderReq represents the HTTP request to fetch one of the caller's orders.
+type GetOrderReq struct {
+	OrderID string `path:"orderId"` // Public order ID
+}
+
+// ListMyOrdersReq represents the HTTP request to list the caller's orders.
+type ListMyOrdersReq struct {
+	Cursor string `form:"cursor,optional"` // Cursor from the previous page, empty for the first page
+	Status int    `form:"status,optional"` // Order status filter, 0 for all statuses
+	Limit  int    `form:"limit,optional"`  // Page size, defaults to 20, maximum 100
+}
+
+// ListMyOrdersResp represents the HTTP response listing the caller's orders.
+type ListMyOrdersResp struct {
+	Orders     []OrderInfo `json:"orders"`     // Orders, newest first
+	NextCursor string      `json:"nextCursor"` // Cursor for the next page, empty when there are no more orders
+	HasMore    bool        `json:"hasMore"`    // Whether more orders exist after this page
+}
+
+// GetOrderItemsReq represents the HTTP request to fetch the items of one of the caller's orders.
+type GetOrderItemsReq struct {
+	OrderID string `path:"orderId"` // Public order ID
+}
+
+// GetOrderItemsResp represents the HTTP response for order items.
+type GetOrderItemsResp struct {
+	Items   []OrderItemInfo `json:"items"`
+	OrderID string          `json:"orderId"` // Public order ID
+}
+
+// RefundItemInfo represents a refunded order item.
+type RefundItemInfo struct {
+	OrderItemID int64 `json:"orderItemId"` // Order item ID
+	CourseID    int64 `json:"courseId"`    // Course ID
+	Amount      int   `json:"amount"`      // Refunded amount (in cents)
+}
+
+// RefundInfo represents a refund in responses.
+type RefundInfo struct {
+	Items       []RefundItemInfo `json:"items"`
+	Reason      string           `json:"reason"`      // Refund reason
+	OutRefundNo string           `json:"outRefundNo"` // Third-party refund number, empty until succeeded
+	FailReason  string           `json:"failReason"`  // Payment gateway failure reason, empty unless failed
+	RefundID    int64            `json:"refundId"`    // Refund ID
+	OrderID     string           `json:"orderId"`     // Public ID of the refunded order
+	Status      int              `json:"status"`      // Refund status (1: Requested, 2: Approved, 3: Succeeded, 4: Failed)
+	Amount      int              `json:"amount"`      // Refund amount (in cents)
+}
+
+// RequestRefundReq represents the HTTP request to refund one of the caller's orders.
+type RequestRefundReq struct {
+	OrderItemIDs []int64 `json:"orderItemIds,optional"` // Items to refund, empty for every remaining item
+	Reason       string  `json:"reason,optional"`       // Refund reason
+	OrderID      string  `path:"orderId"`               // Public order ID
+}
+
+// ApproveRefundReq represents the admin HTTP request to approve a refund.
+type ApproveRefundReq struct {
+	RefundID int64 `path:"refundId"`
 }
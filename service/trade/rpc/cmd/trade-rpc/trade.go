@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/zrpc"
 	"google.golang.org/grpc"
 
@@ -21,14 +22,21 @@ func main() {
 	flag.Parse()
 
 	var c config.Config
-	conf.MustLoad(*configFile, &c)
+	conf.MustLoad(*configFile, &c, conf.UseEnv())
 
 	// Create service context with all dependencies
 	ctx := svc.NewServiceContext(&c)
+	// Runs after s.Stop, so in-flight PlaceOrder calls finish before the producer shuts down.
+	defer func() {
+		if err := ctx.Close(); err != nil {
+			logx.Errorf("failed to close service context: %v", err)
+		}
+	}()
 
 	s := zrpc.MustNewServer(c.RpcServerConf, func(grpcServer *grpc.Server) {
 		rpc.RegisterTradeServiceServer(grpcServer, server.NewTradeServiceServer(ctx))
 	})
+	s.AddUnaryInterceptors(ctx.Permission.Unary)
 	defer s.Stop()
 
 	_, _ = fmt.Printf("Starting trade rpc server at %s...\n", c.ListenOn)
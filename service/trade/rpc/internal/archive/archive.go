@@ -0,0 +1,112 @@
+// Package archive moves old orders of the trade service to the archive tables, keeping
+// trade_order small enough for its indexes to stay shallow.
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/service/trade/rpc/internal/config"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
+)
+
+// Job archives the orders in a final status once past their retention, in periodic passes over
+// every shard.
+//
+// Each batch is archived in its own transaction, which also records the progress of the pass in
+// the checkpoint of its shard: a pass interrupted by a failure or a restart resumes from there,
+// and several instances of the service can run the job, taking turns on each shard.
+type Job struct {
+	store    repo.OrderArchiveStore
+	cancel   context.CancelFunc
+	done     chan struct{}
+	config   config.ArchiveConf
+	stopOnce sync.Once
+}
+
+// NewJob creates a job that archives orders with store.
+func NewJob(store repo.OrderArchiveStore, cfg config.ArchiveConf) (*Job, error) {
+	if store == nil {
+		return nil, fmt.Errorf("order archive store cannot be nil")
+	}
+	if !cfg.Enabled() {
+		return nil, fmt.Errorf("invalid retention: %d days", cfg.RetentionDays)
+	}
+	return &Job{store: store, config: cfg}, nil
+}
+
+// Start runs a pass in the background right away, then every interval until Stop is called.
+func (j *Job) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	j.done = make(chan struct{})
+	go j.run(ctx)
+}
+
+// Stop stops the job and waits for the batch in progress.
+func (j *Job) Stop() {
+	j.stopOnce.Do(func() {
+		if j.cancel == nil {
+			return
+		}
+		j.cancel()
+		<-j.done
+	})
+}
+
+// run runs passes until ctx is canceled.
+func (j *Job) run(ctx context.Context) {
+	defer close(j.done)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		archived, err := j.RunOnce(ctx, time.Now())
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logx.Errorf("order archival failed after archiving %d orders: %v", archived, err)
+		} else if archived > 0 {
+			logx.Infof("archived %d orders", archived)
+		}
+		timer.Reset(j.config.GetInterval())
+	}
+}
+
+// RunOnce completes the pass of every shard, archiving the orders created and last updated more
+// than the retention before now, and returns how many it archived. A shard that fails is left for
+// the next pass, while the others proceed.
+func (j *Job) RunOnce(ctx context.Context, now time.Time) (int, error) {
+	cutoff := now.AddDate(0, 0, -j.config.RetentionDays)
+	total := 0
+	var errs []error
+	for shard := 0; shard < j.store.Shards(); shard++ {
+		for {
+			archived, done, err := j.store.ArchiveBatch(ctx, shard, cutoff, j.config.GetBatchSize())
+			total += archived
+			if err != nil {
+				errs = append(errs, err)
+				break
+			}
+			if done {
+				break
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return total, errors.Join(errs...)
+}
@@ -0,0 +1,124 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/service/trade/rpc/internal/config"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
+)
+
+// fakeArchiveStore archives batches of a fixed number of orders, per shard, until each shard has
+// no more.
+type fakeArchiveStore struct {
+	remaining map[int]int
+	failing   map[int]error
+	cutoffs   []time.Time
+	mu        sync.Mutex
+	shards    int
+}
+
+func (f *fakeArchiveStore) Shards() int {
+	return f.shards
+}
+
+func (f *fakeArchiveStore) ArchiveBatch(_ context.Context, shard int, cutoff time.Time, limit int) (int, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cutoffs = append(f.cutoffs, cutoff)
+	if err := f.failing[shard]; err != nil {
+		return 0, false, err
+	}
+	archived := min(limit, f.remaining[shard])
+	f.remaining[shard] -= archived
+	return archived, archived < limit, nil
+}
+
+func (f *fakeArchiveStore) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.cutoffs)
+}
+
+func TestNewJob(t *testing.T) {
+	_, err := NewJob(nil, config.ArchiveConf{RetentionDays: 30})
+	assert.ErrorContains(t, err, "store cannot be nil")
+
+	_, err = NewJob(repo.NewMemoryStore().OrderArchiver(), config.ArchiveConf{})
+	assert.ErrorContains(t, err, "invalid retention")
+}
+
+func TestJob_RunOnce(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	old, recent := now.AddDate(0, 0, -40), now.AddDate(0, 0, -10)
+	store := repo.NewMemoryStore()
+	for _, order := range []*database.TradeOrder{
+		{ID: 1, UserID: 1, Status: database.OrderStatusClosed, CreateTime: old, UpdateTime: old},
+		{ID: 2, UserID: 1, Status: database.OrderStatusFinished, CreateTime: old, UpdateTime: old},
+		{ID: 3, UserID: 1, Status: database.OrderStatusRefunded, CreateTime: old, UpdateTime: old},
+		{ID: 4, UserID: 1, Status: database.OrderStatusRefunded, CreateTime: old, UpdateTime: recent},
+		{ID: 5, UserID: 1, Status: database.OrderStatusPaid, CreateTime: old, UpdateTime: old},
+		{ID: 6, UserID: 1, Status: database.OrderStatusClosed, CreateTime: recent, UpdateTime: recent},
+	} {
+		store.Put(order)
+	}
+
+	job, err := NewJob(store.OrderArchiver(), config.ArchiveConf{RetentionDays: 30, BatchSize: 2})
+	require.NoError(t, err)
+	archived, err := job.RunOnce(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 3, archived)
+
+	// Archived orders remain readable, and the next pass has nothing left to archive.
+	got, err := store.OrderRepo().GetByID(context.Background(), 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.OrderStatusFinished), got.Status)
+	archived, err = job.RunOnce(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, archived)
+
+	// Orders past their retention by the time of a later pass are archived then.
+	archived, err = job.RunOnce(context.Background(), now.AddDate(0, 0, 30))
+	require.NoError(t, err)
+	assert.Equal(t, 2, archived)
+}
+
+func TestJob_RunOnce_ShardFailure(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeArchiveStore{
+		shards:    3,
+		remaining: map[int]int{0: 5, 2: 1},
+		failing:   map[int]error{1: errors.New("lock wait timeout exceeded")},
+	}
+	job, err := NewJob(store, config.ArchiveConf{RetentionDays: 7, BatchSize: 2})
+	require.NoError(t, err)
+
+	archived, err := job.RunOnce(context.Background(), now)
+	assert.ErrorContains(t, err, "lock wait timeout exceeded")
+	assert.Equal(t, 6, archived)
+	assert.Equal(t, map[int]int{0: 0, 2: 0}, store.remaining)
+	for _, cutoff := range store.cutoffs {
+		assert.Equal(t, now.AddDate(0, 0, -7), cutoff)
+	}
+}
+
+func TestJob_StartStop(t *testing.T) {
+	store := &fakeArchiveStore{shards: 1, remaining: map[int]int{0: 3}}
+	job, err := NewJob(store, config.ArchiveConf{RetentionDays: 7, BatchSize: 1, Interval: time.Hour})
+	require.NoError(t, err)
+
+	job.Start()
+	// The first pass runs right away, and archives batch after batch until it completes.
+	require.Eventually(t, func() bool { return store.calls() == 4 }, time.Second, 5*time.Millisecond)
+	job.Stop()
+	job.Stop()
+
+	assert.Equal(t, 4, store.calls(), "expected the next pass to wait for the interval")
+}
@@ -2,12 +2,97 @@
 package config
 
 import (
+	"time"
+
 	"github.com/zeromicro/go-zero/zrpc"
 
 	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/deadletter"
 	"github.com/aether-defense-system/common/mq"
+	"github.com/aether-defense-system/common/redis"
+	"github.com/aether-defense-system/common/segment"
+	"github.com/aether-defense-system/common/snowflake"
+)
+
+// AuthConf represents access token verification configuration.
+// AccessSecret must match the secret the API gateways use to verify JWTs.
+type AuthConf struct {
+	AccessSecret string `json:"accessSecret" yaml:"accessSecret"`
+}
+
+// PlaceOrderConf bounds how long PlaceOrder waits for the order-creation outcome.
+type PlaceOrderConf struct {
+	// ConfirmTimeout in milliseconds (default: 3000); PlaceOrder reports a pending order when it elapses.
+	ConfirmTimeout int `json:"confirmTimeout,optional" yaml:"confirmTimeout"`
+}
+
+// GetConfirmTimeout returns the confirm timeout, defaulting to 3s.
+func (c PlaceOrderConf) GetConfirmTimeout() time.Duration {
+	if c.ConfirmTimeout <= 0 {
+		return 3 * time.Second
+	}
+	return time.Duration(c.ConfirmTimeout) * time.Millisecond
+}
+
+// ArchiveConf configures the archival of old orders to the archive tables.
+type ArchiveConf struct {
+	// RetentionDays after which closed, finished and refunded orders are archived; 0 disables archival.
+	RetentionDays int `json:"retentionDays,optional" yaml:"retentionDays"`
+	// BatchSize is the number of orders archived per transaction (default: 200).
+	BatchSize int `json:"batchSize,optional" yaml:"batchSize"`
+	// Interval between archival passes (default: 1h).
+	Interval time.Duration `json:"interval,optional" yaml:"interval"`
+}
+
+// Enabled reports whether orders are archived.
+func (c ArchiveConf) Enabled() bool {
+	return c.RetentionDays > 0
+}
+
+// GetBatchSize returns the batch size, defaulting to 200.
+func (c ArchiveConf) GetBatchSize() int {
+	if c.BatchSize <= 0 {
+		return 200
+	}
+	return c.BatchSize
+}
+
+// GetInterval returns the interval between passes, defaulting to 1h.
+func (c ArchiveConf) GetInterval() time.Duration {
+	if c.Interval <= 0 {
+		return time.Hour
+	}
+	return c.Interval
+}
+
+// Order event delivery modes.
+const (
+	// OrderEventsModeTransaction sends ORDER_PLACED as a RocketMQ half message whose local
+	// transaction creates the order.
+	OrderEventsModeTransaction = "transaction"
+	// OrderEventsModeOutbox writes ORDER_PLACED to the outbox_event table with the order;
+	// a relay publishes it with a plain producer.
+	OrderEventsModeOutbox = "outbox"
 )
 
+// OrderEventsConf selects how order events are delivered.
+type OrderEventsConf struct {
+	// Mode is "transaction" (default) or "outbox".
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Mode string `json:"mode,optional" yaml:"mode"`
+	// Outbox configures the relay, which publishes refund events and, in outbox mode, order events.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Outbox mq.OutboxConfig `json:"outbox,optional" yaml:"outbox"`
+}
+
+// GetMode returns the delivery mode, defaulting to transaction.
+func (c OrderEventsConf) GetMode() string {
+	if c.Mode == "" {
+		return OrderEventsModeTransaction
+	}
+	return c.Mode
+}
+
 // Config represents the configuration for trade RPC service.
 type Config struct {
 	zrpc.RpcServerConf
@@ -15,4 +100,52 @@ type Config struct {
 	UserRPC      zrpc.RpcClientConf `json:"userRpc" yaml:"userRpc"`
 	PromotionRPC zrpc.RpcClientConf `json:"promotionRpc" yaml:"promotionRpc"`
 	Database     database.Config    `json:"database" yaml:"database"`
+
+	// Auth is optional: admin RPCs such as ApproveRefund are rejected when unset.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Auth AuthConf `json:"auth,optional" yaml:"auth"`
+
+	// SessionRedis is the user session store; when set, admin RPCs reject tokens of revoked sessions.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	SessionRedis redis.Config `json:"sessionRedis,optional" yaml:"sessionRedis"`
+
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	PlaceOrder PlaceOrderConf `json:"placeOrder,optional" yaml:"placeOrder"`
+
+	// OrderEvents is optional: order events use RocketMQ half messages when unset.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	OrderEvents OrderEventsConf `json:"orderEvents,optional" yaml:"orderEvents"`
+
+	// DeadLetters configures the dead-letter admin RPCs, available with RocketMQ and the database.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	DeadLetters deadletter.Config `json:"deadLetters,optional" yaml:"deadLetters"`
+
+	// Snowflake leases the worker ID of refund IDs and order item IDs from Redis or etcd.
+	// Without it the worker ID comes from the environment.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Snowflake snowflake.LeaseConfig `json:"snowflake,optional" yaml:"snowflake"`
+
+	// RefundIDs is optional: refund IDs are snowflake IDs unless it names an id_segment business
+	// tag, which needs the database.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	RefundIDs segment.Config `json:"refundIds,optional" yaml:"refundIds"`
+
+	// Sharding is optional: it spreads orders and refunds over databases by user_id, while other
+	// tables stay in Database. Each sharding database needs its own outbox_event table.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Sharding database.ShardingConfig `json:"sharding,optional" yaml:"sharding"`
+
+	// Reporting is the reporting database the CDC service keeps; AdminSearchOrders is unavailable
+	// without it.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Reporting database.Config `json:"reporting,optional" yaml:"reporting"`
+
+	// Archive is optional: orders are archived only when it sets a retention, which needs the database.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Archive ArchiveConf `json:"archive,optional" yaml:"archive"`
+
+	// AutoMigrate applies the pending common and trade schema migrations at startup, in every
+	// sharding database too. Without it the schema is migrated with the migrate command.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	AutoMigrate bool `json:"autoMigrate,optional" yaml:"autoMigrate"`
 }
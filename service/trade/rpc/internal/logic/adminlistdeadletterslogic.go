@@ -0,0 +1,100 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/common/deadletter"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+)
+
+// maxDeadLetterListLimit bounds the dead letters returned by one AdminListDeadLetters call.
+const maxDeadLetterListLimit = 200
+
+// AdminListDeadLettersLogic lists dead-lettered messages for admins.
+type AdminListDeadLettersLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewAdminListDeadLettersLogic creates a new AdminListDeadLettersLogic instance.
+func NewAdminListDeadLettersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminListDeadLettersLogic {
+	return &AdminListDeadLettersLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// AdminListDeadLetters lists the dead letters of a consumer group, oldest first, with their
+// decoded events and the reasons their last delivery failed. They can be filtered by event type
+// and by the order the event is about.
+func (l *AdminListDeadLettersLogic) AdminListDeadLetters(
+	req *rpc.AdminListDeadLettersRequest,
+) (*rpc.AdminListDeadLettersResponse, error) {
+	if req == nil {
+		l.Errorf("received nil AdminListDeadLettersRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	if req.ConsumerGroup == "" {
+		return nil, fmt.Errorf("consumer_group is required")
+	}
+
+	if req.Limit < 0 || req.Limit > maxDeadLetterListLimit {
+		return nil, fmt.Errorf("invalid limit: %d, must be between 1 and %d", req.Limit, maxDeadLetterListLimit)
+	}
+
+	if l.svcCtx.DeadLetters == nil {
+		l.Errorf("dead letter inspector not initialized")
+		return nil, fmt.Errorf("dead letter inspection not available")
+	}
+
+	dead, err := l.svcCtx.DeadLetters.List(l.ctx, deadletter.Filter{
+		Group:     req.ConsumerGroup,
+		EventType: req.EventType,
+		OrderID:   req.OrderId,
+		Limit:     int(req.Limit),
+	})
+	if err != nil {
+		l.Errorf("failed to list dead letters: %v, group=%s", err, req.ConsumerGroup)
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	infos := make([]*rpc.DeadLetterInfo, 0, len(dead))
+	for _, d := range dead {
+		infos = append(infos, toDeadLetterInfo(d))
+	}
+	return &rpc.AdminListDeadLettersResponse{DeadLetters: infos}, nil
+}
+
+// toDeadLetterInfo converts a dead letter to its RPC representation.
+func toDeadLetterInfo(d *deadletter.DeadLetter) *rpc.DeadLetterInfo {
+	info := &rpc.DeadLetterInfo{
+		MsgId:       d.Message.MsgID,
+		OriginTopic: d.OriginTopic,
+		Tag:         d.Message.Tag,
+		Keys:        d.Message.Keys,
+		EventId:     d.EventID(),
+		EventType:   d.EventType(),
+		PayloadJson: d.PayloadJSON(),
+	}
+	if d.Event != nil {
+		info.EventVersion = d.Event.Envelope.Version
+		info.AggregateId = d.Event.Envelope.AggregateId
+		info.OccurredAt = d.Event.Envelope.OccurredAt
+	}
+	if d.DecodeErr != nil {
+		info.DecodeError = d.DecodeErr.Error()
+	}
+	if d.Failure != nil {
+		info.FailureReason = d.Failure.Reason
+		info.FailedAt = d.Failure.FailedAt.UnixMilli()
+		info.ReconsumeTimes = d.Failure.ReconsumeTimes
+	}
+	return info
+}
@@ -0,0 +1,123 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/deadletter"
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/mq"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/config"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+)
+
+const deadLetterTestGroup = "promotion"
+
+// fakeReplayAudit records replays in memory.
+type fakeReplayAudit struct {
+	replays []*database.DeadLetterReplay
+}
+
+func (f *fakeReplayAudit) Insert(_ context.Context, replay *database.DeadLetterReplay) error {
+	f.replays = append(f.replays, replay)
+	return nil
+}
+
+// newDeadLetterTestContext returns a service context whose inspector reads a memory broker in which
+// the promotion group dead-lettered an ORDER_PLACED event for order 42 and one for order 43.
+func newDeadLetterTestContext(t *testing.T) (*svc.ServiceContext, *mq.MemoryBroker, *fakeReplayAudit) {
+	t.Helper()
+	broker := mq.NewMemoryBroker(mq.WithMaxReconsumeTimes(0))
+	t.Cleanup(broker.Shutdown)
+
+	consumer, err := broker.NewConsumer(deadLetterTestGroup)
+	require.NoError(t, err)
+	require.NoError(t, consumer.Subscribe("orders", "*", func(context.Context, *mq.Message) error {
+		return errors.New("inventory unavailable")
+	}))
+	require.NoError(t, consumer.Start())
+
+	for _, orderID := range []int64{42, 43} {
+		var msg *mq.Message
+		msg, err = newDeadLetterTestMessage(orderID)
+		require.NoError(t, err)
+		_, err = broker.NewProducer().Send(context.Background(), msg)
+		require.NoError(t, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, broker.WaitIdle(ctx))
+	require.NoError(t, consumer.Shutdown())
+
+	audit := &fakeReplayAudit{}
+	inspector, err := deadletter.NewInspector(deadletter.Config{SandboxTopic: "orders-sandbox"},
+		broker, broker.NewProducer(), audit)
+	require.NoError(t, err)
+	return &svc.ServiceContext{Config: &config.Config{}, DeadLetters: inspector}, broker, audit
+}
+
+func newDeadLetterTestMessage(orderID int64) (*mq.Message, error) {
+	placed := &event.OrderPlaced{OrderId: orderID, UserId: 1, CourseIds: []int64{1}}
+	env, err := event.Default.NewEnvelope(event.TypeOrderPlaced, strconv.FormatInt(orderID, 10), placed)
+	if err != nil {
+		return nil, err
+	}
+	return event.Default.NewMessage("orders", env)
+}
+
+func TestAdminListDeadLettersLogic_AdminListDeadLetters(t *testing.T) {
+	svcCtx, _, _ := newDeadLetterTestContext(t)
+	logic := NewAdminListDeadLettersLogic(context.Background(), svcCtx)
+
+	resp, err := logic.AdminListDeadLetters(&rpc.AdminListDeadLettersRequest{ConsumerGroup: deadLetterTestGroup})
+	require.NoError(t, err)
+	assert.Len(t, resp.DeadLetters, 2)
+
+	resp, err = logic.AdminListDeadLetters(&rpc.AdminListDeadLettersRequest{
+		ConsumerGroup: deadLetterTestGroup, EventType: event.TypeOrderPlaced, OrderId: 43,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.DeadLetters, 1)
+	info := resp.DeadLetters[0]
+	assert.NotEmpty(t, info.MsgId)
+	assert.Equal(t, "orders", info.OriginTopic)
+	assert.Equal(t, event.TypeOrderPlaced, info.EventType)
+	assert.Equal(t, int32(2), info.EventVersion)
+	assert.NotEmpty(t, info.EventId)
+	assert.Contains(t, info.PayloadJson, `"orderId":"43"`)
+	assert.Empty(t, info.DecodeError)
+	assert.Empty(t, info.FailureReason, "no failure log is configured")
+}
+
+func TestAdminListDeadLettersLogic_AdminListDeadLetters_Invalid(t *testing.T) {
+	svcCtx, _, _ := newDeadLetterTestContext(t)
+	logic := NewAdminListDeadLettersLogic(context.Background(), svcCtx)
+
+	tests := []struct {
+		req  *rpc.AdminListDeadLettersRequest
+		name string
+	}{
+		{name: "nil request", req: nil},
+		{name: "no group", req: &rpc.AdminListDeadLettersRequest{}},
+		{name: "negative limit", req: &rpc.AdminListDeadLettersRequest{ConsumerGroup: deadLetterTestGroup, Limit: -1}},
+		{name: "limit too large", req: &rpc.AdminListDeadLettersRequest{ConsumerGroup: deadLetterTestGroup, Limit: 201}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := logic.AdminListDeadLetters(tt.req)
+			assert.Error(t, err)
+		})
+	}
+
+	unavailable := NewAdminListDeadLettersLogic(context.Background(), &svc.ServiceContext{Config: &config.Config{}})
+	_, err := unavailable.AdminListDeadLetters(&rpc.AdminListDeadLettersRequest{ConsumerGroup: deadLetterTestGroup})
+	assert.Error(t, err)
+}
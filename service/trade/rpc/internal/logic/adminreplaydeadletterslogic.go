@@ -0,0 +1,102 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/common/auth"
+	"github.com/aether-defense-system/common/deadletter"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+)
+
+// maxDeadLetterReplayBatch bounds the messages replayed by one AdminReplayDeadLetters call.
+const maxDeadLetterReplayBatch = 100
+
+// AdminReplayDeadLettersLogic replays dead-lettered messages for admins.
+type AdminReplayDeadLettersLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewAdminReplayDeadLettersLogic creates a new AdminReplayDeadLettersLogic instance.
+func NewAdminReplayDeadLettersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminReplayDeadLettersLogic {
+	return &AdminReplayDeadLettersLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// AdminReplayDeadLetters sends the selected dead letters of a consumer group back to the topic
+// they were dead-lettered from, or to the sandbox topic for a dry run. Each attempt is recorded
+// in the audit log with the calling admin as operator.
+//
+// A message that cannot be replayed, for example because it is no longer in the dead-letter
+// topic, is reported in its result rather than failing the call.
+func (l *AdminReplayDeadLettersLogic) AdminReplayDeadLetters(
+	req *rpc.AdminReplayDeadLettersRequest,
+) (*rpc.AdminReplayDeadLettersResponse, error) {
+	if req == nil {
+		l.Errorf("received nil AdminReplayDeadLettersRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	if req.ConsumerGroup == "" {
+		return nil, fmt.Errorf("consumer_group is required")
+	}
+
+	if len(req.MsgIds) == 0 || len(req.MsgIds) > maxDeadLetterReplayBatch {
+		return nil, fmt.Errorf("invalid msg_ids count: %d, must be between 1 and %d",
+			len(req.MsgIds), maxDeadLetterReplayBatch)
+	}
+
+	var target deadletter.Target
+	switch req.Target {
+	case rpc.ReplayTarget_REPLAY_TARGET_ORIGIN:
+		target = deadletter.TargetOrigin
+	case rpc.ReplayTarget_REPLAY_TARGET_SANDBOX:
+		target = deadletter.TargetSandbox
+	default:
+		return nil, fmt.Errorf("invalid target: %d", req.Target)
+	}
+
+	claims, ok := auth.ClaimsFromContext(l.ctx)
+	if !ok {
+		l.Errorf("no caller claims for dead letter replay")
+		return nil, fmt.Errorf("caller not authenticated")
+	}
+
+	if l.svcCtx.DeadLetters == nil {
+		l.Errorf("dead letter inspector not initialized")
+		return nil, fmt.Errorf("dead letter replay not available")
+	}
+
+	results, err := l.svcCtx.DeadLetters.Replay(l.ctx, &deadletter.ReplayRequest{
+		Group:    req.ConsumerGroup,
+		Operator: fmt.Sprintf("user:%d", claims.UserID),
+		Reason:   req.Reason,
+		MsgIDs:   req.MsgIds,
+		Target:   target,
+	})
+	if err != nil {
+		l.Errorf("failed to replay dead letters: %v, group=%s, replayed=%d", err, req.ConsumerGroup, len(results))
+		return nil, fmt.Errorf("failed to replay dead letters: %w", err)
+	}
+
+	resp := &rpc.AdminReplayDeadLettersResponse{Results: make([]*rpc.ReplayResult, 0, len(results))}
+	for _, r := range results {
+		result := &rpc.ReplayResult{MsgId: r.MsgID, TargetTopic: r.TargetTopic, NewMsgId: r.NewMsgID}
+		if r.Err != nil {
+			result.Error = r.Err.Error()
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	l.Infof("replayed dead letters: group=%s, target=%s, operator=user:%d, messages=%d",
+		req.ConsumerGroup, req.Target, claims.UserID, len(results))
+	return resp, nil
+}
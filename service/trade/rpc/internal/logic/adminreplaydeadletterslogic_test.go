@@ -0,0 +1,99 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/auth"
+	"github.com/aether-defense-system/common/deadletter"
+	"github.com/aether-defense-system/service/trade/rpc"
+)
+
+func adminContext() context.Context {
+	return auth.WithClaims(context.Background(), &auth.Claims{UserID: 9, Roles: []string{auth.RoleAdmin}})
+}
+
+func TestAdminReplayDeadLettersLogic_AdminReplayDeadLetters(t *testing.T) {
+	svcCtx, broker, audit := newDeadLetterTestContext(t)
+	dead := broker.DeadLetters(deadLetterTestGroup)
+	require.Len(t, dead, 2)
+
+	logic := NewAdminReplayDeadLettersLogic(adminContext(), svcCtx)
+	resp, err := logic.AdminReplayDeadLetters(&rpc.AdminReplayDeadLettersRequest{
+		ConsumerGroup: deadLetterTestGroup,
+		MsgIds:        []string{dead[0].MsgID, "missing"},
+		Reason:        "inventory restored",
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, "orders", resp.Results[0].TargetTopic)
+	assert.NotEmpty(t, resp.Results[0].NewMsgId)
+	assert.Empty(t, resp.Results[0].Error)
+	assert.NotEmpty(t, resp.Results[1].Error)
+	assert.Len(t, broker.Messages("orders"), 3)
+
+	require.Len(t, audit.replays, 2)
+	assert.Equal(t, "user:9", audit.replays[0].Operator)
+	assert.Equal(t, "inventory restored", audit.replays[0].Reason)
+	assert.False(t, audit.replays[0].DryRun)
+}
+
+func TestAdminReplayDeadLettersLogic_AdminReplayDeadLetters_Sandbox(t *testing.T) {
+	svcCtx, broker, audit := newDeadLetterTestContext(t)
+	dead := broker.DeadLetters(deadLetterTestGroup)
+
+	logic := NewAdminReplayDeadLettersLogic(adminContext(), svcCtx)
+	resp, err := logic.AdminReplayDeadLetters(&rpc.AdminReplayDeadLettersRequest{
+		ConsumerGroup: deadLetterTestGroup,
+		MsgIds:        []string{dead[1].MsgID},
+		Target:        rpc.ReplayTarget_REPLAY_TARGET_SANDBOX,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "orders-sandbox", resp.Results[0].TargetTopic)
+
+	sandbox := broker.Messages("orders-sandbox")
+	require.Len(t, sandbox, 1)
+	assert.Equal(t, "true", sandbox[0].Property(deadletter.PropertyDryRun))
+	assert.True(t, audit.replays[0].DryRun)
+}
+
+func TestAdminReplayDeadLettersLogic_AdminReplayDeadLetters_Invalid(t *testing.T) {
+	svcCtx, _, audit := newDeadLetterTestContext(t)
+	tooMany := make([]string, maxDeadLetterReplayBatch+1)
+
+	tests := []struct {
+		ctx  context.Context
+		req  *rpc.AdminReplayDeadLettersRequest
+		name string
+	}{
+		{name: "nil request", ctx: adminContext(), req: nil},
+		{name: "no group", ctx: adminContext(), req: &rpc.AdminReplayDeadLettersRequest{MsgIds: []string{"m1"}}},
+		{
+			name: "no messages", ctx: adminContext(),
+			req: &rpc.AdminReplayDeadLettersRequest{ConsumerGroup: deadLetterTestGroup},
+		},
+		{
+			name: "too many messages", ctx: adminContext(),
+			req: &rpc.AdminReplayDeadLettersRequest{ConsumerGroup: deadLetterTestGroup, MsgIds: tooMany},
+		},
+		{
+			name: "unknown target", ctx: adminContext(),
+			req: &rpc.AdminReplayDeadLettersRequest{ConsumerGroup: deadLetterTestGroup, MsgIds: []string{"m1"}, Target: 7},
+		},
+		{
+			name: "no caller", ctx: context.Background(),
+			req: &rpc.AdminReplayDeadLettersRequest{ConsumerGroup: deadLetterTestGroup, MsgIds: []string{"m1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAdminReplayDeadLettersLogic(tt.ctx, svcCtx).AdminReplayDeadLetters(tt.req)
+			assert.Error(t, err)
+		})
+	}
+	assert.Empty(t, audit.replays)
+}
@@ -0,0 +1,172 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+)
+
+// maxOrderSearchRange bounds the creation time range of one AdminSearchOrders call, and so the
+// monthly partitions it reads.
+const maxOrderSearchRange = 92 * 24 * time.Hour
+
+// AdminSearchOrdersLogic searches the orders of every user for admins.
+type AdminSearchOrdersLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewAdminSearchOrdersLogic creates a new AdminSearchOrdersLogic instance.
+func NewAdminSearchOrdersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminSearchOrdersLogic {
+	return &AdminSearchOrdersLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// AdminSearchOrders lists the orders created in a time range, newest first, one page at a time,
+// with their items. Orders are sharded by user, so they are searched in the reporting database the
+// CDC service keeps from the binlog of every shard; it lags the trade databases slightly, and
+// keeps the orders deleted from them.
+func (l *AdminSearchOrdersLogic) AdminSearchOrders(req *rpc.AdminSearchOrdersRequest) (*rpc.AdminSearchOrdersResponse, error) {
+	if req == nil {
+		l.Errorf("received nil AdminSearchOrdersRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	if req.CreatedFrom <= 0 || req.CreatedTo <= req.CreatedFrom {
+		return nil, fmt.Errorf("invalid creation time range: [%d, %d)", req.CreatedFrom, req.CreatedTo)
+	}
+	from, to := time.Unix(req.CreatedFrom, 0), time.Unix(req.CreatedTo, 0)
+	if to.Sub(from) > maxOrderSearchRange {
+		return nil, fmt.Errorf("creation time range too long: at most %d days", int(maxOrderSearchRange.Hours()/24))
+	}
+
+	if req.UserId < 0 {
+		return nil, fmt.Errorf("invalid user_id: %d", req.UserId)
+	}
+
+	q := &database.OrderReportQuery{
+		CreatedFrom:    from,
+		CreatedTo:      to,
+		UserID:         req.UserId,
+		IncludeDeleted: req.IncludeDeleted,
+	}
+	if req.Status != 0 {
+		if req.Status < database.OrderStatusPendingPayment || req.Status > database.OrderStatusRefunded {
+			return nil, fmt.Errorf("invalid status: %d", req.Status)
+		}
+		s := int8(req.Status)
+		q.Status = &s
+	}
+
+	limit := int(req.Limit)
+	if limit < 0 || limit > maxOrderPageSize {
+		return nil, fmt.Errorf("invalid limit: %d, must be between 1 and %d", req.Limit, maxOrderPageSize)
+	}
+	if limit == 0 {
+		limit = defaultOrderPageSize
+	}
+	// Fetch one extra row to learn whether another page exists.
+	q.Limit = limit + 1
+
+	after, err := decodeOrderCursor(req.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if after != nil {
+		q.After = &database.OrderReportCursor{CreateTime: after.CreateTime, ID: after.ID}
+	}
+
+	if l.svcCtx.OrderReports == nil {
+		l.Errorf("reporting database not initialized")
+		return nil, fmt.Errorf("order search not available")
+	}
+
+	orders, err := l.svcCtx.OrderReports.SearchOrders(l.ctx, q)
+	if err != nil {
+		l.Errorf("failed to search orders: %v, createdFrom=%d, createdTo=%d", err, req.CreatedFrom, req.CreatedTo)
+		return nil, fmt.Errorf("failed to search orders: %w", err)
+	}
+
+	resp := &rpc.AdminSearchOrdersResponse{}
+	if len(orders) > limit {
+		orders = orders[:limit]
+		resp.HasMore = true
+	}
+
+	orderIDs := make([]int64, 0, len(orders))
+	for _, order := range orders {
+		orderIDs = append(orderIDs, order.ID)
+	}
+	items, err := l.svcCtx.OrderReports.GetItemsByOrderIDs(l.ctx, orderIDs)
+	if err != nil {
+		l.Errorf("failed to get order items: %v, orders=%d", err, len(orderIDs))
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+	itemsByOrder := make(map[int64][]*rpc.OrderItemInfo, len(orders))
+	for _, item := range items {
+		itemsByOrder[item.OrderID] = append(itemsByOrder[item.OrderID], toReportedOrderItemInfo(item))
+	}
+
+	resp.Orders = make([]*rpc.ReportedOrderInfo, 0, len(orders))
+	for _, order := range orders {
+		resp.Orders = append(resp.Orders, &rpc.ReportedOrderInfo{
+			Order:   toReportedOrderInfo(order),
+			Items:   itemsByOrder[order.ID],
+			Deleted: order.Deleted,
+		})
+	}
+
+	if resp.HasMore {
+		last := orders[len(orders)-1]
+		resp.NextCursor = encodeOrderCursor(&repo.OrderCursor{CreateTime: last.CreateTime, ID: last.ID})
+	}
+
+	return resp, nil
+}
+
+// toReportedOrderInfo converts a reported order to its RPC representation.
+func toReportedOrderInfo(order *database.ReportOrder) *rpc.OrderInfo {
+	info := &rpc.OrderInfo{
+		OrderId:     order.ID,
+		UserId:      order.UserID,
+		Status:      int32(order.Status),
+		TotalAmount: order.TotalAmount,
+		PayAmount:   order.PayAmount,
+		CreateTime:  order.CreateTime.Unix(),
+		UpdateTime:  order.UpdateTime.Unix(),
+	}
+	if order.PayChannel != nil {
+		info.PayChannel = int32(*order.PayChannel)
+	}
+	if order.OutTradeNo != nil {
+		info.OutTradeNo = *order.OutTradeNo
+	}
+	if order.PayTime != nil {
+		info.PayTime = order.PayTime.Unix()
+	}
+	return info
+}
+
+// toReportedOrderItemInfo converts a reported order item to its RPC representation.
+func toReportedOrderItemInfo(item *database.ReportOrderItem) *rpc.OrderItemInfo {
+	return &rpc.OrderItemInfo{
+		ItemId:        item.ID,
+		OrderId:       item.OrderID,
+		CourseId:      item.CourseID,
+		CourseName:    item.CourseName,
+		Price:         item.Price,
+		RealPayAmount: item.RealPayAmount,
+		RefundStatus:  int32(item.RefundStatus),
+	}
+}
@@ -0,0 +1,181 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/config"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+)
+
+// fakeOrderReports searches reported orders in memory like database.OrderReportStore.
+type fakeOrderReports struct {
+	err     error
+	queries []*database.OrderReportQuery
+	orders  []*database.ReportOrder
+	items   []*database.ReportOrderItem
+}
+
+func (f *fakeOrderReports) SearchOrders(_ context.Context, q *database.OrderReportQuery) ([]*database.ReportOrder, error) {
+	f.queries = append(f.queries, q)
+	if f.err != nil {
+		return nil, f.err
+	}
+	var matched []*database.ReportOrder
+	for _, o := range f.orders {
+		switch {
+		case o.CreateTime.Before(q.CreatedFrom) || !o.CreateTime.Before(q.CreatedTo):
+		case q.Status != nil && o.Status != *q.Status:
+		case q.UserID != 0 && o.UserID != q.UserID:
+		case o.Deleted && !q.IncludeDeleted:
+		case q.After != nil && !o.CreateTime.Before(q.After.CreateTime) &&
+			!(o.CreateTime.Equal(q.After.CreateTime) && o.ID < q.After.ID):
+		default:
+			matched = append(matched, o)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].CreateTime.Equal(matched[j].CreateTime) {
+			return matched[i].CreateTime.After(matched[j].CreateTime)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+	if len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	return matched, nil
+}
+
+func (f *fakeOrderReports) GetItemsByOrderIDs(_ context.Context, orderIDs []int64) ([]*database.ReportOrderItem, error) {
+	var items []*database.ReportOrderItem
+	for _, item := range f.items {
+		for _, id := range orderIDs {
+			if item.OrderID == id {
+				items = append(items, item)
+			}
+		}
+	}
+	return items, nil
+}
+
+func newSearchLogic(reports svc.OrderReportRepository) *AdminSearchOrdersLogic {
+	return NewAdminSearchOrdersLogic(context.Background(),
+		&svc.ServiceContext{Config: &config.Config{}, OrderReports: reports})
+}
+
+func TestAdminSearchOrdersLogic_AdminSearchOrders(t *testing.T) {
+	day := time.Date(2023, 11, 11, 0, 0, 0, 0, time.UTC)
+	channel, outTradeNo, paid := int8(1), "ALI1001", day.Add(10*time.Hour)
+	reports := &fakeOrderReports{
+		orders: []*database.ReportOrder{
+			{ID: 1001, UserID: 7, Status: database.OrderStatusPaid, CreateTime: day.Add(9 * time.Hour),
+				PayChannel: &channel, OutTradeNo: &outTradeNo, PayTime: &paid},
+			{ID: 1004, UserID: 9, Status: database.OrderStatusRefunded, CreateTime: day.Add(20 * time.Hour)},
+			{ID: 1002, UserID: 40, Status: database.OrderStatusClosed, CreateTime: day.Add(23 * time.Hour), Deleted: true},
+			{ID: 1003, UserID: 7, Status: database.OrderStatusPendingPayment, CreateTime: day.AddDate(0, 0, 1)},
+		},
+		items: []*database.ReportOrderItem{
+			{ID: 10011, OrderID: 1001, CourseID: 5, CourseName: "Go", Price: 100, RealPayAmount: 90},
+			{ID: 10012, OrderID: 1001, CourseID: 6, CourseName: "SQL", Price: 100, RealPayAmount: 90},
+			{ID: 10041, OrderID: 1004, CourseID: 5, CourseName: "Go", RefundStatus: 2},
+		},
+	}
+	logic := newSearchLogic(reports)
+	req := &rpc.AdminSearchOrdersRequest{CreatedFrom: day.Unix(), CreatedTo: day.AddDate(0, 0, 1).Unix(), Limit: 1}
+
+	var seen []int64
+	for page := 0; page < 5; page++ {
+		resp, err := logic.AdminSearchOrders(req)
+		require.NoError(t, err)
+		for _, o := range resp.Orders {
+			seen = append(seen, o.Order.OrderId)
+		}
+		if !resp.HasMore {
+			assert.Empty(t, resp.NextCursor)
+			break
+		}
+		req.Cursor = resp.NextCursor
+	}
+	assert.Equal(t, []int64{1004, 1001}, seen)
+
+	resp, err := logic.AdminSearchOrders(&rpc.AdminSearchOrdersRequest{
+		CreatedFrom: day.Unix(), CreatedTo: day.AddDate(0, 0, 1).Unix(), UserId: 7,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Orders, 1)
+	order := resp.Orders[0]
+	assert.Equal(t, int32(1), order.Order.PayChannel)
+	assert.Equal(t, "ALI1001", order.Order.OutTradeNo)
+	assert.Equal(t, paid.Unix(), order.Order.PayTime)
+	require.Len(t, order.Items, 2)
+	assert.Equal(t, "SQL", order.Items[1].CourseName)
+	assert.False(t, order.Deleted)
+
+	resp, err = logic.AdminSearchOrders(&rpc.AdminSearchOrdersRequest{
+		CreatedFrom: day.Unix(), CreatedTo: day.AddDate(0, 0, 1).Unix(),
+		Status: database.OrderStatusClosed, IncludeDeleted: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Orders, 1)
+	assert.Equal(t, int64(1002), resp.Orders[0].Order.OrderId)
+	assert.True(t, resp.Orders[0].Deleted)
+	assert.Empty(t, resp.Orders[0].Items)
+
+	// The default page size applies, plus the row that tells whether another page exists.
+	assert.Equal(t, defaultOrderPageSize+1, reports.queries[len(reports.queries)-1].Limit)
+}
+
+func TestAdminSearchOrdersLogic_AdminSearchOrders_Errors(t *testing.T) {
+	from := time.Date(2023, 11, 11, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	tests := []struct {
+		reports svc.OrderReportRepository
+		req     *rpc.AdminSearchOrdersRequest
+		name    string
+		errMsg  string
+	}{
+		{name: "nil request", reports: &fakeOrderReports{}, errMsg: "request cannot be nil"},
+		{name: "missing range", reports: &fakeOrderReports{}, req: &rpc.AdminSearchOrdersRequest{},
+			errMsg: "invalid creation time range"},
+		{name: "reversed range", reports: &fakeOrderReports{},
+			req:    &rpc.AdminSearchOrdersRequest{CreatedFrom: to.Unix(), CreatedTo: from.Unix()},
+			errMsg: "invalid creation time range"},
+		{name: "range too long", reports: &fakeOrderReports{},
+			req:    &rpc.AdminSearchOrdersRequest{CreatedFrom: from.Unix(), CreatedTo: from.AddDate(0, 4, 0).Unix()},
+			errMsg: "range too long"},
+		{name: "invalid status", reports: &fakeOrderReports{},
+			req:    &rpc.AdminSearchOrdersRequest{CreatedFrom: from.Unix(), CreatedTo: to.Unix(), Status: 9},
+			errMsg: "invalid status"},
+		{name: "invalid user", reports: &fakeOrderReports{},
+			req:    &rpc.AdminSearchOrdersRequest{CreatedFrom: from.Unix(), CreatedTo: to.Unix(), UserId: -1},
+			errMsg: "invalid user_id"},
+		{name: "limit too large", reports: &fakeOrderReports{},
+			req:    &rpc.AdminSearchOrdersRequest{CreatedFrom: from.Unix(), CreatedTo: to.Unix(), Limit: 101},
+			errMsg: "invalid limit"},
+		{name: "malformed cursor", reports: &fakeOrderReports{},
+			req:    &rpc.AdminSearchOrdersRequest{CreatedFrom: from.Unix(), CreatedTo: to.Unix(), Cursor: "!!"},
+			errMsg: "invalid cursor"},
+		{name: "no reporting database", reports: nil,
+			req:    &rpc.AdminSearchOrdersRequest{CreatedFrom: from.Unix(), CreatedTo: to.Unix()},
+			errMsg: "order search not available"},
+		{name: "search failure", reports: &fakeOrderReports{err: errors.New("connection refused")},
+			req:    &rpc.AdminSearchOrdersRequest{CreatedFrom: from.Unix(), CreatedTo: to.Unix()},
+			errMsg: "failed to search orders"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := newSearchLogic(tt.reports).AdminSearchOrders(tt.req)
+			assert.Nil(t, resp)
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
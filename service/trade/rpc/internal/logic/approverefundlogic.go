@@ -0,0 +1,211 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/mq"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/ordertx"
+	"github.com/aether-defense-system/service/trade/rpc/internal/payment"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ApproveRefundLogic handles refund approval by admins.
+type ApproveRefundLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewApproveRefundLogic creates a new ApproveRefundLogic instance.
+func NewApproveRefundLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ApproveRefundLogic {
+	return &ApproveRefundLogic{
+		// The status of the refund decides whether it is approved or resumed.
+		ctx:    database.ForcePrimary(ctx),
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// ApproveRefund approves a requested refund and returns the money through the payment gateway.
+//
+// Responsibilities:
+//   - Move the refund from Requested to Approved
+//   - Call the payment gateway, then record Succeeded or Failed
+//   - On success, publish ORDER_REFUNDED through the outbox, so that promotion restores the
+//     inventory of the refunded items and, once the whole order is refunded, returns its coupons
+//
+// A gateway rejection (payment.ErrRefundRejected) is not an RPC error: the refund ends up Failed
+// and its items become refundable again. Other gateway errors, such as timeouts, fail the RPC and
+// leave the refund Approved, since the gateway may have paid it out. Approving a refund that is still Approved resumes it, because an earlier
+// attempt stopped before the gateway result was recorded; the gateway call carries the refund's
+// idempotency key, so the money is returned once however many approvals reach it.
+func (l *ApproveRefundLogic) ApproveRefund(req *rpc.ApproveRefundRequest) (*rpc.ApproveRefundResponse, error) {
+	if req == nil {
+		l.Errorf("received nil ApproveRefundRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	if req.RefundId <= 0 {
+		l.Errorf("invalid refund_id: %d", req.RefundId)
+		return nil, fmt.Errorf("invalid refund_id: %d", req.RefundId)
+	}
+
+	if l.svcCtx.RefundRepo == nil || l.svcCtx.OrderRepo == nil {
+		l.Errorf("refund or order repository not initialized")
+		return nil, fmt.Errorf("refund repository not available")
+	}
+
+	if l.svcCtx.Payment == nil {
+		l.Errorf("payment gateway not initialized")
+		return nil, fmt.Errorf("payment gateway not available")
+	}
+
+	// Admins approve refunds by ID alone; the user of the refund routes everything after this lookup.
+	refund, err := l.svcCtx.RefundRepo.FindByID(l.ctx, req.RefundId)
+	if err != nil {
+		l.Errorf("failed to load refund: %v, refundId=%d", err, req.RefundId)
+		return nil, fmt.Errorf("refund not found: %w", err)
+	}
+
+	switch refund.Status {
+	case database.RefundStatusRequested:
+		err = l.svcCtx.RefundRepo.UpdateStatus(l.ctx, refund.UserID, refund.ID,
+			database.RefundStatusRequested, database.RefundStatusApproved)
+		if err != nil {
+			l.Errorf("failed to approve refund: %v, refundId=%d", err, refund.ID)
+			return nil, fmt.Errorf("failed to approve refund: %w", err)
+		}
+		refund.Status = database.RefundStatusApproved
+	case database.RefundStatusApproved:
+		l.Infof("resuming approved refund: refundId=%d", refund.ID)
+	default:
+		if err = checkRefundTransition(refund.Status, database.RefundStatusApproved); err != nil {
+			l.Errorf("refund cannot be approved: %v, refundId=%d", err, refund.ID)
+			return nil, err
+		}
+	}
+
+	items, err := l.svcCtx.RefundRepo.GetItemsByRefundID(l.ctx, refund.UserID, refund.ID)
+	if err != nil {
+		l.Errorf("failed to load refund items: %v, refundId=%d", err, refund.ID)
+		return nil, fmt.Errorf("failed to load refund items: %w", err)
+	}
+
+	order, err := l.svcCtx.OrderRepo.GetByID(l.ctx, refund.UserID, refund.OrderID)
+	if err != nil {
+		l.Errorf("failed to load order: %v, orderId=%d", err, refund.OrderID)
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+
+	// Every approval of the refund, including concurrent ones, is one refund to the gateway.
+	gatewayReq := &payment.RefundRequest{
+		IdempotencyKey: payment.RefundIdempotencyKey(refund.ID),
+		RefundID:       refund.ID,
+		OrderID:        order.ID,
+		Amount:         refund.Amount,
+		TotalAmount:    order.PayAmount,
+	}
+	if order.OutTradeNo != nil {
+		gatewayReq.OutTradeNo = *order.OutTradeNo
+	}
+	if order.PayChannel != nil {
+		gatewayReq.PayChannel = *order.PayChannel
+	}
+
+	result, gatewayErr := l.svcCtx.Payment.Refund(l.ctx, gatewayReq)
+	if gatewayErr != nil {
+		// Any other failure leaves it unknown whether the money left: the refund stays Approved, so
+		// that approving it again resumes under the same idempotency key.
+		if !errors.Is(gatewayErr, payment.ErrRefundRejected) {
+			l.Errorf("payment gateway refund failed: %v, refundId=%d", gatewayErr, refund.ID)
+			return nil, fmt.Errorf("payment gateway refund failed: %w", gatewayErr)
+		}
+		l.Errorf("payment gateway rejected refund: %v, refundId=%d", gatewayErr, refund.ID)
+
+		failReason := truncateReason(gatewayErr.Error(), maxRefundReasonLength)
+		if err = l.svcCtx.RefundRepo.MarkFailed(l.ctx, refund, failReason); err != nil {
+			l.Errorf("failed to record refund failure: %v, refundId=%d", err, refund.ID)
+			return nil, fmt.Errorf("failed to record refund failure: %w", err)
+		}
+
+		refund.Status = database.RefundStatusFailed
+		refund.FailReason = &failReason
+		return &rpc.ApproveRefundResponse{Refund: toRefundInfo(refund, items)}, nil
+	}
+
+	refund.OutRefundNo = &result.OutRefundNo
+	orderRefunded, err := l.svcCtx.RefundRepo.MarkSucceeded(l.ctx, refund, result.OutRefundNo,
+		l.refundedEvent(refund, items))
+	if err != nil {
+		// A concurrent approval of the same refund got the same gateway result and recorded it first.
+		if recorded, getErr := l.svcCtx.RefundRepo.GetByID(l.ctx, refund.UserID, refund.ID); getErr == nil &&
+			recorded.Status == database.RefundStatusSucceeded {
+			l.Infof("refund already recorded as succeeded: refundId=%d", refund.ID)
+			return &rpc.ApproveRefundResponse{Refund: toRefundInfo(recorded, items)}, nil
+		}
+		// The money has left; approving again resumes and re-records the gateway's deduplicated result.
+		l.Errorf("refund paid out but not recorded: %v, refundId=%d, outRefundNo=%s",
+			err, refund.ID, result.OutRefundNo)
+		return nil, fmt.Errorf("failed to record refund success: %w", err)
+	}
+	refund.Status = database.RefundStatusSucceeded
+
+	l.Infof("refund succeeded: refundId=%d, orderId=%d, amount=%d, outRefundNo=%s, orderRefunded=%t",
+		refund.ID, refund.OrderID, refund.Amount, result.OutRefundNo, orderRefunded)
+
+	return &rpc.ApproveRefundResponse{Refund: toRefundInfo(refund, items)}, nil
+}
+
+// truncateReason cuts reason to at most n bytes without splitting a UTF-8 character.
+func truncateReason(reason string, n int) string {
+	if len(reason) <= n {
+		return reason
+	}
+	for n > 0 && !utf8.RuneStart(reason[n]) {
+		n--
+	}
+	return reason[:n]
+}
+
+// refundedEvent builds the ORDER_REFUNDED outbox event of a refund, which promotion consumes to
+// restore the inventory of the refunded items and, once the whole order is refunded, return its
+// coupons. Partially refunded orders keep their coupons consumed: the discount stays with the
+// items that were kept.
+func (l *ApproveRefundLogic) refundedEvent(
+	refund *database.TradeRefund, items []*database.TradeRefundItem,
+) repo.RefundEventFunc {
+	return func(orderRefunded bool) (*database.OutboxEvent, error) {
+		refunded := &event.OrderRefunded{
+			RefundId:      refund.ID,
+			OrderId:       refund.OrderID,
+			UserId:        refund.UserID,
+			Amount:        refund.Amount,
+			OrderRefunded: orderRefunded,
+		}
+		for _, item := range items {
+			refunded.CourseIds = append(refunded.CourseIds, item.CourseID)
+		}
+
+		orderID := strconv.FormatInt(refund.OrderID, 10)
+		env, err := event.Default.NewEnvelope(event.TypeOrderRefunded, orderID, refunded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build order refunded event: %w", err)
+		}
+		msg, err := event.Default.NewMessage(l.svcCtx.Config.RocketMQ.Topic, env)
+		if err != nil {
+			return nil, err
+		}
+		msg = msg.WithKeys(fmt.Sprintf("refund_%d", refund.ID))
+		return mq.NewOutboxEvent(l.ctx, ordertx.AggregateOrder, orderID, msg)
+	}
+}
@@ -0,0 +1,231 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/mq"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/config"
+	"github.com/aether-defense-system/service/trade/rpc/internal/payment"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+)
+
+// newApproveTestContext returns a service context with a paid order 100 (see newRefundTestStore).
+func newApproveTestContext() (*svc.ServiceContext, *repo.MemoryStore, *payment.FakeGateway) {
+	store := newRefundTestStore()
+	gateway := payment.NewFakeGateway()
+	svcCtx := newRefundTestContext(store)
+	svcCtx.Config.RocketMQ.Topic = "order-topic"
+	svcCtx.Payment = gateway
+	return svcCtx, store, gateway
+}
+
+// refundedEvents returns the ORDER_REFUNDED events written to the outbox of store.
+func refundedEvents(t *testing.T, store *repo.MemoryStore) []*event.OrderRefunded {
+	t.Helper()
+	var refunded []*event.OrderRefunded
+	for _, outboxEvent := range store.OutboxEvents() {
+		msg := mq.NewMessage(outboxEvent.Topic, outboxEvent.Payload).WithTag(outboxEvent.Tag)
+		e, err := event.Default.Parse(msg)
+		require.NoError(t, err)
+		payload, ok := e.Payload.(*event.OrderRefunded)
+		require.True(t, ok, "unexpected event %s", e.Envelope.Type)
+		assert.Equal(t, "order-topic", outboxEvent.Topic)
+		assert.Equal(t, "100", outboxEvent.AggregateID)
+		refunded = append(refunded, payload)
+	}
+	return refunded
+}
+
+func requestTestRefund(t *testing.T, svcCtx *svc.ServiceContext, itemIDs ...int64) int64 {
+	t.Helper()
+	resp, err := NewRequestRefundLogic(context.Background(), svcCtx).
+		RequestRefund(&rpc.RequestRefundRequest{UserId: 1, OrderId: 100, OrderItemIds: itemIDs})
+	if err != nil {
+		t.Fatalf("failed to request refund: %v", err)
+	}
+	return resp.Refund.RefundId
+}
+
+func TestApproveRefundLogic_ApproveRefund_PartialRefund(t *testing.T) {
+	svcCtx, store, _ := newApproveTestContext()
+	logic := NewApproveRefundLogic(context.Background(), svcCtx)
+	refundID := requestTestRefund(t, svcCtx, 101)
+
+	resp, err := logic.ApproveRefund(&rpc.ApproveRefundRequest{RefundId: refundID})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(database.RefundStatusSucceeded), resp.Refund.Status)
+	assert.NotEmpty(t, resp.Refund.OutRefundNo)
+	events := refundedEvents(t, store)
+	require.Len(t, events, 1)
+	assert.Equal(t, refundID, events[0].RefundId)
+	assert.Equal(t, []int64{11}, events[0].CourseIds)
+	assert.Equal(t, int32(4500), events[0].Amount)
+	assert.False(t, events[0].OrderRefunded, "coupons stay consumed on a partial refund")
+
+	order, _ := svcCtx.OrderRepo.GetByID(context.Background(), 1, 100)
+	assert.Equal(t, int8(database.OrderStatusPaid), order.Status)
+
+	_, err = logic.ApproveRefund(&rpc.ApproveRefundRequest{RefundId: refundID})
+	assert.Error(t, err, "a succeeded refund cannot be approved again")
+}
+
+func TestApproveRefundLogic_ApproveRefund_CompletesOrder(t *testing.T) {
+	svcCtx, store, _ := newApproveTestContext()
+	logic := NewApproveRefundLogic(context.Background(), svcCtx)
+
+	_, err := logic.ApproveRefund(&rpc.ApproveRefundRequest{RefundId: requestTestRefund(t, svcCtx, 101)})
+	assert.NoError(t, err)
+	_, err = logic.ApproveRefund(&rpc.ApproveRefundRequest{RefundId: requestTestRefund(t, svcCtx)})
+	assert.NoError(t, err)
+
+	order, _ := svcCtx.OrderRepo.GetByID(context.Background(), 1, 100)
+	assert.Equal(t, int8(database.OrderStatusRefunded), order.Status)
+	events := refundedEvents(t, store)
+	require.Len(t, events, 2)
+	assert.Equal(t, []int64{11}, events[0].CourseIds)
+	assert.False(t, events[0].OrderRefunded)
+	assert.Equal(t, []int64{12, 13}, events[1].CourseIds)
+	assert.True(t, events[1].OrderRefunded, "expected the coupons of the refunded order returned")
+}
+
+func TestApproveRefundLogic_ApproveRefund_GatewayFailure(t *testing.T) {
+	svcCtx, store, gateway := newApproveTestContext()
+	gateway.Err = fmt.Errorf("%w: insufficient merchant balance", payment.ErrRefundRejected)
+	logic := NewApproveRefundLogic(context.Background(), svcCtx)
+	refundID := requestTestRefund(t, svcCtx, 101, 102)
+
+	resp, err := logic.ApproveRefund(&rpc.ApproveRefundRequest{RefundId: refundID})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(database.RefundStatusFailed), resp.Refund.Status)
+	assert.Equal(t, "refund rejected by payment gateway: insufficient merchant balance", resp.Refund.FailReason)
+	assert.Empty(t, refundedEvents(t, store))
+
+	for _, item := range orderItems(t, store, 1, 100) {
+		assert.Equal(t, int8(database.ItemRefundStatusNone), item.RefundStatus, "failed refund releases its items")
+	}
+
+	// The released items can be refunded again.
+	gateway.Err = nil
+	resp, err = logic.ApproveRefund(&rpc.ApproveRefundRequest{RefundId: requestTestRefund(t, svcCtx, 101, 102)})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(database.RefundStatusSucceeded), resp.Refund.Status)
+}
+
+func TestApproveRefundLogic_ApproveRefund_GatewayError(t *testing.T) {
+	svcCtx, store, gateway := newApproveTestContext()
+	gateway.Err = context.DeadlineExceeded
+	logic := NewApproveRefundLogic(context.Background(), svcCtx)
+	refundID := requestTestRefund(t, svcCtx, 101)
+
+	// The gateway may have paid out: the refund stays Approved with its items claimed.
+	_, err := logic.ApproveRefund(&rpc.ApproveRefundRequest{RefundId: refundID})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	refund, err := svcCtx.RefundRepo.GetByID(context.Background(), 1, refundID)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.RefundStatusApproved), refund.Status)
+	assert.Equal(t, int8(database.ItemRefundStatusRefunding), orderItems(t, store, 1, 100)[0].RefundStatus)
+	assert.Empty(t, refundedEvents(t, store))
+
+	// Approving it again resumes it.
+	gateway.Err = nil
+	resp, err := logic.ApproveRefund(&rpc.ApproveRefundRequest{RefundId: refundID})
+	require.NoError(t, err)
+	assert.Equal(t, int32(database.RefundStatusSucceeded), resp.Refund.Status)
+	assert.Equal(t, 1, gateway.Issued())
+}
+
+func TestTruncateReason(t *testing.T) {
+	assert.Equal(t, "rejected", truncateReason("rejected", 8))
+	assert.Equal(t, "rej", truncateReason("rejected", 3))
+	// "退" is three bytes: a cut inside it drops it whole.
+	assert.Equal(t, "a", truncateReason("a退款", 3))
+	assert.Equal(t, "a退", truncateReason("a退款", 4))
+}
+
+func TestApproveRefundLogic_ApproveRefund_ResumesApproved(t *testing.T) {
+	svcCtx, store, gateway := newApproveTestContext()
+	logic := NewApproveRefundLogic(context.Background(), svcCtx)
+	refundID := requestTestRefund(t, svcCtx, 103)
+	err := svcCtx.RefundRepo.UpdateStatus(context.Background(), 1, refundID,
+		database.RefundStatusRequested, database.RefundStatusApproved)
+	assert.NoError(t, err)
+
+	resp, err := logic.ApproveRefund(&rpc.ApproveRefundRequest{RefundId: refundID})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(database.RefundStatusSucceeded), resp.Refund.Status)
+	assert.Equal(t, 1, gateway.Issued())
+	assert.Len(t, refundedEvents(t, store), 1)
+}
+
+func TestApproveRefundLogic_ApproveRefund_Concurrent(t *testing.T) {
+	svcCtx, store, gateway := newApproveTestContext()
+	refundID := requestTestRefund(t, svcCtx, 101, 102)
+	err := svcCtx.RefundRepo.UpdateStatus(context.Background(), 1, refundID,
+		database.RefundStatusRequested, database.RefundStatusApproved)
+	require.NoError(t, err)
+
+	// Approvals racing on the Approved refund all resume it, but the gateway pays out once.
+	const approvals = 5
+	var wg sync.WaitGroup
+	errs := make([]error, approvals)
+	for i := range approvals {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = NewApproveRefundLogic(context.Background(), svcCtx).
+				ApproveRefund(&rpc.ApproveRefundRequest{RefundId: refundID})
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			assert.ErrorContains(t, err, "cannot transition", "expected only approvals after the success to fail")
+		}
+	}
+	assert.Equal(t, 1, gateway.Issued())
+	assert.Len(t, refundedEvents(t, store), 1, "expected the refund announced once")
+	refund, err := svcCtx.RefundRepo.GetByID(context.Background(), 1, refundID)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.RefundStatusSucceeded), refund.Status)
+}
+
+func TestApproveRefundLogic_ApproveRefund_Errors(t *testing.T) {
+	svcCtx, _, _ := newApproveTestContext()
+	logic := NewApproveRefundLogic(context.Background(), svcCtx)
+
+	tests := []struct {
+		req    *rpc.ApproveRefundRequest
+		name   string
+		errMsg string
+	}{
+		{name: "nil request", req: nil, errMsg: "request cannot be nil"},
+		{name: "invalid refund id", req: &rpc.ApproveRefundRequest{RefundId: 0}, errMsg: "invalid refund_id"},
+		{name: "not found", req: &rpc.ApproveRefundRequest{RefundId: 404}, errMsg: "refund not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := logic.ApproveRefund(tt.req)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+			assert.Nil(t, resp)
+		})
+	}
+
+	noGateway := NewApproveRefundLogic(context.Background(), &svc.ServiceContext{
+		Config: &config.Config{}, OrderRepo: svcCtx.OrderRepo, RefundRepo: svcCtx.RefundRepo,
+	})
+	_, err := noGateway.ApproveRefund(&rpc.ApproveRefundRequest{RefundId: 1})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "payment gateway not available")
+}
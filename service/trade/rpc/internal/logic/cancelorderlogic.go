@@ -22,7 +22,8 @@ type CancelOrderLogic struct {
 // NewCancelOrderLogic creates a new CancelOrderLogic instance.
 func NewCancelOrderLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CancelOrderLogic {
 	return &CancelOrderLogic{
-		ctx:    ctx,
+		// The status and version of the order guard its update.
+		ctx:    database.ForcePrimary(ctx),
 		svcCtx: svcCtx,
 		Logger: logx.WithContext(ctx),
 	}
@@ -59,19 +60,13 @@ func (l *CancelOrderLogic) CancelOrder(req *rpc.CancelOrderRequest) (*rpc.Cancel
 		return nil, fmt.Errorf("order repository not available")
 	}
 
-	order, err := l.svcCtx.OrderRepo.GetByID(l.ctx, req.OrderId)
+	// Only orders of the user are found, which verifies ownership.
+	order, err := l.svcCtx.OrderRepo.GetByID(l.ctx, req.UserId, req.OrderId)
 	if err != nil {
-		l.Errorf("failed to load order: %v, orderId=%d", err, req.OrderId)
+		l.Errorf("failed to load order: %v, userId=%d, orderId=%d", err, req.UserId, req.OrderId)
 		return nil, fmt.Errorf("order not found: %w", err)
 	}
 
-	// Verify ownership
-	if order.UserID != req.UserId {
-		l.Errorf("order ownership mismatch: orderUserId=%d, requestUserId=%d, orderId=%d",
-			order.UserID, req.UserId, req.OrderId)
-		return nil, fmt.Errorf("order does not belong to user")
-	}
-
 	// Verify order can be canceled (only pending payment orders can be canceled)
 	if order.Status != database.OrderStatusPendingPayment {
 		l.Errorf("order cannot be canceled: orderId=%d, currentStatus=%d", req.OrderId, order.Status)
@@ -81,6 +76,7 @@ func (l *CancelOrderLogic) CancelOrder(req *rpc.CancelOrderRequest) (*rpc.Cancel
 	// Update order status with optimistic locking
 	err = l.svcCtx.OrderRepo.UpdateStatus(
 		l.ctx,
+		req.UserId,
 		req.OrderId,
 		database.OrderStatusPendingPayment,
 		database.OrderStatusClosed,
@@ -110,7 +110,7 @@ func TestCancelOrderLogic_NewCancelOrderLogic(t *testing.T) {
 
 	logic := NewCancelOrderLogic(ctx, svcCtx)
 	assert.NotNil(t, logic)
-	assert.Equal(t, ctx, logic.ctx)
+	assert.Equal(t, database.ForcePrimary(ctx), logic.ctx, "reads must go to the primary")
 	assert.Equal(t, svcCtx, logic.svcCtx)
 }
 
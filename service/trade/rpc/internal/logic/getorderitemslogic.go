@@ -0,0 +1,64 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GetOrderItemsLogic handles order item queries.
+type GetOrderItemsLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewGetOrderItemsLogic creates a new GetOrderItemsLogic instance.
+func NewGetOrderItemsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetOrderItemsLogic {
+	return &GetOrderItemsLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// GetOrderItems returns the items of one order of the requesting user.
+func (l *GetOrderItemsLogic) GetOrderItems(req *rpc.GetOrderItemsRequest) (*rpc.GetOrderItemsResponse, error) {
+	if req == nil {
+		l.Errorf("received nil GetOrderItemsRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	// Items carry a redundant user_id, but ownership is decided by the order itself.
+	if _, err := loadOwnedOrder(l.ctx, l.svcCtx, l.Logger, req.UserId, req.OrderId); err != nil {
+		return nil, err
+	}
+
+	items, err := l.svcCtx.OrderRepo.GetItemsByOrderID(l.ctx, req.UserId, req.OrderId)
+	if err != nil {
+		l.Errorf("failed to load order items: %v, orderId=%d", err, req.OrderId)
+		return nil, fmt.Errorf("failed to load order items: %w", err)
+	}
+
+	resp := &rpc.GetOrderItemsResponse{
+		OrderId: req.OrderId,
+		Items:   make([]*rpc.OrderItemInfo, 0, len(items)),
+	}
+	for _, item := range items {
+		resp.Items = append(resp.Items, &rpc.OrderItemInfo{
+			ItemId:        item.ID,
+			OrderId:       item.OrderID,
+			CourseId:      item.CourseID,
+			CourseName:    item.CourseName,
+			Price:         item.Price,
+			RealPayAmount: item.RealPayAmount,
+			RefundStatus:  int32(item.RefundStatus),
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,40 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/config"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+)
+
+func TestGetOrderItemsLogic_GetOrderItems(t *testing.T) {
+	store := repo.NewMemoryStore()
+	store.Put(&database.TradeOrder{ID: 100, UserID: 1, Status: database.OrderStatusPaid},
+		&database.TradeOrderItem{ID: 1001, OrderID: 100, UserID: 1, CourseID: 10, CourseName: "Go", Price: 6000, RealPayAmount: 5400},
+		&database.TradeOrderItem{ID: 1002, OrderID: 100, UserID: 1, CourseID: 11, CourseName: "SQL", Price: 4000, RealPayAmount: 3600},
+	)
+	svcCtx := &svc.ServiceContext{Config: &config.Config{}, OrderRepo: store.OrderRepo()}
+	logic := NewGetOrderItemsLogic(context.Background(), svcCtx)
+
+	resp, err := logic.GetOrderItems(&rpc.GetOrderItemsRequest{UserId: 1, OrderId: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), resp.OrderId)
+	assert.Len(t, resp.Items, 2)
+	assert.Equal(t, "Go", resp.Items[0].CourseName)
+	assert.Equal(t, int32(5400), resp.Items[0].RealPayAmount)
+
+	// Items of another user's order must not leak: the order is not found for that user.
+	resp, err = logic.GetOrderItems(&rpc.GetOrderItemsRequest{UserId: 2, OrderId: 100})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "order not found")
+	assert.Nil(t, resp)
+
+	_, err = logic.GetOrderItems(nil)
+	assert.Error(t, err)
+}
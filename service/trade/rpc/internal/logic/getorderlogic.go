@@ -0,0 +1,95 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// GetOrderLogic handles single order queries.
+type GetOrderLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewGetOrderLogic creates a new GetOrderLogic instance.
+func NewGetOrderLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetOrderLogic {
+	return &GetOrderLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// GetOrder returns one order of the requesting user.
+func (l *GetOrderLogic) GetOrder(req *rpc.GetOrderRequest) (*rpc.GetOrderResponse, error) {
+	if req == nil {
+		l.Errorf("received nil GetOrderRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	order, err := loadOwnedOrder(l.ctx, l.svcCtx, l.Logger, req.UserId, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rpc.GetOrderResponse{Order: toOrderInfo(order)}, nil
+}
+
+// loadOwnedOrder validates the IDs and loads the order of the user. Orders of other users are not
+// found, the same way CancelOrder does not find them.
+func loadOwnedOrder(
+	ctx context.Context, svcCtx *svc.ServiceContext, logger logx.Logger, userID, orderID int64,
+) (*database.TradeOrder, error) {
+	if userID <= 0 {
+		logger.Errorf("invalid user_id: %d", userID)
+		return nil, fmt.Errorf("invalid user_id: %d", userID)
+	}
+
+	if orderID <= 0 {
+		logger.Errorf("invalid order_id: %d", orderID)
+		return nil, fmt.Errorf("invalid order_id: %d", orderID)
+	}
+
+	if svcCtx.OrderRepo == nil {
+		logger.Errorf("order repository not initialized")
+		return nil, fmt.Errorf("order repository not available")
+	}
+
+	order, err := svcCtx.OrderRepo.GetByID(ctx, userID, orderID)
+	if err != nil {
+		logger.Errorf("failed to load order: %v, userId=%d, orderId=%d", err, userID, orderID)
+		return nil, fmt.Errorf("order not found: %w", err)
+	}
+
+	return order, nil
+}
+
+// toOrderInfo converts an order row to its RPC representation.
+func toOrderInfo(order *database.TradeOrder) *rpc.OrderInfo {
+	info := &rpc.OrderInfo{
+		OrderId:     order.ID,
+		UserId:      order.UserID,
+		Status:      int32(order.Status),
+		TotalAmount: order.TotalAmount,
+		PayAmount:   order.PayAmount,
+		CreateTime:  order.CreateTime.Unix(),
+		UpdateTime:  order.UpdateTime.Unix(),
+	}
+	if order.PayChannel != nil {
+		info.PayChannel = int32(*order.PayChannel)
+	}
+	if order.OutTradeNo != nil {
+		info.OutTradeNo = *order.OutTradeNo
+	}
+	if order.PayTime != nil {
+		info.PayTime = order.PayTime.Unix()
+	}
+	return info
+}
@@ -0,0 +1,101 @@
+package logic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/config"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+)
+
+// newTestStore returns an in-memory trade store holding orders without items.
+func newTestStore(orders ...*database.TradeOrder) *repo.MemoryStore {
+	store := repo.NewMemoryStore()
+	for _, order := range orders {
+		store.Put(order)
+	}
+	return store
+}
+
+// orderItems returns the items of an order of a user in store.
+func orderItems(t *testing.T, store *repo.MemoryStore, userID, orderID int64) []*database.TradeOrderItem {
+	t.Helper()
+	items, err := store.OrderRepo().GetItemsByOrderID(context.Background(), userID, orderID)
+	require.NoError(t, err)
+	return items
+}
+
+// hasOrder reports whether store holds an order of a user.
+func hasOrder(store *repo.MemoryStore, userID, orderID int64) bool {
+	_, err := store.OrderRepo().GetByID(context.Background(), userID, orderID)
+	return err == nil
+}
+
+// failingOrderRepo fails reads with err, as when the database is down.
+type failingOrderRepo struct {
+	svc.OrderRepository
+	err error
+}
+
+func (f *failingOrderRepo) GetByID(context.Context, int64, int64) (*database.TradeOrder, error) {
+	return nil, f.err
+}
+
+func (f *failingOrderRepo) ListByUserID(
+	context.Context, int64, *int8, *repo.OrderCursor, int,
+) ([]*database.TradeOrder, error) {
+	return nil, f.err
+}
+
+func TestGetOrderLogic_GetOrder(t *testing.T) {
+	channel := int8(database.PayChannelAlipay)
+	payTime := time.Unix(1700000100, 0)
+	store := newTestStore(&database.TradeOrder{
+		ID: 100, UserID: 1, Status: database.OrderStatusPaid, TotalAmount: 10000, PayAmount: 9000,
+		PayChannel: &channel, PayTime: &payTime, CreateTime: time.Unix(1700000000, 0),
+	})
+	svcCtx := &svc.ServiceContext{Config: &config.Config{}, OrderRepo: store.OrderRepo()}
+	logic := NewGetOrderLogic(context.Background(), svcCtx)
+
+	resp, err := logic.GetOrder(&rpc.GetOrderRequest{UserId: 1, OrderId: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), resp.Order.OrderId)
+	assert.Equal(t, int32(database.OrderStatusPaid), resp.Order.Status)
+	assert.Equal(t, int32(database.PayChannelAlipay), resp.Order.PayChannel)
+	assert.Equal(t, payTime.Unix(), resp.Order.PayTime)
+
+	tests := []struct {
+		req    *rpc.GetOrderRequest
+		name   string
+		errMsg string
+	}{
+		{name: "nil request", req: nil, errMsg: "request cannot be nil"},
+		{name: "invalid user id", req: &rpc.GetOrderRequest{UserId: 0, OrderId: 100}, errMsg: "invalid user_id"},
+		{name: "invalid order id", req: &rpc.GetOrderRequest{UserId: 1, OrderId: 0}, errMsg: "invalid order_id"},
+		{name: "not found", req: &rpc.GetOrderRequest{UserId: 1, OrderId: 404}, errMsg: "order not found"},
+		{name: "other user's order", req: &rpc.GetOrderRequest{UserId: 2, OrderId: 100}, errMsg: "order not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := logic.GetOrder(tt.req)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+			assert.Nil(t, resp)
+		})
+	}
+}
+
+func TestGetOrderLogic_GetOrder_OrderRepoNotInitialized(t *testing.T) {
+	logic := NewGetOrderLogic(context.Background(), &svc.ServiceContext{Config: &config.Config{}})
+
+	_, err := logic.GetOrder(&rpc.GetOrderRequest{UserId: 1, OrderId: 100})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "order repository not available")
+}
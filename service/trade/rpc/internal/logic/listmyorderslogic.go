@@ -0,0 +1,144 @@
+package logic
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	defaultOrderPageSize = 20
+	maxOrderPageSize     = 100
+)
+
+// ListMyOrdersLogic handles the "my orders" listing.
+type ListMyOrdersLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewListMyOrdersLogic creates a new ListMyOrdersLogic instance.
+func NewListMyOrdersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListMyOrdersLogic {
+	return &ListMyOrdersLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// ListMyOrders lists the requesting user's orders, newest first, one page at a time.
+//
+// Pages are keyed on (create_time, id) rather than offsets, so deep pages cost the same
+// as the first one and concurrent inserts do not shift results between pages.
+func (l *ListMyOrdersLogic) ListMyOrders(req *rpc.ListMyOrdersRequest) (*rpc.ListMyOrdersResponse, error) {
+	if req == nil {
+		l.Errorf("received nil ListMyOrdersRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	if req.UserId <= 0 {
+		l.Errorf("invalid user_id: %d", req.UserId)
+		return nil, fmt.Errorf("invalid user_id: %d", req.UserId)
+	}
+
+	var status *int8
+	if req.Status != 0 {
+		if req.Status < database.OrderStatusPendingPayment || req.Status > database.OrderStatusRefunded {
+			l.Errorf("invalid status filter: %d, userId=%d", req.Status, req.UserId)
+			return nil, fmt.Errorf("invalid status: %d", req.Status)
+		}
+		s := int8(req.Status)
+		status = &s
+	}
+
+	limit := int(req.Limit)
+	if limit < 0 || limit > maxOrderPageSize {
+		l.Errorf("invalid limit: %d, userId=%d", req.Limit, req.UserId)
+		return nil, fmt.Errorf("invalid limit: %d, must be between 1 and %d", req.Limit, maxOrderPageSize)
+	}
+	if limit == 0 {
+		limit = defaultOrderPageSize
+	}
+
+	after, err := decodeOrderCursor(req.Cursor)
+	if err != nil {
+		l.Errorf("invalid cursor: %v, userId=%d", err, req.UserId)
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	if l.svcCtx.OrderRepo == nil {
+		l.Errorf("order repository not initialized")
+		return nil, fmt.Errorf("order repository not available")
+	}
+
+	// Fetch one extra row to learn whether another page exists.
+	orders, err := l.svcCtx.OrderRepo.ListByUserID(l.ctx, req.UserId, status, after, limit+1)
+	if err != nil {
+		l.Errorf("failed to list orders: %v, userId=%d", err, req.UserId)
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	resp := &rpc.ListMyOrdersResponse{}
+	if len(orders) > limit {
+		orders = orders[:limit]
+		resp.HasMore = true
+	}
+
+	resp.Orders = make([]*rpc.OrderInfo, 0, len(orders))
+	for _, order := range orders {
+		resp.Orders = append(resp.Orders, toOrderInfo(order))
+	}
+
+	if resp.HasMore {
+		last := orders[len(orders)-1]
+		resp.NextCursor = encodeOrderCursor(&repo.OrderCursor{CreateTime: last.CreateTime, ID: last.ID})
+	}
+
+	return resp, nil
+}
+
+// encodeOrderCursor produces an opaque cursor token. Clients must pass it back unchanged.
+func encodeOrderCursor(c *repo.OrderCursor) string {
+	raw := strconv.FormatInt(c.CreateTime.UnixNano(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeOrderCursor parses a cursor token; an empty token means the first page.
+func decodeOrderCursor(token string) (*repo.OrderCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	return &repo.OrderCursor{CreateTime: time.Unix(0, nanos), ID: id}, nil
+}
@@ -0,0 +1,111 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/config"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+)
+
+func TestListMyOrdersLogic_ListMyOrders_Pagination(t *testing.T) {
+	base := time.Unix(1700000000, 0)
+	store := repo.NewMemoryStore()
+	// Five orders for user 1; orders 3 and 4 share a create_time to exercise the id tie-breaker.
+	for i, created := range []time.Time{base, base.Add(time.Second), base.Add(2 * time.Second),
+		base.Add(2 * time.Second), base.Add(3 * time.Second)} {
+		id := int64(i + 1)
+		store.Put(&database.TradeOrder{ID: id, UserID: 1, Status: database.OrderStatusPaid, CreateTime: created})
+	}
+	store.Put(&database.TradeOrder{ID: 99, UserID: 2, Status: database.OrderStatusPaid, CreateTime: base})
+
+	logic := NewListMyOrdersLogic(context.Background(),
+		&svc.ServiceContext{Config: &config.Config{}, OrderRepo: store.OrderRepo()})
+
+	var seen []int64
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		resp, err := logic.ListMyOrders(&rpc.ListMyOrdersRequest{UserId: 1, Cursor: cursor, Limit: 2})
+		assert.NoError(t, err)
+		for _, o := range resp.Orders {
+			seen = append(seen, o.OrderId)
+		}
+		if !resp.HasMore {
+			assert.Empty(t, resp.NextCursor)
+			break
+		}
+		cursor = resp.NextCursor
+	}
+
+	assert.Equal(t, []int64{5, 4, 3, 2, 1}, seen)
+}
+
+func TestListMyOrdersLogic_ListMyOrders_StatusFilter(t *testing.T) {
+	store := newTestStore(
+		&database.TradeOrder{ID: 1, UserID: 1, Status: database.OrderStatusPaid, CreateTime: time.Unix(1, 0)},
+		&database.TradeOrder{ID: 2, UserID: 1, Status: database.OrderStatusClosed, CreateTime: time.Unix(2, 0)},
+	)
+	logic := NewListMyOrdersLogic(context.Background(),
+		&svc.ServiceContext{Config: &config.Config{}, OrderRepo: store.OrderRepo()})
+
+	resp, err := logic.ListMyOrders(&rpc.ListMyOrdersRequest{UserId: 1, Status: database.OrderStatusClosed})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Orders, 1)
+	assert.Equal(t, int64(2), resp.Orders[0].OrderId)
+	assert.False(t, resp.HasMore)
+}
+
+func TestListMyOrdersLogic_ListMyOrders_ValidationErrors(t *testing.T) {
+	logic := NewListMyOrdersLogic(context.Background(),
+		&svc.ServiceContext{Config: &config.Config{}, OrderRepo: repo.NewMemoryStore().OrderRepo()})
+
+	tests := []struct {
+		req    *rpc.ListMyOrdersRequest
+		name   string
+		errMsg string
+	}{
+		{name: "nil request", req: nil, errMsg: "request cannot be nil"},
+		{name: "invalid user id", req: &rpc.ListMyOrdersRequest{UserId: 0}, errMsg: "invalid user_id"},
+		{name: "invalid status", req: &rpc.ListMyOrdersRequest{UserId: 1, Status: 9}, errMsg: "invalid status"},
+		{name: "limit too large", req: &rpc.ListMyOrdersRequest{UserId: 1, Limit: 101}, errMsg: "invalid limit"},
+		{name: "negative limit", req: &rpc.ListMyOrdersRequest{UserId: 1, Limit: -1}, errMsg: "invalid limit"},
+		{name: "malformed cursor", req: &rpc.ListMyOrdersRequest{UserId: 1, Cursor: "!!"}, errMsg: "invalid cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := logic.ListMyOrders(tt.req)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+			assert.Nil(t, resp)
+		})
+	}
+}
+
+func TestListMyOrdersLogic_ListMyOrders_RepoError(t *testing.T) {
+	orders := &failingOrderRepo{OrderRepository: repo.NewMemoryStore().OrderRepo(), err: fmt.Errorf("db down")}
+	logic := NewListMyOrdersLogic(context.Background(), &svc.ServiceContext{Config: &config.Config{}, OrderRepo: orders})
+
+	_, err := logic.ListMyOrders(&rpc.ListMyOrdersRequest{UserId: 1})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list orders")
+}
+
+func TestOrderCursor_RoundTrip(t *testing.T) {
+	want := &repo.OrderCursor{CreateTime: time.Unix(1700000000, 123), ID: 42}
+
+	got, err := decodeOrderCursor(encodeOrderCursor(want))
+	assert.NoError(t, err)
+	assert.True(t, want.CreateTime.Equal(got.CreateTime))
+	assert.Equal(t, want.ID, got.ID)
+
+	got, err = decodeOrderCursor("")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+}
@@ -3,16 +3,21 @@ package logic
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
-	"github.com/apache/rocketmq-client-go/v2/primitive"
 	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/event"
 	"github.com/aether-defense-system/common/mq"
+	"github.com/aether-defense-system/common/snowflake"
 	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/ordertx"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
 	tradesvc "github.com/aether-defense-system/service/trade/rpc/internal/svc"
 	userservice "github.com/aether-defense-system/service/user/rpc/userservice"
 )
@@ -27,52 +32,57 @@ type PlaceOrderLogic struct {
 // NewPlaceOrderLogic creates a new PlaceOrderLogic instance.
 func NewPlaceOrderLogic(ctx context.Context, svcCtx *tradesvc.ServiceContext) *PlaceOrderLogic {
 	return &PlaceOrderLogic{
-		ctx:    ctx,
+		// A replayed request must find the order an earlier attempt just created.
+		ctx:    database.ForcePrimary(ctx),
 		svcCtx: svcCtx,
 		Logger: logx.WithContext(ctx),
 	}
 }
 
-// OrderMessage represents the message sent to RocketMQ for inventory deduction.
-type OrderMessage struct {
-	CourseIDs  []int64 `json:"courseIds"`
-	OrderID    int64   `json:"orderId"`
-	UserID     int64   `json:"userId"`
-	RealAmount int32   `json:"realAmount"`
-}
+// placeOrderPollInterval is how often PlaceOrder re-checks an order whose creation outcome is unknown.
+const placeOrderPollInterval = 100 * time.Millisecond
 
 // PlaceOrder places an order.
 //
 // This method implements the complete order placement flow:
 //   - Validates user exists
-//   - Creates order in database
-//   - Sends RocketMQ transactional message for inventory deduction
-//   - Returns order response
+//   - Sends a transactional message for inventory deduction; the local transaction
+//     creates the order in the database
+//   - Waits, bounded by PlaceOrder.ConfirmTimeout, until the local transaction outcome is known
+//   - Returns the outcome: committed, rolled back (with the reason) or pending
+//
+// A pending order is resolved by broker check-back, which commits the message if the order exists.
+// Callers poll GetOrder with the same order ID. Placing an order that already exists returns it as
+// committed without sending another message, so retries with the same order ID are safe.
+//
+// Invalid requests fail with InvalidArgument: no order was created. Any other error leaves the
+// outcome to be checked with GetOrder. Orders are looked up among those of the user, so an order ID
+// held by another user is rolled back by the local transaction rather than reported as existing.
 func (l *PlaceOrderLogic) PlaceOrder(req *rpc.PlaceOrderRequest) (*rpc.PlaceOrderResponse, error) {
 	// Parameter validation (business rules)
 	if req == nil {
 		l.Errorf("received nil PlaceOrderRequest")
-		return nil, fmt.Errorf("request cannot be nil")
+		return nil, status.Error(codes.InvalidArgument, "request cannot be nil")
 	}
 
 	if req.UserId <= 0 {
 		l.Errorf("invalid user_id: %d", req.UserId)
-		return nil, fmt.Errorf("invalid user_id: %d", req.UserId)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user_id: %d", req.UserId)
 	}
 
 	if req.OrderId <= 0 {
 		l.Errorf("invalid order_id: %d", req.OrderId)
-		return nil, fmt.Errorf("invalid order_id: %d", req.OrderId)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid order_id: %d", req.OrderId)
 	}
 
 	if len(req.CourseIds) == 0 {
 		l.Errorf("empty course_ids for user_id: %d", req.UserId)
-		return nil, fmt.Errorf("course_ids cannot be empty")
+		return nil, status.Error(codes.InvalidArgument, "course_ids cannot be empty")
 	}
 
 	if req.RealAmount <= 0 {
 		l.Errorf("invalid real_amount: %d for order_id: %d", req.RealAmount, req.OrderId)
-		return nil, fmt.Errorf("real_amount must be greater than 0")
+		return nil, status.Error(codes.InvalidArgument, "real_amount must be greater than 0")
 	}
 
 	// Validate user exists (mandatory)
@@ -88,148 +98,144 @@ func (l *PlaceOrderLogic) PlaceOrder(req *rpc.PlaceOrderRequest) (*rpc.PlaceOrde
 	}
 	l.Infof("user validated: userId=%d", req.UserId)
 
-	// Create RocketMQ transaction producer if not already created
-	// Note: The producer is reused across transactions, so the local executor
-	// must reconstruct order data from the message
-	if l.svcCtx.RocketMQ == nil {
-		// Local transaction executor: creates order in database
-		localExecutor := func(ctx context.Context, msg *primitive.MessageExt) (mq.LocalTransactionState, error) {
-			// Parse message to get order info
-			var orderMsg OrderMessage
-			if parseErr := json.Unmarshal(msg.Body, &orderMsg); parseErr != nil {
-				l.Errorf("failed to parse order message: %v", parseErr)
-				return mq.RollbackMessageState, parseErr
-			}
-
-			// Reconstruct order and items from message
-			// In production, you might want to include more details in the message
-			// or fetch course prices from a service
-			order := &database.TradeOrder{
-				ID:          orderMsg.OrderID,
-				UserID:      orderMsg.UserID,
-				Status:      database.OrderStatusPendingPayment,
-				TotalAmount: orderMsg.RealAmount,
-				PayAmount:   orderMsg.RealAmount,
-				CreateTime:  time.Now(),
-				UpdateTime:  time.Now(),
-				Version:     1,
-			}
-
-			courseCount := len(orderMsg.CourseIDs)
-			if courseCount == 0 {
-				l.Errorf("empty course list in order message: orderId=%d", orderMsg.OrderID)
-				return mq.RollbackMessageState, fmt.Errorf("course list cannot be empty")
-			}
-			if courseCount > 2147483647 { // Max int32 value
-				l.Errorf("course count exceeds int32 limit: orderId=%d, count=%d", orderMsg.OrderID, courseCount)
-				return mq.RollbackMessageState, fmt.Errorf("course count too large")
-			}
-			courseCount32 := int32(courseCount)
-			orderItems := make([]*database.TradeOrderItem, 0, courseCount)
-			for i, courseID := range orderMsg.CourseIDs {
-				pricePerCourse := orderMsg.RealAmount / courseCount32
-				if i == courseCount-1 {
-					pricePerCourse = orderMsg.RealAmount - (pricePerCourse * (courseCount32 - 1))
-				}
-				item := &database.TradeOrderItem{
-					ID:            orderMsg.OrderID + int64(i+1),
-					OrderID:       orderMsg.OrderID,
-					UserID:        orderMsg.UserID,
-					CourseID:      courseID,
-					CourseName:    fmt.Sprintf("Course %d", courseID),
-					Price:         pricePerCourse,
-					RealPayAmount: pricePerCourse,
-					CreateTime:    time.Now(),
-					UpdateTime:    time.Now(),
-				}
-				orderItems = append(orderItems, item)
-			}
-
-			// Create order in database
-			if l.svcCtx.OrderRepo == nil {
-				l.Errorf("order repository not initialized")
-				return mq.RollbackMessageState, fmt.Errorf("order repository not available")
-			}
-
-			if createErr := l.svcCtx.OrderRepo.CreateOrder(ctx, order, orderItems); createErr != nil {
-				l.Errorf("failed to create order in local transaction: %v, orderId=%d", createErr, orderMsg.OrderID)
-				return mq.RollbackMessageState, createErr
-			}
-
-			l.Infof("order created successfully in local transaction: orderId=%d", orderMsg.OrderID)
-			return mq.CommitMessageState, nil
-		}
+	if l.svcCtx.OrderRepo == nil {
+		l.Errorf("order repository not initialized")
+		return nil, fmt.Errorf("order repository not available")
+	}
 
-		// Check-back executor: verifies if order exists in database
-		checkBack := func(ctx context.Context, msg *primitive.MessageExt) (mq.LocalTransactionState, error) {
-			var orderMsg OrderMessage
-			if parseErr := json.Unmarshal(msg.Body, &orderMsg); parseErr != nil {
-				l.Errorf("failed to parse order message in check-back: %v", parseErr)
-				return mq.RollbackMessageState, parseErr
-			}
-
-			if l.svcCtx.OrderRepo == nil {
-				return mq.RollbackMessageState, fmt.Errorf("order repository not available")
-			}
-
-			// Check if order exists
-			order, getErr := l.svcCtx.OrderRepo.GetByID(ctx, orderMsg.OrderID)
-			if getErr != nil {
-				l.Infof("order not found in check-back: orderId=%d, error=%v", orderMsg.OrderID, getErr)
-				return mq.RollbackMessageState, nil // Order doesn't exist, rollback
-			}
-
-			// Order exists, commit
-			l.Infof("order found in check-back: orderId=%d, status=%d", orderMsg.OrderID, order.Status)
-			return mq.CommitMessageState, nil
-		}
+	// A retry of an order that was already created must not send a second order message.
+	existing, err := l.findOrder(l.ctx, req.UserId, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		l.Infof("order already exists: orderId=%d, status=%d", existing.ID, existing.Status)
+		return &rpc.PlaceOrderResponse{
+			OrderId:   existing.ID,
+			PayAmount: existing.PayAmount,
+			Status:    int32(existing.Status),
+			Outcome:   rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED,
+			Reason:    "order already exists",
+		}, nil
+	}
 
-		producer, producerErr := mq.NewTransactionProducer(&l.svcCtx.Config.RocketMQ, localExecutor, checkBack)
-		if producerErr != nil {
-			l.Errorf("failed to create RocketMQ transaction producer: %v", producerErr)
-			return nil, fmt.Errorf("failed to initialize message queue: %w", producerErr)
-		}
-		l.svcCtx.RocketMQ = producer
+	// The producer is created with the service context; without it no order can be created.
+	if l.svcCtx.OrderProducer == nil {
+		l.Errorf("order transaction producer not initialized")
+		return nil, fmt.Errorf("message queue not available")
 	}
 
-	// Prepare message for RocketMQ
-	orderMsg := OrderMessage{
-		OrderID:    req.OrderId,
-		UserID:     req.UserId,
-		CourseIDs:  req.CourseIds,
-		RealAmount: req.RealAmount,
+	// Item IDs travel in the message, so every delivery of it creates the same rows.
+	itemIDs, err := snowflake.NextN(len(req.CourseIds))
+	if err != nil {
+		l.Errorf("failed to generate order item IDs: %v, orderId=%d", err, req.OrderId)
+		return nil, fmt.Errorf("failed to generate order item IDs: %w", err)
 	}
-	msgBody, err := json.Marshal(orderMsg)
+
+	// Prepare the order message
+	msg, err := ordertx.NewOrderPlacedMessage(l.svcCtx.Config.RocketMQ.Topic, &event.OrderPlaced{
+		OrderId:    req.OrderId,
+		UserId:     req.UserId,
+		CourseIds:  req.CourseIds,
+		RealAmount: req.RealAmount,
+		ItemIds:    itemIDs,
+	})
 	if err != nil {
-		l.Errorf("failed to marshal order message: %v", err)
+		l.Errorf("failed to prepare order message: %v", err)
 		return nil, fmt.Errorf("failed to prepare message: %w", err)
 	}
 
-	// Create RocketMQ message
-	msg := primitive.NewMessage(l.svcCtx.Config.RocketMQ.Topic, msgBody)
-	msg.WithKeys([]string{fmt.Sprintf("order_%d", req.OrderId)})
-	msg.WithTag("ORDER_PLACED")
+	// One deadline bounds the whole call: sending the message and waiting for an unknown outcome.
+	ctx, cancel := context.WithTimeout(l.ctx, l.svcCtx.Config.PlaceOrder.GetConfirmTimeout())
+	defer cancel()
 
 	// Send transactional message
-	// This will trigger the local transaction executor which creates the order
-	result, err := l.svcCtx.RocketMQ.SendMessageInTransaction(l.ctx, msg)
+	// This runs the local transaction (ordertx.Executor), which creates the order, before returning
+	result, err := l.svcCtx.OrderProducer.SendMessageInTransaction(ctx, msg)
 	if err != nil {
+		if ctx.Err() != nil {
+			// The half message may have reached the broker; check-back decides its fate.
+			l.Errorf("timed out sending transactional message: %v, orderId=%d", err, req.OrderId)
+			return l.awaitOrder(ctx, req, fmt.Sprintf("timed out waiting for the message broker: %v", err))
+		}
 		l.Errorf("failed to send transactional message: %v, orderId=%d", err, req.OrderId)
 		return nil, fmt.Errorf("failed to send order message: %w", err)
 	}
 
-	l.Infof("transactional message sent: orderId=%d, msgId=%s, status=%s",
-		req.OrderId, result.MsgID, result.Status)
+	l.Infof("transactional message sent: orderId=%d, msgId=%s, state=%d",
+		req.OrderId, result.MsgID, result.State)
+
+	switch result.State {
+	case mq.CommitMessageState:
+		return &rpc.PlaceOrderResponse{
+			OrderId:   req.OrderId,
+			PayAmount: req.RealAmount,
+			Status:    database.OrderStatusPendingPayment,
+			Outcome:   rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED,
+		}, nil
+	case mq.RollbackMessageState:
+		reason := "order message was rolled back"
+		if result.LocalErr != nil {
+			reason = result.LocalErr.Error()
+		}
+		l.Infof("order rolled back: orderId=%d, reason=%s", req.OrderId, reason)
+		return &rpc.PlaceOrderResponse{
+			OrderId: req.OrderId,
+			Outcome: rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_ROLLED_BACK,
+			Reason:  reason,
+		}, nil
+	default:
+		reason := "order creation outcome unknown"
+		if result.LocalErr != nil {
+			reason = fmt.Sprintf("%s: %v", reason, result.LocalErr)
+		}
+		return l.awaitOrder(ctx, req, reason)
+	}
+}
 
-	// Note: The order is created in the local transaction executor.
-	// If the message is committed, the order exists. If rolled back, it doesn't.
-	// We return success here, but the actual order creation happens asynchronously.
-	// In a production system, you might want to wait for the transaction to complete
-	// or use a different pattern.
+// awaitOrder waits, until the deadline of ctx, for an order whose creation outcome is unknown to
+// appear in the database. It reports the order committed once it exists and pending otherwise.
+// ctx carries the deadline of the whole PlaceOrder call, so the wait only gets what the send left.
+func (l *PlaceOrderLogic) awaitOrder(
+	ctx context.Context, req *rpc.PlaceOrderRequest, reason string,
+) (*rpc.PlaceOrderResponse, error) {
+	ticker := time.NewTicker(placeOrderPollInterval)
+	defer ticker.Stop()
+
+	for {
+		order, err := l.findOrder(ctx, req.UserId, req.OrderId)
+		if err == nil && order != nil {
+			l.Infof("order confirmed after unknown outcome: orderId=%d", req.OrderId)
+			return &rpc.PlaceOrderResponse{
+				OrderId:   order.ID,
+				PayAmount: order.PayAmount,
+				Status:    int32(order.Status),
+				Outcome:   rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED,
+			}, nil
+		}
 
-	return &rpc.PlaceOrderResponse{
-		OrderId:   req.OrderId,
-		PayAmount: req.RealAmount,
-		Status:    database.OrderStatusPendingPayment,
-	}, nil
+		select {
+		case <-ctx.Done():
+			l.Infof("order pending: orderId=%d, reason=%s", req.OrderId, reason)
+			return &rpc.PlaceOrderResponse{
+				OrderId: req.OrderId,
+				Outcome: rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_PENDING,
+				Reason:  reason + "; awaiting transaction check-back",
+			}, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// findOrder returns the order of the user, or nil if the user has no such order.
+func (l *PlaceOrderLogic) findOrder(ctx context.Context, userID, orderID int64) (*database.TradeOrder, error) {
+	order, err := l.svcCtx.OrderRepo.GetByID(ctx, userID, orderID)
+	if err != nil {
+		if errors.Is(err, repo.ErrOrderNotFound) {
+			return nil, nil
+		}
+		l.Errorf("failed to look up order: %v, orderId=%d", err, orderID)
+		return nil, fmt.Errorf("failed to look up order: %w", err)
+	}
+	return order, nil
 }
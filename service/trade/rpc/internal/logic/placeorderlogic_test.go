@@ -2,21 +2,35 @@ package logic
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/event"
 	"github.com/aether-defense-system/common/mq"
+	promotionconsumer "github.com/aether-defense-system/service/promotion/rpc/consumer"
+	promotionsvc "github.com/aether-defense-system/service/promotion/rpc/svc"
 	"github.com/aether-defense-system/service/trade/rpc"
 	"github.com/aether-defense-system/service/trade/rpc/internal/config"
+	"github.com/aether-defense-system/service/trade/rpc/internal/ordertx"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
 	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
 	userservice "github.com/aether-defense-system/service/user/rpc/userservice"
 )
 
 // mockUserService mocks the UserService interface.
+// Only GetUser is used by trade logic; other methods come from the embedded interface.
 type mockUserService struct {
+	userservice.UserService
 	getUserFunc func(
 		ctx context.Context,
 		in *userservice.GetUserRequest,
@@ -142,6 +156,7 @@ func TestPlaceOrderLogic_PlaceOrder_ValidationErrors(t *testing.T) {
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.errMsg)
+				assert.Equal(t, codes.InvalidArgument, status.Code(err), "expected the API to know no order was created")
 				assert.Nil(t, resp)
 			} else {
 				assert.NoError(t, err)
@@ -215,11 +230,11 @@ func TestPlaceOrderLogic_PlaceOrder_UserValidationSuccess(t *testing.T) {
 		},
 	}
 	svcCtx := &svc.ServiceContext{
-		Config:  cfg,
-		UserRPC: mockUserRPC,
-		// RocketMQ is nil, so it will try to create one, which will fail without proper config
-		// This tests the error path when RocketMQ initialization fails
-		RocketMQ: nil,
+		Config:    cfg,
+		UserRPC:   mockUserRPC,
+		OrderRepo: repo.NewMemoryStore().OrderRepo(),
+		// OrderProducer is nil, as when the service starts without a message queue
+		OrderProducer: nil,
 	}
 	logic := NewPlaceOrderLogic(context.Background(), svcCtx)
 
@@ -230,14 +245,12 @@ func TestPlaceOrderLogic_PlaceOrder_UserValidationSuccess(t *testing.T) {
 		RealAmount: 100,
 	}
 
-	// This will fail when trying to create RocketMQ producer (no real broker available)
+	// User validation passes; the request then fails for lack of a producer
 	resp, err := logic.PlaceOrder(req)
-	// We expect an error because we can't create a real RocketMQ producer in unit tests
 	assert.Error(t, err)
 	assert.Nil(t, resp)
-	// Error should be related to RocketMQ initialization
-	assert.Contains(t, err.Error(), "failed to initialize message queue",
-		"Expected RocketMQ initialization error, got: %v", err)
+	assert.Contains(t, err.Error(), "message queue not available",
+		"Expected missing producer error, got: %v", err)
 }
 
 func TestPlaceOrderLogic_PlaceOrder_UserRPCError(t *testing.T) {
@@ -277,7 +290,7 @@ func TestPlaceOrderLogic_NewPlaceOrderLogic(t *testing.T) {
 
 	logic := NewPlaceOrderLogic(ctx, svcCtx)
 	assert.NotNil(t, logic)
-	assert.Equal(t, ctx, logic.ctx)
+	assert.Equal(t, database.ForcePrimary(ctx), logic.ctx, "reads must go to the primary")
 	assert.Equal(t, svcCtx, logic.svcCtx)
 }
 
@@ -293,9 +306,10 @@ func TestPlaceOrderLogic_PlaceOrder_LargeCourseCount(t *testing.T) {
 		},
 	}
 	svcCtx := &svc.ServiceContext{
-		Config:   cfg,
-		UserRPC:  mockUserRPC,
-		RocketMQ: nil,
+		Config:        cfg,
+		UserRPC:       mockUserRPC,
+		OrderRepo:     repo.NewMemoryStore().OrderRepo(),
+		OrderProducer: nil,
 	}
 	logic := NewPlaceOrderLogic(context.Background(), svcCtx)
 
@@ -310,35 +324,16 @@ func TestPlaceOrderLogic_PlaceOrder_LargeCourseCount(t *testing.T) {
 		req.CourseIds[i] = int64(i + 1)
 	}
 
-	// Will fail at RocketMQ initialization, but tests the validation passes
+	// Will fail for lack of a producer, but tests the validation passes
 	resp, err := logic.PlaceOrder(req)
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to initialize message queue")
+	assert.Contains(t, err.Error(), "message queue not available")
 	assert.Nil(t, resp)
 }
 
 func TestPlaceOrderLogic_PlaceOrder_MultipleCoursesPriceDistribution(t *testing.T) {
-	cfg := &config.Config{
-		RocketMQ: mq.Config{
-			NameServer: "127.0.0.1:9876",
-			Group:      "test-group",
-			Topic:      "test-topic",
-		},
-	}
-	mockUserRPC := &mockUserService{
-		getUserFunc: func(
-			_ context.Context,
-			in *userservice.GetUserRequest,
-			_ ...grpc.CallOption,
-		) (*userservice.GetUserResponse, error) {
-			return &userservice.GetUserResponse{UserId: in.UserId}, nil
-		},
-	}
-	svcCtx := &svc.ServiceContext{
-		Config:   cfg,
-		UserRPC:  mockUserRPC,
-		RocketMQ: nil,
-	}
+	store := repo.NewMemoryStore()
+	svcCtx, _ := newOutcomeTestContext(store.OrderRepo(), nil)
 	logic := NewPlaceOrderLogic(context.Background(), svcCtx)
 
 	// Test with 3 courses to verify price distribution logic
@@ -349,27 +344,19 @@ func TestPlaceOrderLogic_PlaceOrder_MultipleCoursesPriceDistribution(t *testing.
 		RealAmount: 1000, // 1000 cents = 10.00
 	}
 
-	// Will fail at RocketMQ initialization or message sending
 	resp, err := logic.PlaceOrder(req)
-	assert.Error(t, err)
-	// Error could be from initialization or message sending - check for any RocketMQ-related error
-	assert.True(t,
-		err != nil && (err.Error() == "failed to initialize message queue" ||
-			err.Error() == "nameServer is required" ||
-			err.Error() == "group is required" ||
-			err.Error() == "local transaction executor cannot be nil" ||
-			err.Error() == "check-back executor cannot be nil" ||
-			err.Error() == "invalid nameServer configuration" ||
-			err.Error() == "failed to create transaction producer" ||
-			err.Error() == "failed to start transaction producer" ||
-			err.Error() == "mq config cannot be nil" ||
-			err.Error() == "failed to send order message" ||
-			err.Error() == "failed to send transactional message: the topic=test-topic route info not found" ||
-			err.Error() == "failed to send order message: failed to send transactional message: "+
-				"the topic=test-topic route info not found" ||
-			err.Error() == "failed to send order message: failed to send transactional message"),
-		"Expected RocketMQ error, got: %v", err)
-	assert.Nil(t, resp)
+	assert.NoError(t, err)
+	assert.Equal(t, rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED, resp.Outcome)
+
+	var prices []int32
+	itemIDs := make(map[int64]bool)
+	for _, item := range orderItems(t, store, 1, 1) {
+		prices = append(prices, item.RealPayAmount)
+		itemIDs[item.ID] = true
+	}
+	assert.Equal(t, []int32{333, 333, 334}, prices, "the last item absorbs the rounding remainder")
+	assert.Len(t, itemIDs, 3, "every item gets its own ID")
+	assert.NotContains(t, itemIDs, req.OrderId+1, "item IDs are allocated, not derived from the order ID")
 }
 
 func TestPlaceOrderLogic_PlaceOrder_WithCoupons(t *testing.T) {
@@ -384,9 +371,10 @@ func TestPlaceOrderLogic_PlaceOrder_WithCoupons(t *testing.T) {
 		},
 	}
 	svcCtx := &svc.ServiceContext{
-		Config:   cfg,
-		UserRPC:  mockUserRPC,
-		RocketMQ: nil,
+		Config:        cfg,
+		UserRPC:       mockUserRPC,
+		OrderRepo:     repo.NewMemoryStore().OrderRepo(),
+		OrderProducer: nil,
 	}
 	logic := NewPlaceOrderLogic(context.Background(), svcCtx)
 
@@ -398,9 +386,413 @@ func TestPlaceOrderLogic_PlaceOrder_WithCoupons(t *testing.T) {
 		RealAmount: 1000,
 	}
 
-	// Will fail at RocketMQ initialization
+	// Will fail for lack of a producer
 	resp, err := logic.PlaceOrder(req)
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to initialize message queue")
+	assert.Contains(t, err.Error(), "message queue not available")
+	assert.Nil(t, resp)
+}
+
+// fakeOrderProducer is an mq.TransactionProducer that runs send behavior supplied by the test.
+type fakeOrderProducer struct {
+	send  func(ctx context.Context, msg *mq.Message) (*mq.TransactionSendResult, error)
+	sends int
+}
+
+func (f *fakeOrderProducer) Shutdown() error {
+	return nil
+}
+
+func (f *fakeOrderProducer) SendMessageInTransaction(
+	ctx context.Context, msg *mq.Message,
+) (*mq.TransactionSendResult, error) {
+	f.sends++
+	return f.send(ctx, msg)
+}
+
+// newOutcomeTestContext returns a service context whose producer runs the real local transaction
+// executor and then reports the state returned by report (the executor's state when report is nil).
+func newOutcomeTestContext(
+	orders svc.OrderRepository,
+	report func(state mq.LocalTransactionState) mq.LocalTransactionState,
+) (*svc.ServiceContext, *fakeOrderProducer) {
+	svcCtx := &svc.ServiceContext{
+		Config:    &config.Config{PlaceOrder: config.PlaceOrderConf{ConfirmTimeout: 50}},
+		UserRPC:   &mockUserService{},
+		OrderRepo: orders,
+	}
+	producer := &fakeOrderProducer{}
+	producer.send = func(ctx context.Context, msg *mq.Message) (*mq.TransactionSendResult, error) {
+		executor := ordertx.NewExecutor(svcCtx.OrderRepo, svcCtx.Config.PlaceOrder.GetConfirmTimeout())
+		state, err := executor.Execute(ctx, msg)
+		if err != nil && state != mq.UnknownState {
+			state = mq.RollbackMessageState
+		}
+		if report != nil {
+			state = report(state)
+		}
+		return &mq.TransactionSendResult{MsgID: "msg", LocalErr: err, State: state}, nil
+	}
+	svcCtx.OrderProducer = producer
+	return svcCtx, producer
+}
+
+func newOutcomeTestRequest() *rpc.PlaceOrderRequest {
+	return &rpc.PlaceOrderRequest{UserId: 1, OrderId: 500, CourseIds: []int64{1, 2}, RealAmount: 1000}
+}
+
+func TestPlaceOrderLogic_PlaceOrder_Committed(t *testing.T) {
+	store := repo.NewMemoryStore()
+	svcCtx, producer := newOutcomeTestContext(store.OrderRepo(), nil)
+
+	resp, err := NewPlaceOrderLogic(context.Background(), svcCtx).PlaceOrder(newOutcomeTestRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED, resp.Outcome)
+	assert.Equal(t, int32(database.OrderStatusPendingPayment), resp.Status)
+	assert.Equal(t, int32(1000), resp.PayAmount)
+	assert.True(t, hasOrder(store, 1, 500))
+	assert.Len(t, orderItems(t, store, 1, 500), 2)
+
+	// A retry returns the existing order without sending another message.
+	resp, err = NewPlaceOrderLogic(context.Background(), svcCtx).PlaceOrder(newOutcomeTestRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED, resp.Outcome)
+	assert.Equal(t, 1, producer.sends)
+}
+
+func TestPlaceOrderLogic_PlaceOrder_RolledBack(t *testing.T) {
+	store := repo.NewMemoryStore()
+	svcCtx, _ := newOutcomeTestContext(
+		&failingCreateOrderRepo{OrderRepository: store.OrderRepo(), err: errors.New("duplicate entry")}, nil)
+
+	resp, err := NewPlaceOrderLogic(context.Background(), svcCtx).PlaceOrder(newOutcomeTestRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_ROLLED_BACK, resp.Outcome)
+	assert.Equal(t, "duplicate entry", resp.Reason)
+	assert.Zero(t, resp.Status)
+	assert.False(t, hasOrder(store, 1, 500))
+}
+
+func TestPlaceOrderLogic_PlaceOrder_LookupError(t *testing.T) {
+	orders := &failingOrderRepo{OrderRepository: repo.NewMemoryStore().OrderRepo(), err: errors.New("connection refused")}
+	svcCtx, producer := newOutcomeTestContext(orders, nil)
+
+	resp, err := NewPlaceOrderLogic(context.Background(), svcCtx).PlaceOrder(newOutcomeTestRequest())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to look up order")
+	assert.Nil(t, resp)
+	assert.Zero(t, producer.sends)
+}
+
+func TestPlaceOrderLogic_PlaceOrder_UnknownResolvedByOrder(t *testing.T) {
+	// The insert committed but the executor could not tell; the order is found while waiting.
+	svcCtx, _ := newOutcomeTestContext(repo.NewMemoryStore().OrderRepo(), func(mq.LocalTransactionState) mq.LocalTransactionState {
+		return mq.UnknownState
+	})
+
+	resp, err := NewPlaceOrderLogic(context.Background(), svcCtx).PlaceOrder(newOutcomeTestRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED, resp.Outcome)
+}
+
+func TestPlaceOrderLogic_PlaceOrder_Pending(t *testing.T) {
+	svcCtx, _ := newOutcomeTestContext(
+		&failingCreateOrderRepo{OrderRepository: repo.NewMemoryStore().OrderRepo(), err: context.DeadlineExceeded}, nil)
+
+	resp, err := NewPlaceOrderLogic(context.Background(), svcCtx).PlaceOrder(newOutcomeTestRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_PENDING, resp.Outcome)
+	assert.Contains(t, resp.Reason, "deadline exceeded")
+	assert.Contains(t, resp.Reason, "check-back")
+	assert.Zero(t, resp.Status)
+}
+
+func TestPlaceOrderLogic_PlaceOrder_SendTimeoutIsPending(t *testing.T) {
+	svcCtx, producer := newOutcomeTestContext(repo.NewMemoryStore().OrderRepo(), nil)
+	producer.send = func(ctx context.Context, _ *mq.Message) (*mq.TransactionSendResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	resp, err := NewPlaceOrderLogic(context.Background(), svcCtx).PlaceOrder(newOutcomeTestRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_PENDING, resp.Outcome)
+	assert.Contains(t, resp.Reason, "timed out waiting for the message broker")
+}
+
+func TestPlaceOrderLogic_PlaceOrder_OneDeadline(t *testing.T) {
+	orders := &deadlineRecordingRepo{OrderRepository: repo.NewMemoryStore().OrderRepo()}
+	svcCtx, producer := newOutcomeTestContext(orders, nil)
+	var sendDeadline time.Time
+	producer.send = func(ctx context.Context, _ *mq.Message) (*mq.TransactionSendResult, error) {
+		sendDeadline, _ = ctx.Deadline()
+		return &mq.TransactionSendResult{MsgID: "msg", State: mq.UnknownState}, nil
+	}
+
+	resp, err := NewPlaceOrderLogic(context.Background(), svcCtx).PlaceOrder(newOutcomeTestRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_PENDING, resp.Outcome)
+
+	// Waiting for the unknown outcome gets what the send left, not a deadline of its own.
+	require.False(t, sendDeadline.IsZero())
+	require.NotEmpty(t, orders.deadlines)
+	for _, deadline := range orders.deadlines {
+		assert.Equal(t, sendDeadline, deadline)
+	}
+}
+
+func TestPlaceOrderLogic_PlaceOrder_SendError(t *testing.T) {
+	svcCtx, producer := newOutcomeTestContext(repo.NewMemoryStore().OrderRepo(), nil)
+	producer.send = func(_ context.Context, _ *mq.Message) (*mq.TransactionSendResult, error) {
+		return nil, errors.New("route info not found")
+	}
+
+	resp, err := NewPlaceOrderLogic(context.Background(), svcCtx).PlaceOrder(newOutcomeTestRequest())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to send order message")
 	assert.Nil(t, resp)
 }
+
+func TestPlaceOrderLogic_PlaceOrder_OrderIDOfAnotherUser(t *testing.T) {
+	store := newTestStore(&database.TradeOrder{ID: 500, UserID: 2, Status: database.OrderStatusPendingPayment})
+	svcCtx, _ := newOutcomeTestContext(store.OrderRepo(), nil)
+
+	// Lookups only see the orders of the user, so the order of user 2 is not reported as this one:
+	// the local transaction finds the ID taken and rolls the message back.
+	resp, err := NewPlaceOrderLogic(context.Background(), svcCtx).PlaceOrder(newOutcomeTestRequest())
+	assert.NoError(t, err)
+	assert.Equal(t, rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_ROLLED_BACK, resp.Outcome)
+	assert.False(t, hasOrder(store, 1, 500))
+	assert.True(t, hasOrder(store, 2, 500), "the order of the other user is kept")
+}
+
+// failingCreateOrderRepo fails CreateOrder while reads go to the embedded repository.
+type failingCreateOrderRepo struct {
+	svc.OrderRepository
+	err error
+}
+
+func (f *failingCreateOrderRepo) CreateOrder(
+	_ context.Context, _ *database.TradeOrder, _ []*database.TradeOrderItem,
+) error {
+	return f.err
+}
+
+// deadlineRecordingRepo records the deadlines of the order lookups made under one.
+type deadlineRecordingRepo struct {
+	svc.OrderRepository
+	deadlines []time.Time
+}
+
+func (r *deadlineRecordingRepo) GetByID(ctx context.Context, userID, orderID int64) (*database.TradeOrder, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		r.deadlines = append(r.deadlines, deadline)
+	}
+	return r.OrderRepository.GetByID(ctx, userID, orderID)
+}
+
+// timedOutCreateOrderRepo stores the order but reports a timeout, as when a commit succeeds after
+// the client gave up waiting.
+type timedOutCreateOrderRepo struct {
+	svc.OrderRepository
+}
+
+func (f *timedOutCreateOrderRepo) CreateOrder(
+	ctx context.Context, order *database.TradeOrder, items []*database.TradeOrderItem,
+) error {
+	if err := f.OrderRepository.CreateOrder(ctx, order, items); err != nil {
+		return err
+	}
+	return fmt.Errorf("failed to commit: %w", context.DeadlineExceeded)
+}
+
+// inventoryRedis keeps course stock for the promotion consumer, refusing to go below zero like
+// the Lua script of common/redis.
+type inventoryRedis struct {
+	stock map[string]int64
+	mu    sync.Mutex
+}
+
+func (r *inventoryRedis) Get(_ context.Context, key string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return fmt.Sprint(r.stock[key]), nil
+}
+
+func (r *inventoryRedis) DecrStock(_ context.Context, inventoryKey string, quantity int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stock[inventoryKey] < quantity {
+		return fmt.Errorf("insufficient stock for %s", inventoryKey)
+	}
+	r.stock[inventoryKey] -= quantity
+	return nil
+}
+
+func (r *inventoryRedis) IncrStock(_ context.Context, inventoryKey string, quantity int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stock[inventoryKey] += quantity
+	return nil
+}
+
+// promotionStock runs the promotion service's order event consumer over inventoryRedis, recording
+// the request ID each delivery carries.
+type promotionStock struct {
+	redis      *inventoryRedis
+	handler    *promotionconsumer.OrderEvents
+	requestIDs []string
+	mu         sync.Mutex
+}
+
+func newPromotionStock(stock map[int64]int64) *promotionStock {
+	redis := &inventoryRedis{stock: make(map[string]int64, len(stock))}
+	for courseID, units := range stock {
+		redis.stock[fmt.Sprintf("inventory:course:%d", courseID)] = units
+	}
+	return &promotionStock{
+		redis:   redis,
+		handler: promotionconsumer.NewOrderEvents(&promotionsvc.ServiceContext{Redis: redis}),
+	}
+}
+
+func (p *promotionStock) handle(ctx context.Context, e *event.Event) error {
+	p.mu.Lock()
+	p.requestIDs = append(p.requestIDs, mq.RequestIDFromContext(ctx))
+	p.mu.Unlock()
+	return p.handler.Handle(ctx, e)
+}
+
+// stock returns the units left of each course.
+func (p *promotionStock) stock() map[int64]int64 {
+	p.redis.mu.Lock()
+	defer p.redis.mu.Unlock()
+	stock := make(map[int64]int64, len(p.redis.stock))
+	for key, units := range p.redis.stock {
+		var courseID int64
+		_, _ = fmt.Sscanf(key, "inventory:course:%d", &courseID)
+		stock[courseID] = units
+	}
+	return stock
+}
+
+// newMemoryBrokerFlow wires PlaceOrder to an in-memory broker with the real order transaction
+// handlers, and the promotion consumer deducting the stock of the ordered courses.
+func newMemoryBrokerFlow(
+	t *testing.T, orders svc.OrderRepository, stock map[int64]int64,
+) (*svc.ServiceContext, *mq.MemoryBroker, *promotionStock) {
+	t.Helper()
+	broker := mq.NewMemoryBroker(
+		mq.WithCheckBackDelay(time.Millisecond),
+		mq.WithRetryDelay(time.Millisecond),
+		mq.WithMaxReconsumeTimes(2),
+	)
+	t.Cleanup(broker.Shutdown)
+
+	cfg := &config.Config{
+		RocketMQ:   mq.Config{Topic: "order-topic"},
+		PlaceOrder: config.PlaceOrderConf{ConfirmTimeout: 50},
+	}
+	producer, err := broker.NewTransactionProducer(
+		ordertx.NewExecutor(orders, cfg.PlaceOrder.GetConfirmTimeout()).Execute,
+		ordertx.NewChecker(orders).Check,
+	)
+	if err != nil {
+		t.Fatalf("failed to create producer: %v", err)
+	}
+
+	promotion := newPromotionStock(stock)
+	consumer, err := broker.NewConsumer("promotion-consumer")
+	if err != nil {
+		t.Fatalf("failed to create consumer: %v", err)
+	}
+	err = consumer.Subscribe(cfg.RocketMQ.Topic, promotionconsumer.SubscribedEvents, event.Default.Handler(promotion.handle))
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	if err := consumer.Start(); err != nil {
+		t.Fatalf("failed to start consumer: %v", err)
+	}
+
+	svcCtx := &svc.ServiceContext{
+		Config:        cfg,
+		UserRPC:       &mockUserService{},
+		OrderRepo:     orders,
+		OrderProducer: producer,
+	}
+	return svcCtx, broker, promotion
+}
+
+func waitBrokerIdle(t *testing.T, broker *mq.MemoryBroker) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := broker.WaitIdle(ctx); err != nil {
+		t.Fatalf("broker did not settle: %v", err)
+	}
+}
+
+func TestPlaceOrderLogic_PlaceOrder_MemoryBrokerFlow(t *testing.T) {
+	store := repo.NewMemoryStore()
+	svcCtx, broker, promotion := newMemoryBrokerFlow(t, store.OrderRepo(), map[int64]int64{1: 1, 2: 5})
+	ctx := mq.WithRequestID(context.Background(), "req-1")
+
+	resp, err := NewPlaceOrderLogic(ctx, svcCtx).PlaceOrder(
+		&rpc.PlaceOrderRequest{UserId: 1, OrderId: 500, CourseIds: []int64{1, 2}, RealAmount: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED, resp.Outcome)
+	waitBrokerIdle(t, broker)
+
+	// Course 1 is now sold out, so promotion keeps failing this order until it is dead-lettered.
+	resp, err = NewPlaceOrderLogic(ctx, svcCtx).PlaceOrder(
+		&rpc.PlaceOrderRequest{UserId: 1, OrderId: 501, CourseIds: []int64{1}, RealAmount: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED, resp.Outcome)
+
+	waitBrokerIdle(t, broker)
+	assert.True(t, hasOrder(store, 1, 500))
+	assert.True(t, hasOrder(store, 1, 501))
+	assert.Equal(t, map[int64]int64{1: 0, 2: 4}, promotion.stock())
+	assert.Len(t, promotion.requestIDs, 4, "one delivery for order 500, three for order 501")
+	for _, requestID := range promotion.requestIDs {
+		assert.Equal(t, "req-1", requestID)
+	}
+
+	dead := broker.DeadLetters("promotion-consumer")
+	if assert.Len(t, dead, 1) {
+		assert.Equal(t, []string{"order_501"}, dead[0].Keys)
+		assert.Equal(t, "order-topic", dead[0].Property(mq.PropertyOriginTopic))
+	}
+}
+
+func TestPlaceOrderLogic_PlaceOrder_MemoryBrokerCheckBack(t *testing.T) {
+	t.Run("order exists", func(t *testing.T) {
+		svcCtx, broker, promotion := newMemoryBrokerFlow(t,
+			&timedOutCreateOrderRepo{OrderRepository: repo.NewMemoryStore().OrderRepo()},
+			map[int64]int64{1: 1, 2: 1})
+
+		resp, err := NewPlaceOrderLogic(context.Background(), svcCtx).PlaceOrder(newOutcomeTestRequest())
+		assert.NoError(t, err)
+		assert.Equal(t, rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED, resp.Outcome)
+
+		waitBrokerIdle(t, broker)
+		assert.Zero(t, broker.HalfMessages())
+		assert.Len(t, broker.Messages("order-topic"), 1, "check-back commits the message")
+		assert.Len(t, promotion.requestIDs, 1)
+	})
+
+	t.Run("order missing", func(t *testing.T) {
+		svcCtx, broker, promotion := newMemoryBrokerFlow(t, &failingCreateOrderRepo{
+			OrderRepository: repo.NewMemoryStore().OrderRepo(), err: context.DeadlineExceeded,
+		}, map[int64]int64{1: 1})
+
+		resp, err := NewPlaceOrderLogic(context.Background(), svcCtx).PlaceOrder(newOutcomeTestRequest())
+		assert.NoError(t, err)
+		assert.Equal(t, rpc.PlaceOrderOutcome_PLACE_ORDER_OUTCOME_PENDING, resp.Outcome)
+
+		waitBrokerIdle(t, broker)
+		assert.Zero(t, broker.HalfMessages())
+		assert.Empty(t, broker.Messages("order-topic"), "check-back rolls the message back")
+		assert.Empty(t, promotion.requestIDs)
+	})
+}
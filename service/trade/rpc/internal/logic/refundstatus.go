@@ -0,0 +1,56 @@
+package logic
+
+import (
+	"fmt"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/service/trade/rpc"
+)
+
+// refundTransitions is the refund status machine:
+//
+//	Requested -> Approved -> Succeeded
+//	                      -> Failed
+//
+// Succeeded and Failed are terminal; a failed refund releases its items so the
+// user can request a new refund for them.
+var refundTransitions = map[int8][]int8{
+	database.RefundStatusRequested: {database.RefundStatusApproved},
+	database.RefundStatusApproved:  {database.RefundStatusSucceeded, database.RefundStatusFailed},
+}
+
+// checkRefundTransition reports an error unless the machine allows moving from one status to another.
+func checkRefundTransition(from, to int8) error {
+	for _, next := range refundTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("refund status %d cannot transition to %d", from, to)
+}
+
+// toRefundInfo converts a refund row and its items to their RPC representation.
+func toRefundInfo(refund *database.TradeRefund, items []*database.TradeRefundItem) *rpc.RefundInfo {
+	info := &rpc.RefundInfo{
+		RefundId: refund.ID,
+		OrderId:  refund.OrderID,
+		UserId:   refund.UserID,
+		Status:   int32(refund.Status),
+		Amount:   refund.Amount,
+		Reason:   refund.Reason,
+	}
+	if refund.OutRefundNo != nil {
+		info.OutRefundNo = *refund.OutRefundNo
+	}
+	if refund.FailReason != nil {
+		info.FailReason = *refund.FailReason
+	}
+	for _, item := range items {
+		info.Items = append(info.Items, &rpc.RefundItemInfo{
+			OrderItemId: item.OrderItemID,
+			CourseId:    item.CourseID,
+			Amount:      item.Amount,
+		})
+	}
+	return info
+}
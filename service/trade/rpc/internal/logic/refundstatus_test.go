@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+func TestCheckRefundTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    int8
+		to      int8
+		wantErr bool
+	}{
+		{name: "requested to approved", from: database.RefundStatusRequested, to: database.RefundStatusApproved},
+		{name: "approved to succeeded", from: database.RefundStatusApproved, to: database.RefundStatusSucceeded},
+		{name: "approved to failed", from: database.RefundStatusApproved, to: database.RefundStatusFailed},
+		{
+			name: "requested cannot skip approval", from: database.RefundStatusRequested,
+			to: database.RefundStatusSucceeded, wantErr: true,
+		},
+		{
+			name: "approved cannot be approved again", from: database.RefundStatusApproved,
+			to: database.RefundStatusApproved, wantErr: true,
+		},
+		{
+			name: "succeeded is terminal", from: database.RefundStatusSucceeded,
+			to: database.RefundStatusFailed, wantErr: true,
+		},
+		{
+			name: "failed is terminal", from: database.RefundStatusFailed,
+			to: database.RefundStatusApproved, wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRefundTransition(tt.from, tt.to)
+			if tt.wantErr && err == nil {
+				t.Fatalf("expected error for %d -> %d", tt.from, tt.to)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error for %d -> %d: %v", tt.from, tt.to, err)
+			}
+		})
+	}
+}
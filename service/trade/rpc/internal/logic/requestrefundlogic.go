@@ -0,0 +1,173 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxRefundReasonLength matches the trade_refund.reason column.
+const maxRefundReasonLength = 256
+
+// RequestRefundLogic handles refund requests from users.
+type RequestRefundLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewRequestRefundLogic creates a new RequestRefundLogic instance.
+func NewRequestRefundLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RequestRefundLogic {
+	return &RequestRefundLogic{
+		// The status of the order and its items decides whether they can be refunded.
+		ctx:    database.ForcePrimary(ctx),
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// RequestRefund opens a refund for a whole order or for selected order items.
+//
+// Responsibilities:
+//   - Verify that the order belongs to the user and has been paid
+//   - Select the items: the requested ones, or every item not yet refunded when none are given
+//   - Compute the amount as the sum of the items' real_pay_amount, which already carries
+//     each item's share of the coupon discount, so partial refunds never return more than was paid
+//   - Persist the refund as Requested; money only moves once an admin approves it
+//
+// Archived orders are no longer refunded: the request fails with repo.ErrOrderArchived.
+func (l *RequestRefundLogic) RequestRefund(req *rpc.RequestRefundRequest) (*rpc.RequestRefundResponse, error) {
+	if req == nil {
+		l.Errorf("received nil RequestRefundRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	if len(req.Reason) > maxRefundReasonLength {
+		l.Errorf("refund reason too long: %d bytes, orderId=%d", len(req.Reason), req.OrderId)
+		return nil, fmt.Errorf("refund reason must be at most %d bytes", maxRefundReasonLength)
+	}
+
+	for i, itemID := range req.OrderItemIds {
+		if itemID <= 0 {
+			l.Errorf("invalid order_item_id at index %d: %d", i, itemID)
+			return nil, fmt.Errorf("invalid order_item_id: %d", itemID)
+		}
+	}
+
+	order, err := loadOwnedOrder(l.ctx, l.svcCtx, l.Logger, req.UserId, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.svcCtx.RefundRepo == nil {
+		l.Errorf("refund repository not initialized")
+		return nil, fmt.Errorf("refund repository not available")
+	}
+
+	if order.Status != database.OrderStatusPaid && order.Status != database.OrderStatusFinished {
+		l.Errorf("order cannot be refunded: orderId=%d, status=%d", order.ID, order.Status)
+		return nil, fmt.Errorf("order cannot be refunded in status %d", order.Status)
+	}
+
+	orderItems, err := l.svcCtx.OrderRepo.GetItemsByOrderID(l.ctx, order.UserID, order.ID)
+	if err != nil {
+		l.Errorf("failed to load order items: %v, orderId=%d", err, order.ID)
+		return nil, fmt.Errorf("failed to load order items: %w", err)
+	}
+
+	selected, err := selectRefundItems(orderItems, req.OrderItemIds)
+	if err != nil {
+		l.Errorf("invalid refund items: %v, orderId=%d", err, order.ID)
+		return nil, err
+	}
+
+	refundID, err := l.svcCtx.NextRefundID()
+	if err != nil {
+		l.Errorf("failed to generate refund ID: %v", err)
+		return nil, fmt.Errorf("failed to generate refund ID: %w", err)
+	}
+
+	refund := &database.TradeRefund{
+		ID:      refundID,
+		OrderID: order.ID,
+		UserID:  order.UserID,
+		Status:  database.RefundStatusRequested,
+		Reason:  req.Reason,
+	}
+	refundItems := make([]*database.TradeRefundItem, 0, len(selected))
+	for _, item := range selected {
+		refund.Amount += item.RealPayAmount
+		refundItems = append(refundItems, &database.TradeRefundItem{
+			RefundID:    refundID,
+			OrderItemID: item.ID,
+			CourseID:    item.CourseID,
+			Amount:      item.RealPayAmount,
+		})
+	}
+
+	err = l.svcCtx.RefundRepo.CreateRefund(l.ctx, refund, refundItems)
+	if errors.Is(err, repo.ErrOrderArchived) {
+		l.Errorf("archived order cannot be refunded: orderId=%d", order.ID)
+		return nil, err
+	}
+	if err != nil {
+		l.Errorf("failed to create refund: %v, orderId=%d", err, order.ID)
+		return nil, fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	l.Infof("refund requested: refundId=%d, orderId=%d, userId=%d, items=%d, amount=%d",
+		refund.ID, order.ID, order.UserID, len(refundItems), refund.Amount)
+
+	return &rpc.RequestRefundResponse{Refund: toRefundInfo(refund, refundItems)}, nil
+}
+
+// selectRefundItems picks the order items a refund covers.
+// With no item IDs it takes every item that is not refunded or being refunded.
+func selectRefundItems(
+	orderItems []*database.TradeOrderItem, itemIDs []int64,
+) ([]*database.TradeOrderItem, error) {
+	if len(itemIDs) == 0 {
+		var selected []*database.TradeOrderItem
+		for _, item := range orderItems {
+			if item.RefundStatus == database.ItemRefundStatusNone {
+				selected = append(selected, item)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, fmt.Errorf("order has no refundable items")
+		}
+		return selected, nil
+	}
+
+	byID := make(map[int64]*database.TradeOrderItem, len(orderItems))
+	for _, item := range orderItems {
+		byID[item.ID] = item
+	}
+
+	selected := make([]*database.TradeOrderItem, 0, len(itemIDs))
+	seen := make(map[int64]bool, len(itemIDs))
+	for _, itemID := range itemIDs {
+		if seen[itemID] {
+			return nil, fmt.Errorf("duplicate order_item_id: %d", itemID)
+		}
+		seen[itemID] = true
+
+		item, ok := byID[itemID]
+		if !ok {
+			return nil, fmt.Errorf("order item %d does not belong to order", itemID)
+		}
+		if item.RefundStatus != database.ItemRefundStatusNone {
+			return nil, fmt.Errorf("order item %d is already refunded or being refunded", itemID)
+		}
+		selected = append(selected, item)
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,170 @@
+package logic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/config"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
+	"github.com/aether-defense-system/service/trade/rpc/internal/svc"
+)
+
+// newRefundTestStore returns a store with a paid order 100 of user 1 with three items whose
+// real_pay_amount carries a proportional share of a 1000-cent coupon discount.
+func newRefundTestStore() *repo.MemoryStore {
+	store := repo.NewMemoryStore()
+	store.Put(&database.TradeOrder{
+		ID: 100, UserID: 1, Status: database.OrderStatusPaid, TotalAmount: 10000, PayAmount: 9000,
+	},
+		&database.TradeOrderItem{ID: 101, OrderID: 100, UserID: 1, CourseID: 11, Price: 5000, RealPayAmount: 4500},
+		&database.TradeOrderItem{ID: 102, OrderID: 100, UserID: 1, CourseID: 12, Price: 3000, RealPayAmount: 2700},
+		&database.TradeOrderItem{ID: 103, OrderID: 100, UserID: 1, CourseID: 13, Price: 2000, RealPayAmount: 1800},
+	)
+	return store
+}
+
+// newRefundTestContext returns a service context over store.
+func newRefundTestContext(store *repo.MemoryStore) *svc.ServiceContext {
+	return &svc.ServiceContext{Config: &config.Config{}, OrderRepo: store.OrderRepo(), RefundRepo: store.RefundRepo()}
+}
+
+func TestRequestRefundLogic_RequestRefund_WholeOrder(t *testing.T) {
+	store := newRefundTestStore()
+	logic := NewRequestRefundLogic(context.Background(), newRefundTestContext(store))
+
+	resp, err := logic.RequestRefund(&rpc.RequestRefundRequest{UserId: 1, OrderId: 100, Reason: "duplicate purchase"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(database.RefundStatusRequested), resp.Refund.Status)
+	assert.Equal(t, int32(9000), resp.Refund.Amount, "whole-order refund returns exactly what was paid")
+	assert.Len(t, resp.Refund.Items, 3)
+	_, err = store.RefundRepo().GetByID(context.Background(), 1, resp.Refund.RefundId)
+	assert.NoError(t, err)
+
+	for _, item := range orderItems(t, store, 1, 100) {
+		assert.Equal(t, int8(database.ItemRefundStatusRefunding), item.RefundStatus)
+	}
+
+	_, err = logic.RequestRefund(&rpc.RequestRefundRequest{UserId: 1, OrderId: 100})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no refundable items")
+}
+
+// fixedIDs is a segment.IDGenerator handing out consecutive IDs from next.
+type fixedIDs struct {
+	next int64
+}
+
+func (g *fixedIDs) Next() (int64, error) {
+	g.next++
+	return g.next - 1, nil
+}
+
+func TestRequestRefundLogic_RequestRefund_SegmentIDs(t *testing.T) {
+	store := newRefundTestStore()
+	svcCtx := newRefundTestContext(store)
+	svcCtx.RefundIDs = &fixedIDs{next: 1001}
+	logic := NewRequestRefundLogic(context.Background(), svcCtx)
+
+	resp, err := logic.RequestRefund(&rpc.RequestRefundRequest{UserId: 1, OrderId: 100, OrderItemIds: []int64{102}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1001), resp.Refund.RefundId)
+	items, err := store.RefundRepo().GetItemsByRefundID(context.Background(), 1, 1001)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+}
+
+func TestRequestRefundLogic_RequestRefund_PartialThenRemainder(t *testing.T) {
+	logic := NewRequestRefundLogic(context.Background(), newRefundTestContext(newRefundTestStore()))
+
+	resp, err := logic.RequestRefund(&rpc.RequestRefundRequest{UserId: 1, OrderId: 100, OrderItemIds: []int64{102}})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2700), resp.Refund.Amount, "partial refund uses the item's real_pay_amount, not its price")
+
+	_, err = logic.RequestRefund(&rpc.RequestRefundRequest{UserId: 1, OrderId: 100, OrderItemIds: []int64{102}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "already refunded or being refunded")
+
+	resp, err = logic.RequestRefund(&rpc.RequestRefundRequest{UserId: 1, OrderId: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(4500+1800), resp.Refund.Amount, "whole-order request only covers the remaining items")
+	assert.Len(t, resp.Refund.Items, 2)
+}
+
+func TestRequestRefundLogic_RequestRefund_Errors(t *testing.T) {
+	store := newRefundTestStore()
+	store.Put(&database.TradeOrder{ID: 200, UserID: 1, Status: database.OrderStatusPendingPayment})
+	logic := NewRequestRefundLogic(context.Background(), newRefundTestContext(store))
+
+	tests := []struct {
+		req    *rpc.RequestRefundRequest
+		name   string
+		errMsg string
+	}{
+		{name: "nil request", req: nil, errMsg: "request cannot be nil"},
+		{name: "invalid user id", req: &rpc.RequestRefundRequest{UserId: 0, OrderId: 100}, errMsg: "invalid user_id"},
+		{name: "invalid order id", req: &rpc.RequestRefundRequest{UserId: 1, OrderId: 0}, errMsg: "invalid order_id"},
+		{
+			name:   "invalid item id",
+			req:    &rpc.RequestRefundRequest{UserId: 1, OrderId: 100, OrderItemIds: []int64{0}},
+			errMsg: "invalid order_item_id",
+		},
+		{
+			name:   "other user's order",
+			req:    &rpc.RequestRefundRequest{UserId: 2, OrderId: 100},
+			errMsg: "order not found",
+		},
+		{
+			name:   "unpaid order",
+			req:    &rpc.RequestRefundRequest{UserId: 1, OrderId: 200},
+			errMsg: "cannot be refunded",
+		},
+		{
+			name:   "item of another order",
+			req:    &rpc.RequestRefundRequest{UserId: 1, OrderId: 100, OrderItemIds: []int64{999}},
+			errMsg: "does not belong to order",
+		},
+		{
+			name:   "duplicate item",
+			req:    &rpc.RequestRefundRequest{UserId: 1, OrderId: 100, OrderItemIds: []int64{101, 101}},
+			errMsg: "duplicate order_item_id",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := logic.RequestRefund(tt.req)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+			assert.Nil(t, resp)
+		})
+	}
+}
+
+func TestRequestRefundLogic_RequestRefund_ArchivedOrder(t *testing.T) {
+	ctx := context.Background()
+	store := newRefundTestStore()
+	svcCtx := newRefundTestContext(store)
+	require.NoError(t, svcCtx.OrderRepo.UpdateStatus(ctx, 1, 100,
+		database.OrderStatusPaid, database.OrderStatusFinished, 0))
+	archived, _, err := store.OrderArchiver().ArchiveBatch(ctx, 0, time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, archived)
+
+	_, err = NewRequestRefundLogic(ctx, svcCtx).RequestRefund(&rpc.RequestRefundRequest{UserId: 1, OrderId: 100})
+	assert.ErrorIs(t, err, repo.ErrOrderArchived)
+	assert.Contains(t, err.Error(), "archived orders cannot be refunded")
+}
+
+func TestRequestRefundLogic_RequestRefund_RefundRepoNotInitialized(t *testing.T) {
+	svcCtx := &svc.ServiceContext{Config: &config.Config{}, OrderRepo: newRefundTestStore().OrderRepo()}
+	logic := NewRequestRefundLogic(context.Background(), svcCtx)
+
+	_, err := logic.RequestRefund(&rpc.RequestRefundRequest{UserId: 1, OrderId: 100})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "refund repository not available")
+}
@@ -0,0 +1,276 @@
+// Package ordertx contains the message queue local transaction handlers that create orders.
+//
+// PlaceOrder sends an ORDER_PLACED transactional message; Executor creates the order as the
+// message's local transaction and Checker answers the broker's check-back for unresolved messages.
+// Where RocketMQ transactions are not available, OutboxProducer writes the message to the outbox
+// with the order instead. All are stateless apart from the order store, so one instance serves
+// every request.
+package ordertx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/mq"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
+)
+
+// NewOrderPlacedMessage builds the ORDER_PLACED transactional message for an order.
+func NewOrderPlacedMessage(topic string, placed *event.OrderPlaced) (*mq.Message, error) {
+	env, err := event.Default.NewEnvelope(event.TypeOrderPlaced, strconv.FormatInt(placed.GetOrderId(), 10), placed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build order placed event: %w", err)
+	}
+	msg, err := event.Default.NewMessage(topic, env)
+	if err != nil {
+		return nil, err
+	}
+	return msg.WithKeys(fmt.Sprintf("order_%d", placed.OrderId)), nil
+}
+
+// parseOrderPlaced decodes the order carried by a transactional message.
+func parseOrderPlaced(msg *mq.Message) (*event.OrderPlaced, error) {
+	e, err := event.Default.Parse(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse order message: %w", err)
+	}
+	placed, ok := e.Payload.(*event.OrderPlaced)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse order message: unexpected event type %s", e.Envelope.Type)
+	}
+	return placed, nil
+}
+
+// OrderLookup loads the created orders of a user, so that a message whose order ID is taken can be
+// checked against the order that holds it.
+type OrderLookup interface {
+	GetByID(ctx context.Context, userID, orderID int64) (*database.TradeOrder, error)
+	GetItemsByOrderID(ctx context.Context, userID, orderID int64) ([]*database.TradeOrderItem, error)
+}
+
+// OrderStore defines the order operations the transaction handlers need.
+type OrderStore interface {
+	CreateOrder(ctx context.Context, order *database.TradeOrder, items []*database.TradeOrderItem) error
+	OrderLookup
+}
+
+// Executor creates orders as the local transaction of ORDER_PLACED messages.
+type Executor struct {
+	orders  OrderStore
+	timeout time.Duration
+}
+
+// NewExecutor creates an Executor. Each order insert is bounded by timeout.
+func NewExecutor(orders OrderStore, timeout time.Duration) *Executor {
+	return &Executor{orders: orders, timeout: timeout}
+}
+
+// Execute creates the order described by an order message. It implements mq.LocalTransactionExecutor.
+// A database timeout leaves the outcome unknown, because the insert may still have committed.
+func (e *Executor) Execute(ctx context.Context, msg *mq.Message) (mq.LocalTransactionState, error) {
+	if e.orders == nil {
+		logx.WithContext(ctx).Errorf("order repository not initialized")
+		return mq.RollbackMessageState, fmt.Errorf("order repository not available")
+	}
+	return createOrder(ctx, msg, e.timeout, e.orders, e.orders.CreateOrder)
+}
+
+// createOrder creates the order described by msg with create, bounded by timeout, and reports the
+// outcome as a transaction state. When the order ID is taken, the order holding it is loaded from
+// orders and compared with the message.
+func createOrder(
+	ctx context.Context,
+	msg *mq.Message,
+	timeout time.Duration,
+	orders OrderLookup,
+	create func(ctx context.Context, order *database.TradeOrder, items []*database.TradeOrderItem) error,
+) (mq.LocalTransactionState, error) {
+	logger := logx.WithContext(ctx)
+
+	placed, err := parseOrderPlaced(msg)
+	if err != nil {
+		logger.Errorf("%v", err)
+		return mq.RollbackMessageState, err
+	}
+
+	order, orderItems, err := toOrder(placed, time.Now())
+	if err != nil {
+		logger.Errorf("invalid order message: %v, orderId=%d", err, placed.OrderId)
+		return mq.RollbackMessageState, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err = create(ctx, order, orderItems); err != nil {
+		// An earlier delivery of the same message created the order, unless another request
+		// reused the order ID: only an order matching the message commits it.
+		if errors.Is(err, repo.ErrOrderExists) {
+			return resolveExisting(ctx, orders, order, orderItems)
+		}
+		logger.Errorf("failed to create order in local transaction: %v, orderId=%d", err, placed.OrderId)
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			return mq.UnknownState, err
+		}
+		return mq.RollbackMessageState, err
+	}
+
+	logger.Infof("order created successfully in local transaction: orderId=%d", placed.OrderId)
+	return mq.CommitMessageState, nil
+}
+
+// resolveExisting reports the state of a message whose order ID is taken: committed if the order
+// holding it is the one the message describes, rolled back if it is another order, of this user or
+// another one, and unknown if the order cannot be loaded.
+func resolveExisting(
+	ctx context.Context, orders OrderLookup, order *database.TradeOrder, items []*database.TradeOrderItem,
+) (mq.LocalTransactionState, error) {
+	logger := logx.WithContext(ctx)
+
+	// A replica may not have the order yet: only the primary tells what was created.
+	ctx = database.ForcePrimary(ctx)
+	existing, err := orders.GetByID(ctx, order.UserID, order.ID)
+	if errors.Is(err, repo.ErrOrderNotFound) {
+		logger.Infof("order not found: orderId=%d, userId=%d", order.ID, order.UserID)
+		return mq.RollbackMessageState, nil
+	}
+	if err != nil {
+		logger.Errorf("failed to look up existing order: %v, orderId=%d", err, order.ID)
+		return mq.UnknownState, err
+	}
+	existingItems, err := orders.GetItemsByOrderID(ctx, order.UserID, order.ID)
+	if err != nil {
+		logger.Errorf("failed to look up items of existing order: %v, orderId=%d", err, order.ID)
+		return mq.UnknownState, err
+	}
+
+	if diff := orderDiff(order, items, existing, existingItems); diff != "" {
+		err = fmt.Errorf("order %d already exists with a different %s", order.ID, diff)
+		logger.Errorf("%v", err)
+		return mq.RollbackMessageState, err
+	}
+	logger.Infof("order already created: orderId=%d, status=%d", order.ID, existing.Status)
+	return mq.CommitMessageState, nil
+}
+
+// orderDiff names the first difference between the order a message describes and an existing
+// order, or returns an empty string if the existing order is the one described. Statuses and
+// times are not compared: they change after the order is created.
+func orderDiff(
+	order *database.TradeOrder, items []*database.TradeOrderItem,
+	existing *database.TradeOrder, existingItems []*database.TradeOrderItem,
+) string {
+	switch {
+	case existing.UserID != order.UserID:
+		return "user"
+	case existing.TotalAmount != order.TotalAmount || existing.PayAmount != order.PayAmount:
+		return "amount"
+	case len(existingItems) != len(items):
+		return "item count"
+	}
+
+	byID := make(map[int64]*database.TradeOrderItem, len(existingItems))
+	for _, item := range existingItems {
+		byID[item.ID] = item
+	}
+	for _, item := range items {
+		got, ok := byID[item.ID]
+		if !ok || got.CourseID != item.CourseID || got.RealPayAmount != item.RealPayAmount {
+			return fmt.Sprintf("item %d", item.ID)
+		}
+	}
+	return ""
+}
+
+// toOrder reconstructs the order and its items from the message.
+// In production, you might want to include more details in the message
+// or fetch course prices from a service.
+//
+// Item IDs come from the message, which must carry one per course.
+func toOrder(m *event.OrderPlaced, now time.Time) (*database.TradeOrder, []*database.TradeOrderItem, error) {
+	courseCount := len(m.CourseIds)
+	if courseCount == 0 {
+		return nil, nil, fmt.Errorf("course list cannot be empty")
+	}
+	if courseCount > 2147483647 { // Max int32 value
+		return nil, nil, fmt.Errorf("course count too large")
+	}
+	if len(m.ItemIds) != courseCount {
+		return nil, nil, fmt.Errorf("got %d item IDs for %d courses", len(m.ItemIds), courseCount)
+	}
+
+	order := &database.TradeOrder{
+		ID:          m.OrderId,
+		UserID:      m.UserId,
+		Status:      database.OrderStatusPendingPayment,
+		TotalAmount: m.RealAmount,
+		PayAmount:   m.RealAmount,
+		CreateTime:  now,
+		UpdateTime:  now,
+		Version:     1,
+	}
+
+	courseCount32 := int32(courseCount)
+	items := make([]*database.TradeOrderItem, 0, courseCount)
+	for i, courseID := range m.CourseIds {
+		pricePerCourse := m.RealAmount / courseCount32
+		if i == courseCount-1 {
+			pricePerCourse = m.RealAmount - (pricePerCourse * (courseCount32 - 1))
+		}
+		items = append(items, &database.TradeOrderItem{
+			ID:            m.ItemIds[i],
+			OrderID:       m.OrderId,
+			UserID:        m.UserId,
+			CourseID:      courseID,
+			CourseName:    fmt.Sprintf("Course %d", courseID),
+			Price:         pricePerCourse,
+			RealPayAmount: pricePerCourse,
+			CreateTime:    now,
+			UpdateTime:    now,
+		})
+	}
+
+	return order, items, nil
+}
+
+// Checker resolves ORDER_PLACED messages whose local transaction outcome is unknown.
+type Checker struct {
+	orders OrderStore
+}
+
+// NewChecker creates a Checker.
+func NewChecker(orders OrderStore) *Checker {
+	return &Checker{orders: orders}
+}
+
+// Check commits the message if its order exists and is the order the message describes, and rolls
+// it back otherwise. It implements mq.CheckBackExecutor. A lookup failure keeps the transaction
+// unknown so that the broker checks back again, instead of rolling back a message whose order may
+// exist.
+func (c *Checker) Check(ctx context.Context, msg *mq.Message) (mq.LocalTransactionState, error) {
+	logger := logx.WithContext(ctx)
+
+	placed, err := parseOrderPlaced(msg)
+	if err != nil {
+		logger.Errorf("check-back: %v", err)
+		return mq.RollbackMessageState, err
+	}
+
+	order, items, err := toOrder(placed, time.Now())
+	if err != nil {
+		logger.Errorf("check-back: invalid order message: %v, orderId=%d", err, placed.OrderId)
+		return mq.RollbackMessageState, err
+	}
+
+	if c.orders == nil {
+		return mq.UnknownState, fmt.Errorf("order repository not available")
+	}
+	return resolveExisting(ctx, c.orders, order, items)
+}
@@ -0,0 +1,274 @@
+package ordertx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/mq"
+	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
+)
+
+// fakeOrderStore is an in-memory order store that fails with createErr and getErr, when set, and
+// records how orders were read.
+type fakeOrderStore struct {
+	*repo.MemoryOrderRepo
+	tables    *repo.MemoryStore
+	createErr error
+	getErr    error
+	// readPrimary records whether the last GetByID was forced to the primary.
+	readPrimary bool
+}
+
+func newFakeOrderStore() *fakeOrderStore {
+	tables := repo.NewMemoryStore()
+	return &fakeOrderStore{MemoryOrderRepo: tables.OrderRepo(), tables: tables}
+}
+
+func (f *fakeOrderStore) CreateOrder(
+	ctx context.Context, order *database.TradeOrder, items []*database.TradeOrderItem,
+) error {
+	if f.createErr != nil {
+		return f.createErr
+	}
+	return f.MemoryOrderRepo.CreateOrder(ctx, order, items)
+}
+
+func (f *fakeOrderStore) CreateOrderWithEvent(
+	ctx context.Context, order *database.TradeOrder, items []*database.TradeOrderItem, event *database.OutboxEvent,
+) error {
+	if f.createErr != nil {
+		return f.createErr
+	}
+	return f.MemoryOrderRepo.CreateOrderWithEvent(ctx, order, items, event)
+}
+
+func (f *fakeOrderStore) GetByID(ctx context.Context, userID, orderID int64) (*database.TradeOrder, error) {
+	f.readPrimary = database.PrimaryForced(ctx)
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	return f.MemoryOrderRepo.GetByID(ctx, userID, orderID)
+}
+
+// order returns a stored order of a user, or nil.
+func (f *fakeOrderStore) order(userID, orderID int64) *database.TradeOrder {
+	order, err := f.MemoryOrderRepo.GetByID(context.Background(), userID, orderID)
+	if err != nil {
+		return nil
+	}
+	return order
+}
+
+// items returns the stored items of an order of a user.
+func (f *fakeOrderStore) items(userID, orderID int64) []*database.TradeOrderItem {
+	items, _ := f.GetItemsByOrderID(context.Background(), userID, orderID)
+	return items
+}
+
+func newTestMessage(t *testing.T, placed *event.OrderPlaced) *mq.Message {
+	t.Helper()
+	msg, err := NewOrderPlacedMessage("order-topic", placed)
+	if err != nil {
+		t.Fatalf("failed to build message: %v", err)
+	}
+	return msg
+}
+
+// newLegacyMessage builds an ORDER_PLACED message with a JSON body, as published before the
+// event envelope.
+func newLegacyMessage(body string) *mq.Message {
+	msg := mq.NewMessage("order-topic", []byte(body)).WithTag(event.TypeOrderPlaced)
+	msg.MsgID = "legacy-msg"
+	return msg
+}
+
+func TestNewOrderPlacedMessage(t *testing.T) {
+	msg, err := NewOrderPlacedMessage("order-topic",
+		&event.OrderPlaced{OrderId: 42, UserId: 7, CourseIds: []int64{1}, RealAmount: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, "order-topic", msg.Topic)
+	assert.Equal(t, event.TypeOrderPlaced, msg.Tag)
+	assert.Equal(t, []string{"order_42"}, msg.Keys)
+
+	placed, err := parseOrderPlaced(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), placed.OrderId)
+	assert.Equal(t, []int64{1}, placed.CourseIds)
+}
+
+func TestExecutor_Execute(t *testing.T) {
+	store := newFakeOrderStore()
+	executor := NewExecutor(store, time.Second)
+
+	state, err := executor.Execute(context.Background(), newTestMessage(t, &event.OrderPlaced{
+		OrderId: 100, UserId: 1, CourseIds: []int64{1, 2, 3}, RealAmount: 1000, ItemIds: []int64{501, 502, 503},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, mq.CommitMessageState, state)
+
+	order := store.order(1, 100)
+	assert.NotNil(t, order)
+	assert.Equal(t, int64(1), order.UserID)
+	assert.Equal(t, int8(database.OrderStatusPendingPayment), order.Status)
+	assert.Equal(t, int32(1000), order.PayAmount)
+
+	var total int32
+	var ids []int64
+	for _, item := range store.items(1, 100) {
+		total += item.RealPayAmount
+		ids = append(ids, item.ID)
+	}
+	assert.Len(t, store.items(1, 100), 3)
+	assert.Equal(t, int32(1000), total, "item amounts add up to the order amount")
+	assert.Equal(t, []int64{501, 502, 503}, ids, "item IDs come from the message")
+}
+
+func TestExecutor_Execute_NoItemIDs(t *testing.T) {
+	store := newFakeOrderStore()
+	state, err := NewExecutor(store, time.Second).Execute(context.Background(),
+		newLegacyMessage(`{"orderId":100,"userId":1,"courseIds":[1,2],"realAmount":100}`))
+	assert.Error(t, err)
+	assert.Equal(t, mq.RollbackMessageState, state, "items cannot be created without the IDs allocated for them")
+	assert.Nil(t, store.order(1, 100))
+}
+
+func TestExecutor_Execute_AlreadyCreated(t *testing.T) {
+	placed := &event.OrderPlaced{OrderId: 100, UserId: 1, CourseIds: []int64{1, 2}, RealAmount: 100, ItemIds: []int64{501, 502}}
+	store := newFakeOrderStore()
+	executor := NewExecutor(store, time.Second)
+	state, err := executor.Execute(context.Background(), newTestMessage(t, placed))
+	assert.NoError(t, err)
+	assert.Equal(t, mq.CommitMessageState, state)
+
+	state, err = executor.Execute(context.Background(), newTestMessage(t, placed))
+	assert.NoError(t, err)
+	assert.Equal(t, mq.CommitMessageState, state, "a redelivered message is treated as applied")
+	assert.True(t, store.readPrimary, "a lagging replica would miss the order")
+	assert.Len(t, store.items(1, 100), 2)
+
+	// The order ID is taken by an order of another user, which the user's lookup does not find.
+	other := &event.OrderPlaced{OrderId: 100, UserId: 2, CourseIds: []int64{1, 2}, RealAmount: 100, ItemIds: []int64{501, 502}}
+	state, err = executor.Execute(context.Background(), newTestMessage(t, other))
+	assert.NoError(t, err)
+	assert.Equal(t, mq.RollbackMessageState, state, "a message of another user must not commit")
+	assert.Nil(t, store.order(2, 100))
+
+	tests := []struct {
+		placed *event.OrderPlaced
+		name   string
+	}{
+		{
+			name:   "other amount",
+			placed: &event.OrderPlaced{OrderId: 100, UserId: 1, CourseIds: []int64{1, 2}, RealAmount: 200, ItemIds: []int64{501, 502}},
+		},
+		{
+			name:   "other courses",
+			placed: &event.OrderPlaced{OrderId: 100, UserId: 1, CourseIds: []int64{1, 3}, RealAmount: 100, ItemIds: []int64{501, 502}},
+		},
+		{
+			name:   "other items",
+			placed: &event.OrderPlaced{OrderId: 100, UserId: 1, CourseIds: []int64{1}, RealAmount: 100, ItemIds: []int64{501}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, execErr := executor.Execute(context.Background(), newTestMessage(t, tt.placed))
+			assert.Error(t, execErr)
+			assert.Equal(t, mq.RollbackMessageState, got, "a message describing another order must not commit")
+			assert.Equal(t, int64(1), store.order(1, 100).UserID, "the existing order is kept")
+		})
+	}
+
+	store.getErr = errors.New("connection refused")
+	state, err = executor.Execute(context.Background(), newTestMessage(t, placed))
+	assert.Error(t, err)
+	assert.Equal(t, mq.UnknownState, state, "a failed lookup leaves the outcome to the check-back")
+}
+
+func TestExecutor_Execute_Failures(t *testing.T) {
+	tests := []struct {
+		createErr error
+		name      string
+		body      string
+		want      mq.LocalTransactionState
+	}{
+		{name: "malformed message", body: "{", want: mq.RollbackMessageState},
+		{name: "no courses", body: `{"orderId":100,"userId":1,"courseIds":[]}`, want: mq.RollbackMessageState},
+		{
+			name: "item IDs do not match courses",
+			body: `{"orderId":100,"userId":1,"courseIds":[1,2],"realAmount":100,"itemIds":[501]}`,
+			want: mq.RollbackMessageState,
+		},
+		{
+			name:      "insert failed",
+			body:      `{"orderId":100,"userId":1,"courseIds":[1],"realAmount":100,"itemIds":[501]}`,
+			createErr: errors.New("duplicate entry"),
+			want:      mq.RollbackMessageState,
+		},
+		{
+			name:      "insert timed out",
+			body:      `{"orderId":100,"userId":1,"courseIds":[1],"realAmount":100,"itemIds":[501]}`,
+			createErr: fmt.Errorf("failed to commit: %w", context.DeadlineExceeded),
+			want:      mq.UnknownState,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeOrderStore()
+			store.createErr = tt.createErr
+			state, err := NewExecutor(store, time.Second).Execute(context.Background(),
+				newLegacyMessage(tt.body))
+			assert.Error(t, err)
+			assert.Equal(t, tt.want, state)
+			assert.Nil(t, store.order(1, 100))
+		})
+	}
+}
+
+func TestChecker_Check(t *testing.T) {
+	placed := &event.OrderPlaced{OrderId: 100, UserId: 1, CourseIds: []int64{1}, RealAmount: 100, ItemIds: []int64{501}}
+	store := newFakeOrderStore()
+	state, err := NewExecutor(store, time.Second).Execute(context.Background(), newTestMessage(t, placed))
+	assert.NoError(t, err)
+	assert.Equal(t, mq.CommitMessageState, state)
+	checker := NewChecker(store)
+
+	state, err = checker.Check(context.Background(), newTestMessage(t, placed))
+	assert.NoError(t, err)
+	assert.Equal(t, mq.CommitMessageState, state)
+	assert.True(t, store.readPrimary, "a lagging replica would roll back a created order")
+
+	state, err = checker.Check(context.Background(), newTestMessage(t,
+		&event.OrderPlaced{OrderId: 200, UserId: 1, CourseIds: []int64{1}, RealAmount: 100, ItemIds: []int64{601}}))
+	assert.NoError(t, err)
+	assert.Equal(t, mq.RollbackMessageState, state)
+
+	// The order ID is held by another user's order, which is not among the orders of this user.
+	state, err = checker.Check(context.Background(), newTestMessage(t,
+		&event.OrderPlaced{OrderId: 100, UserId: 2, CourseIds: []int64{1}, RealAmount: 100, ItemIds: []int64{501}}))
+	assert.NoError(t, err)
+	assert.Equal(t, mq.RollbackMessageState, state)
+
+	// A half message sent before the event envelope is resolved the same way.
+	state, err = checker.Check(context.Background(),
+		newLegacyMessage(`{"orderId":100,"userId":1,"courseIds":[1],"realAmount":100,"itemIds":[501]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, mq.CommitMessageState, state)
+
+	state, err = checker.Check(context.Background(), newLegacyMessage(`{"orderId":100,"userId":1,"courseIds":[1]}`))
+	assert.Error(t, err)
+	assert.Equal(t, mq.RollbackMessageState, state, "a message without item IDs is invalid")
+
+	store.getErr = errors.New("connection refused")
+	state, err = checker.Check(context.Background(), newTestMessage(t, placed))
+	assert.Error(t, err)
+	assert.Equal(t, mq.UnknownState, state, "a failed lookup must not roll back an order that may exist")
+}
@@ -0,0 +1,75 @@
+package ordertx
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/mq"
+)
+
+// AggregateOrder is the outbox aggregate type of order events.
+const AggregateOrder = "order"
+
+// OutboxOrderStore creates an order together with the outbox event announcing it.
+type OutboxOrderStore interface {
+	CreateOrderWithEvent(
+		ctx context.Context,
+		order *database.TradeOrder,
+		items []*database.TradeOrderItem,
+		event *database.OutboxEvent,
+	) error
+	OrderLookup
+}
+
+// OutboxProducer is the outbox alternative to RocketMQ half messages. It writes the order and an
+// outbox event carrying the ORDER_PLACED message in one database transaction; an mq.OutboxRelay
+// publishes the event afterwards.
+//
+// It implements mq.TransactionProducer, so PlaceOrder works the same with either delivery mode.
+// No check-back is needed: the event exists exactly when the order does.
+type OutboxProducer struct {
+	orders  OutboxOrderStore
+	timeout time.Duration
+}
+
+// NewOutboxProducer creates an OutboxProducer. Each order insert is bounded by timeout.
+func NewOutboxProducer(orders OutboxOrderStore, timeout time.Duration) *OutboxProducer {
+	return &OutboxProducer{orders: orders, timeout: timeout}
+}
+
+// SendMessageInTransaction creates the order described by msg and its outbox event.
+// A database timeout leaves the outcome unknown, because the insert may still have committed.
+func (p *OutboxProducer) SendMessageInTransaction(
+	ctx context.Context,
+	msg *mq.Message,
+) (*mq.TransactionSendResult, error) {
+	placed, err := parseOrderPlaced(msg)
+	if err != nil {
+		return &mq.TransactionSendResult{LocalErr: err, State: mq.RollbackMessageState}, nil
+	}
+
+	outboxEvent, err := mq.NewOutboxEvent(ctx, AggregateOrder, strconv.FormatInt(placed.OrderId, 10), msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare outbox event: %w", err)
+	}
+
+	state, err := createOrder(ctx, msg, p.timeout, p.orders,
+		func(ctx context.Context, order *database.TradeOrder, items []*database.TradeOrderItem) error {
+			return p.orders.CreateOrderWithEvent(ctx, order, items, outboxEvent)
+		})
+
+	result := &mq.TransactionSendResult{LocalErr: err, State: state}
+	// An order created earlier commits without writing another event, so there is no new ID.
+	if state == mq.CommitMessageState && outboxEvent.ID != 0 {
+		result.MsgID = fmt.Sprintf("outbox-%d", outboxEvent.ID)
+	}
+	return result, nil
+}
+
+// Shutdown is a no-op; the relay and its producer are stopped separately.
+func (p *OutboxProducer) Shutdown() error {
+	return nil
+}
@@ -0,0 +1,76 @@
+package ordertx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aether-defense-system/common/event"
+	"github.com/aether-defense-system/common/mq"
+)
+
+func TestOutboxProducer_SendMessageInTransaction(t *testing.T) {
+	store := newFakeOrderStore()
+	producer := NewOutboxProducer(store, time.Second)
+
+	ctx := mq.WithRequestID(context.Background(), "req-1")
+	result, err := producer.SendMessageInTransaction(ctx,
+		newTestMessage(t, &event.OrderPlaced{OrderId: 100, UserId: 1, CourseIds: []int64{1, 2}, RealAmount: 1000, ItemIds: []int64{501, 502}}))
+	assert.NoError(t, err)
+	assert.Equal(t, mq.CommitMessageState, result.State)
+	assert.NoError(t, result.LocalErr)
+	assert.Equal(t, "outbox-1", result.MsgID)
+
+	assert.NotNil(t, store.order(1, 100))
+	events := store.tables.OutboxEvents()
+	if assert.Len(t, events, 1) {
+		outboxEvent := events[0]
+		assert.Equal(t, AggregateOrder, outboxEvent.AggregateType)
+		assert.Equal(t, "100", outboxEvent.AggregateID)
+		assert.Equal(t, "order-topic", outboxEvent.Topic)
+		assert.Equal(t, event.TypeOrderPlaced, outboxEvent.Tag)
+		assert.Equal(t, "order_100", outboxEvent.Keys)
+		assert.Contains(t, outboxEvent.Properties, `"x-request-id":"req-1"`)
+	}
+}
+
+func TestOutboxProducer_SendMessageInTransaction_Failures(t *testing.T) {
+	tests := []struct {
+		createErr error
+		name      string
+		body      string
+		want      mq.LocalTransactionState
+	}{
+		{name: "malformed message", body: "{", want: mq.RollbackMessageState},
+		{
+			name:      "insert failed",
+			body:      `{"orderId":100,"userId":1,"courseIds":[1],"realAmount":100,"itemIds":[501]}`,
+			createErr: errors.New("duplicate entry"),
+			want:      mq.RollbackMessageState,
+		},
+		{
+			name:      "insert timed out",
+			body:      `{"orderId":100,"userId":1,"courseIds":[1],"realAmount":100,"itemIds":[501]}`,
+			createErr: fmt.Errorf("failed to commit: %w", context.DeadlineExceeded),
+			want:      mq.UnknownState,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeOrderStore()
+			store.createErr = tt.createErr
+			result, err := NewOutboxProducer(store, time.Second).SendMessageInTransaction(context.Background(),
+				newLegacyMessage(tt.body))
+			assert.NoError(t, err)
+			assert.Error(t, result.LocalErr)
+			assert.Equal(t, tt.want, result.State)
+			assert.Empty(t, result.MsgID)
+			assert.Empty(t, store.tables.OutboxEvents())
+		})
+	}
+}
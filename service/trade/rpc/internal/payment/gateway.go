@@ -0,0 +1,91 @@
+// Package payment adapts trade logic to third-party payment gateways.
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrRefundRejected is returned when the gateway declines a refund.
+var ErrRefundRejected = errors.New("refund rejected by payment gateway")
+
+// RefundIdempotencyKey returns the idempotency key of the gateway refund of a refund.
+// Every attempt at a refund uses the same key, so the money is returned at most once.
+func RefundIdempotencyKey(refundID int64) string {
+	return fmt.Sprintf("refund-%d", refundID)
+}
+
+// RefundRequest describes a refund against a previously paid order.
+type RefundRequest struct {
+	OutTradeNo     string // Gateway transaction number of the original payment
+	IdempotencyKey string // Gateways return the original result for a retried key; see RefundIdempotencyKey
+	RefundID       int64  // Our refund ID
+	OrderID        int64
+	Amount         int32 // Refund amount in cents
+	TotalAmount    int32 // Amount originally paid in cents
+	PayChannel     int8  // PayChannel: 1=Alipay, 2=WeChat
+}
+
+// RefundResult is the gateway's acknowledgement of a successful refund.
+type RefundResult struct {
+	OutRefundNo string // Gateway refund transaction number
+}
+
+// Gateway issues refunds through a payment provider.
+type Gateway interface {
+	// Refund returns the money of req. A declined refund fails with an error wrapping
+	// ErrRefundRejected; any other error leaves the outcome unknown, to be settled by a retry.
+	Refund(ctx context.Context, req *RefundRequest) (*RefundResult, error)
+}
+
+// FakeGateway is an in-process Gateway for development and tests.
+// It accepts every refund that does not exceed the paid amount, unless Err is set.
+type FakeGateway struct {
+	Err     error // When set, every refund fails with this error
+	refunds map[string]*RefundResult
+	mu      sync.Mutex
+}
+
+// NewFakeGateway creates a new FakeGateway.
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{refunds: make(map[string]*RefundResult)}
+}
+
+// Refund simulates a gateway refund. Retrying the same idempotency key returns the original result.
+func (g *FakeGateway) Refund(_ context.Context, req *RefundRequest) (*RefundResult, error) {
+	if req == nil {
+		return nil, fmt.Errorf("refund request cannot be nil")
+	}
+	if req.IdempotencyKey == "" {
+		return nil, fmt.Errorf("refund idempotency key cannot be empty")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Err != nil {
+		return nil, g.Err
+	}
+
+	if result, ok := g.refunds[req.IdempotencyKey]; ok {
+		return result, nil
+	}
+
+	if req.Amount <= 0 || req.Amount > req.TotalAmount {
+		return nil, fmt.Errorf("%w: invalid amount %d of %d", ErrRefundRejected, req.Amount, req.TotalAmount)
+	}
+
+	result := &RefundResult{OutRefundNo: fmt.Sprintf("FAKE-REFUND-%d", req.RefundID)}
+	g.refunds[req.IdempotencyKey] = result
+
+	return result, nil
+}
+
+// Issued returns the number of distinct refunds the gateway has paid out.
+func (g *FakeGateway) Issued() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.refunds)
+}
@@ -0,0 +1,78 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFakeGateway_Refund(t *testing.T) {
+	g := NewFakeGateway()
+	req := &RefundRequest{
+		RefundID: 1, IdempotencyKey: RefundIdempotencyKey(1), OrderID: 10, Amount: 500, TotalAmount: 1000,
+	}
+
+	result, err := g.Refund(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.OutRefundNo == "" {
+		t.Fatalf("expected OutRefundNo")
+	}
+
+	again, err := g.Refund(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error on retry: %v", err)
+	}
+	if again.OutRefundNo != result.OutRefundNo {
+		t.Fatalf("expected retry to return %q, got %q", result.OutRefundNo, again.OutRefundNo)
+	}
+	if g.Issued() != 1 {
+		t.Fatalf("expected one refund to be paid out, got %d", g.Issued())
+	}
+}
+
+func TestFakeGateway_Refund_NoIdempotencyKey(t *testing.T) {
+	_, err := NewFakeGateway().Refund(context.Background(), &RefundRequest{RefundID: 1, Amount: 1, TotalAmount: 1})
+	if err == nil {
+		t.Fatalf("expected error without an idempotency key")
+	}
+}
+
+func TestFakeGateway_Refund_Rejected(t *testing.T) {
+	g := NewFakeGateway()
+
+	tests := []struct {
+		req  *RefundRequest
+		name string
+	}{
+		{name: "zero amount", req: &RefundRequest{IdempotencyKey: "refund-1", Amount: 0, TotalAmount: 100}},
+		{name: "exceeds paid amount", req: &RefundRequest{IdempotencyKey: "refund-2", Amount: 101, TotalAmount: 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := g.Refund(context.Background(), tt.req)
+			if !errors.Is(err, ErrRefundRejected) {
+				t.Fatalf("expected ErrRefundRejected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFakeGateway_Refund_ConfiguredError(t *testing.T) {
+	wantErr := errors.New("gateway unavailable")
+	g := NewFakeGateway()
+	g.Err = wantErr
+
+	_, err := g.Refund(context.Background(), &RefundRequest{IdempotencyKey: "refund-1", Amount: 1, TotalAmount: 1})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected configured error, got %v", err)
+	}
+}
+
+func TestFakeGateway_Refund_NilRequest(t *testing.T) {
+	if _, err := NewFakeGateway().Refund(context.Background(), nil); err == nil {
+		t.Fatalf("expected error for nil request")
+	}
+}
@@ -0,0 +1,245 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+// ArchivedStatuses are the final order statuses: orders in them are archived once past retention.
+var ArchivedStatuses = []int8{database.OrderStatusClosed, database.OrderStatusFinished, database.OrderStatusRefunded}
+
+// noOpenRefund is the condition, on trade_order o, that no refund of the order is in progress:
+// none of its items is being refunded and none of its refunds is requested or approved. Orders
+// with a refund in progress are left to a later pass, once the refund has settled.
+const noOpenRefund = `NOT EXISTS (SELECT 1 FROM trade_order_item i WHERE i.order_id = o.id AND i.refund_status = ?)
+	AND NOT EXISTS (SELECT 1 FROM trade_refund r WHERE r.order_id = o.id AND r.status IN (?, ?))`
+
+// noOpenRefundArgs are the arguments of noOpenRefund.
+var noOpenRefundArgs = []interface{}{
+	database.ItemRefundStatusRefunding, database.RefundStatusRequested, database.RefundStatusApproved,
+}
+
+// OrderArchiveStore moves old orders and their items to the archive tables, shard by shard, in
+// passes over the orders in (create_time, id) order. A checkpoint records the progress of the
+// pass of each shard, so an interrupted pass resumes where it stopped, and the next pass starts
+// over to pick up the orders that have reached a final status, or settled their refunds, since.
+//
+// OrderArchiver implements it over MySQL, and MemoryOrderArchiver in memory for tests.
+type OrderArchiveStore interface {
+	// Shards returns the number of shards.
+	Shards() int
+	// ArchiveBatch archives up to limit orders of a shard created and last updated before cutoff,
+	// and with no refund in progress, continuing the pass of the shard, and returns how many it
+	// archived and whether the pass is complete.
+	ArchiveBatch(ctx context.Context, shard int, cutoff time.Time, limit int) (int, bool, error)
+}
+
+var (
+	_ OrderArchiveStore = (*OrderArchiver)(nil)
+	_ OrderArchiveStore = (*MemoryOrderArchiver)(nil)
+)
+
+// OrderArchiver archives orders in trade_order_archive and trade_order_item_archive, keeping its
+// checkpoints in the trade_archive_checkpoint table of each database.
+type OrderArchiver struct {
+	shards *database.ShardRouter
+}
+
+// NewOrderArchiver creates a new OrderArchiver instance over the ShardedTables routed by shards.
+func NewOrderArchiver(shards *database.ShardRouter) *OrderArchiver {
+	return &OrderArchiver{shards: shards}
+}
+
+// Shards returns the number of shards.
+func (a *OrderArchiver) Shards() int {
+	return len(a.shards.Shards())
+}
+
+// ArchiveBatch moves a batch of orders and their items in one transaction, which also advances
+// the checkpoint of the shard: a batch is archived entirely or not at all. The checkpoint row is
+// locked for the duration of the batch, so concurrent archivers take turns on a shard.
+func (a *OrderArchiver) ArchiveBatch(ctx context.Context, shard int, cutoff time.Time, limit int) (int, bool, error) {
+	if shard < 0 || shard >= a.Shards() {
+		return 0, false, fmt.Errorf("invalid shard: %d", shard)
+	}
+	if limit <= 0 {
+		return 0, false, fmt.Errorf("invalid batch size: %d", limit)
+	}
+
+	s := a.shards.Shards()[shard]
+	var (
+		archived int
+		done     bool
+	)
+	err := s.WithTx(ctx, func(ctx context.Context) error {
+		archived, done = 0, false
+		tx := s.Executor(ctx)
+
+		if _, err := tx.ExecContext(ctx,
+			"INSERT IGNORE INTO trade_archive_checkpoint (shard) VALUES (?)", shard); err != nil {
+			return fmt.Errorf("failed to create archive checkpoint: %w", err)
+		}
+		var after sql.NullTime
+		var afterID int64
+		err := tx.QueryRowContext(ctx,
+			"SELECT last_create_time, last_order_id FROM trade_archive_checkpoint WHERE shard = ? FOR UPDATE",
+			shard).Scan(&after, &afterID)
+		if err != nil {
+			return fmt.Errorf("failed to read archive checkpoint: %w", err)
+		}
+
+		var cursor *OrderCursor
+		if after.Valid {
+			cursor = &OrderCursor{CreateTime: after.Time, ID: afterID}
+		}
+		candidates, last, err := archiveCandidates(ctx, tx, cutoff, cursor, limit)
+		if err != nil {
+			return err
+		}
+		done = len(candidates) < limit
+
+		if len(candidates) > 0 {
+			archived, err = moveOrders(ctx, tx, candidates)
+			if err != nil {
+				return err
+			}
+		}
+
+		// A complete pass clears the position, so that the next one starts over.
+		var position, positionID interface{} = nil, int64(0)
+		if !done {
+			position, positionID = last.CreateTime, last.ID
+		}
+		_, err = tx.ExecContext(ctx,
+			`UPDATE trade_archive_checkpoint
+			 SET last_create_time = ?, last_order_id = ?, archived_orders = archived_orders + ?
+			 WHERE shard = ?`,
+			position, positionID, archived, shard)
+		if err != nil {
+			return fmt.Errorf("failed to update archive checkpoint: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to archive orders of shard %d: %w", shard, err)
+	}
+	return archived, done, nil
+}
+
+// archiveCandidates returns the IDs of the next orders to archive after cursor, in
+// (create_time, id) order, and the position of the last one.
+func archiveCandidates(
+	ctx context.Context, tx database.DBTX, cutoff time.Time, cursor *OrderCursor, limit int,
+) ([]int64, *OrderCursor, error) {
+	query := `SELECT o.id, o.create_time FROM trade_order o
+	          WHERE o.create_time < ? AND o.update_time < ? AND o.status IN (?, ?, ?) AND ` + noOpenRefund
+	args := []interface{}{cutoff, cutoff, ArchivedStatuses[0], ArchivedStatuses[1], ArchivedStatuses[2]}
+	args = append(args, noOpenRefundArgs...)
+	if cursor != nil {
+		query += " AND (o.create_time > ? OR (o.create_time = ? AND o.id > ?))"
+		args = append(args, cursor.CreateTime, cursor.CreateTime, cursor.ID)
+	}
+	query += " ORDER BY o.create_time, o.id LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query orders to archive: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var ids []int64
+	last := &OrderCursor{}
+	for rows.Next() {
+		if err := rows.Scan(&last.ID, &last.CreateTime); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan order to archive: %w", err)
+		}
+		ids = append(ids, last.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating orders to archive: %w", err)
+	}
+	return ids, last, nil
+}
+
+// moveOrders moves the orders among candidates that are still in a final status and have no
+// refund in progress, with their items, to the archive tables and returns how many it moved. The
+// items and then the orders are locked first, so that none changes between its copy and its
+// deletion: a refund claiming items of a candidate either committed before, and the order is
+// left, or waits for the batch and finds no items to claim.
+func moveOrders(ctx context.Context, tx database.DBTX, candidates []int64) (int, error) {
+	in := "(" + placeholders(len(candidates)) + ")"
+	args := make([]interface{}, 0, len(candidates)+len(ArchivedStatuses)+len(noOpenRefundArgs))
+	for _, id := range candidates {
+		args = append(args, id)
+	}
+	items, err := tx.QueryContext(ctx,
+		"SELECT id FROM trade_order_item WHERE order_id IN "+in+" FOR UPDATE", args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock order items to archive: %w", err)
+	}
+	_ = items.Close()
+	for _, status := range ArchivedStatuses {
+		args = append(args, status)
+	}
+	args = append(args, noOpenRefundArgs...)
+	rows, err := tx.QueryContext(ctx,
+		"SELECT o.id FROM trade_order o WHERE o.id IN "+in+" AND o.status IN (?, ?, ?) AND "+noOpenRefund+" FOR UPDATE",
+		args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock orders to archive: %w", err)
+	}
+	var ids []interface{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("failed to scan order to archive: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		_ = rows.Close()
+		return 0, fmt.Errorf("error iterating orders to archive: %w", err)
+	}
+	_ = rows.Close()
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	in = "(" + placeholders(len(ids)) + ")"
+	for _, stmt := range []struct {
+		query string
+		what  string
+	}{
+		{
+			query: "INSERT INTO trade_order_archive (" + orderColumns + ") SELECT " + orderColumns +
+				" FROM trade_order WHERE id IN " + in,
+			what: "archive orders",
+		},
+		{
+			query: "INSERT INTO trade_order_item_archive (" + orderItemColumns + ") SELECT " + orderItemColumns +
+				" FROM trade_order_item WHERE order_id IN " + in,
+			what: "archive order items",
+		},
+		{query: "DELETE FROM trade_order_item WHERE order_id IN " + in, what: "delete archived order items"},
+		{query: "DELETE FROM trade_order WHERE id IN " + in, what: "delete archived orders"},
+	} {
+		if _, err := tx.ExecContext(ctx, stmt.query, ids...); err != nil {
+			return 0, fmt.Errorf("failed to %s: %w", stmt.what, err)
+		}
+	}
+	return len(ids), nil
+}
+
+// placeholders returns n comma-separated query placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
@@ -0,0 +1,166 @@
+//go:build integration
+// +build integration
+
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/database/migrations"
+	"github.com/aether-defense-system/common/migrate"
+)
+
+// repoTestDSNEnv names a MySQL server allowed to create and drop the test schema, e.g.
+// root:root@tcp(localhost:3306)/.
+const repoTestDSNEnv = "TRADE_REPO_TEST_MYSQL_DSN"
+
+// repoTestSchema is the local schema holding the trade tables of the tests.
+const repoTestSchema = "aether_trade_repo_test"
+
+// createTradeSchema creates the empty test schema, dropped when the test ends, and returns its DSN.
+func createTradeSchema(t *testing.T) string {
+	t.Helper()
+	dsn := os.Getenv(repoTestDSNEnv)
+	if dsn == "" {
+		t.Skipf("Set %s to run trade repository integration tests.", repoTestDSNEnv)
+	}
+	base, err := mysql.ParseDSN(dsn)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	server, err := sql.Open("mysql", base.FormatDSN())
+	require.NoError(t, err)
+	defer func() { _ = server.Close() }()
+	if err := server.PingContext(ctx); err != nil {
+		t.Skipf("MySQL not available for integration test: %v", err)
+	}
+	for _, stmt := range []string{
+		"DROP DATABASE IF EXISTS " + repoTestSchema,
+		"CREATE DATABASE " + repoTestSchema,
+	} {
+		_, err := server.ExecContext(ctx, stmt)
+		require.NoError(t, err)
+	}
+	t.Cleanup(func() {
+		dropDB, err := sql.Open("mysql", base.FormatDSN())
+		if err != nil {
+			return
+		}
+		defer func() { _ = dropDB.Close() }()
+		_, _ = dropDB.Exec("DROP DATABASE IF EXISTS " + repoTestSchema)
+	})
+
+	cfg := base.Clone()
+	cfg.DBName = repoTestSchema
+	cfg.ParseTime = true
+	return cfg.FormatDSN()
+}
+
+// setupTradeSchema creates the test schema with the trade tables, and returns a client of it.
+func setupTradeSchema(t *testing.T) *database.Client {
+	t.Helper()
+	client, err := database.NewClient(&database.Config{DSN: createTradeSchema(t)})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	set, err := migrations.Load(migrations.Trade)
+	require.NoError(t, err)
+	_, err = migrate.New(client.DB()).Up(ctx, set)
+	require.NoError(t, err)
+	return client
+}
+
+// setupShardedTradeSchema creates the test schema with the trade tables split into 4 shards, and
+// returns a router of them that rejects queries without a sharding key.
+func setupShardedTradeSchema(t *testing.T) *database.ShardRouter {
+	t.Helper()
+	router, err := database.NewShardRouter(&database.ShardingConfig{
+		Unkeyed:   database.ShardPolicyReject,
+		Databases: []database.Config{{DSN: createTradeSchema(t)}},
+		Shards:    4,
+	}, ShardedTables...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = router.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	set, err := migrations.Load(migrations.Trade)
+	require.NoError(t, err)
+	for _, m := range migrate.ForRouter(router) {
+		_, err = m.Up(ctx, set)
+		require.NoError(t, err)
+	}
+	return router
+}
+
+func TestIntegration_SQLRepos_Contract(t *testing.T) {
+	testStoreContract(t, func(t *testing.T) (OrderStore, RefundStore) {
+		client := setupTradeSchema(t)
+		return NewOrderRepo(client), NewRefundRepo(client)
+	})
+}
+
+// TestIntegration_ShardedSQLRepos_Contract runs the contract with users 1 and 2 in different
+// shards. The router rejects queries without a sharding key, so the contract passing shows that
+// the lookups and writes of users are routed by the user.
+func TestIntegration_ShardedSQLRepos_Contract(t *testing.T) {
+	testStoreContract(t, func(t *testing.T) (OrderStore, RefundStore) {
+		router := setupShardedTradeSchema(t)
+		require.NotEqual(t, router.Route(1).Index(), router.Route(2).Index())
+		return NewShardedOrderRepo(router), NewShardedRefundRepo(router)
+	})
+}
+
+func TestIntegration_SQLRepos_ArchiveContract(t *testing.T) {
+	testArchiveContract(t, func(t *testing.T) (OrderStore, RefundStore, OrderArchiveStore) {
+		client := setupTradeSchema(t)
+		return NewOrderRepo(client), NewRefundRepo(client), NewOrderArchiver(database.NewUnshardedRouter(client))
+	})
+}
+
+// TestIntegration_SQLRepos_JoinTransaction checks that the repos read and write in the
+// transaction of the context: the changes of a rolled back transaction are seen inside it and
+// nowhere else.
+func TestIntegration_SQLRepos_JoinTransaction(t *testing.T) {
+	client := setupTradeSchema(t)
+	orders, refunds := NewOrderRepo(client), NewRefundRepo(client)
+	ctx := context.Background()
+	items := createPaidOrder(t, orders, 100, 2)
+	require.NoError(t, requestRefund(ctx, refunds, 1000, 100, items[0]))
+
+	errRollback := errors.New("rollback")
+	err := database.WithTx(ctx, client.DB(), func(ctx context.Context) error {
+		require.NoError(t, orders.UpdateStatus(ctx, 1, 100, database.OrderStatusPaid, database.OrderStatusFinished, 1))
+		require.NoError(t, refunds.UpdateStatus(ctx, 1, 1000, database.RefundStatusRequested,
+			database.RefundStatusApproved))
+
+		order, err := orders.GetByID(ctx, 1, 100)
+		require.NoError(t, err)
+		assert.Equal(t, int8(database.OrderStatusFinished), order.Status)
+		refund, err := refunds.GetByID(ctx, 1, 1000)
+		require.NoError(t, err)
+		assert.Equal(t, int8(database.RefundStatusApproved), refund.Status)
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	order, err := orders.GetByID(ctx, 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.OrderStatusPaid), order.Status)
+	refund, err := refunds.GetByID(ctx, 1, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.RefundStatusRequested), refund.Status)
+}
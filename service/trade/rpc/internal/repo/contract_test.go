@@ -0,0 +1,526 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+// newStoresFunc returns an order and a refund store over the same empty tables.
+type newStoresFunc func(t *testing.T) (OrderStore, RefundStore)
+
+// testStoreContract checks the behavior OrderStore and RefundStore implementations share, so that
+// the in-memory repositories behave like the SQL ones logic tests stand in for.
+func testStoreContract(t *testing.T, newStores newStoresFunc) {
+	tests := []struct {
+		run  func(t *testing.T, orders OrderStore, refunds RefundStore)
+		name string
+	}{
+		{name: "CreateOrder", run: testCreateOrder},
+		{name: "CreateOrderAtomic", run: testCreateOrderAtomic},
+		{name: "CreateOrderWithEvent", run: testCreateOrderWithEvent},
+		{name: "UpdateStatus", run: testUpdateOrderStatus},
+		{name: "UpdateStatusConcurrent", run: testUpdateOrderStatusConcurrent},
+		{name: "UpdatePayInfo", run: testUpdatePayInfo},
+		{name: "ListByUserID", run: testListByUserID},
+		{name: "CreateRefund", run: testCreateRefund},
+		{name: "MarkFailed", run: testMarkFailed},
+		{name: "MarkSucceeded", run: testMarkSucceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders, refunds := newStores(t)
+			tt.run(t, orders, refunds)
+		})
+	}
+}
+
+// newContractOrder returns a pending order of user 1 with n items, its IDs derived from id.
+func newContractOrder(id int64, n int) (*database.TradeOrder, []*database.TradeOrderItem) {
+	order := &database.TradeOrder{
+		ID: id, UserID: 1, Status: database.OrderStatusPendingPayment, TotalAmount: int32(n) * 1000,
+		PayAmount: int32(n) * 1000,
+	}
+	items := make([]*database.TradeOrderItem, 0, n)
+	for i := 1; i <= n; i++ {
+		items = append(items, &database.TradeOrderItem{
+			ID: id*10 + int64(i), OrderID: id, UserID: 1, CourseID: int64(i), CourseName: "course",
+			Price: 1000, RealPayAmount: 1000,
+		})
+	}
+	return order, items
+}
+
+// createPaidOrder creates an order with n items and moves it to Paid.
+func createPaidOrder(t *testing.T, orders OrderStore, id int64, n int) []*database.TradeOrderItem {
+	t.Helper()
+	ctx := context.Background()
+	order, items := newContractOrder(id, n)
+	require.NoError(t, orders.CreateOrder(ctx, order, items))
+	require.NoError(t, orders.UpdateStatus(ctx, 1, id, database.OrderStatusPendingPayment, database.OrderStatusPaid, 0))
+	return items
+}
+
+// requestRefund creates a requested refund of user 1 for items of an order.
+func requestRefund(
+	ctx context.Context, refunds RefundStore, refundID, orderID int64, items ...*database.TradeOrderItem,
+) error {
+	refund := &database.TradeRefund{
+		ID: refundID, OrderID: orderID, UserID: 1, Status: database.RefundStatusRequested, Reason: "changed my mind",
+	}
+	var refundItems []*database.TradeRefundItem
+	for _, item := range items {
+		refund.Amount += item.RealPayAmount
+		refundItems = append(refundItems, &database.TradeRefundItem{
+			OrderItemID: item.ID, CourseID: item.CourseID, Amount: item.RealPayAmount,
+		})
+	}
+	return refunds.CreateRefund(ctx, refund, refundItems)
+}
+
+// itemRefundStatuses returns the refund status of the items of an order of user 1, by item ID.
+func itemRefundStatuses(t *testing.T, orders OrderStore, orderID int64) map[int64]int8 {
+	t.Helper()
+	items, err := orders.GetItemsByOrderID(context.Background(), 1, orderID)
+	require.NoError(t, err)
+	statuses := make(map[int64]int8, len(items))
+	for _, item := range items {
+		statuses[item.ID] = item.RefundStatus
+	}
+	return statuses
+}
+
+func testCreateOrder(t *testing.T, orders OrderStore, _ RefundStore) {
+	ctx := context.Background()
+	order, items := newContractOrder(100, 2)
+	require.NoError(t, orders.CreateOrder(ctx, order, items))
+
+	got, err := orders.GetByID(ctx, 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.UserID)
+	assert.Equal(t, int8(database.OrderStatusPendingPayment), got.Status)
+	assert.Equal(t, int32(2000), got.PayAmount)
+	assert.Nil(t, got.PayTime)
+	assert.False(t, got.CreateTime.IsZero(), "expected the create time set by the store")
+
+	// Rows are returned as copies.
+	got.Status = database.OrderStatusClosed
+	again, err := orders.GetByID(ctx, 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.OrderStatusPendingPayment), again.Status)
+
+	gotItems, err := orders.GetItemsByOrderID(ctx, 1, 100)
+	require.NoError(t, err)
+	require.Len(t, gotItems, 2)
+	for _, item := range gotItems {
+		assert.Equal(t, int8(database.ItemRefundStatusNone), item.RefundStatus)
+	}
+
+	_, err = orders.GetByID(ctx, 1, 999)
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+	gotItems, err = orders.GetItemsByOrderID(ctx, 1, 999)
+	require.NoError(t, err)
+	assert.Empty(t, gotItems)
+
+	// Orders are looked up among those of a user.
+	_, err = orders.GetByID(ctx, 2, 100)
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+	gotItems, err = orders.GetItemsByOrderID(ctx, 2, 100)
+	require.NoError(t, err)
+	assert.Empty(t, gotItems)
+}
+
+func testCreateOrderAtomic(t *testing.T, orders OrderStore, _ RefundStore) {
+	ctx := context.Background()
+	order, items := newContractOrder(100, 2)
+	require.NoError(t, orders.CreateOrder(ctx, order, items))
+
+	// The same order ID is rejected, and none of the new items is created.
+	_, otherItems := newContractOrder(200, 1)
+	err := orders.CreateOrder(ctx, order, otherItems)
+	assert.ErrorIs(t, err, ErrOrderExists)
+	gotItems, err := orders.GetItemsByOrderID(ctx, 1, 200)
+	require.NoError(t, err)
+	assert.Empty(t, gotItems)
+
+	// An item ID taken by another order rolls back the whole order.
+	order2, items2 := newContractOrder(300, 2)
+	items2[1].ID = items[0].ID
+	require.Error(t, orders.CreateOrder(ctx, order2, items2))
+	_, err = orders.GetByID(ctx, 1, 300)
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+
+	// Items of another user are rejected.
+	order3, items3 := newContractOrder(400, 1)
+	items3[0].UserID = 2
+	require.Error(t, orders.CreateOrder(ctx, order3, items3))
+	_, err = orders.GetByID(ctx, 1, 400)
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+}
+
+func testCreateOrderWithEvent(t *testing.T, orders OrderStore, _ RefundStore) {
+	ctx := context.Background()
+	order, items := newContractOrder(100, 1)
+	event := &database.OutboxEvent{
+		AggregateType: "order", AggregateID: "100", Topic: "order-events", Tag: "ORDER_PLACED",
+		Properties: "{}", Payload: []byte(`{"orderId":100}`),
+	}
+	require.NoError(t, orders.CreateOrderWithEvent(ctx, order, items, event))
+	assert.NotZero(t, event.ID)
+	assert.Equal(t, int8(database.OutboxStatusPending), event.Status)
+	_, err := orders.GetByID(ctx, 1, 100)
+	require.NoError(t, err)
+
+	order2, items2 := newContractOrder(200, 1)
+	require.Error(t, orders.CreateOrderWithEvent(ctx, order2, items2, nil))
+	_, err = orders.GetByID(ctx, 1, 200)
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+}
+
+func testUpdateOrderStatus(t *testing.T, orders OrderStore, _ RefundStore) {
+	ctx := context.Background()
+	order, items := newContractOrder(100, 1)
+	require.NoError(t, orders.CreateOrder(ctx, order, items))
+
+	assert.Error(t, orders.UpdateStatus(ctx, 1, 100, database.OrderStatusPendingPayment, database.OrderStatusPaid, 1),
+		"expected a stale version to be rejected")
+	assert.Error(t, orders.UpdateStatus(ctx, 1, 100, database.OrderStatusPaid, database.OrderStatusFinished, 0),
+		"expected a wrong status to be rejected")
+	assert.Error(t, orders.UpdateStatus(ctx, 1, 999, database.OrderStatusPendingPayment, database.OrderStatusPaid, 0))
+	assert.Error(t, orders.UpdateStatus(ctx, 2, 100, database.OrderStatusPendingPayment, database.OrderStatusPaid, 0),
+		"expected another user's update to be rejected")
+
+	require.NoError(t, orders.UpdateStatus(ctx, 1, 100, database.OrderStatusPendingPayment, database.OrderStatusPaid, 0))
+	got, err := orders.GetByID(ctx, 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.OrderStatusPaid), got.Status)
+	assert.Equal(t, int32(1), got.Version)
+}
+
+func testUpdateOrderStatusConcurrent(t *testing.T, orders OrderStore, _ RefundStore) {
+	ctx := context.Background()
+	order, items := newContractOrder(100, 1)
+	require.NoError(t, orders.CreateOrder(ctx, order, items))
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- orders.UpdateStatus(ctx, 1, 100, database.OrderStatusPendingPayment, database.OrderStatusClosed, 0)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	assert.Equal(t, 1, succeeded, "expected the version to let exactly one update through")
+	got, err := orders.GetByID(ctx, 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), got.Version)
+}
+
+func testUpdatePayInfo(t *testing.T, orders OrderStore, _ RefundStore) {
+	ctx := context.Background()
+	order, items := newContractOrder(100, 1)
+	require.NoError(t, orders.CreateOrder(ctx, order, items))
+
+	payTime := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	assert.Error(t, orders.UpdatePayInfo(ctx, 2, 100, database.PayChannelAlipay, "T100", payTime),
+		"expected another user's update to be rejected")
+	require.NoError(t, orders.UpdatePayInfo(ctx, 1, 100, database.PayChannelAlipay, "T100", payTime))
+	got, err := orders.GetByID(ctx, 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.OrderStatusPaid), got.Status)
+	require.NotNil(t, got.PayChannel)
+	assert.Equal(t, int8(database.PayChannelAlipay), *got.PayChannel)
+	require.NotNil(t, got.OutTradeNo)
+	assert.Equal(t, "T100", *got.OutTradeNo)
+	assert.NotNil(t, got.PayTime)
+
+	assert.Error(t, orders.UpdatePayInfo(ctx, 1, 100, database.PayChannelAlipay, "T101", payTime),
+		"expected a paid order to be rejected")
+}
+
+func testListByUserID(t *testing.T, orders OrderStore, _ RefundStore) {
+	ctx := context.Background()
+	for id := int64(101); id <= 105; id++ {
+		order, items := newContractOrder(id, 1)
+		require.NoError(t, orders.CreateOrder(ctx, order, items))
+	}
+	other, otherItems := newContractOrder(200, 1)
+	other.UserID = 2
+	otherItems[0].UserID = 2
+	require.NoError(t, orders.CreateOrder(ctx, other, otherItems))
+	require.NoError(t, orders.UpdateStatus(ctx, 1, 104, database.OrderStatusPendingPayment, database.OrderStatusClosed, 0))
+
+	// Orders are created in ID order, so that newest first is highest ID first, including
+	// between orders created within the same second.
+	page, err := orders.ListByUserID(ctx, 1, nil, nil, 3)
+	require.NoError(t, err)
+	require.Len(t, page, 3)
+	ids := []int64{page[0].ID, page[1].ID, page[2].ID}
+	last := page[2]
+	page, err = orders.ListByUserID(ctx, 1, nil, &OrderCursor{CreateTime: last.CreateTime, ID: last.ID}, 3)
+	require.NoError(t, err)
+	for _, order := range page {
+		ids = append(ids, order.ID)
+	}
+	assert.Equal(t, []int64{105, 104, 103, 102, 101}, ids)
+	closed := int8(database.OrderStatusClosed)
+	page, err = orders.ListByUserID(ctx, 1, &closed, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, int64(104), page[0].ID)
+
+	page, err = orders.ListByUserID(ctx, 3, nil, nil, 10)
+	require.NoError(t, err)
+	assert.Empty(t, page)
+}
+
+func testCreateRefund(t *testing.T, orders OrderStore, refunds RefundStore) {
+	ctx := context.Background()
+	items := createPaidOrder(t, orders, 100, 3)
+
+	require.NoError(t, requestRefund(ctx, refunds, 1000, 100, items[0], items[1]))
+	got, err := refunds.GetByID(ctx, 1, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.RefundStatusRequested), got.Status)
+	assert.Equal(t, int32(2000), got.Amount)
+	refundItems, err := refunds.GetItemsByRefundID(ctx, 1, 1000)
+	require.NoError(t, err)
+	assert.Len(t, refundItems, 2)
+
+	// Refunds are looked up among those of a user, except by admins, who only know the refund ID.
+	_, err = refunds.GetByID(ctx, 2, 1000)
+	assert.Error(t, err)
+	refundItems, err = refunds.GetItemsByRefundID(ctx, 2, 1000)
+	require.NoError(t, err)
+	assert.Empty(t, refundItems)
+	// A sharded store may reject them instead of querying every shard.
+	if found, findErr := refunds.FindByID(ctx, 1000); !errors.Is(findErr, database.ErrShardKeyRequired) {
+		require.NoError(t, findErr)
+		assert.Equal(t, int64(1), found.UserID)
+		_, err = refunds.FindByID(ctx, 999)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, map[int64]int8{
+		items[0].ID: database.ItemRefundStatusRefunding,
+		items[1].ID: database.ItemRefundStatusRefunding,
+		items[2].ID: database.ItemRefundStatusNone,
+	}, itemRefundStatuses(t, orders, 100))
+
+	// A claimed item cannot be refunded twice, and the rejected refund claims nothing.
+	err = requestRefund(ctx, refunds, 1001, 100, items[2], items[0])
+	assert.ErrorIs(t, err, ErrItemNotRefundable)
+	_, err = refunds.GetByID(ctx, 1, 1001)
+	assert.Error(t, err)
+	assert.Equal(t, int8(database.ItemRefundStatusNone), itemRefundStatuses(t, orders, 100)[items[2].ID])
+
+	// Items of another order are not refundable with this one.
+	otherItems := createPaidOrder(t, orders, 200, 1)
+	err = requestRefund(ctx, refunds, 1002, 100, otherItems[0])
+	assert.ErrorIs(t, err, ErrItemNotRefundable)
+
+	require.Error(t, refunds.UpdateStatus(ctx, 1, 1000, database.RefundStatusApproved, database.RefundStatusSucceeded))
+	require.Error(t, refunds.UpdateStatus(ctx, 2, 1000, database.RefundStatusRequested, database.RefundStatusApproved),
+		"expected another user's update to be rejected")
+	require.NoError(t, refunds.UpdateStatus(ctx, 1, 1000, database.RefundStatusRequested, database.RefundStatusApproved))
+	require.Error(t, refunds.UpdateStatus(ctx, 1, 1000, database.RefundStatusRequested, database.RefundStatusApproved),
+		"expected the status to let one transition through")
+	require.Error(t, refunds.UpdateStatus(ctx, 1, 999, database.RefundStatusRequested, database.RefundStatusApproved))
+}
+
+func testMarkFailed(t *testing.T, orders OrderStore, refunds RefundStore) {
+	ctx := context.Background()
+	items := createPaidOrder(t, orders, 100, 2)
+	require.NoError(t, requestRefund(ctx, refunds, 1000, 100, items[0]))
+	refund, err := refunds.GetByID(ctx, 1, 1000)
+	require.NoError(t, err)
+
+	assert.Error(t, refunds.MarkFailed(ctx, refund, "rejected"), "expected a requested refund to be rejected")
+	require.NoError(t, refunds.UpdateStatus(ctx, 1, 1000, database.RefundStatusRequested, database.RefundStatusApproved))
+	require.NoError(t, refunds.MarkFailed(ctx, refund, "rejected"))
+
+	got, err := refunds.GetByID(ctx, 1, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.RefundStatusFailed), got.Status)
+	require.NotNil(t, got.FailReason)
+	assert.Equal(t, "rejected", *got.FailReason)
+	assert.Equal(t, int8(database.ItemRefundStatusNone), itemRefundStatuses(t, orders, 100)[items[0].ID],
+		"expected the item released")
+
+	// The released item can be refunded again.
+	require.NoError(t, requestRefund(ctx, refunds, 1001, 100, items[0]))
+}
+
+func testMarkSucceeded(t *testing.T, orders OrderStore, refunds RefundStore) {
+	ctx := context.Background()
+	items := createPaidOrder(t, orders, 100, 2)
+	for i, item := range items {
+		refundID := int64(1000 + i)
+		require.NoError(t, requestRefund(ctx, refunds, refundID, 100, item))
+		require.NoError(t, refunds.UpdateStatus(ctx, 1, refundID, database.RefundStatusRequested, database.RefundStatusApproved))
+	}
+
+	// refundEvent records whether each refund completed the order, as passed to its event.
+	completions := map[string]bool{}
+	refundEvent := func(outRefundNo string) RefundEventFunc {
+		return func(orderRefunded bool) (*database.OutboxEvent, error) {
+			completions[outRefundNo] = orderRefunded
+			return &database.OutboxEvent{
+				AggregateType: "order", AggregateID: "100", Topic: "order-events", Tag: "ORDER_REFUNDED",
+				Properties: "{}", Payload: []byte(outRefundNo),
+			}, nil
+		}
+	}
+
+	first, err := refunds.GetByID(ctx, 1, 1000)
+	require.NoError(t, err)
+	completed, err := refunds.MarkSucceeded(ctx, first, "R1000", refundEvent("R1000"))
+	require.NoError(t, err)
+	assert.False(t, completed, "expected an item of the order left unrefunded")
+	got, err := refunds.GetByID(ctx, 1, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.RefundStatusSucceeded), got.Status)
+	require.NotNil(t, got.OutRefundNo)
+	assert.Equal(t, "R1000", *got.OutRefundNo)
+	_, err = refunds.MarkSucceeded(ctx, first, "R1000", nil)
+	assert.Error(t, err, "expected a succeeded refund to be rejected")
+
+	// A refund whose event cannot be built is not recorded.
+	second, err := refunds.GetByID(ctx, 1, 1001)
+	require.NoError(t, err)
+	_, err = refunds.MarkSucceeded(ctx, second, "R1001", func(bool) (*database.OutboxEvent, error) {
+		return nil, errors.New("no event")
+	})
+	require.Error(t, err)
+	got, err = refunds.GetByID(ctx, 1, 1001)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.RefundStatusApproved), got.Status)
+
+	completed, err = refunds.MarkSucceeded(ctx, second, "R1001", refundEvent("R1001"))
+	require.NoError(t, err)
+	assert.True(t, completed)
+	assert.Equal(t, map[string]bool{"R1000": false, "R1001": true}, completions)
+
+	order, err := orders.GetByID(ctx, 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.OrderStatusRefunded), order.Status)
+	assert.Equal(t, int32(2), order.Version)
+	assert.Equal(t, map[int64]int8{
+		items[0].ID: database.ItemRefundStatusRefunded,
+		items[1].ID: database.ItemRefundStatusRefunded,
+	}, itemRefundStatuses(t, orders, 100))
+}
+
+// newArchiveStoresFunc returns an order store, a refund store and an order archiver over the same
+// empty tables.
+type newArchiveStoresFunc func(t *testing.T) (OrderStore, RefundStore, OrderArchiveStore)
+
+// testArchiveContract checks the behavior OrderArchiveStore implementations share, and that
+// orders stay readable from an OrderStore once archived.
+func testArchiveContract(t *testing.T, newStores newArchiveStoresFunc) {
+	ctx := context.Background()
+	orders, refunds, archiver := newStores(t)
+	require.Equal(t, 1, archiver.Shards())
+
+	for id := int64(101); id <= 104; id++ {
+		order, items := newContractOrder(id, 2)
+		require.NoError(t, orders.CreateOrder(ctx, order, items))
+	}
+	require.NoError(t, orders.UpdateStatus(ctx, 1, 101, database.OrderStatusPendingPayment, database.OrderStatusClosed, 0))
+	require.NoError(t, orders.UpdateStatus(ctx, 1, 102, database.OrderStatusPendingPayment, database.OrderStatusPaid, 0))
+	require.NoError(t, orders.UpdateStatus(ctx, 1, 102, database.OrderStatusPaid, database.OrderStatusFinished, 1))
+	require.NoError(t, orders.UpdateStatus(ctx, 1, 103, database.OrderStatusPendingPayment, database.OrderStatusClosed, 0))
+
+	// Orders are archived once created and last updated before the cutoff.
+	archived, done, err := archiver.ArchiveBatch(ctx, 0, time.Now().Add(-time.Hour), 2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, archived)
+	assert.True(t, done)
+
+	// A pass archives the orders in a final status in batches, and leaves the pending one.
+	cutoff := time.Now().Add(time.Hour)
+	archived, done, err = archiver.ArchiveBatch(ctx, 0, cutoff, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, archived)
+	assert.False(t, done)
+	archived, done, err = archiver.ArchiveBatch(ctx, 0, cutoff, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, archived)
+	assert.True(t, done)
+
+	// The next pass starts over, and picks up the orders that reached a final status since.
+	require.NoError(t, orders.UpdateStatus(ctx, 1, 104, database.OrderStatusPendingPayment, database.OrderStatusClosed, 0))
+	archived, done, err = archiver.ArchiveBatch(ctx, 0, cutoff, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, archived)
+	assert.True(t, done)
+
+	// Archived orders and their items are still read, and no longer updated.
+	got, err := orders.GetByID(ctx, 1, 102)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.OrderStatusFinished), got.Status)
+	assert.Equal(t, int32(2), got.Version)
+	items, err := orders.GetItemsByOrderID(ctx, 1, 102)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, int64(1021), items[0].ID)
+	assert.ErrorIs(t, requestRefund(ctx, refunds, 2000, 102, items[0]), ErrOrderArchived,
+		"expected an archived order not to be refunded")
+	page, err := orders.ListByUserID(ctx, 1, nil, nil, 10)
+	require.NoError(t, err)
+	ids := make([]int64, 0, len(page))
+	for _, order := range page {
+		ids = append(ids, order.ID)
+	}
+	assert.Equal(t, []int64{104, 103, 102, 101}, ids)
+	assert.Error(t, orders.UpdateStatus(ctx, 1, 101, database.OrderStatusClosed, database.OrderStatusPaid, 1))
+
+	_, err = orders.GetByID(ctx, 1, 999)
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+
+	// Orders with a refund in progress are left until the refund settles.
+	items = createPaidOrder(t, orders, 105, 2)
+	require.NoError(t, orders.UpdateStatus(ctx, 1, 105, database.OrderStatusPaid, database.OrderStatusFinished, 1))
+	require.NoError(t, requestRefund(ctx, refunds, 1000, 105, items[0]))
+	archived, done, err = archiver.ArchiveBatch(ctx, 0, cutoff, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, archived, "expected an order with a requested refund to be left")
+	assert.True(t, done)
+	require.NoError(t, refunds.UpdateStatus(ctx, 1, 1000, database.RefundStatusRequested, database.RefundStatusApproved))
+	archived, _, err = archiver.ArchiveBatch(ctx, 0, cutoff, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, archived, "expected an order with an approved refund to be left")
+	refund, err := refunds.GetByID(ctx, 1, 1000)
+	require.NoError(t, err)
+	require.NoError(t, refunds.MarkFailed(ctx, refund, "rejected"))
+	archived, done, err = archiver.ArchiveBatch(ctx, 0, cutoff, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, archived)
+	assert.True(t, done)
+	assert.Equal(t, map[int64]int8{
+		items[0].ID: database.ItemRefundStatusNone,
+		items[1].ID: database.ItemRefundStatusNone,
+	}, itemRefundStatuses(t, orders, 105))
+
+	_, _, err = archiver.ArchiveBatch(ctx, 1, cutoff, 2)
+	assert.ErrorContains(t, err, "invalid shard")
+	_, _, err = archiver.ArchiveBatch(ctx, 0, cutoff, 0)
+	assert.ErrorContains(t, err, "invalid batch size")
+}
@@ -0,0 +1,655 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+// MemoryStore holds trade tables in memory, for tests. Its repositories follow the SQL semantics
+// of OrderRepo and RefundRepo, which the contract tests of this package check against MySQL:
+//   - writes that span rows are atomic: when one fails, none of them is applied
+//   - conditional updates check the status and, for orders, the version like their WHERE clauses
+//   - IDs are unique, and create and update times are set by the store with second precision
+//   - rows are copied in and out, so callers never share them with the store
+//   - archived orders and items are moved to separate tables, which reads fall back to
+//
+// Unlike the SQL repositories, they do not join the transactions of database.WithTx.
+type MemoryStore struct {
+	orders           map[int64]*database.TradeOrder
+	orderItems       map[int64]*database.TradeOrderItem
+	archivedOrders   map[int64]*database.TradeOrder
+	archivedItems    map[int64]*database.TradeOrderItem
+	archiveCursor    *OrderCursor // Position of the archive pass, nil at its start
+	refunds          map[int64]*database.TradeRefund
+	refundItems      map[int64]*database.TradeRefundItem
+	events           []*database.OutboxEvent
+	itemOrder        []int64 // Order item IDs in insertion order
+	mu               sync.Mutex
+	nextRefundItemID int64 // Last auto-increment ID of trade_refund_item
+	nextEventID      int64 // Last auto-increment ID of outbox_event
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		orders:         make(map[int64]*database.TradeOrder),
+		orderItems:     make(map[int64]*database.TradeOrderItem),
+		archivedOrders: make(map[int64]*database.TradeOrder),
+		archivedItems:  make(map[int64]*database.TradeOrderItem),
+		refunds:        make(map[int64]*database.TradeRefund),
+		refundItems:    make(map[int64]*database.TradeRefundItem),
+	}
+}
+
+// OrderRepo returns an order repository over the store.
+func (s *MemoryStore) OrderRepo() *MemoryOrderRepo {
+	return &MemoryOrderRepo{store: s}
+}
+
+// OrderArchiver returns an order archiver over the store, with the single shard 0.
+func (s *MemoryStore) OrderArchiver() *MemoryOrderArchiver {
+	return &MemoryOrderArchiver{store: s}
+}
+
+// RefundRepo returns a refund repository over the store.
+func (s *MemoryStore) RefundRepo() *MemoryRefundRepo {
+	return &MemoryRefundRepo{store: s}
+}
+
+// Put stores an order and its items as they are, replacing an order with the same ID, without
+// the checks of CreateOrder. Tests use it to set up orders in any state.
+func (s *MemoryStore) Put(order *database.TradeOrder, items ...*database.TradeOrderItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orders[order.ID] = copyOrder(order)
+	for _, item := range items {
+		copiedItem := *item
+		if _, ok := s.orderItems[item.ID]; !ok {
+			s.itemOrder = append(s.itemOrder, item.ID)
+		}
+		s.orderItems[item.ID] = &copiedItem
+	}
+}
+
+// OutboxEvents returns the outbox events written with orders, oldest first.
+func (s *MemoryStore) OutboxEvents() []*database.OutboxEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := make([]*database.OutboxEvent, 0, len(s.events))
+	for _, event := range s.events {
+		copied := *event
+		events = append(events, &copied)
+	}
+	return events
+}
+
+// now is the time of a write, with the second precision of DATETIME columns.
+func (s *MemoryStore) now() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+// itemsOf returns the items of an order, in insertion order. The caller holds s.mu.
+func (s *MemoryStore) itemsOf(orderID int64) []*database.TradeOrderItem {
+	return s.itemsIn(s.orderItems, orderID)
+}
+
+// itemsIn returns the items of an order in table, in insertion order. The caller holds s.mu.
+func (s *MemoryStore) itemsIn(table map[int64]*database.TradeOrderItem, orderID int64) []*database.TradeOrderItem {
+	var items []*database.TradeOrderItem
+	for _, id := range s.itemOrder {
+		if item, ok := table[id]; ok && item.OrderID == orderID {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// MemoryOrderRepo is an in-memory OrderStore; see MemoryStore.
+type MemoryOrderRepo struct {
+	store *MemoryStore
+}
+
+// CreateOrder creates a new order with items.
+func (r *MemoryOrderRepo) CreateOrder(
+	ctx context.Context,
+	order *database.TradeOrder,
+	items []*database.TradeOrderItem,
+) error {
+	return r.createOrder(ctx, order, items, nil)
+}
+
+// CreateOrderWithEvent creates a new order with items and the outbox event announcing it.
+func (r *MemoryOrderRepo) CreateOrderWithEvent(
+	ctx context.Context,
+	order *database.TradeOrder,
+	items []*database.TradeOrderItem,
+	event *database.OutboxEvent,
+) error {
+	if event == nil {
+		return fmt.Errorf("outbox event cannot be nil")
+	}
+	return r.createOrder(ctx, order, items, event)
+}
+
+// createOrder stores the columns OrderRepo inserts, after checking every row can be inserted.
+func (r *MemoryOrderRepo) createOrder(
+	_ context.Context,
+	order *database.TradeOrder,
+	items []*database.TradeOrderItem,
+	event *database.OutboxEvent,
+) error {
+	for _, item := range items {
+		if item.UserID != order.UserID {
+			return fmt.Errorf("order item %d belongs to user %d, not to user %d of the order",
+				item.ID, item.UserID, order.UserID)
+		}
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.orders[order.ID]; ok {
+		return fmt.Errorf("%w: %d", ErrOrderExists, order.ID)
+	}
+	seen := make(map[int64]bool, len(items))
+	for _, item := range items {
+		if _, ok := s.orderItems[item.ID]; ok || seen[item.ID] {
+			return fmt.Errorf("failed to create order item: duplicate entry %d", item.ID)
+		}
+		seen[item.ID] = true
+	}
+
+	now := s.now()
+	s.orders[order.ID] = &database.TradeOrder{
+		ID: order.ID, UserID: order.UserID, Status: order.Status, TotalAmount: order.TotalAmount,
+		PayAmount: order.PayAmount, PayChannel: copyPtr(order.PayChannel), Version: order.Version,
+		CreateTime: now, UpdateTime: now,
+	}
+	for _, item := range items {
+		s.orderItems[item.ID] = &database.TradeOrderItem{
+			ID: item.ID, OrderID: item.OrderID, UserID: item.UserID, CourseID: item.CourseID,
+			CourseName: item.CourseName, Price: item.Price, RealPayAmount: item.RealPayAmount,
+			RefundStatus: database.ItemRefundStatusNone, CreateTime: now, UpdateTime: now,
+		}
+		s.itemOrder = append(s.itemOrder, item.ID)
+	}
+	if event != nil {
+		s.addEvent(event, now)
+	}
+	return nil
+}
+
+// addEvent stores a pending outbox event, assigning its ID. The caller holds the lock.
+func (s *MemoryStore) addEvent(event *database.OutboxEvent, now time.Time) {
+	s.nextEventID++
+	event.ID = s.nextEventID
+	event.Status = database.OutboxStatusPending
+	copied := *event
+	copied.CreateTime = now
+	s.events = append(s.events, &copied)
+}
+
+// GetByID retrieves an order of a user by ID, falling back to the archive.
+func (r *MemoryOrderRepo) GetByID(_ context.Context, userID, orderID int64) (*database.TradeOrder, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	order, ok := s.orders[orderID]
+	if !ok {
+		order, ok = s.archivedOrders[orderID]
+	}
+	if !ok || order.UserID != userID {
+		return nil, fmt.Errorf("%w: %d", ErrOrderNotFound, orderID)
+	}
+	return copyOrder(order), nil
+}
+
+// ListByUserID retrieves a user's orders, archived ones included, newest first, using keyset
+// pagination.
+func (r *MemoryOrderRepo) ListByUserID(
+	_ context.Context, userID int64, status *int8, after *OrderCursor, limit int,
+) ([]*database.TradeOrder, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var orders []*database.TradeOrder
+	for _, table := range []map[int64]*database.TradeOrder{s.orders, s.archivedOrders} {
+		for _, order := range table {
+			if order.UserID != userID || (status != nil && order.Status != *status) {
+				continue
+			}
+			if after != nil && !order.CreateTime.Before(after.CreateTime) &&
+				!(order.CreateTime.Equal(after.CreateTime) && order.ID < after.ID) {
+				continue
+			}
+			orders = append(orders, copyOrder(order))
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].CreateTime.Equal(orders[j].CreateTime) {
+			return orders[i].CreateTime.After(orders[j].CreateTime)
+		}
+		return orders[i].ID > orders[j].ID
+	})
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+	return orders, nil
+}
+
+// GetItemsByOrderID retrieves the order items of an order of a user, falling back to the archive.
+func (r *MemoryOrderRepo) GetItemsByOrderID(
+	_ context.Context, userID, orderID int64,
+) ([]*database.TradeOrderItem, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := s.itemsOf(orderID)
+	if len(found) == 0 {
+		found = s.itemsIn(s.archivedItems, orderID)
+	}
+	var items []*database.TradeOrderItem
+	for _, item := range found {
+		if item.UserID != userID {
+			continue
+		}
+		copied := *item
+		items = append(items, &copied)
+	}
+	return items, nil
+}
+
+// UpdateStatus updates the status of an order of a user with optimistic lock.
+func (r *MemoryOrderRepo) UpdateStatus(
+	_ context.Context, userID, orderID int64, oldStatus, newStatus int8, version int32,
+) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[orderID]
+	if !ok || order.UserID != userID || order.Status != oldStatus || order.Version != version {
+		return fmt.Errorf(
+			"order status update failed: order not found or version mismatch "+
+				"(id=%d, expected_status=%d, expected_version=%d)",
+			orderID, oldStatus, version)
+	}
+	order.Status = newStatus
+	order.Version++
+	order.UpdateTime = s.now()
+	return nil
+}
+
+// UpdatePayInfo updates payment information. payTime is a time.Time or a *time.Time.
+func (r *MemoryOrderRepo) UpdatePayInfo(
+	_ context.Context, userID, orderID int64, payChannel int8, outTradeNo string, payTime interface{},
+) error {
+	var paidAt *time.Time
+	switch t := payTime.(type) {
+	case time.Time:
+		paidAt = &t
+	case *time.Time:
+		paidAt = t
+	default:
+		return fmt.Errorf("failed to update pay info: unsupported pay time %T", payTime)
+	}
+	if paidAt != nil {
+		truncated := paidAt.Truncate(time.Second)
+		paidAt = &truncated
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[orderID]
+	if !ok || order.UserID != userID || order.Status != database.OrderStatusPendingPayment {
+		return fmt.Errorf("pay info update failed: order not found or status mismatch (id=%d)", orderID)
+	}
+	order.PayChannel = &payChannel
+	order.OutTradeNo = &outTradeNo
+	order.PayTime = paidAt
+	order.Status = database.OrderStatusPaid
+	order.UpdateTime = s.now()
+	return nil
+}
+
+// MemoryOrderArchiver is an in-memory OrderArchiveStore with a single shard; see MemoryStore.
+type MemoryOrderArchiver struct {
+	store *MemoryStore
+}
+
+// Shards returns the number of shards, 1.
+func (a *MemoryOrderArchiver) Shards() int {
+	return 1
+}
+
+// ArchiveBatch archives up to limit orders created and last updated before cutoff, continuing
+// the pass, and returns how many it archived and whether the pass is complete.
+func (a *MemoryOrderArchiver) ArchiveBatch(_ context.Context, shard int, cutoff time.Time, limit int) (int, bool, error) {
+	if shard != 0 {
+		return 0, false, fmt.Errorf("invalid shard: %d", shard)
+	}
+	if limit <= 0 {
+		return 0, false, fmt.Errorf("invalid batch size: %d", limit)
+	}
+
+	s := a.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var candidates []*database.TradeOrder
+	for _, order := range s.orders {
+		if !order.CreateTime.Before(cutoff) || !order.UpdateTime.Before(cutoff) || !isArchivedStatus(order.Status) ||
+			s.refundOpen(order.ID) {
+			continue
+		}
+		if c := s.archiveCursor; c != nil && !order.CreateTime.After(c.CreateTime) &&
+			!(order.CreateTime.Equal(c.CreateTime) && order.ID > c.ID) {
+			continue
+		}
+		candidates = append(candidates, order)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if !candidates[i].CreateTime.Equal(candidates[j].CreateTime) {
+			return candidates[i].CreateTime.Before(candidates[j].CreateTime)
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	for _, order := range candidates {
+		delete(s.orders, order.ID)
+		s.archivedOrders[order.ID] = order
+		for _, item := range s.itemsOf(order.ID) {
+			delete(s.orderItems, item.ID)
+			s.archivedItems[item.ID] = item
+		}
+	}
+
+	done := len(candidates) < limit
+	if done {
+		s.archiveCursor = nil
+	} else {
+		last := candidates[len(candidates)-1]
+		s.archiveCursor = &OrderCursor{CreateTime: last.CreateTime, ID: last.ID}
+	}
+	return len(candidates), done, nil
+}
+
+// refundOpen reports whether a refund of an order is in progress: one of its items is being
+// refunded, or one of its refunds is requested or approved. The caller holds s.mu.
+func (s *MemoryStore) refundOpen(orderID int64) bool {
+	for _, item := range s.itemsOf(orderID) {
+		if item.RefundStatus == database.ItemRefundStatusRefunding {
+			return true
+		}
+	}
+	for _, refund := range s.refunds {
+		if refund.OrderID == orderID &&
+			(refund.Status == database.RefundStatusRequested || refund.Status == database.RefundStatusApproved) {
+			return true
+		}
+	}
+	return false
+}
+
+// isArchivedStatus reports whether an order in status is archived once past retention.
+func isArchivedStatus(status int8) bool {
+	for _, s := range ArchivedStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// MemoryRefundRepo is an in-memory RefundStore; see MemoryStore.
+type MemoryRefundRepo struct {
+	store *MemoryStore
+}
+
+// CreateRefund creates a refund with its items, claiming each refunded order item.
+func (r *MemoryRefundRepo) CreateRefund(
+	_ context.Context,
+	refund *database.TradeRefund,
+	items []*database.TradeRefundItem,
+) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if archived, ok := s.archivedOrders[refund.OrderID]; ok && archived.UserID == refund.UserID {
+		return fmt.Errorf("%w (order_id=%d)", ErrOrderArchived, refund.OrderID)
+	}
+	claimed := make(map[int64]bool, len(items))
+	for _, item := range items {
+		orderItem, ok := s.orderItems[item.OrderItemID]
+		if !ok || orderItem.OrderID != refund.OrderID ||
+			orderItem.RefundStatus != database.ItemRefundStatusNone || claimed[item.OrderItemID] {
+			return fmt.Errorf("%w (order_item_id=%d)", ErrItemNotRefundable, item.OrderItemID)
+		}
+		claimed[item.OrderItemID] = true
+	}
+	if _, ok := s.refunds[refund.ID]; ok {
+		return fmt.Errorf("failed to create refund: duplicate entry %d", refund.ID)
+	}
+
+	now := s.now()
+	for _, item := range items {
+		s.orderItems[item.OrderItemID].RefundStatus = database.ItemRefundStatusRefunding
+		s.orderItems[item.OrderItemID].UpdateTime = now
+	}
+	s.refunds[refund.ID] = &database.TradeRefund{
+		ID: refund.ID, OrderID: refund.OrderID, UserID: refund.UserID, Status: refund.Status,
+		Amount: refund.Amount, Reason: refund.Reason, CreateTime: now, UpdateTime: now,
+	}
+	for _, item := range items {
+		s.nextRefundItemID++
+		s.refundItems[s.nextRefundItemID] = &database.TradeRefundItem{
+			ID: s.nextRefundItemID, RefundID: refund.ID, OrderItemID: item.OrderItemID,
+			CourseID: item.CourseID, Amount: item.Amount, CreateTime: now,
+		}
+	}
+	return nil
+}
+
+// FindByID retrieves a refund by ID alone, for admins.
+func (r *MemoryRefundRepo) FindByID(_ context.Context, refundID int64) (*database.TradeRefund, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refund, ok := s.refunds[refundID]
+	if !ok {
+		return nil, fmt.Errorf("refund not found: %d", refundID)
+	}
+	return copyRefund(refund), nil
+}
+
+// GetByID retrieves a refund of a user by ID.
+func (r *MemoryRefundRepo) GetByID(_ context.Context, userID, refundID int64) (*database.TradeRefund, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refund, ok := s.refunds[refundID]
+	if !ok || refund.UserID != userID {
+		return nil, fmt.Errorf("refund not found: %d", refundID)
+	}
+	return copyRefund(refund), nil
+}
+
+// GetItemsByRefundID retrieves the items of a refund of a user.
+func (r *MemoryRefundRepo) GetItemsByRefundID(
+	_ context.Context, userID, refundID int64,
+) ([]*database.TradeRefundItem, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if refund, ok := s.refunds[refundID]; !ok || refund.UserID != userID {
+		return nil, nil
+	}
+	var items []*database.TradeRefundItem
+	for _, item := range s.refundItems {
+		if item.RefundID == refundID {
+			copied := *item
+			items = append(items, &copied)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items, nil
+}
+
+// UpdateStatus moves a refund of a user from oldStatus to newStatus.
+func (r *MemoryRefundRepo) UpdateStatus(
+	_ context.Context, userID, refundID int64, oldStatus, newStatus int8,
+) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refund, err := r.transition(refundID, oldStatus)
+	if err != nil {
+		return err
+	}
+	if refund.UserID != userID {
+		return refundTransitionError(refundID, oldStatus)
+	}
+	refund.Status = newStatus
+	refund.UpdateTime = s.now()
+	return nil
+}
+
+// MarkSucceeded moves the refund from Approved to Succeeded, marks its order items Refunded, once
+// no item of the order is left unrefunded moves the order to Refunded, and writes the outbox event
+// built by newEvent, if any. It reports whether this refund completed the order.
+func (r *MemoryRefundRepo) MarkSucceeded(
+	_ context.Context, refund *database.TradeRefund, outRefundNo string, newEvent RefundEventFunc,
+) (bool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := r.transition(refund.ID, database.RefundStatusApproved)
+	if err != nil {
+		return false, err
+	}
+	order, orderRefunded := s.orders[refund.OrderID], true
+	if order == nil || (order.Status != database.OrderStatusPaid && order.Status != database.OrderStatusFinished) {
+		orderRefunded = false
+	}
+	for _, item := range s.itemsOf(refund.OrderID) {
+		claimed := item.RefundStatus == database.ItemRefundStatusRefunding && r.claims(refund.ID, item.ID)
+		if item.RefundStatus != database.ItemRefundStatusRefunded && !claimed {
+			orderRefunded = false
+		}
+	}
+	var event *database.OutboxEvent
+	if newEvent != nil {
+		if event, err = newEvent(orderRefunded); err != nil {
+			return false, fmt.Errorf("failed to prepare refund event: %w", err)
+		}
+	}
+
+	// Nothing has changed before this point, so a failure above leaves the refund as it was.
+	now := s.now()
+	stored.Status = database.RefundStatusSucceeded
+	stored.OutRefundNo = &outRefundNo
+	stored.UpdateTime = now
+	r.settleItems(refund.ID, database.ItemRefundStatusRefunded, now)
+	if orderRefunded {
+		order.Status = database.OrderStatusRefunded
+		order.Version++
+		order.UpdateTime = now
+	}
+	if event != nil {
+		s.addEvent(event, now)
+	}
+	return orderRefunded, nil
+}
+
+// MarkFailed moves the refund from Approved to Failed and releases its order items.
+func (r *MemoryRefundRepo) MarkFailed(_ context.Context, refund *database.TradeRefund, failReason string) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := r.transition(refund.ID, database.RefundStatusApproved)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	stored.Status = database.RefundStatusFailed
+	stored.FailReason = &failReason
+	stored.UpdateTime = now
+	r.settleItems(refund.ID, database.ItemRefundStatusNone, now)
+	return nil
+}
+
+// transition returns a refund in oldStatus, about to move out of it. The caller holds the lock.
+func (r *MemoryRefundRepo) transition(refundID int64, oldStatus int8) (*database.TradeRefund, error) {
+	refund, ok := r.store.refunds[refundID]
+	if !ok || refund.Status != oldStatus {
+		return nil, refundTransitionError(refundID, oldStatus)
+	}
+	return refund, nil
+}
+
+// settleItems moves the order items claimed by a refund out of Refunding. The caller holds the lock.
+func (r *MemoryRefundRepo) settleItems(refundID int64, itemStatus int8, now time.Time) {
+	for _, refundItem := range r.store.refundItems {
+		if refundItem.RefundID != refundID {
+			continue
+		}
+		if item, ok := r.store.orderItems[refundItem.OrderItemID]; ok &&
+			item.RefundStatus == database.ItemRefundStatusRefunding {
+			item.RefundStatus = itemStatus
+			item.UpdateTime = now
+		}
+	}
+}
+
+// claims reports whether a refund includes an order item. The caller holds the lock.
+func (r *MemoryRefundRepo) claims(refundID, orderItemID int64) bool {
+	for _, refundItem := range r.store.refundItems {
+		if refundItem.RefundID == refundID && refundItem.OrderItemID == orderItemID {
+			return true
+		}
+	}
+	return false
+}
+
+// copyRefund returns a copy of refund that shares no pointer with it.
+func copyRefund(refund *database.TradeRefund) *database.TradeRefund {
+	copied := *refund
+	copied.OutRefundNo = copyPtr(refund.OutRefundNo)
+	copied.FailReason = copyPtr(refund.FailReason)
+	return &copied
+}
+
+// copyOrder returns a copy of order that shares no pointer with it.
+func copyOrder(order *database.TradeOrder) *database.TradeOrder {
+	copied := *order
+	copied.PayChannel = copyPtr(order.PayChannel)
+	copied.OutTradeNo = copyPtr(order.OutTradeNo)
+	copied.PayTime = copyPtr(order.PayTime)
+	return &copied
+}
+
+// copyPtr returns a copy of an optional column value.
+func copyPtr[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	copied := *v
+	return &copied
+}
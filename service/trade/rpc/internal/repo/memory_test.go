@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+func TestMemoryStore_Contract(t *testing.T) {
+	testStoreContract(t, func(*testing.T) (OrderStore, RefundStore) {
+		store := NewMemoryStore()
+		return store.OrderRepo(), store.RefundRepo()
+	})
+}
+
+func TestMemoryStore_ArchiveContract(t *testing.T) {
+	testArchiveContract(t, func(*testing.T) (OrderStore, RefundStore, OrderArchiveStore) {
+		store := NewMemoryStore()
+		return store.OrderRepo(), store.RefundRepo(), store.OrderArchiver()
+	})
+}
+
+func TestMemoryStore_Put(t *testing.T) {
+	store := NewMemoryStore()
+	orders := store.OrderRepo()
+	order, items := newContractOrder(100, 2)
+	order.Status = database.OrderStatusFinished
+	items[1].RefundStatus = database.ItemRefundStatusRefunded
+	store.Put(order, items...)
+
+	got, err := orders.GetByID(context.Background(), 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.OrderStatusFinished), got.Status)
+	assert.Equal(t, map[int64]int8{
+		items[0].ID: database.ItemRefundStatusNone,
+		items[1].ID: database.ItemRefundStatusRefunded,
+	}, itemRefundStatuses(t, orders, 100))
+
+	// The store keeps its own copy.
+	order.Status = database.OrderStatusClosed
+	got, err = orders.GetByID(context.Background(), 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int8(database.OrderStatusFinished), got.Status)
+}
+
+func TestMemoryStore_OutboxEvents(t *testing.T) {
+	store := NewMemoryStore()
+	order, items := newContractOrder(100, 1)
+	event := &database.OutboxEvent{AggregateType: "order", AggregateID: "100", Topic: "order-events"}
+	require.NoError(t, store.OrderRepo().CreateOrderWithEvent(context.Background(), order, items, event))
+
+	events := store.OutboxEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, event.ID, events[0].ID)
+	assert.Equal(t, "100", events[0].AggregateID)
+}
@@ -4,19 +4,66 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/database/migrations"
 )
 
-// OrderRepo provides data access operations for order domain.
+// ErrOrderNotFound is returned when an order does not exist.
+var ErrOrderNotFound = errors.New("order not found")
+
+// ErrOrderExists is returned when an order with the same ID has already been created.
+var ErrOrderExists = errors.New("order already exists")
+
+// ShardedTables are the trade tables sharded by user_id. They are binding tables: the orders,
+// order items, refunds and refund items of a user live in the same shard, so that transactions
+// spanning them stay in one database.
+var ShardedTables = migrations.ShardedTables[migrations.Trade]
+
+// OrderStore is the persistence of orders. OrderRepo implements it over MySQL, and
+// MemoryOrderRepo in memory for tests.
+type OrderStore interface {
+	CreateOrder(ctx context.Context, order *database.TradeOrder, items []*database.TradeOrderItem) error
+	CreateOrderWithEvent(
+		ctx context.Context,
+		order *database.TradeOrder,
+		items []*database.TradeOrderItem,
+		event *database.OutboxEvent,
+	) error
+	GetByID(ctx context.Context, userID, orderID int64) (*database.TradeOrder, error)
+	ListByUserID(
+		ctx context.Context, userID int64, status *int8, after *OrderCursor, limit int,
+	) ([]*database.TradeOrder, error)
+	GetItemsByOrderID(ctx context.Context, userID, orderID int64) ([]*database.TradeOrderItem, error)
+	UpdateStatus(ctx context.Context, userID, orderID int64, oldStatus, newStatus int8, version int32) error
+	UpdatePayInfo(
+		ctx context.Context, userID, orderID int64, payChannel int8, outTradeNo string, payTime interface{},
+	) error
+}
+
+var (
+	_ OrderStore = (*OrderRepo)(nil)
+	_ OrderStore = (*MemoryOrderRepo)(nil)
+)
+
+// OrderRepo provides data access operations for order domain. Every method runs in the
+// transaction of ctx if any (see database.WithTx).
 type OrderRepo struct {
-	db *sql.DB
+	shards *database.ShardRouter
 }
 
-// NewOrderRepo creates a new OrderRepo instance.
-func NewOrderRepo(db *sql.DB) *OrderRepo {
-	return &OrderRepo{db: db}
+// NewOrderRepo creates a new OrderRepo instance over unsharded tables.
+func NewOrderRepo(client *database.Client) *OrderRepo {
+	return NewShardedOrderRepo(database.NewUnshardedRouter(client))
+}
+
+// NewShardedOrderRepo creates a new OrderRepo instance over the ShardedTables routed by shards.
+// Every query is routed by the user of the order.
+func NewShardedOrderRepo(shards *database.ShardRouter) *OrderRepo {
+	return &OrderRepo{shards: shards}
 }
 
 // CreateOrder creates a new order with items in a transaction.
@@ -25,91 +72,161 @@ func (r *OrderRepo) CreateOrder(
 	order *database.TradeOrder,
 	items []*database.TradeOrderItem,
 ) error {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+	return r.createOrder(ctx, order, items, nil)
+}
+
+// CreateOrderWithEvent creates a new order with items and the outbox event announcing it in one
+// transaction, so that the event is published if and only if the order exists.
+func (r *OrderRepo) CreateOrderWithEvent(
+	ctx context.Context,
+	order *database.TradeOrder,
+	items []*database.TradeOrderItem,
+	event *database.OutboxEvent,
+) error {
+	if event == nil {
+		return fmt.Errorf("outbox event cannot be nil")
 	}
-	defer func() {
+	return r.createOrder(ctx, order, items, event)
+}
+
+// createOrder inserts the order, its items and, if set, an outbox event in a transaction in the
+// shard of the user, or in the transaction of ctx (see database.WithTx) if it runs on the same
+// database. It returns ErrOrderExists, and changes nothing, if the order ID is taken.
+func (r *OrderRepo) createOrder(
+	ctx context.Context,
+	order *database.TradeOrder,
+	items []*database.TradeOrderItem,
+	event *database.OutboxEvent,
+) error {
+	for _, item := range items {
+		if item.UserID != order.UserID {
+			return fmt.Errorf("order item %d belongs to user %d, not to user %d of the order",
+				item.ID, item.UserID, order.UserID)
+		}
+	}
+
+	shard := r.shards.Route(order.UserID)
+	return shard.WithTx(ctx, func(ctx context.Context) error {
+		tx := shard.Executor(ctx)
+
+		orderQuery := `INSERT INTO trade_order
+		               (id, user_id, status, total_amount, pay_amount, pay_channel, version)
+		               VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+		_, err := tx.ExecContext(ctx, orderQuery,
+			order.ID, order.UserID, order.Status, order.TotalAmount, order.PayAmount,
+			order.PayChannel, order.Version)
 		if err != nil {
-			if rollbackErr := tx.Rollback(); rollbackErr != nil {
-				// Log rollback error but don't override original error
-				_ = rollbackErr
+			if database.IsDuplicateKey(err) {
+				return fmt.Errorf("%w: %d", ErrOrderExists, order.ID)
 			}
+			return fmt.Errorf("failed to create order: %w", err)
 		}
-	}()
 
-	// Insert order
-	orderQuery := `INSERT INTO trade_order
-	               (id, user_id, status, total_amount, pay_amount, pay_channel, version)
-	               VALUES (?, ?, ?, ?, ?, ?, ?)`
+		itemQuery := `INSERT INTO trade_order_item
+		              (id, order_id, user_id, course_id, course_name, price, real_pay_amount)
+		              VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-	_, err = tx.ExecContext(ctx, orderQuery,
-		order.ID, order.UserID, order.Status, order.TotalAmount, order.PayAmount,
-		order.PayChannel, order.Version)
-	if err != nil {
-		return fmt.Errorf("failed to create order: %w", err)
-	}
-
-	// Insert order items
-	itemQuery := `INSERT INTO trade_order_item
-	              (id, order_id, user_id, course_id, course_name, price, real_pay_amount)
-	              VALUES (?, ?, ?, ?, ?, ?, ?)`
+		for _, item := range items {
+			_, err = tx.ExecContext(ctx, itemQuery,
+				item.ID, item.OrderID, item.UserID, item.CourseID, item.CourseName,
+				item.Price, item.RealPayAmount)
+			if err != nil {
+				return fmt.Errorf("failed to create order item: %w", err)
+			}
+		}
 
-	for _, item := range items {
-		_, err = tx.ExecContext(ctx, itemQuery,
-			item.ID, item.OrderID, item.UserID, item.CourseID, item.CourseName,
-			item.Price, item.RealPayAmount)
-		if err != nil {
-			return fmt.Errorf("failed to create order item: %w", err)
+		if event != nil {
+			return database.InsertOutboxEvent(ctx, tx, event)
 		}
-	}
+		return nil
+	})
+}
 
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+// orderColumns are the columns of trade_order read into a database.TradeOrder, which
+// trade_order_archive has too.
+const orderColumns = `id, user_id, status, total_amount, pay_amount, pay_channel,
+	                 out_trade_no, pay_time, create_time, update_time, version`
+
+// orderItemColumns are the columns of trade_order_item read into a database.TradeOrderItem, which
+// trade_order_item_archive has too.
+const orderItemColumns = `id, order_id, user_id, course_id, course_name, price, real_pay_amount,
+	                 refund_status, create_time, update_time`
+
+// GetByID retrieves an order of a user by ID, from the archive if it has been archived.
+func (r *OrderRepo) GetByID(ctx context.Context, userID, orderID int64) (*database.TradeOrder, error) {
+	db := r.shards.Route(userID).Executor(ctx)
+	order, err := getByID(ctx, db, "trade_order", userID, orderID)
+	if err == nil && order == nil {
+		order, err = getByID(ctx, db, "trade_order_archive", userID, orderID)
 	}
-
-	return nil
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order == nil {
+		return nil, fmt.Errorf("%w: %d", ErrOrderNotFound, orderID)
+	}
+	return order, nil
 }
 
-// GetByID retrieves an order by ID.
-func (r *OrderRepo) GetByID(ctx context.Context, orderID int64) (*database.TradeOrder, error) {
-	query := `SELECT id, user_id, status, total_amount, pay_amount, pay_channel,
-	                 out_trade_no, pay_time, create_time, update_time, version
-	          FROM trade_order WHERE id = ?`
+// getByID retrieves an order of a user by ID from table, trade_order or trade_order_archive, in
+// db, the shard of the user or its transaction. It returns nil if the order is not there.
+func getByID(
+	ctx context.Context, db database.DBTX, table string, userID, orderID int64,
+) (*database.TradeOrder, error) {
+	query := "SELECT " + orderColumns + " FROM " + table + " WHERE id = ? AND user_id = ?"
 
 	var order database.TradeOrder
-	err := r.db.QueryRowContext(ctx, query, orderID).
+	err := db.QueryRowContext(ctx, query, orderID, userID).
 		Scan(&order.ID, &order.UserID, &order.Status, &order.TotalAmount, &order.PayAmount,
 			&order.PayChannel, &order.OutTradeNo, &order.PayTime,
 			&order.CreateTime, &order.UpdateTime, &order.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("order not found: %d", orderID)
-		}
-		return nil, fmt.Errorf("failed to get order: %w", err)
+		return nil, err
 	}
-
 	return &order, nil
 }
 
-// GetByUserID retrieves orders by user ID with pagination.
-func (r *OrderRepo) GetByUserID(
-	ctx context.Context, userID int64, status *int8, limit, offset int,
+// OrderCursor identifies the last order of a page in (create_time, id) order.
+type OrderCursor struct {
+	CreateTime time.Time
+	ID         int64
+}
+
+// ListByUserID retrieves a user's orders, archived ones included, newest first, using keyset
+// pagination.
+//
+// Orders are ordered by (create_time DESC, id DESC); after, if set, is the last order of
+// the previous page. Unlike LIMIT/OFFSET, the cost of a page does not grow with its depth:
+// the (user_id, create_time, id) index of both tables lets MySQL seek straight to the cursor,
+// and with a status the (user_id, status, create_time) index, which InnoDB implicitly extends
+// with id, does likewise. A page merges the first limit orders of each table.
+func (r *OrderRepo) ListByUserID(
+	ctx context.Context, userID int64, status *int8, after *OrderCursor, limit int,
 ) ([]*database.TradeOrder, error) {
-	query := `SELECT id, user_id, status, total_amount, pay_amount, pay_channel,
-	                 out_trade_no, pay_time, create_time, update_time, version
-	          FROM trade_order WHERE user_id = ?`
+	where := " WHERE user_id = ?"
 	args := []interface{}{userID}
 
 	if status != nil {
-		query += " AND status = ?"
+		where += " AND status = ?"
 		args = append(args, *status)
 	}
 
-	query += " ORDER BY create_time DESC LIMIT ? OFFSET ?"
-	args = append(args, limit, offset)
+	if after != nil {
+		where += " AND (create_time < ? OR (create_time = ? AND id < ?))"
+		args = append(args, after.CreateTime, after.CreateTime, after.ID)
+	}
+
+	page := " ORDER BY create_time DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+	query := "(SELECT " + orderColumns + " FROM trade_order" + where + page + ")" +
+		" UNION ALL (SELECT " + orderColumns + " FROM trade_order_archive" + where + page + ")" + page
+	args = append(append(args, args...), limit)
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.shards.Route(userID).Executor(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query orders: %w", err)
 	}
@@ -138,13 +255,27 @@ func (r *OrderRepo) GetByUserID(
 	return orders, nil
 }
 
-// GetItemsByOrderID retrieves order items by order ID.
-func (r *OrderRepo) GetItemsByOrderID(ctx context.Context, orderID int64) ([]*database.TradeOrderItem, error) {
-	query := `SELECT id, order_id, user_id, course_id, course_name, price, real_pay_amount,
-	                 create_time, update_time
-	          FROM trade_order_item WHERE order_id = ?`
+// GetItemsByOrderID retrieves the order items of an order of a user, from the archive if the
+// order has been archived.
+func (r *OrderRepo) GetItemsByOrderID(
+	ctx context.Context, userID, orderID int64,
+) ([]*database.TradeOrderItem, error) {
+	db := r.shards.Route(userID).Executor(ctx)
+	items, err := getItemsByOrderID(ctx, db, "trade_order_item", userID, orderID)
+	if err == nil && len(items) == 0 {
+		items, err = getItemsByOrderID(ctx, db, "trade_order_item_archive", userID, orderID)
+	}
+	return items, err
+}
+
+// getItemsByOrderID retrieves the order items of an order of a user from table, trade_order_item
+// or trade_order_item_archive, in db, the shard of the user or its transaction.
+func getItemsByOrderID(
+	ctx context.Context, db database.DBTX, table string, userID, orderID int64,
+) ([]*database.TradeOrderItem, error) {
+	query := "SELECT " + orderItemColumns + " FROM " + table + " WHERE order_id = ? AND user_id = ?"
 
-	rows, err := r.db.QueryContext(ctx, query, orderID)
+	rows, err := db.QueryContext(ctx, query, orderID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query order items: %w", err)
 	}
@@ -159,7 +290,7 @@ func (r *OrderRepo) GetItemsByOrderID(ctx context.Context, orderID int64) ([]*da
 		var item database.TradeOrderItem
 		scanErr := rows.Scan(&item.ID, &item.OrderID, &item.UserID, &item.CourseID,
 			&item.CourseName, &item.Price, &item.RealPayAmount,
-			&item.CreateTime, &item.UpdateTime)
+			&item.RefundStatus, &item.CreateTime, &item.UpdateTime)
 		if scanErr != nil {
 			return nil, fmt.Errorf("failed to scan order item: %w", scanErr)
 		}
@@ -173,14 +304,15 @@ func (r *OrderRepo) GetItemsByOrderID(ctx context.Context, orderID int64) ([]*da
 	return items, nil
 }
 
-// UpdateStatus updates order status with optimistic lock.
+// UpdateStatus updates the status of an order of a user with optimistic lock.
 func (r *OrderRepo) UpdateStatus(
-	ctx context.Context, orderID int64, oldStatus, newStatus int8, version int32,
+	ctx context.Context, userID, orderID int64, oldStatus, newStatus int8, version int32,
 ) error {
 	query := `UPDATE trade_order SET status = ?, version = version + 1
-	          WHERE id = ? AND status = ? AND version = ?`
+	          WHERE id = ? AND user_id = ? AND status = ? AND version = ?`
 
-	result, err := r.db.ExecContext(ctx, query, newStatus, orderID, oldStatus, version)
+	result, err := r.shards.Route(userID).Executor(ctx).ExecContext(ctx, query,
+		newStatus, orderID, userID, oldStatus, version)
 	if err != nil {
 		return fmt.Errorf("failed to update order status: %w", err)
 	}
@@ -200,15 +332,15 @@ func (r *OrderRepo) UpdateStatus(
 	return nil
 }
 
-// UpdatePayInfo updates payment information.
+// UpdatePayInfo updates the payment information of an order of a user.
 func (r *OrderRepo) UpdatePayInfo(
-	ctx context.Context, orderID int64, payChannel int8, outTradeNo string, payTime interface{},
+	ctx context.Context, userID, orderID int64, payChannel int8, outTradeNo string, payTime interface{},
 ) error {
 	query := `UPDATE trade_order SET pay_channel = ?, out_trade_no = ?, pay_time = ?, status = ?
-	          WHERE id = ? AND status = ?`
+	          WHERE id = ? AND user_id = ? AND status = ?`
 
-	result, err := r.db.ExecContext(ctx, query, payChannel, outTradeNo, payTime,
-		database.OrderStatusPaid, orderID, database.OrderStatusPendingPayment)
+	result, err := r.shards.Route(userID).Executor(ctx).ExecContext(ctx, query, payChannel, outTradeNo, payTime,
+		database.OrderStatusPaid, orderID, userID, database.OrderStatusPendingPayment)
 	if err != nil {
 		return fmt.Errorf("failed to update pay info: %w", err)
 	}
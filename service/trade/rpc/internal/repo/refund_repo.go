@@ -0,0 +1,348 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/aether-defense-system/common/database"
+)
+
+// ErrItemNotRefundable is returned when an order item is already claimed by another refund.
+var ErrItemNotRefundable = errors.New("order item is already refunded or being refunded")
+
+// ErrOrderArchived is returned when refunding an order that has been archived: its items are no
+// longer updated, so they cannot be claimed.
+var ErrOrderArchived = errors.New("archived orders cannot be refunded")
+
+// RefundEventFunc builds the outbox event announcing a successful refund, given whether the refund
+// completed its order.
+type RefundEventFunc func(orderRefunded bool) (*database.OutboxEvent, error)
+
+// RefundStore is the persistence of refunds. RefundRepo implements it over MySQL, and
+// MemoryRefundRepo in memory for tests.
+type RefundStore interface {
+	CreateRefund(ctx context.Context, refund *database.TradeRefund, items []*database.TradeRefundItem) error
+	FindByID(ctx context.Context, refundID int64) (*database.TradeRefund, error)
+	GetByID(ctx context.Context, userID, refundID int64) (*database.TradeRefund, error)
+	GetItemsByRefundID(ctx context.Context, userID, refundID int64) ([]*database.TradeRefundItem, error)
+	UpdateStatus(ctx context.Context, userID, refundID int64, oldStatus, newStatus int8) error
+	MarkSucceeded(
+		ctx context.Context, refund *database.TradeRefund, outRefundNo string, newEvent RefundEventFunc,
+	) (bool, error)
+	MarkFailed(ctx context.Context, refund *database.TradeRefund, failReason string) error
+}
+
+var (
+	_ RefundStore = (*RefundRepo)(nil)
+	_ RefundStore = (*MemoryRefundRepo)(nil)
+)
+
+// RefundRepo provides data access operations for refund domain. Every method runs in the
+// transaction of ctx if any (see database.WithTx).
+type RefundRepo struct {
+	shards *database.ShardRouter
+}
+
+// NewRefundRepo creates a new RefundRepo instance over unsharded tables.
+func NewRefundRepo(client *database.Client) *RefundRepo {
+	return NewShardedRefundRepo(database.NewUnshardedRouter(client))
+}
+
+// NewShardedRefundRepo creates a new RefundRepo instance over the ShardedTables routed by shards.
+// Queries are routed by the user of the refund, except FindByID, which follows the policy of the
+// router.
+func NewShardedRefundRepo(shards *database.ShardRouter) *RefundRepo {
+	return &RefundRepo{shards: shards}
+}
+
+// CreateRefund creates a refund with its items in a transaction.
+//
+// Each refunded order item is claimed by moving its refund_status from None to Refunding,
+// so two concurrent refunds can never both include the same item. It fails with ErrOrderArchived if the
+// order has been archived.
+func (r *RefundRepo) CreateRefund(
+	ctx context.Context,
+	refund *database.TradeRefund,
+	items []*database.TradeRefundItem,
+) error {
+	shard := r.shards.Route(refund.UserID)
+	return shard.WithTx(ctx, func(ctx context.Context) error {
+		tx := shard.Executor(ctx)
+
+		claimQuery := `UPDATE trade_order_item SET refund_status = ?
+		               WHERE id = ? AND order_id = ? AND refund_status = ?`
+
+		for _, item := range items {
+			result, err := tx.ExecContext(ctx, claimQuery,
+				database.ItemRefundStatusRefunding, item.OrderItemID, refund.OrderID, database.ItemRefundStatusNone)
+			if err != nil {
+				return fmt.Errorf("failed to claim order item: %w", err)
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			if rowsAffected == 0 {
+				return notRefundable(ctx, tx, refund, item.OrderItemID)
+			}
+		}
+
+		refundQuery := `INSERT INTO trade_refund (id, order_id, user_id, status, amount, reason)
+		                VALUES (?, ?, ?, ?, ?, ?)`
+
+		_, err := tx.ExecContext(ctx, refundQuery,
+			refund.ID, refund.OrderID, refund.UserID, refund.Status, refund.Amount, refund.Reason)
+		if err != nil {
+			return fmt.Errorf("failed to create refund: %w", err)
+		}
+
+		itemQuery := `INSERT INTO trade_refund_item (refund_id, order_item_id, course_id, amount)
+		              VALUES (?, ?, ?, ?)`
+
+		for _, item := range items {
+			_, err = tx.ExecContext(ctx, itemQuery, refund.ID, item.OrderItemID, item.CourseID, item.Amount)
+			if err != nil {
+				return fmt.Errorf("failed to create refund item: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// notRefundable returns the error of an order item that could not be claimed: ErrOrderArchived if
+// its order has been archived, and ErrItemNotRefundable otherwise.
+func notRefundable(ctx context.Context, tx database.DBTX, refund *database.TradeRefund, orderItemID int64) error {
+	var archived int
+	err := tx.QueryRowContext(ctx, "SELECT 1 FROM trade_order_archive WHERE id = ? AND user_id = ?",
+		refund.OrderID, refund.UserID).Scan(&archived)
+	switch {
+	case err == nil:
+		return fmt.Errorf("%w (order_id=%d)", ErrOrderArchived, refund.OrderID)
+	case errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("%w (order_item_id=%d)", ErrItemNotRefundable, orderItemID)
+	default:
+		return fmt.Errorf("failed to check archived order: %w", err)
+	}
+}
+
+// refundColumns are the columns of trade_refund read into a database.TradeRefund.
+const refundColumns = `id, order_id, user_id, status, amount, reason, out_refund_no, fail_reason,
+	                 create_time, update_time`
+
+// FindByID retrieves a refund by ID alone, for admins, who do not know its user. The query has no
+// sharding key: it runs on every shard, or fails if the policy of the router rejects that.
+func (r *RefundRepo) FindByID(ctx context.Context, refundID int64) (*database.TradeRefund, error) {
+	query := "SELECT " + refundColumns + " FROM trade_refund WHERE id = ?"
+
+	found, err := database.FanOut(ctx, r.shards,
+		func(ctx context.Context, shard *database.Shard) (*database.TradeRefund, error) {
+			return scanRefund(shard.Executor(ctx).QueryRowContext(ctx, query, refundID))
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+
+	for _, refund := range found {
+		if refund != nil {
+			return refund, nil
+		}
+	}
+	return nil, fmt.Errorf("refund not found: %d", refundID)
+}
+
+// GetByID retrieves a refund of a user by ID.
+func (r *RefundRepo) GetByID(ctx context.Context, userID, refundID int64) (*database.TradeRefund, error) {
+	query := "SELECT " + refundColumns + " FROM trade_refund WHERE id = ? AND user_id = ?"
+
+	refund, err := scanRefund(r.shards.Route(userID).Executor(ctx).QueryRowContext(ctx, query, refundID, userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+	if refund == nil {
+		return nil, fmt.Errorf("refund not found: %d", refundID)
+	}
+	return refund, nil
+}
+
+// scanRefund scans a row of refundColumns. It returns nil if there is no row.
+func scanRefund(row *sql.Row) (*database.TradeRefund, error) {
+	var refund database.TradeRefund
+	err := row.Scan(&refund.ID, &refund.OrderID, &refund.UserID, &refund.Status, &refund.Amount,
+		&refund.Reason, &refund.OutRefundNo, &refund.FailReason,
+		&refund.CreateTime, &refund.UpdateTime)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// GetItemsByRefundID retrieves the items of a refund of a user.
+func (r *RefundRepo) GetItemsByRefundID(
+	ctx context.Context, userID, refundID int64,
+) ([]*database.TradeRefundItem, error) {
+	query := `SELECT i.id, i.refund_id, i.order_item_id, i.course_id, i.amount, i.create_time
+	          FROM trade_refund_item i JOIN trade_refund r ON r.id = i.refund_id
+	          WHERE i.refund_id = ? AND r.user_id = ?`
+
+	rows, err := r.shards.Route(userID).Executor(ctx).QueryContext(ctx, query, refundID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query refund items: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			_ = closeErr
+		}
+	}()
+
+	var items []*database.TradeRefundItem
+	for rows.Next() {
+		var item database.TradeRefundItem
+		scanErr := rows.Scan(&item.ID, &item.RefundID, &item.OrderItemID, &item.CourseID,
+			&item.Amount, &item.CreateTime)
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan refund item: %w", scanErr)
+		}
+		items = append(items, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating refund items: %w", err)
+	}
+
+	return items, nil
+}
+
+// UpdateStatus moves a refund of a user from oldStatus to newStatus.
+// The status condition makes concurrent transitions of the same refund mutually exclusive.
+func (r *RefundRepo) UpdateStatus(ctx context.Context, userID, refundID int64, oldStatus, newStatus int8) error {
+	query := `UPDATE trade_refund SET status = ? WHERE id = ? AND user_id = ? AND status = ?`
+
+	result, err := r.shards.Route(userID).Executor(ctx).ExecContext(ctx, query, newStatus, refundID, userID, oldStatus)
+	if err != nil {
+		return fmt.Errorf("failed to update refund status: %w", err)
+	}
+	return checkRefundTransition(result, refundID, oldStatus)
+}
+
+// MarkSucceeded records a successful gateway refund.
+//
+// In one transaction it moves the refund from Approved to Succeeded, marks its order items
+// Refunded, once no item of the order is left unrefunded moves the order to Refunded, and writes
+// the outbox event built by newEvent, if any. It reports whether this refund completed the order.
+func (r *RefundRepo) MarkSucceeded(
+	ctx context.Context, refund *database.TradeRefund, outRefundNo string, newEvent RefundEventFunc,
+) (bool, error) {
+	var orderRefunded bool
+	shard := r.shards.Route(refund.UserID)
+	err := shard.WithTx(ctx, func(ctx context.Context) error {
+		tx := shard.Executor(ctx)
+
+		refundQuery := `UPDATE trade_refund SET status = ?, out_refund_no = ? WHERE id = ? AND status = ?`
+
+		result, err := tx.ExecContext(ctx, refundQuery,
+			database.RefundStatusSucceeded, outRefundNo, refund.ID, database.RefundStatusApproved)
+		if err != nil {
+			return fmt.Errorf("failed to update refund status: %w", err)
+		}
+		if err = checkRefundTransition(result, refund.ID, database.RefundStatusApproved); err != nil {
+			return err
+		}
+
+		if err = settleRefundItems(ctx, tx, refund.ID, database.ItemRefundStatusRefunded); err != nil {
+			return err
+		}
+
+		orderQuery := `UPDATE trade_order SET status = ?, version = version + 1
+		               WHERE id = ? AND status IN (?, ?)
+		                 AND NOT EXISTS (SELECT 1 FROM trade_order_item
+		                                 WHERE order_id = ? AND refund_status <> ?)`
+
+		result, err = tx.ExecContext(ctx, orderQuery,
+			database.OrderStatusRefunded, refund.OrderID, database.OrderStatusPaid, database.OrderStatusFinished,
+			refund.OrderID, database.ItemRefundStatusRefunded)
+		if err != nil {
+			return fmt.Errorf("failed to update order status: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		orderRefunded = rowsAffected > 0
+
+		if newEvent == nil {
+			return nil
+		}
+		event, err := newEvent(orderRefunded)
+		if err != nil {
+			return fmt.Errorf("failed to prepare refund event: %w", err)
+		}
+		return database.InsertOutboxEvent(ctx, tx, event)
+	})
+	if err != nil {
+		return false, err
+	}
+	return orderRefunded, nil
+}
+
+// MarkFailed records a rejected gateway refund.
+// It moves the refund from Approved to Failed and releases its order items for a later refund.
+func (r *RefundRepo) MarkFailed(ctx context.Context, refund *database.TradeRefund, failReason string) error {
+	shard := r.shards.Route(refund.UserID)
+	return shard.WithTx(ctx, func(ctx context.Context) error {
+		tx := shard.Executor(ctx)
+
+		refundQuery := `UPDATE trade_refund SET status = ?, fail_reason = ? WHERE id = ? AND status = ?`
+
+		result, err := tx.ExecContext(ctx, refundQuery,
+			database.RefundStatusFailed, failReason, refund.ID, database.RefundStatusApproved)
+		if err != nil {
+			return fmt.Errorf("failed to update refund status: %w", err)
+		}
+		if err = checkRefundTransition(result, refund.ID, database.RefundStatusApproved); err != nil {
+			return err
+		}
+
+		return settleRefundItems(ctx, tx, refund.ID, database.ItemRefundStatusNone)
+	})
+}
+
+// settleRefundItems moves the order items claimed by a refund out of Refunding.
+func settleRefundItems(ctx context.Context, tx database.DBTX, refundID int64, itemStatus int8) error {
+	query := `UPDATE trade_order_item SET refund_status = ?
+	          WHERE refund_status = ?
+	            AND id IN (SELECT order_item_id FROM trade_refund_item WHERE refund_id = ?)`
+
+	if _, err := tx.ExecContext(ctx, query, itemStatus, database.ItemRefundStatusRefunding, refundID); err != nil {
+		return fmt.Errorf("failed to update order item refund status: %w", err)
+	}
+
+	return nil
+}
+
+// checkRefundTransition verifies that a conditional refund status update matched a row.
+func checkRefundTransition(result sql.Result, refundID int64, oldStatus int8) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return refundTransitionError(refundID, oldStatus)
+	}
+	return nil
+}
+
+// refundTransitionError is the error of a conditional refund status update that matched no row.
+func refundTransitionError(refundID int64, oldStatus int8) error {
+	return fmt.Errorf(
+		"refund status update failed: refund not found or status mismatch "+
+			"(id=%d, expected_status=%d)",
+		refundID, oldStatus)
+}
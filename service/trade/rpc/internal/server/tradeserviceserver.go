@@ -35,3 +35,51 @@ func (s *TradeServiceServer) CancelOrder(ctx context.Context, in *rpc.CancelOrde
 	l := logic.NewCancelOrderLogic(ctx, s.svcCtx)
 	return l.CancelOrder(in)
 }
+
+// GetOrder returns one order of the requesting user.
+func (s *TradeServiceServer) GetOrder(ctx context.Context, in *rpc.GetOrderRequest) (*rpc.GetOrderResponse, error) {
+	l := logic.NewGetOrderLogic(ctx, s.svcCtx)
+	return l.GetOrder(in)
+}
+
+// ListMyOrders lists the requesting user's orders with cursor pagination.
+func (s *TradeServiceServer) ListMyOrders(ctx context.Context, in *rpc.ListMyOrdersRequest) (*rpc.ListMyOrdersResponse, error) {
+	l := logic.NewListMyOrdersLogic(ctx, s.svcCtx)
+	return l.ListMyOrders(in)
+}
+
+// GetOrderItems returns the items of one order of the requesting user.
+func (s *TradeServiceServer) GetOrderItems(ctx context.Context, in *rpc.GetOrderItemsRequest) (*rpc.GetOrderItemsResponse, error) {
+	l := logic.NewGetOrderItemsLogic(ctx, s.svcCtx)
+	return l.GetOrderItems(in)
+}
+
+// RequestRefund opens a refund for a whole order or selected order items.
+func (s *TradeServiceServer) RequestRefund(ctx context.Context, in *rpc.RequestRefundRequest) (*rpc.RequestRefundResponse, error) {
+	l := logic.NewRequestRefundLogic(ctx, s.svcCtx)
+	return l.RequestRefund(in)
+}
+
+// ApproveRefund approves a refund and pays it out through the payment gateway.
+func (s *TradeServiceServer) ApproveRefund(ctx context.Context, in *rpc.ApproveRefundRequest) (*rpc.ApproveRefundResponse, error) {
+	l := logic.NewApproveRefundLogic(ctx, s.svcCtx)
+	return l.ApproveRefund(in)
+}
+
+// AdminListDeadLetters lists the dead letters of a consumer group with their decoded events.
+func (s *TradeServiceServer) AdminListDeadLetters(ctx context.Context, in *rpc.AdminListDeadLettersRequest) (*rpc.AdminListDeadLettersResponse, error) {
+	l := logic.NewAdminListDeadLettersLogic(ctx, s.svcCtx)
+	return l.AdminListDeadLetters(in)
+}
+
+// AdminReplayDeadLetters replays dead letters to their topic or to the sandbox topic.
+func (s *TradeServiceServer) AdminReplayDeadLetters(ctx context.Context, in *rpc.AdminReplayDeadLettersRequest) (*rpc.AdminReplayDeadLettersResponse, error) {
+	l := logic.NewAdminReplayDeadLettersLogic(ctx, s.svcCtx)
+	return l.AdminReplayDeadLetters(in)
+}
+
+// AdminSearchOrders searches the orders of every user by creation time in the reporting database.
+func (s *TradeServiceServer) AdminSearchOrders(ctx context.Context, in *rpc.AdminSearchOrdersRequest) (*rpc.AdminSearchOrdersResponse, error) {
+	l := logic.NewAdminSearchOrdersLogic(ctx, s.svcCtx)
+	return l.AdminSearchOrders(in)
+}
@@ -2,33 +2,117 @@
 package svc
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/zeromicro/go-zero/zrpc"
 
+	"github.com/aether-defense-system/common/auth"
 	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/database/migrations"
+	"github.com/aether-defense-system/common/deadletter"
+	"github.com/aether-defense-system/common/interceptor"
+	"github.com/aether-defense-system/common/migrate"
 	"github.com/aether-defense-system/common/mq"
+	"github.com/aether-defense-system/common/redis"
+	"github.com/aether-defense-system/common/segment"
+	"github.com/aether-defense-system/common/snowflake"
 	"github.com/aether-defense-system/service/promotion/rpc/promotionservice"
+	"github.com/aether-defense-system/service/trade/rpc"
+	"github.com/aether-defense-system/service/trade/rpc/internal/archive"
 	"github.com/aether-defense-system/service/trade/rpc/internal/config"
+	"github.com/aether-defense-system/service/trade/rpc/internal/ordertx"
+	"github.com/aether-defense-system/service/trade/rpc/internal/payment"
 	"github.com/aether-defense-system/service/trade/rpc/internal/repo"
 	"github.com/aether-defense-system/service/user/rpc/userservice"
 )
 
+// OrderRepository defines the order persistence operations required by trade logic.
+// Every repo.OrderStore satisfies this interface; tests use repo.MemoryStore.
+type OrderRepository interface {
+	CreateOrder(ctx context.Context, order *database.TradeOrder, items []*database.TradeOrderItem) error
+	GetByID(ctx context.Context, userID, orderID int64) (*database.TradeOrder, error)
+	ListByUserID(
+		ctx context.Context, userID int64, status *int8, after *repo.OrderCursor, limit int,
+	) ([]*database.TradeOrder, error)
+	GetItemsByOrderID(ctx context.Context, userID, orderID int64) ([]*database.TradeOrderItem, error)
+	UpdateStatus(ctx context.Context, userID, orderID int64, oldStatus, newStatus int8, version int32) error
+}
+
+// RefundRepository defines the refund persistence operations required by trade logic.
+// Every repo.RefundStore satisfies this interface; tests use repo.MemoryStore.
+type RefundRepository interface {
+	CreateRefund(ctx context.Context, refund *database.TradeRefund, items []*database.TradeRefundItem) error
+	FindByID(ctx context.Context, refundID int64) (*database.TradeRefund, error)
+	GetByID(ctx context.Context, userID, refundID int64) (*database.TradeRefund, error)
+	GetItemsByRefundID(ctx context.Context, userID, refundID int64) ([]*database.TradeRefundItem, error)
+	UpdateStatus(ctx context.Context, userID, refundID int64, oldStatus, newStatus int8) error
+	MarkSucceeded(
+		ctx context.Context, refund *database.TradeRefund, outRefundNo string, newEvent repo.RefundEventFunc,
+	) (bool, error)
+	MarkFailed(ctx context.Context, refund *database.TradeRefund, failReason string) error
+}
+
+// OrderReportRepository defines the reporting database queries required by admin order search.
+// Every database.OrderReportStore satisfies this interface.
+type OrderReportRepository interface {
+	SearchOrders(ctx context.Context, q *database.OrderReportQuery) ([]*database.ReportOrder, error)
+	GetItemsByOrderIDs(ctx context.Context, orderIDs []int64) ([]*database.ReportOrderItem, error)
+}
+
+// workerLeaseTimeout bounds leasing and releasing the snowflake worker ID.
+const workerLeaseTimeout = 10 * time.Second
+
+// AdminMethodPrefix marks RPCs that are denied unless a permission is registered for them.
+// New admin-only RPCs should be named Admin* so they are protected by default.
+const AdminMethodPrefix = "/trade.TradeService/Admin"
+
 // ServiceContext represents the service context for trade RPC service.
 type ServiceContext struct {
-	Config       *config.Config
-	DB           *database.Client
-	OrderRepo    *repo.OrderRepo
-	UserRPC      userservice.UserService
-	PromotionRPC promotionservice.PromotionService
-	RocketMQ     *mq.TransactionProducer
+	Config        *config.Config
+	DB            *database.Client
+	OrderRepo     OrderRepository
+	RefundRepo    RefundRepository
+	RefundIDs     segment.IDGenerator   // Refund IDs from an ID segment; snowflake IDs when nil
+	OrderReports  OrderReportRepository // Orders of every shard by creation time; nil without Reporting
+	UserRPC       userservice.UserService
+	PromotionRPC  promotionservice.PromotionService
+	Payment       payment.Gateway                    // Refund gateway; a fake until a real provider is integrated
+	OrderProducer mq.TransactionProducer             // Created at startup when RocketMQ and the database are configured
+	eventProducer mq.Producer                        // Used by OutboxRelays
+	DeadLetters   *deadletter.Inspector              // Lists and replays dead letters for admins
+	replayer      mq.Producer                        // Used by DeadLetters
+	Permission    *interceptor.PermissionInterceptor // Enforces per-method permissions; registered in main
+	workerLease   *snowflake.WorkerLease             // Worker ID of the default snowflake generator, when leased
+	shards        *database.ShardRouter              // Holds orders and refunds when sharded
+	reportDB      *database.Client                   // Reporting database, when configured
+	sessionRedis  *redis.Client                      // Checked by Permission, when configured
+	Archive       *archive.Job                       // Archives old orders when a retention is configured
+	OutboxRelays  []*mq.OutboxRelay                  // Publish outbox events, one per order database
 }
 
 // NewServiceContext creates a new service context.
 func NewServiceContext(c *config.Config) *ServiceContext {
-	var dbClient *database.Client
-	var orderRepo *repo.OrderRepo
+	// The worker ID is leased before anything can generate an ID with the default generator.
+	var workerLease *snowflake.WorkerLease
+	if c.Snowflake.Enabled() {
+		ctx, cancel := context.WithTimeout(context.Background(), workerLeaseTimeout)
+		lease, err := snowflake.InitializeDefaultFromLease(ctx, &c.Snowflake)
+		cancel()
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize snowflake: %v", err))
+		}
+		workerLease = lease
+	}
 
+	var dbClient *database.Client
+	var orderStore repo.OrderStore
+	var orderRepo OrderRepository
+	var refundRepo RefundRepository
+	var idAllocator *segment.Allocator
+	var shards *database.ShardRouter
 	// Initialize database client if DSN is configured
 	if c.Database.DSN != "" {
 		client, err := database.NewClient(&c.Database)
@@ -36,7 +120,76 @@ func NewServiceContext(c *config.Config) *ServiceContext {
 			panic(fmt.Sprintf("failed to initialize database: %v", err))
 		}
 		dbClient = client
-		orderRepo = repo.NewOrderRepo(client.DB())
+		shards = database.NewUnshardedRouter(client)
+		idAllocator, err = segment.NewAllocator(database.NewIDSegmentStore(client.DB()))
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize ID segment allocator: %v", err))
+		}
+	}
+
+	// Orders and refunds sharded by user_id live in the sharding databases instead.
+	if c.Sharding.Enabled() {
+		if dbClient == nil {
+			panic("sharding needs the database")
+		}
+		router, err := database.NewShardRouter(&c.Sharding, repo.ShardedTables...)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize sharding: %v", err))
+		}
+		shards = router
+	}
+	// Orders and refunds are migrated wherever they live: every sharding database when sharded.
+	if c.AutoMigrate {
+		if dbClient == nil {
+			panic("migrating at startup needs the database")
+		}
+		ctx := context.Background()
+		err := migrations.Up(ctx, []*migrate.Migrator{migrate.New(dbClient.DB())}, migrations.Common)
+		if err == nil {
+			err = migrations.Up(ctx, migrate.ForRouter(shards), migrations.Trade)
+		}
+		if err != nil {
+			panic(fmt.Sprintf("failed to migrate the database: %v", err))
+		}
+	}
+	if shards != nil {
+		orderStore = repo.NewShardedOrderRepo(shards)
+		orderRepo = orderStore
+		refundRepo = repo.NewShardedRefundRepo(shards)
+	}
+
+	// Orders are archived in the databases that hold them, by every instance of the service.
+	var archiveJob *archive.Job
+	if c.Archive.Enabled() {
+		if shards == nil {
+			panic("archiving orders needs the database")
+		}
+		job, err := archive.NewJob(repo.NewOrderArchiver(shards), c.Archive)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize order archival: %v", err))
+		}
+		job.Start()
+		archiveJob = job
+	}
+
+	var refundIDs segment.IDGenerator
+	if c.RefundIDs.Enabled() {
+		if idAllocator == nil {
+			panic("refund IDs from an ID segment need the database")
+		}
+		refundIDs = idAllocator.Generator(c.RefundIDs.BizTag)
+	}
+
+	// The reporting database is written by the CDC service; trade only searches it.
+	var reportDB *database.Client
+	var orderReports OrderReportRepository
+	if c.Reporting.DSN != "" {
+		client, err := database.NewClient(&c.Reporting)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize reporting database: %v", err))
+		}
+		reportDB = client
+		orderReports = database.NewOrderReportStore(client.DB())
 	}
 
 	// Initialize User RPC client
@@ -53,15 +206,184 @@ func NewServiceContext(c *config.Config) *ServiceContext {
 		promotionRPC = promotionservice.NewPromotionService(promotionClient)
 	}
 
-	// RocketMQ producer will be initialized lazily when needed (in PlaceOrder logic)
-	// to allow for dependency injection of transaction executors
+	// The order producer is shared by all requests for the lifetime of the service.
+	// It creates orders, so it is only started when the database is configured.
+	var orderProducer mq.TransactionProducer
+	var eventProducer mq.Producer
+	var outboxRelays []*mq.OutboxRelay
+	if c.RocketMQ.NameServer != "" && dbClient != nil {
+		// Refund events are always written to the outbox, and order events too in outbox mode.
+		producer, err := mq.NewProducer(&c.RocketMQ)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize RocketMQ producer: %v", err))
+		}
+		// Events are written to the database of their order, so each one has a relay.
+		for _, db := range shards.Databases() {
+			relay, err := mq.NewOutboxRelay(database.NewOutboxStore(db), producer, c.OrderEvents.Outbox)
+			if err != nil {
+				panic(fmt.Sprintf("failed to initialize outbox relay: %v", err))
+			}
+			relay.Start()
+			outboxRelays = append(outboxRelays, relay)
+		}
+		eventProducer = producer
+
+		switch mode := c.OrderEvents.GetMode(); mode {
+		case config.OrderEventsModeTransaction:
+			producer, err := mq.NewTransactionProducer(&c.RocketMQ,
+				ordertx.NewExecutor(orderRepo, c.PlaceOrder.GetConfirmTimeout()).Execute,
+				ordertx.NewChecker(orderRepo).Check)
+			if err != nil {
+				panic(fmt.Sprintf("failed to initialize RocketMQ transaction producer: %v", err))
+			}
+			orderProducer = producer
+		case config.OrderEventsModeOutbox:
+			// Orders and their events are written together; the relay publishes the events.
+			orderProducer = ordertx.NewOutboxProducer(orderStore, c.PlaceOrder.GetConfirmTimeout())
+		default:
+			panic(fmt.Sprintf("unknown order events mode: %q", mode))
+		}
+	}
+
+	// Dead letters are read and replayed as the inspection group; replays are audited in the database.
+	var deadLetters *deadletter.Inspector
+	var replayer mq.Producer
+	if c.RocketMQ.NameServer != "" && dbClient != nil {
+		readerCfg := c.DeadLetters.ReaderConfig(c.RocketMQ)
+		reader, err := mq.NewDeadLetterReader(readerCfg)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize dead letter reader: %v", err))
+		}
+		producer, err := mq.NewProducer(readerCfg)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize dead letter replay producer: %v", err))
+		}
+		deadLetters, err = deadletter.NewInspector(c.DeadLetters, reader, producer,
+			database.NewDeadLetterReplayStore(dbClient.DB()),
+			deadletter.WithFailureLog(mq.NewSQLFailureLog(database.NewConsumeFailureStore(dbClient.DB()))))
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize dead letter inspector: %v", err))
+		}
+		replayer = producer
+	}
+
+	// Admin RPCs are authorized with the caller's forwarded access token.
+	// Without an access secret every protected method is rejected rather than left open.
+	var verifier interceptor.TokenVerifier
+	if c.Auth.AccessSecret != "" {
+		tokenVerifier, err := auth.NewTokenVerifier(c.Auth.AccessSecret)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize token verifier: %v", err))
+		}
+		verifier = tokenVerifier
+	}
+	permission := interceptor.NewPermissionInterceptor(auth.DefaultPolicy(), verifier, AdminMethodPrefix).
+		Require(rpc.TradeService_ApproveRefund_FullMethodName, auth.PermOrderRefund).
+		Require(rpc.TradeService_AdminListDeadLetters_FullMethodName, auth.PermDeadLetterRead).
+		Require(rpc.TradeService_AdminReplayDeadLetters_FullMethodName, auth.PermDeadLetterReplay).
+		Require(rpc.TradeService_AdminSearchOrders_FullMethodName, auth.PermOrderRead)
+	// Tokens of force-logged-out admins are rejected like at the gateways.
+	var sessionRedis *redis.Client
+	if c.SessionRedis.Addr != "" || c.SessionRedis.Host != "" {
+		redisClient, err := redis.NewClient(&c.SessionRedis)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize session Redis: %v", err))
+		}
+		// Trade only checks sessions; user-rpc creates and refreshes them.
+		sessions, err := auth.NewSessionStore(redisClient, 0)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize session store: %v", err))
+		}
+		permission.WithSessions(sessions)
+		sessionRedis = redisClient
+	}
 
 	return &ServiceContext{
-		Config:       c,
-		DB:           dbClient,
-		OrderRepo:    orderRepo,
-		UserRPC:      userRPC,
-		PromotionRPC: promotionRPC,
-		RocketMQ:     nil, // Will be set when transaction producer is created
+		Config:        c,
+		DB:            dbClient,
+		OrderRepo:     orderRepo,
+		RefundRepo:    refundRepo,
+		RefundIDs:     refundIDs,
+		OrderReports:  orderReports,
+		UserRPC:       userRPC,
+		PromotionRPC:  promotionRPC,
+		Payment:       payment.NewFakeGateway(),
+		OrderProducer: orderProducer,
+		OutboxRelays:  outboxRelays,
+		Archive:       archiveJob,
+		eventProducer: eventProducer,
+		DeadLetters:   deadLetters,
+		replayer:      replayer,
+		Permission:    permission,
+		workerLease:   workerLease,
+		shards:        shards,
+		reportDB:      reportDB,
+		sessionRedis:  sessionRedis,
+	}
+}
+
+// NextRefundID returns the ID of a new refund.
+func (s *ServiceContext) NextRefundID() (int64, error) {
+	if s.RefundIDs == nil {
+		return snowflake.Next()
+	}
+	return s.RefundIDs.Next()
+}
+
+// Close releases the resources owned by the service context. It is called during graceful stop,
+// after the server has stopped accepting requests.
+func (s *ServiceContext) Close() error {
+	var errs []error
+	// Stop the relays first so that they do not publish through a producer being shut down.
+	for _, relay := range s.OutboxRelays {
+		relay.Stop()
+	}
+	// Stop archiving before the databases are closed under the batch in progress.
+	if s.Archive != nil {
+		s.Archive.Stop()
+	}
+	if s.eventProducer != nil {
+		if err := s.eventProducer.Shutdown(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to shut down event producer: %w", err))
+		}
+	}
+	if s.replayer != nil {
+		if err := s.replayer.Shutdown(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to shut down dead letter replay producer: %w", err))
+		}
+	}
+	if s.OrderProducer != nil {
+		if err := s.OrderProducer.Shutdown(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to shut down order producer: %w", err))
+		}
+	}
+	if s.shards != nil {
+		if err := s.shards.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close sharding databases: %w", err))
+		}
+	}
+	if s.DB != nil {
+		if err := s.DB.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close database: %w", err))
+		}
+	}
+	if s.reportDB != nil {
+		if err := s.reportDB.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close reporting database: %w", err))
+		}
+	}
+	if s.sessionRedis != nil {
+		if err := s.sessionRedis.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close session Redis: %w", err))
+		}
+	}
+	// Released last: no more IDs are generated once the worker ID may be leased by another process.
+	if s.workerLease != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), workerLeaseTimeout)
+		defer cancel()
+		if err := s.workerLease.Release(ctx); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errors.Join(errs...)
 }
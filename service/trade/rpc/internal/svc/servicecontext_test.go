@@ -1,8 +1,12 @@
 package svc
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/mq"
 	"github.com/aether-defense-system/service/trade/rpc/internal/config"
 )
 
@@ -16,4 +20,77 @@ func TestNewServiceContext(t *testing.T) {
 	if ctx.Config != cfg {
 		t.Fatalf("expected Config pointer to be preserved")
 	}
+	// Without a DSN no repository is created; a nil interface (not a typed nil) lets logic detect it.
+	if ctx.RefundRepo != nil {
+		t.Fatalf("expected RefundRepo to be nil when database is not configured")
+	}
+	if ctx.Payment == nil {
+		t.Fatalf("expected a payment gateway to be configured")
+	}
+	if ctx.Permission == nil {
+		t.Fatalf("expected permission interceptor to be configured")
+	}
+	// The producer's local transaction needs the database, so it is not started without one.
+	if ctx.OrderProducer != nil {
+		t.Fatalf("expected order producer to be nil when database is not configured")
+	}
+	if err := ctx.Close(); err != nil {
+		t.Fatalf("expected Close to succeed, got %v", err)
+	}
+}
+
+// fakeProducer records whether it was shut down.
+type fakeProducer struct {
+	mq.TransactionProducer
+	shutdownErr error
+	shutdown    bool
+}
+
+func (f *fakeProducer) Shutdown() error {
+	f.shutdown = true
+	return f.shutdownErr
+}
+
+func TestServiceContext_Close(t *testing.T) {
+	producer := &fakeProducer{}
+	ctx := &ServiceContext{OrderProducer: producer}
+	if err := ctx.Close(); err != nil {
+		t.Fatalf("expected Close to succeed, got %v", err)
+	}
+	if !producer.shutdown {
+		t.Fatalf("expected producer to be shut down")
+	}
+
+	producer = &fakeProducer{shutdownErr: errors.New("broker unreachable")}
+	ctx = &ServiceContext{OrderProducer: producer}
+	if err := ctx.Close(); err == nil || !strings.Contains(err.Error(), "broker unreachable") {
+		t.Fatalf("expected shutdown error to be returned, got %v", err)
+	}
+}
+
+// fakeEventProducer records whether it was shut down.
+type fakeEventProducer struct {
+	mq.Producer
+	shutdown bool
+}
+
+func (f *fakeEventProducer) Shutdown() error {
+	f.shutdown = true
+	return nil
+}
+
+func TestServiceContext_Close_Outbox(t *testing.T) {
+	events := &fakeEventProducer{}
+	relay, err := mq.NewOutboxRelay(database.NewOutboxStore(nil), events, mq.OutboxConfig{})
+	if err != nil {
+		t.Fatalf("failed to create relay: %v", err)
+	}
+
+	ctx := &ServiceContext{OrderProducer: &fakeProducer{}, OutboxRelays: []*mq.OutboxRelay{relay}, eventProducer: events}
+	if err := ctx.Close(); err != nil {
+		t.Fatalf("expected Close to succeed, got %v", err)
+	}
+	if !events.shutdown {
+		t.Fatalf("expected event producer to be shut down")
+	}
 }
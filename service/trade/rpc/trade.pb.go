@@ -21,6 +21,106 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Outcome of the local transaction that creates the order
+type PlaceOrderOutcome int32
+
+const (
+	PlaceOrderOutcome_PLACE_ORDER_OUTCOME_UNSPECIFIED PlaceOrderOutcome = 0
+	PlaceOrderOutcome_PLACE_ORDER_OUTCOME_COMMITTED   PlaceOrderOutcome = 1 // Order created, order message committed
+	PlaceOrderOutcome_PLACE_ORDER_OUTCOME_ROLLED_BACK PlaceOrderOutcome = 2 // Order not created, order message discarded
+	PlaceOrderOutcome_PLACE_ORDER_OUTCOME_PENDING     PlaceOrderOutcome = 3 // Not known within the wait; poll GetOrder
+)
+
+// Enum value maps for PlaceOrderOutcome.
+var (
+	PlaceOrderOutcome_name = map[int32]string{
+		0: "PLACE_ORDER_OUTCOME_UNSPECIFIED",
+		1: "PLACE_ORDER_OUTCOME_COMMITTED",
+		2: "PLACE_ORDER_OUTCOME_ROLLED_BACK",
+		3: "PLACE_ORDER_OUTCOME_PENDING",
+	}
+	PlaceOrderOutcome_value = map[string]int32{
+		"PLACE_ORDER_OUTCOME_UNSPECIFIED": 0,
+		"PLACE_ORDER_OUTCOME_COMMITTED":   1,
+		"PLACE_ORDER_OUTCOME_ROLLED_BACK": 2,
+		"PLACE_ORDER_OUTCOME_PENDING":     3,
+	}
+)
+
+func (x PlaceOrderOutcome) Enum() *PlaceOrderOutcome {
+	p := new(PlaceOrderOutcome)
+	*p = x
+	return p
+}
+
+func (x PlaceOrderOutcome) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PlaceOrderOutcome) Descriptor() protoreflect.EnumDescriptor {
+	return file_service_trade_rpc_trade_proto_enumTypes[0].Descriptor()
+}
+
+func (PlaceOrderOutcome) Type() protoreflect.EnumType {
+	return &file_service_trade_rpc_trade_proto_enumTypes[0]
+}
+
+func (x PlaceOrderOutcome) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PlaceOrderOutcome.Descriptor instead.
+func (PlaceOrderOutcome) EnumDescriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{0}
+}
+
+// Where dead letters are replayed to
+type ReplayTarget int32
+
+const (
+	ReplayTarget_REPLAY_TARGET_ORIGIN  ReplayTarget = 0 // The topic the message was dead-lettered from
+	ReplayTarget_REPLAY_TARGET_SANDBOX ReplayTarget = 1 // The sandbox topic, for a dry run
+)
+
+// Enum value maps for ReplayTarget.
+var (
+	ReplayTarget_name = map[int32]string{
+		0: "REPLAY_TARGET_ORIGIN",
+		1: "REPLAY_TARGET_SANDBOX",
+	}
+	ReplayTarget_value = map[string]int32{
+		"REPLAY_TARGET_ORIGIN":  0,
+		"REPLAY_TARGET_SANDBOX": 1,
+	}
+)
+
+func (x ReplayTarget) Enum() *ReplayTarget {
+	p := new(ReplayTarget)
+	*p = x
+	return p
+}
+
+func (x ReplayTarget) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReplayTarget) Descriptor() protoreflect.EnumDescriptor {
+	return file_service_trade_rpc_trade_proto_enumTypes[1].Descriptor()
+}
+
+func (ReplayTarget) Type() protoreflect.EnumType {
+	return &file_service_trade_rpc_trade_proto_enumTypes[1]
+}
+
+func (x ReplayTarget) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReplayTarget.Descriptor instead.
+func (ReplayTarget) EnumDescriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{1}
+}
+
 // Request Parameters
 type PlaceOrderRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -101,9 +201,11 @@ func (x *PlaceOrderRequest) GetRealAmount() int32 {
 // Response Parameters
 type PlaceOrderResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	OrderId       int64                  `protobuf:"varint,1,opt,name=orderId,proto3" json:"orderId,omitempty"`     // Order ID
-	PayAmount     int32                  `protobuf:"varint,2,opt,name=payAmount,proto3" json:"payAmount,omitempty"` // Actual payment amount (in cents)
-	Status        int32                  `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`       // Order status (1: Pending Payment)
+	OrderId       int64                  `protobuf:"varint,1,opt,name=orderId,proto3" json:"orderId,omitempty"`                              // Order ID
+	PayAmount     int32                  `protobuf:"varint,2,opt,name=payAmount,proto3" json:"payAmount,omitempty"`                          // Actual payment amount (in cents)
+	Status        int32                  `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`                                // Order status (1: Pending Payment), 0 unless committed
+	Outcome       PlaceOrderOutcome      `protobuf:"varint,4,opt,name=outcome,proto3,enum=trade.PlaceOrderOutcome" json:"outcome,omitempty"` // Whether the order was created
+	Reason        string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`                                 // Why the order was rolled back or is still pending
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -159,6 +261,20 @@ func (x *PlaceOrderResponse) GetStatus() int32 {
 	return 0
 }
 
+func (x *PlaceOrderResponse) GetOutcome() PlaceOrderOutcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return PlaceOrderOutcome_PLACE_ORDER_OUTCOME_UNSPECIFIED
+}
+
+func (x *PlaceOrderResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
 // Cancel Order Request Parameters
 type CancelOrderRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -273,34 +389,1766 @@ func (x *CancelOrderResponse) GetSuccess() bool {
 	return false
 }
 
-var File_service_trade_rpc_trade_proto protoreflect.FileDescriptor
+// Order summary returned by query interfaces
+type OrderInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=orderId,proto3" json:"orderId,omitempty"`         // Order ID
+	UserId        int64                  `protobuf:"varint,2,opt,name=userId,proto3" json:"userId,omitempty"`           // Owner user ID
+	Status        int32                  `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`           // Order status (1: Pending Payment, 2: Closed, 3: Paid, 4: Finished, 5: Refunded)
+	TotalAmount   int32                  `protobuf:"varint,4,opt,name=totalAmount,proto3" json:"totalAmount,omitempty"` // Total amount (cents)
+	PayAmount     int32                  `protobuf:"varint,5,opt,name=payAmount,proto3" json:"payAmount,omitempty"`     // Actual payment amount (cents)
+	PayChannel    int32                  `protobuf:"varint,6,opt,name=payChannel,proto3" json:"payChannel,omitempty"`   // Payment channel (0: unpaid, 1: Alipay, 2: WeChat)
+	OutTradeNo    string                 `protobuf:"bytes,7,opt,name=outTradeNo,proto3" json:"outTradeNo,omitempty"`    // Third-party transaction number, empty if unpaid
+	PayTime       int64                  `protobuf:"varint,8,opt,name=payTime,proto3" json:"payTime,omitempty"`         // Payment time (unix seconds), 0 if unpaid
+	CreateTime    int64                  `protobuf:"varint,9,opt,name=createTime,proto3" json:"createTime,omitempty"`   // Creation time (unix seconds)
+	UpdateTime    int64                  `protobuf:"varint,10,opt,name=updateTime,proto3" json:"updateTime,omitempty"`  // Last update time (unix seconds)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_service_trade_rpc_trade_proto_rawDesc = "" +
-	"\n" +
-	"\x1dservice/trade/rpc/trade.proto\x12\x05trade\"\xa1\x01\n" +
-	"\x11PlaceOrderRequest\x12\x16\n" +
-	"\x06userId\x18\x01 \x01(\x03R\x06userId\x12\x1c\n" +
-	"\tcourseIds\x18\x02 \x03(\x03R\tcourseIds\x12\x1c\n" +
-	"\tcouponIds\x18\x03 \x03(\x03R\tcouponIds\x12\x18\n" +
-	"\aorderId\x18\x04 \x01(\x03R\aorderId\x12\x1e\n" +
-	"\n" +
-	"realAmount\x18\x05 \x01(\x05R\n" +
-	"realAmount\"d\n" +
-	"\x12PlaceOrderResponse\x12\x18\n" +
-	"\aorderId\x18\x01 \x01(\x03R\aorderId\x12\x1c\n" +
-	"\tpayAmount\x18\x02 \x01(\x05R\tpayAmount\x12\x16\n" +
-	"\x06status\x18\x03 \x01(\x05R\x06status\"F\n" +
-	"\x12CancelOrderRequest\x12\x16\n" +
-	"\x06userId\x18\x01 \x01(\x03R\x06userId\x12\x18\n" +
-	"\aorderId\x18\x02 \x01(\x03R\aorderId\"a\n" +
-	"\x13CancelOrderResponse\x12\x18\n" +
-	"\aorderId\x18\x01 \x01(\x03R\aorderId\x12\x16\n" +
-	"\x06status\x18\x02 \x01(\x05R\x06status\x12\x18\n" +
-	"\asuccess\x18\x03 \x01(\bR\asuccess2\x97\x01\n" +
+func (x *OrderInfo) Reset() {
+	*x = OrderInfo{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderInfo) ProtoMessage() {}
+
+func (x *OrderInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderInfo.ProtoReflect.Descriptor instead.
+func (*OrderInfo) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OrderInfo) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderInfo) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *OrderInfo) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *OrderInfo) GetTotalAmount() int32 {
+	if x != nil {
+		return x.TotalAmount
+	}
+	return 0
+}
+
+func (x *OrderInfo) GetPayAmount() int32 {
+	if x != nil {
+		return x.PayAmount
+	}
+	return 0
+}
+
+func (x *OrderInfo) GetPayChannel() int32 {
+	if x != nil {
+		return x.PayChannel
+	}
+	return 0
+}
+
+func (x *OrderInfo) GetOutTradeNo() string {
+	if x != nil {
+		return x.OutTradeNo
+	}
+	return ""
+}
+
+func (x *OrderInfo) GetPayTime() int64 {
+	if x != nil {
+		return x.PayTime
+	}
+	return 0
+}
+
+func (x *OrderInfo) GetCreateTime() int64 {
+	if x != nil {
+		return x.CreateTime
+	}
+	return 0
+}
+
+func (x *OrderInfo) GetUpdateTime() int64 {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return 0
+}
+
+// Order item snapshot returned by query interfaces
+type OrderItemInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ItemId        int64                  `protobuf:"varint,1,opt,name=itemId,proto3" json:"itemId,omitempty"`               // Order item ID
+	OrderId       int64                  `protobuf:"varint,2,opt,name=orderId,proto3" json:"orderId,omitempty"`             // Order ID
+	CourseId      int64                  `protobuf:"varint,3,opt,name=courseId,proto3" json:"courseId,omitempty"`           // Course ID
+	CourseName    string                 `protobuf:"bytes,4,opt,name=courseName,proto3" json:"courseName,omitempty"`        // Course name at purchase time
+	Price         int32                  `protobuf:"varint,5,opt,name=price,proto3" json:"price,omitempty"`                 // Unit price at purchase time (cents)
+	RealPayAmount int32                  `protobuf:"varint,6,opt,name=realPayAmount,proto3" json:"realPayAmount,omitempty"` // Allocated payment amount (cents)
+	RefundStatus  int32                  `protobuf:"varint,7,opt,name=refundStatus,proto3" json:"refundStatus,omitempty"`   // Refund status (0: None, 1: Refunding, 2: Refunded)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderItemInfo) Reset() {
+	*x = OrderItemInfo{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderItemInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderItemInfo) ProtoMessage() {}
+
+func (x *OrderItemInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderItemInfo.ProtoReflect.Descriptor instead.
+func (*OrderItemInfo) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *OrderItemInfo) GetItemId() int64 {
+	if x != nil {
+		return x.ItemId
+	}
+	return 0
+}
+
+func (x *OrderItemInfo) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderItemInfo) GetCourseId() int64 {
+	if x != nil {
+		return x.CourseId
+	}
+	return 0
+}
+
+func (x *OrderItemInfo) GetCourseName() string {
+	if x != nil {
+		return x.CourseName
+	}
+	return ""
+}
+
+func (x *OrderItemInfo) GetPrice() int32 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *OrderItemInfo) GetRealPayAmount() int32 {
+	if x != nil {
+		return x.RealPayAmount
+	}
+	return 0
+}
+
+func (x *OrderItemInfo) GetRefundStatus() int32 {
+	if x != nil {
+		return x.RefundStatus
+	}
+	return 0
+}
+
+// Get Order Request Parameters
+type GetOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=userId,proto3" json:"userId,omitempty"`   // User ID, parsed from JWT Token
+	OrderId       int64                  `protobuf:"varint,2,opt,name=orderId,proto3" json:"orderId,omitempty"` // Order ID to query
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderRequest) Reset() {
+	*x = GetOrderRequest{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderRequest) ProtoMessage() {}
+
+func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderRequest) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetOrderRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *GetOrderRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+// Get Order Response Parameters
+type GetOrderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Order         *OrderInfo             `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderResponse) Reset() {
+	*x = GetOrderResponse{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderResponse) ProtoMessage() {}
+
+func (x *GetOrderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderResponse) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetOrderResponse) GetOrder() *OrderInfo {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+// List My Orders Request Parameters
+type ListMyOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=userId,proto3" json:"userId,omitempty"` // User ID, parsed from JWT Token
+	Status        int32                  `protobuf:"varint,2,opt,name=status,proto3" json:"status,omitempty"` // Order status filter, 0 for all statuses
+	Cursor        string                 `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`  // Opaque cursor from the previous page, empty for the first page
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`   // Page size, defaults to 20, maximum 100
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMyOrdersRequest) Reset() {
+	*x = ListMyOrdersRequest{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMyOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMyOrdersRequest) ProtoMessage() {}
+
+func (x *ListMyOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMyOrdersRequest.ProtoReflect.Descriptor instead.
+func (*ListMyOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListMyOrdersRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ListMyOrdersRequest) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *ListMyOrdersRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListMyOrdersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// List My Orders Response Parameters
+type ListMyOrdersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*OrderInfo           `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`         // Orders, newest first
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=nextCursor,proto3" json:"nextCursor,omitempty"` // Cursor for the next page, empty when there are no more orders
+	HasMore       bool                   `protobuf:"varint,3,opt,name=hasMore,proto3" json:"hasMore,omitempty"`      // Whether more orders exist after this page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMyOrdersResponse) Reset() {
+	*x = ListMyOrdersResponse{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMyOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMyOrdersResponse) ProtoMessage() {}
+
+func (x *ListMyOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMyOrdersResponse.ProtoReflect.Descriptor instead.
+func (*ListMyOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListMyOrdersResponse) GetOrders() []*OrderInfo {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *ListMyOrdersResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *ListMyOrdersResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+// Get Order Items Request Parameters
+type GetOrderItemsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=userId,proto3" json:"userId,omitempty"`   // User ID, parsed from JWT Token
+	OrderId       int64                  `protobuf:"varint,2,opt,name=orderId,proto3" json:"orderId,omitempty"` // Order ID to query
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderItemsRequest) Reset() {
+	*x = GetOrderItemsRequest{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderItemsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderItemsRequest) ProtoMessage() {}
+
+func (x *GetOrderItemsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderItemsRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderItemsRequest) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetOrderItemsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *GetOrderItemsRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+// Get Order Items Response Parameters
+type GetOrderItemsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=orderId,proto3" json:"orderId,omitempty"` // Order ID
+	Items         []*OrderItemInfo       `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderItemsResponse) Reset() {
+	*x = GetOrderItemsResponse{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderItemsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderItemsResponse) ProtoMessage() {}
+
+func (x *GetOrderItemsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderItemsResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderItemsResponse) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetOrderItemsResponse) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *GetOrderItemsResponse) GetItems() []*OrderItemInfo {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// Refunded order item
+type RefundItemInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderItemId   int64                  `protobuf:"varint,1,opt,name=orderItemId,proto3" json:"orderItemId,omitempty"` // Order item ID
+	CourseId      int64                  `protobuf:"varint,2,opt,name=courseId,proto3" json:"courseId,omitempty"`       // Course ID
+	Amount        int32                  `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`           // Refunded amount (cents), the item's realPayAmount
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundItemInfo) Reset() {
+	*x = RefundItemInfo{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundItemInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundItemInfo) ProtoMessage() {}
+
+func (x *RefundItemInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundItemInfo.ProtoReflect.Descriptor instead.
+func (*RefundItemInfo) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RefundItemInfo) GetOrderItemId() int64 {
+	if x != nil {
+		return x.OrderItemId
+	}
+	return 0
+}
+
+func (x *RefundItemInfo) GetCourseId() int64 {
+	if x != nil {
+		return x.CourseId
+	}
+	return 0
+}
+
+func (x *RefundItemInfo) GetAmount() int32 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+// Refund returned by refund interfaces
+type RefundInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RefundId      int64                  `protobuf:"varint,1,opt,name=refundId,proto3" json:"refundId,omitempty"`      // Refund ID
+	OrderId       int64                  `protobuf:"varint,2,opt,name=orderId,proto3" json:"orderId,omitempty"`        // Refunded order ID
+	UserId        int64                  `protobuf:"varint,3,opt,name=userId,proto3" json:"userId,omitempty"`          // Owner user ID
+	Status        int32                  `protobuf:"varint,4,opt,name=status,proto3" json:"status,omitempty"`          // Refund status (1: Requested, 2: Approved, 3: Succeeded, 4: Failed)
+	Amount        int32                  `protobuf:"varint,5,opt,name=amount,proto3" json:"amount,omitempty"`          // Refund amount (cents)
+	Reason        string                 `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`           // Refund reason
+	OutRefundNo   string                 `protobuf:"bytes,7,opt,name=outRefundNo,proto3" json:"outRefundNo,omitempty"` // Third-party refund number, empty until succeeded
+	FailReason    string                 `protobuf:"bytes,8,opt,name=failReason,proto3" json:"failReason,omitempty"`   // Payment gateway failure reason, empty unless failed
+	Items         []*RefundItemInfo      `protobuf:"bytes,9,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundInfo) Reset() {
+	*x = RefundInfo{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundInfo) ProtoMessage() {}
+
+func (x *RefundInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundInfo.ProtoReflect.Descriptor instead.
+func (*RefundInfo) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RefundInfo) GetRefundId() int64 {
+	if x != nil {
+		return x.RefundId
+	}
+	return 0
+}
+
+func (x *RefundInfo) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *RefundInfo) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *RefundInfo) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *RefundInfo) GetAmount() int32 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RefundInfo) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RefundInfo) GetOutRefundNo() string {
+	if x != nil {
+		return x.OutRefundNo
+	}
+	return ""
+}
+
+func (x *RefundInfo) GetFailReason() string {
+	if x != nil {
+		return x.FailReason
+	}
+	return ""
+}
+
+func (x *RefundInfo) GetItems() []*RefundItemInfo {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// Request Refund Request Parameters
+type RequestRefundRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=userId,proto3" json:"userId,omitempty"`                    // User ID, parsed from JWT Token
+	OrderId       int64                  `protobuf:"varint,2,opt,name=orderId,proto3" json:"orderId,omitempty"`                  // Order ID to refund
+	OrderItemIds  []int64                `protobuf:"varint,3,rep,packed,name=orderItemIds,proto3" json:"orderItemIds,omitempty"` // Order items to refund, empty for every remaining item
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`                     // Refund reason
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestRefundRequest) Reset() {
+	*x = RequestRefundRequest{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestRefundRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestRefundRequest) ProtoMessage() {}
+
+func (x *RequestRefundRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestRefundRequest.ProtoReflect.Descriptor instead.
+func (*RequestRefundRequest) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RequestRefundRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *RequestRefundRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *RequestRefundRequest) GetOrderItemIds() []int64 {
+	if x != nil {
+		return x.OrderItemIds
+	}
+	return nil
+}
+
+func (x *RequestRefundRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Request Refund Response Parameters
+type RequestRefundResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Refund        *RefundInfo            `protobuf:"bytes,1,opt,name=refund,proto3" json:"refund,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestRefundResponse) Reset() {
+	*x = RequestRefundResponse{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestRefundResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestRefundResponse) ProtoMessage() {}
+
+func (x *RequestRefundResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestRefundResponse.ProtoReflect.Descriptor instead.
+func (*RequestRefundResponse) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RequestRefundResponse) GetRefund() *RefundInfo {
+	if x != nil {
+		return x.Refund
+	}
+	return nil
+}
+
+// Approve Refund Request Parameters
+type ApproveRefundRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RefundId      int64                  `protobuf:"varint,1,opt,name=refundId,proto3" json:"refundId,omitempty"` // Refund ID to approve
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveRefundRequest) Reset() {
+	*x = ApproveRefundRequest{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveRefundRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveRefundRequest) ProtoMessage() {}
+
+func (x *ApproveRefundRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveRefundRequest.ProtoReflect.Descriptor instead.
+func (*ApproveRefundRequest) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ApproveRefundRequest) GetRefundId() int64 {
+	if x != nil {
+		return x.RefundId
+	}
+	return 0
+}
+
+// Approve Refund Response Parameters
+type ApproveRefundResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Refund        *RefundInfo            `protobuf:"bytes,1,opt,name=refund,proto3" json:"refund,omitempty"` // Refund after the payment gateway call (3: Succeeded or 4: Failed)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveRefundResponse) Reset() {
+	*x = ApproveRefundResponse{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveRefundResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveRefundResponse) ProtoMessage() {}
+
+func (x *ApproveRefundResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveRefundResponse.ProtoReflect.Descriptor instead.
+func (*ApproveRefundResponse) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ApproveRefundResponse) GetRefund() *RefundInfo {
+	if x != nil {
+		return x.Refund
+	}
+	return nil
+}
+
+// Dead-lettered message with its decoded event and failure reason
+type DeadLetterInfo struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	MsgId          string                 `protobuf:"bytes,1,opt,name=msgId,proto3" json:"msgId,omitempty"`                     // Message ID, unchanged by redelivery and dead-lettering
+	OriginTopic    string                 `protobuf:"bytes,2,opt,name=originTopic,proto3" json:"originTopic,omitempty"`         // Topic the message was sent to
+	Tag            string                 `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`                         // Message tag
+	Keys           []string               `protobuf:"bytes,4,rep,name=keys,proto3" json:"keys,omitempty"`                       // Message keys
+	EventId        string                 `protobuf:"bytes,5,opt,name=eventId,proto3" json:"eventId,omitempty"`                 // Event ID, empty when the message carries no valid event
+	EventType      string                 `protobuf:"bytes,6,opt,name=eventType,proto3" json:"eventType,omitempty"`             // Event type, or the tag when the message carries no valid event
+	EventVersion   int32                  `protobuf:"varint,7,opt,name=eventVersion,proto3" json:"eventVersion,omitempty"`      // Event version after upcasting
+	AggregateId    string                 `protobuf:"bytes,8,opt,name=aggregateId,proto3" json:"aggregateId,omitempty"`         // ID of the entity the event is about
+	OccurredAt     int64                  `protobuf:"varint,9,opt,name=occurredAt,proto3" json:"occurredAt,omitempty"`          // When the event happened (unix milliseconds)
+	PayloadJson    string                 `protobuf:"bytes,10,opt,name=payloadJson,proto3" json:"payloadJson,omitempty"`        // Event payload as JSON
+	DecodeError    string                 `protobuf:"bytes,11,opt,name=decodeError,proto3" json:"decodeError,omitempty"`        // Why the message does not carry a valid event
+	FailureReason  string                 `protobuf:"bytes,12,opt,name=failureReason,proto3" json:"failureReason,omitempty"`    // Error of the last failed delivery, empty if none was recorded
+	FailedAt       int64                  `protobuf:"varint,13,opt,name=failedAt,proto3" json:"failedAt,omitempty"`             // Time of the last failed delivery (unix milliseconds), 0 if unknown
+	ReconsumeTimes int32                  `protobuf:"varint,14,opt,name=reconsumeTimes,proto3" json:"reconsumeTimes,omitempty"` // Failed deliveries before the last one
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeadLetterInfo) Reset() {
+	*x = DeadLetterInfo{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadLetterInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadLetterInfo) ProtoMessage() {}
+
+func (x *DeadLetterInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadLetterInfo.ProtoReflect.Descriptor instead.
+func (*DeadLetterInfo) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DeadLetterInfo) GetMsgId() string {
+	if x != nil {
+		return x.MsgId
+	}
+	return ""
+}
+
+func (x *DeadLetterInfo) GetOriginTopic() string {
+	if x != nil {
+		return x.OriginTopic
+	}
+	return ""
+}
+
+func (x *DeadLetterInfo) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *DeadLetterInfo) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+func (x *DeadLetterInfo) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *DeadLetterInfo) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *DeadLetterInfo) GetEventVersion() int32 {
+	if x != nil {
+		return x.EventVersion
+	}
+	return 0
+}
+
+func (x *DeadLetterInfo) GetAggregateId() string {
+	if x != nil {
+		return x.AggregateId
+	}
+	return ""
+}
+
+func (x *DeadLetterInfo) GetOccurredAt() int64 {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return 0
+}
+
+func (x *DeadLetterInfo) GetPayloadJson() string {
+	if x != nil {
+		return x.PayloadJson
+	}
+	return ""
+}
+
+func (x *DeadLetterInfo) GetDecodeError() string {
+	if x != nil {
+		return x.DecodeError
+	}
+	return ""
+}
+
+func (x *DeadLetterInfo) GetFailureReason() string {
+	if x != nil {
+		return x.FailureReason
+	}
+	return ""
+}
+
+func (x *DeadLetterInfo) GetFailedAt() int64 {
+	if x != nil {
+		return x.FailedAt
+	}
+	return 0
+}
+
+func (x *DeadLetterInfo) GetReconsumeTimes() int32 {
+	if x != nil {
+		return x.ReconsumeTimes
+	}
+	return 0
+}
+
+// Admin List Dead Letters Request Parameters
+type AdminListDeadLettersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerGroup string                 `protobuf:"bytes,1,opt,name=consumerGroup,proto3" json:"consumerGroup,omitempty"` // Consumer group whose dead-letter topic is listed
+	EventType     string                 `protobuf:"bytes,2,opt,name=eventType,proto3" json:"eventType,omitempty"`         // Optional: keep events of this type
+	OrderId       int64                  `protobuf:"varint,3,opt,name=orderId,proto3" json:"orderId,omitempty"`            // Optional: keep events about this order
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`                // Maximum number of dead letters (default 50)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminListDeadLettersRequest) Reset() {
+	*x = AdminListDeadLettersRequest{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminListDeadLettersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminListDeadLettersRequest) ProtoMessage() {}
+
+func (x *AdminListDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminListDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*AdminListDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AdminListDeadLettersRequest) GetConsumerGroup() string {
+	if x != nil {
+		return x.ConsumerGroup
+	}
+	return ""
+}
+
+func (x *AdminListDeadLettersRequest) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *AdminListDeadLettersRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *AdminListDeadLettersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Admin List Dead Letters Response Parameters
+type AdminListDeadLettersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeadLetters   []*DeadLetterInfo      `protobuf:"bytes,1,rep,name=deadLetters,proto3" json:"deadLetters,omitempty"` // Oldest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminListDeadLettersResponse) Reset() {
+	*x = AdminListDeadLettersResponse{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminListDeadLettersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminListDeadLettersResponse) ProtoMessage() {}
+
+func (x *AdminListDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminListDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*AdminListDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *AdminListDeadLettersResponse) GetDeadLetters() []*DeadLetterInfo {
+	if x != nil {
+		return x.DeadLetters
+	}
+	return nil
+}
+
+// Admin Replay Dead Letters Request Parameters
+type AdminReplayDeadLettersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerGroup string                 `protobuf:"bytes,1,opt,name=consumerGroup,proto3" json:"consumerGroup,omitempty"`            // Consumer group that dead-lettered the messages
+	MsgIds        []string               `protobuf:"bytes,2,rep,name=msgIds,proto3" json:"msgIds,omitempty"`                          // Dead-lettered message IDs
+	Target        ReplayTarget           `protobuf:"varint,3,opt,name=target,proto3,enum=trade.ReplayTarget" json:"target,omitempty"` // Replay target
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`                          // Why the messages are replayed, for the audit log
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminReplayDeadLettersRequest) Reset() {
+	*x = AdminReplayDeadLettersRequest{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminReplayDeadLettersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminReplayDeadLettersRequest) ProtoMessage() {}
+
+func (x *AdminReplayDeadLettersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminReplayDeadLettersRequest.ProtoReflect.Descriptor instead.
+func (*AdminReplayDeadLettersRequest) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *AdminReplayDeadLettersRequest) GetConsumerGroup() string {
+	if x != nil {
+		return x.ConsumerGroup
+	}
+	return ""
+}
+
+func (x *AdminReplayDeadLettersRequest) GetMsgIds() []string {
+	if x != nil {
+		return x.MsgIds
+	}
+	return nil
+}
+
+func (x *AdminReplayDeadLettersRequest) GetTarget() ReplayTarget {
+	if x != nil {
+		return x.Target
+	}
+	return ReplayTarget_REPLAY_TARGET_ORIGIN
+}
+
+func (x *AdminReplayDeadLettersRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Outcome of replaying one dead-lettered message
+type ReplayResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MsgId         string                 `protobuf:"bytes,1,opt,name=msgId,proto3" json:"msgId,omitempty"`             // Dead-lettered message ID
+	TargetTopic   string                 `protobuf:"bytes,2,opt,name=targetTopic,proto3" json:"targetTopic,omitempty"` // Topic the message was replayed to
+	NewMsgId      string                 `protobuf:"bytes,3,opt,name=newMsgId,proto3" json:"newMsgId,omitempty"`       // ID of the replayed message, empty on failure
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`             // Why the message was not replayed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplayResult) Reset() {
+	*x = ReplayResult{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplayResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplayResult) ProtoMessage() {}
+
+func (x *ReplayResult) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplayResult.ProtoReflect.Descriptor instead.
+func (*ReplayResult) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ReplayResult) GetMsgId() string {
+	if x != nil {
+		return x.MsgId
+	}
+	return ""
+}
+
+func (x *ReplayResult) GetTargetTopic() string {
+	if x != nil {
+		return x.TargetTopic
+	}
+	return ""
+}
+
+func (x *ReplayResult) GetNewMsgId() string {
+	if x != nil {
+		return x.NewMsgId
+	}
+	return ""
+}
+
+func (x *ReplayResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Admin Replay Dead Letters Response Parameters
+type AdminReplayDeadLettersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*ReplayResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // In request order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminReplayDeadLettersResponse) Reset() {
+	*x = AdminReplayDeadLettersResponse{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminReplayDeadLettersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminReplayDeadLettersResponse) ProtoMessage() {}
+
+func (x *AdminReplayDeadLettersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminReplayDeadLettersResponse.ProtoReflect.Descriptor instead.
+func (*AdminReplayDeadLettersResponse) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *AdminReplayDeadLettersResponse) GetResults() []*ReplayResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// Admin Search Orders Request Parameters
+type AdminSearchOrdersRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CreatedFrom    int64                  `protobuf:"varint,1,opt,name=createdFrom,proto3" json:"createdFrom,omitempty"`       // Orders created at or after this time (unix seconds)
+	CreatedTo      int64                  `protobuf:"varint,2,opt,name=createdTo,proto3" json:"createdTo,omitempty"`           // Orders created before this time (unix seconds), at most 92 days after createdFrom
+	Status         int32                  `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`                 // Order status filter, 0 for all statuses
+	UserId         int64                  `protobuf:"varint,4,opt,name=userId,proto3" json:"userId,omitempty"`                 // Optional: orders of this user
+	IncludeDeleted bool                   `protobuf:"varint,5,opt,name=includeDeleted,proto3" json:"includeDeleted,omitempty"` // Also return orders deleted from the trade database, e.g. archived
+	Cursor         string                 `protobuf:"bytes,6,opt,name=cursor,proto3" json:"cursor,omitempty"`                  // Opaque cursor from the previous page, empty for the first page
+	Limit          int32                  `protobuf:"varint,7,opt,name=limit,proto3" json:"limit,omitempty"`                   // Page size, defaults to 20, maximum 100
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AdminSearchOrdersRequest) Reset() {
+	*x = AdminSearchOrdersRequest{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminSearchOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminSearchOrdersRequest) ProtoMessage() {}
+
+func (x *AdminSearchOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminSearchOrdersRequest.ProtoReflect.Descriptor instead.
+func (*AdminSearchOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AdminSearchOrdersRequest) GetCreatedFrom() int64 {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return 0
+}
+
+func (x *AdminSearchOrdersRequest) GetCreatedTo() int64 {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return 0
+}
+
+func (x *AdminSearchOrdersRequest) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *AdminSearchOrdersRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *AdminSearchOrdersRequest) GetIncludeDeleted() bool {
+	if x != nil {
+		return x.IncludeDeleted
+	}
+	return false
+}
+
+func (x *AdminSearchOrdersRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *AdminSearchOrdersRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Order of any user as captured by the reporting database
+type ReportedOrderInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Order         *OrderInfo             `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`      // Order as last captured
+	Items         []*OrderItemInfo       `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`      // Order items, deleted ones included
+	Deleted       bool                   `protobuf:"varint,3,opt,name=deleted,proto3" json:"deleted,omitempty"` // Whether the order was deleted from the trade database
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportedOrderInfo) Reset() {
+	*x = ReportedOrderInfo{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportedOrderInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportedOrderInfo) ProtoMessage() {}
+
+func (x *ReportedOrderInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportedOrderInfo.ProtoReflect.Descriptor instead.
+func (*ReportedOrderInfo) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ReportedOrderInfo) GetOrder() *OrderInfo {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+func (x *ReportedOrderInfo) GetItems() []*OrderItemInfo {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ReportedOrderInfo) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+// Admin Search Orders Response Parameters
+type AdminSearchOrdersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*ReportedOrderInfo   `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`         // Orders, newest first
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=nextCursor,proto3" json:"nextCursor,omitempty"` // Cursor for the next page, empty when there are no more orders
+	HasMore       bool                   `protobuf:"varint,3,opt,name=hasMore,proto3" json:"hasMore,omitempty"`      // Whether more orders exist after this page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdminSearchOrdersResponse) Reset() {
+	*x = AdminSearchOrdersResponse{}
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdminSearchOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdminSearchOrdersResponse) ProtoMessage() {}
+
+func (x *AdminSearchOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_service_trade_rpc_trade_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdminSearchOrdersResponse.ProtoReflect.Descriptor instead.
+func (*AdminSearchOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_service_trade_rpc_trade_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *AdminSearchOrdersResponse) GetOrders() []*ReportedOrderInfo {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *AdminSearchOrdersResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+func (x *AdminSearchOrdersResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+var File_service_trade_rpc_trade_proto protoreflect.FileDescriptor
+
+const file_service_trade_rpc_trade_proto_rawDesc = "" +
+	"\n" +
+	"\x1dservice/trade/rpc/trade.proto\x12\x05trade\"\xa1\x01\n" +
+	"\x11PlaceOrderRequest\x12\x16\n" +
+	"\x06userId\x18\x01 \x01(\x03R\x06userId\x12\x1c\n" +
+	"\tcourseIds\x18\x02 \x03(\x03R\tcourseIds\x12\x1c\n" +
+	"\tcouponIds\x18\x03 \x03(\x03R\tcouponIds\x12\x18\n" +
+	"\aorderId\x18\x04 \x01(\x03R\aorderId\x12\x1e\n" +
+	"\n" +
+	"realAmount\x18\x05 \x01(\x05R\n" +
+	"realAmount\"\xb0\x01\n" +
+	"\x12PlaceOrderResponse\x12\x18\n" +
+	"\aorderId\x18\x01 \x01(\x03R\aorderId\x12\x1c\n" +
+	"\tpayAmount\x18\x02 \x01(\x05R\tpayAmount\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\x05R\x06status\x122\n" +
+	"\aoutcome\x18\x04 \x01(\x0e2\x18.trade.PlaceOrderOutcomeR\aoutcome\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\"F\n" +
+	"\x12CancelOrderRequest\x12\x16\n" +
+	"\x06userId\x18\x01 \x01(\x03R\x06userId\x12\x18\n" +
+	"\aorderId\x18\x02 \x01(\x03R\aorderId\"a\n" +
+	"\x13CancelOrderResponse\x12\x18\n" +
+	"\aorderId\x18\x01 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\x05R\x06status\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\"\xaf\x02\n" +
+	"\tOrderInfo\x12\x18\n" +
+	"\aorderId\x18\x01 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06userId\x18\x02 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\x05R\x06status\x12 \n" +
+	"\vtotalAmount\x18\x04 \x01(\x05R\vtotalAmount\x12\x1c\n" +
+	"\tpayAmount\x18\x05 \x01(\x05R\tpayAmount\x12\x1e\n" +
+	"\n" +
+	"payChannel\x18\x06 \x01(\x05R\n" +
+	"payChannel\x12\x1e\n" +
+	"\n" +
+	"outTradeNo\x18\a \x01(\tR\n" +
+	"outTradeNo\x12\x18\n" +
+	"\apayTime\x18\b \x01(\x03R\apayTime\x12\x1e\n" +
+	"\n" +
+	"createTime\x18\t \x01(\x03R\n" +
+	"createTime\x12\x1e\n" +
+	"\n" +
+	"updateTime\x18\n" +
+	" \x01(\x03R\n" +
+	"updateTime\"\xdd\x01\n" +
+	"\rOrderItemInfo\x12\x16\n" +
+	"\x06itemId\x18\x01 \x01(\x03R\x06itemId\x12\x18\n" +
+	"\aorderId\x18\x02 \x01(\x03R\aorderId\x12\x1a\n" +
+	"\bcourseId\x18\x03 \x01(\x03R\bcourseId\x12\x1e\n" +
+	"\n" +
+	"courseName\x18\x04 \x01(\tR\n" +
+	"courseName\x12\x14\n" +
+	"\x05price\x18\x05 \x01(\x05R\x05price\x12$\n" +
+	"\rrealPayAmount\x18\x06 \x01(\x05R\rrealPayAmount\x12\"\n" +
+	"\frefundStatus\x18\a \x01(\x05R\frefundStatus\"C\n" +
+	"\x0fGetOrderRequest\x12\x16\n" +
+	"\x06userId\x18\x01 \x01(\x03R\x06userId\x12\x18\n" +
+	"\aorderId\x18\x02 \x01(\x03R\aorderId\":\n" +
+	"\x10GetOrderResponse\x12&\n" +
+	"\x05order\x18\x01 \x01(\v2\x10.trade.OrderInfoR\x05order\"s\n" +
+	"\x13ListMyOrdersRequest\x12\x16\n" +
+	"\x06userId\x18\x01 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\x05R\x06status\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"z\n" +
+	"\x14ListMyOrdersResponse\x12(\n" +
+	"\x06orders\x18\x01 \x03(\v2\x10.trade.OrderInfoR\x06orders\x12\x1e\n" +
+	"\n" +
+	"nextCursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\x12\x18\n" +
+	"\ahasMore\x18\x03 \x01(\bR\ahasMore\"H\n" +
+	"\x14GetOrderItemsRequest\x12\x16\n" +
+	"\x06userId\x18\x01 \x01(\x03R\x06userId\x12\x18\n" +
+	"\aorderId\x18\x02 \x01(\x03R\aorderId\"]\n" +
+	"\x15GetOrderItemsResponse\x12\x18\n" +
+	"\aorderId\x18\x01 \x01(\x03R\aorderId\x12*\n" +
+	"\x05items\x18\x02 \x03(\v2\x14.trade.OrderItemInfoR\x05items\"f\n" +
+	"\x0eRefundItemInfo\x12 \n" +
+	"\vorderItemId\x18\x01 \x01(\x03R\vorderItemId\x12\x1a\n" +
+	"\bcourseId\x18\x02 \x01(\x03R\bcourseId\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x05R\x06amount\"\x91\x02\n" +
+	"\n" +
+	"RefundInfo\x12\x1a\n" +
+	"\brefundId\x18\x01 \x01(\x03R\brefundId\x12\x18\n" +
+	"\aorderId\x18\x02 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06userId\x18\x03 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\x05R\x06status\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x05R\x06amount\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason\x12 \n" +
+	"\voutRefundNo\x18\a \x01(\tR\voutRefundNo\x12\x1e\n" +
+	"\n" +
+	"failReason\x18\b \x01(\tR\n" +
+	"failReason\x12+\n" +
+	"\x05items\x18\t \x03(\v2\x15.trade.RefundItemInfoR\x05items\"\x84\x01\n" +
+	"\x14RequestRefundRequest\x12\x16\n" +
+	"\x06userId\x18\x01 \x01(\x03R\x06userId\x12\x18\n" +
+	"\aorderId\x18\x02 \x01(\x03R\aorderId\x12\"\n" +
+	"\forderItemIds\x18\x03 \x03(\x03R\forderItemIds\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"B\n" +
+	"\x15RequestRefundResponse\x12)\n" +
+	"\x06refund\x18\x01 \x01(\v2\x11.trade.RefundInfoR\x06refund\"2\n" +
+	"\x14ApproveRefundRequest\x12\x1a\n" +
+	"\brefundId\x18\x01 \x01(\x03R\brefundId\"B\n" +
+	"\x15ApproveRefundResponse\x12)\n" +
+	"\x06refund\x18\x01 \x01(\v2\x11.trade.RefundInfoR\x06refund\"\xba\x03\n" +
+	"\x0eDeadLetterInfo\x12\x14\n" +
+	"\x05msgId\x18\x01 \x01(\tR\x05msgId\x12 \n" +
+	"\voriginTopic\x18\x02 \x01(\tR\voriginTopic\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\x12\x12\n" +
+	"\x04keys\x18\x04 \x03(\tR\x04keys\x12\x18\n" +
+	"\aeventId\x18\x05 \x01(\tR\aeventId\x12\x1c\n" +
+	"\teventType\x18\x06 \x01(\tR\teventType\x12\"\n" +
+	"\feventVersion\x18\a \x01(\x05R\feventVersion\x12 \n" +
+	"\vaggregateId\x18\b \x01(\tR\vaggregateId\x12\x1e\n" +
+	"\n" +
+	"occurredAt\x18\t \x01(\x03R\n" +
+	"occurredAt\x12 \n" +
+	"\vpayloadJson\x18\n" +
+	" \x01(\tR\vpayloadJson\x12 \n" +
+	"\vdecodeError\x18\v \x01(\tR\vdecodeError\x12$\n" +
+	"\rfailureReason\x18\f \x01(\tR\rfailureReason\x12\x1a\n" +
+	"\bfailedAt\x18\r \x01(\x03R\bfailedAt\x12&\n" +
+	"\x0ereconsumeTimes\x18\x0e \x01(\x05R\x0ereconsumeTimes\"\x91\x01\n" +
+	"\x1bAdminListDeadLettersRequest\x12$\n" +
+	"\rconsumerGroup\x18\x01 \x01(\tR\rconsumerGroup\x12\x1c\n" +
+	"\teventType\x18\x02 \x01(\tR\teventType\x12\x18\n" +
+	"\aorderId\x18\x03 \x01(\x03R\aorderId\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"W\n" +
+	"\x1cAdminListDeadLettersResponse\x127\n" +
+	"\vdeadLetters\x18\x01 \x03(\v2\x15.trade.DeadLetterInfoR\vdeadLetters\"\xa2\x01\n" +
+	"\x1dAdminReplayDeadLettersRequest\x12$\n" +
+	"\rconsumerGroup\x18\x01 \x01(\tR\rconsumerGroup\x12\x16\n" +
+	"\x06msgIds\x18\x02 \x03(\tR\x06msgIds\x12+\n" +
+	"\x06target\x18\x03 \x01(\x0e2\x13.trade.ReplayTargetR\x06target\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\"x\n" +
+	"\fReplayResult\x12\x14\n" +
+	"\x05msgId\x18\x01 \x01(\tR\x05msgId\x12 \n" +
+	"\vtargetTopic\x18\x02 \x01(\tR\vtargetTopic\x12\x1a\n" +
+	"\bnewMsgId\x18\x03 \x01(\tR\bnewMsgId\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"O\n" +
+	"\x1eAdminReplayDeadLettersResponse\x12-\n" +
+	"\aresults\x18\x01 \x03(\v2\x13.trade.ReplayResultR\aresults\"\xe0\x01\n" +
+	"\x18AdminSearchOrdersRequest\x12 \n" +
+	"\vcreatedFrom\x18\x01 \x01(\x03R\vcreatedFrom\x12\x1c\n" +
+	"\tcreatedTo\x18\x02 \x01(\x03R\tcreatedTo\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\x05R\x06status\x12\x16\n" +
+	"\x06userId\x18\x04 \x01(\x03R\x06userId\x12&\n" +
+	"\x0eincludeDeleted\x18\x05 \x01(\bR\x0eincludeDeleted\x12\x16\n" +
+	"\x06cursor\x18\x06 \x01(\tR\x06cursor\x12\x14\n" +
+	"\x05limit\x18\a \x01(\x05R\x05limit\"\x81\x01\n" +
+	"\x11ReportedOrderInfo\x12&\n" +
+	"\x05order\x18\x01 \x01(\v2\x10.trade.OrderInfoR\x05order\x12*\n" +
+	"\x05items\x18\x02 \x03(\v2\x14.trade.OrderItemInfoR\x05items\x12\x18\n" +
+	"\adeleted\x18\x03 \x01(\bR\adeleted\"\x87\x01\n" +
+	"\x19AdminSearchOrdersResponse\x120\n" +
+	"\x06orders\x18\x01 \x03(\v2\x18.trade.ReportedOrderInfoR\x06orders\x12\x1e\n" +
+	"\n" +
+	"nextCursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\x12\x18\n" +
+	"\ahasMore\x18\x03 \x01(\bR\ahasMore*\xa1\x01\n" +
+	"\x11PlaceOrderOutcome\x12#\n" +
+	"\x1fPLACE_ORDER_OUTCOME_UNSPECIFIED\x10\x00\x12!\n" +
+	"\x1dPLACE_ORDER_OUTCOME_COMMITTED\x10\x01\x12#\n" +
+	"\x1fPLACE_ORDER_OUTCOME_ROLLED_BACK\x10\x02\x12\x1f\n" +
+	"\x1bPLACE_ORDER_OUTCOME_PENDING\x10\x03*C\n" +
+	"\fReplayTarget\x12\x18\n" +
+	"\x14REPLAY_TARGET_ORIGIN\x10\x00\x12\x19\n" +
+	"\x15REPLAY_TARGET_SANDBOX\x10\x012\xa1\x06\n" +
 	"\fTradeService\x12A\n" +
 	"\n" +
 	"PlaceOrder\x12\x18.trade.PlaceOrderRequest\x1a\x19.trade.PlaceOrderResponse\x12D\n" +
-	"\vCancelOrder\x12\x19.trade.CancelOrderRequest\x1a\x1a.trade.CancelOrderResponseB4Z2github.com/aether-defense-system/service/trade/rpcb\x06proto3"
+	"\vCancelOrder\x12\x19.trade.CancelOrderRequest\x1a\x1a.trade.CancelOrderResponse\x12;\n" +
+	"\bGetOrder\x12\x16.trade.GetOrderRequest\x1a\x17.trade.GetOrderResponse\x12G\n" +
+	"\fListMyOrders\x12\x1a.trade.ListMyOrdersRequest\x1a\x1b.trade.ListMyOrdersResponse\x12J\n" +
+	"\rGetOrderItems\x12\x1b.trade.GetOrderItemsRequest\x1a\x1c.trade.GetOrderItemsResponse\x12J\n" +
+	"\rRequestRefund\x12\x1b.trade.RequestRefundRequest\x1a\x1c.trade.RequestRefundResponse\x12J\n" +
+	"\rApproveRefund\x12\x1b.trade.ApproveRefundRequest\x1a\x1c.trade.ApproveRefundResponse\x12_\n" +
+	"\x14AdminListDeadLetters\x12\".trade.AdminListDeadLettersRequest\x1a#.trade.AdminListDeadLettersResponse\x12e\n" +
+	"\x16AdminReplayDeadLetters\x12$.trade.AdminReplayDeadLettersRequest\x1a%.trade.AdminReplayDeadLettersResponse\x12V\n" +
+	"\x11AdminSearchOrders\x12\x1f.trade.AdminSearchOrdersRequest\x1a .trade.AdminSearchOrdersResponseB4Z2github.com/aether-defense-system/service/trade/rpcb\x06proto3"
 
 var (
 	file_service_trade_rpc_trade_proto_rawDescOnce sync.Once
@@ -314,23 +2162,78 @@ func file_service_trade_rpc_trade_proto_rawDescGZIP() []byte {
 	return file_service_trade_rpc_trade_proto_rawDescData
 }
 
-var file_service_trade_rpc_trade_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_service_trade_rpc_trade_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_service_trade_rpc_trade_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
 var file_service_trade_rpc_trade_proto_goTypes = []any{
-	(*PlaceOrderRequest)(nil),   // 0: trade.PlaceOrderRequest
-	(*PlaceOrderResponse)(nil),  // 1: trade.PlaceOrderResponse
-	(*CancelOrderRequest)(nil),  // 2: trade.CancelOrderRequest
-	(*CancelOrderResponse)(nil), // 3: trade.CancelOrderResponse
+	(PlaceOrderOutcome)(0),                 // 0: trade.PlaceOrderOutcome
+	(ReplayTarget)(0),                      // 1: trade.ReplayTarget
+	(*PlaceOrderRequest)(nil),              // 2: trade.PlaceOrderRequest
+	(*PlaceOrderResponse)(nil),             // 3: trade.PlaceOrderResponse
+	(*CancelOrderRequest)(nil),             // 4: trade.CancelOrderRequest
+	(*CancelOrderResponse)(nil),            // 5: trade.CancelOrderResponse
+	(*OrderInfo)(nil),                      // 6: trade.OrderInfo
+	(*OrderItemInfo)(nil),                  // 7: trade.OrderItemInfo
+	(*GetOrderRequest)(nil),                // 8: trade.GetOrderRequest
+	(*GetOrderResponse)(nil),               // 9: trade.GetOrderResponse
+	(*ListMyOrdersRequest)(nil),            // 10: trade.ListMyOrdersRequest
+	(*ListMyOrdersResponse)(nil),           // 11: trade.ListMyOrdersResponse
+	(*GetOrderItemsRequest)(nil),           // 12: trade.GetOrderItemsRequest
+	(*GetOrderItemsResponse)(nil),          // 13: trade.GetOrderItemsResponse
+	(*RefundItemInfo)(nil),                 // 14: trade.RefundItemInfo
+	(*RefundInfo)(nil),                     // 15: trade.RefundInfo
+	(*RequestRefundRequest)(nil),           // 16: trade.RequestRefundRequest
+	(*RequestRefundResponse)(nil),          // 17: trade.RequestRefundResponse
+	(*ApproveRefundRequest)(nil),           // 18: trade.ApproveRefundRequest
+	(*ApproveRefundResponse)(nil),          // 19: trade.ApproveRefundResponse
+	(*DeadLetterInfo)(nil),                 // 20: trade.DeadLetterInfo
+	(*AdminListDeadLettersRequest)(nil),    // 21: trade.AdminListDeadLettersRequest
+	(*AdminListDeadLettersResponse)(nil),   // 22: trade.AdminListDeadLettersResponse
+	(*AdminReplayDeadLettersRequest)(nil),  // 23: trade.AdminReplayDeadLettersRequest
+	(*ReplayResult)(nil),                   // 24: trade.ReplayResult
+	(*AdminReplayDeadLettersResponse)(nil), // 25: trade.AdminReplayDeadLettersResponse
+	(*AdminSearchOrdersRequest)(nil),       // 26: trade.AdminSearchOrdersRequest
+	(*ReportedOrderInfo)(nil),              // 27: trade.ReportedOrderInfo
+	(*AdminSearchOrdersResponse)(nil),      // 28: trade.AdminSearchOrdersResponse
 }
 var file_service_trade_rpc_trade_proto_depIdxs = []int32{
-	0, // 0: trade.TradeService.PlaceOrder:input_type -> trade.PlaceOrderRequest
-	2, // 1: trade.TradeService.CancelOrder:input_type -> trade.CancelOrderRequest
-	1, // 2: trade.TradeService.PlaceOrder:output_type -> trade.PlaceOrderResponse
-	3, // 3: trade.TradeService.CancelOrder:output_type -> trade.CancelOrderResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: trade.PlaceOrderResponse.outcome:type_name -> trade.PlaceOrderOutcome
+	6,  // 1: trade.GetOrderResponse.order:type_name -> trade.OrderInfo
+	6,  // 2: trade.ListMyOrdersResponse.orders:type_name -> trade.OrderInfo
+	7,  // 3: trade.GetOrderItemsResponse.items:type_name -> trade.OrderItemInfo
+	14, // 4: trade.RefundInfo.items:type_name -> trade.RefundItemInfo
+	15, // 5: trade.RequestRefundResponse.refund:type_name -> trade.RefundInfo
+	15, // 6: trade.ApproveRefundResponse.refund:type_name -> trade.RefundInfo
+	20, // 7: trade.AdminListDeadLettersResponse.deadLetters:type_name -> trade.DeadLetterInfo
+	1,  // 8: trade.AdminReplayDeadLettersRequest.target:type_name -> trade.ReplayTarget
+	24, // 9: trade.AdminReplayDeadLettersResponse.results:type_name -> trade.ReplayResult
+	6,  // 10: trade.ReportedOrderInfo.order:type_name -> trade.OrderInfo
+	7,  // 11: trade.ReportedOrderInfo.items:type_name -> trade.OrderItemInfo
+	27, // 12: trade.AdminSearchOrdersResponse.orders:type_name -> trade.ReportedOrderInfo
+	2,  // 13: trade.TradeService.PlaceOrder:input_type -> trade.PlaceOrderRequest
+	4,  // 14: trade.TradeService.CancelOrder:input_type -> trade.CancelOrderRequest
+	8,  // 15: trade.TradeService.GetOrder:input_type -> trade.GetOrderRequest
+	10, // 16: trade.TradeService.ListMyOrders:input_type -> trade.ListMyOrdersRequest
+	12, // 17: trade.TradeService.GetOrderItems:input_type -> trade.GetOrderItemsRequest
+	16, // 18: trade.TradeService.RequestRefund:input_type -> trade.RequestRefundRequest
+	18, // 19: trade.TradeService.ApproveRefund:input_type -> trade.ApproveRefundRequest
+	21, // 20: trade.TradeService.AdminListDeadLetters:input_type -> trade.AdminListDeadLettersRequest
+	23, // 21: trade.TradeService.AdminReplayDeadLetters:input_type -> trade.AdminReplayDeadLettersRequest
+	26, // 22: trade.TradeService.AdminSearchOrders:input_type -> trade.AdminSearchOrdersRequest
+	3,  // 23: trade.TradeService.PlaceOrder:output_type -> trade.PlaceOrderResponse
+	5,  // 24: trade.TradeService.CancelOrder:output_type -> trade.CancelOrderResponse
+	9,  // 25: trade.TradeService.GetOrder:output_type -> trade.GetOrderResponse
+	11, // 26: trade.TradeService.ListMyOrders:output_type -> trade.ListMyOrdersResponse
+	13, // 27: trade.TradeService.GetOrderItems:output_type -> trade.GetOrderItemsResponse
+	17, // 28: trade.TradeService.RequestRefund:output_type -> trade.RequestRefundResponse
+	19, // 29: trade.TradeService.ApproveRefund:output_type -> trade.ApproveRefundResponse
+	22, // 30: trade.TradeService.AdminListDeadLetters:output_type -> trade.AdminListDeadLettersResponse
+	25, // 31: trade.TradeService.AdminReplayDeadLetters:output_type -> trade.AdminReplayDeadLettersResponse
+	28, // 32: trade.TradeService.AdminSearchOrders:output_type -> trade.AdminSearchOrdersResponse
+	23, // [23:33] is the sub-list for method output_type
+	13, // [13:23] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_service_trade_rpc_trade_proto_init() }
@@ -343,13 +2246,14 @@ func file_service_trade_rpc_trade_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_service_trade_rpc_trade_proto_rawDesc), len(file_service_trade_rpc_trade_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      2,
+			NumMessages:   27,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_service_trade_rpc_trade_proto_goTypes,
 		DependencyIndexes: file_service_trade_rpc_trade_proto_depIdxs,
+		EnumInfos:         file_service_trade_rpc_trade_proto_enumTypes,
 		MessageInfos:      file_service_trade_rpc_trade_proto_msgTypes,
 	}.Build()
 	File_service_trade_rpc_trade_proto = out.File
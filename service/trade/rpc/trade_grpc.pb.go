@@ -21,8 +21,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TradeService_PlaceOrder_FullMethodName  = "/trade.TradeService/PlaceOrder"
-	TradeService_CancelOrder_FullMethodName = "/trade.TradeService/CancelOrder"
+	TradeService_PlaceOrder_FullMethodName             = "/trade.TradeService/PlaceOrder"
+	TradeService_CancelOrder_FullMethodName            = "/trade.TradeService/CancelOrder"
+	TradeService_GetOrder_FullMethodName               = "/trade.TradeService/GetOrder"
+	TradeService_ListMyOrders_FullMethodName           = "/trade.TradeService/ListMyOrders"
+	TradeService_GetOrderItems_FullMethodName          = "/trade.TradeService/GetOrderItems"
+	TradeService_RequestRefund_FullMethodName          = "/trade.TradeService/RequestRefund"
+	TradeService_ApproveRefund_FullMethodName          = "/trade.TradeService/ApproveRefund"
+	TradeService_AdminListDeadLetters_FullMethodName   = "/trade.TradeService/AdminListDeadLetters"
+	TradeService_AdminReplayDeadLetters_FullMethodName = "/trade.TradeService/AdminReplayDeadLetters"
+	TradeService_AdminSearchOrders_FullMethodName      = "/trade.TradeService/AdminSearchOrders"
 )
 
 // TradeServiceClient is the client API for TradeService service.
@@ -35,6 +43,22 @@ type TradeServiceClient interface {
 	PlaceOrder(ctx context.Context, in *PlaceOrderRequest, opts ...grpc.CallOption) (*PlaceOrderResponse, error)
 	// Cancel Order Interface
 	CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error)
+	// Get Order Interface
+	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*GetOrderResponse, error)
+	// List My Orders Interface, cursor paginated
+	ListMyOrders(ctx context.Context, in *ListMyOrdersRequest, opts ...grpc.CallOption) (*ListMyOrdersResponse, error)
+	// Get Order Items Interface
+	GetOrderItems(ctx context.Context, in *GetOrderItemsRequest, opts ...grpc.CallOption) (*GetOrderItemsResponse, error)
+	// Request Refund Interface, for a whole order or selected order items
+	RequestRefund(ctx context.Context, in *RequestRefundRequest, opts ...grpc.CallOption) (*RequestRefundResponse, error)
+	// Approve Refund Interface, admin only; refunds through the payment gateway
+	ApproveRefund(ctx context.Context, in *ApproveRefundRequest, opts ...grpc.CallOption) (*ApproveRefundResponse, error)
+	// List Dead Letters Interface, admin only; decodes the events of a consumer group's dead letters
+	AdminListDeadLetters(ctx context.Context, in *AdminListDeadLettersRequest, opts ...grpc.CallOption) (*AdminListDeadLettersResponse, error)
+	// Replay Dead Letters Interface, admin only; every replay is audited
+	AdminReplayDeadLetters(ctx context.Context, in *AdminReplayDeadLettersRequest, opts ...grpc.CallOption) (*AdminReplayDeadLettersResponse, error)
+	// Search Orders Interface, admin only; searches every shard by creation time in the reporting database
+	AdminSearchOrders(ctx context.Context, in *AdminSearchOrdersRequest, opts ...grpc.CallOption) (*AdminSearchOrdersResponse, error)
 }
 
 type tradeServiceClient struct {
@@ -65,6 +89,86 @@ func (c *tradeServiceClient) CancelOrder(ctx context.Context, in *CancelOrderReq
 	return out, nil
 }
 
+func (c *tradeServiceClient) GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*GetOrderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOrderResponse)
+	err := c.cc.Invoke(ctx, TradeService_GetOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeServiceClient) ListMyOrders(ctx context.Context, in *ListMyOrdersRequest, opts ...grpc.CallOption) (*ListMyOrdersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMyOrdersResponse)
+	err := c.cc.Invoke(ctx, TradeService_ListMyOrders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeServiceClient) GetOrderItems(ctx context.Context, in *GetOrderItemsRequest, opts ...grpc.CallOption) (*GetOrderItemsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOrderItemsResponse)
+	err := c.cc.Invoke(ctx, TradeService_GetOrderItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeServiceClient) RequestRefund(ctx context.Context, in *RequestRefundRequest, opts ...grpc.CallOption) (*RequestRefundResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RequestRefundResponse)
+	err := c.cc.Invoke(ctx, TradeService_RequestRefund_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeServiceClient) ApproveRefund(ctx context.Context, in *ApproveRefundRequest, opts ...grpc.CallOption) (*ApproveRefundResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApproveRefundResponse)
+	err := c.cc.Invoke(ctx, TradeService_ApproveRefund_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeServiceClient) AdminListDeadLetters(ctx context.Context, in *AdminListDeadLettersRequest, opts ...grpc.CallOption) (*AdminListDeadLettersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminListDeadLettersResponse)
+	err := c.cc.Invoke(ctx, TradeService_AdminListDeadLetters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeServiceClient) AdminReplayDeadLetters(ctx context.Context, in *AdminReplayDeadLettersRequest, opts ...grpc.CallOption) (*AdminReplayDeadLettersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminReplayDeadLettersResponse)
+	err := c.cc.Invoke(ctx, TradeService_AdminReplayDeadLetters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tradeServiceClient) AdminSearchOrders(ctx context.Context, in *AdminSearchOrdersRequest, opts ...grpc.CallOption) (*AdminSearchOrdersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AdminSearchOrdersResponse)
+	err := c.cc.Invoke(ctx, TradeService_AdminSearchOrders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TradeServiceServer is the server API for TradeService service.
 // All implementations must embed UnimplementedTradeServiceServer
 // for forward compatibility.
@@ -75,6 +179,22 @@ type TradeServiceServer interface {
 	PlaceOrder(context.Context, *PlaceOrderRequest) (*PlaceOrderResponse, error)
 	// Cancel Order Interface
 	CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error)
+	// Get Order Interface
+	GetOrder(context.Context, *GetOrderRequest) (*GetOrderResponse, error)
+	// List My Orders Interface, cursor paginated
+	ListMyOrders(context.Context, *ListMyOrdersRequest) (*ListMyOrdersResponse, error)
+	// Get Order Items Interface
+	GetOrderItems(context.Context, *GetOrderItemsRequest) (*GetOrderItemsResponse, error)
+	// Request Refund Interface, for a whole order or selected order items
+	RequestRefund(context.Context, *RequestRefundRequest) (*RequestRefundResponse, error)
+	// Approve Refund Interface, admin only; refunds through the payment gateway
+	ApproveRefund(context.Context, *ApproveRefundRequest) (*ApproveRefundResponse, error)
+	// List Dead Letters Interface, admin only; decodes the events of a consumer group's dead letters
+	AdminListDeadLetters(context.Context, *AdminListDeadLettersRequest) (*AdminListDeadLettersResponse, error)
+	// Replay Dead Letters Interface, admin only; every replay is audited
+	AdminReplayDeadLetters(context.Context, *AdminReplayDeadLettersRequest) (*AdminReplayDeadLettersResponse, error)
+	// Search Orders Interface, admin only; searches every shard by creation time in the reporting database
+	AdminSearchOrders(context.Context, *AdminSearchOrdersRequest) (*AdminSearchOrdersResponse, error)
 	mustEmbedUnimplementedTradeServiceServer()
 }
 
@@ -91,6 +211,30 @@ func (UnimplementedTradeServiceServer) PlaceOrder(context.Context, *PlaceOrderRe
 func (UnimplementedTradeServiceServer) CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CancelOrder not implemented")
 }
+func (UnimplementedTradeServiceServer) GetOrder(context.Context, *GetOrderRequest) (*GetOrderResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetOrder not implemented")
+}
+func (UnimplementedTradeServiceServer) ListMyOrders(context.Context, *ListMyOrdersRequest) (*ListMyOrdersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListMyOrders not implemented")
+}
+func (UnimplementedTradeServiceServer) GetOrderItems(context.Context, *GetOrderItemsRequest) (*GetOrderItemsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetOrderItems not implemented")
+}
+func (UnimplementedTradeServiceServer) RequestRefund(context.Context, *RequestRefundRequest) (*RequestRefundResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RequestRefund not implemented")
+}
+func (UnimplementedTradeServiceServer) ApproveRefund(context.Context, *ApproveRefundRequest) (*ApproveRefundResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ApproveRefund not implemented")
+}
+func (UnimplementedTradeServiceServer) AdminListDeadLetters(context.Context, *AdminListDeadLettersRequest) (*AdminListDeadLettersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AdminListDeadLetters not implemented")
+}
+func (UnimplementedTradeServiceServer) AdminReplayDeadLetters(context.Context, *AdminReplayDeadLettersRequest) (*AdminReplayDeadLettersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AdminReplayDeadLetters not implemented")
+}
+func (UnimplementedTradeServiceServer) AdminSearchOrders(context.Context, *AdminSearchOrdersRequest) (*AdminSearchOrdersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AdminSearchOrders not implemented")
+}
 func (UnimplementedTradeServiceServer) mustEmbedUnimplementedTradeServiceServer() {}
 func (UnimplementedTradeServiceServer) testEmbeddedByValue()                      {}
 
@@ -148,6 +292,150 @@ func _TradeService_CancelOrder_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TradeService_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).GetOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_GetOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).GetOrder(ctx, req.(*GetOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeService_ListMyOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMyOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).ListMyOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_ListMyOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).ListMyOrders(ctx, req.(*ListMyOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeService_GetOrderItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).GetOrderItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_GetOrderItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).GetOrderItems(ctx, req.(*GetOrderItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeService_RequestRefund_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestRefundRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).RequestRefund(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_RequestRefund_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).RequestRefund(ctx, req.(*RequestRefundRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeService_ApproveRefund_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApproveRefundRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).ApproveRefund(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_ApproveRefund_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).ApproveRefund(ctx, req.(*ApproveRefundRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeService_AdminListDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminListDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).AdminListDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_AdminListDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).AdminListDeadLetters(ctx, req.(*AdminListDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeService_AdminReplayDeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminReplayDeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).AdminReplayDeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_AdminReplayDeadLetters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).AdminReplayDeadLetters(ctx, req.(*AdminReplayDeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TradeService_AdminSearchOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AdminSearchOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TradeServiceServer).AdminSearchOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TradeService_AdminSearchOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TradeServiceServer).AdminSearchOrders(ctx, req.(*AdminSearchOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TradeService_ServiceDesc is the grpc.ServiceDesc for TradeService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -163,6 +451,38 @@ var TradeService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CancelOrder",
 			Handler:    _TradeService_CancelOrder_Handler,
 		},
+		{
+			MethodName: "GetOrder",
+			Handler:    _TradeService_GetOrder_Handler,
+		},
+		{
+			MethodName: "ListMyOrders",
+			Handler:    _TradeService_ListMyOrders_Handler,
+		},
+		{
+			MethodName: "GetOrderItems",
+			Handler:    _TradeService_GetOrderItems_Handler,
+		},
+		{
+			MethodName: "RequestRefund",
+			Handler:    _TradeService_RequestRefund_Handler,
+		},
+		{
+			MethodName: "ApproveRefund",
+			Handler:    _TradeService_ApproveRefund_Handler,
+		},
+		{
+			MethodName: "AdminListDeadLetters",
+			Handler:    _TradeService_AdminListDeadLetters_Handler,
+		},
+		{
+			MethodName: "AdminReplayDeadLetters",
+			Handler:    _TradeService_AdminReplayDeadLetters_Handler,
+		},
+		{
+			MethodName: "AdminSearchOrders",
+			Handler:    _TradeService_AdminSearchOrders_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "service/trade/rpc/trade.proto",
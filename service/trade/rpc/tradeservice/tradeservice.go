@@ -15,16 +15,55 @@ import (
 )
 
 type (
-	CancelOrderRequest  = rpc.CancelOrderRequest
-	CancelOrderResponse = rpc.CancelOrderResponse
-	PlaceOrderRequest   = rpc.PlaceOrderRequest
-	PlaceOrderResponse  = rpc.PlaceOrderResponse
+	AdminListDeadLettersRequest    = rpc.AdminListDeadLettersRequest
+	AdminListDeadLettersResponse   = rpc.AdminListDeadLettersResponse
+	AdminReplayDeadLettersRequest  = rpc.AdminReplayDeadLettersRequest
+	AdminReplayDeadLettersResponse = rpc.AdminReplayDeadLettersResponse
+	AdminSearchOrdersRequest       = rpc.AdminSearchOrdersRequest
+	AdminSearchOrdersResponse      = rpc.AdminSearchOrdersResponse
+	ApproveRefundRequest           = rpc.ApproveRefundRequest
+	ApproveRefundResponse          = rpc.ApproveRefundResponse
+	CancelOrderRequest             = rpc.CancelOrderRequest
+	CancelOrderResponse            = rpc.CancelOrderResponse
+	DeadLetterInfo                 = rpc.DeadLetterInfo
+	GetOrderItemsRequest           = rpc.GetOrderItemsRequest
+	GetOrderItemsResponse          = rpc.GetOrderItemsResponse
+	GetOrderRequest                = rpc.GetOrderRequest
+	GetOrderResponse               = rpc.GetOrderResponse
+	ListMyOrdersRequest            = rpc.ListMyOrdersRequest
+	ListMyOrdersResponse           = rpc.ListMyOrdersResponse
+	OrderInfo                      = rpc.OrderInfo
+	OrderItemInfo                  = rpc.OrderItemInfo
+	PlaceOrderRequest              = rpc.PlaceOrderRequest
+	PlaceOrderResponse             = rpc.PlaceOrderResponse
+	RefundInfo                     = rpc.RefundInfo
+	RefundItemInfo                 = rpc.RefundItemInfo
+	ReplayResult                   = rpc.ReplayResult
+	ReportedOrderInfo              = rpc.ReportedOrderInfo
+	RequestRefundRequest           = rpc.RequestRefundRequest
+	RequestRefundResponse          = rpc.RequestRefundResponse
 
 	TradeService interface {
 		// Place Order Interface
 		PlaceOrder(ctx context.Context, in *PlaceOrderRequest, opts ...grpc.CallOption) (*PlaceOrderResponse, error)
 		// Cancel Order Interface
 		CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error)
+		// Get Order Interface
+		GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*GetOrderResponse, error)
+		// List My Orders Interface, cursor paginated
+		ListMyOrders(ctx context.Context, in *ListMyOrdersRequest, opts ...grpc.CallOption) (*ListMyOrdersResponse, error)
+		// Get Order Items Interface
+		GetOrderItems(ctx context.Context, in *GetOrderItemsRequest, opts ...grpc.CallOption) (*GetOrderItemsResponse, error)
+		// Request Refund Interface, for a whole order or selected order items
+		RequestRefund(ctx context.Context, in *RequestRefundRequest, opts ...grpc.CallOption) (*RequestRefundResponse, error)
+		// Approve Refund Interface, admin only; refunds through the payment gateway
+		ApproveRefund(ctx context.Context, in *ApproveRefundRequest, opts ...grpc.CallOption) (*ApproveRefundResponse, error)
+		// List Dead Letters Interface, admin only; decodes the events of a consumer group's dead letters
+		AdminListDeadLetters(ctx context.Context, in *AdminListDeadLettersRequest, opts ...grpc.CallOption) (*AdminListDeadLettersResponse, error)
+		// Replay Dead Letters Interface, admin only; every replay is audited
+		AdminReplayDeadLetters(ctx context.Context, in *AdminReplayDeadLettersRequest, opts ...grpc.CallOption) (*AdminReplayDeadLettersResponse, error)
+		// Search Orders Interface, admin only; searches every shard by creation time in the reporting database
+		AdminSearchOrders(ctx context.Context, in *AdminSearchOrdersRequest, opts ...grpc.CallOption) (*AdminSearchOrdersResponse, error)
 	}
 
 	defaultTradeService struct {
@@ -49,3 +88,51 @@ func (m *defaultTradeService) CancelOrder(ctx context.Context, in *CancelOrderRe
 	client := rpc.NewTradeServiceClient(m.cli.Conn())
 	return client.CancelOrder(ctx, in, opts...)
 }
+
+// Get Order Interface
+func (m *defaultTradeService) GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*GetOrderResponse, error) {
+	client := rpc.NewTradeServiceClient(m.cli.Conn())
+	return client.GetOrder(ctx, in, opts...)
+}
+
+// List My Orders Interface, cursor paginated
+func (m *defaultTradeService) ListMyOrders(ctx context.Context, in *ListMyOrdersRequest, opts ...grpc.CallOption) (*ListMyOrdersResponse, error) {
+	client := rpc.NewTradeServiceClient(m.cli.Conn())
+	return client.ListMyOrders(ctx, in, opts...)
+}
+
+// Get Order Items Interface
+func (m *defaultTradeService) GetOrderItems(ctx context.Context, in *GetOrderItemsRequest, opts ...grpc.CallOption) (*GetOrderItemsResponse, error) {
+	client := rpc.NewTradeServiceClient(m.cli.Conn())
+	return client.GetOrderItems(ctx, in, opts...)
+}
+
+// Request Refund Interface, for a whole order or selected order items
+func (m *defaultTradeService) RequestRefund(ctx context.Context, in *RequestRefundRequest, opts ...grpc.CallOption) (*RequestRefundResponse, error) {
+	client := rpc.NewTradeServiceClient(m.cli.Conn())
+	return client.RequestRefund(ctx, in, opts...)
+}
+
+// Approve Refund Interface, admin only; refunds through the payment gateway
+func (m *defaultTradeService) ApproveRefund(ctx context.Context, in *ApproveRefundRequest, opts ...grpc.CallOption) (*ApproveRefundResponse, error) {
+	client := rpc.NewTradeServiceClient(m.cli.Conn())
+	return client.ApproveRefund(ctx, in, opts...)
+}
+
+// List Dead Letters Interface, admin only; decodes the events of a consumer group's dead letters
+func (m *defaultTradeService) AdminListDeadLetters(ctx context.Context, in *AdminListDeadLettersRequest, opts ...grpc.CallOption) (*AdminListDeadLettersResponse, error) {
+	client := rpc.NewTradeServiceClient(m.cli.Conn())
+	return client.AdminListDeadLetters(ctx, in, opts...)
+}
+
+// Replay Dead Letters Interface, admin only; every replay is audited
+func (m *defaultTradeService) AdminReplayDeadLetters(ctx context.Context, in *AdminReplayDeadLettersRequest, opts ...grpc.CallOption) (*AdminReplayDeadLettersResponse, error) {
+	client := rpc.NewTradeServiceClient(m.cli.Conn())
+	return client.AdminReplayDeadLetters(ctx, in, opts...)
+}
+
+// Search Orders Interface, admin only; searches every shard by creation time in the reporting database
+func (m *defaultTradeService) AdminSearchOrders(ctx context.Context, in *AdminSearchOrdersRequest, opts ...grpc.CallOption) (*AdminSearchOrdersResponse, error) {
+	client := rpc.NewTradeServiceClient(m.cli.Conn())
+	return client.AdminSearchOrders(ctx, in, opts...)
+}
@@ -4,12 +4,26 @@ package config
 import (
 	"github.com/zeromicro/go-zero/rest"
 	"github.com/zeromicro/go-zero/zrpc"
+
+	"github.com/aether-defense-system/common/redis"
 )
 
+// AuthConf represents JWT authentication configuration.
+type AuthConf struct {
+	AccessSecret string `json:"accessSecret" yaml:"accessSecret"`
+	AccessExpire int64  `json:"accessExpire" yaml:"accessExpire"`
+}
+
 // Config defines configuration for the user HTTP API.
 // Note: fieldalignment warnings for this struct are acceptable in this project
 // because it is constructed infrequently and not on hot paths.
 type Config struct { //nolint:govet
 	rest.RestConf
 	UserRPC *zrpc.RpcClientConf
+
+	// Auth and SessionRedis are optional: session routes are not registered without them.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Auth AuthConf `json:"auth,optional" yaml:"auth"`
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	SessionRedis redis.Config `json:"sessionRedis,optional" yaml:"sessionRedis"`
 }
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/aether-defense-system/common/auth"
+	"github.com/aether-defense-system/service/user/api/internal/logic"
+	"github.com/aether-defense-system/service/user/api/internal/svc"
+	"github.com/aether-defense-system/service/user/api/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// ForceLogoutHandler handles POST /v1/admin/users/:userId/logout requests.
+func ForceLogoutHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ForceLogoutRequest
+		if err := httpx.ParsePath(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		// user-rpc re-checks the admin's permission, so forward the caller's token.
+		ctx := auth.WithOutgoingAuthorization(r.Context(), r.Header.Get("Authorization"))
+
+		l := logic.NewForceLogoutLogic(ctx, svcCtx)
+		resp, err := l.ForceLogout(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/aether-defense-system/common/auth"
+	"github.com/aether-defense-system/service/user/api/internal/logic"
+	"github.com/aether-defense-system/service/user/api/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// ListSessionsHandler handles GET /v1/users/sessions requests.
+func ListSessionsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, err := auth.UserIDFromContext(r.Context())
+		if err != nil {
+			logx.WithContext(r.Context()).Errorf("failed to get user_id from JWT token: %v", err)
+			http.Error(w, "unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		// The current session is only used for display, so a token without one is not an error.
+		sessionID, _ := auth.SessionIDFromContext(r.Context()) //nolint:errcheck // optional
+
+		l := logic.NewListSessionsLogic(r.Context(), svcCtx)
+		resp, err := l.ListSessions(userID, sessionID)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
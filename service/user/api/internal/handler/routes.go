@@ -11,6 +11,9 @@ import (
 
 // RegisterHandlers registers HTTP routes for user-api.
 func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
+	// Server-level middleware runs after per-route JWT verification, so role claims are available.
+	server.Use(svcCtx.Permission)
+
 	server.AddRoutes(
 		[]rest.Route{
 			{
@@ -23,6 +26,51 @@ func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
 				Path:    "/v1/users/:userId",
 				Handler: GetUserHandler(svcCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/v1/users/login",
+				Handler: LoginHandler(svcCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/v1/users/token/refresh",
+				Handler: RefreshTokenHandler(svcCtx),
+			},
 		},
 	)
+
+	// Session management requires both JWT verification and the session check,
+	// otherwise a revoked token could still list or revoke sessions.
+	if svcCtx.JWTSecret == "" || svcCtx.SessionCheck == nil {
+		return
+	}
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{svcCtx.SessionCheck},
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/v1/users/sessions",
+					Handler: ListSessionsHandler(svcCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/v1/users/sessions/:sessionId",
+					Handler: RevokeSessionHandler(svcCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/v1/users/logout",
+					Handler: LogoutHandler(svcCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/v1/admin/users/:userId/logout",
+					Handler: ForceLogoutHandler(svcCtx),
+				},
+			}...,
+		),
+		rest.WithJwt(svcCtx.JWTSecret),
+	)
 }
@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/aether-defense-system/service/user/api/internal/svc"
+	"github.com/aether-defense-system/service/user/api/internal/types"
+	"github.com/aether-defense-system/service/user/rpc/userservice"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ForceLogoutLogic contains admin force-logout logic for the user HTTP API.
+type ForceLogoutLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewForceLogoutLogic creates a new ForceLogoutLogic.
+// ctx must carry the admin's forwarded access token (see auth.WithOutgoingAuthorization),
+// since user-rpc authorizes ForceLogout itself.
+func NewForceLogoutLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ForceLogoutLogic {
+	return &ForceLogoutLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ForceLogout revokes every session of the target user.
+func (l *ForceLogoutLogic) ForceLogout(req *types.ForceLogoutRequest) (*types.ForceLogoutResponse, error) {
+	if req.UserID <= 0 {
+		l.Errorf("invalid user_id: %d", req.UserID)
+		return nil, types.ErrInvalidUserID
+	}
+
+	rpcResp, err := l.svcCtx.UserRPC.ForceLogout(l.ctx, &userservice.ForceLogoutRequest{
+		UserId: req.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("force logout: userId=%d", req.UserID)
+
+	return &types.ForceLogoutResponse{Success: rpcResp.Success}, nil
+}
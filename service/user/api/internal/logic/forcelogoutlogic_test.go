@@ -0,0 +1,22 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aether-defense-system/service/user/api/internal/svc"
+	"github.com/aether-defense-system/service/user/api/internal/types"
+)
+
+func TestForceLogoutLogic_ForceLogout(t *testing.T) {
+	logic := NewForceLogoutLogic(context.Background(), &svc.ServiceContext{UserRPC: &mockUserRPC{}})
+
+	_, err := logic.ForceLogout(&types.ForceLogoutRequest{UserID: 0})
+	assert.ErrorIs(t, err, types.ErrInvalidUserID)
+
+	resp, err := logic.ForceLogout(&types.ForceLogoutRequest{UserID: 42})
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+}
@@ -20,6 +20,26 @@ type mockUserRPC struct {
 		in *userservice.GetUserRequest,
 		opts ...grpc.CallOption,
 	) (*userservice.GetUserResponse, error)
+	loginFunc func(
+		ctx context.Context,
+		in *userservice.LoginRequest,
+		opts ...grpc.CallOption,
+	) (*userservice.LoginResponse, error)
+	refreshTokenFunc func(
+		ctx context.Context,
+		in *userservice.RefreshTokenRequest,
+		opts ...grpc.CallOption,
+	) (*userservice.RefreshTokenResponse, error)
+	listSessionsFunc func(
+		ctx context.Context,
+		in *userservice.ListSessionsRequest,
+		opts ...grpc.CallOption,
+	) (*userservice.ListSessionsResponse, error)
+	revokeSessionFunc func(
+		ctx context.Context,
+		in *userservice.RevokeSessionRequest,
+		opts ...grpc.CallOption,
+	) (*userservice.RevokeSessionResponse, error)
 }
 
 func (m *mockUserRPC) GetUser(
@@ -37,6 +57,70 @@ func (m *mockUserRPC) GetUser(
 	}, nil
 }
 
+func (m *mockUserRPC) Login(
+	ctx context.Context,
+	in *userservice.LoginRequest,
+	opts ...grpc.CallOption,
+) (*userservice.LoginResponse, error) {
+	if m.loginFunc != nil {
+		return m.loginFunc(ctx, in, opts...)
+	}
+	return &userservice.LoginResponse{
+		UserId:       1,
+		SessionId:    "s1",
+		AccessToken:  "access",
+		AccessExpire: 1700000000,
+		RefreshToken: "refresh",
+	}, nil
+}
+
+func (m *mockUserRPC) RefreshToken(
+	ctx context.Context,
+	in *userservice.RefreshTokenRequest,
+	opts ...grpc.CallOption,
+) (*userservice.RefreshTokenResponse, error) {
+	if m.refreshTokenFunc != nil {
+		return m.refreshTokenFunc(ctx, in, opts...)
+	}
+	return &userservice.RefreshTokenResponse{
+		UserId:       1,
+		SessionId:    "s1",
+		AccessToken:  "access2",
+		AccessExpire: 1700000000,
+		RefreshToken: "refresh2",
+	}, nil
+}
+
+func (m *mockUserRPC) ListSessions(
+	ctx context.Context,
+	in *userservice.ListSessionsRequest,
+	opts ...grpc.CallOption,
+) (*userservice.ListSessionsResponse, error) {
+	if m.listSessionsFunc != nil {
+		return m.listSessionsFunc(ctx, in, opts...)
+	}
+	return &userservice.ListSessionsResponse{}, nil
+}
+
+func (m *mockUserRPC) RevokeSession(
+	ctx context.Context,
+	in *userservice.RevokeSessionRequest,
+	opts ...grpc.CallOption,
+) (*userservice.RevokeSessionResponse, error) {
+	if m.revokeSessionFunc != nil {
+		return m.revokeSessionFunc(ctx, in, opts...)
+	}
+	return &userservice.RevokeSessionResponse{Success: true}, nil
+}
+
+func (m *mockUserRPC) ForceLogout(
+	_ context.Context,
+	_ *userservice.ForceLogoutRequest,
+	_ ...grpc.CallOption,
+) (*userservice.ForceLogoutResponse, error) {
+	return &userservice.ForceLogoutResponse{Success: true}, nil
+}
+
 func TestGetUserLogic_GetUser_ValidationErrors(t *testing.T) {
 	svcCtx := &svc.ServiceContext{}
 	logic := NewGetUserLogic(context.Background(), svcCtx)
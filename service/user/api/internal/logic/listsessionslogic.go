@@ -0,0 +1,56 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/aether-defense-system/service/user/api/internal/svc"
+	"github.com/aether-defense-system/service/user/api/internal/types"
+	"github.com/aether-defense-system/service/user/rpc/userservice"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ListSessionsLogic contains device session listing logic for the user HTTP API.
+type ListSessionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewListSessionsLogic creates a new ListSessionsLogic.
+func NewListSessionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListSessionsLogic {
+	return &ListSessionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ListSessions lists the caller's active sessions, flagging the one the request was made with.
+func (l *ListSessionsLogic) ListSessions(userID int64, currentSessionID string) (*types.ListSessionsResponse, error) {
+	if userID <= 0 {
+		l.Errorf("invalid user_id: %d", userID)
+		return nil, types.ErrInvalidUserID
+	}
+
+	rpcResp, err := l.svcCtx.UserRPC.ListSessions(l.ctx, &userservice.ListSessionsRequest{
+		UserId: userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]types.SessionInfo, 0, len(rpcResp.Sessions))
+	for _, s := range rpcResp.Sessions {
+		sessions = append(sessions, types.SessionInfo{
+			SessionID:      s.SessionId,
+			Device:         s.Device,
+			CreateTime:     s.CreateTime,
+			LastActiveTime: s.LastActiveTime,
+			ExpireTime:     s.ExpireTime,
+			Current:        s.SessionId == currentSessionID,
+		})
+	}
+
+	return &types.ListSessionsResponse{Sessions: sessions}, nil
+}
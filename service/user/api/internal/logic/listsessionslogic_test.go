@@ -0,0 +1,41 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/aether-defense-system/service/user/api/internal/svc"
+	"github.com/aether-defense-system/service/user/api/internal/types"
+	userservice "github.com/aether-defense-system/service/user/rpc/userservice"
+)
+
+func TestListSessionsLogic_ListSessions(t *testing.T) {
+	mockRPC := &mockUserRPC{
+		listSessionsFunc: func(
+			_ context.Context,
+			in *userservice.ListSessionsRequest,
+			_ ...grpc.CallOption,
+		) (*userservice.ListSessionsResponse, error) {
+			assert.Equal(t, int64(1), in.UserId)
+			return &userservice.ListSessionsResponse{
+				Sessions: []*userservice.SessionInfo{
+					{SessionId: "s1", Device: "web"},
+					{SessionId: "s2", Device: "app"},
+				},
+			}, nil
+		},
+	}
+	logic := NewListSessionsLogic(context.Background(), &svc.ServiceContext{UserRPC: mockRPC})
+
+	_, err := logic.ListSessions(0, "s1")
+	assert.ErrorIs(t, err, types.ErrInvalidUserID)
+
+	resp, err := logic.ListSessions(1, "s2")
+	assert.NoError(t, err)
+	assert.Len(t, resp.Sessions, 2)
+	assert.False(t, resp.Sessions[0].Current)
+	assert.True(t, resp.Sessions[1].Current)
+}
@@ -0,0 +1,56 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/aether-defense-system/service/user/api/internal/svc"
+	"github.com/aether-defense-system/service/user/api/internal/types"
+	"github.com/aether-defense-system/service/user/rpc/userservice"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// LoginLogic contains login logic for the user HTTP API.
+type LoginLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewLoginLogic creates a new LoginLogic.
+func NewLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LoginLogic {
+	return &LoginLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Login logs a user in via the user RPC service and returns a token pair.
+func (l *LoginLogic) Login(req *types.LoginRequest) (*types.TokenResponse, error) {
+	if req.Mobile == "" {
+		l.Errorf("empty mobile in login request")
+		return nil, types.ErrEmptyMobile
+	}
+	if req.Code == "" {
+		l.Errorf("empty code in login request")
+		return nil, types.ErrEmptyCode
+	}
+
+	rpcResp, err := l.svcCtx.UserRPC.Login(l.ctx, &userservice.LoginRequest{
+		Mobile: req.Mobile,
+		Code:   req.Code,
+		Device: req.Device,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.TokenResponse{
+		UserID:       rpcResp.UserId,
+		SessionID:    rpcResp.SessionId,
+		AccessToken:  rpcResp.AccessToken,
+		AccessExpire: rpcResp.AccessExpire,
+		RefreshToken: rpcResp.RefreshToken,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/aether-defense-system/service/user/api/internal/svc"
+	"github.com/aether-defense-system/service/user/api/internal/types"
+	userservice "github.com/aether-defense-system/service/user/rpc/userservice"
+)
+
+func TestLoginLogic_Login_ValidationErrors(t *testing.T) {
+	logic := NewLoginLogic(context.Background(), &svc.ServiceContext{})
+
+	_, err := logic.Login(&types.LoginRequest{Code: "123456"})
+	assert.ErrorIs(t, err, types.ErrEmptyMobile)
+
+	_, err = logic.Login(&types.LoginRequest{Mobile: "13800138000"})
+	assert.ErrorIs(t, err, types.ErrEmptyCode)
+}
+
+func TestLoginLogic_Login_Success(t *testing.T) {
+	var got *userservice.LoginRequest
+	mockRPC := &mockUserRPC{
+		loginFunc: func(
+			_ context.Context,
+			in *userservice.LoginRequest,
+			_ ...grpc.CallOption,
+		) (*userservice.LoginResponse, error) {
+			got = in
+			return &userservice.LoginResponse{UserId: 1, SessionId: "s1", AccessToken: "a", RefreshToken: "r"}, nil
+		},
+	}
+	logic := NewLoginLogic(context.Background(), &svc.ServiceContext{UserRPC: mockRPC})
+
+	resp, err := logic.Login(&types.LoginRequest{Mobile: "13800138000", Code: "123456", Device: "web"})
+	assert.NoError(t, err)
+	assert.Equal(t, "web", got.Device)
+	assert.Equal(t, int64(1), resp.UserID)
+	assert.Equal(t, "s1", resp.SessionID)
+	assert.Equal(t, "r", resp.RefreshToken)
+}
+
+func TestLoginLogic_Login_RPCError(t *testing.T) {
+	mockRPC := &mockUserRPC{
+		loginFunc: func(
+			_ context.Context,
+			_ *userservice.LoginRequest,
+			_ ...grpc.CallOption,
+		) (*userservice.LoginResponse, error) {
+			return nil, fmt.Errorf("invalid or expired login code")
+		},
+	}
+	logic := NewLoginLogic(context.Background(), &svc.ServiceContext{UserRPC: mockRPC})
+
+	resp, err := logic.Login(&types.LoginRequest{Mobile: "13800138000", Code: "000000"})
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestRefreshTokenLogic_RefreshToken(t *testing.T) {
+	logic := NewRefreshTokenLogic(context.Background(), &svc.ServiceContext{UserRPC: &mockUserRPC{}})
+
+	_, err := logic.RefreshToken(&types.RefreshTokenRequest{})
+	assert.ErrorIs(t, err, types.ErrEmptyRefresh)
+
+	resp, err := logic.RefreshToken(&types.RefreshTokenRequest{RefreshToken: "refresh"})
+	assert.NoError(t, err)
+	assert.Equal(t, "refresh2", resp.RefreshToken)
+}
@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/aether-defense-system/service/user/api/internal/svc"
+	"github.com/aether-defense-system/service/user/api/internal/types"
+	"github.com/aether-defense-system/service/user/rpc/userservice"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// RefreshTokenLogic contains token refresh logic for the user HTTP API.
+type RefreshTokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRefreshTokenLogic creates a new RefreshTokenLogic.
+func NewRefreshTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RefreshTokenLogic {
+	return &RefreshTokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RefreshToken exchanges a refresh token for a new token pair via the user RPC service.
+func (l *RefreshTokenLogic) RefreshToken(req *types.RefreshTokenRequest) (*types.TokenResponse, error) {
+	if req.RefreshToken == "" {
+		l.Errorf("empty refresh token")
+		return nil, types.ErrEmptyRefresh
+	}
+
+	rpcResp, err := l.svcCtx.UserRPC.RefreshToken(l.ctx, &userservice.RefreshTokenRequest{
+		RefreshToken: req.RefreshToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.TokenResponse{
+		UserID:       rpcResp.UserId,
+		SessionID:    rpcResp.SessionId,
+		AccessToken:  rpcResp.AccessToken,
+		AccessExpire: rpcResp.AccessExpire,
+		RefreshToken: rpcResp.RefreshToken,
+	}, nil
+}
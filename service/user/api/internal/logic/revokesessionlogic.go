@@ -0,0 +1,51 @@
+package logic
+
+import (
+	"context"
+
+	"github.com/aether-defense-system/service/user/api/internal/svc"
+	"github.com/aether-defense-system/service/user/api/internal/types"
+	"github.com/aether-defense-system/service/user/rpc/userservice"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// RevokeSessionLogic contains session revocation logic for the user HTTP API.
+type RevokeSessionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRevokeSessionLogic creates a new RevokeSessionLogic.
+func NewRevokeSessionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RevokeSessionLogic {
+	return &RevokeSessionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// RevokeSession revokes one of the caller's sessions (log out a device, or the current one).
+func (l *RevokeSessionLogic) RevokeSession(
+	req *types.RevokeSessionRequest, userID int64,
+) (*types.RevokeSessionResponse, error) {
+	if userID <= 0 {
+		l.Errorf("invalid user_id: %d", userID)
+		return nil, types.ErrInvalidUserID
+	}
+	if req.SessionID == "" {
+		l.Errorf("empty session_id for user_id: %d", userID)
+		return nil, types.ErrInvalidSessionID
+	}
+
+	rpcResp, err := l.svcCtx.UserRPC.RevokeSession(l.ctx, &userservice.RevokeSessionRequest{
+		UserId:    userID,
+		SessionId: req.SessionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.RevokeSessionResponse{Success: rpcResp.Success}, nil
+}
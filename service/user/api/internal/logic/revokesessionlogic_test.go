@@ -0,0 +1,41 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/aether-defense-system/service/user/api/internal/svc"
+	"github.com/aether-defense-system/service/user/api/internal/types"
+	userservice "github.com/aether-defense-system/service/user/rpc/userservice"
+)
+
+func TestRevokeSessionLogic_RevokeSession(t *testing.T) {
+	var got *userservice.RevokeSessionRequest
+	mockRPC := &mockUserRPC{
+		revokeSessionFunc: func(
+			_ context.Context,
+			in *userservice.RevokeSessionRequest,
+			_ ...grpc.CallOption,
+		) (*userservice.RevokeSessionResponse, error) {
+			got = in
+			return &userservice.RevokeSessionResponse{Success: true}, nil
+		},
+	}
+	logic := NewRevokeSessionLogic(context.Background(), &svc.ServiceContext{UserRPC: mockRPC})
+
+	_, err := logic.RevokeSession(&types.RevokeSessionRequest{SessionID: "s1"}, 0)
+	assert.ErrorIs(t, err, types.ErrInvalidUserID)
+
+	_, err = logic.RevokeSession(&types.RevokeSessionRequest{}, 1)
+	assert.ErrorIs(t, err, types.ErrInvalidSessionID)
+
+	resp, err := logic.RevokeSession(&types.RevokeSessionRequest{SessionID: "s1"}, 1)
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	// The user id comes from the token, never from the request, so users can only revoke their own sessions.
+	assert.Equal(t, int64(1), got.UserId)
+	assert.Equal(t, "s1", got.SessionId)
+}
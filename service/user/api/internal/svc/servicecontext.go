@@ -2,23 +2,59 @@
 package svc
 
 import (
+	"fmt"
+	"net/http"
+
+	"github.com/aether-defense-system/common/auth"
+	"github.com/aether-defense-system/common/middleware"
+	"github.com/aether-defense-system/common/redis"
 	"github.com/aether-defense-system/service/user/rpc/userservice"
 
 	"github.com/aether-defense-system/service/user/api/internal/config"
 
+	"github.com/zeromicro/go-zero/rest"
 	"github.com/zeromicro/go-zero/zrpc"
 )
 
 // ServiceContext wires configuration and external dependencies for user-api.
 type ServiceContext struct {
-	Config  *config.Config
-	UserRPC userservice.UserService
+	Config       *config.Config
+	UserRPC      userservice.UserService
+	SessionCheck rest.Middleware // Rejects revoked sessions; nil when session Redis is not configured
+	Permission   rest.Middleware // Enforces route permissions; denies unregistered admin routes
+	JWTSecret    string          // JWT access secret for authentication
 }
 
 // NewServiceContext creates a new ServiceContext.
 func NewServiceContext(c *config.Config) *ServiceContext {
+	var sessionCheck rest.Middleware
+	if c.SessionRedis.Addr != "" || c.SessionRedis.Host != "" {
+		redisClient, err := redis.NewClient(&c.SessionRedis)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize session Redis: %v", err))
+		}
+		// The gateway only checks sessions; user-rpc creates and refreshes them.
+		sessions, err := auth.NewSessionStore(redisClient, 0)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize session store: %v", err))
+		}
+		sessionCheck = middleware.NewSessionMiddleware(sessions).Handle
+	}
+
 	return &ServiceContext{
-		Config:  c,
-		UserRPC: userservice.NewUserService(zrpc.MustNewClient(*c.UserRPC)),
+		Config:       c,
+		UserRPC:      userservice.NewUserService(zrpc.MustNewClient(*c.UserRPC)),
+		JWTSecret:    c.Auth.AccessSecret,
+		SessionCheck: sessionCheck,
+		Permission:   newPermissionMiddleware().Handle,
 	}
 }
+
+// AdminPathPrefix is the path prefix under which routes are denied unless a permission is registered.
+const AdminPathPrefix = "/v1/admin/"
+
+// newPermissionMiddleware registers the permission required by each protected route.
+func newPermissionMiddleware() *middleware.PermissionMiddleware {
+	return middleware.NewPermissionMiddleware(auth.DefaultPolicy(), AdminPathPrefix).
+		Require(http.MethodPost, "/v1/admin/users/:userId/logout", auth.PermUserSessionRevoke)
+}
@@ -15,7 +15,67 @@ type GetUserResponse struct {
 	UserID   int64  `json:"userId"`
 }
 
+// LoginRequest represents the HTTP request to log in with a one-time code.
+type LoginRequest struct {
+	Mobile string `json:"mobile"`
+	Code   string `json:"code"`
+	Device string `json:"device,optional"` // Device description shown in the session list
+}
+
+// RefreshTokenRequest represents the HTTP request to refresh an access token.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// TokenResponse represents the HTTP response carrying a token pair.
+type TokenResponse struct {
+	SessionID    string `json:"sessionId"`
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	UserID       int64  `json:"userId"`
+	AccessExpire int64  `json:"accessExpire"` // Access token expiry (unix seconds)
+}
+
+// SessionInfo represents one logged-in device.
+type SessionInfo struct {
+	SessionID      string `json:"sessionId"`
+	Device         string `json:"device"`
+	CreateTime     int64  `json:"createTime"`     // Unix seconds
+	LastActiveTime int64  `json:"lastActiveTime"` // Unix seconds
+	ExpireTime     int64  `json:"expireTime"`     // Unix seconds
+	Current        bool   `json:"current"`        // Whether this is the session of the calling token
+}
+
+// ListSessionsResponse represents the HTTP response listing a user's sessions.
+type ListSessionsResponse struct {
+	Sessions []SessionInfo `json:"sessions"`
+}
+
+// RevokeSessionRequest represents the HTTP request to revoke one session.
+type RevokeSessionRequest struct {
+	SessionID string `path:"sessionId"`
+}
+
+// RevokeSessionResponse represents the HTTP response for session revocation.
+type RevokeSessionResponse struct {
+	Success bool `json:"success"`
+}
+
+// ForceLogoutRequest represents the admin HTTP request to end every session of a user.
+type ForceLogoutRequest struct {
+	UserID int64 `path:"userId"`
+}
+
+// ForceLogoutResponse represents the HTTP response for a force logout.
+type ForceLogoutResponse struct {
+	Success bool `json:"success"`
+}
+
 // Domain-level errors returned by the HTTP layer.
 var (
-	ErrInvalidUserID = errors.New("invalid user_id: must be greater than 0")
+	ErrInvalidUserID    = errors.New("invalid user_id: must be greater than 0")
+	ErrEmptyMobile      = errors.New("mobile cannot be empty")
+	ErrEmptyCode        = errors.New("code cannot be empty")
+	ErrEmptyRefresh     = errors.New("refreshToken cannot be empty")
+	ErrInvalidSessionID = errors.New("invalid sessionId")
 )
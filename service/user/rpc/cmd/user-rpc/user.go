@@ -21,7 +21,7 @@ func main() {
 	flag.Parse()
 
 	var c config.Config
-	conf.MustLoad(*configFile, &c)
+	conf.MustLoad(*configFile, &c, conf.UseEnv())
 
 	// Create service context with all dependencies
 	ctx := svc.NewServiceContext(&c)
@@ -29,6 +29,7 @@ func main() {
 	s := zrpc.MustNewServer(c.RpcServerConf, func(grpcServer *grpc.Server) {
 		rpc.RegisterUserServiceServer(grpcServer, server.NewUserServiceServer(ctx))
 	})
+	s.AddUnaryInterceptors(ctx.Permission.Unary)
 	defer s.Stop()
 
 	_, _ = fmt.Printf("Starting user rpc server at %s...\n", c.ListenOn)
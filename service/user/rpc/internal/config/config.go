@@ -5,10 +5,33 @@ import (
 	"github.com/zeromicro/go-zero/zrpc"
 
 	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/redis"
 )
 
+// AuthConf represents token issuance configuration.
+// AccessSecret must match the secret the API gateways use to verify JWTs.
+type AuthConf struct {
+	AccessSecret  string `json:"accessSecret" yaml:"accessSecret"`
+	AccessExpire  int64  `json:"accessExpire" yaml:"accessExpire"`   // Access token lifetime in seconds
+	RefreshExpire int64  `json:"refreshExpire" yaml:"refreshExpire"` // Session lifetime in seconds
+}
+
 // Config represents the configuration for user RPC service.
 type Config struct {
 	zrpc.RpcServerConf
 	Database database.Config `json:"database" yaml:"database"`
+
+	// Auth and SessionRedis are optional: login and session RPCs are disabled when unset.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	Auth AuthConf `json:"auth,optional" yaml:"auth"`
+
+	// SessionRedis stores user sessions and login codes.
+	// zrpc.RpcServerConf already has a Redis field used for RPC auth, so we use a distinct key.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	SessionRedis redis.Config `json:"sessionRedis,optional" yaml:"sessionRedis"`
+
+	// AutoMigrate applies the pending user schema migrations at startup. Without it the schema is
+	// migrated with the migrate command.
+	//lint:ignore SA5008 go-zero config uses json tag options like ",optional"; not for encoding/json.
+	AutoMigrate bool `json:"autoMigrate,optional" yaml:"autoMigrate"`
 }
@@ -0,0 +1,55 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aether-defense-system/service/user/rpc"
+	"github.com/aether-defense-system/service/user/rpc/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ForceLogoutLogic handles revocation of all sessions of a user.
+type ForceLogoutLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewForceLogoutLogic creates a new ForceLogoutLogic instance.
+func NewForceLogoutLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ForceLogoutLogic {
+	return &ForceLogoutLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// ForceLogout revokes every session of a user.
+// Access tokens already issued stop working as soon as the gateways check the session.
+func (l *ForceLogoutLogic) ForceLogout(req *rpc.ForceLogoutRequest) (*rpc.ForceLogoutResponse, error) {
+	if req == nil {
+		l.Errorf("received nil ForceLogoutRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	if req.UserId <= 0 {
+		l.Errorf("invalid user_id: %d", req.UserId)
+		return nil, fmt.Errorf("invalid user_id: %d", req.UserId)
+	}
+
+	if l.svcCtx.Sessions == nil {
+		l.Errorf("session service not initialized")
+		return nil, fmt.Errorf("session service not available")
+	}
+
+	if err := l.svcCtx.Sessions.RevokeAll(l.ctx, req.UserId); err != nil {
+		l.Errorf("failed to revoke sessions: %v, userId=%d", err, req.UserId)
+		return nil, fmt.Errorf("failed to force logout: %w", err)
+	}
+
+	l.Infof("user force-logged out: userId=%d", req.UserId)
+
+	return &rpc.ForceLogoutResponse{Success: true}, nil
+}
@@ -0,0 +1,41 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aether-defense-system/service/user/rpc"
+	"github.com/aether-defense-system/service/user/rpc/internal/config"
+	"github.com/aether-defense-system/service/user/rpc/internal/svc"
+)
+
+func TestForceLogoutLogic_ForceLogout(t *testing.T) {
+	sessions := newFakeSessions()
+	svcCtx := newSessionTestContext(sessions)
+	ctx := context.Background()
+	_, _, _ = sessions.Create(ctx, 7, "web")
+	_, _, _ = sessions.Create(ctx, 7, "app")
+	other, _, _ := sessions.Create(ctx, 8, "web")
+
+	logic := NewForceLogoutLogic(ctx, svcCtx)
+
+	if _, err := logic.ForceLogout(&rpc.ForceLogoutRequest{UserId: 0}); err == nil {
+		t.Errorf("expected error for invalid user id")
+	}
+
+	resp, err := logic.ForceLogout(&rpc.ForceLogoutRequest{UserId: 7})
+	if err != nil || !resp.Success {
+		t.Fatalf("ForceLogout() = %+v, %v", resp, err)
+	}
+	if len(sessions.sessions) != 1 || sessions.sessions[other.SessionID] == nil {
+		t.Errorf("expected only the other user's session to remain, got %d", len(sessions.sessions))
+	}
+}
+
+func TestForceLogoutLogic_SessionsNotConfigured(t *testing.T) {
+	logic := NewForceLogoutLogic(context.Background(), &svc.ServiceContext{Config: &config.Config{}})
+
+	if _, err := logic.ForceLogout(&rpc.ForceLogoutRequest{UserId: 7}); err == nil {
+		t.Errorf("expected error when sessions are not configured")
+	}
+}
@@ -73,8 +73,9 @@ func TestGetUserLogic_GetUser_ValidationAndSuccess(t *testing.T) {
 }
 
 type fakeUserRepo struct {
-	user *database.User
-	err  error
+	user  *database.User
+	err   error
+	roles []string
 }
 
 func (f *fakeUserRepo) GetByID(_ context.Context, _ int64) (*database.User, error) {
@@ -87,6 +88,23 @@ func (f *fakeUserRepo) GetByID(_ context.Context, _ int64) (*database.User, erro
 	return f.user, nil
 }
 
+func (f *fakeUserRepo) GetRoles(_ context.Context, _ int64) ([]string, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.roles, nil
+}
+
+func (f *fakeUserRepo) GetByMobile(_ context.Context, _ string) (*database.User, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	return f.user, nil
+}
+
 func TestGetUserLogic_GetUser_Success_WithRepo(t *testing.T) {
 	cfg := &config.Config{}
 	svcCtx := &svc.ServiceContext{
@@ -0,0 +1,69 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aether-defense-system/service/user/rpc"
+	"github.com/aether-defense-system/service/user/rpc/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// ListSessionsLogic handles device session listing.
+type ListSessionsLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewListSessionsLogic creates a new ListSessionsLogic instance.
+func NewListSessionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListSessionsLogic {
+	return &ListSessionsLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// ListSessions returns the active sessions of a user, most recently active first.
+func (l *ListSessionsLogic) ListSessions(req *rpc.ListSessionsRequest) (*rpc.ListSessionsResponse, error) {
+	if req == nil {
+		l.Errorf("received nil ListSessionsRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	if req.UserId <= 0 {
+		l.Errorf("invalid user_id: %d", req.UserId)
+		return nil, fmt.Errorf("invalid user_id: %d", req.UserId)
+	}
+
+	if l.svcCtx.Sessions == nil {
+		l.Errorf("session service not initialized")
+		return nil, fmt.Errorf("session service not available")
+	}
+
+	sessions, err := l.svcCtx.Sessions.List(l.ctx, req.UserId)
+	if err != nil {
+		l.Errorf("failed to list sessions: %v, userId=%d", err, req.UserId)
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastActiveTime.After(sessions[j].LastActiveTime)
+	})
+
+	infos := make([]*rpc.SessionInfo, 0, len(sessions))
+	for _, s := range sessions {
+		infos = append(infos, &rpc.SessionInfo{
+			SessionId:      s.SessionID,
+			Device:         s.Device,
+			CreateTime:     s.CreateTime.Unix(),
+			LastActiveTime: s.LastActiveTime.Unix(),
+			ExpireTime:     s.ExpireTime.Unix(),
+		})
+	}
+
+	return &rpc.ListSessionsResponse{Sessions: infos}, nil
+}
@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aether-defense-system/service/user/rpc"
+)
+
+func TestListSessionsLogic_ListSessions(t *testing.T) {
+	sessions := newFakeSessions()
+	svcCtx := newSessionTestContext(sessions)
+	ctx := context.Background()
+
+	older, _, _ := sessions.Create(ctx, 7, "old")
+	older.LastActiveTime = time.Now().Add(-time.Hour)
+	newer, _, _ := sessions.Create(ctx, 7, "new")
+	_, _, _ = sessions.Create(ctx, 8, "someone else")
+
+	logic := NewListSessionsLogic(ctx, svcCtx)
+
+	if _, err := logic.ListSessions(&rpc.ListSessionsRequest{UserId: 0}); err == nil {
+		t.Errorf("expected error for invalid user id")
+	}
+
+	resp, err := logic.ListSessions(&rpc.ListSessionsRequest{UserId: 7})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(resp.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(resp.Sessions))
+	}
+	if resp.Sessions[0].SessionId != newer.SessionID || resp.Sessions[1].SessionId != older.SessionID {
+		t.Errorf("expected most recently active session first: %+v", resp.Sessions)
+	}
+
+	sessions.err = fmt.Errorf("redis down")
+	if _, err := logic.ListSessions(&rpc.ListSessionsRequest{UserId: 7}); err == nil {
+		t.Errorf("expected store error to be returned")
+	}
+}
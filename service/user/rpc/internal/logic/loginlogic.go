@@ -0,0 +1,110 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aether-defense-system/service/user/rpc"
+	"github.com/aether-defense-system/service/user/rpc/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxDeviceLength bounds the client-supplied device description stored with a session.
+const maxDeviceLength = 128
+
+// LoginLogic handles user login and session creation.
+type LoginLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewLoginLogic creates a new LoginLogic instance.
+func NewLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LoginLogic {
+	return &LoginLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// Login authenticates a user by mobile number and one-time code.
+//
+// Responsibilities:
+//   - Verify and consume the login code
+//   - Load the (non-banned) user by mobile number
+//   - Create a device session and issue an access/refresh token pair
+func (l *LoginLogic) Login(req *rpc.LoginRequest) (*rpc.LoginResponse, error) {
+	if req == nil {
+		l.Errorf("received nil LoginRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	if req.Mobile == "" {
+		l.Errorf("empty mobile in login request")
+		return nil, fmt.Errorf("mobile cannot be empty")
+	}
+
+	if req.Code == "" {
+		l.Errorf("empty login code for mobile: %s", req.Mobile)
+		return nil, fmt.Errorf("code cannot be empty")
+	}
+
+	device := req.Device
+	if device == "" {
+		device = "unknown"
+	}
+	if len(device) > maxDeviceLength {
+		device = device[:maxDeviceLength]
+	}
+
+	if l.svcCtx.Sessions == nil || l.svcCtx.LoginCodes == nil || l.svcCtx.Tokens == nil {
+		l.Errorf("session service not initialized")
+		return nil, fmt.Errorf("session service not available")
+	}
+
+	if l.svcCtx.UserRepo == nil {
+		l.Errorf("user repository not initialized")
+		return nil, fmt.Errorf("user repository not available")
+	}
+
+	if err := l.svcCtx.LoginCodes.Verify(l.ctx, req.Mobile, req.Code); err != nil {
+		l.Errorf("login code verification failed: %v, mobile=%s", err, req.Mobile)
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+
+	user, err := l.svcCtx.UserRepo.GetByMobile(l.ctx, req.Mobile)
+	if err != nil {
+		l.Errorf("failed to get user by mobile: %v, mobile=%s", err, req.Mobile)
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+
+	session, refreshToken, err := l.svcCtx.Sessions.Create(l.ctx, user.ID, device)
+	if err != nil {
+		l.Errorf("failed to create session: %v, userId=%d", err, user.ID)
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	roles, err := l.svcCtx.UserRepo.GetRoles(l.ctx, user.ID)
+	if err != nil {
+		l.Errorf("failed to get user roles: %v, userId=%d", err, user.ID)
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	accessToken, expiresAt, err := l.svcCtx.Tokens.IssueAccessToken(user.ID, session.SessionID, roles)
+	if err != nil {
+		l.Errorf("failed to issue access token: %v, userId=%d", err, user.ID)
+		return nil, fmt.Errorf("failed to issue access token: %w", err)
+	}
+
+	l.Infof("user logged in: userId=%d, sessionId=%s, device=%s", user.ID, session.SessionID, device)
+
+	return &rpc.LoginResponse{
+		UserId:       user.ID,
+		SessionId:    session.SessionID,
+		AccessToken:  accessToken,
+		AccessExpire: expiresAt.Unix(),
+		RefreshToken: refreshToken,
+	}, nil
+}
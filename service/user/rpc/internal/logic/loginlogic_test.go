@@ -0,0 +1,169 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aether-defense-system/common/auth"
+	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/service/user/rpc"
+	"github.com/aether-defense-system/service/user/rpc/internal/config"
+	"github.com/aether-defense-system/service/user/rpc/internal/svc"
+)
+
+// fakeSessions is an in-memory SessionManager.
+type fakeSessions struct {
+	sessions map[string]*auth.Session
+	err      error
+	revoked  []string
+}
+
+func newFakeSessions() *fakeSessions {
+	return &fakeSessions{sessions: make(map[string]*auth.Session)}
+}
+
+func (f *fakeSessions) Create(_ context.Context, userID int64, device string) (*auth.Session, string, error) {
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	id := fmt.Sprintf("s%d", len(f.sessions)+1)
+	now := time.Now()
+	s := &auth.Session{
+		SessionID: id, UserID: userID, Device: device,
+		CreateTime: now, LastActiveTime: now, ExpireTime: now.Add(time.Hour),
+	}
+	f.sessions[id] = s
+	return s, "refresh-" + id, nil
+}
+
+func (f *fakeSessions) Rotate(_ context.Context, refreshToken string) (*auth.Session, string, error) {
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	for id, s := range f.sessions {
+		if refreshToken == "refresh-"+id {
+			return s, "refresh2-" + id, nil
+		}
+	}
+	return nil, "", auth.ErrInvalidRefreshToken
+}
+
+func (f *fakeSessions) List(_ context.Context, userID int64) ([]*auth.Session, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var out []*auth.Session
+	for _, s := range f.sessions {
+		if s.UserID == userID {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSessions) Revoke(_ context.Context, _ int64, sessionID string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.revoked = append(f.revoked, sessionID)
+	delete(f.sessions, sessionID)
+	return nil
+}
+
+func (f *fakeSessions) RevokeAll(_ context.Context, userID int64) error {
+	if f.err != nil {
+		return f.err
+	}
+	for id, s := range f.sessions {
+		if s.UserID == userID {
+			f.revoked = append(f.revoked, id)
+			delete(f.sessions, id)
+		}
+	}
+	return nil
+}
+
+type fakeLoginCodes struct {
+	code string
+}
+
+func (f *fakeLoginCodes) Verify(_ context.Context, _, code string) error {
+	if code != f.code {
+		return auth.ErrInvalidLoginCode
+	}
+	return nil
+}
+
+type fakeTokens struct{}
+
+func (fakeTokens) IssueAccessToken(userID int64, sessionID string, roles []string) (string, time.Time, error) {
+	return fmt.Sprintf("token-%d-%s-%s", userID, sessionID, strings.Join(roles, ",")), time.Unix(1700000000, 0), nil
+}
+
+func newSessionTestContext(sessions *fakeSessions) *svc.ServiceContext {
+	return &svc.ServiceContext{
+		Config: &config.Config{},
+		UserRepo: &fakeUserRepo{
+			user:  &database.User{ID: 7, Username: "u7", Mobile: "13800000007"},
+			roles: []string{auth.RoleSupport},
+		},
+		Sessions:   sessions,
+		LoginCodes: &fakeLoginCodes{code: "123456"},
+		Tokens:     fakeTokens{},
+	}
+}
+
+func TestLoginLogic_Login_ValidationErrors(t *testing.T) {
+	logic := NewLoginLogic(context.Background(), newSessionTestContext(newFakeSessions()))
+
+	tests := []struct {
+		req  *rpc.LoginRequest
+		name string
+	}{
+		{name: "nil request", req: nil},
+		{name: "empty mobile", req: &rpc.LoginRequest{Code: "123456"}},
+		{name: "empty code", req: &rpc.LoginRequest{Mobile: "13800000007"}},
+		{name: "wrong code", req: &rpc.LoginRequest{Mobile: "13800000007", Code: "000000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := logic.Login(tt.req)
+			if err == nil {
+				t.Fatalf("expected error, got nil (resp=%+v)", resp)
+			}
+		})
+	}
+}
+
+func TestLoginLogic_Login_SessionsNotConfigured(t *testing.T) {
+	svcCtx := &svc.ServiceContext{Config: &config.Config{}}
+	logic := NewLoginLogic(context.Background(), svcCtx)
+
+	_, err := logic.Login(&rpc.LoginRequest{Mobile: "13800000007", Code: "123456"})
+	if err == nil {
+		t.Fatalf("expected error when sessions are not configured")
+	}
+}
+
+func TestLoginLogic_Login_Success(t *testing.T) {
+	sessions := newFakeSessions()
+	logic := NewLoginLogic(context.Background(), newSessionTestContext(sessions))
+
+	resp, err := logic.Login(&rpc.LoginRequest{Mobile: "13800000007", Code: "123456", Device: "iPhone"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.UserId != 7 || resp.SessionId == "" || resp.RefreshToken == "" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if resp.AccessToken != "token-7-"+resp.SessionId+"-"+auth.RoleSupport {
+		t.Errorf("access token not bound to session and roles: %s", resp.AccessToken)
+	}
+	if sessions.sessions[resp.SessionId].Device != "iPhone" {
+		t.Errorf("expected device to be recorded")
+	}
+}
@@ -0,0 +1,80 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aether-defense-system/service/user/rpc"
+	"github.com/aether-defense-system/service/user/rpc/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// RefreshTokenLogic handles access token refresh.
+type RefreshTokenLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewRefreshTokenLogic creates a new RefreshTokenLogic instance.
+func NewRefreshTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RefreshTokenLogic {
+	return &RefreshTokenLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// RefreshToken exchanges a refresh token for a new access/refresh token pair.
+// The session must still be active; revoked sessions cannot be refreshed.
+func (l *RefreshTokenLogic) RefreshToken(req *rpc.RefreshTokenRequest) (*rpc.RefreshTokenResponse, error) {
+	if req == nil {
+		l.Errorf("received nil RefreshTokenRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	if req.RefreshToken == "" {
+		l.Errorf("empty refresh token")
+		return nil, fmt.Errorf("refresh_token cannot be empty")
+	}
+
+	if l.svcCtx.Sessions == nil || l.svcCtx.Tokens == nil {
+		l.Errorf("session service not initialized")
+		return nil, fmt.Errorf("session service not available")
+	}
+
+	if l.svcCtx.UserRepo == nil {
+		l.Errorf("user repository not initialized")
+		return nil, fmt.Errorf("user repository not available")
+	}
+
+	session, refreshToken, err := l.svcCtx.Sessions.Rotate(l.ctx, req.RefreshToken)
+	if err != nil {
+		l.Errorf("failed to rotate refresh token: %v", err)
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	// Roles are reloaded on every refresh so role changes take effect within one access token lifetime.
+	roles, err := l.svcCtx.UserRepo.GetRoles(l.ctx, session.UserID)
+	if err != nil {
+		l.Errorf("failed to get user roles: %v, userId=%d", err, session.UserID)
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	accessToken, expiresAt, err := l.svcCtx.Tokens.IssueAccessToken(session.UserID, session.SessionID, roles)
+	if err != nil {
+		l.Errorf("failed to issue access token: %v, userId=%d", err, session.UserID)
+		return nil, fmt.Errorf("failed to issue access token: %w", err)
+	}
+
+	l.Infof("token refreshed: userId=%d, sessionId=%s", session.UserID, session.SessionID)
+
+	return &rpc.RefreshTokenResponse{
+		UserId:       session.UserID,
+		SessionId:    session.SessionID,
+		AccessToken:  accessToken,
+		AccessExpire: expiresAt.Unix(),
+		RefreshToken: refreshToken,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package logic
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aether-defense-system/common/auth"
+	"github.com/aether-defense-system/service/user/rpc"
+)
+
+func TestRefreshTokenLogic_RefreshToken(t *testing.T) {
+	sessions := newFakeSessions()
+	svcCtx := newSessionTestContext(sessions)
+	session, refreshToken, err := sessions.Create(context.Background(), 7, "web")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	logic := NewRefreshTokenLogic(context.Background(), svcCtx)
+
+	if _, err = logic.RefreshToken(nil); err == nil {
+		t.Errorf("expected error for nil request")
+	}
+	if _, err = logic.RefreshToken(&rpc.RefreshTokenRequest{}); err == nil {
+		t.Errorf("expected error for empty refresh token")
+	}
+	if _, err = logic.RefreshToken(&rpc.RefreshTokenRequest{RefreshToken: "bogus"}); err == nil {
+		t.Errorf("expected error for unknown refresh token")
+	}
+
+	resp, err := logic.RefreshToken(&rpc.RefreshTokenRequest{RefreshToken: refreshToken})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.UserId != 7 || resp.SessionId != session.SessionID {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if !strings.HasSuffix(resp.AccessToken, "-"+auth.RoleSupport) {
+		t.Errorf("expected roles to be reloaded into the access token: %s", resp.AccessToken)
+	}
+	if resp.RefreshToken == refreshToken {
+		t.Errorf("expected refresh token to be rotated")
+	}
+}
@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aether-defense-system/service/user/rpc"
+	"github.com/aether-defense-system/service/user/rpc/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// RevokeSessionLogic handles revocation of a single device session.
+type RevokeSessionLogic struct {
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	logx.Logger
+}
+
+// NewRevokeSessionLogic creates a new RevokeSessionLogic instance.
+func NewRevokeSessionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RevokeSessionLogic {
+	return &RevokeSessionLogic{
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		Logger: logx.WithContext(ctx),
+	}
+}
+
+// RevokeSession ends one of the user's sessions.
+// Sessions are stored per user, so a user can only ever revoke their own sessions.
+func (l *RevokeSessionLogic) RevokeSession(req *rpc.RevokeSessionRequest) (*rpc.RevokeSessionResponse, error) {
+	if req == nil {
+		l.Errorf("received nil RevokeSessionRequest")
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	if req.UserId <= 0 {
+		l.Errorf("invalid user_id: %d", req.UserId)
+		return nil, fmt.Errorf("invalid user_id: %d", req.UserId)
+	}
+
+	if req.SessionId == "" {
+		l.Errorf("empty session_id for user_id: %d", req.UserId)
+		return nil, fmt.Errorf("session_id cannot be empty")
+	}
+
+	if l.svcCtx.Sessions == nil {
+		l.Errorf("session service not initialized")
+		return nil, fmt.Errorf("session service not available")
+	}
+
+	if err := l.svcCtx.Sessions.Revoke(l.ctx, req.UserId, req.SessionId); err != nil {
+		l.Errorf("failed to revoke session: %v, userId=%d, sessionId=%s", err, req.UserId, req.SessionId)
+		return nil, fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	l.Infof("session revoked: userId=%d, sessionId=%s", req.UserId, req.SessionId)
+
+	return &rpc.RevokeSessionResponse{Success: true}, nil
+}
@@ -0,0 +1,40 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aether-defense-system/service/user/rpc"
+)
+
+func TestRevokeSessionLogic_RevokeSession(t *testing.T) {
+	sessions := newFakeSessions()
+	svcCtx := newSessionTestContext(sessions)
+	session, _, _ := sessions.Create(context.Background(), 7, "web")
+
+	logic := NewRevokeSessionLogic(context.Background(), svcCtx)
+
+	tests := []struct {
+		req  *rpc.RevokeSessionRequest
+		name string
+	}{
+		{name: "nil request", req: nil},
+		{name: "invalid user id", req: &rpc.RevokeSessionRequest{UserId: 0, SessionId: session.SessionID}},
+		{name: "empty session id", req: &rpc.RevokeSessionRequest{UserId: 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := logic.RevokeSession(tt.req); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+
+	resp, err := logic.RevokeSession(&rpc.RevokeSessionRequest{UserId: 7, SessionId: session.SessionID})
+	if err != nil || !resp.Success {
+		t.Fatalf("RevokeSession() = %+v, %v", resp, err)
+	}
+	if _, ok := sessions.sessions[session.SessionID]; ok {
+		t.Errorf("expected session to be revoked")
+	}
+}
@@ -10,13 +10,14 @@ import (
 )
 
 // UserRepo provides data access operations for user domain.
+// Reads go to a replica unless the context forces the primary; writes go to the primary.
 type UserRepo struct {
-	db *sql.DB
+	client *database.Client
 }
 
 // NewUserRepo creates a new UserRepo instance.
-func NewUserRepo(db *sql.DB) *UserRepo {
-	return &UserRepo{db: db}
+func NewUserRepo(client *database.Client) *UserRepo {
+	return &UserRepo{client: client}
 }
 
 // GetByID retrieves a user by ID.
@@ -25,7 +26,7 @@ func (r *UserRepo) GetByID(ctx context.Context, userID int64) (*database.User, e
 	          FROM user WHERE id = ? AND status = ?`
 
 	var user database.User
-	err := r.db.QueryRowContext(ctx, query, userID, database.UserStatusNormal).
+	err := r.client.ReadDB(ctx).QueryRowContext(ctx, query, userID, database.UserStatusNormal).
 		Scan(&user.ID, &user.Username, &user.Mobile, &user.Email, &user.Avatar,
 			&user.Status, &user.CreateTime, &user.UpdateTime)
 	if err != nil {
@@ -44,7 +45,7 @@ func (r *UserRepo) GetByMobile(ctx context.Context, mobile string) (*database.Us
 	          FROM user WHERE mobile = ? AND status = ?`
 
 	var user database.User
-	err := r.db.QueryRowContext(ctx, query, mobile, database.UserStatusNormal).
+	err := r.client.ReadDB(ctx).QueryRowContext(ctx, query, mobile, database.UserStatusNormal).
 		Scan(&user.ID, &user.Username, &user.Mobile, &user.Email, &user.Avatar,
 			&user.Status, &user.CreateTime, &user.UpdateTime)
 	if err != nil {
@@ -57,12 +58,43 @@ func (r *UserRepo) GetByMobile(ctx context.Context, mobile string) (*database.Us
 	return &user, nil
 }
 
+// GetRoles retrieves the role codes assigned to a user.
+// Users without assignments have no roles; that is not an error.
+func (r *UserRepo) GetRoles(ctx context.Context, userID int64) ([]string, error) {
+	query := `SELECT role FROM user_role WHERE user_id = ? ORDER BY role`
+
+	rows, err := r.client.ReadDB(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			_ = closeErr
+		}
+	}()
+
+	var roles []string
+	for rows.Next() {
+		var role string
+		if scanErr := rows.Scan(&role); scanErr != nil {
+			return nil, fmt.Errorf("failed to scan user role: %w", scanErr)
+		}
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user roles: %w", err)
+	}
+
+	return roles, nil
+}
+
 // Create creates a new user.
 func (r *UserRepo) Create(ctx context.Context, user *database.User) error {
 	query := `INSERT INTO user (id, username, mobile, email, avatar, status)
 	          VALUES (?, ?, ?, ?, ?, ?)`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := r.client.DB().ExecContext(ctx, query,
 		user.ID, user.Username, user.Mobile, user.Email, user.Avatar, user.Status)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
@@ -76,7 +108,7 @@ func (r *UserRepo) Update(ctx context.Context, user *database.User) error {
 	query := `UPDATE user SET username = ?, mobile = ?, email = ?, avatar = ?, status = ?
 	          WHERE id = ?`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := r.client.DB().ExecContext(ctx, query,
 		user.Username, user.Mobile, user.Email, user.Avatar, user.Status, user.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
@@ -28,3 +28,33 @@ func (s *UserServiceServer) GetUser(ctx context.Context, in *rpc.GetUserRequest)
 	l := logic.NewGetUserLogic(ctx, s.svcCtx)
 	return l.GetUser(in)
 }
+
+// Login Interface, creates a device session
+func (s *UserServiceServer) Login(ctx context.Context, in *rpc.LoginRequest) (*rpc.LoginResponse, error) {
+	l := logic.NewLoginLogic(ctx, s.svcCtx)
+	return l.Login(in)
+}
+
+// Refresh Token Interface, rotates the refresh token
+func (s *UserServiceServer) RefreshToken(ctx context.Context, in *rpc.RefreshTokenRequest) (*rpc.RefreshTokenResponse, error) {
+	l := logic.NewRefreshTokenLogic(ctx, s.svcCtx)
+	return l.RefreshToken(in)
+}
+
+// List Sessions Interface
+func (s *UserServiceServer) ListSessions(ctx context.Context, in *rpc.ListSessionsRequest) (*rpc.ListSessionsResponse, error) {
+	l := logic.NewListSessionsLogic(ctx, s.svcCtx)
+	return l.ListSessions(in)
+}
+
+// Revoke Session Interface
+func (s *UserServiceServer) RevokeSession(ctx context.Context, in *rpc.RevokeSessionRequest) (*rpc.RevokeSessionResponse, error) {
+	l := logic.NewRevokeSessionLogic(ctx, s.svcCtx)
+	return l.RevokeSession(in)
+}
+
+// Force Logout Interface, revokes all sessions of a user (admin)
+func (s *UserServiceServer) ForceLogout(ctx context.Context, in *rpc.ForceLogoutRequest) (*rpc.ForceLogoutResponse, error) {
+	l := logic.NewForceLogoutLogic(ctx, s.svcCtx)
+	return l.ForceLogout(in)
+}
@@ -4,8 +4,15 @@ package svc
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/aether-defense-system/common/auth"
 	"github.com/aether-defense-system/common/database"
+	"github.com/aether-defense-system/common/database/migrations"
+	"github.com/aether-defense-system/common/interceptor"
+	"github.com/aether-defense-system/common/migrate"
+	"github.com/aether-defense-system/common/redis"
+	"github.com/aether-defense-system/service/user/rpc"
 	"github.com/aether-defense-system/service/user/rpc/internal/config"
 	"github.com/aether-defense-system/service/user/rpc/internal/repo"
 )
@@ -14,13 +21,45 @@ import (
 // This makes user logic unit-testable without a real database.
 type UserRepository interface {
 	GetByID(ctx context.Context, userID int64) (*database.User, error)
+	GetByMobile(ctx context.Context, mobile string) (*database.User, error)
+	GetRoles(ctx context.Context, userID int64) ([]string, error)
 }
 
+// SessionManager defines the session operations required by user logic.
+// *auth.SessionStore satisfies this interface.
+type SessionManager interface {
+	Create(ctx context.Context, userID int64, device string) (*auth.Session, string, error)
+	Rotate(ctx context.Context, refreshToken string) (*auth.Session, string, error)
+	List(ctx context.Context, userID int64) ([]*auth.Session, error)
+	Revoke(ctx context.Context, userID int64, sessionID string) error
+	RevokeAll(ctx context.Context, userID int64) error
+}
+
+// LoginCodeVerifier verifies one-time login codes.
+// *auth.LoginCodeStore satisfies this interface.
+type LoginCodeVerifier interface {
+	Verify(ctx context.Context, mobile, code string) error
+}
+
+// AccessTokenIssuer signs access tokens for sessions.
+// *auth.TokenIssuer satisfies this interface.
+type AccessTokenIssuer interface {
+	IssueAccessToken(userID int64, sessionID string, roles []string) (string, time.Time, error)
+}
+
+// AdminMethodPrefix marks RPCs that are denied unless a permission is registered for them.
+// New admin-only RPCs should be named Admin* so they are protected by default.
+const AdminMethodPrefix = "/rpc.UserService/Admin"
+
 // ServiceContext represents the service context for user RPC service.
 type ServiceContext struct {
-	Config   *config.Config
-	DB       *database.Client
-	UserRepo UserRepository
+	Config     *config.Config
+	DB         *database.Client
+	UserRepo   UserRepository
+	Sessions   SessionManager
+	LoginCodes LoginCodeVerifier
+	Tokens     AccessTokenIssuer
+	Permission *interceptor.PermissionInterceptor // Enforces per-method permissions; registered in main
 }
 
 // NewServiceContext creates a new service context.
@@ -35,12 +74,65 @@ func NewServiceContext(c *config.Config) *ServiceContext {
 			panic(fmt.Sprintf("failed to initialize database: %v", err))
 		}
 		dbClient = client
-		userRepo = repo.NewUserRepo(client.DB())
+		userRepo = repo.NewUserRepo(client)
+	}
+	if c.AutoMigrate {
+		if dbClient == nil {
+			panic("migrating at startup needs the database")
+		}
+		err := migrations.Up(context.Background(), []*migrate.Migrator{migrate.New(dbClient.DB())}, migrations.User)
+		if err != nil {
+			panic(fmt.Sprintf("failed to migrate the database: %v", err))
+		}
 	}
 
-	return &ServiceContext{
+	svcCtx := &ServiceContext{
 		Config:   c,
 		DB:       dbClient,
 		UserRepo: userRepo,
 	}
+
+	var sessionChecker interceptor.SessionChecker
+	// Initialize sessions only when both the session Redis and the token secret are configured.
+	// Without them login and session RPCs report the feature as unavailable.
+	if (c.SessionRedis.Addr != "" || c.SessionRedis.Host != "") && c.Auth.AccessSecret != "" {
+		redisClient, err := redis.NewClient(&c.SessionRedis)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize session Redis: %v", err))
+		}
+
+		sessions, err := auth.NewSessionStore(redisClient, c.Auth.RefreshExpire)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize session store: %v", err))
+		}
+
+		tokens, err := auth.NewTokenIssuer(c.Auth.AccessSecret, c.Auth.AccessExpire)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize token issuer: %v", err))
+		}
+
+		svcCtx.Sessions = sessions
+		sessionChecker = sessions
+		svcCtx.LoginCodes = auth.NewLoginCodeStore(redisClient)
+		svcCtx.Tokens = tokens
+	}
+
+	// Admin RPCs are authorized with the caller's forwarded access token.
+	// Without an access secret every protected method is rejected rather than left open.
+	var verifier interceptor.TokenVerifier
+	if c.Auth.AccessSecret != "" {
+		tokenVerifier, err := auth.NewTokenVerifier(c.Auth.AccessSecret)
+		if err != nil {
+			panic(fmt.Sprintf("failed to initialize token verifier: %v", err))
+		}
+		verifier = tokenVerifier
+	}
+	svcCtx.Permission = interceptor.NewPermissionInterceptor(auth.DefaultPolicy(), verifier, AdminMethodPrefix).
+		Require(rpc.UserService_ForceLogout_FullMethodName, auth.PermUserSessionRevoke)
+	// Tokens of force-logged-out admins are rejected like at the gateways.
+	if sessionChecker != nil {
+		svcCtx.Permission.WithSessions(sessionChecker)
+	}
+
+	return svcCtx
 }
@@ -16,4 +16,10 @@ func TestNewServiceContext(t *testing.T) {
 	if ctx.Config != cfg {
 		t.Fatalf("expected Config pointer to be preserved")
 	}
+	if ctx.Sessions != nil {
+		t.Errorf("expected sessions to be disabled without session Redis")
+	}
+	if ctx.Permission == nil {
+		t.Errorf("expected permission interceptor to be initialized")
+	}
 }
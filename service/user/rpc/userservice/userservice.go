@@ -14,12 +14,33 @@ import (
 )
 
 type (
-	GetUserRequest  = rpc.GetUserRequest
-	GetUserResponse = rpc.GetUserResponse
+	ForceLogoutRequest    = rpc.ForceLogoutRequest
+	ForceLogoutResponse   = rpc.ForceLogoutResponse
+	GetUserRequest        = rpc.GetUserRequest
+	GetUserResponse       = rpc.GetUserResponse
+	ListSessionsRequest   = rpc.ListSessionsRequest
+	ListSessionsResponse  = rpc.ListSessionsResponse
+	LoginRequest          = rpc.LoginRequest
+	LoginResponse         = rpc.LoginResponse
+	RefreshTokenRequest   = rpc.RefreshTokenRequest
+	RefreshTokenResponse  = rpc.RefreshTokenResponse
+	RevokeSessionRequest  = rpc.RevokeSessionRequest
+	RevokeSessionResponse = rpc.RevokeSessionResponse
+	SessionInfo           = rpc.SessionInfo
 
 	UserService interface {
 		// Get User Information Interface
 		GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*GetUserResponse, error)
+		// Login Interface, creates a device session
+		Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+		// Refresh Token Interface, rotates the refresh token
+		RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*RefreshTokenResponse, error)
+		// List Sessions Interface
+		ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+		// Revoke Session Interface
+		RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*RevokeSessionResponse, error)
+		// Force Logout Interface, revokes all sessions of a user (admin)
+		ForceLogout(ctx context.Context, in *ForceLogoutRequest, opts ...grpc.CallOption) (*ForceLogoutResponse, error)
 	}
 
 	defaultUserService struct {
@@ -38,3 +59,33 @@ func (m *defaultUserService) GetUser(ctx context.Context, in *GetUserRequest, op
 	client := rpc.NewUserServiceClient(m.cli.Conn())
 	return client.GetUser(ctx, in, opts...)
 }
+
+// Login Interface, creates a device session
+func (m *defaultUserService) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	client := rpc.NewUserServiceClient(m.cli.Conn())
+	return client.Login(ctx, in, opts...)
+}
+
+// Refresh Token Interface, rotates the refresh token
+func (m *defaultUserService) RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*RefreshTokenResponse, error) {
+	client := rpc.NewUserServiceClient(m.cli.Conn())
+	return client.RefreshToken(ctx, in, opts...)
+}
+
+// List Sessions Interface
+func (m *defaultUserService) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	client := rpc.NewUserServiceClient(m.cli.Conn())
+	return client.ListSessions(ctx, in, opts...)
+}
+
+// Revoke Session Interface
+func (m *defaultUserService) RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*RevokeSessionResponse, error) {
+	client := rpc.NewUserServiceClient(m.cli.Conn())
+	return client.RevokeSession(ctx, in, opts...)
+}
+
+// Force Logout Interface, revokes all sessions of a user (admin)
+func (m *defaultUserService) ForceLogout(ctx context.Context, in *ForceLogoutRequest, opts ...grpc.CallOption) (*ForceLogoutResponse, error) {
+	client := rpc.NewUserServiceClient(m.cli.Conn())
+	return client.ForceLogout(ctx, in, opts...)
+}